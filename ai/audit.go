@@ -0,0 +1,180 @@
+/*
+Package ai AI决策审计记录（按账号追加写入JSON Lines文件）
+
+主要功能：
+- NewAudit(dir string) (*Audit, error)                                                // 创建审计记录（目录不存在时自动创建）
+- (a *Audit) Record(rec *AuditRecord) error                                            // 追加一条审计记录
+- (a *Audit) Load(accountID string) ([]AuditRecord, error)                             // 读取账号的全部审计记录
+- (c *Client) RunStage(ctx context.Context, name, template string, req *Request) (*Stage, error)  // 发送一个阶段的请求并生成阶段记录
+
+每次AI分析（单次调用或两阶段的分析、决策两次调用）写入一条记录，包含每个阶段的完整提示词和回复、
+解析出的决策以及执行结果，用于复盘AI的判断过程。
+*/
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/veto"
+)
+
+// 分析阶段
+const (
+	StageSingle   = "single"   // 单次调用（直接输出决策）
+	StageAnalysis = "analysis" // 两阶段：市场分析
+	StageDecision = "decision" // 两阶段：结合分析和账户状态输出决策
+)
+
+// 分析模式
+const (
+	ModeSingle   = "single"    // 单次调用
+	ModeTwoStage = "two_stage" // 分析 → 决策两次调用
+	ModeCached   = "cached"    // 指标数据与上次相同，复用上次决策（没有调用AI）
+)
+
+// Stage 一次AI调用的记录
+type Stage struct {
+	Name             string `json:"name"`                        // 阶段
+	Template         string `json:"template"`                    // 提示词模板
+	Prompt           string `json:"prompt"`                      // 完整提示词
+	Reply            string `json:"reply"`                       // AI回复
+	Model            string `json:"model,omitempty"`             // 实际使用的模型
+	PromptTokens     int    `json:"prompt_tokens,omitempty"`     // 提示词token数（接口返回）
+	CompletionTokens int    `json:"completion_tokens,omitempty"` // 回复token数（接口返回）
+	LatencyMs        int64  `json:"latency_ms"`                  // 请求耗时（毫秒）
+	Error            string `json:"error,omitempty"`             // 调用或解析失败原因
+
+	ToolCalls []*ToolCall `json:"tool_calls,omitempty"` // 回复前AI发起的工具调用
+	Partial   bool        `json:"partial,omitempty"`    // 流式回复中断，使用了已收到的部分内容
+
+	Sample  int    `json:"sample,omitempty"`  // 投票采样序号（从1开始，未投票时为0）
+	Action  string `json:"action,omitempty"`  // 投票采样解析出的决策动作
+	Dissent bool   `json:"dissent,omitempty"` // 投票采样与多数动作不一致（或无法解析）
+}
+
+// AuditRecord 一次AI分析的审计记录
+type AuditRecord struct {
+	AccountID string             `json:"account_id"`         // 账号ID
+	Strategy  string             `json:"strategy"`           // 策略注册名称
+	Symbol    string             `json:"symbol"`             // 交易对
+	Time      time.Time          `json:"time"`               // 开始分析的时间
+	Mode      string             `json:"mode"`               // 分析模式：single / two_stage / cached
+	Stages    []*Stage           `json:"stages"`             // 各阶段的调用记录
+	Vote      *VoteResult        `json:"vote,omitempty"`     // 多次采样投票结果（未启用投票时为nil）
+	Decision  *executor.Decision `json:"decision,omitempty"` // 解析出的决策
+	Veto      *veto.Result       `json:"veto,omitempty"`     // 风控否决规则的检查结果（没有触发规则时为nil）
+	Result    string             `json:"result,omitempty"`   // 执行结果
+	Error     string             `json:"error,omitempty"`    // 分析或执行失败原因
+
+	IndicatorKind string          `json:"indicator_kind,omitempty"` // 指标数据类型（indicators.Kind，回放时按类型还原）
+	Indicators    json.RawMessage `json:"indicators,omitempty"`     // 分析时的完整指标数据（未经token预算压缩）
+
+	PayloadHash string     `json:"payload_hash,omitempty"` // 指标数据指纹（启用决策缓存时）
+	CachedFrom  *time.Time `json:"cached_from,omitempty"`  // 复用的决策最初的分析时间（未命中缓存时为nil）
+}
+
+// Audit AI决策审计记录
+type Audit struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewAudit 创建审计记录
+func NewAudit(dir string) (*Audit, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建AI审计目录失败: %w", err)
+	}
+	return &Audit{dir: dir}, nil
+}
+
+// Record 追加一条审计记录
+func (a *Audit) Record(rec *AuditRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("序列化AI审计记录失败: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.OpenFile(a.path(rec.AccountID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开AI审计记录失败: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("写入AI审计记录失败: %w", err)
+	}
+	return nil
+}
+
+// Load 读取账号的全部审计记录（文件不存在时返回空）
+func (a *Audit) Load(accountID string) ([]AuditRecord, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.Open(a.path(accountID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开AI审计记录失败: %w", err)
+	}
+	defer f.Close()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(f)
+	// 单条记录包含完整提示词，超出默认的64KB行长度限制
+	scanner.Buffer(make([]byte, 0, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("解析AI审计记录失败: %w", err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取AI审计记录失败: %w", err)
+	}
+	return records, nil
+}
+
+// path 账号的审计记录文件路径
+func (a *Audit) path(accountID string) string {
+	return filepath.Join(a.dir, accountID+".jsonl")
+}
+
+// RunStage 发送一个阶段的请求并生成阶段记录（调用失败时记录中带有错误原因）
+func (c *Client) RunStage(ctx context.Context, name, template string, req *Request) (*Stage, error) {
+	stage := &Stage{Name: name, Template: template, Prompt: req.Prompt}
+	return stage, c.runStage(ctx, stage, req)
+}
+
+// runStage 发送请求并把回复填入阶段记录
+func (c *Client) runStage(ctx context.Context, stage *Stage, req *Request) error {
+	resp, err := c.Complete(ctx, req)
+	if err != nil {
+		stage.Error = err.Error()
+		return err
+	}
+	stage.Reply = resp.Text
+	stage.Model = resp.Model
+	stage.PromptTokens = resp.PromptTokens
+	stage.CompletionTokens = resp.CompletionTokens
+	stage.LatencyMs = resp.Latency.Milliseconds()
+	stage.ToolCalls = resp.ToolCalls
+	stage.Partial = resp.Partial
+	return nil
+}
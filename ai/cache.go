@@ -0,0 +1,65 @@
+/*
+Package ai AI决策缓存（指标数据未变化时复用上次决策）
+
+主要功能：
+- NewCache(maxAge time.Duration) *Cache                                                 // 创建决策缓存
+- (c *Cache) Get(symbol, hash string, now time.Time) (*executor.Decision, time.Time)    // 指纹相同且未过期时返回上次决策的副本和决策时间
+- (c *Cache) Put(symbol, hash string, decision *executor.Decision, now time.Time)       // 记录交易对最近一次决策
+
+每个账号一个缓存，每个交易对只保留最近一次决策。缓存为nil（未启用）时 Get 总是未命中、Put 不做任何事。
+*/
+package ai
+
+import (
+	"sync"
+	"time"
+
+	"crypto-ai-trader/executor"
+)
+
+// Cache AI决策缓存
+type Cache struct {
+	maxAge time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry // 交易对 -> 最近一次决策
+}
+
+// cacheEntry 缓存的决策
+type cacheEntry struct {
+	hash      string            // 指标数据指纹
+	decision  executor.Decision // 决策副本
+	decidedAt time.Time         // 决策时间
+}
+
+// NewCache 创建决策缓存
+func NewCache(maxAge time.Duration) *Cache {
+	return &Cache{maxAge: maxAge, entries: make(map[string]*cacheEntry)}
+}
+
+// Get 指纹相同且未超过最长缓存时间时返回上次决策的副本和决策时间（未命中时返回nil）
+func (c *Cache) Get(symbol, hash string, now time.Time) (*executor.Decision, time.Time) {
+	if c == nil || hash == "" {
+		return nil, time.Time{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[symbol]
+	if !ok || entry.hash != hash || now.Sub(entry.decidedAt) > c.maxAge {
+		return nil, time.Time{}
+	}
+	d := entry.decision
+	return &d, entry.decidedAt
+}
+
+// Put 记录交易对最近一次决策（保存副本，执行器对决策的修改不影响缓存）
+func (c *Cache) Put(symbol, hash string, decision *executor.Decision, now time.Time) {
+	if c == nil || hash == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[symbol] = &cacheEntry{hash: hash, decision: *decision, decidedAt: now}
+}
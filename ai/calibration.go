@@ -0,0 +1,254 @@
+/*
+Package ai 置信度校准（AI给出的置信度与实际交易结果对比）
+
+主要功能：
+- Calibrate(accountID string, records []AuditRecord, trades []journal.Trade, bins int) []*CalibrationGroup  // 按模型计算账号的校准曲线
+- BuildCalibrationReport(audit *Audit, tradeJournal *journal.Journal, accountIDs []string, bins int) *CalibrationReport  // 生成多个账号的校准报告
+- SaveCalibrationReport(report *CalibrationReport, dir string) (string, error)                            // 报告保存为JSON文件
+- RunCalibrationReports(ctx context.Context, audit *Audit, tradeJournal *journal.Journal, accountIDs []string, cfg config.CalibrationConfig)  // 定时生成并保存报告，直到ctx取消
+
+审计记录中的开仓决策按决策哈希与交易日志中已结束的交易对应（一个决策分多次平仓时净盈亏合计），
+净盈亏为正记为盈利。置信度按 [0,1] 等分为 bins 段，每段比较平均置信度与实际胜率：
+校准良好时置信度0.9的决策约90%盈利。Brier分数 = 平均(置信度 - 结果)²，
+ECE（期望校准误差）= 各段 |平均置信度 - 胜率| 按交易数加权平均，两者越小越好。
+置信度为0（AI未给出）的决策和尚未平仓的决策不参与统计。
+*/
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/journal"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// unknownModel 审计记录中没有模型名称时的分组
+const unknownModel = "unknown"
+
+// CalibrationReport 置信度校准报告
+type CalibrationReport struct {
+	Time   time.Time           `json:"time"`             // 生成时间
+	Bins   int                 `json:"bins"`             // 置信度分段数
+	Groups []*CalibrationGroup `json:"groups"`           // 按账号、模型分组的校准结果
+	Errors map[string]string   `json:"errors,omitempty"` // 读取失败的账号及原因
+}
+
+// CalibrationGroup 一个账号、一个模型的校准结果
+type CalibrationGroup struct {
+	AccountID      string           `json:"account_id"`      // 账号ID
+	Model          string           `json:"model"`           // 模型名称
+	Trades         int              `json:"trades"`          // 已平仓的决策数
+	MeanConfidence float64          `json:"mean_confidence"` // 平均置信度
+	WinRate        float64          `json:"win_rate"`        // 实际胜率（0-1）
+	Brier          float64          `json:"brier"`           // Brier分数（越小越好）
+	ECE            float64          `json:"ece"`             // 期望校准误差（越小越好）
+	Curve          []CalibrationBin `json:"curve"`           // 校准曲线（只含有交易的分段）
+}
+
+// CalibrationBin 校准曲线的一个置信度分段
+type CalibrationBin struct {
+	Low            float64 `json:"low"`             // 置信度下限（含）
+	High           float64 `json:"high"`            // 置信度上限（最后一段含上限）
+	Trades         int     `json:"trades"`          // 决策数
+	MeanConfidence float64 `json:"mean_confidence"` // 平均置信度
+	WinRate        float64 `json:"win_rate"`        // 实际胜率
+	AvgNetPnL      float64 `json:"avg_net_pnl"`     // 平均净盈亏
+}
+
+// outcome 一个已平仓决策的置信度和结果
+type outcome struct {
+	confidence float64
+	netPnL     float64
+}
+
+// Calibrate 按模型计算账号的校准曲线（没有可统计的决策时返回空）
+func Calibrate(accountID string, records []AuditRecord, trades []journal.Trade, bins int) []*CalibrationGroup {
+	pnl := make(map[string]float64)
+	for _, t := range trades {
+		if t.DecisionID != "" {
+			pnl[t.DecisionID] += t.NetPnL
+		}
+	}
+
+	// 复用缓存的记录没有调用AI，模型取原决策所在记录的模型
+	models := make(map[string]string)
+	for _, rec := range records {
+		models[recordKey(rec.Symbol, rec.Time)] = recordModel(&rec)
+	}
+
+	byModel := make(map[string][]outcome)
+	for _, rec := range records {
+		d := rec.Decision
+		if d == nil || d.Confidence <= 0 || (d.Action != executor.ActionOpenLong && d.Action != executor.ActionOpenShort) {
+			continue
+		}
+		netPnL, closed := pnl[d.Hash()]
+		if !closed {
+			continue
+		}
+		model := recordModel(&rec)
+		if rec.CachedFrom != nil {
+			if m, ok := models[recordKey(rec.Symbol, *rec.CachedFrom)]; ok {
+				model = m
+			}
+		}
+		byModel[model] = append(byModel[model], outcome{confidence: d.Confidence, netPnL: netPnL})
+	}
+
+	groups := make([]*CalibrationGroup, 0, len(byModel))
+	for model, outcomes := range byModel {
+		group := calibrateOutcomes(outcomes, bins)
+		group.AccountID, group.Model = accountID, model
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Model < groups[j].Model })
+	return groups
+}
+
+// calibrateOutcomes 计算一组决策的校准曲线和汇总指标
+func calibrateOutcomes(outcomes []outcome, bins int) *CalibrationGroup {
+	group := &CalibrationGroup{Trades: len(outcomes)}
+	curve := make([]CalibrationBin, bins)
+	var wins int
+	for i := range curve {
+		curve[i].Low = float64(i) / float64(bins)
+		curve[i].High = float64(i+1) / float64(bins)
+	}
+	for _, o := range outcomes {
+		won := 0.0
+		if o.netPnL > 0 {
+			won = 1
+			wins++
+		}
+		group.MeanConfidence += o.confidence
+		group.Brier += (o.confidence - won) * (o.confidence - won)
+
+		b := &curve[min(int(o.confidence*float64(bins)), bins-1)]
+		b.Trades++
+		b.MeanConfidence += o.confidence
+		b.WinRate += won
+		b.AvgNetPnL += o.netPnL
+	}
+
+	n := float64(len(outcomes))
+	group.MeanConfidence /= n
+	group.Brier /= n
+	group.WinRate = float64(wins) / n
+	for _, b := range curve {
+		if b.Trades == 0 {
+			continue
+		}
+		count := float64(b.Trades)
+		b.MeanConfidence /= count
+		b.WinRate /= count
+		b.AvgNetPnL /= count
+		group.ECE += count / n * math.Abs(b.MeanConfidence-b.WinRate)
+		group.Curve = append(group.Curve, b)
+	}
+	return group
+}
+
+// recordModel 审计记录使用的模型（取最后一个有模型名称的阶段）
+func recordModel(rec *AuditRecord) string {
+	for i := len(rec.Stages) - 1; i >= 0; i-- {
+		if rec.Stages[i] != nil && rec.Stages[i].Model != "" {
+			return rec.Stages[i].Model
+		}
+	}
+	return unknownModel
+}
+
+// recordKey 按交易对和分析时间定位审计记录
+func recordKey(symbol string, t time.Time) string {
+	return fmt.Sprintf("%s|%d", symbol, t.UnixNano())
+}
+
+// BuildCalibrationReport 读取各账号的审计记录和交易日志，生成校准报告（读取失败的账号记录在 Errors 中）
+func BuildCalibrationReport(audit *Audit, tradeJournal *journal.Journal, accountIDs []string, bins int) *CalibrationReport {
+	report := &CalibrationReport{Time: time.Now(), Bins: bins, Groups: []*CalibrationGroup{}}
+	for _, accountID := range accountIDs {
+		records, err := audit.Load(accountID)
+		if err != nil {
+			setReportError(report, accountID, err)
+			continue
+		}
+		trades, err := tradeJournal.Load(accountID)
+		if err != nil {
+			setReportError(report, accountID, err)
+			continue
+		}
+		report.Groups = append(report.Groups, Calibrate(accountID, records, trades, bins)...)
+	}
+	return report
+}
+
+// setReportError 记录账号读取失败的原因
+func setReportError(report *CalibrationReport, accountID string, err error) {
+	if report.Errors == nil {
+		report.Errors = make(map[string]string)
+	}
+	report.Errors[accountID] = err.Error()
+}
+
+// SaveCalibrationReport 报告保存为JSON文件，返回文件路径
+func SaveCalibrationReport(report *CalibrationReport, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建报告目录失败: %w", err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("序列化校准报告失败: %w", err)
+	}
+
+	path := filepath.Join(dir, "calibration_"+report.Time.Format("20060102_150405")+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("写入校准报告失败: %w", err)
+	}
+	return path, nil
+}
+
+// RunCalibrationReports 定时生成并保存置信度校准报告，直到ctx取消
+func RunCalibrationReports(ctx context.Context, audit *Audit, tradeJournal *journal.Journal, accountIDs []string, cfg config.CalibrationConfig) {
+	ticker := time.NewTicker(time.Duration(cfg.IntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			report := BuildCalibrationReport(audit, tradeJournal, accountIDs, cfg.Bins)
+			path, err := SaveCalibrationReport(report, cfg.Dir)
+			if err != nil {
+				utils.Error("保存置信度校准报告失败", zap.Error(err))
+			}
+			for _, g := range report.Groups {
+				utils.Info("置信度校准",
+					zap.String("file", path),
+					zap.String("account_id", g.AccountID),
+					zap.String("model", g.Model),
+					zap.Int("trades", g.Trades),
+					zap.Float64("mean_confidence", g.MeanConfidence),
+					zap.Float64("win_rate", g.WinRate),
+					zap.Float64("brier", g.Brier),
+					zap.Float64("ece", g.ECE),
+				)
+			}
+			for accountID, reason := range report.Errors {
+				utils.Warn("读取校准数据失败", zap.String("account_id", accountID), zap.String("error", reason))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
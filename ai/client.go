@@ -0,0 +1,206 @@
+/*
+Package ai AI模型客户端（OpenAI兼容的 /chat/completions 接口）
+
+主要功能：
+- NewClient(cfg config.AIConfig, proxyURL string) *Client                    // 创建AI客户端
+- (c *Client) Complete(ctx context.Context, req *Request) (*Response, error)  // 发送对话请求并返回回复（可带工具）
+
+兼容 OpenAI、DeepSeek、通义千问等提供 /chat/completions 接口的服务，接口地址和模型名称在 config.yml 的 ai 中配置。
+*/
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// Request 对话请求
+type Request struct {
+	System       string   // 系统提示词（可选）
+	Prompt       string   // 用户提示词
+	Temperature  *float64 // 采样温度（为nil时使用配置的温度）
+	Tools        []*Tool  // AI可调用的工具（可选）
+	MaxToolCalls int      // 工具调用次数上限（达到后要求AI直接回复）
+}
+
+// Response 对话回复
+type Response struct {
+	Text             string        `json:"text"`              // 回复内容
+	Model            string        `json:"model"`             // 实际使用的模型
+	PromptTokens     int           `json:"prompt_tokens"`     // 提示词token数（接口返回）
+	CompletionTokens int           `json:"completion_tokens"` // 回复token数（接口返回）
+	Latency          time.Duration `json:"latency"`           // 请求耗时
+	ToolCalls        []*ToolCall   `json:"tool_calls"`        // 回复前AI发起的工具调用
+	Partial          bool          `json:"partial"`           // 流式回复中断，使用已收到的部分内容
+}
+
+// Client AI模型客户端
+type Client struct {
+	cfg        config.AIConfig
+	httpClient *http.Client
+}
+
+// chatMessage 对话消息
+type chatMessage struct {
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`   // AI发起的工具调用（assistant消息）
+	ToolCallID string         `json:"tool_call_id,omitempty"` // 对应的工具调用ID（tool消息）
+}
+
+// chatRequest /chat/completions 请求体
+type chatRequest struct {
+	Model         string         `json:"model"`
+	Messages      []chatMessage  `json:"messages"`
+	MaxTokens     int            `json:"max_tokens,omitempty"`
+	Temperature   float64        `json:"temperature"`
+	Tools         []chatTool     `json:"tools,omitempty"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
+}
+
+// chatResponse /chat/completions 响应体
+type chatResponse struct {
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   chatUsage    `json:"usage"`
+	Error   *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+
+	partial bool // 流式回复中断，只收到部分内容（内容中已有完整的JSON对象）
+}
+
+// chatChoice 回复选项
+type chatChoice struct {
+	Message chatMessage `json:"message"`
+}
+
+// chatUsage token用量
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// NewClient 创建AI客户端
+func NewClient(cfg config.AIConfig, proxyURL string) *Client {
+	client := &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{}, // 超时由 Complete 的截止时间控制（流式回复需要边读边判断）
+	}
+
+	if proxyURL != "" {
+		if proxy, err := url.Parse(proxyURL); err != nil {
+			utils.Error("解析代理URL失败", zap.String("proxy", proxyURL), zap.Error(err))
+		} else {
+			client.httpClient.Transport = &http.Transport{Proxy: http.ProxyURL(proxy)}
+		}
+	}
+
+	utils.Info("创建AI客户端",
+		zap.String("base_url", cfg.BaseURL),
+		zap.String("model", cfg.Model),
+		zap.Bool("proxy_enabled", proxyURL != ""),
+	)
+	return client
+}
+
+// Complete 发送对话请求并返回回复
+// 请求带有工具时，AI可以先调用工具获取数据，工具结果加入对话后继续请求，直到AI给出回复
+// timeout_sec 为整个对话（含工具调用的多轮请求）的截止时间
+func (c *Client) Complete(ctx context.Context, req *Request) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.cfg.TimeoutSec)*time.Second)
+	defer cancel()
+
+	body := &chatRequest{
+		Model:       c.cfg.Model,
+		MaxTokens:   c.cfg.MaxTokens,
+		Temperature: c.cfg.Temperature,
+	}
+	if req.Temperature != nil {
+		body.Temperature = *req.Temperature
+	}
+	if req.System != "" {
+		body.Messages = append(body.Messages, chatMessage{Role: "system", Content: req.System})
+	}
+	body.Messages = append(body.Messages, chatMessage{Role: "user", Content: req.Prompt})
+	if len(req.Tools) > 0 {
+		return c.completeWithTools(ctx, body, req.Tools, req.MaxToolCalls)
+	}
+
+	start := time.Now()
+	result, err := c.chat(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{
+		Text:             result.Choices[0].Message.Content,
+		Model:            result.Model,
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		Latency:          time.Since(start),
+		Partial:          result.partial,
+	}, nil
+}
+
+// chat 发送一次 /chat/completions 请求（返回的回复至少有一个choice），启用流式回复时按SSE读取
+func (c *Client) chat(ctx context.Context, body *chatRequest) (*chatResponse, error) {
+	if c.cfg.Stream {
+		return c.chatStream(ctx, body)
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("序列化AI请求失败: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimSuffix(c.cfg.BaseURL, "/")+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("创建AI请求失败: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("AI请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取AI响应失败: %w", err)
+	}
+
+	var result chatResponse
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("AI接口返回错误: HTTP %d, %s", resp.StatusCode, truncate(string(raw), 200))
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("AI接口返回错误: HTTP %d, %s", resp.StatusCode, result.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK || len(result.Choices) == 0 {
+		return nil, fmt.Errorf("AI接口返回错误: HTTP %d, %s", resp.StatusCode, truncate(string(raw), 200))
+	}
+	return &result, nil
+}
+
+// truncate 截断过长的文本（用于错误信息）
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,148 @@
+/*
+Package ai 决策对照组统计（按提示词模板版本、模型、配置哈希分组比较绩效）
+
+主要功能：
+- ConfigHash(v interface{}) string                                                                   // 配置的哈希（JSON序列化后计算）
+- Cohorts(accountID string, records []AuditRecord, trades []journal.Trade) []*CohortStats            // 按决策标签分组统计账号的决策和交易结果
+- BuildCohortReport(audit *Audit, tradeJournal *journal.Journal, accountIDs []string) *CohortReport  // 生成多个账号的对照组报告
+
+每个AI决策带有标签（executor.DecisionTags）：提示词模板及版本、实际使用的模型、影响决策的配置哈希。
+修改模板内容、更换模型或调整策略/AI配置后，新决策的标签随之变化，自动形成新的对照组，无需手动记录实验。
+审计记录中的决策按决策哈希与交易日志中已结束的交易对应（一个决策分多次平仓时净盈亏合计）。
+复用缓存的决策沿用原决策的标签；没有标签的历史记录归入对照组标识为空的一组。
+*/
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/journal"
+)
+
+// CohortReport 对照组报告
+type CohortReport struct {
+	Time    time.Time         `json:"time"`             // 生成时间
+	Cohorts []*CohortStats    `json:"cohorts"`          // 按账号、标签分组的统计
+	Errors  map[string]string `json:"errors,omitempty"` // 读取失败的账号及原因
+}
+
+// CohortStats 一个账号、一组标签的统计
+type CohortStats struct {
+	AccountID string                `json:"account_id"` // 账号ID
+	Cohort    string                `json:"cohort"`     // 对照组标识
+	Tags      executor.DecisionTags `json:"tags"`       // 决策标签
+	FirstSeen time.Time             `json:"first_seen"` // 第一条决策的时间
+	LastSeen  time.Time             `json:"last_seen"`  // 最后一条决策的时间
+
+	Decisions      int            `json:"decisions"`       // 决策数
+	Actions        map[string]int `json:"actions"`         // 各动作的决策数
+	Opens          int            `json:"opens"`           // 开仓决策数
+	Vetoed         int            `json:"vetoed"`          // 被风控否决的开仓决策数
+	MeanConfidence float64        `json:"mean_confidence"` // 开仓决策的平均置信度
+	Trades         int            `json:"trades"`          // 已平仓的开仓决策数
+	Wins           int            `json:"wins"`            // 盈利的决策数（按净盈亏）
+	WinRate        float64        `json:"win_rate"`        // 胜率（0-1）
+	NetPnL         float64        `json:"net_pnl"`         // 净盈亏合计
+	AvgNetPnL      float64        `json:"avg_net_pnl"`     // 平均每个决策的净盈亏
+}
+
+// ConfigHash 配置的哈希（JSON序列化后计算，不要包含API密钥等无关字段）
+func ConfigHash(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// Cohorts 按决策标签分组统计账号的决策和交易结果（按审计记录中第一次出现的顺序）
+func Cohorts(accountID string, records []AuditRecord, trades []journal.Trade) []*CohortStats {
+	pnl := make(map[string]float64)
+	for _, t := range trades {
+		if t.DecisionID != "" {
+			pnl[t.DecisionID] += t.NetPnL
+		}
+	}
+
+	byCohort := make(map[string]*CohortStats)
+	var cohorts []*CohortStats
+	for _, rec := range records {
+		d := rec.Decision
+		if d == nil {
+			continue
+		}
+		var tags executor.DecisionTags
+		if d.Tags != nil {
+			tags = *d.Tags
+		}
+		cohort := d.Tags.Cohort()
+		stats, ok := byCohort[cohort]
+		if !ok {
+			stats = &CohortStats{AccountID: accountID, Cohort: cohort, Tags: tags, FirstSeen: rec.Time, Actions: make(map[string]int)}
+			byCohort[cohort] = stats
+			cohorts = append(cohorts, stats)
+		}
+		stats.LastSeen = rec.Time
+		stats.Decisions++
+		stats.Actions[d.Action]++
+
+		if d.Action != executor.ActionOpenLong && d.Action != executor.ActionOpenShort {
+			continue
+		}
+		stats.Opens++
+		stats.MeanConfidence += d.Confidence
+		if rec.Veto != nil && rec.Veto.Vetoed {
+			stats.Vetoed++
+		}
+		if netPnL, closed := pnl[d.Hash()]; closed {
+			stats.Trades++
+			stats.NetPnL += netPnL
+			if netPnL > 0 {
+				stats.Wins++
+			}
+		}
+	}
+
+	for _, stats := range cohorts {
+		if stats.Opens > 0 {
+			stats.MeanConfidence /= float64(stats.Opens)
+		}
+		if stats.Trades > 0 {
+			stats.WinRate = float64(stats.Wins) / float64(stats.Trades)
+			stats.AvgNetPnL = stats.NetPnL / float64(stats.Trades)
+		}
+	}
+	return cohorts
+}
+
+// BuildCohortReport 读取各账号的审计记录和交易日志，生成对照组报告（读取失败的账号记录在 Errors 中）
+func BuildCohortReport(audit *Audit, tradeJournal *journal.Journal, accountIDs []string) *CohortReport {
+	report := &CohortReport{Time: time.Now(), Cohorts: []*CohortStats{}}
+	for _, accountID := range accountIDs {
+		records, err := audit.Load(accountID)
+		if err != nil {
+			setCohortError(report, accountID, err)
+			continue
+		}
+		trades, err := tradeJournal.Load(accountID)
+		if err != nil {
+			setCohortError(report, accountID, err)
+			continue
+		}
+		report.Cohorts = append(report.Cohorts, Cohorts(accountID, records, trades)...)
+	}
+	return report
+}
+
+// setCohortError 记录账号读取失败的原因
+func setCohortError(report *CohortReport, accountID string, err error) {
+	if report.Errors == nil {
+		report.Errors = make(map[string]string)
+	}
+	report.Errors[accountID] = err.Error()
+}
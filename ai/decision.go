@@ -0,0 +1,52 @@
+/*
+Package ai 交易决策回复解析
+
+主要功能：
+- ParseDecision(text, symbol string) (*executor.Decision, error)  // 解析AI输出的交易决策（校验动作、交易对、止损和置信度）
+
+回复格式见 configs/prompts/common.tmpl 的 output_format 片段。动作不区分大小写；
+回复中没有交易对时使用分析的交易对，与分析的交易对不一致时视为无效回复。
+*/
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"crypto-ai-trader/executor"
+)
+
+// ParseDecision 解析AI输出的交易决策
+func ParseDecision(text, symbol string) (*executor.Decision, error) {
+	raw, err := ExtractJSON(text)
+	if err != nil {
+		return nil, err
+	}
+	var d executor.Decision
+	if err := json.Unmarshal([]byte(raw), &d); err != nil {
+		return nil, fmt.Errorf("解析决策回复失败: %w", err)
+	}
+
+	d.Action = strings.ToLower(strings.TrimSpace(d.Action))
+	switch d.Action {
+	case executor.ActionOpenLong, executor.ActionOpenShort:
+		if d.StopLoss <= 0 {
+			return nil, fmt.Errorf("开仓决策缺少止损价: %s", truncate(raw, 200))
+		}
+	case executor.ActionClose, executor.ActionHold:
+	default:
+		return nil, fmt.Errorf("未知的决策动作: %q", d.Action)
+	}
+
+	d.Symbol = strings.ToUpper(strings.TrimSpace(d.Symbol))
+	if d.Symbol == "" {
+		d.Symbol = symbol
+	} else if d.Symbol != symbol {
+		return nil, fmt.Errorf("决策交易对 %s 与分析的交易对 %s 不一致", d.Symbol, symbol)
+	}
+	if d.Confidence < 0 || d.Confidence > 1 {
+		return nil, fmt.Errorf("置信度必须在0-1之间: %v", d.Confidence)
+	}
+	return &d, nil
+}
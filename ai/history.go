@@ -0,0 +1,177 @@
+/*
+Package ai 指标快照历史查询（从审计记录中读取每次分析时的指标数据）
+
+主要功能：
+- QuerySnapshots(audit *Audit, accountIDs []string, q SnapshotQuery) *SnapshotHistory  // 按交易对、时间周期、时间范围查询指标快照
+- QuerySeries(audit *Audit, accountIDs []string, q SnapshotQuery) *SeriesHistory       // 按交易对、时间范围查询持仓量、资金费率序列
+
+每条保存了指标数据的审计记录（AI分析、复用缓存或因数据过期跳过）就是一个快照：分析时的完整指标数据（未经token预算压缩），
+读取时迁移到当前数据结构版本，看板和回测可以直接使用系统自己记录的数据，而不必重新从交易所获取并计算。
+多个账号分析同一交易对时各有各的快照（带账号ID），按时间从旧到新排列；limit 从最新的快照往前取。
+持仓量、资金费率序列取自快照的市场数据，没有市场数据的快照（如现货账号）不产生数据点。
+*/
+package ai
+
+import (
+	"sort"
+	"time"
+
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/schemas"
+)
+
+// SnapshotQuery 指标快照查询条件
+type SnapshotQuery struct {
+	Symbol    string    // 交易对（必需）
+	Timeframe string    // 时间周期（如 15m，为空时返回完整指标数据；快照没有该周期时跳过）
+	From      time.Time // 开始时间（包含，零值表示不限）
+	To        time.Time // 结束时间（不包含，零值表示不限）
+	Limit     int       // 最多返回的快照数（从最新的往前取，0表示全部）
+}
+
+// Snapshot 一次分析时的指标快照
+type Snapshot struct {
+	AccountID  string                    `json:"account_id"`            // 账号ID
+	Strategy   string                    `json:"strategy"`              // 策略注册名称
+	Time       time.Time                 `json:"time"`                  // 分析时间
+	Kind       string                    `json:"kind"`                  // 指标数据类型（indicators.Kind）
+	Indicators interface{}               `json:"indicators,omitempty"`  // 完整指标数据（未指定时间周期时）
+	Timeframe  *indicators.TimeframeData `json:"timeframe,omitempty"`   // 指定时间周期的指标
+	MarketData *indicators.MarketData    `json:"market_data,omitempty"` // 指定时间周期时附带的市场数据（持仓量、资金费率）
+}
+
+// SnapshotHistory 指标快照查询结果
+type SnapshotHistory struct {
+	Symbol    string            `json:"symbol"`              // 交易对
+	Timeframe string            `json:"timeframe,omitempty"` // 时间周期
+	Snapshots []*Snapshot       `json:"snapshots"`           // 快照（按时间从旧到新）
+	Errors    map[string]string `json:"errors,omitempty"`    // 读取失败的账号及原因
+	Skipped   int               `json:"skipped,omitempty"`   // 无法还原（如版本比当前新）或没有该时间周期的快照数
+}
+
+// SeriesPoint 持仓量、资金费率序列的一个数据点
+type SeriesPoint struct {
+	AccountID    string    `json:"account_id"`               // 账号ID
+	Time         time.Time `json:"time"`                     // 分析时间
+	Price        float64   `json:"price,omitempty"`          // 主分析周期的当前价格
+	OI           float64   `json:"oi"`                       // 持仓量（百万美元）
+	OIChange15m  *float64  `json:"oi_change_15m,omitempty"`  // 持仓量15分钟变化率(%)
+	OIPriceState string    `json:"oi_price_state,omitempty"` // 持仓量与价格关系
+	FundingRate  float64   `json:"funding_rate"`             // 资金费率(%)
+	FundingAvg3  float64   `json:"funding_avg_3"`            // 最近3次平均资金费率(%)
+}
+
+// SeriesHistory 持仓量、资金费率序列查询结果
+type SeriesHistory struct {
+	Symbol  string            `json:"symbol"`            // 交易对
+	Points  []*SeriesPoint    `json:"points"`            // 数据点（按时间从旧到新）
+	Errors  map[string]string `json:"errors,omitempty"`  // 读取失败的账号及原因
+	Skipped int               `json:"skipped,omitempty"` // 无法还原的快照数
+}
+
+// snapshotRecord 已还原指标数据的审计记录
+type snapshotRecord struct {
+	rec  *AuditRecord
+	data interface{}
+}
+
+// QuerySnapshots 按交易对、时间周期、时间范围查询多个账号的指标快照
+func QuerySnapshots(audit *Audit, accountIDs []string, q SnapshotQuery) *SnapshotHistory {
+	history := &SnapshotHistory{Symbol: q.Symbol, Timeframe: q.Timeframe, Snapshots: []*Snapshot{}}
+	records, skipped, errors := loadSnapshots(audit, accountIDs, q)
+	history.Errors, history.Skipped = errors, skipped
+
+	for _, r := range records {
+		snapshot := &Snapshot{
+			AccountID: r.rec.AccountID,
+			Strategy:  r.rec.Strategy,
+			Time:      r.rec.Time,
+			Kind:      r.rec.IndicatorKind,
+		}
+		if q.Timeframe == "" {
+			snapshot.Indicators = r.data
+		} else {
+			tf := indicators.Timeframes(r.data)[q.Timeframe]
+			if tf == nil {
+				history.Skipped++
+				continue
+			}
+			_, _, market := indicators.PrimaryTimeframe(r.data)
+			snapshot.Timeframe, snapshot.MarketData = tf, market
+		}
+		history.Snapshots = append(history.Snapshots, snapshot)
+	}
+	history.Snapshots = lastN(history.Snapshots, q.Limit)
+	return history
+}
+
+// QuerySeries 按交易对、时间范围查询多个账号快照中的持仓量、资金费率序列（忽略 q.Timeframe）
+func QuerySeries(audit *Audit, accountIDs []string, q SnapshotQuery) *SeriesHistory {
+	series := &SeriesHistory{Symbol: q.Symbol, Points: []*SeriesPoint{}}
+	records, skipped, errors := loadSnapshots(audit, accountIDs, q)
+	series.Errors, series.Skipped = errors, skipped
+
+	for _, r := range records {
+		_, tf, market := indicators.PrimaryTimeframe(r.data)
+		if market == nil {
+			continue
+		}
+		point := &SeriesPoint{
+			AccountID:    r.rec.AccountID,
+			Time:         r.rec.Time,
+			OI:           market.OICurrent,
+			OIChange15m:  market.OIChange15m,
+			OIPriceState: market.OIPriceState,
+			FundingRate:  market.FundingRate,
+			FundingAvg3:  market.FundingAvg3,
+		}
+		if tf != nil {
+			point.Price = tf.CurrentPrice()
+		}
+		series.Points = append(series.Points, point)
+	}
+	series.Points = lastN(series.Points, q.Limit)
+	return series
+}
+
+// loadSnapshots 读取各账号审计记录中该交易对、时间范围内的快照并还原指标数据（按时间从旧到新）
+func loadSnapshots(audit *Audit, accountIDs []string, q SnapshotQuery) ([]snapshotRecord, int, map[string]string) {
+	var records []snapshotRecord
+	var skipped int
+	var errors map[string]string
+	for _, accountID := range accountIDs {
+		all, err := audit.Load(accountID)
+		if err != nil {
+			if errors == nil {
+				errors = make(map[string]string)
+			}
+			errors[accountID] = err.Error()
+			continue
+		}
+		for i := range all {
+			rec := &all[i]
+			if rec.Symbol != q.Symbol || len(rec.Indicators) == 0 {
+				continue
+			}
+			if (!q.From.IsZero() && rec.Time.Before(q.From)) || (!q.To.IsZero() && !rec.Time.Before(q.To)) {
+				continue
+			}
+			data, err := schemas.Decode(rec.IndicatorKind, rec.Indicators)
+			if err != nil {
+				skipped++
+				continue
+			}
+			records = append(records, snapshotRecord{rec: rec, data: data})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].rec.Time.Before(records[j].rec.Time) })
+	return records, skipped, errors
+}
+
+// lastN 保留最后n个元素（n为0时全部保留）
+func lastN[T any](items []T, n int) []T {
+	if n > 0 && len(items) > n {
+		return items[len(items)-n:]
+	}
+	return items
+}
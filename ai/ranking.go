@@ -0,0 +1,64 @@
+/*
+Package ai 多交易对排名回复解析
+
+主要功能：
+- ParseRanking(text string, candidates []string, topN int) ([]string, error)  // 解析AI挑选的候选交易对
+- ExtractJSON(text string) (string, error)                                   // 从AI回复中提取JSON对象（兼容代码块和前后说明文字）
+*/
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// rankingReply 排名提示词要求的回复格式
+type rankingReply struct {
+	Symbols []string `json:"symbols"` // 按优先级排列的候选交易对
+}
+
+// ParseRanking 解析AI挑选的候选交易对
+// 只保留排名表中的交易对（不区分大小写），去重后最多返回 topN 个
+func ParseRanking(text string, candidates []string, topN int) ([]string, error) {
+	raw, err := ExtractJSON(text)
+	if err != nil {
+		return nil, err
+	}
+	var reply rankingReply
+	if err := json.Unmarshal([]byte(raw), &reply); err != nil {
+		return nil, fmt.Errorf("解析排名回复失败: %w", err)
+	}
+
+	valid := make(map[string]bool, len(candidates))
+	for _, s := range candidates {
+		valid[s] = true
+	}
+	picked := make([]string, 0, topN)
+	seen := make(map[string]bool, topN)
+	for _, s := range reply.Symbols {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if !valid[s] || seen[s] {
+			continue
+		}
+		seen[s] = true
+		picked = append(picked, s)
+		if len(picked) == topN {
+			break
+		}
+	}
+	if len(picked) == 0 {
+		return nil, fmt.Errorf("排名回复中没有有效的交易对: %v", reply.Symbols)
+	}
+	return picked, nil
+}
+
+// ExtractJSON 从AI回复中提取JSON对象（第一个 { 到最后一个 }）
+func ExtractJSON(text string) (string, error) {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return "", fmt.Errorf("AI回复中没有JSON对象: %s", truncate(text, 200))
+	}
+	return text[start : end+1], nil
+}
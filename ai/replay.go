@@ -0,0 +1,172 @@
+/*
+Package ai 决策回放（用其他模型或新的提示词模板重新评估历史决策）
+
+主要功能：
+- (c *Client) Replay(ctx context.Context, records []AuditRecord, opts ReplayOptions) *ReplayReport  // 回放审计记录并与原决策对比
+- DiffDecisions(original, replayed *executor.Decision) []string                                   // 两个决策的差异（动作、止损、止盈、杠杆、置信度）
+
+更换模型或修改提示词前，用审计记录中的历史数据离线评估：
+1. 未指定模板：把原来输出决策的提示词（单次调用的提示词，两阶段模式决策阶段的提示词）原样发送给当前客户端的模型
+2. 指定模板：用审计记录中保存的完整指标数据（旧版本数据先迁移到当前结构版本）渲染新模板，单次调用输出决策（两阶段记录同样按单次调用回放）
+回放结果按原决策动作 → 新决策动作统计，逐条列出差异。复用缓存的记录（没有调用AI）跳过。
+回放只调用AI并解析决策，不执行、不写入审计记录。
+*/
+package ai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/prompt"
+	"crypto-ai-trader/schemas"
+)
+
+// noDecision 原记录没有解析出决策（失败或投票未达成多数）时的动作
+const noDecision = "none"
+
+// ReplayOptions 回放选项
+type ReplayOptions struct {
+	Template string        // 新的提示词模板（为空时使用审计记录中的原提示词）
+	Store    *prompt.Store // 提示词模板（指定 Template 时必需）
+	Limit    int           // 最多回放的记录数（从最新的记录往前取，0表示全部）
+}
+
+// ReplayResult 一条审计记录的回放结果
+type ReplayResult struct {
+	Symbol   string             `json:"symbol"`             // 交易对
+	Time     time.Time          `json:"time"`               // 原分析时间
+	Original *executor.Decision `json:"original,omitempty"` // 原决策
+	Replayed *executor.Decision `json:"replayed,omitempty"` // 回放得到的决策
+	Stage    *Stage             `json:"stage,omitempty"`    // 回放的调用记录
+	Changed  bool               `json:"changed"`            // 决策动作是否改变
+	Diff     []string           `json:"diff,omitempty"`     // 决策差异
+	Error    string             `json:"error,omitempty"`    // 回放失败原因
+}
+
+// ReplayReport 回放报告
+type ReplayReport struct {
+	Model       string          `json:"model"`              // 回放使用的模型（接口返回）
+	Template    string          `json:"template,omitempty"` // 回放使用的新模板（为空表示原提示词）
+	Records     int             `json:"records"`            // 参与回放的审计记录数
+	Skipped     int             `json:"skipped"`            // 跳过的记录数（缓存复用、没有提示词或指标数据）
+	Failed      int             `json:"failed"`             // 调用或解析失败的记录数
+	SameAction  int             `json:"same_action"`        // 动作与原决策相同的记录数
+	Changed     int             `json:"changed"`            // 动作改变的记录数
+	Transitions map[string]int  `json:"transitions"`        // 原动作 → 新动作 的记录数（如 "open_long -> hold"）
+	Results     []*ReplayResult `json:"results"`            // 逐条结果
+}
+
+// Replay 回放审计记录并与原决策对比（按记录时间顺序逐条调用AI）
+func (c *Client) Replay(ctx context.Context, records []AuditRecord, opts ReplayOptions) *ReplayReport {
+	report := &ReplayReport{Template: opts.Template, Transitions: make(map[string]int)}
+	if opts.Limit > 0 && len(records) > opts.Limit {
+		records = records[len(records)-opts.Limit:]
+	}
+
+	for i := range records {
+		if ctx.Err() != nil {
+			break
+		}
+		rec := &records[i]
+		template, text, err := replayPrompt(rec, opts)
+		if err != nil {
+			report.Skipped++
+			continue
+		}
+		report.Records++
+
+		result := &ReplayResult{Symbol: rec.Symbol, Time: rec.Time, Original: rec.Decision}
+		report.Results = append(report.Results, result)
+		stage, err := c.RunStage(ctx, StageSingle, template, &Request{Prompt: text})
+		result.Stage = stage
+		if err == nil {
+			report.Model = stage.Model
+			result.Replayed, err = ParseDecision(stage.Reply, rec.Symbol)
+		}
+		if err != nil {
+			result.Error = err.Error()
+			report.Failed++
+			continue
+		}
+
+		from, to := noDecision, result.Replayed.Action
+		if rec.Decision != nil {
+			from = rec.Decision.Action
+		}
+		report.Transitions[from+" -> "+to]++
+		result.Diff = DiffDecisions(rec.Decision, result.Replayed)
+		if result.Changed = from != to; result.Changed {
+			report.Changed++
+		} else {
+			report.SameAction++
+		}
+	}
+	return report
+}
+
+// replayPrompt 生成回放的提示词，返回模板名称和提示词
+func replayPrompt(rec *AuditRecord, opts ReplayOptions) (string, string, error) {
+	if rec.Mode == ModeCached {
+		return "", "", fmt.Errorf("复用缓存的记录没有调用AI")
+	}
+
+	if opts.Template == "" {
+		// 投票模式有多个相同提示词的采样，取第一个
+		for _, stage := range rec.Stages {
+			if stage != nil && (stage.Name == StageSingle || stage.Name == StageDecision) && stage.Prompt != "" {
+				return stage.Template, stage.Prompt, nil
+			}
+		}
+		return "", "", fmt.Errorf("审计记录中没有输出决策的提示词")
+	}
+
+	if len(rec.Indicators) == 0 {
+		return "", "", fmt.Errorf("审计记录中没有保存指标数据")
+	}
+	data, err := schemas.Decode(rec.IndicatorKind, rec.Indicators)
+	if err != nil {
+		return "", "", err
+	}
+	text, err := opts.Store.Render(opts.Template, &prompt.Data{
+		AccountID:    rec.AccountID,
+		Strategy:     rec.Strategy,
+		StrategyName: rec.Strategy,
+		Symbol:       rec.Symbol,
+		Time:         rec.Time,
+		Indicators:   data,
+		Summary:      indicators.Describe(data),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return opts.Template, text, nil
+}
+
+// DiffDecisions 两个决策的差异（动作、止损、止盈、杠杆、置信度），原决策为nil时只比较动作
+func DiffDecisions(original, replayed *executor.Decision) []string {
+	if original == nil {
+		return []string{fmt.Sprintf("action: %s -> %s", noDecision, replayed.Action)}
+	}
+
+	var diff []string
+	if original.Action != replayed.Action {
+		diff = append(diff, fmt.Sprintf("action: %s -> %s", original.Action, replayed.Action))
+	}
+	for _, f := range []struct {
+		name     string
+		from, to float64
+	}{
+		{"stop_loss", original.StopLoss, replayed.StopLoss},
+		{"take_profit", original.TakeProfit, replayed.TakeProfit},
+		{"leverage", float64(original.Leverage), float64(replayed.Leverage)},
+		{"confidence", original.Confidence, replayed.Confidence},
+	} {
+		if f.from != f.to {
+			diff = append(diff, fmt.Sprintf("%s: %v -> %v", f.name, f.from, f.to))
+		}
+	}
+	return diff
+}
@@ -0,0 +1,179 @@
+/*
+Package ai 流式回复（Server-Sent Events）
+
+主要功能：
+- (c *Client) chatStream(ctx context.Context, body *chatRequest) (*chatResponse, error)  // 发送流式请求并拼接回复（超时或停顿时按部分内容处理）
+- ErrStreamInterrupted                                                                    // 流式回复超时或停顿，且已收到的内容不可用
+
+启用 ai.stream 后请求带上 stream: true，边接收边拼接回复内容和工具调用，并检查两种超时：
+1. 截止时间：timeout_sec（Complete 对整个对话设置），到期后中断
+2. 停顿：idle_timeout_sec 内没有收到任何数据（接口卡住），立即中断，不必等到截止时间
+
+中断时已收到的内容中如果有完整的JSON对象（决策已经输出完，只是结尾说明或结束标记没收到），
+使用部分内容并标记 Response.Partial；否则返回 ErrStreamInterrupted，由调用方跳过该交易对。
+*/
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ErrStreamInterrupted 流式回复超时或停顿，且已收到的内容不可用
+var ErrStreamInterrupted = errors.New("AI流式回复中断")
+
+// streamOptions 流式请求选项（最后一个数据块带上token用量）
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// chatChunk 流式回复的数据块
+type chatChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Type     string `json:"type"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *chatUsage `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// chatStream 发送流式请求并拼接回复（超时或停顿时按部分内容处理）
+func (c *Client) chatStream(ctx context.Context, body *chatRequest) (*chatResponse, error) {
+	streamBody := *body
+	streamBody.Stream = true
+	streamBody.StreamOptions = &streamOptions{IncludeUsage: true}
+	payload, err := json.Marshal(&streamBody)
+	if err != nil {
+		return nil, fmt.Errorf("序列化AI请求失败: %w", err)
+	}
+
+	// 停顿检测：每收到一行数据重置计时，超过 idle_timeout_sec 没有数据时取消请求
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var stalled atomic.Bool
+	idle := time.Duration(c.cfg.IdleTimeoutSec) * time.Second
+	timer := time.AfterFunc(idle, func() {
+		stalled.Store(true)
+		cancel()
+	})
+	defer timer.Stop()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimSuffix(c.cfg.BaseURL, "/")+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("创建AI请求失败: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("AI请求失败: %w", interruptReason(ctx, &stalled, idle, err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		raw, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("AI接口返回错误: HTTP %d, %s", resp.StatusCode, truncate(string(raw), 200))
+	}
+
+	result := &chatResponse{Choices: []chatChoice{{Message: chatMessage{Role: "assistant"}}}}
+	msg := &result.Choices[0].Message
+	var content strings.Builder
+	done := false
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		timer.Reset(idle)
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue // 空行、注释（心跳）、event字段
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			done = true
+			break
+		}
+
+		var chunk chatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("解析AI流式数据失败: %w", err)
+		}
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("AI接口返回错误: %s", chunk.Error.Message)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.Usage = *chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+			for _, d := range choice.Delta.ToolCalls {
+				for len(msg.ToolCalls) <= d.Index {
+					msg.ToolCalls = append(msg.ToolCalls, chatToolCall{Type: "function"})
+				}
+				call := &msg.ToolCalls[d.Index]
+				if d.ID != "" {
+					call.ID = d.ID
+				}
+				if call.Function.Name == "" {
+					call.Function.Name = d.Function.Name // 部分接口在每个分片中重复名称，只取第一次
+				}
+				call.Function.Arguments += d.Function.Arguments
+			}
+		}
+	}
+	msg.Content = content.String()
+
+	// 连接正常结束但没有 [DONE] 也视为完整回复（部分兼容接口不发送结束标记）
+	if err := scanner.Err(); err != nil && !done {
+		reason := interruptReason(ctx, &stalled, idle, err)
+		raw, jsonErr := ExtractJSON(msg.Content)
+		if len(msg.ToolCalls) > 0 || jsonErr != nil || !json.Valid([]byte(raw)) {
+			return nil, fmt.Errorf("%w（已收到%d字节）: %v", ErrStreamInterrupted, len(msg.Content), reason)
+		}
+		msg.Content = raw
+		result.partial = true
+	}
+	if msg.Content == "" && len(msg.ToolCalls) == 0 {
+		return nil, fmt.Errorf("AI流式回复为空（接口可能不支持stream）")
+	}
+	return result, nil
+}
+
+// interruptReason 区分停顿、截止时间和其他网络错误
+func interruptReason(ctx context.Context, stalled *atomic.Bool, idle time.Duration, err error) error {
+	switch {
+	case stalled.Load():
+		return fmt.Errorf("超过%s没有收到数据", idle)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("超过截止时间")
+	default:
+		return err
+	}
+}
@@ -0,0 +1,261 @@
+/*
+Package ai 工具调用（AI在给出决策前请求额外数据）
+
+主要功能：
+- MarketTools(client *binance.Client) []*Tool  // 由币安客户端提供数据的行情工具（K线、订单簿深度、资金费率历史）
+
+提示词中的指标数据只覆盖策略使用的时间周期。请求带有工具时（OpenAI兼容的 tools / tool_calls 协议），
+AI可以在对话中调用工具获取额外数据（如日线K线、盘口深度、最近30次资金费率），工具结果加入对话后继续请求。
+工具调用达到 MaxToolCalls 次后不再提供工具，要求AI直接给出回复。工具执行失败时把错误信息作为工具结果返回给AI。
+*/
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// Tool AI可调用的工具
+type Tool struct {
+	Name        string                                                          // 工具名称
+	Description string                                                          // 工具说明（AI据此决定何时调用）
+	Parameters  map[string]interface{}                                          // 参数的JSON Schema
+	Handler     func(ctx context.Context, args json.RawMessage) (string, error) // 执行工具，返回交给AI的结果（通常为JSON）
+}
+
+// ToolCall 一次工具调用的记录
+type ToolCall struct {
+	Name      string `json:"name"`            // 工具名称
+	Arguments string `json:"arguments"`       // AI给出的参数（JSON）
+	Result    string `json:"result"`          // 返回给AI的结果
+	Error     string `json:"error,omitempty"` // 执行失败原因
+	LatencyMs int64  `json:"latency_ms"`      // 执行耗时（毫秒）
+}
+
+// chatTool /chat/completions 请求中的工具定义
+type chatTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description"`
+		Parameters  map[string]interface{} `json:"parameters"`
+	} `json:"function"`
+}
+
+// chatToolCall 回复中的工具调用
+type chatToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// completeWithTools 带工具的对话：AI发起工具调用时执行工具并继续请求，直到AI给出回复
+func (c *Client) completeWithTools(ctx context.Context, body *chatRequest, tools []*Tool, maxCalls int) (*Response, error) {
+	byName := make(map[string]*Tool, len(tools))
+	for _, t := range tools {
+		byName[t.Name] = t
+		ct := chatTool{Type: "function"}
+		ct.Function.Name, ct.Function.Description, ct.Function.Parameters = t.Name, t.Description, t.Parameters
+		body.Tools = append(body.Tools, ct)
+	}
+
+	start := time.Now()
+	resp := &Response{}
+	for {
+		// 工具调用次数用完后不再提供工具，要求AI直接回复
+		if len(resp.ToolCalls) >= maxCalls {
+			body.Tools = nil
+		}
+		result, err := c.chat(ctx, body)
+		if err != nil {
+			return nil, err
+		}
+		resp.Model = result.Model
+		resp.PromptTokens += result.Usage.PromptTokens
+		resp.CompletionTokens += result.Usage.CompletionTokens
+
+		msg := result.Choices[0].Message
+		if len(msg.ToolCalls) == 0 || body.Tools == nil {
+			resp.Text = msg.Content
+			resp.Partial = result.partial
+			resp.Latency = time.Since(start)
+			return resp, nil
+		}
+
+		body.Messages = append(body.Messages, msg)
+		for _, call := range msg.ToolCalls {
+			record := runTool(ctx, byName[call.Function.Name], call.Function.Name, call.Function.Arguments)
+			resp.ToolCalls = append(resp.ToolCalls, record)
+			content := record.Result
+			if record.Error != "" {
+				content = "错误: " + record.Error
+			}
+			body.Messages = append(body.Messages, chatMessage{Role: "tool", Content: content, ToolCallID: call.ID})
+		}
+	}
+}
+
+// runTool 执行一次工具调用（工具不存在时记录错误）
+func runTool(ctx context.Context, tool *Tool, name, args string) *ToolCall {
+	record := &ToolCall{Name: name, Arguments: args}
+	if tool == nil {
+		record.Error = "未知的工具: " + name
+		return record
+	}
+	if strings.TrimSpace(args) == "" {
+		args = "{}"
+	}
+
+	start := time.Now()
+	result, err := tool.Handler(ctx, json.RawMessage(args))
+	record.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		record.Result = result
+	}
+	utils.Info("AI工具调用",
+		zap.String("tool", name),
+		zap.String("arguments", args),
+		zap.Int("result_bytes", len(record.Result)),
+		zap.String("error", record.Error),
+	)
+	return record
+}
+
+// toolArgs 工具参数（各工具只使用其中部分字段）
+type toolArgs struct {
+	Symbol   string `json:"symbol"`
+	Interval string `json:"interval"`
+	Limit    int    `json:"limit"`
+}
+
+// parseToolArgs 解析工具参数，limit未给出时使用默认值并按上限截断
+func parseToolArgs(raw json.RawMessage, defaultLimit, maxLimit int) (*toolArgs, error) {
+	var args toolArgs
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, fmt.Errorf("解析工具参数失败: %w", err)
+	}
+	args.Symbol = strings.ToUpper(strings.TrimSpace(args.Symbol))
+	if args.Symbol == "" {
+		return nil, fmt.Errorf("缺少参数symbol")
+	}
+	if args.Limit <= 0 {
+		args.Limit = defaultLimit
+	}
+	args.Limit = min(args.Limit, maxLimit)
+	return &args, nil
+}
+
+// toJSON 序列化工具结果
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("序列化工具结果失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// schema 生成工具参数的JSON Schema（symbol必填，其他字段可选）
+func schema(props map[string]interface{}) map[string]interface{} {
+	props["symbol"] = map[string]interface{}{"type": "string", "description": "交易对，如 BTCUSDT"}
+	return map[string]interface{}{"type": "object", "properties": props, "required": []string{"symbol"}}
+}
+
+// MarketTools 由币安客户端提供数据的行情工具
+func MarketTools(client *binance.Client) []*Tool {
+	return []*Tool{
+		{
+			Name:        "get_klines",
+			Description: "获取K线（从旧到新），每根为 [开盘时间(毫秒), 开, 高, 低, 收, 成交量]。用于查看提示词中没有的周期，如日线",
+			Parameters: schema(map[string]interface{}{
+				"interval": map[string]interface{}{"type": "string", "enum": []string{"5m", "15m", "1h", "4h", "1d", "1w"}, "description": "K线周期"},
+				"limit":    map[string]interface{}{"type": "integer", "description": "K线数量（默认30，最多200）"},
+			}),
+			Handler: func(ctx context.Context, raw json.RawMessage) (string, error) {
+				args, err := parseToolArgs(raw, 30, 200)
+				if err != nil {
+					return "", err
+				}
+				if args.Interval == "" {
+					return "", fmt.Errorf("缺少参数interval")
+				}
+				klines, err := client.GetKlines(args.Symbol, args.Interval, args.Limit)
+				if err != nil {
+					return "", err
+				}
+				rows := make([][]interface{}, 0, len(klines))
+				for _, k := range klines {
+					rows = append(rows, []interface{}{k.OpenTime, k.Open, k.High, k.Low, k.Close, k.Volume})
+				}
+				return toJSON(map[string]interface{}{"symbol": args.Symbol, "interval": args.Interval, "klines": rows})
+			},
+		},
+		{
+			Name:        "get_order_book",
+			Description: "获取订单簿深度（买卖盘 [价格, 数量]）、中间价和买卖价差，用于判断流动性和挂单支撑阻力",
+			Parameters: schema(map[string]interface{}{
+				"limit": map[string]interface{}{"type": "integer", "enum": []int{5, 10, 20, 50, 100}, "description": "档位数量（默认20）"},
+			}),
+			Handler: func(ctx context.Context, raw json.RawMessage) (string, error) {
+				args, err := parseToolArgs(raw, 20, 100)
+				if err != nil {
+					return "", err
+				}
+				book, err := client.GetOrderBook(args.Symbol, depthLimit(args.Limit))
+				if err != nil {
+					return "", err
+				}
+				result := map[string]interface{}{"symbol": args.Symbol, "bids": book.Bids, "asks": book.Asks}
+				if mid := book.MidPrice(); mid > 0 {
+					result["mid_price"] = mid
+					result["spread_bps"] = book.SpreadBps()
+				}
+				return toJSON(result)
+			},
+		},
+		{
+			Name:        "get_funding_history",
+			Description: "获取最近已结算的资金费率（从旧到新，费率为小数，0.0001表示0.01%），用于判断多空拥挤程度",
+			Parameters: schema(map[string]interface{}{
+				"limit": map[string]interface{}{"type": "integer", "description": "数量（默认30，最多100）"},
+			}),
+			Handler: func(ctx context.Context, raw json.RawMessage) (string, error) {
+				args, err := parseToolArgs(raw, 30, 100)
+				if err != nil {
+					return "", err
+				}
+				rates, err := client.GetFundingRateHistory(args.Symbol, args.Limit)
+				if err != nil {
+					return "", err
+				}
+				rows := make([][]interface{}, 0, len(rates))
+				for _, r := range rates {
+					rows = append(rows, []interface{}{r.FundingTime, r.FundingRate})
+				}
+				return toJSON(map[string]interface{}{"symbol": args.Symbol, "funding_rates": rows})
+			},
+		},
+	}
+}
+
+// depthLimit 取不小于n的币安订单簿档位
+func depthLimit(n int) int {
+	for _, limit := range []int{5, 10, 20, 50, 100} {
+		if n <= limit {
+			return limit
+		}
+	}
+	return 100
+}
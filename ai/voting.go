@@ -0,0 +1,96 @@
+/*
+Package ai 多次采样投票（自洽性检查）
+
+主要功能：
+- (c *Client) SampleStage(ctx context.Context, name, template string, req *Request, k int, temperature float64) []*Stage  // 并发发送K次同一请求，返回每次的阶段记录
+- Vote(stages []*Stage, symbol string, required int) (*executor.Decision, *VoteResult)                       // 解析每个采样的决策并按动作投票
+- (v *VoteResult) Agreed() bool                                                                               // 是否达成多数
+
+单次回复受采样随机性影响，同一份数据可能给出相反的判断。投票模式以大于0的温度独立采样K次，
+只有同一动作（开多、开空、平仓、观望）得票不少于 required 时才采用，否则本次不执行。
+与多数动作不一致或无法解析的采样标记为异议（未达成多数时全部标记），完整回复保留在审计记录中。
+*/
+package ai
+
+import (
+	"context"
+	"sync"
+
+	"crypto-ai-trader/executor"
+)
+
+// VoteResult 投票结果
+type VoteResult struct {
+	Samples  int            `json:"samples"`          // 采样次数
+	Valid    int            `json:"valid"`            // 成功解析出决策的采样数
+	Required int            `json:"required"`         // 采用决策需要的最少同意票数
+	Counts   map[string]int `json:"counts"`           // 各动作的票数
+	Action   string         `json:"action,omitempty"` // 胜出的动作（未达成多数时为空）
+	Agree    int            `json:"agree"`            // 胜出动作（或得票最多动作）的票数
+}
+
+// Agreed 是否达成多数
+func (v *VoteResult) Agreed() bool {
+	return v.Action != ""
+}
+
+// SampleStage 并发发送K次同一请求（使用指定的采样温度），返回每次的阶段记录（失败的记录带有错误原因）
+func (c *Client) SampleStage(ctx context.Context, name, template string, req *Request, k int, temperature float64) []*Stage {
+	stages := make([]*Stage, k)
+	var wg sync.WaitGroup
+	for i := range stages {
+		stages[i] = &Stage{Name: name, Template: template, Prompt: req.Prompt, Sample: i + 1}
+		sample := *req
+		sample.Temperature = &temperature
+		wg.Add(1)
+		go func(stage *Stage) {
+			defer wg.Done()
+			c.runStage(ctx, stage, &sample)
+		}(stages[i])
+	}
+	wg.Wait()
+	return stages
+}
+
+// Vote 解析每个采样的决策并按动作投票
+// 得票最多且不少于 required 的动作胜出（票数相同时视为未达成），返回该动作第一个采样的决策；未达成时决策为nil
+func Vote(stages []*Stage, symbol string, required int) (*executor.Decision, *VoteResult) {
+	result := &VoteResult{Samples: len(stages), Required: required, Counts: make(map[string]int)}
+	decisions := make([]*executor.Decision, len(stages))
+	for i, stage := range stages {
+		if stage.Error != "" {
+			continue
+		}
+		d, err := ParseDecision(stage.Reply, symbol)
+		if err != nil {
+			stage.Error = err.Error()
+			continue
+		}
+		decisions[i] = d
+		stage.Action = d.Action
+		result.Valid++
+		result.Counts[d.Action]++
+	}
+
+	best, tie := "", false
+	for action, n := range result.Counts {
+		switch {
+		case n > result.Agree:
+			best, tie, result.Agree = action, false, n
+		case n == result.Agree:
+			tie = true
+		}
+	}
+	if !tie && result.Agree >= required {
+		result.Action = best
+	}
+
+	var winner *executor.Decision
+	for i, stage := range stages {
+		stage.Dissent = decisions[i] == nil || decisions[i].Action != result.Action
+		if winner == nil && !stage.Dissent {
+			winner = decisions[i]
+		}
+	}
+	return winner, result
+}
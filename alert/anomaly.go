@@ -0,0 +1,158 @@
+/*
+Package alert 价格、持仓量异动检测
+
+主要功能：
+- NewAnomalyDetector(cfg config.AnomalyConfig) *AnomalyDetector      // 创建异动检测
+- (d *AnomalyDetector) Check(symbol, timeframe string, tf *indicators.TimeframeData, market *indicators.MarketData, now time.Time) []Event  // 检查一个交易对，返回触发的告警
+
+价格异动：主分析周期当前K线（只用已收盘K线计算时为最近一根已收盘K线）的涨跌幅度（|收盘价 - 开盘价|）超过 atr_multiple 倍ATR。
+持仓量异动：持仓量与 oi_window_minutes 之前相比变化超过 oi_jump_pct（增加和减少都告警）。
+持仓量历史按交易对保存在环形缓冲中，30秒内的多次检查只保留最新一次（多个账号共用时不会挤掉历史）；
+窗口之前没有数据或数据过旧（超过两个窗口）时不判断，启动后至少经过一个窗口才会出现持仓量告警。
+*/
+package alert
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+)
+
+// 异动告警类型
+const (
+	KindPriceMove = "price_move" // 价格异动
+	KindOIJump    = "oi_jump"    // 持仓量异动
+)
+
+// oiHistorySize 每个交易对保留的持仓量记录数
+const oiHistorySize = 64
+
+// oiMinSpacing 持仓量记录的最小间隔（间隔内的检查替换最新一条）
+const oiMinSpacing = 30 * time.Second
+
+// AnomalyDetector 价格、持仓量异动检测
+type AnomalyDetector struct {
+	cfg config.AnomalyConfig
+
+	mu sync.Mutex
+	oi map[string]*utils.Ring[float64] // symbol -> 持仓量（百万美元）
+}
+
+// NewAnomalyDetector 创建异动检测
+func NewAnomalyDetector(cfg config.AnomalyConfig) *AnomalyDetector {
+	return &AnomalyDetector{
+		cfg: cfg,
+		oi:  make(map[string]*utils.Ring[float64]),
+	}
+}
+
+// Check 检查一个交易对（tf为主分析周期指标，market为市场数据，可以为nil），返回触发的告警（未经过冷却过滤）
+func (d *AnomalyDetector) Check(symbol, timeframe string, tf *indicators.TimeframeData, market *indicators.MarketData, now time.Time) []Event {
+	if d == nil {
+		return nil
+	}
+	var events []Event
+	if event, ok := d.checkPrice(symbol, timeframe, tf, now); ok {
+		events = append(events, event)
+	}
+	if market != nil && market.OICurrent > 0 {
+		if event, ok := d.checkOI(symbol, market.OICurrent, now); ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// checkPrice 当前K线涨跌幅度超过 atr_multiple 倍ATR
+func (d *AnomalyDetector) checkPrice(symbol, timeframe string, tf *indicators.TimeframeData, now time.Time) (Event, bool) {
+	if tf == nil || tf.ATR <= 0 || tf.OpenPrice <= 0 || d.cfg.ATRMultiple <= 0 {
+		return Event{}, false
+	}
+	move := tf.ClosePrice - tf.OpenPrice
+	multiple := math.Abs(move) / tf.ATR
+	if multiple < d.cfg.ATRMultiple {
+		return Event{}, false
+	}
+
+	changePct := move / tf.OpenPrice * 100
+	direction := "上涨"
+	if move < 0 {
+		direction = "下跌"
+	}
+	return Event{
+		Time:    now,
+		Kind:    KindPriceMove,
+		Symbol:  symbol,
+		Level:   LevelWarning,
+		Message: fmt.Sprintf("%s %s K线%s %.2f%%，为ATR的%.1f倍", symbol, timeframe, direction, math.Abs(changePct), multiple),
+		Values: map[string]float64{
+			"change_pct":   round2(changePct),
+			"atr_multiple": round2(multiple),
+			"price":        tf.CurrentPrice(),
+			"atr":          tf.ATR,
+		},
+	}, true
+}
+
+// checkOI 记录持仓量，与窗口之前相比变化超过 oi_jump_pct
+func (d *AnomalyDetector) checkOI(symbol string, oi float64, now time.Time) (Event, bool) {
+	window := time.Duration(d.cfg.OIWindowMinutes) * time.Minute
+
+	d.mu.Lock()
+	history, ok := d.oi[symbol]
+	if !ok {
+		history = utils.NewRing[float64](oiHistorySize)
+		d.oi[symbol] = history
+	}
+	if latest, ok := history.Latest(); ok && now.Sub(latest.Time) < oiMinSpacing {
+		history.Update(oi)
+	} else {
+		history.Push(now, oi)
+	}
+	// 窗口之前最近的一条记录
+	var ref utils.Point[float64]
+	found := false
+	for _, p := range history.Newest(history.Len()) {
+		if !p.Time.After(now.Add(-window)) {
+			ref, found = p, true
+			break
+		}
+	}
+	d.mu.Unlock()
+
+	if !found || ref.Value <= 0 || now.Sub(ref.Time) > 2*window || d.cfg.OIJumpPct <= 0 {
+		return Event{}, false
+	}
+	changePct := (oi - ref.Value) / ref.Value * 100
+	if math.Abs(changePct) < d.cfg.OIJumpPct {
+		return Event{}, false
+	}
+
+	direction := "增加"
+	if changePct < 0 {
+		direction = "减少"
+	}
+	return Event{
+		Time:    now,
+		Kind:    KindOIJump,
+		Symbol:  symbol,
+		Level:   LevelWarning,
+		Message: fmt.Sprintf("%s 持仓量%.0f分钟内%s %.2f%%（%.2fM → %.2fM）", symbol, now.Sub(ref.Time).Minutes(), direction, math.Abs(changePct), ref.Value, oi),
+		Values: map[string]float64{
+			"change_pct":  round2(changePct),
+			"oi":          oi,
+			"oi_previous": ref.Value,
+			"minutes":     round2(now.Sub(ref.Time).Minutes()),
+		},
+	}, true
+}
+
+// round2 保留2位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
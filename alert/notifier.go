@@ -0,0 +1,237 @@
+/*
+Package alert 行情告警（写入日志，配置webhook时同时推送）
+
+主要功能：
+- NewNotifier(cfg config.AlertsConfig, proxyURL string) *Notifier     // 创建告警通知
+- (n *Notifier) Notify(event Event) bool                             // 发出一条告警（冷却期内的重复告警被忽略，返回false）
+- (n *Notifier) Run(ctx context.Context)                             // 推送webhook，直到ctx取消
+- (n *Notifier) Recent(limit int, kind, symbol string) []Event       // 最近的告警（从新到旧，可按类型、交易对过滤）
+- (n *Notifier) Stats() NotifierStats                                // 告警统计
+
+同一交易对同一类告警在 cooldown_minutes 内只发出一次（多个账号共用交易对池时不会重复告警）。
+webhook推送在单独的协程中完成，Notify 从不阻塞：队列满时丢弃告警（仍写入日志和历史）。
+推送内容为JSON：text 为一行文字说明（常见聊天机器人的webhook可以直接显示），event 为完整的告警。
+nil 的 *Notifier 可以安全调用，Notify 始终返回false。
+*/
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 告警级别
+const (
+	LevelInfo     = "info"
+	LevelWarning  = "warning"
+	LevelCritical = "critical"
+)
+
+// webhookQueueSize 等待推送的告警数上限
+const webhookQueueSize = 100
+
+// webhookTimeout 每次webhook推送的超时时间
+const webhookTimeout = 10 * time.Second
+
+// Event 一条告警
+type Event struct {
+	Time    time.Time          `json:"time"`
+	Kind    string             `json:"kind"`             // 告警类型，如 price_move、oi_jump
+	Symbol  string             `json:"symbol,omitempty"` // 交易对（与交易对无关的告警为空）
+	Level   string             `json:"level"`            // info / warning / critical
+	Message string             `json:"message"`          // 文字说明
+	Values  map[string]float64 `json:"values,omitempty"` // 触发告警的数值
+}
+
+// NotifierStats 告警统计
+type NotifierStats struct {
+	Sent       int64 `json:"sent"`       // 发出的告警数
+	Suppressed int64 `json:"suppressed"` // 冷却期内被忽略的告警数
+	Delivered  int64 `json:"delivered"`  // webhook推送成功数
+	Failed     int64 `json:"failed"`     // webhook推送失败数
+	Dropped    int64 `json:"dropped"`    // 推送队列满时丢弃数
+}
+
+// webhookPayload webhook推送内容
+type webhookPayload struct {
+	Text  string `json:"text"`
+	Event Event  `json:"event"`
+}
+
+// Notifier 告警通知
+type Notifier struct {
+	webhook    string
+	cooldown   time.Duration
+	httpClient *http.Client
+	queue      chan Event
+
+	mu      sync.Mutex
+	last    map[string]time.Time // kind/symbol -> 最近一次告警时间
+	history *utils.Ring[Event]
+	stats   NotifierStats
+}
+
+// NewNotifier 创建告警通知（未配置webhook时只写日志和历史）
+func NewNotifier(cfg config.AlertsConfig, proxyURL string) *Notifier {
+	n := &Notifier{
+		webhook:    cfg.WebhookURL,
+		cooldown:   time.Duration(cfg.CooldownMinutes) * time.Minute,
+		httpClient: &http.Client{Timeout: webhookTimeout},
+		queue:      make(chan Event, webhookQueueSize),
+		last:       make(map[string]time.Time),
+		history:    utils.NewRing[Event](cfg.History),
+	}
+	if proxyURL != "" {
+		if proxy, err := url.Parse(proxyURL); err != nil {
+			utils.Error("解析代理URL失败", zap.String("proxy", proxyURL), zap.Error(err))
+		} else {
+			n.httpClient.Transport = &http.Transport{Proxy: http.ProxyURL(proxy)}
+		}
+	}
+	return n
+}
+
+// Notify 发出一条告警：写入日志和历史，配置webhook时加入推送队列（冷却期内的重复告警被忽略，返回false）
+func (n *Notifier) Notify(event Event) bool {
+	if n == nil {
+		return false
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Level == "" {
+		event.Level = LevelWarning
+	}
+
+	n.mu.Lock()
+	key := event.Kind + "/" + event.Symbol
+	if last, ok := n.last[key]; ok && event.Time.Sub(last) < n.cooldown {
+		n.stats.Suppressed++
+		n.mu.Unlock()
+		return false
+	}
+	n.last[key] = event.Time
+	n.history.Push(event.Time, event)
+	n.stats.Sent++
+	n.mu.Unlock()
+
+	fields := []zap.Field{
+		zap.String("kind", event.Kind),
+		zap.String("symbol", event.Symbol),
+		zap.String("message", event.Message),
+		zap.Any("values", event.Values),
+	}
+	if event.Level == LevelInfo {
+		utils.Info("行情告警", fields...)
+	} else {
+		utils.Warn("行情告警", fields...)
+	}
+
+	if n.webhook != "" {
+		select {
+		case n.queue <- event:
+		default:
+			n.mu.Lock()
+			n.stats.Dropped++
+			n.mu.Unlock()
+			utils.Warn("告警推送队列已满，丢弃告警", zap.String("kind", event.Kind), zap.String("symbol", event.Symbol))
+		}
+	}
+	return true
+}
+
+// Run 推送webhook，直到ctx取消
+func (n *Notifier) Run(ctx context.Context) {
+	for {
+		select {
+		case event := <-n.queue:
+			err := n.post(ctx, event)
+			n.mu.Lock()
+			if err != nil {
+				n.stats.Failed++
+			} else {
+				n.stats.Delivered++
+			}
+			n.mu.Unlock()
+			if err != nil {
+				utils.Warn("推送告警失败", zap.String("kind", event.Kind), zap.String("symbol", event.Symbol), zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// post 把一条告警POST到webhook
+func (n *Notifier) post(ctx context.Context, event Event) error {
+	body, err := json.Marshal(webhookPayload{Text: Text(event), Event: event})
+	if err != nil {
+		return fmt.Errorf("序列化告警失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhook, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Text 告警的一行文字说明（如 "[warning] BTCUSDT 15m K线上涨 3.20%，为ATR的4.1倍"）
+func Text(event Event) string {
+	if event.Symbol == "" || bytes.Contains([]byte(event.Message), []byte(event.Symbol)) {
+		return fmt.Sprintf("[%s] %s", event.Level, event.Message)
+	}
+	return fmt.Sprintf("[%s] %s %s", event.Level, event.Symbol, event.Message)
+}
+
+// Recent 最近的告警（从新到旧，最多limit条，limit<=0时返回全部；kind、symbol为空时不过滤）
+func (n *Notifier) Recent(limit int, kind, symbol string) []Event {
+	if n == nil {
+		return nil
+	}
+	n.mu.Lock()
+	points := n.history.Newest(n.history.Len())
+	n.mu.Unlock()
+
+	events := make([]Event, 0, len(points))
+	for _, p := range points {
+		if (kind != "" && p.Value.Kind != kind) || (symbol != "" && p.Value.Symbol != symbol) {
+			continue
+		}
+		events = append(events, p.Value)
+		if limit > 0 && len(events) >= limit {
+			break
+		}
+	}
+	return events
+}
+
+// Stats 告警统计
+func (n *Notifier) Stats() NotifierStats {
+	if n == nil {
+		return NotifierStats{}
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.stats
+}
@@ -0,0 +1,34 @@
+/*
+Package alert 状态变化跟踪（只在状态改变时告警，状态持续期间不重复告警）
+
+主要功能：
+- NewStateTracker() *StateTracker                                          // 创建状态跟踪
+- (t *StateTracker) Update(key, state string) (previous string, changed bool)  // 记录最新状态，返回上一次的状态及是否改变
+
+第一次记录某个key时没有上一次的状态，不算改变（启动时不会对已有的状态告警）。
+多个账号共用交易对时同一周期内重复记录相同状态不算改变。
+*/
+package alert
+
+import "sync"
+
+// StateTracker 状态变化跟踪
+type StateTracker struct {
+	mu     sync.Mutex
+	states map[string]string
+}
+
+// NewStateTracker 创建状态跟踪
+func NewStateTracker() *StateTracker {
+	return &StateTracker{states: make(map[string]string)}
+}
+
+// Update 记录key的最新状态，返回上一次的状态及是否改变（第一次记录时changed为false）
+func (t *StateTracker) Update(key, state string) (previous string, changed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	previous, seen := t.states[key]
+	t.states[key] = state
+	return previous, seen && previous != state
+}
@@ -0,0 +1,168 @@
+/*
+Package backtest 交易图表数据导出
+
+主要功能：
+- TradeCharts(result *Result, klines []binance.Kline, opts ChartOptions) []TradeChart  // 生成每笔交易的图表数据
+- ExportTradeCharts(path string, charts []TradeChart) error                        // 图表数据保存为JSON文件
+
+每笔交易导出入场前到出场后的一段K线、入场/出场标记和止损止盈价位线，
+可直接交给前端K线图库绘制，用于逐笔复盘亏损交易。
+*/
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/journal"
+)
+
+// 图表标记类型
+const (
+	MarkerEntry = "entry"
+	MarkerExit  = "exit"
+)
+
+// ChartOptions 图表导出选项
+type ChartOptions struct {
+	Padding    int  // 入场前和出场后各保留的K线数量（默认20）
+	LosingOnly bool // 只导出净亏损的交易
+}
+
+// ChartCandle 图表K线
+type ChartCandle struct {
+	Time   int64   `json:"time"` // 开盘时间（毫秒）
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume float64 `json:"volume"`
+}
+
+// ChartMarker 入场/出场标记
+type ChartMarker struct {
+	Time  int64   `json:"time"`  // 时间（毫秒）
+	Price float64 `json:"price"` // 成交价
+	Type  string  `json:"type"`  // entry 或 exit
+	Side  string  `json:"side"`  // BUY 或 SELL（该笔订单的方向）
+	Label string  `json:"label"` // 标注文字（如 开多、止损）
+}
+
+// ChartLevel 价位线（持仓期间的止损价、止盈价）
+type ChartLevel struct {
+	Name  string  `json:"name"`  // stop_loss 或 take_profit
+	Price float64 `json:"price"` // 价格
+	From  int64   `json:"from"`  // 开始时间（毫秒）
+	To    int64   `json:"to"`    // 结束时间（毫秒）
+}
+
+// TradeChart 单笔交易的图表数据
+type TradeChart struct {
+	Index   int           `json:"index"`   // 在回测交易列表中的序号（从1开始）
+	Trade   journal.Trade `json:"trade"`   // 交易记录
+	Candles []ChartCandle `json:"candles"` // 入场前到出场后的K线
+	Markers []ChartMarker `json:"markers"` // 入场/出场标记
+	Levels  []ChartLevel  `json:"levels"`  // 止损止盈价位线
+}
+
+// TradeCharts 生成每笔交易的图表数据
+// klines: 回测使用的K线（与Run的输入相同）
+func TradeCharts(result *Result, klines []binance.Kline, opts ChartOptions) []TradeChart {
+	padding := opts.Padding
+	if padding <= 0 {
+		padding = 20
+	}
+
+	charts := make([]TradeChart, 0, len(result.Trades))
+	for i, trade := range result.Trades {
+		if opts.LosingOnly && trade.NetPnL >= 0 {
+			continue
+		}
+
+		entryIdx := klineIndex(klines, trade.EntryTime.UnixMilli())
+		exitIdx := klineIndex(klines, trade.ExitTime.UnixMilli())
+		from := max(entryIdx-padding, 0)
+		to := min(exitIdx+padding, len(klines)-1)
+
+		chart := TradeChart{
+			Index:   i + 1,
+			Trade:   trade,
+			Candles: make([]ChartCandle, 0, to-from+1),
+		}
+		for _, k := range klines[from : to+1] {
+			chart.Candles = append(chart.Candles, toChartCandle(k))
+		}
+
+		entryTime, exitTime := trade.EntryTime.UnixMilli(), trade.ExitTime.UnixMilli()
+		entryLabel := "开多"
+		if trade.Side == binance.SideSell {
+			entryLabel = "开空"
+		}
+		chart.Markers = []ChartMarker{
+			{Time: entryTime, Price: trade.EntryPrice, Type: MarkerEntry, Side: trade.Side, Label: entryLabel},
+			{Time: exitTime, Price: trade.ExitPrice, Type: MarkerExit, Side: closeSide(trade.Side), Label: closeReasonLabel(trade.CloseReason)},
+		}
+		if trade.StopLoss > 0 {
+			chart.Levels = append(chart.Levels, ChartLevel{Name: "stop_loss", Price: trade.StopLoss, From: entryTime, To: exitTime})
+		}
+		if trade.TakeProfit > 0 {
+			chart.Levels = append(chart.Levels, ChartLevel{Name: "take_profit", Price: trade.TakeProfit, From: entryTime, To: exitTime})
+		}
+
+		charts = append(charts, chart)
+	}
+	return charts
+}
+
+// ExportTradeCharts 图表数据保存为JSON文件（目录不存在时自动创建）
+func ExportTradeCharts(path string, charts []TradeChart) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	data, err := json.MarshalIndent(charts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化图表数据失败: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入图表数据失败: %w", err)
+	}
+	return nil
+}
+
+// klineIndex 开盘时间不晚于t的最后一根K线的下标（早于第一根时返回0）
+func klineIndex(klines []binance.Kline, t int64) int {
+	i := sort.Search(len(klines), func(i int) bool { return klines[i].OpenTime > t })
+	return max(i-1, 0)
+}
+
+// toChartCandle 转换为图表K线
+func toChartCandle(k binance.Kline) ChartCandle {
+	c := ChartCandle{Time: k.OpenTime}
+	c.Open, _ = strconv.ParseFloat(k.Open, 64)
+	c.High, _ = strconv.ParseFloat(k.High, 64)
+	c.Low, _ = strconv.ParseFloat(k.Low, 64)
+	c.Close, _ = strconv.ParseFloat(k.Close, 64)
+	c.Volume, _ = strconv.ParseFloat(k.Volume, 64)
+	return c
+}
+
+// closeReasonLabel 结束原因的标注文字
+func closeReasonLabel(reason string) string {
+	switch reason {
+	case executor.CloseReasonStopLoss:
+		return "止损"
+	case executor.CloseReasonTakeProfit:
+		return "止盈"
+	case CloseReasonEndOfData:
+		return "数据结束"
+	default:
+		return "平仓"
+	}
+}
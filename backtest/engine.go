@@ -0,0 +1,305 @@
+/*
+Package backtest 单交易对K线回测引擎
+
+主要功能：
+- Run(cfg Config, symbol string, klines []binance.Kline, funding []binance.FundingRate, decide DecideFunc) (*Result, error)  // 运行回测
+
+撮合规则：
+- 平仓决策在下一根K线开盘价成交
+- 开仓决策按成交模型撮合（延迟、排队、部分成交，见 fill.go），默认在下一根K线开盘价市价成交
+- 部分成交时持仓按已成交数量计算，入场价按成交数量加权平均，持仓结束时撤销剩余入场挂单
+- 止损止盈按K线最高/最低价判断，同一根K线同时触及时按止损处理（保守）
+- 跳空越过止损/止盈价时按开盘价成交
+- 手续费：入场挂单成交按Maker费率，吃单按Taker费率（市价入场按 EntryMaker 选择），出场（止损、止盈、平仓）按Taker费率
+- 资金费：持仓期间每个资金费结算时间按当时开盘价 × 数量 × 费率计算
+- 滑点：每次Taker成交（市价入场和全部出场）按不利方向加上 固定滑点 + 随机滑点
+- 随机数只来自按 Seed 创建的随机源，相同种子、配置和数据的回测结果完全一致
+*/
+package backtest
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/journal"
+)
+
+// 回测特有的结束原因
+const CloseReasonEndOfData = "end_of_data" // 回测数据结束时强制平仓
+
+// position 回测中的持仓
+type position struct {
+	side       string
+	qty        float64
+	entryPrice float64
+	stopLoss   float64
+	takeProfit float64
+	entryTime  time.Time
+	commission float64
+	funding    float64
+	decisionID string
+}
+
+// bar 解析后的K线
+type bar struct {
+	openTime  int64
+	closeTime int64
+	open      float64
+	high      float64
+	low       float64
+	close     float64
+	volume    float64
+}
+
+// Run 运行回测
+func Run(cfg Config, symbol string, klines []binance.Kline, funding []binance.FundingRate, decide DecideFunc) (*Result, error) {
+	if decide == nil {
+		return nil, fmt.Errorf("决策函数不能为空")
+	}
+	if len(klines) <= cfg.Warmup {
+		return nil, fmt.Errorf("K线数量不足: %d (预热需要 %d)", len(klines), cfg.Warmup)
+	}
+	if cfg.SlippagePct < 0 || cfg.SlippageJitterPct < 0 {
+		return nil, fmt.Errorf("滑点不能为负数: slippage_pct=%v slippage_jitter_pct=%v", cfg.SlippagePct, cfg.SlippageJitterPct)
+	}
+	// 未设置成交模型时使用默认模型
+	if cfg.Fill.EntryType == "" {
+		cfg.Fill = DefaultFillConfig()
+	}
+	if err := cfg.Fill.Validate(); err != nil {
+		return nil, err
+	}
+
+	bars, err := parseBars(klines)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	result := &Result{
+		Symbol:         symbol,
+		Seed:           cfg.Seed,
+		ConfigHash:     cfg.Hash(),
+		InitialBalance: cfg.InitialBalance,
+	}
+
+	// slip 按订单方向加上滑点后的成交价
+	slip := func(price float64, side string) float64 {
+		pct := cfg.SlippagePct
+		if cfg.SlippageJitterPct > 0 {
+			pct += rng.Float64() * cfg.SlippageJitterPct
+		}
+		if side == binance.SideBuy {
+			return price * (1 + pct/100)
+		}
+		return price * (1 - pct/100)
+	}
+
+	var (
+		pos          *position
+		order        *entryOrder
+		pendingOpen  *executor.Decision
+		pendingClose bool
+	)
+
+	// cancelOrder 撤销剩余入场挂单
+	cancelOrder := func() {
+		if order != nil {
+			order.cancel(&result.Fills)
+			order = nil
+		}
+	}
+	closePos := func(b bar, price float64, reason string) {
+		trade := closeTrade(cfg, symbol, pos, slip(price, closeSide(pos.side)), time.UnixMilli(b.openTime), reason)
+		result.Trades = append(result.Trades, *trade)
+		pos = nil
+		cancelOrder()
+	}
+
+	for i := cfg.Warmup; i < len(bars); i++ {
+		b := bars[i]
+
+		// 1. 执行上一根K线的决策：平仓按开盘价成交，开仓提交入场订单
+		if pendingClose {
+			if pos != nil {
+				closePos(b, b.open, executor.CloseReasonPositionClosed)
+			}
+			cancelOrder()
+		}
+		if pendingOpen != nil && pos == nil && order == nil {
+			order = newEntryOrder(cfg.Fill, pendingOpen, bars[i-1])
+			result.Fills.Orders++
+			result.Fills.RequestedQty += pendingOpen.Quantity
+		}
+		pendingOpen, pendingClose = nil, false
+
+		// 2. 入场订单撮合（可能跨多根K线部分成交）
+		if order != nil {
+			fills, done := order.match(cfg.Fill, b, &result.Fills)
+			for _, f := range fills {
+				price, maker := f.price, f.maker
+				if maker {
+					result.Fills.MakerQty += f.qty
+				} else {
+					result.Fills.TakerQty += f.qty
+					price = slip(price, order.side)
+					maker = cfg.EntryMaker && cfg.Fill.EntryType == config.EntryTypeMarket
+				}
+				pos = addFill(cfg, pos, order, f.qty, price, maker, b)
+			}
+			if done {
+				order = nil
+			}
+		}
+
+		// 3. 持仓期间的资金费和止损止盈
+		if pos != nil {
+			if cfg.IncludeFunding {
+				for j := range funding {
+					if funding[j].FundingTime > b.openTime && funding[j].FundingTime <= b.closeTime {
+						pos.funding += journal.FundingPayment(pos.side, pos.qty, b.open, funding[j].RateFloat())
+					}
+				}
+			}
+			if price, reason, hit := checkExit(pos, b); hit {
+				closePos(b, price, reason)
+			}
+		}
+
+		// 4. 当前K线收盘后做决策（最后一根K线不再决策）
+		if i == len(bars)-1 {
+			break
+		}
+		d := decide(symbol, klines[:i+1])
+		if d == nil {
+			continue
+		}
+		switch d.Action {
+		case executor.ActionOpenLong, executor.ActionOpenShort:
+			if pos == nil && order == nil && d.Quantity > 0 {
+				pendingOpen = d
+			}
+		case executor.ActionClose:
+			pendingClose = pos != nil || order != nil
+		}
+	}
+
+	// 数据结束时撤销剩余挂单，按最后收盘价平仓
+	cancelOrder()
+	if pos != nil {
+		last := bars[len(bars)-1]
+		trade := closeTrade(cfg, symbol, pos, slip(last.close, closeSide(pos.side)), time.UnixMilli(last.closeTime), CloseReasonEndOfData)
+		result.Trades = append(result.Trades, *trade)
+	}
+
+	result.Fills.finalize()
+
+	result.Summary = journal.Summarize(result.Trades)
+	result.FinalBalance = cfg.InitialBalance + result.Summary.NetPnL
+	result.Metrics = journal.CalculateMetrics(result.Trades, cfg.InitialBalance,
+		time.UnixMilli(bars[cfg.Warmup].openTime), time.UnixMilli(bars[len(bars)-1].closeTime))
+	return result, nil
+}
+
+// addFill 入场成交计入持仓（首笔成交时开仓，之后按成交数量加权平均入场价）
+func addFill(cfg Config, pos *position, o *entryOrder, qty, price float64, maker bool, b bar) *position {
+	if pos == nil {
+		pos = &position{
+			side:       o.side,
+			stopLoss:   o.decision.StopLoss,
+			takeProfit: o.decision.TakeProfit,
+			entryTime:  time.UnixMilli(b.openTime),
+			decisionID: o.decision.Hash(),
+		}
+	}
+	pos.entryPrice = (pos.entryPrice*pos.qty + price*qty) / (pos.qty + qty)
+	pos.qty += qty
+	pos.commission += cfg.Fees.Commission(qty*price, maker)
+	return pos
+}
+
+// closeSide 平仓订单方向
+func closeSide(side string) string {
+	if side == binance.SideBuy {
+		return binance.SideSell
+	}
+	return binance.SideBuy
+}
+
+// checkExit 检查K线是否触发止损或止盈
+// 返回：成交价、结束原因、是否触发
+func checkExit(pos *position, b bar) (float64, string, bool) {
+	if pos.side == binance.SideBuy {
+		if pos.stopLoss > 0 && b.low <= pos.stopLoss {
+			return min(pos.stopLoss, b.open), executor.CloseReasonStopLoss, true
+		}
+		if pos.takeProfit > 0 && b.high >= pos.takeProfit {
+			return max(pos.takeProfit, b.open), executor.CloseReasonTakeProfit, true
+		}
+		return 0, "", false
+	}
+
+	if pos.stopLoss > 0 && b.high >= pos.stopLoss {
+		return max(pos.stopLoss, b.open), executor.CloseReasonStopLoss, true
+	}
+	if pos.takeProfit > 0 && b.low <= pos.takeProfit {
+		return min(pos.takeProfit, b.open), executor.CloseReasonTakeProfit, true
+	}
+	return 0, "", false
+}
+
+// closeTrade 平仓并生成交易记录（出场按Taker费率）
+func closeTrade(cfg Config, symbol string, pos *position, price float64, exitTime time.Time, reason string) *journal.Trade {
+	trade := &journal.Trade{
+		Symbol:      symbol,
+		Side:        pos.side,
+		Quantity:    pos.qty,
+		EntryPrice:  pos.entryPrice,
+		ExitPrice:   price,
+		StopLoss:    pos.stopLoss,
+		TakeProfit:  pos.takeProfit,
+		EntryTime:   pos.entryTime,
+		ExitTime:    exitTime,
+		Commission:  pos.commission + cfg.Fees.Commission(pos.qty*price, false),
+		Funding:     pos.funding,
+		CloseReason: reason,
+		DecisionID:  pos.decisionID,
+	}
+	trade.Finalize()
+	return trade
+}
+
+// parseBars 解析K线价格
+func parseBars(klines []binance.Kline) ([]bar, error) {
+	bars := make([]bar, len(klines))
+	for i, k := range klines {
+		var err error
+		b := bar{openTime: k.OpenTime, closeTime: k.CloseTime}
+		if b.open, err = strconv.ParseFloat(k.Open, 64); err != nil {
+			return nil, fmt.Errorf("解析K线开盘价失败: %w", err)
+		}
+		if b.high, err = strconv.ParseFloat(k.High, 64); err != nil {
+			return nil, fmt.Errorf("解析K线最高价失败: %w", err)
+		}
+		if b.low, err = strconv.ParseFloat(k.Low, 64); err != nil {
+			return nil, fmt.Errorf("解析K线最低价失败: %w", err)
+		}
+		if b.close, err = strconv.ParseFloat(k.Close, 64); err != nil {
+			return nil, fmt.Errorf("解析K线收盘价失败: %w", err)
+		}
+		if b.volume, err = strconv.ParseFloat(k.Volume, 64); err != nil {
+			return nil, fmt.Errorf("解析K线成交量失败: %w", err)
+		}
+		bars[i] = b
+	}
+	return bars, nil
+}
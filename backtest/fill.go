@@ -0,0 +1,303 @@
+/*
+Package backtest 入场成交模型（下单延迟、限价单排队、跨K线部分成交）
+
+主要功能：
+- DefaultFillConfig() FillConfig  // 默认成交模型（市价、无延迟）
+- FillConfigFromExecution(exec config.ExecutionConfig, interval time.Duration) FillConfig  // 按实盘执行配置生成成交模型
+- (f FillConfig) Validate() error // 验证成交模型配置
+
+撮合规则（K线内没有逐笔数据，按以下近似处理）：
+- 延迟：订单在信号K线收盘后 latency_ms 到达交易所，到达前的K线不参与撮合
+- 市价单：在到达时所在K线按开盘价到收盘价的线性插值成交（无延迟时即下一根K线开盘价）
+- 限价单：挂单价 = 信号K线收盘价向有利方向偏移 limit_offset_pct
+- 吃单：到达时已穿过当前价格的限价单按当时价格成交（Taker），maker_first 则按当时价格重新挂单（Post Only 不会吃单）
+- 排队：挂单时前面排着信号K线成交量 × queue_ahead_pct 的数量
+- 部分成交：价格触及挂单价时，该价位成交量（K线成交量 × fill_volume_pct）先消耗前面的排队，剩余部分才轮到自己
+- 越价：价格越过挂单价超过 trade_through_pct 时，该价位的挂单全部成交
+- 超时：挂单 timeout_bars 根K线后未完全成交，maker_first 按最新收盘价重新挂单（重新排队）
+- 补齐：改价次数用完或普通限价单超时后，按 fallback 市价补齐（Taker）或放弃剩余数量
+
+到达时间落在K线中间时，该K线可参与的成交量按剩余时间折算。
+*/
+package backtest
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/executor"
+)
+
+// FillConfig 入场成交模型配置
+type FillConfig struct {
+	EntryType       string  // 入场方式：market、limit 或 maker_first（与执行配置的 entry_type 相同）
+	LatencyMs       int64   // 下单延迟（毫秒）
+	LimitOffsetPct  float64 // 限价挂单价相对信号K线收盘价向有利方向的偏移（%，0表示挂在收盘价）
+	TimeoutBars     int     // 限价单每次挂单等待的K线数
+	MaxReprices     int     // maker_first 最多重新挂单次数
+	Fallback        string  // 超时后未成交部分：market（市价补齐）或 none（放弃）
+	QueueAheadPct   float64 // 挂单时排在前面的数量（占信号K线成交量的%）
+	FillVolumePct   float64 // 价格触及挂单价时该价位的成交量（占K线成交量的%）
+	TradeThroughPct float64 // 价格越过挂单价超过该比例（%）时挂单全部成交
+}
+
+// DefaultFillConfig 默认成交模型（市价、无延迟，与下一根K线开盘价成交一致）
+func DefaultFillConfig() FillConfig {
+	return FillConfig{
+		EntryType:       config.EntryTypeMarket,
+		TimeoutBars:     3,
+		Fallback:        config.FallbackMarket,
+		QueueAheadPct:   5,
+		FillVolumePct:   10,
+		TradeThroughPct: 0.02,
+	}
+}
+
+// FillConfigFromExecution 按实盘执行配置生成成交模型
+// interval: K线周期，limit_timeout_sec 按K线数向上取整（至少1根）
+func FillConfigFromExecution(exec config.ExecutionConfig, interval time.Duration) FillConfig {
+	f := DefaultFillConfig()
+	if exec.EntryType == "" || exec.EntryType == config.EntryTypeMarket {
+		return f
+	}
+
+	f.EntryType = exec.EntryType
+	f.MaxReprices = exec.MaxReprices
+	if exec.Fallback != "" {
+		f.Fallback = exec.Fallback
+	}
+	if interval > 0 && exec.LimitTimeoutSec > 0 {
+		timeout := time.Duration(exec.LimitTimeoutSec) * time.Second
+		f.TimeoutBars = max(int((timeout+interval-1)/interval), 1)
+	}
+	// GTX限价单不会吃单，按不改价的 maker_first 处理
+	if exec.EntryType == config.EntryTypeLimit && exec.TimeInForce == "GTX" {
+		f.EntryType = config.EntryTypeMakerFirst
+		f.MaxReprices = 0
+	}
+	return f
+}
+
+// Validate 验证成交模型配置
+func (f FillConfig) Validate() error {
+	switch f.EntryType {
+	case config.EntryTypeMarket:
+	case config.EntryTypeLimit, config.EntryTypeMakerFirst:
+		if f.TimeoutBars <= 0 {
+			return fmt.Errorf("限价入场的timeout_bars必须大于0")
+		}
+		if f.Fallback != config.FallbackMarket && f.Fallback != config.FallbackNone {
+			return fmt.Errorf("fallback无效: %s (必须是 market 或 none)", f.Fallback)
+		}
+	default:
+		return fmt.Errorf("entry_type无效: %s (必须是 market、limit 或 maker_first)", f.EntryType)
+	}
+	if f.LatencyMs < 0 || f.LimitOffsetPct < 0 || f.MaxReprices < 0 {
+		return fmt.Errorf("latency_ms、limit_offset_pct和max_reprices不能为负数")
+	}
+	if f.QueueAheadPct < 0 || f.FillVolumePct < 0 || f.FillVolumePct > 100 || f.TradeThroughPct < 0 {
+		return fmt.Errorf("queue_ahead_pct、trade_through_pct不能为负数，fill_volume_pct必须在0-100之间")
+	}
+	return nil
+}
+
+// FillStats 入场成交统计
+type FillStats struct {
+	Orders        int     `json:"orders"`         // 入场订单数
+	RequestedQty  float64 `json:"requested_qty"`  // 请求数量合计
+	MakerQty      float64 `json:"maker_qty"`      // Maker成交数量
+	TakerQty      float64 `json:"taker_qty"`      // Taker成交数量（市价单、吃单的限价单、市价补齐）
+	UnfilledQty   float64 `json:"unfilled_qty"`   // 未成交放弃的数量（含止损、平仓、数据结束时撤销的剩余挂单）
+	PartialOrders int     `json:"partial_orders"` // 分多根K线成交或只部分成交的订单数
+	Reprices      int     `json:"reprices"`       // maker_first 重新挂单次数
+	Fallbacks     int     `json:"fallbacks"`      // 市价补齐次数
+	FillRate      float64 `json:"fill_rate"`      // 成交数量 / 请求数量
+	MakerRatio    float64 `json:"maker_ratio"`    // Maker成交数量 / 成交数量
+}
+
+// fill 一笔成交
+type fill struct {
+	qty   float64
+	price float64
+	maker bool
+}
+
+// entryOrder 撮合中的入场订单
+type entryOrder struct {
+	decision   *executor.Decision
+	side       string
+	remaining  float64
+	market     bool    // 市价单（含市价补齐）
+	price      float64 // 当前挂单价（限价单）
+	activeAt   int64   // 订单到达交易所的时间（毫秒）
+	queueAhead float64 // 排在前面的数量
+	bars       int     // 当前挂单已参与撮合的K线数
+	reprices   int     // 已重新挂单次数
+	fillBars   int     // 有成交的K线数
+}
+
+// newEntryOrder 按信号K线创建入场订单（在信号K线收盘时提交）
+func newEntryOrder(f FillConfig, d *executor.Decision, signal bar) *entryOrder {
+	side := binance.SideBuy
+	if d.Action == executor.ActionOpenShort {
+		side = binance.SideSell
+	}
+
+	o := &entryOrder{
+		decision:  d,
+		side:      side,
+		remaining: d.Quantity,
+		market:    f.EntryType == config.EntryTypeMarket,
+	}
+	o.post(f, signal)
+	return o
+}
+
+// post 按K线收盘价（重新）挂单，订单延迟后到达交易所
+func (o *entryOrder) post(f FillConfig, b bar) {
+	o.activeAt = b.closeTime + 1 + f.LatencyMs
+	o.bars = 0
+	if o.market {
+		return
+	}
+	o.price = limitPrice(o.side, b.close, f.LimitOffsetPct)
+	o.queueAhead = b.volume * f.QueueAheadPct / 100
+}
+
+// match 按一根K线撮合订单
+// 返回：本根K线的成交、订单是否结束（全部成交或放弃剩余数量）
+func (o *entryOrder) match(f FillConfig, b bar, stats *FillStats) ([]fill, bool) {
+	if o.activeAt > b.closeTime {
+		return nil, false
+	}
+
+	// 到达时间在K线内时，按剩余时间折算可参与的成交量，到达时的价格按开盘到收盘线性插值
+	duration := float64(b.closeTime - b.openTime + 1)
+	remainFrac, arrival := 1.0, b.open
+	if o.activeAt > b.openTime {
+		elapsed := float64(o.activeAt-b.openTime) / duration
+		remainFrac = 1 - elapsed
+		arrival = b.open + (b.close-b.open)*elapsed
+	}
+
+	var fills []fill
+	if o.market {
+		fills = append(fills, o.take(o.remaining, arrival, false))
+		return fills, o.finish(stats)
+	}
+
+	// 到达时限价单已穿过对手价：limit 按当时价格吃单，maker_first 按当时价格重新挂单
+	if o.bars == 0 && crosses(o.side, o.price, arrival) {
+		if f.EntryType == config.EntryTypeLimit {
+			fills = append(fills, o.take(o.remaining, arrival, false))
+			return fills, o.finish(stats)
+		}
+		o.price = arrival
+	}
+	o.bars++
+
+	if touched(o.side, o.price, b) {
+		qty := o.remaining
+		if !tradedThrough(o.side, o.price, f.TradeThroughPct, b) {
+			available := b.volume * remainFrac * f.FillVolumePct / 100
+			consumed := min(available, o.queueAhead)
+			o.queueAhead -= consumed
+			qty = min(available-consumed, o.remaining)
+		}
+		if qty > 0 {
+			fills = append(fills, o.take(qty, o.price, true))
+		}
+	}
+	if o.done() {
+		return fills, o.finish(stats)
+	}
+
+	// 超时：maker_first 重新挂单，否则按fallback处理
+	if o.bars >= f.TimeoutBars {
+		switch {
+		case f.EntryType == config.EntryTypeMakerFirst && o.reprices < f.MaxReprices:
+			o.reprices++
+			stats.Reprices++
+			o.post(f, b)
+		case f.Fallback == config.FallbackMarket:
+			o.market = true
+			stats.Fallbacks++
+			o.post(f, b)
+		default:
+			stats.UnfilledQty += o.remaining
+			return fills, o.finish(stats)
+		}
+	}
+	return fills, false
+}
+
+// take 成交指定数量
+func (o *entryOrder) take(qty, price float64, maker bool) fill {
+	o.remaining -= qty
+	o.fillBars++
+	return fill{qty: qty, price: price, maker: maker}
+}
+
+// done 剩余数量是否可以忽略
+func (o *entryOrder) done() bool {
+	return o.remaining <= o.decision.Quantity*1e-9
+}
+
+// finish 订单结束，计入部分成交统计
+func (o *entryOrder) finish(stats *FillStats) bool {
+	if o.fillBars > 1 || (o.fillBars == 1 && !o.done()) {
+		stats.PartialOrders++
+	}
+	return true
+}
+
+// cancel 撤销剩余挂单（止损、平仓、数据结束）
+func (o *entryOrder) cancel(stats *FillStats) {
+	stats.UnfilledQty += o.remaining
+	o.finish(stats)
+}
+
+// limitPrice 限价挂单价（做多低于收盘价，做空高于收盘价）
+func limitPrice(side string, close, offsetPct float64) float64 {
+	if side == binance.SideBuy {
+		return close * (1 - offsetPct/100)
+	}
+	return close * (1 + offsetPct/100)
+}
+
+// crosses 限价是否已穿过当前价格（买单高于当前价、卖单低于当前价会立即吃单）
+func crosses(side string, limit, price float64) bool {
+	if side == binance.SideBuy {
+		return limit >= price
+	}
+	return limit <= price
+}
+
+// touched K线是否触及挂单价
+func touched(side string, limit float64, b bar) bool {
+	if side == binance.SideBuy {
+		return b.low <= limit
+	}
+	return b.high >= limit
+}
+
+// tradedThrough K线是否越过挂单价超过 throughPct
+func tradedThrough(side string, limit, throughPct float64, b bar) bool {
+	if side == binance.SideBuy {
+		return b.low < limit*(1-throughPct/100)
+	}
+	return b.high > limit*(1+throughPct/100)
+}
+
+// finalize 计算成交率和Maker占比
+func (s *FillStats) finalize() {
+	filled := s.MakerQty + s.TakerQty
+	if s.RequestedQty > 0 {
+		s.FillRate = round4(filled / s.RequestedQty)
+	}
+	if filled > 0 {
+		s.MakerRatio = round4(s.MakerQty / filled)
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Package backtest 蒙特卡洛稳健性分析
+
+主要功能：
+- DefaultMonteCarloConfig() MonteCarloConfig                                       // 默认模拟配置（1000次，跳过10%，滑点0.05%）
+- MonteCarlo(result *Result, cfg MonteCarloConfig) (*MonteCarloResult, error)       // 对回测交易序列重采样，输出收益率和最大回撤分布
+
+单次回测只是众多可能路径中的一条，参数可能只是碰上了好运气的交易顺序。每次模拟：
+1. 打乱交易顺序（收益不变，回撤随顺序变化）
+2. 每笔交易按 SkipProb 的概率跳过（模拟错过信号、下单失败）
+3. 每笔交易入场和出场各扣除 [0, SlippagePct] 的随机滑点（按名义价值）
+汇总所有路径的收益率和最大回撤分布，与原始路径对比。
+*/
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"crypto-ai-trader/journal"
+)
+
+// MonteCarloConfig 蒙特卡洛模拟配置
+type MonteCarloConfig struct {
+	Iterations  int     // 模拟次数
+	SkipProb    float64 // 每笔交易被跳过的概率（0-1）
+	SlippagePct float64 // 入场和出场各自的最大随机滑点（%）
+	Seed        int64   // 随机种子（0表示沿用回测结果的种子，固定种子可复现结果）
+}
+
+// DefaultMonteCarloConfig 默认模拟配置（1000次，跳过10%，滑点0.05%）
+func DefaultMonteCarloConfig() MonteCarloConfig {
+	return MonteCarloConfig{
+		Iterations:  1000,
+		SkipProb:    0.1,
+		SlippagePct: 0.05,
+	}
+}
+
+// Distribution 模拟结果分布
+type Distribution struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+	Min    float64 `json:"min"`
+	P5     float64 `json:"p5"`
+	P25    float64 `json:"p25"`
+	Median float64 `json:"median"`
+	P75    float64 `json:"p75"`
+	P95    float64 `json:"p95"`
+	Max    float64 `json:"max"`
+}
+
+// MonteCarloResult 蒙特卡洛分析结果
+type MonteCarloResult struct {
+	Iterations          int          `json:"iterations"`            // 模拟次数
+	Seed                int64        `json:"seed"`                  // 实际使用的随机种子
+	Trades              int          `json:"trades"`                // 原始交易笔数
+	OriginalReturn      float64      `json:"original_return"`       // 原始路径收益率（%）
+	OriginalMaxDrawdown float64      `json:"original_max_drawdown"` // 原始路径最大回撤（%）
+	Return              Distribution `json:"return"`                // 收益率分布（%）
+	MaxDrawdown         Distribution `json:"max_drawdown"`          // 最大回撤分布（%）
+	ProbLoss            float64      `json:"prob_loss"`             // 最终亏损的路径比例（0-1）
+	ProbWorseDrawdown   float64      `json:"prob_worse_drawdown"`   // 最大回撤超过原始路径的比例（0-1）
+}
+
+// MonteCarlo 对回测交易序列重采样，输出收益率和最大回撤分布
+func MonteCarlo(result *Result, cfg MonteCarloConfig) (*MonteCarloResult, error) {
+	if result == nil || len(result.Trades) == 0 {
+		return nil, fmt.Errorf("回测没有交易，无法进行蒙特卡洛分析")
+	}
+	if result.InitialBalance <= 0 {
+		return nil, fmt.Errorf("初始资金必须大于0")
+	}
+	if cfg.Iterations <= 0 {
+		return nil, fmt.Errorf("模拟次数必须大于0")
+	}
+	if cfg.SkipProb < 0 || cfg.SkipProb >= 1 || cfg.SlippagePct < 0 {
+		return nil, fmt.Errorf("模拟参数无效: skip_prob=%v slippage_pct=%v", cfg.SkipProb, cfg.SlippagePct)
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = result.Seed
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	n := len(result.Trades)
+	pnls := make([]float64, n)
+	entryNotional := make([]float64, n)
+	exitNotional := make([]float64, n)
+	for i, t := range result.Trades {
+		pnls[i] = t.NetPnL
+		entryNotional[i] = t.Quantity * t.EntryPrice
+		exitNotional[i] = t.Quantity * t.ExitPrice
+	}
+
+	initial := result.InitialBalance
+	mc := &MonteCarloResult{
+		Iterations:          cfg.Iterations,
+		Seed:                seed,
+		Trades:              n,
+		OriginalReturn:      round4(sum(pnls) / initial * 100),
+		OriginalMaxDrawdown: round4(journal.MaxDrawdown(initial, pnls)),
+	}
+
+	returns := make([]float64, cfg.Iterations)
+	drawdowns := make([]float64, cfg.Iterations)
+	order := make([]int, n)
+	path := make([]float64, 0, n)
+	losses, worse := 0, 0
+	for it := 0; it < cfg.Iterations; it++ {
+		for i := range order {
+			order[i] = i
+		}
+		rng.Shuffle(n, func(i, j int) { order[i], order[j] = order[j], order[i] })
+
+		path = path[:0]
+		for _, idx := range order {
+			if rng.Float64() < cfg.SkipProb {
+				continue
+			}
+			slippage := (entryNotional[idx]*rng.Float64() + exitNotional[idx]*rng.Float64()) * cfg.SlippagePct / 100
+			path = append(path, pnls[idx]-slippage)
+		}
+
+		returns[it] = sum(path) / initial * 100
+		drawdowns[it] = journal.MaxDrawdown(initial, path)
+		if returns[it] < 0 {
+			losses++
+		}
+		if drawdowns[it] > mc.OriginalMaxDrawdown {
+			worse++
+		}
+	}
+
+	mc.Return = distribution(returns)
+	mc.MaxDrawdown = distribution(drawdowns)
+	mc.ProbLoss = round4(float64(losses) / float64(cfg.Iterations))
+	mc.ProbWorseDrawdown = round4(float64(worse) / float64(cfg.Iterations))
+
+	return mc, nil
+}
+
+// distribution 计算均值、标准差和分位数（会对values排序）
+func distribution(values []float64) Distribution {
+	sort.Float64s(values)
+
+	n := float64(len(values))
+	mean := sum(values) / n
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+
+	return Distribution{
+		Mean:   round4(mean),
+		StdDev: round4(math.Sqrt(variance / n)),
+		Min:    round4(values[0]),
+		P5:     round4(percentile(values, 5)),
+		P25:    round4(percentile(values, 25)),
+		Median: round4(percentile(values, 50)),
+		P75:    round4(percentile(values, 75)),
+		P95:    round4(percentile(values, 95)),
+		Max:    round4(values[len(values)-1]),
+	}
+}
+
+// percentile 已排序数据的分位数（线性插值）
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := p / 100 * float64(len(sorted)-1)
+	lower := int(pos)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lower)
+	return sorted[lower] + (sorted[lower+1]-sorted[lower])*frac
+}
+
+// sum 求和
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// round4 保留四位小数
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,312 @@
+/*
+Package backtest 参数网格搜索
+
+主要功能：
+- Grid(ranges map[string]config.ParamRange) []Params                      // 展开全部参数组合（按参数名排序，结果确定）
+- Optimize(cfg config.OptimizeConfig, klines []binance.Kline, funding []binance.FundingRate) (*OptimizeResult, error)  // 并行回测全部参数组合并排序
+- WriteTable(w io.Writer, result *OptimizeResult, top int)               // 输出排名表
+
+K线按 in_sample_pct 切分为样本内和样本外两段，每组参数分别回测：
+按样本内指标排序（只用样本内数据选参数），样本外结果用于判断是否过拟合。
+每组参数的过拟合警告：
+- 样本内交易笔数不足 min_trades（结果主要是噪声）
+- 样本内盈利、样本外亏损
+- 样本外每根K线收益不到样本内的一半
+整体警告：样本内外得分的排名相关系数低于0.3时，样本内排名靠前的参数在样本外没有优势。
+全部组合使用同一个随机种子，结果记录种子和配置哈希，相同种子、配置和数据可完全复现排名。
+*/
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+)
+
+// minRankCorrelation 样本内外排名相关系数低于该值时输出整体过拟合警告
+const minRankCorrelation = 0.3
+
+// RunMetrics 单次回测指标
+type RunMetrics struct {
+	Trades         int     `json:"trades"`           // 交易笔数
+	NetPnL         float64 `json:"net_pnl"`          // 净盈亏
+	ReturnPct      float64 `json:"return_pct"`       // 收益率（%）
+	MaxDrawdownPct float64 `json:"max_drawdown_pct"` // 最大回撤（%，按交易结束顺序）
+	WinRate        float64 `json:"win_rate"`         // 胜率（0-1）
+	Score          float64 `json:"score"`            // 排序得分（按rank_by计算）
+}
+
+// OptimizeRow 一组参数的回测结果
+type OptimizeRow struct {
+	Params      Params     `json:"params"`
+	InSample    RunMetrics `json:"in_sample"`
+	OutOfSample RunMetrics `json:"out_of_sample"`
+	Warnings    []string   `json:"warnings,omitempty"`
+	Err         string     `json:"error,omitempty"` // 回测失败的原因（失败的组合排在最后）
+}
+
+// OptimizeResult 网格搜索结果
+type OptimizeResult struct {
+	Strategy        string        `json:"strategy"`
+	Symbol          string        `json:"symbol"`
+	RankBy          string        `json:"rank_by"`
+	Seed            int64         `json:"seed"`               // 实际使用的随机种子
+	ConfigHash      string        `json:"config_hash"`        // 参数优化配置和回测配置的哈希
+	InSampleBars    int           `json:"in_sample_bars"`     // 样本内K线数量
+	OutOfSampleBars int           `json:"out_of_sample_bars"` // 样本外K线数量
+	Rows            []OptimizeRow `json:"rows"`               // 按样本内得分从高到低
+	RankCorrelation float64       `json:"rank_correlation"`   // 样本内外得分的Spearman排名相关系数
+	Warnings        []string      `json:"warnings,omitempty"` // 整体警告
+}
+
+// Grid 展开全部参数组合（按参数名排序，结果确定）
+func Grid(ranges map[string]config.ParamRange) []Params {
+	names := make([]string, 0, len(ranges))
+	for name := range ranges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	grid := []Params{{}}
+	for _, name := range names {
+		values := ranges[name].Expand()
+		next := make([]Params, 0, len(grid)*len(values))
+		for _, base := range grid {
+			for _, v := range values {
+				p := make(Params, len(base)+1)
+				for k, bv := range base {
+					p[k] = bv
+				}
+				p[name] = v
+				next = append(next, p)
+			}
+		}
+		grid = next
+	}
+	return grid
+}
+
+// Optimize 并行回测全部参数组合，按样本内得分排序
+func Optimize(cfg config.OptimizeConfig, klines []binance.Kline, funding []binance.FundingRate) (*OptimizeResult, error) {
+	factory, err := GetDecideFactory(cfg.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	btCfg := DefaultConfig()
+	if cfg.InitialBalance > 0 {
+		btCfg.InitialBalance = cfg.InitialBalance
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	btCfg.Seed = cfg.Seed
+
+	// 样本外回测带上切分点之前的预热K线，保证从切分点开始就能决策
+	split := int(float64(len(klines)) * cfg.InSamplePct / 100)
+	if split <= btCfg.Warmup || len(klines)-split < btCfg.Warmup {
+		return nil, fmt.Errorf("K线数量不足以切分样本内外: %d (每段至少 %d)", len(klines), btCfg.Warmup)
+	}
+	inSample := klines[:split]
+	outOfSample := klines[split-btCfg.Warmup:]
+
+	grid := Grid(cfg.Params)
+	rows := make([]OptimizeRow, len(grid))
+
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				rows[i] = runCombination(cfg, btCfg, factory, grid[i], inSample, outOfSample, funding)
+			}
+		}()
+	}
+	for i := range grid {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if (rows[i].Err == "") != (rows[j].Err == "") {
+			return rows[i].Err == ""
+		}
+		return rows[i].InSample.Score > rows[j].InSample.Score
+	})
+
+	result := &OptimizeResult{
+		Strategy: cfg.Strategy,
+		Symbol:   cfg.Symbol,
+		RankBy:   cfg.RankBy,
+		Seed:     cfg.Seed,
+		ConfigHash: hashJSON(struct {
+			Optimize config.OptimizeConfig
+			Backtest Config
+		}{cfg, btCfg}),
+		InSampleBars:    split,
+		OutOfSampleBars: len(klines) - split,
+		Rows:            rows,
+	}
+	result.RankCorrelation = rankCorrelation(rows)
+	if len(rows) >= 5 && result.RankCorrelation < minRankCorrelation {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"样本内外排名相关系数仅 %.2f，样本内排名靠前的参数在样本外没有优势，结果可能是过拟合", result.RankCorrelation))
+	}
+	return result, nil
+}
+
+// runCombination 回测一组参数的样本内和样本外
+func runCombination(cfg config.OptimizeConfig, btCfg Config, factory DecideFactory, params Params,
+	inSample, outOfSample []binance.Kline, funding []binance.FundingRate) OptimizeRow {
+	row := OptimizeRow{Params: params}
+
+	// 决策函数可能带状态，两段分别创建
+	var metrics [2]RunMetrics
+	for i, klines := range [][]binance.Kline{inSample, outOfSample} {
+		decide, err := factory(params)
+		if err != nil {
+			row.Err = err.Error()
+			return row
+		}
+		result, err := Run(btCfg, cfg.Symbol, klines, funding, decide)
+		if err != nil {
+			row.Err = err.Error()
+			return row
+		}
+		metrics[i] = runMetrics(result, cfg.RankBy)
+	}
+	row.InSample, row.OutOfSample = metrics[0], metrics[1]
+
+	inBars := float64(len(inSample) - btCfg.Warmup)
+	outBars := float64(len(outOfSample) - btCfg.Warmup)
+	if row.InSample.Trades < cfg.MinTrades {
+		row.Warnings = append(row.Warnings, fmt.Sprintf("样本内仅%d笔交易", row.InSample.Trades))
+	}
+	if row.InSample.NetPnL > 0 && row.OutOfSample.NetPnL < 0 {
+		row.Warnings = append(row.Warnings, "样本外亏损")
+	} else if row.InSample.NetPnL > 0 && row.OutOfSample.ReturnPct/outBars < row.InSample.ReturnPct/inBars/2 {
+		row.Warnings = append(row.Warnings, "样本外收益衰减超过50%")
+	}
+	return row
+}
+
+// runMetrics 计算回测指标和排序得分
+func runMetrics(result *Result, rankBy string) RunMetrics {
+	m := RunMetrics{
+		Trades:         result.Summary.Trades,
+		NetPnL:         round4(result.Summary.NetPnL),
+		ReturnPct:      result.Metrics.TotalReturnPct,
+		MaxDrawdownPct: result.Metrics.MaxDrawdownPct,
+		WinRate:        result.Metrics.WinRate,
+	}
+
+	switch rankBy {
+	case config.RankByNetPnL:
+		m.Score = m.NetPnL
+	case config.RankByWinRate:
+		m.Score = m.WinRate
+	default:
+		// 回撤低于1%时按1%计，避免交易很少的组合因回撤接近0得分虚高
+		m.Score = round4(m.ReturnPct / math.Max(m.MaxDrawdownPct, 1))
+	}
+	return m
+}
+
+// rankCorrelation 成功组合的样本内外得分Spearman排名相关系数
+func rankCorrelation(rows []OptimizeRow) float64 {
+	var in, out []float64
+	for _, row := range rows {
+		if row.Err == "" {
+			in = append(in, row.InSample.Score)
+			out = append(out, row.OutOfSample.Score)
+		}
+	}
+	n := len(in)
+	if n < 2 {
+		return 0
+	}
+
+	rankIn, rankOut := ranks(in), ranks(out)
+	mean := float64(n-1) / 2
+	var cov, varIn, varOut float64
+	for i := 0; i < n; i++ {
+		a, b := rankIn[i]-mean, rankOut[i]-mean
+		cov += a * b
+		varIn += a * a
+		varOut += b * b
+	}
+	if varIn == 0 || varOut == 0 {
+		return 0
+	}
+	return round4(cov / math.Sqrt(varIn*varOut))
+}
+
+// ranks 计算排名（从0开始，相同值取平均排名）
+func ranks(values []float64) []float64 {
+	idx := make([]int, len(values))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return values[idx[a]] < values[idx[b]] })
+
+	result := make([]float64, len(values))
+	for i := 0; i < len(idx); {
+		j := i
+		for j+1 < len(idx) && values[idx[j+1]] == values[idx[i]] {
+			j++
+		}
+		avg := float64(i+j) / 2
+		for k := i; k <= j; k++ {
+			result[idx[k]] = avg
+		}
+		i = j + 1
+	}
+	return result
+}
+
+// WriteTable 输出排名表（top为0时输出全部）
+func WriteTable(w io.Writer, result *OptimizeResult, top int) {
+	fmt.Fprintf(w, "策略: %s  交易对: %s  排序: %s  样本内: %d根K线  样本外: %d根K线  排名相关系数: %.2f\n",
+		result.Strategy, result.Symbol, result.RankBy, result.InSampleBars, result.OutOfSampleBars, result.RankCorrelation)
+	fmt.Fprintf(w, "随机种子: %d  配置哈希: %s\n\n", result.Seed, result.ConfigHash)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "排名\t参数\t内:笔数\t内:收益%\t内:回撤%\t内:得分\t外:笔数\t外:收益%\t外:回撤%\t外:得分\t警告")
+	for i, row := range result.Rows {
+		if top > 0 && i >= top {
+			break
+		}
+		if row.Err != "" {
+			fmt.Fprintf(tw, "%d\t%s\t-\t-\t-\t-\t-\t-\t-\t-\t回测失败: %s\n", i+1, row.Params, row.Err)
+			continue
+		}
+		in, out := row.InSample, row.OutOfSample
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%.2f\t%.2f\t%.2f\t%d\t%.2f\t%.2f\t%.2f\t%s\n",
+			i+1, row.Params,
+			in.Trades, in.ReturnPct, in.MaxDrawdownPct, in.Score,
+			out.Trades, out.ReturnPct, out.MaxDrawdownPct, out.Score,
+			strings.Join(row.Warnings, "；"),
+		)
+	}
+	tw.Flush()
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(w, "\n警告: %s\n", warning)
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Package backtest 可参数化的回测决策函数
+
+主要功能：
+- GetDecideFactory(name string) (DecideFactory, error)  // 按名称获取决策函数工厂
+- EMACross(params Params) (DecideFunc, error)           // EMA交叉 + RSI过滤，ATR止损止盈，按风险金额计算数量
+- RandomDecide(params Params) (DecideFunc, error)       // 按种子随机开仓（模拟AI决策，用于基准对比）
+
+参数优化按参数组合调用工厂生成决策函数，参数缺失时使用默认值，未知参数名直接报错（避免配置拼写错误被忽略）。
+带随机性的决策函数必须使用参数中的种子创建随机源，每次调用工厂都从同一种子重新开始，保证回测可复现。
+*/
+package backtest
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/indicators"
+
+	"github.com/markcheno/go-talib"
+)
+
+// Params 一组参数（参数名 -> 取值）
+type Params map[string]float64
+
+// String 按参数名排序输出（如 ema_fast=9 ema_slow=21）
+func (p Params) String() string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%g", name, p[name])
+	}
+	return strings.Join(parts, " ")
+}
+
+// DecideFactory 按参数创建决策函数
+type DecideFactory func(params Params) (DecideFunc, error)
+
+// factories 内置决策函数工厂
+var factories = map[string]DecideFactory{
+	"ema_cross": EMACross,
+	"random":    RandomDecide,
+}
+
+// GetDecideFactory 按名称获取决策函数工厂
+func GetDecideFactory(name string) (DecideFactory, error) {
+	factory, ok := factories[name]
+	if !ok {
+		return nil, fmt.Errorf("未知的回测决策函数: %s", name)
+	}
+	return factory, nil
+}
+
+// EMACross EMA交叉 + RSI过滤
+// 快线上穿慢线且RSI低于rsi_long_max时开多，下穿且RSI高于rsi_short_min时开空，
+// 止损止盈按 ATR × stop_atr / target_atr，数量 = risk_usdt / 止损距离
+func EMACross(params Params) (DecideFunc, error) {
+	p := Params{
+		"ema_fast":      9,
+		"ema_slow":      21,
+		"rsi_period":    14,
+		"rsi_long_max":  70,
+		"rsi_short_min": 30,
+		"atr_period":    14,
+		"stop_atr":      1.5,
+		"target_atr":    3,
+		"risk_usdt":     100,
+	}
+	for name, value := range params {
+		if _, ok := p[name]; !ok {
+			return nil, fmt.Errorf("ema_cross不支持参数: %s", name)
+		}
+		p[name] = value
+	}
+
+	fast, slow := int(p["ema_fast"]), int(p["ema_slow"])
+	rsiPeriod, atrPeriod := int(p["rsi_period"]), int(p["atr_period"])
+	if fast <= 0 || slow <= fast || rsiPeriod <= 0 || atrPeriod <= 0 {
+		return nil, fmt.Errorf("ema_cross参数无效: 需要 0 < ema_fast < ema_slow，周期大于0")
+	}
+	levelParams := indicators.LevelParams{StopATR: p["stop_atr"], TargetATR: p["target_atr"]}
+	risk := p["risk_usdt"]
+
+	// EMA只取最近一段K线计算，避免每根K线都从头计算全部历史
+	window := max(slow*4, 200)
+
+	return func(symbol string, history []binance.Kline) *executor.Decision {
+		if len(history) < slow+2 || len(history) < atrPeriod+1 {
+			return nil
+		}
+		recent := history[max(0, len(history)-window):]
+		closes := make([]float64, len(recent))
+		for i, k := range recent {
+			closes[i], _ = strconv.ParseFloat(k.Close, 64)
+		}
+
+		// 直接用ta-lib计算EMA（indicators.CalculateEMA会取整，交叉判断使用未取整的值）
+		fastEMA, slowEMA := talib.Ema(closes, fast), talib.Ema(closes, slow)
+		n := len(closes)
+		prevFast, prevSlow := fastEMA[n-2], slowEMA[n-2]
+		curFast, curSlow := fastEMA[n-1], slowEMA[n-1]
+		rsi := indicators.CalculateRSI(recent, rsiPeriod)
+
+		var direction, action string
+		switch {
+		case prevFast <= prevSlow && curFast > curSlow && rsi < p["rsi_long_max"]:
+			direction, action = indicators.DirectionLong, executor.ActionOpenLong
+		case prevFast >= prevSlow && curFast < curSlow && rsi > p["rsi_short_min"]:
+			direction, action = indicators.DirectionShort, executor.ActionOpenShort
+		default:
+			return nil
+		}
+
+		last := history[len(history)-1]
+		entry := closes[n-1]
+		atr := indicators.CalculateATRPercent(recent, atrPeriod) / 100 * entry
+		levels := indicators.CalculateLevels(entry, direction, atr, levelParams, risk)
+		if levels == nil || levels.Quantity <= 0 {
+			return nil
+		}
+
+		return &executor.Decision{
+			Symbol:     symbol,
+			Action:     action,
+			Quantity:   levels.Quantity,
+			StopLoss:   levels.StopLoss,
+			TakeProfit: levels.TakeProfit,
+			Timestamp:  last.CloseTime,
+		}
+	}, nil
+}
+
+// RandomDecide 按种子随机开仓（模拟AI决策）
+// 每根K线以 entry_prob 的概率随机开多或开空，止损止盈按入场价的 stop_pct / target_pct（%），
+// 数量固定为 quantity。相同 seed 的决策序列完全相同，可作为策略对比的随机基准
+func RandomDecide(params Params) (DecideFunc, error) {
+	p := Params{
+		"seed":       1,
+		"entry_prob": 0.05,
+		"stop_pct":   1,
+		"target_pct": 2,
+		"quantity":   0.01,
+	}
+	for name, value := range params {
+		if _, ok := p[name]; !ok {
+			return nil, fmt.Errorf("random不支持参数: %s", name)
+		}
+		p[name] = value
+	}
+	if p["entry_prob"] <= 0 || p["entry_prob"] > 1 || p["stop_pct"] <= 0 || p["target_pct"] <= 0 || p["quantity"] <= 0 {
+		return nil, fmt.Errorf("random参数无效: 需要 0 < entry_prob <= 1，stop_pct、target_pct、quantity大于0")
+	}
+
+	rng := rand.New(rand.NewSource(int64(p["seed"])))
+	stop, target := p["stop_pct"]/100, p["target_pct"]/100
+
+	return func(symbol string, history []binance.Kline) *executor.Decision {
+		// 每根K线固定抽取两个随机数，决策序列只取决于种子和K线数量
+		roll, coin := rng.Float64(), rng.Float64()
+		if roll >= p["entry_prob"] {
+			return nil
+		}
+
+		last := history[len(history)-1]
+		entry, err := strconv.ParseFloat(last.Close, 64)
+		if err != nil || entry <= 0 {
+			return nil
+		}
+
+		d := &executor.Decision{
+			Symbol:    symbol,
+			Quantity:  p["quantity"],
+			Timestamp: last.CloseTime,
+		}
+		if coin < 0.5 {
+			d.Action = executor.ActionOpenLong
+			d.StopLoss, d.TakeProfit = entry*(1-stop), entry*(1+target)
+		} else {
+			d.Action = executor.ActionOpenShort
+			d.StopLoss, d.TakeProfit = entry*(1+stop), entry*(1-target)
+		}
+		return d
+	}, nil
+}
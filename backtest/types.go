@@ -0,0 +1,78 @@
+/*
+Package backtest 回测数据结构定义
+
+主要功能：
+- DefaultConfig() Config   // 默认回测配置（10000 USDT，普通用户费率，计入资金费）
+- (c Config) Hash() string // 配置哈希（SHA256，用于确认两次回测使用的配置完全相同）
+
+数据结构：
+- Config      // 回测配置（初始资金、手续费、资金费、成交模型、滑点、随机种子）
+- DecideFunc  // 决策函数（按截至当前K线的历史数据给出交易决策）
+- Result      // 回测结果
+*/
+package backtest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/journal"
+)
+
+// Config 回测配置
+type Config struct {
+	InitialBalance float64          // 初始资金（USDT）
+	Fees           journal.FeeModel // 手续费费率
+	EntryMaker     bool             // 市价入场是否按Maker费率计算（不模拟挂单时近似限价入场）
+	IncludeFunding bool             // 是否计入资金费
+	Warmup         int              // 预热K线数量（指标计算需要的最少历史）
+	Fill           FillConfig       // 入场成交模型（延迟、限价单排队、部分成交）
+
+	SlippagePct       float64 // 固定滑点（%，每次成交都按不利方向）
+	SlippageJitterPct float64 // 随机滑点上限（%，每次成交在0到该值之间均匀抽取，叠加在固定滑点上）
+	Seed              int64   // 随机种子（0表示按当前时间，实际使用的种子记录在结果中）
+}
+
+// Hash 配置哈希（SHA256，用于确认两次回测使用的配置完全相同）
+func (c Config) Hash() string {
+	return hashJSON(c)
+}
+
+// hashJSON 按JSON序列化结果计算SHA256（map按键排序序列化，结果确定）
+func hashJSON(v any) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// DefaultConfig 默认回测配置（10000 USDT，普通用户费率，计入资金费）
+func DefaultConfig() Config {
+	return Config{
+		InitialBalance: 10000,
+		Fees:           journal.DefaultFeeModel,
+		IncludeFunding: true,
+		Warmup:         50,
+		Fill:           DefaultFillConfig(),
+	}
+}
+
+// DecideFunc 决策函数
+// history: 截至当前K线（含）的全部历史K线，返回nil表示观望
+// 开仓决策在下一根K线开盘价成交，平仓决策在下一根K线开盘价平仓
+type DecideFunc func(symbol string, history []binance.Kline) *executor.Decision
+
+// Result 回测结果
+type Result struct {
+	Symbol         string           `json:"symbol"`          // 交易对
+	Seed           int64            `json:"seed"`            // 实际使用的随机种子（相同种子和配置可完全复现结果）
+	ConfigHash     string           `json:"config_hash"`     // 回测配置哈希（种子为0时按实际种子计算）
+	InitialBalance float64          `json:"initial_balance"` // 初始资金
+	FinalBalance   float64          `json:"final_balance"`   // 最终资金（按净盈亏）
+	Trades         []journal.Trade  `json:"trades"`          // 全部交易
+	Summary        journal.Summary  `json:"summary"`         // 盈亏汇总（价格盈亏、手续费、资金费、净盈亏）
+	Metrics        *journal.Metrics `json:"metrics"`         // 绩效指标（夏普、最大回撤、盈利因子等）
+	Fills          FillStats        `json:"fills"`           // 入场成交统计（Maker占比、部分成交、补齐次数）
+}
@@ -4,14 +4,19 @@ Package binance 账户信息相关API
 主要功能：
 - (c *Client) GetAccountInfo() (*AccountInfo, error)           // 获取账户信息
 - (c *Client) GetBalance() (*Balance, error)                   // 获取USDT余额
+- (c *Client) GetAssetBalance(asset string) (*Balance, error)  // 获取单个资产余额
 - (c *Client) GetPositions() ([]Position, error)               // 获取持仓信息
 - (c *Client) GetPositionRisk(symbol string) ([]PositionRisk, error)  // 获取持仓风险
+- (c *Client) GetCommissionRate(symbol string) (*CommissionRate, error)  // 获取交易对的Maker/Taker手续费率
+- (r *CommissionRate) MakerRate() float64                        // Maker费率
+- (r *CommissionRate) TakerRate() float64                        // Taker费率
 */
 package binance
 
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"crypto-ai-trader/utils"
 
@@ -75,7 +80,7 @@ type Position struct {
 
 // Balance 余额信息（单个资产）
 type Balance struct {
-	Asset            string `json:"asset"`            // 资产（USDT）
+	Asset            string `json:"asset"`            // 资产（如USDT、BTC）
 	Balance          string `json:"balance"`          // 余额
 	AvailableBalance string `json:"availableBalance"` // 可用余额
 	UnrealizedProfit string `json:"unrealizedProfit"` // 未实现盈亏
@@ -145,7 +150,12 @@ func (c *Client) GetAccountInfo() (*AccountInfo, error) {
 
 // GetBalance 获取USDT余额
 func (c *Client) GetBalance() (*Balance, error) {
-	utils.Debug("获取账户余额")
+	return c.GetAssetBalance("USDT")
+}
+
+// GetAssetBalance 获取单个资产余额（币本位合约账户按币种保存保证金，如BTC）
+func (c *Client) GetAssetBalance(asset string) (*Balance, error) {
+	utils.Debug("获取账户余额", zap.String("asset", asset))
 
 	body, err := c.doRequest("GET", EndpointBalance, nil, true)
 	if err != nil {
@@ -157,10 +167,10 @@ func (c *Client) GetBalance() (*Balance, error) {
 		return nil, fmt.Errorf("解析账户余额失败: %w", err)
 	}
 
-	// 查找USDT余额
 	for _, balance := range balances {
-		if balance.Asset == "USDT" {
-			utils.Info("获取USDT余额成功",
+		if balance.Asset == asset {
+			utils.Info("获取账户余额成功",
+				zap.String("asset", asset),
 				zap.String("balance", balance.Balance),
 				zap.String("available", balance.AvailableBalance),
 			)
@@ -168,7 +178,7 @@ func (c *Client) GetBalance() (*Balance, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("未找到USDT余额")
+	return nil, fmt.Errorf("未找到%s余额", asset)
 }
 
 // GetPositions 获取持仓信息
@@ -217,3 +227,49 @@ func (c *Client) GetPositionRisk(symbol string) ([]PositionRisk, error) {
 
 	return positionRisks, nil
 }
+
+// CommissionRate 用户手续费率（随VIP等级、BNB抵扣变化）
+type CommissionRate struct {
+	Symbol              string `json:"symbol"`              // 交易对
+	MakerCommissionRate string `json:"makerCommissionRate"` // Maker费率（如"0.0002"表示0.02%）
+	TakerCommissionRate string `json:"takerCommissionRate"` // Taker费率
+}
+
+// GetCommissionRate 获取交易对的Maker/Taker手续费率
+func (c *Client) GetCommissionRate(symbol string) (*CommissionRate, error) {
+	params := map[string]string{
+		"symbol": symbol,
+	}
+
+	body, err := c.doRequest("GET", EndpointCommission, params, true)
+	if err != nil {
+		return nil, fmt.Errorf("获取手续费率失败: %w", err)
+	}
+
+	if c.IsSpot() {
+		rate, err := parseSpotCommission(body)
+		if err != nil {
+			return nil, fmt.Errorf("解析手续费率失败: %w", err)
+		}
+		return rate, nil
+	}
+
+	var rate CommissionRate
+	if err := json.Unmarshal(body, &rate); err != nil {
+		return nil, fmt.Errorf("解析手续费率失败: %w", err)
+	}
+
+	return &rate, nil
+}
+
+// MakerRate Maker费率
+func (r *CommissionRate) MakerRate() float64 {
+	rate, _ := strconv.ParseFloat(r.MakerCommissionRate, 64)
+	return rate
+}
+
+// TakerRate Taker费率
+func (r *CommissionRate) TakerRate() float64 {
+	rate, _ := strconv.ParseFloat(r.TakerCommissionRate, 64)
+	return rate
+}
@@ -4,8 +4,11 @@ Package binance 币安API客户端
 主要功能：
 - NewClient(apiKey, apiSecret, baseURL string, proxy string) *Client  // 创建客户端
 - (c *Client) SetProxy(proxyURL string)                                // 设置代理
-- (c *Client) doRequest(method, endpoint string, params map[string]string, signed bool) ([]byte, error)  // 执行HTTP请求
-- (c *Client) sign(params map[string]string) string                    // 生成签名
+- (c *Client) doRequest(method, endpoint string, params map[string]string, signed bool) ([]byte, error)  // 执行HTTP请求（无签名GET请求跨客户端合并）
+- (c *Client) sign(secret string, params map[string]string) string     // 生成签名
+- (c *Client) GetServerTime() (int64, error)                           // 获取服务器时间（毫秒）
+
+签名请求返回 -1021（时间戳超出recvWindow）时，同步服务器时间后重新签名重试一次（见 time_sync.go）。
 */
 package binance
 
@@ -13,12 +16,15 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"crypto-ai-trader/utils"
@@ -28,22 +34,24 @@ import (
 
 // Client 币安API客户端
 type Client struct {
-	apiKey     string
-	apiSecret  string
+	creds      atomic.Pointer[credentials] // API密钥（轮换时整体替换，见 credentials.go）
 	baseURL    string
+	marketType string // 市场类型（为空表示U本位合约）
 	httpClient *http.Client
+
+	recvWindow int64        // 签名请求的recvWindow（毫秒，0表示不传）
+	timeOffset atomic.Int64 // 服务器时间 - 本地时间（毫秒）
 }
 
 // NewClient 创建新的币安客户端
 func NewClient(apiKey, apiSecret, baseURL string, proxyURL string) *Client {
 	client := &Client{
-		apiKey:    apiKey,
-		apiSecret: apiSecret,
-		baseURL:   baseURL,
+		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
+	client.creds.Store(&credentials{apiKey: apiKey, apiSecret: apiSecret})
 
 	// 设置代理
 	if proxyURL != "" {
@@ -79,51 +87,88 @@ func (c *Client) SetProxy(proxyURL string) {
 
 // doRequest 执行HTTP请求
 func (c *Client) doRequest(method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
-	// 如果需要签名，添加时间戳和签名
+	// 币本位合约、现货客户端转换端点和参数
+	params = c.resolveParams(endpoint, params)
+	endpoint, err := c.resolveEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// 如果需要签名，添加时间戳和签名；时间戳超出recvWindow时同步服务器时间后重试一次
 	if signed {
 		if params == nil {
 			params = make(map[string]string)
 		}
-		params["timestamp"] = fmt.Sprintf("%d", time.Now().UnixMilli())
-		
-		// 生成签名
-		signature := c.sign(params)
-		
-		// 构建带签名的查询字符串
-		queryString := c.buildQueryString(params)
-		queryString += "&signature=" + signature
-		
-		// 构建URL
-		fullURL := c.baseURL + endpoint + "?" + queryString
-		
-		// 创建请求
+		if c.recvWindow > 0 {
+			params["recvWindow"] = fmt.Sprintf("%d", c.recvWindow)
+		}
+
+		body, err := c.doSignedRequest(method, endpoint, params)
+		if errors.Is(err, ErrInvalidTimestamp) {
+			utils.Warn("请求时间戳超出recvWindow，同步服务器时间后重试",
+				zap.String("endpoint", endpoint),
+				zap.Duration("offset", c.TimeOffset()),
+			)
+			if syncErr := c.SyncTime(); syncErr != nil {
+				utils.Error("同步服务器时间失败", zap.Error(syncErr))
+				return nil, err
+			}
+			return c.doSignedRequest(method, endpoint, params)
+		}
+		return body, err
+	}
+
+	// 无签名请求
+	fullURL := c.baseURL + endpoint
+	if len(params) > 0 {
+		fullURL += "?" + c.buildQueryString(params)
+	}
+
+	send := func() ([]byte, error) {
 		req, err := http.NewRequest(method, fullURL, nil)
 		if err != nil {
 			return nil, fmt.Errorf("创建请求失败: %w", err)
 		}
-		
-		// 添加请求头
-		req.Header.Set("X-MBX-APIKEY", c.apiKey)
+
+		req.Header.Set("X-MBX-APIKEY", c.creds.Load().apiKey)
 		req.Header.Set("Content-Type", "application/json")
-		
+
 		return c.executeRequest(req, endpoint, signed)
 	}
 
-	// 无签名请求
-	fullURL := c.baseURL + endpoint
-	if len(params) > 0 {
-		fullURL += "?" + c.buildQueryString(params)
+	// 公开行情GET请求与其他客户端进行中的相同请求合并
+	if method == http.MethodGet {
+		return marketFlights.do(fullURL, send)
 	}
+	return send()
+}
 
+// doSignedRequest 添加时间戳和签名后执行请求
+func (c *Client) doSignedRequest(method, endpoint string, params map[string]string) ([]byte, error) {
+	params["timestamp"] = fmt.Sprintf("%d", c.timestamp())
+
+	// 签名和请求头使用同一组密钥（密钥轮换时进行中的请求用旧密钥完成）
+	creds := c.creds.Load()
+	signature := c.sign(creds.apiSecret, params)
+
+	// 构建带签名的查询字符串
+	queryString := c.buildQueryString(params)
+	queryString += "&signature=" + signature
+
+	// 构建URL
+	fullURL := c.baseURL + endpoint + "?" + queryString
+
+	// 创建请求
 	req, err := http.NewRequest(method, fullURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %w", err)
 	}
 
-	req.Header.Set("X-MBX-APIKEY", c.apiKey)
+	// 添加请求头
+	req.Header.Set("X-MBX-APIKEY", creds.apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	return c.executeRequest(req, endpoint, signed)
+	return c.executeRequest(req, endpoint, true)
 }
 
 // executeRequest 执行HTTP请求
@@ -158,7 +203,7 @@ func (c *Client) executeRequest(req *http.Request, endpoint string, signed bool)
 			zap.Int("status_code", resp.StatusCode),
 			zap.String("response", string(body)),
 		)
-		return nil, fmt.Errorf("API错误 [%d]: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	utils.Debug("API请求成功",
@@ -170,12 +215,12 @@ func (c *Client) executeRequest(req *http.Request, endpoint string, signed bool)
 }
 
 // sign 生成签名
-func (c *Client) sign(params map[string]string) string {
+func (c *Client) sign(secret string, params map[string]string) string {
 	// 构建查询字符串
 	queryString := c.buildQueryString(params)
 
 	// 使用HMAC SHA256签名
-	h := hmac.New(sha256.New, []byte(c.apiSecret))
+	h := hmac.New(sha256.New, []byte(secret))
 	h.Write([]byte(queryString))
 	signature := hex.EncodeToString(h.Sum(nil))
 
@@ -217,13 +262,18 @@ func (c *Client) Ping() error {
 	return nil
 }
 
-// GetServerTime 获取服务器时间
+// GetServerTime 获取服务器时间（毫秒）
 func (c *Client) GetServerTime() (int64, error) {
-	_, err := c.doRequest("GET", EndpointServerTime, nil, false)
+	body, err := c.doRequest("GET", EndpointServerTime, nil, false)
 	if err != nil {
 		return 0, err
 	}
 
-	// 简单返回当前时间（实际应该解析响应）
-	return time.Now().UnixMilli(), nil
+	var result struct {
+		ServerTime int64 `json:"serverTime"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, fmt.Errorf("解析服务器时间失败: %w", err)
+	}
+	return result.ServerTime, nil
 }
@@ -0,0 +1,73 @@
+/*
+Package binance 公开行情请求合并（多个账号同时请求相同数据时只发送一次）
+
+主要功能：
+- GetCoalesceStats() CoalesceStats  // 获取请求合并统计（发出的请求数、合并掉的请求数）
+
+多个账号共用同一个交易对池时，每个账号每个周期都会请求相同的K线、持仓量、资金费率。
+无签名的GET请求按完整URL（地址、端点、参数）合并：同一URL的请求正在进行时，后来的调用等待并共享同一个响应，
+不再发送新请求。只合并进行中的请求，不缓存已完成的响应，返回的数据与单独请求一致。
+合并在进程内所有客户端之间进行（每个账号有独立的客户端实例）。签名请求和下单等非GET请求不合并。
+*/
+package binance
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// CoalesceStats 请求合并统计
+type CoalesceStats struct {
+	Requests int64 `json:"requests"` // 实际发出的无签名GET请求数
+	Shared   int64 `json:"shared"`   // 等待其他调用的进行中请求、共享响应的调用数
+}
+
+// flightCall 一个进行中的请求
+type flightCall struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// flightGroup 按key合并进行中的请求
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+
+	requests atomic.Int64
+	shared   atomic.Int64
+}
+
+// marketFlights 所有客户端共用的公开行情请求合并
+var marketFlights = &flightGroup{calls: make(map[string]*flightCall)}
+
+// do 执行请求；同一key的请求正在进行时等待并返回它的结果（响应内容只读，调用方不能修改）
+func (g *flightGroup) do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		g.shared.Add(1)
+		<-call.done
+		return call.body, call.err
+	}
+	call := &flightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	g.requests.Add(1)
+	call.body, call.err = fn()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(call.done)
+	return call.body, call.err
+}
+
+// GetCoalesceStats 获取请求合并统计（进程启动以来）
+func GetCoalesceStats() CoalesceStats {
+	return CoalesceStats{
+		Requests: marketFlights.requests.Load(),
+		Shared:   marketFlights.shared.Load(),
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Package binance 币本位合约（dapi）支持
+
+主要功能：
+- NewCoinMClient(apiKey, apiSecret, baseURL, proxyURL string) *Client      // 创建币本位合约客户端（baseURL如 https://dapi.binance.com）
+- (c *Client) MarketType() string                                        // 市场类型（usdt_m / coin_m / spot）
+- (c *Client) IsCoinM() bool                                             // 是否为币本位合约客户端
+- CoinMSymbol(symbol string) string                                      // U本位交易对转币本位永续（BTCUSDT → BTCUSD_PERP）
+- (s *SymbolInfo) ContractsForQuantity(qty, price float64) float64       // 标的数量转合约张数（U本位原样返回）
+- (s *SymbolInfo) Notional(qty, price float64) float64                   // 名义价值（USD）
+
+币本位合约与U本位的主要差异：
+- 接口前缀 /dapi，账户、余额、持仓风险为 v1 版本
+- 下单数量单位是合约张数，每张合约面值为 contractSize 美元（BTC为100，其他一般为10）
+- 盈亏、手续费、资金费以标的币种结算
+- 最优挂单、溢价指数等接口即使指定交易对也返回数组
+*/
+package binance
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+)
+
+// 市场类型
+const (
+	MarketTypeUSDTM = "usdt_m" // U本位合约（fapi）
+	MarketTypeCoinM = "coin_m" // 币本位合约（dapi）
+)
+
+// coinMEndpoints 币本位合约中路径与U本位不只是前缀不同的端点
+var coinMEndpoints = map[string]string{
+	EndpointAccount:      "/dapi/v1/account",
+	EndpointBalance:      "/dapi/v1/balance",
+	EndpointPositionRisk: "/dapi/v1/positionRisk",
+}
+
+// NewCoinMClient 创建币本位合约客户端
+func NewCoinMClient(apiKey, apiSecret, baseURL, proxyURL string) *Client {
+	client := NewClient(apiKey, apiSecret, baseURL, proxyURL)
+	client.marketType = MarketTypeCoinM
+	return client
+}
+
+// MarketType 市场类型
+func (c *Client) MarketType() string {
+	if c.marketType == "" {
+		return MarketTypeUSDTM
+	}
+	return c.marketType
+}
+
+// IsCoinM 是否为币本位合约客户端
+func (c *Client) IsCoinM() bool {
+	return c.marketType == MarketTypeCoinM
+}
+
+// resolveEndpoint 按市场类型转换端点路径
+func (c *Client) resolveEndpoint(endpoint string) (string, error) {
+	switch {
+	case c.IsSpot():
+		return resolveSpotEndpoint(endpoint)
+	case c.IsCoinM():
+		if mapped, ok := coinMEndpoints[endpoint]; ok {
+			return mapped, nil
+		}
+		return strings.Replace(endpoint, "/fapi/", "/dapi/", 1), nil
+	default:
+		return endpoint, nil
+	}
+}
+
+// resolveParams 按市场类型转换请求参数
+// 现货下单参数见 resolveSpotParams；币本位持仓风险接口不支持symbol参数，按pair（如BTCUSD）查询
+func (c *Client) resolveParams(endpoint string, params map[string]string) map[string]string {
+	if c.IsSpot() && endpoint == EndpointOrder {
+		return resolveSpotParams(params)
+	}
+	if !c.IsCoinM() || endpoint != EndpointPositionRisk || params["symbol"] == "" {
+		return params
+	}
+
+	resolved := make(map[string]string, len(params))
+	for k, v := range params {
+		resolved[k] = v
+	}
+	symbol := resolved["symbol"]
+	delete(resolved, "symbol")
+	resolved["pair"] = strings.SplitN(symbol, "_", 2)[0]
+	return resolved
+}
+
+// CoinMSymbol U本位交易对转币本位永续合约（BTCUSDT → BTCUSD_PERP），已是币本位格式时原样返回
+func CoinMSymbol(symbol string) string {
+	if strings.Contains(symbol, "_") {
+		return symbol
+	}
+	return strings.TrimSuffix(symbol, "USDT") + "USD_PERP"
+}
+
+// ContractsForQuantity 标的数量转合约张数
+// 币本位：张数 = 数量 × 价格 / 合约面值；U本位（contractSize为0）原样返回
+func (s *SymbolInfo) ContractsForQuantity(qty, price float64) float64 {
+	if s.ContractSize <= 0 {
+		return qty
+	}
+	return math.Floor(qty * price / s.ContractSize)
+}
+
+// Notional 名义价值（USD）
+// 币本位：张数 × 合约面值；U本位：数量 × 价格
+func (s *SymbolInfo) Notional(qty, price float64) float64 {
+	if s.ContractSize > 0 {
+		return qty * s.ContractSize
+	}
+	return qty * price
+}
+
+// unmarshalSingle 解析单个对象（币本位接口返回数组时取第一个元素）
+func unmarshalSingle(body []byte, v interface{}) error {
+	trimmed := strings.TrimSpace(string(body))
+	if !strings.HasPrefix(trimmed, "[") {
+		return json.Unmarshal(body, v)
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return json.Unmarshal([]byte("{}"), v)
+	}
+	return json.Unmarshal(items[0], v)
+}
@@ -0,0 +1,342 @@
+/*
+Package binance 组合推送（/stream?streams=a/b/c，多个推送共用少量连接）
+
+主要功能：
+- newCombinedStreams(baseURL, proxyURL string, limit int, handler combinedHandler) *combinedStreams  // 创建组合推送（limit为单连接最多推送数）
+- (c *combinedStreams) Run(ctx context.Context)                 // 启动已设置的推送，直到ctx取消
+- (c *combinedStreams) SetStreams(streams []string)             // 设置需要的推送（新增的订阅、不再需要的取消订阅）
+- (c *combinedStreams) Shard(stream string) (int, bool)         // 推送所在的连接编号
+- (c *combinedStreams) Connections() int                        // 当前的连接数
+
+推送按加入顺序装入连接，每个连接最多limit个（币安合约单连接上限200个），装满后自动新建连接（分片）。
+连接已建立时新增和取消推送通过 SUBSCRIBE/UNSUBSCRIBE 消息完成，不影响同一连接上的其他推送；
+连接断开后按指数退避重连，重连地址包含该连接当前的全部推送（自动重新订阅）。连接的推送全部取消后关闭连接。
+币安限制每个连接每秒最多10条客户端消息，每次 SetStreams 每个连接最多发送一条订阅和一条取消订阅消息。
+*/
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// combinedHandler 组合推送的回调（在连接的读取协程中调用，不能阻塞）
+type combinedHandler interface {
+	// onMessage 收到推送消息（stream为推送名称，如 btcusdt@depth@100ms）
+	onMessage(stream string, data json.RawMessage)
+	// onLive 推送开始接收（连接建立或订阅消息已发送，live为true）或停止（连接断开或取消订阅，live为false）
+	// 在持有组合推送锁时调用，不能回调组合推送的方法
+	onLive(stream string, live bool)
+}
+
+// combinedMessage 组合推送消息：推送数据或订阅请求的响应
+type combinedMessage struct {
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+	ID     int64           `json:"id"`
+	Error  *struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"error"`
+}
+
+// combinedRequest 订阅、取消订阅请求
+type combinedRequest struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+	ID     int64    `json:"id"`
+}
+
+// combinedShard 一个连接及其推送
+type combinedShard struct {
+	id      int
+	cancel  context.CancelFunc
+	streams map[string]bool // 该连接当前需要的推送
+	subbed  map[string]bool // 已在连接上订阅的推送（连接断开时为空）
+	conn    *wsConn         // 连接断开时为nil
+	nextID  int64
+}
+
+// combinedStreams 组合推送
+type combinedStreams struct {
+	baseURL string
+	proxy   string
+	limit   int
+	handler combinedHandler
+
+	mu     sync.Mutex
+	ctx    context.Context // Run 启动后设置
+	shards []*combinedShard
+	nextID int
+}
+
+// newCombinedStreams 创建组合推送（limit<=0时不限制单连接推送数）
+func newCombinedStreams(baseURL, proxyURL string, limit int, handler combinedHandler) *combinedStreams {
+	return &combinedStreams{
+		baseURL: baseURL,
+		proxy:   proxyURL,
+		limit:   limit,
+		handler: handler,
+	}
+}
+
+// Run 启动已设置的推送，直到ctx取消
+func (c *combinedStreams) Run(ctx context.Context) {
+	c.mu.Lock()
+	c.ctx = ctx
+	for _, shard := range c.shards {
+		c.startLocked(shard)
+	}
+	c.mu.Unlock()
+
+	<-ctx.Done()
+}
+
+// SetStreams 设置需要的推送：新增的装入未满的连接（都已满时新建连接），不再需要的取消订阅
+func (c *combinedStreams) SetStreams(streams []string) {
+	wanted := make(map[string]bool, len(streams))
+	for _, stream := range streams {
+		wanted[stream] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	assigned := make(map[string]bool)
+	kept := c.shards[:0]
+	for _, shard := range c.shards {
+		for stream := range shard.streams {
+			if !wanted[stream] {
+				delete(shard.streams, stream)
+			} else {
+				assigned[stream] = true
+			}
+		}
+		if len(shard.streams) == 0 {
+			c.stopLocked(shard)
+			continue
+		}
+		kept = append(kept, shard)
+	}
+	c.shards = kept
+
+	// 按名称排序后装入，同一批推送的分片结果稳定
+	added := make([]string, 0, len(wanted))
+	for stream := range wanted {
+		if !assigned[stream] {
+			added = append(added, stream)
+		}
+	}
+	sort.Strings(added)
+	for _, stream := range added {
+		shard := c.freeShardLocked()
+		if shard == nil {
+			shard = &combinedShard{id: c.nextID, streams: make(map[string]bool), subbed: make(map[string]bool)}
+			c.nextID++
+			c.shards = append(c.shards, shard)
+		}
+		shard.streams[stream] = true
+	}
+
+	for _, shard := range c.shards {
+		if shard.cancel == nil {
+			c.startLocked(shard)
+		} else {
+			c.syncLocked(shard)
+		}
+	}
+}
+
+// freeShardLocked 还有空位的连接（调用方持有锁）
+func (c *combinedStreams) freeShardLocked() *combinedShard {
+	for _, shard := range c.shards {
+		if c.limit <= 0 || len(shard.streams) < c.limit {
+			return shard
+		}
+	}
+	return nil
+}
+
+// startLocked 启动连接的协程（Run之前只记录，调用方持有锁）
+func (c *combinedStreams) startLocked(shard *combinedShard) {
+	if c.ctx == nil || shard.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	shard.cancel = cancel
+	go c.maintain(ctx, shard)
+}
+
+// stopLocked 关闭推送已全部取消的连接（调用方持有锁）
+func (c *combinedStreams) stopLocked(shard *combinedShard) {
+	if shard.cancel != nil {
+		shard.cancel()
+	}
+	for stream := range shard.subbed {
+		c.handler.onLive(stream, false)
+	}
+	shard.subbed = make(map[string]bool)
+	shard.conn = nil
+}
+
+// syncLocked 已连接时按需要的推送发送订阅和取消订阅消息（调用方持有锁）
+func (c *combinedStreams) syncLocked(shard *combinedShard) {
+	if shard.conn == nil {
+		return
+	}
+	var subscribe, unsubscribe []string
+	for stream := range shard.streams {
+		if !shard.subbed[stream] {
+			subscribe = append(subscribe, stream)
+		}
+	}
+	for stream := range shard.subbed {
+		if !shard.streams[stream] {
+			unsubscribe = append(unsubscribe, stream)
+		}
+	}
+	sort.Strings(subscribe)
+	sort.Strings(unsubscribe)
+
+	if len(unsubscribe) > 0 {
+		if err := c.sendLocked(shard, "UNSUBSCRIBE", unsubscribe); err != nil {
+			utils.Warn("取消订阅推送失败", zap.Int("shard", shard.id), zap.Strings("streams", unsubscribe), zap.Error(err))
+		}
+		for _, stream := range unsubscribe {
+			delete(shard.subbed, stream)
+			c.handler.onLive(stream, false)
+		}
+	}
+	if len(subscribe) > 0 {
+		if err := c.sendLocked(shard, "SUBSCRIBE", subscribe); err != nil {
+			// 发送失败说明连接已断开，重连时按当前推送重新订阅
+			utils.Warn("订阅推送失败", zap.Int("shard", shard.id), zap.Strings("streams", subscribe), zap.Error(err))
+			return
+		}
+		for _, stream := range subscribe {
+			shard.subbed[stream] = true
+			c.handler.onLive(stream, true)
+		}
+	}
+}
+
+// sendLocked 发送订阅请求（调用方持有锁）
+func (c *combinedStreams) sendLocked(shard *combinedShard, method string, streams []string) error {
+	shard.nextID++
+	payload, err := json.Marshal(combinedRequest{Method: method, Params: streams, ID: shard.nextID})
+	if err != nil {
+		return err
+	}
+	return shard.conn.WriteMessage(payload)
+}
+
+// maintain 维持一个连接，断线后按指数退避重连，直到ctx取消（连接的推送全部取消时ctx被取消）
+func (c *combinedStreams) maintain(ctx context.Context, shard *combinedShard) {
+	var backoff wsBackoff
+	for {
+		start := time.Now()
+		err := c.receive(ctx, shard)
+		if ctx.Err() != nil {
+			return
+		}
+		delay := backoff.next(time.Since(start))
+		utils.Warn("组合推送连接中断，稍后重连", zap.Int("shard", shard.id), zap.Duration("delay", delay), zap.Error(err))
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// receive 建立一次连接并分发推送，连接出错时返回
+func (c *combinedStreams) receive(ctx context.Context, shard *combinedShard) error {
+	c.mu.Lock()
+	streams := make([]string, 0, len(shard.streams))
+	for stream := range shard.streams {
+		streams = append(streams, stream)
+	}
+	c.mu.Unlock()
+	sort.Strings(streams)
+
+	conn, err := dialWS(ctx, c.baseURL+"/stream?streams="+strings.Join(streams, "/"), c.proxy)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// 连接期间新增或取消的推送随后通过订阅消息补上
+	c.mu.Lock()
+	if ctx.Err() != nil {
+		c.mu.Unlock()
+		return ctx.Err()
+	}
+	shard.conn = conn
+	for _, stream := range streams {
+		shard.subbed[stream] = true
+		c.handler.onLive(stream, true)
+	}
+	c.syncLocked(shard)
+	c.mu.Unlock()
+	utils.Info("组合推送已连接", zap.Int("shard", shard.id), zap.Int("streams", len(streams)))
+
+	defer func() {
+		c.mu.Lock()
+		if shard.conn == conn {
+			shard.conn = nil
+		}
+		for stream := range shard.subbed {
+			c.handler.onLive(stream, false)
+		}
+		shard.subbed = make(map[string]bool)
+		c.mu.Unlock()
+	}()
+
+	for {
+		message, err := conn.ReadMessage(0)
+		if err != nil {
+			return err
+		}
+		var msg combinedMessage
+		if err := json.Unmarshal(message, &msg); err != nil {
+			utils.Warn("解析组合推送消息失败", zap.Int("shard", shard.id), zap.Error(err))
+			continue
+		}
+		switch {
+		case msg.Error != nil:
+			utils.Warn("组合推送订阅请求失败", zap.Int("shard", shard.id), zap.Int64("id", msg.ID),
+				zap.Int("code", msg.Error.Code), zap.String("msg", msg.Error.Msg))
+		case msg.Stream != "":
+			c.handler.onMessage(msg.Stream, msg.Data)
+		}
+	}
+}
+
+// Shard 推送所在的连接编号（推送未设置时返回false）
+func (c *combinedStreams) Shard(stream string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, shard := range c.shards {
+		if shard.streams[stream] {
+			return shard.id, true
+		}
+	}
+	return 0, false
+}
+
+// Connections 当前的连接数（包括断线重连中的连接）
+func (c *combinedStreams) Connections() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.shards)
+}
@@ -0,0 +1,93 @@
+/*
+Package binance API密钥轮换（不重启程序替换账号的API密钥）
+
+主要功能：
+- (c *Client) SetCredentials(apiKey, apiSecret string)               // 替换客户端的API密钥（之后发起的请求使用新密钥）
+- (c *Client) HasCredentials(apiKey, apiSecret string) bool          // 客户端当前是否使用该API密钥
+- NewKeyRing(clients ...*Client) *KeyRing                            // 创建账号的密钥组（同一组API密钥的全部客户端）
+- (k *KeyRing) Add(c *Client)                                        // 加入使用同一组API密钥的客户端（如资金调拨的现货客户端）
+- (k *KeyRing) SetVerifier(fn func(apiKey, apiSecret string) error)  // 设置替换前检查新密钥的函数
+- (k *KeyRing) Rotate(apiKey, apiSecret string) (bool, error)        // 检查新密钥后替换全部客户端的密钥，密钥未变化时返回false
+
+API Key和Secret作为一组原子替换：每个请求发起时读取一次，签名和请求头使用同一组密钥，
+进行中的请求用旧密钥完成，之后的请求使用新密钥，不会出现新Key配旧Secret的请求。
+客户端的其他状态（服务器时间偏移、连接池、订阅的行情推送）不受影响。
+*/
+package binance
+
+import (
+	"fmt"
+	"sync"
+)
+
+// credentials 一组API密钥
+type credentials struct {
+	apiKey    string
+	apiSecret string
+}
+
+// SetCredentials 替换客户端的API密钥（之后发起的请求使用新密钥）
+func (c *Client) SetCredentials(apiKey, apiSecret string) {
+	c.creds.Store(&credentials{apiKey: apiKey, apiSecret: apiSecret})
+}
+
+// HasCredentials 客户端当前是否使用该API密钥
+func (c *Client) HasCredentials(apiKey, apiSecret string) bool {
+	creds := c.creds.Load()
+	return creds.apiKey == apiKey && creds.apiSecret == apiSecret
+}
+
+// KeyRing 一个账号的API密钥及使用该密钥的全部客户端
+type KeyRing struct {
+	mu      sync.Mutex
+	clients []*Client
+	verify  func(apiKey, apiSecret string) error
+}
+
+// NewKeyRing 创建账号的密钥组
+func NewKeyRing(clients ...*Client) *KeyRing {
+	return &KeyRing{clients: clients}
+}
+
+// Add 加入使用同一组API密钥的客户端
+func (k *KeyRing) Add(c *Client) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.clients = append(k.clients, c)
+}
+
+// SetVerifier 设置替换前检查新密钥的函数（返回错误时不替换）
+func (k *KeyRing) SetVerifier(fn func(apiKey, apiSecret string) error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.verify = fn
+}
+
+// Rotate 检查新密钥后替换全部客户端的密钥，密钥未变化时返回false
+// 同一时间只进行一次轮换；检查失败时全部客户端保留旧密钥
+func (k *KeyRing) Rotate(apiKey, apiSecret string) (bool, error) {
+	if apiKey == "" || apiSecret == "" {
+		return false, fmt.Errorf("API Key和API Secret不能为空")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if len(k.clients) == 0 {
+		return false, fmt.Errorf("没有使用该密钥的客户端")
+	}
+	if k.clients[0].HasCredentials(apiKey, apiSecret) {
+		return false, nil
+	}
+	if k.verify != nil {
+		if err := k.verify(apiKey, apiSecret); err != nil {
+			return false, fmt.Errorf("新密钥检查未通过: %w", err)
+		}
+	}
+	for _, c := range k.clients {
+		c.SetCredentials(apiKey, apiSecret)
+	}
+	return true, nil
+}
@@ -0,0 +1,110 @@
+/*
+Package binance 订单簿深度
+
+主要功能：
+- (c *Client) GetOrderBook(symbol string, limit int) (*OrderBook, error)            // 获取订单簿深度
+- (ob *OrderBook) MidPrice() float64                                                // 中间价
+- (ob *OrderBook) SpreadBps() float64                                               // 买一卖一价差（基点）
+- (ob *OrderBook) EstimateFill(side string, quantity float64) *FillEstimate         // 估算按盘口吃单的成交均价和滑点
+*/
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// OrderBook 订单簿
+type OrderBook struct {
+	LastUpdateID int64       `json:"lastUpdateId"` // 更新ID
+	Bids         [][2]string `json:"bids"`         // 买单 [价格, 数量]，价格从高到低
+	Asks         [][2]string `json:"asks"`         // 卖单 [价格, 数量]，价格从低到高
+}
+
+// FillEstimate 吃单成交估算
+type FillEstimate struct {
+	Quantity    float64 // 计划数量
+	FilledQty   float64 // 盘口深度内可成交数量
+	AvgPrice    float64 // 预计成交均价
+	MidPrice    float64 // 中间价
+	SlippageBps float64 // 相对中间价的滑点（基点）
+}
+
+// GetOrderBook 获取订单簿深度
+// symbol: 交易对，如 "BTCUSDT"
+// limit: 档位数量，可选 5, 10, 20, 50, 100, 500, 1000
+func (c *Client) GetOrderBook(symbol string, limit int) (*OrderBook, error) {
+	params := map[string]string{
+		"symbol": symbol,
+		"limit":  strconv.Itoa(limit),
+	}
+
+	body, err := c.doRequest("GET", EndpointDepth, params, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取订单簿失败: %w", err)
+	}
+
+	var book OrderBook
+	if err := json.Unmarshal(body, &book); err != nil {
+		return nil, fmt.Errorf("解析订单簿数据失败: %w", err)
+	}
+
+	return &book, nil
+}
+
+// MidPrice 中间价（买一卖一均价）
+func (ob *OrderBook) MidPrice() float64 {
+	if len(ob.Bids) == 0 || len(ob.Asks) == 0 {
+		return 0
+	}
+	bid, _ := strconv.ParseFloat(ob.Bids[0][0], 64)
+	ask, _ := strconv.ParseFloat(ob.Asks[0][0], 64)
+	return (bid + ask) / 2
+}
+
+// SpreadBps 买一卖一价差相对中间价的基点数（盘口为空时为0）
+func (ob *OrderBook) SpreadBps() float64 {
+	mid := ob.MidPrice()
+	if mid <= 0 {
+		return 0
+	}
+	bid, _ := strconv.ParseFloat(ob.Bids[0][0], 64)
+	ask, _ := strconv.ParseFloat(ob.Asks[0][0], 64)
+	return (ask - bid) / mid * 10000
+}
+
+// EstimateFill 估算按盘口逐档吃单的成交均价和滑点
+// side: BUY 吃卖单，SELL 吃买单
+// 盘口深度不足时 FilledQty 小于 Quantity
+func (ob *OrderBook) EstimateFill(side string, quantity float64) *FillEstimate {
+	levels := ob.Asks
+	if side == SideSell {
+		levels = ob.Bids
+	}
+
+	est := &FillEstimate{Quantity: quantity, MidPrice: ob.MidPrice()}
+
+	var cost float64
+	for _, level := range levels {
+		if est.FilledQty >= quantity {
+			break
+		}
+		price, _ := strconv.ParseFloat(level[0], 64)
+		qty, _ := strconv.ParseFloat(level[1], 64)
+
+		take := math.Min(qty, quantity-est.FilledQty)
+		cost += take * price
+		est.FilledQty += take
+	}
+
+	if est.FilledQty > 0 {
+		est.AvgPrice = cost / est.FilledQty
+	}
+	if est.MidPrice > 0 && est.AvgPrice > 0 {
+		est.SlippageBps = math.Abs(est.AvgPrice-est.MidPrice) / est.MidPrice * 10000
+	}
+
+	return est
+}
@@ -0,0 +1,540 @@
+/*
+Package binance 增量深度推送维护本地订单簿（<symbol>@depth@100ms + REST快照同步）
+
+主要功能：
+- NewDepthStream(client *Client, baseURL, proxyURL string, maxPerConn int) *DepthStream  // 创建本地订单簿（client用于获取REST快照，baseURL如 wss://fstream.binance.com，maxPerConn为单连接最多交易对数）
+- (s *DepthStream) Run(ctx context.Context)                              // 按订阅的交易对维护本地订单簿，直到ctx取消
+- (s *DepthStream) SetSymbols(owner string, symbols []string)            // 设置owner（如账号ID）需要的交易对，所有owner的并集保持订阅
+- (s *DepthStream) OrderBook(symbol string, limit int) (*OrderBook, bool) // 本地订单簿的前limit档（未同步时返回false）
+- (s *DepthStream) BestBidAsk(symbol string) (bid, ask float64, ok bool) // 本地订单簿的买一卖一价
+- (s *DepthStream) Status() []DepthBookStatus                            // 各交易对本地订单簿的同步状态
+- (s *DepthStream) Connections() int                                     // 推送连接数
+- (s *DepthStream) Subscribe(ctx context.Context, name string, capacity int) <-chan DepthUpdate  // 订阅盘口更新（同一交易对只保留最新一条）
+- (s *DepthStream) Subscribers() []QueueStats                            // 各订阅队列的统计
+
+同步流程（币安合约文档）：
+1. 订阅 <symbol>@depth@100ms 推送（推送先缓存在内存中），再用REST获取1000档快照
+2. 丢弃 u < 快照lastUpdateId 的推送；第一条应用的推送须满足 U <= lastUpdateId <= u
+3. 之后每条推送的 pu 必须等于上一条的 u，否则说明丢了数据，重新获取快照
+4. 数量为0的档位删除
+所有交易对通过组合推送（/stream?streams=）共用连接，每个连接最多maxPerConn个交易对，超过时自动增加连接；
+交易对的增减通过订阅消息完成，不影响同一连接上的其他交易对。数据不连续时只重新获取该交易对的快照，
+连接中断时该连接上的交易对全部标记为未同步，重连后重新获取快照（断线期间的数据由快照补齐）；
+重新同步按指数退避等待，未同步期间调用方改用REST接口。
+nil 的 *DepthStream 可以安全调用，OrderBook 等方法始终返回false。
+*/
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	depthSnapshotLimit  = 1000 // REST快照档位数
+	depthMaxPending     = 5000 // 快照完成前最多缓存的推送数（超过时重新同步）
+	depthStreamInterval = "@depth@100ms"
+)
+
+// DepthBookStatus 交易对本地订单簿的同步状态
+type DepthBookStatus struct {
+	Symbol       string    `json:"symbol"`
+	Synced       bool      `json:"synced"`         // 是否已与快照同步（未同步时不提供数据）
+	LastUpdateID int64     `json:"last_update_id"` // 最近应用的更新ID
+	Updated      time.Time `json:"updated"`        // 最近一次应用推送的时间
+	Bids         int       `json:"bids"`           // 买单档位数
+	Asks         int       `json:"asks"`           // 卖单档位数
+	Resyncs      int       `json:"resyncs"`        // 重新同步次数
+	Shard        int       `json:"shard"`          // 所在的推送连接编号
+	LastError    string    `json:"last_error,omitempty"`
+}
+
+// DepthUpdate 本地订单簿应用推送后的盘口（订阅使用）
+type DepthUpdate struct {
+	Symbol       string    `json:"symbol"`
+	BidPrice     float64   `json:"bid_price"`
+	AskPrice     float64   `json:"ask_price"`
+	LastUpdateID int64     `json:"last_update_id"`
+	Time         time.Time `json:"time"`
+}
+
+// depthEvent 增量深度推送
+type depthEvent struct {
+	EventType     string      `json:"e"`
+	EventTime     int64       `json:"E"`
+	TradeTime     int64       `json:"T"`
+	Symbol        string      `json:"s"`
+	FirstUpdateID int64       `json:"U"`
+	FinalUpdateID int64       `json:"u"`
+	PrevUpdateID  int64       `json:"pu"`
+	Bids          [][2]string `json:"b"`
+	Asks          [][2]string `json:"a"`
+}
+
+// depthBook 单个交易对的本地订单簿
+type depthBook struct {
+	mu     sync.RWMutex
+	bids   map[float64]float64 // 价格 -> 数量
+	asks   map[float64]float64
+	status DepthBookStatus
+
+	// 同步状态（mu保护）
+	live       bool          // 推送正在接收
+	gen        int           // 每次重新同步加1，过期的快照请求结果被丢弃
+	pending    []*depthEvent // 快照完成前缓存的推送
+	snapshotID int64         // 已应用快照的lastUpdateId（0表示快照未完成）
+	applied    bool          // 快照之后是否已应用过推送
+	syncStart  time.Time
+	backoff    wsBackoff
+}
+
+// DepthStream 按交易对维护的本地订单簿集合
+type DepthStream struct {
+	client  *Client
+	streams *combinedStreams
+
+	subMu  sync.Mutex // 串行化 SetSymbols，保证订阅按调用顺序生效
+	mu     sync.Mutex
+	ctx    context.Context // Run 启动后设置
+	owners map[string]map[string]bool
+	books  map[string]*depthBook
+
+	subscribers *streamSubscribers
+}
+
+// NewDepthStream 创建本地订单簿（maxPerConn<=0时所有交易对共用一个连接）
+func NewDepthStream(client *Client, baseURL, proxyURL string, maxPerConn int) *DepthStream {
+	s := &DepthStream{
+		client:      client,
+		owners:      make(map[string]map[string]bool),
+		books:       make(map[string]*depthBook),
+		subscribers: newStreamSubscribers(),
+	}
+	s.streams = newCombinedStreams(baseURL, proxyURL, maxPerConn, s)
+	return s
+}
+
+// Run 按订阅的交易对维护本地订单簿，直到ctx取消（Run之前设置的交易对在启动时开始同步）
+func (s *DepthStream) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	s.streams.Run(ctx)
+}
+
+// SetSymbols 设置owner需要的交易对（覆盖该owner之前的设置），不再被任何owner需要的交易对停止订阅
+func (s *DepthStream) SetSymbols(owner string, symbols []string) {
+	if s == nil {
+		return
+	}
+	wanted := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		wanted[symbol] = true
+	}
+
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	s.mu.Lock()
+	if len(wanted) == 0 {
+		delete(s.owners, owner)
+	} else {
+		s.owners[owner] = wanted
+	}
+	streams := s.reconcileLocked()
+	s.mu.Unlock()
+
+	s.streams.SetStreams(streams)
+}
+
+// reconcileLocked 为新需要的交易对创建订单簿，删除不再需要的订单簿，返回需要订阅的推送（调用方持有锁）
+func (s *DepthStream) reconcileLocked() []string {
+	wanted := make(map[string]bool)
+	for _, symbols := range s.owners {
+		for symbol := range symbols {
+			wanted[symbol] = true
+		}
+	}
+
+	for symbol, book := range s.books {
+		if !wanted[symbol] {
+			book.mu.Lock()
+			book.live = false
+			book.gen++
+			book.mu.Unlock()
+			delete(s.books, symbol)
+			utils.Info("停止维护本地订单簿", zap.String("symbol", symbol))
+		}
+	}
+	streams := make([]string, 0, len(wanted))
+	for symbol := range wanted {
+		if _, ok := s.books[symbol]; !ok {
+			s.books[symbol] = &depthBook{status: DepthBookStatus{Symbol: symbol}}
+		}
+		streams = append(streams, depthStreamName(symbol))
+	}
+	return streams
+}
+
+// depthStreamName 交易对的增量深度推送名称
+func depthStreamName(symbol string) string {
+	return strings.ToLower(symbol) + depthStreamInterval
+}
+
+// streamBook 推送对应的订单簿（已停止维护时返回nil）
+func (s *DepthStream) streamBook(stream string) *depthBook {
+	symbol := strings.ToUpper(strings.TrimSuffix(stream, depthStreamInterval))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.books[symbol]
+}
+
+// onLive 推送开始接收时获取快照开始同步，停止时标记为未同步（组合推送回调）
+func (s *DepthStream) onLive(stream string, live bool) {
+	book := s.streamBook(stream)
+	if book == nil {
+		return
+	}
+
+	book.mu.Lock()
+	defer book.mu.Unlock()
+	if live {
+		book.backoff = wsBackoff{}
+		s.startSyncLocked(book, 0)
+		return
+	}
+	book.live = false
+	book.gen++
+	book.pending = nil
+	book.status.Synced = false
+	book.status.LastError = "推送连接中断"
+}
+
+// onMessage 快照完成前缓存推送，完成后按更新ID校验并应用（组合推送回调）
+func (s *DepthStream) onMessage(stream string, data json.RawMessage) {
+	book := s.streamBook(stream)
+	if book == nil {
+		return
+	}
+	var event depthEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		utils.Warn("解析深度推送失败", zap.String("stream", stream), zap.Error(err))
+		return
+	}
+
+	book.mu.Lock()
+	defer book.mu.Unlock()
+	if !book.live {
+		return
+	}
+	if book.snapshotID == 0 {
+		if len(book.pending) >= depthMaxPending {
+			s.resyncLocked(book, fmt.Errorf("快照完成前缓存的推送超过%d条", depthMaxPending))
+			return
+		}
+		book.pending = append(book.pending, &event)
+		return
+	}
+	if err := book.applyLocked(&event); err != nil {
+		s.resyncLocked(book, err)
+		return
+	}
+	s.publishLocked(book)
+}
+
+// publishLocked 有订阅者且已同步时把最新盘口交给订阅者（调用方持有book锁）
+func (s *DepthStream) publishLocked(book *depthBook) {
+	if !book.status.Synced || !s.subscribers.active() {
+		return
+	}
+	bid, ask := book.bestLocked()
+	s.subscribers.publish(book.status.Symbol, DepthUpdate{
+		Symbol:       book.status.Symbol,
+		BidPrice:     bid,
+		AskPrice:     ask,
+		LastUpdateID: book.status.LastUpdateID,
+		Time:         book.status.Updated,
+	})
+}
+
+// startSyncLocked 丢弃当前数据，等待delay后获取快照重新同步（调用方持有book锁）
+func (s *DepthStream) startSyncLocked(book *depthBook, delay time.Duration) {
+	book.live = true
+	book.gen++
+	book.pending = nil
+	book.snapshotID = 0
+	book.applied = false
+	book.syncStart = time.Now()
+	book.status.Synced = false
+	go s.fetchSnapshot(book, book.gen, delay)
+}
+
+// resyncLocked 数据不连续时按指数退避重新同步（调用方持有book锁）
+func (s *DepthStream) resyncLocked(book *depthBook, err error) {
+	delay := book.backoff.next(time.Since(book.syncStart))
+	book.status.Resyncs++
+	book.status.LastError = err.Error()
+	utils.Warn("本地订单簿同步中断，稍后重新同步", zap.String("symbol", book.status.Symbol), zap.Duration("delay", delay), zap.Error(err))
+	s.startSyncLocked(book, delay)
+}
+
+// fetchSnapshot 等待delay后获取快照，应用快照和之前缓存的推送（期间重新同步过时丢弃结果）
+func (s *DepthStream) fetchSnapshot(book *depthBook, gen int, delay time.Duration) {
+	s.mu.Lock()
+	ctx := s.ctx
+	s.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+	book.mu.RLock()
+	symbol, current := book.status.Symbol, book.gen == gen
+	book.mu.RUnlock()
+	if !current {
+		return
+	}
+
+	snapshot, err := s.client.GetOrderBook(symbol, depthSnapshotLimit)
+
+	book.mu.Lock()
+	defer book.mu.Unlock()
+	if book.gen != gen || !book.live {
+		return
+	}
+	if err != nil {
+		s.resyncLocked(book, fmt.Errorf("获取订单簿快照失败: %w", err))
+		return
+	}
+
+	book.reset(snapshot)
+	pending := book.pending
+	book.pending = nil
+	for _, event := range pending {
+		if err := book.applyLocked(event); err != nil {
+			s.resyncLocked(book, err)
+			return
+		}
+	}
+	s.publishLocked(book)
+}
+
+// reset 按快照重建订单簿（应用第一条推送前标记为未同步，调用方持有锁）
+func (b *depthBook) reset(snapshot *OrderBook) {
+	b.bids = make(map[float64]float64, len(snapshot.Bids))
+	b.asks = make(map[float64]float64, len(snapshot.Asks))
+	applyLevels(b.bids, snapshot.Bids)
+	applyLevels(b.asks, snapshot.Asks)
+	b.snapshotID = snapshot.LastUpdateID
+	b.applied = false
+	b.status.Synced = false
+	b.status.LastUpdateID = snapshot.LastUpdateID
+	b.status.Bids, b.status.Asks = len(b.bids), len(b.asks)
+}
+
+// applyLocked 校验更新ID并应用一条增量推送（快照之前的推送丢弃，不衔接时返回错误，调用方持有锁）
+func (b *depthBook) applyLocked(event *depthEvent) error {
+	if !b.applied {
+		// 快照之前的推送丢弃；第一条应用的推送必须覆盖快照的更新ID
+		if event.FinalUpdateID < b.snapshotID {
+			return nil
+		}
+		if event.FirstUpdateID > b.snapshotID {
+			return fmt.Errorf("深度推送与快照不衔接: 快照 %d，推送 %d-%d", b.snapshotID, event.FirstUpdateID, event.FinalUpdateID)
+		}
+	} else if event.PrevUpdateID != b.status.LastUpdateID {
+		return fmt.Errorf("深度推送不连续: 上一条 %d，本条pu %d", b.status.LastUpdateID, event.PrevUpdateID)
+	}
+
+	applyLevels(b.bids, event.Bids)
+	applyLevels(b.asks, event.Asks)
+	b.status.Synced = true
+	b.status.LastUpdateID = event.FinalUpdateID
+	b.status.Updated = time.Now()
+	b.status.LastError = ""
+	b.status.Bids, b.status.Asks = len(b.bids), len(b.asks)
+	if !b.applied {
+		b.applied = true
+		utils.Info("本地订单簿已同步", zap.String("symbol", b.status.Symbol), zap.Int64("last_update_id", event.FinalUpdateID))
+	}
+	return nil
+}
+
+// applyLevels 更新档位（数量为0时删除）
+func applyLevels(side map[float64]float64, levels [][2]string) {
+	for _, level := range levels {
+		price, err := strconv.ParseFloat(level[0], 64)
+		if err != nil {
+			continue
+		}
+		qty, err := strconv.ParseFloat(level[1], 64)
+		if err != nil {
+			continue
+		}
+		if qty == 0 {
+			delete(side, price)
+		} else {
+			side[price] = qty
+		}
+	}
+}
+
+// book 获取已同步的本地订单簿
+func (s *DepthStream) book(symbol string) (*depthBook, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	book, ok := s.books[symbol]
+	s.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	book.mu.RLock()
+	synced := book.status.Synced
+	book.mu.RUnlock()
+	return book, synced
+}
+
+// OrderBook 本地订单簿的前limit档（格式与REST接口相同；未订阅或未同步时返回false）
+func (s *DepthStream) OrderBook(symbol string, limit int) (*OrderBook, bool) {
+	book, ok := s.book(symbol)
+	if !ok {
+		return nil, false
+	}
+
+	book.mu.RLock()
+	defer book.mu.RUnlock()
+	return &OrderBook{
+		LastUpdateID: book.status.LastUpdateID,
+		Bids:         topLevels(book.bids, limit, true),
+		Asks:         topLevels(book.asks, limit, false),
+	}, true
+}
+
+// BestBidAsk 本地订单簿的买一卖一价（未订阅、未同步或一侧为空时返回false）
+func (s *DepthStream) BestBidAsk(symbol string) (bid, ask float64, ok bool) {
+	book, synced := s.book(symbol)
+	if !synced {
+		return 0, 0, false
+	}
+
+	book.mu.RLock()
+	defer book.mu.RUnlock()
+	bid, ask = book.bestLocked()
+	return bid, ask, bid > 0 && ask > 0
+}
+
+// bestLocked 买一卖一价（一侧为空时为0，调用方持有锁）
+func (b *depthBook) bestLocked() (bid, ask float64) {
+	for price := range b.bids {
+		if price > bid {
+			bid = price
+		}
+	}
+	for price := range b.asks {
+		if ask == 0 || price < ask {
+			ask = price
+		}
+	}
+	return bid, ask
+}
+
+// topLevels 按价格排序取前limit档（desc为true时从高到低）
+func topLevels(side map[float64]float64, limit int, desc bool) [][2]string {
+	prices := make([]float64, 0, len(side))
+	for price := range side {
+		prices = append(prices, price)
+	}
+	if desc {
+		sort.Sort(sort.Reverse(sort.Float64Slice(prices)))
+	} else {
+		sort.Float64s(prices)
+	}
+	if limit > 0 && len(prices) > limit {
+		prices = prices[:limit]
+	}
+
+	levels := make([][2]string, len(prices))
+	for i, price := range prices {
+		levels[i] = [2]string{
+			strconv.FormatFloat(price, 'f', -1, 64),
+			strconv.FormatFloat(side[price], 'f', -1, 64),
+		}
+	}
+	return levels
+}
+
+// Status 各交易对本地订单簿的同步状态（按交易对排序）
+func (s *DepthStream) Status() []DepthBookStatus {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	books := make([]*depthBook, 0, len(s.books))
+	for _, book := range s.books {
+		books = append(books, book)
+	}
+	s.mu.Unlock()
+
+	statuses := make([]DepthBookStatus, 0, len(books))
+	for _, book := range books {
+		book.mu.RLock()
+		status := book.status
+		book.mu.RUnlock()
+		status.Shard, _ = s.streams.Shard(depthStreamName(status.Symbol))
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Symbol < statuses[j].Symbol })
+	return statuses
+}
+
+// Connections 推送连接数（包括断线重连中的连接）
+func (s *DepthStream) Connections() int {
+	if s == nil {
+		return 0
+	}
+	return s.streams.Connections()
+}
+
+// Subscribe 订阅盘口更新（name用于统计，capacity为最多缓存的交易对数）
+// 推送每100ms一条，消费者处理慢时同一交易对只保留最新一条，返回的通道在ctx取消后关闭
+func (s *DepthStream) Subscribe(ctx context.Context, name string, capacity int) <-chan DepthUpdate {
+	out := make(chan DepthUpdate)
+	if s == nil {
+		close(out)
+		return out
+	}
+	s.subscribers.subscribe(ctx, name, QueueConflate, capacity, func(value interface{}) bool {
+		select {
+		case out <- value.(DepthUpdate):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(out) })
+	return out
+}
+
+// Subscribers 各订阅队列的统计
+func (s *DepthStream) Subscribers() []QueueStats {
+	if s == nil {
+		return nil
+	}
+	return s.subscribers.Stats()
+}
@@ -14,12 +14,37 @@ const (
 	EndpointAccount      = "/fapi/v2/account"      // 获取账户信息
 	EndpointBalance      = "/fapi/v2/balance"      // 获取账户余额
 	EndpointPositionRisk = "/fapi/v2/positionRisk" // 获取持仓风险
+	EndpointIncome       = "/fapi/v1/income"       // 获取资金流水（已实现盈亏、手续费、资金费等）
+	EndpointCommission   = "/fapi/v1/commissionRate" // 获取用户手续费率
+	EndpointPositionMode = "/fapi/v1/positionSide/dual" // 查询/更改持仓模式（单向/双向）
+	EndpointMarginType   = "/fapi/v1/marginType"        // 更改保证金模式（全仓/逐仓）
+	EndpointLeverage     = "/fapi/v1/leverage"          // 调整杠杆倍数
+	EndpointPositionMargin = "/fapi/v1/positionMargin" // 调整逐仓保证金
 	
 	// 市场数据端点
-	EndpointKlines = "/fapi/v1/klines" // 获取K线数据
+	EndpointKlines       = "/fapi/v1/klines"            // 获取K线数据
+	EndpointExchangeInfo = "/fapi/v1/exchangeInfo"      // 获取交易规则
+	EndpointBookTicker   = "/fapi/v1/ticker/bookTicker" // 获取最优挂单
+	EndpointDepth        = "/fapi/v1/depth"             // 获取订单簿深度
+	EndpointTicker24h    = "/fapi/v1/ticker/24hr"       // 获取24小时行情
+
+	// 交易端点
+	EndpointOrder         = "/fapi/v1/order"         // 下单/查询/撤销订单
+	EndpointOpenOrders    = "/fapi/v1/openOrders"    // 查询当前挂单
+	EndpointAllOpenOrders = "/fapi/v1/allOpenOrders" // 撤销全部挂单
+	EndpointUserTrades    = "/fapi/v1/userTrades"    // 查询账户成交历史
 	
 	// 资金流数据端点
 	EndpointOpenInterest = "/fapi/v1/openInterest" // 获取持仓量
 	EndpointFundingRate  = "/fapi/v1/fundingRate"  // 获取资金费率历史
 	EndpointPremiumIndex = "/fapi/v1/premiumIndex" // 获取当前资金费率和标记价格
+
+	// 系统状态端点（只在现货域名提供，需使用现货客户端）
+	EndpointSystemStatus = "/sapi/v1/system/status" // 获取系统状态（0正常，1维护中）
+
+	// 万向划转端点（只在现货域名提供，需使用现货客户端）
+	EndpointAssetTransfer = "/sapi/v1/asset/transfer" // 现货与合约账户之间划转
+
+	// API密钥权限端点（只在现货域名提供，需使用现货客户端）
+	EndpointAPIRestrict = "/sapi/v1/account/apiRestrictions" // 查询API密钥权限和IP限制
 )
@@ -0,0 +1,99 @@
+/*
+Package binance 接口错误类型
+
+主要功能：
+- (e *APIError) Error() string           // 错误信息（与原始响应一起输出）
+- (e *APIError) Is(target error) bool    // 按错误码匹配常见错误（errors.Is(err, ErrInsufficientBalance) 等）
+- ErrorCode(err error) int               // 错误链中币安错误码（不是接口错误时返回0）
+
+HTTP状态码不是200时，请求返回 *APIError：解析响应体 {"code":...,"msg":...} 得到币安错误码和说明，
+调用方用 errors.Is 判断常见错误、用 errors.As 取得错误码，不需要匹配格式化后的错误信息。
+包装后的错误（fmt.Errorf("...: %w", err)）同样可以判断。
+*/
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 常见错误（用 errors.Is 判断）
+var (
+	ErrInsufficientBalance = errors.New("余额或保证金不足")          // -2018、-2019，现货 -2010 余额不足
+	ErrInvalidTimestamp    = errors.New("请求时间戳超出recvWindow") // -1021
+	ErrWouldTrigger        = errors.New("条件单会立即触发")          // -2021
+	ErrRateLimited         = errors.New("请求频率超限")            // -1003、HTTP 429、HTTP 418（IP被封禁）
+	ErrOrderNotFound       = errors.New("订单不存在")             // -2013、-2011（撤销时订单不存在）
+	ErrMarginTypeUnchanged = errors.New("保证金模式无需更改")         // -4046
+	ErrStatusUnknown       = errors.New("请求已发送但结果未知")        // -1007（交易所后端超时，订单可能已成交）
+	ErrInvalidAPIKey       = errors.New("API密钥无效或IP不在白名单")   // -2015、-2014、-2008（密钥错误、IP不在白名单或没有该接口的权限）
+)
+
+// 错误码 → 常见错误
+var errorCodes = map[int]error{
+	-2018: ErrInsufficientBalance,
+	-2019: ErrInsufficientBalance,
+	-1021: ErrInvalidTimestamp,
+	-2021: ErrWouldTrigger,
+	-1003: ErrRateLimited,
+	-2013: ErrOrderNotFound,
+	-2011: ErrOrderNotFound,
+	-4046: ErrMarginTypeUnchanged,
+	-1007: ErrStatusUnknown,
+	-2015: ErrInvalidAPIKey,
+	-2014: ErrInvalidAPIKey,
+	-2008: ErrInvalidAPIKey,
+}
+
+// APIError 币安接口返回的错误
+type APIError struct {
+	StatusCode int    // HTTP状态码
+	Code       int    // 币安错误码（响应体不是 {"code":...,"msg":...} 时为0）
+	Msg        string // 错误说明
+	Body       string // 原始响应
+}
+
+// newAPIError 按HTTP状态码和响应体创建接口错误
+func newAPIError(statusCode int, body []byte) *APIError {
+	e := &APIError{StatusCode: statusCode, Body: string(body)}
+	var payload struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		e.Code, e.Msg = payload.Code, payload.Msg
+	}
+	return e
+}
+
+// Error 错误信息（格式与之前的字符串错误一致：API错误 [HTTP状态码]: 原始响应）
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API错误 [%d]: %s", e.StatusCode, e.Body)
+}
+
+// Is 按错误码和HTTP状态码匹配常见错误
+func (e *APIError) Is(target error) bool {
+	if known, ok := errorCodes[e.Code]; ok && known == target {
+		return true
+	}
+	switch target {
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusTeapot
+	case ErrInsufficientBalance:
+		// 现货余额不足：-2010 是通用的下单被拒绝，需要看说明
+		return e.Code == -2010 && strings.Contains(strings.ToLower(e.Msg), "insufficient balance")
+	}
+	return false
+}
+
+// ErrorCode 错误链中的币安错误码（不是接口错误或没有错误码时返回0）
+func ErrorCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return 0
+}
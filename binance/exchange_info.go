@@ -0,0 +1,145 @@
+/*
+Package binance 交易规则相关API
+
+主要功能：
+- (c *Client) GetExchangeInfo() (*ExchangeInfo, error)        // 获取交易规则
+- (e *ExchangeInfo) GetSymbol(symbol string) *SymbolInfo      // 获取单个交易对规则
+- (s *SymbolInfo) FormatQuantity(qty float64) string          // 按stepSize向下取整并格式化数量
+- (s *SymbolInfo) FormatPrice(price float64) string           // 按tickSize取整并格式化价格
+- (s *SymbolInfo) MinNotionalValue() float64                  // 最小名义价值
+*/
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// ExchangeInfo 交易规则
+type ExchangeInfo struct {
+	ServerTime int64        `json:"serverTime"` // 服务器时间
+	Symbols    []SymbolInfo `json:"symbols"`    // 交易对规则
+}
+
+// SymbolInfo 交易对规则
+type SymbolInfo struct {
+	Symbol            string         `json:"symbol"`            // 交易对
+	Status            string         `json:"status"`            // 状态（TRADING为可交易）
+	ContractType      string         `json:"contractType"`      // 合约类型（PERPETUAL）
+	BaseAsset         string         `json:"baseAsset"`         // 标的资产
+	QuoteAsset        string         `json:"quoteAsset"`        // 报价资产
+	MarginAsset       string         `json:"marginAsset"`       // 保证金资产（U本位为USDT，币本位为标的币种）
+	ContractSize      float64        `json:"contractSize"`      // 合约面值（仅币本位，单位USD）
+	PricePrecision    int            `json:"pricePrecision"`    // 价格精度
+	QuantityPrecision int            `json:"quantityPrecision"` // 数量精度
+	Filters           []SymbolFilter `json:"filters"`           // 过滤器
+
+	// 从Filters中解析出的常用规则
+	TickSize    float64 `json:"-"` // 价格步长
+	StepSize    float64 `json:"-"` // 数量步长
+	MinQty      float64 `json:"-"` // 最小数量
+	MinNotional float64 `json:"-"` // 最小名义价值
+}
+
+// SymbolFilter 交易对过滤器
+type SymbolFilter struct {
+	FilterType      string `json:"filterType"`            // 过滤器类型
+	TickSize        string `json:"tickSize,omitempty"`    // PRICE_FILTER: 价格步长
+	StepSize        string `json:"stepSize,omitempty"`    // LOT_SIZE: 数量步长
+	MinQty          string `json:"minQty,omitempty"`      // LOT_SIZE: 最小数量
+	Notional        string `json:"notional,omitempty"`    // MIN_NOTIONAL: 最小名义价值
+	MinNotionalSpot string `json:"minNotional,omitempty"` // 现货 NOTIONAL/MIN_NOTIONAL: 最小名义价值
+}
+
+// GetExchangeInfo 获取交易规则
+func (c *Client) GetExchangeInfo() (*ExchangeInfo, error) {
+	utils.Debug("获取交易规则")
+
+	body, err := c.doRequest("GET", EndpointExchangeInfo, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易规则失败: %w", err)
+	}
+
+	var info ExchangeInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("解析交易规则失败: %w", err)
+	}
+
+	// 解析常用过滤器
+	for i := range info.Symbols {
+		info.Symbols[i].parseFilters()
+	}
+
+	utils.Info("获取交易规则成功", zap.Int("symbols", len(info.Symbols)))
+
+	return &info, nil
+}
+
+// GetSymbol 获取单个交易对规则
+func (e *ExchangeInfo) GetSymbol(symbol string) *SymbolInfo {
+	for i := range e.Symbols {
+		if e.Symbols[i].Symbol == symbol {
+			return &e.Symbols[i]
+		}
+	}
+	return nil
+}
+
+// FormatQuantity 按stepSize向下取整并格式化数量
+func (s *SymbolInfo) FormatQuantity(qty float64) string {
+	if s.StepSize <= 0 {
+		return strconv.FormatFloat(qty, 'f', s.QuantityPrecision, 64)
+	}
+	steps := math.Floor(qty/s.StepSize + 1e-9)
+	return strconv.FormatFloat(steps*s.StepSize, 'f', decimalPlaces(s.StepSize), 64)
+}
+
+// FormatPrice 按tickSize四舍五入并格式化价格
+func (s *SymbolInfo) FormatPrice(price float64) string {
+	if s.TickSize <= 0 {
+		return strconv.FormatFloat(price, 'f', s.PricePrecision, 64)
+	}
+	ticks := math.Round(price / s.TickSize)
+	return strconv.FormatFloat(ticks*s.TickSize, 'f', decimalPlaces(s.TickSize), 64)
+}
+
+// MinNotionalValue 最小名义价值
+func (s *SymbolInfo) MinNotionalValue() float64 {
+	return s.MinNotional
+}
+
+// parseFilters 解析过滤器中的常用规则
+func (s *SymbolInfo) parseFilters() {
+	for _, f := range s.Filters {
+		switch f.FilterType {
+		case "PRICE_FILTER":
+			s.TickSize, _ = strconv.ParseFloat(f.TickSize, 64)
+		case "LOT_SIZE":
+			s.StepSize, _ = strconv.ParseFloat(f.StepSize, 64)
+			s.MinQty, _ = strconv.ParseFloat(f.MinQty, 64)
+		case "MIN_NOTIONAL", "NOTIONAL":
+			value := f.Notional
+			if value == "" {
+				value = f.MinNotionalSpot
+			}
+			s.MinNotional, _ = strconv.ParseFloat(value, 64)
+		}
+	}
+}
+
+// decimalPlaces 计算步长的小数位数（如0.001 → 3）
+func decimalPlaces(step float64) int {
+	str := strconv.FormatFloat(step, 'f', -1, 64)
+	idx := strings.IndexByte(str, '.')
+	if idx < 0 {
+		return 0
+	}
+	return len(str) - idx - 1
+}
@@ -0,0 +1,92 @@
+/*
+Package binance 资金流水（收益历史）
+
+主要功能：
+- (c *Client) GetIncomeHistory(symbol, incomeType string, startTime, endTime int64) ([]Income, error)  // 获取资金流水（自动翻页）
+- (i *Income) IncomeFloat() float64                                                                  // 流水金额
+*/
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 资金流水类型
+const (
+	IncomeTypeTransfer    = "TRANSFER"     // 划转
+	IncomeTypeRealizedPnL = "REALIZED_PNL" // 已实现盈亏（不含手续费）
+	IncomeTypeFundingFee  = "FUNDING_FEE"  // 资金费（负数为支付）
+	IncomeTypeCommission  = "COMMISSION"   // 手续费（负数）
+)
+
+const incomePageLimit = 1000 // 单页最大条数
+
+// Income 资金流水
+type Income struct {
+	Symbol     string `json:"symbol"`     // 交易对（划转等为空）
+	IncomeType string `json:"incomeType"` // 流水类型
+	Income     string `json:"income"`     // 金额（正数为收入，负数为支出）
+	Asset      string `json:"asset"`      // 资产
+	Info       string `json:"info"`       // 备注
+	Time       int64  `json:"time"`       // 时间
+	TranID     int64  `json:"tranId"`     // 流水ID
+	TradeID    string `json:"tradeId"`    // 成交ID（已实现盈亏和手续费有）
+}
+
+// GetIncomeHistory 获取资金流水（自动翻页）
+// symbol: 交易对，为空表示全部
+// incomeType: 流水类型，为空表示全部
+// startTime/endTime: 毫秒时间戳
+func (c *Client) GetIncomeHistory(symbol, incomeType string, startTime, endTime int64) ([]Income, error) {
+	var all []Income
+	for {
+		params := map[string]string{
+			"startTime": strconv.FormatInt(startTime, 10),
+			"endTime":   strconv.FormatInt(endTime, 10),
+			"limit":     strconv.Itoa(incomePageLimit),
+		}
+		if symbol != "" {
+			params["symbol"] = symbol
+		}
+		if incomeType != "" {
+			params["incomeType"] = incomeType
+		}
+
+		body, err := c.doRequest("GET", EndpointIncome, params, true)
+		if err != nil {
+			return nil, fmt.Errorf("获取资金流水失败: %w", err)
+		}
+
+		var page []Income
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("解析资金流水失败: %w", err)
+		}
+		all = append(all, page...)
+
+		// 不满一页说明已取完，否则从最后一条之后继续
+		if len(page) < incomePageLimit {
+			break
+		}
+		startTime = page[len(page)-1].Time + 1
+	}
+
+	utils.Debug("获取资金流水成功",
+		zap.String("symbol", symbol),
+		zap.String("income_type", incomeType),
+		zap.Int("count", len(all)),
+	)
+
+	return all, nil
+}
+
+// IncomeFloat 流水金额
+func (i *Income) IncomeFloat() float64 {
+	income, _ := strconv.ParseFloat(i.Income, 64)
+	return income
+}
@@ -0,0 +1,141 @@
+/*
+Package binance API密钥权限与IP限制检查
+
+主要功能：
+- (c *Client) GetAPIRestrictions() (*APIRestrictions, error)               // 查询API密钥的权限和IP限制（只支持现货客户端）
+- CheckKey(spot *Client, accountID string, req KeyRequirement) *KeyReport  // 按账号的用途检查密钥，返回检查报告
+- (r *KeyReport) Failed() bool                                             // 是否有不满足的项
+- (r *KeyReport) String() string                                           // 报告文本（每项一行）
+
+检查项：
+1. 签名请求能否成功：密钥错误、IP不在白名单时交易所返回 -2015 等错误，报告中带上交易所返回的说明（含请求IP）
+2. 交易权限：合约账号需要开启合约交易，现货账号需要开启现货交易
+3. 提现权限：默认不允许开启
+4. IP限制：require_ip_restrict 时要求密钥只允许白名单IP访问
+5. 万向划转：启用资金调拨的账号需要开启万向划转权限
+
+接口 /sapi/v1/account/apiRestrictions 只在现货域名提供，合约账号需要用同一组API密钥创建现货客户端。
+*/
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIRestrictions API密钥的权限和IP限制
+type APIRestrictions struct {
+	IPRestrict                     bool  `json:"ipRestrict"`                     // 是否只允许白名单IP访问
+	CreateTime                     int64 `json:"createTime"`                     // 创建时间（毫秒）
+	EnableReading                  bool  `json:"enableReading"`                  // 读取权限
+	EnableSpotAndMarginTrading     bool  `json:"enableSpotAndMarginTrading"`     // 现货和杠杆交易权限
+	EnableFutures                  bool  `json:"enableFutures"`                  // 合约交易权限
+	EnableWithdrawals              bool  `json:"enableWithdrawals"`              // 提现权限
+	EnableInternalTransfer         bool  `json:"enableInternalTransfer"`         // 内部转账权限
+	PermitsUniversalTransfer       bool  `json:"permitsUniversalTransfer"`       // 万向划转权限
+	TradingAuthorityExpirationTime int64 `json:"tradingAuthorityExpirationTime"` // 交易权限过期时间（毫秒，未设置时为0）
+}
+
+// KeyRequirement 账号对API密钥的要求
+type KeyRequirement struct {
+	MarketType        string // 市场类型（usdt_m、coin_m 需要合约交易权限，spot 需要现货交易权限）
+	Transfer          bool   // 需要万向划转权限（资金调拨）
+	RequireIPRestrict bool   // 要求开启IP白名单限制
+	AllowWithdrawals  bool   // 允许开启提现权限
+}
+
+// KeyReport API密钥检查报告
+type KeyReport struct {
+	AccountID    string
+	Restrictions *APIRestrictions // 交易所返回的权限（请求失败时为nil）
+	Problems     []string         // 不满足的项
+}
+
+// GetAPIRestrictions 查询API密钥的权限和IP限制（只能通过现货客户端查询）
+func (c *Client) GetAPIRestrictions() (*APIRestrictions, error) {
+	if !c.IsSpot() {
+		return nil, fmt.Errorf("%w: API密钥权限只能通过现货客户端查询", ErrUnsupportedMarket)
+	}
+	body, err := c.doRequest("GET", EndpointAPIRestrict, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("查询API密钥权限失败: %w", err)
+	}
+
+	var r APIRestrictions
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("解析API密钥权限失败: %w", err)
+	}
+	return &r, nil
+}
+
+// CheckKey 按账号的用途检查API密钥，返回检查报告
+func CheckKey(spot *Client, accountID string, req KeyRequirement) *KeyReport {
+	report := &KeyReport{AccountID: accountID}
+	r, err := spot.GetAPIRestrictions()
+	if err != nil {
+		report.Problems = append(report.Problems, keyRequestProblem(err))
+		return report
+	}
+	report.Restrictions = r
+
+	switch req.MarketType {
+	case MarketTypeSpot:
+		if !r.EnableSpotAndMarginTrading {
+			report.Problems = append(report.Problems, "未开启现货交易权限（enableSpotAndMarginTrading）")
+		}
+	default:
+		if !r.EnableFutures {
+			report.Problems = append(report.Problems, "未开启合约交易权限（enableFutures）")
+		}
+	}
+	if exp := r.TradingAuthorityExpirationTime; exp > 0 && time.UnixMilli(exp).Before(time.Now()) {
+		report.Problems = append(report.Problems, fmt.Sprintf("交易权限已于 %s 过期", time.UnixMilli(exp).UTC().Format(time.RFC3339)))
+	}
+	if r.EnableWithdrawals && !req.AllowWithdrawals {
+		report.Problems = append(report.Problems, "开启了提现权限（enableWithdrawals），交易程序使用的密钥不应允许提现")
+	}
+	if !r.IPRestrict && req.RequireIPRestrict {
+		report.Problems = append(report.Problems, "未开启IP白名单限制（ipRestrict），任何IP都可以使用该密钥")
+	}
+	if req.Transfer && !r.PermitsUniversalTransfer {
+		report.Problems = append(report.Problems, "启用了资金调拨，但未开启万向划转权限（permitsUniversalTransfer）")
+	}
+	return report
+}
+
+// keyRequestProblem 查询权限失败的原因
+func keyRequestProblem(err error) string {
+	var apiErr *APIError
+	switch {
+	case errors.Is(err, ErrInvalidAPIKey) && errors.As(err, &apiErr):
+		// 交易所的说明中带有请求IP（如 request ip: 1.2.3.4），可用于核对白名单
+		return fmt.Sprintf("API密钥无效或当前IP不在白名单（错误码 %d: %s）", apiErr.Code, apiErr.Msg)
+	case ErrorCode(err) == -1022:
+		return "签名无效，请检查API Secret（错误码 -1022）"
+	case errors.Is(err, ErrInvalidTimestamp):
+		return "请求时间戳超出recvWindow，请检查本机时间（错误码 -1021）"
+	}
+	return err.Error()
+}
+
+// Failed 是否有不满足的项
+func (r *KeyReport) Failed() bool {
+	return len(r.Problems) > 0
+}
+
+// String 报告文本（每项一行）
+func (r *KeyReport) String() string {
+	if !r.Failed() {
+		return fmt.Sprintf("账号[%s]API密钥检查通过（IP限制: %v）", r.AccountID, r.Restrictions.IPRestrict)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "账号[%s]API密钥检查未通过（%d项）:", r.AccountID, len(r.Problems))
+	for _, p := range r.Problems {
+		fmt.Fprintf(&b, "\n  - %s", p)
+	}
+	return b.String()
+}
@@ -0,0 +1,501 @@
+/*
+Package binance 全市场强平订单推送（!forceOrder@arr）
+
+主要功能：
+- NewLiquidationStream(baseURL, proxyURL string, windows []time.Duration, rule CascadeRule) *LiquidationStream  // 创建强平推送（baseURL如 wss://fstream.binance.com）
+- (s *LiquidationStream) Run(ctx context.Context)                     // 连接并持续接收推送，断线后按指数退避重连，直到ctx取消
+- (s *LiquidationStream) Stats(symbol string) LiquidationStats        // 交易对各滚动窗口的强平名义价值
+- (s *LiquidationStream) Cascade(symbol string) (bool, string)        // 交易对或全市场是否处于连环强平中（返回原因）
+- (s *LiquidationStream) Cascades() []LiquidationCascade              // 当前处于连环强平中的交易对（Symbol为空表示全市场）
+- (s *LiquidationStream) Totals(symbol string) []utils.Point[LiquidationTotal]  // 交易对每分钟的强平汇总（从旧到新）
+- (s *LiquidationStream) Status() LiquidationStreamStatus             // 连接状态
+- (s *LiquidationStream) Subscribe(ctx context.Context, name string, capacity int) <-chan LiquidationEvent  // 订阅逐条强平（队列满时丢弃最旧的）
+- (s *LiquidationStream) Subscribers() []QueueStats                   // 各订阅队列的统计
+
+强平卖单（S=SELL）是多头被强平，强平买单是空头被强平，名义价值 = 成交均价 × 累计成交数量（USDT）。
+交易所对每个交易对每秒最多推送一条最新的强平订单，统计值偏小，适合看相对强度而不是精确总额。
+只支持U本位合约（币本位合约数量为张数，需要按合约面值换算）。
+强平按分钟汇总保存在每个交易对的环形缓冲中（覆盖最长窗口），内存占用与强平数量无关；
+窗口统计包含与窗口有重叠的整分钟，边界精度为1分钟。每5秒检查一次连环强平状态，开始和结束时记录日志。
+交易所不提供强平订单的REST查询接口，断线期间的强平无法补齐：重连后记录断线时长（Gaps、LastGapSec），
+断线期间覆盖的窗口统计偏小。
+nil 的 *LiquidationStream 可以安全调用，Cascade 始终返回false。
+*/
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// liquidationStreamPath 全市场强平订单推送路径
+const liquidationStreamPath = "/ws/!forceOrder@arr"
+
+// liquidationCheckInterval 清理过期事件、检查连环强平状态的间隔
+const liquidationCheckInterval = 5 * time.Second
+
+// marketCascadeKey 全市场连环强平在状态表中的key
+const marketCascadeKey = ""
+
+// CascadeRule 连环强平判定规则（阈值为0表示不按该项判定）
+type CascadeRule struct {
+	Window         time.Duration // 统计窗口
+	SymbolNotional float64       // 单个交易对窗口内强平名义价值（USDT）达到该值
+	MarketNotional float64       // 全市场窗口内强平名义价值（USDT）达到该值
+}
+
+// LiquidationWindow 滚动窗口内的强平统计
+type LiquidationWindow struct {
+	Minutes       int     `json:"minutes"`
+	LongNotional  float64 `json:"long_notional"`  // 多头被强平的名义价值（USDT）
+	ShortNotional float64 `json:"short_notional"` // 空头被强平的名义价值（USDT）
+	Count         int     `json:"count"`          // 强平订单数
+}
+
+// LiquidationStats 交易对的强平统计
+type LiquidationStats struct {
+	Symbol  string              `json:"symbol"`
+	Windows []LiquidationWindow `json:"windows"`
+	Cascade bool                `json:"cascade"` // 交易对或全市场是否处于连环强平中
+}
+
+// LiquidationCascade 进行中的连环强平
+type LiquidationCascade struct {
+	Symbol   string    `json:"symbol"`   // 为空表示全市场
+	Notional float64   `json:"notional"` // 统计窗口内的强平名义价值（USDT）
+	Since    time.Time `json:"since"`    // 开始时间
+}
+
+// LiquidationTotal 一分钟内的强平汇总
+type LiquidationTotal struct {
+	LongNotional  float64 `json:"long_notional"`  // 多头被强平的名义价值（USDT）
+	ShortNotional float64 `json:"short_notional"` // 空头被强平的名义价值（USDT）
+	Count         int     `json:"count"`          // 强平订单数
+}
+
+// LiquidationEvent 一条强平订单（订阅使用）
+type LiquidationEvent struct {
+	Symbol   string    `json:"symbol"`
+	Long     bool      `json:"long"` // 多头被强平（强平卖单）
+	Price    float64   `json:"price"`
+	Quantity float64   `json:"quantity"`
+	Notional float64   `json:"notional"` // 名义价值（USDT）
+	Time     time.Time `json:"time"`
+}
+
+// LiquidationStreamStatus 强平推送连接状态
+type LiquidationStreamStatus struct {
+	URL         string    `json:"url"`
+	Connected   bool      `json:"connected"`
+	ConnectedAt time.Time `json:"connected_at"`
+	LastMessage time.Time `json:"last_message"`
+	Events      int       `json:"events"` // 最长窗口内的强平事件数
+	Reconnects  int       `json:"reconnects"`
+	Gaps        int       `json:"gaps"`         // 断线后重连成功的次数（断线期间的强平缺失）
+	LastGapSec  float64   `json:"last_gap_sec"` // 最近一次断线的时长（秒）
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// forceOrderEvent 强平订单推送（字段名区分大小写，同名的大小写字段都需要声明）
+type forceOrderEvent struct {
+	EventType string `json:"e"`
+	EventTime int64  `json:"E"`
+	Order     struct {
+		Symbol      string `json:"s"`
+		Side        string `json:"S"`
+		OrderType   string `json:"o"`
+		TimeInForce string `json:"f"`
+		Quantity    string `json:"q"`
+		Price       string `json:"p"`
+		AvgPrice    string `json:"ap"`
+		Status      string `json:"X"`
+		LastFilled  string `json:"l"`
+		FilledQty   string `json:"z"`
+		TradeTime   int64  `json:"T"`
+	} `json:"o"`
+}
+
+// LiquidationStream 全市场强平订单推送
+type LiquidationStream struct {
+	url     string
+	proxy   string
+	windows []time.Duration
+	rule    CascadeRule
+	keep    time.Duration // 汇总保留时间（最长窗口）
+
+	mu       sync.Mutex
+	totals   map[string]*utils.Ring[LiquidationTotal] // symbol -> 每分钟的强平汇总
+	cascades map[string]*LiquidationCascade
+	status   LiquidationStreamStatus
+
+	disconnectedAt time.Time // 最近一次断线的时间（重连后计算缺失时长）
+	subscribers    *streamSubscribers
+}
+
+// NewLiquidationStream 创建强平推送（windows为统计窗口，rule为连环强平判定规则）
+func NewLiquidationStream(baseURL, proxyURL string, windows []time.Duration, rule CascadeRule) *LiquidationStream {
+	streamURL := baseURL + liquidationStreamPath
+	keep := rule.Window
+	for _, w := range windows {
+		keep = max(keep, w)
+	}
+	return &LiquidationStream{
+		url:      streamURL,
+		proxy:    proxyURL,
+		windows:  windows,
+		rule:     rule,
+		keep:     keep,
+		totals:   make(map[string]*utils.Ring[LiquidationTotal]),
+		cascades: make(map[string]*LiquidationCascade),
+		status:   LiquidationStreamStatus{URL: streamURL},
+
+		subscribers: newStreamSubscribers(),
+	}
+}
+
+// Run 连接并持续接收推送，断线后按指数退避重连，直到ctx取消
+func (s *LiquidationStream) Run(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(liquidationCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				s.check(now)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var backoff wsBackoff
+	for {
+		start := time.Now()
+		err := s.receive(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		delay := backoff.next(time.Since(start))
+
+		s.mu.Lock()
+		if s.status.Connected {
+			s.disconnectedAt = time.Now()
+		}
+		s.status.Connected = false
+		s.status.Reconnects++
+		if err != nil {
+			s.status.LastError = err.Error()
+		}
+		s.mu.Unlock()
+		utils.Warn("强平推送连接中断，稍后重连", zap.String("url", s.url), zap.Duration("delay", delay), zap.Error(err))
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// receive 建立一次连接并接收推送，连接出错时返回
+func (s *LiquidationStream) receive(ctx context.Context) error {
+	conn, err := dialWS(ctx, s.url, s.proxy)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	now := time.Now()
+	var gap time.Duration
+	if !s.disconnectedAt.IsZero() {
+		gap = now.Sub(s.disconnectedAt)
+		s.status.Gaps++
+		s.status.LastGapSec = gap.Seconds()
+	}
+	s.status.Connected = true
+	s.status.ConnectedAt = now
+	s.status.LastError = ""
+	s.mu.Unlock()
+	if gap > 0 {
+		utils.Warn("强平推送已重连，断线期间的强平无法补齐", zap.String("url", s.url), zap.Duration("gap", gap))
+	} else {
+		utils.Info("强平推送已连接", zap.String("url", s.url))
+	}
+
+	for {
+		// 强平只在发生时推送，行情平静时可能几分钟没有数据，不限制等待时间（连接是否存活由ping/pong检测）
+		message, err := conn.ReadMessage(0)
+		if err != nil {
+			return err
+		}
+		var event forceOrderEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			utils.Warn("解析强平推送失败", zap.Error(err))
+			continue
+		}
+		s.add(&event, time.Now())
+	}
+}
+
+// add 记录一条强平订单，并交给订阅者
+func (s *LiquidationStream) add(event *forceOrderEvent, now time.Time) {
+	price, _ := strconv.ParseFloat(event.Order.AvgPrice, 64)
+	if price <= 0 {
+		price, _ = strconv.ParseFloat(event.Order.Price, 64)
+	}
+	qty, _ := strconv.ParseFloat(event.Order.FilledQty, 64)
+	if price <= 0 || qty <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	symbol := event.Order.Symbol
+	totals, ok := s.totals[symbol]
+	if !ok {
+		// 多保留一分钟：最早的一分钟只有一部分落在窗口内
+		totals = utils.NewRing[LiquidationTotal](int(s.keep/time.Minute) + 2)
+		s.totals[symbol] = totals
+	}
+	minute := now.Truncate(time.Minute)
+	latest, ok := totals.Latest()
+	if !ok || !latest.Time.Equal(minute) {
+		totals.Push(minute, LiquidationTotal{})
+		latest.Value = LiquidationTotal{}
+	}
+	if event.Order.Side == SideSell {
+		latest.Value.LongNotional += price * qty
+	} else {
+		latest.Value.ShortNotional += price * qty
+	}
+	latest.Value.Count++
+	totals.Update(latest.Value)
+
+	s.status.LastMessage = now
+	s.status.Events++
+	s.updateCascadeLocked(symbol, now)
+	s.updateCascadeLocked(marketCascadeKey, now)
+
+	s.subscribers.publish(symbol, LiquidationEvent{
+		Symbol:   symbol,
+		Long:     event.Order.Side == SideSell,
+		Price:    price,
+		Quantity: qty,
+		Notional: price * qty,
+		Time:     now,
+	})
+}
+
+// check 移除最长窗口内没有强平的交易对，更新事件数和所有连环强平状态
+func (s *LiquidationStream) check(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	for symbol, totals := range s.totals {
+		points := windowTotals(totals, s.keep, now)
+		if len(points) == 0 {
+			delete(s.totals, symbol)
+			continue
+		}
+		for _, p := range points {
+			total += p.Value.Count
+		}
+	}
+	s.status.Events = total
+
+	for key := range s.cascades {
+		s.updateCascadeLocked(key, now)
+	}
+}
+
+// windowTotals 与窗口有重叠的每分钟汇总（从旧到新）
+func windowTotals(totals *utils.Ring[LiquidationTotal], window time.Duration, now time.Time) []utils.Point[LiquidationTotal] {
+	return totals.Since(now.Add(-window - time.Minute))
+}
+
+// notionalLocked 窗口内的强平名义价值（key为空时统计全市场）
+func (s *LiquidationStream) notionalLocked(key string, window time.Duration, now time.Time) float64 {
+	sum := func(totals *utils.Ring[LiquidationTotal]) float64 {
+		total := 0.0
+		for _, p := range windowTotals(totals, window, now) {
+			total += p.Value.LongNotional + p.Value.ShortNotional
+		}
+		return total
+	}
+	if key != marketCascadeKey {
+		if totals, ok := s.totals[key]; ok {
+			return sum(totals)
+		}
+		return 0
+	}
+	total := 0.0
+	for _, totals := range s.totals {
+		total += sum(totals)
+	}
+	return total
+}
+
+// updateCascadeLocked 按规则更新交易对或全市场的连环强平状态，开始和结束时记录日志
+func (s *LiquidationStream) updateCascadeLocked(key string, now time.Time) {
+	threshold := s.rule.SymbolNotional
+	if key == marketCascadeKey {
+		threshold = s.rule.MarketNotional
+	}
+	if threshold <= 0 || s.rule.Window <= 0 {
+		return
+	}
+
+	notional := s.notionalLocked(key, s.rule.Window, now)
+	cascade, active := s.cascades[key]
+	switch {
+	case notional >= threshold && !active:
+		s.cascades[key] = &LiquidationCascade{Symbol: key, Notional: notional, Since: now}
+		utils.Warn("连环强平进行中",
+			zap.String("symbol", cascadeLabel(key)),
+			zap.Float64("notional_usdt", notional),
+			zap.Float64("threshold_usdt", threshold),
+			zap.Duration("window", s.rule.Window),
+		)
+	case notional >= threshold:
+		cascade.Notional = notional
+	case active:
+		delete(s.cascades, key)
+		utils.Info("连环强平已结束",
+			zap.String("symbol", cascadeLabel(key)),
+			zap.Duration("duration", now.Sub(cascade.Since)),
+		)
+	}
+}
+
+// cascadeLabel 日志中的交易对名称
+func cascadeLabel(key string) string {
+	if key == marketCascadeKey {
+		return "全市场"
+	}
+	return key
+}
+
+// Stats 交易对各滚动窗口的强平名义价值
+func (s *LiquidationStream) Stats(symbol string) LiquidationStats {
+	stats := LiquidationStats{Symbol: symbol}
+	if s == nil {
+		return stats
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	totals := s.totals[symbol]
+	for _, window := range s.windows {
+		w := LiquidationWindow{Minutes: int(window.Minutes())}
+		if totals != nil {
+			for _, p := range windowTotals(totals, window, now) {
+				w.LongNotional += p.Value.LongNotional
+				w.ShortNotional += p.Value.ShortNotional
+				w.Count += p.Value.Count
+			}
+		}
+		stats.Windows = append(stats.Windows, w)
+	}
+	_, symbolCascade := s.cascades[symbol]
+	_, marketCascade := s.cascades[marketCascadeKey]
+	stats.Cascade = symbolCascade || marketCascade
+	return stats
+}
+
+// Cascade 交易对或全市场是否处于连环强平中（返回原因，供暂停开仓使用）
+func (s *LiquidationStream) Cascade(symbol string) (bool, string) {
+	if s == nil {
+		return false, ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.cascades[symbol]; ok {
+		return true, fmt.Sprintf("%s %v内强平 %.0f USDT", symbol, s.rule.Window, c.Notional)
+	}
+	if c, ok := s.cascades[marketCascadeKey]; ok {
+		return true, fmt.Sprintf("全市场 %v内强平 %.0f USDT", s.rule.Window, c.Notional)
+	}
+	return false, ""
+}
+
+// Cascades 当前处于连环强平中的交易对（Symbol为空表示全市场）
+func (s *LiquidationStream) Cascades() []LiquidationCascade {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cascades := make([]LiquidationCascade, 0, len(s.cascades))
+	for _, c := range s.cascades {
+		cascades = append(cascades, *c)
+	}
+	sort.Slice(cascades, func(i, j int) bool { return cascades[i].Symbol < cascades[j].Symbol })
+	return cascades
+}
+
+// Totals 交易对每分钟的强平汇总（从旧到新，最多覆盖最长窗口，没有强平的分钟不记录）
+func (s *LiquidationStream) Totals(symbol string) []utils.Point[LiquidationTotal] {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	totals, ok := s.totals[symbol]
+	if !ok {
+		return nil
+	}
+	return windowTotals(totals, s.keep, time.Now())
+}
+
+// Status 连接状态
+func (s *LiquidationStream) Status() LiquidationStreamStatus {
+	if s == nil {
+		return LiquidationStreamStatus{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Subscribe 订阅逐条强平订单（name用于统计，capacity为最多缓存的条数）
+// 消费者处理慢、队列满时丢弃最旧的一条，返回的通道在ctx取消后关闭
+func (s *LiquidationStream) Subscribe(ctx context.Context, name string, capacity int) <-chan LiquidationEvent {
+	out := make(chan LiquidationEvent)
+	if s == nil {
+		close(out)
+		return out
+	}
+	s.subscribers.subscribe(ctx, name, QueueDropOldest, capacity, func(value interface{}) bool {
+		select {
+		case out <- value.(LiquidationEvent):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(out) })
+	return out
+}
+
+// Subscribers 各订阅队列的统计
+func (s *LiquidationStream) Subscribers() []QueueStats {
+	if s == nil {
+		return nil
+	}
+	return s.subscribers.Stats()
+}
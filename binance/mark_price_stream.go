@@ -0,0 +1,397 @@
+/*
+Package binance 全市场标记价格推送（!markPrice@arr）
+
+主要功能：
+- NewMarkPriceStream(client *Client, baseURL, proxyURL string, maxAge time.Duration) *MarkPriceStream  // 创建标记价格推送（client用于连接后补齐数据，baseURL如 wss://fstream.binance.com）
+- (s *MarkPriceStream) Run(ctx context.Context)                                         // 连接并持续接收推送，断线后按指数退避重连，直到ctx取消
+- (s *MarkPriceStream) Get(symbol string) (MarkPriceUpdate, bool)                       // 获取交易对最新的标记价格和资金费率（超过maxAge未更新时返回false）
+- (s *MarkPriceStream) Price(symbol string) (float64, bool)                             // 获取交易对最新的标记价格
+- (s *MarkPriceStream) FundingRate(symbol string) (float64, bool)                       // 获取交易对当前资金费率
+- (s *MarkPriceStream) Premium(symbol string) (float64, bool)                           // 获取交易对标记价格相对指数价格的偏离（%）
+- PremiumPct(stream *MarkPriceStream, client *Client, symbol string) (float64, error)   // 标记价格相对指数价格的偏离（%，推送不可用时使用REST接口）
+- (s *MarkPriceStream) Snapshot() []MarkPriceUpdate                                     // 所有交易对的最新数据（按交易对排序）
+- (s *MarkPriceStream) Candles(symbol string, n int) []utils.Point[MarkPriceCandle]     // 交易对最近n根1分钟标记价格K线（从旧到新）
+- (s *MarkPriceStream) Closes(symbol string, n int) []float64                           // 最近n根1分钟K线的收盘价（供指标计算）
+- (s *MarkPriceStream) Status() MarkPriceStreamStatus                                   // 连接状态
+- (s *MarkPriceStream) Subscribe(ctx context.Context, name string, capacity int) <-chan MarkPriceUpdate  // 订阅标记价格更新（同一交易对只保留最新一条）
+- (s *MarkPriceStream) Subscribers() []QueueStats                                       // 各订阅队列的统计
+
+U本位和币本位合约都支持（币本位使用 wss://dstream.binance.com，交易对为 BTCUSD_PERP 格式），每秒推送一次全部交易对。
+数据只保存在内存中；连接中断或推送延迟时 Get 返回false，调用方应改用REST接口，不会使用过期价格。
+每个交易对按收到的标记价格生成1分钟K线，最近60根保存在环形缓冲中，最后一根是当前未走完的一分钟。
+每次连接（包括重连）成功后先用REST接口获取一次全部交易对的标记价格，补齐断线期间的数据，不必等待第一条推送。
+nil 的 *MarkPriceStream 可以安全调用，Get 等方法始终返回false。
+*/
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// markPriceStreamPath 全市场标记价格推送路径（每秒一次）
+const markPriceStreamPath = "/ws/!markPrice@arr@1s"
+
+// markCandleHistory 每个交易对保留的1分钟标记价格K线数
+const markCandleHistory = 60
+
+// MarkPriceUpdate 交易对最新的标记价格和资金费率
+type MarkPriceUpdate struct {
+	Symbol          string    `json:"symbol"`
+	MarkPrice       float64   `json:"mark_price"`        // 标记价格
+	IndexPrice      float64   `json:"index_price"`       // 指数价格
+	FundingRate     float64   `json:"funding_rate"`      // 当前资金费率
+	NextFundingTime int64     `json:"next_funding_time"` // 下次结算时间（毫秒时间戳）
+	EventTime       int64     `json:"event_time"`        // 交易所推送时间（毫秒时间戳）
+	Received        time.Time `json:"received"`          // 本地收到的时间
+}
+
+// MarkPriceCandle 由推送的标记价格生成的1分钟K线（时间为该分钟的开始）
+type MarkPriceCandle struct {
+	Open    float64 `json:"open"`
+	High    float64 `json:"high"`
+	Low     float64 `json:"low"`
+	Close   float64 `json:"close"`
+	Updates int     `json:"updates"` // 该分钟收到的推送次数
+}
+
+// MarkPriceStreamStatus 标记价格推送连接状态
+type MarkPriceStreamStatus struct {
+	URL         string    `json:"url"`
+	Connected   bool      `json:"connected"`    // 当前是否已连接
+	ConnectedAt time.Time `json:"connected_at"` // 最近一次连接成功的时间
+	LastMessage time.Time `json:"last_message"` // 最近一次收到推送的时间
+	Symbols     int       `json:"symbols"`      // 已收到数据的交易对数
+	Reconnects  int       `json:"reconnects"`   // 断线重连次数
+	Backfills   int       `json:"backfills"`    // 连接后用REST补齐数据的次数
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// markPriceEvent 推送消息中的单个交易对（字段名区分大小写，同名的大小写字段都需要声明）
+type markPriceEvent struct {
+	EventType       string `json:"e"`
+	EventTime       int64  `json:"E"`
+	Symbol          string `json:"s"`
+	MarkPrice       string `json:"p"`
+	IndexPrice      string `json:"i"`
+	SettlePrice     string `json:"P"` // 预估结算价
+	FundingRate     string `json:"r"`
+	NextFundingTime int64  `json:"T"`
+}
+
+// MarkPriceStream 全市场标记价格推送
+type MarkPriceStream struct {
+	client *Client // 为nil时不补齐数据
+	url    string
+	proxy  string
+	maxAge time.Duration
+
+	mu          sync.RWMutex
+	prices      map[string]MarkPriceUpdate
+	candles     map[string]*utils.Ring[MarkPriceCandle] // symbol -> 1分钟K线
+	status      MarkPriceStreamStatus
+	subscribers *streamSubscribers
+}
+
+// NewMarkPriceStream 创建标记价格推送（maxAge为推送数据的有效期，client为nil时连接后不用REST补齐数据）
+func NewMarkPriceStream(client *Client, baseURL, proxyURL string, maxAge time.Duration) *MarkPriceStream {
+	streamURL := baseURL + markPriceStreamPath
+	return &MarkPriceStream{
+		client:      client,
+		url:         streamURL,
+		proxy:       proxyURL,
+		maxAge:      maxAge,
+		prices:      make(map[string]MarkPriceUpdate),
+		candles:     make(map[string]*utils.Ring[MarkPriceCandle]),
+		status:      MarkPriceStreamStatus{URL: streamURL},
+		subscribers: newStreamSubscribers(),
+	}
+}
+
+// Run 连接并持续接收推送，断线后按指数退避重连，直到ctx取消
+func (s *MarkPriceStream) Run(ctx context.Context) {
+	var backoff wsBackoff
+	for {
+		start := time.Now()
+		err := s.receive(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		delay := backoff.next(time.Since(start))
+
+		s.mu.Lock()
+		s.status.Connected = false
+		s.status.Reconnects++
+		if err != nil {
+			s.status.LastError = err.Error()
+		}
+		s.mu.Unlock()
+		utils.Warn("标记价格推送连接中断，稍后重连", zap.String("url", s.url), zap.Duration("delay", delay), zap.Error(err))
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// receive 建立一次连接并接收推送，连接出错时返回
+func (s *MarkPriceStream) receive(ctx context.Context) error {
+	conn, err := dialWS(ctx, s.url, s.proxy)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.status.Connected = true
+	s.status.ConnectedAt = time.Now()
+	s.status.LastError = ""
+	s.mu.Unlock()
+	utils.Info("标记价格推送已连接", zap.String("url", s.url))
+
+	// 推送在连接后缓存在连接中，先补齐的REST数据随后被更新的推送覆盖
+	s.backfill()
+
+	for {
+		// 每秒推送一次，30秒收不到推送视为推送停止（连接本身是否存活由ping/pong检测）
+		message, err := conn.ReadMessage(30 * time.Second)
+		if err != nil {
+			return err
+		}
+		var events []markPriceEvent
+		if err := json.Unmarshal(message, &events); err != nil {
+			utils.Warn("解析标记价格推送失败", zap.Error(err))
+			continue
+		}
+		s.apply(events, time.Now())
+	}
+}
+
+// backfill 用REST接口获取全部交易对的标记价格（补齐断线期间的数据，失败时只记录日志，等待推送）
+func (s *MarkPriceStream) backfill() {
+	if s.client == nil {
+		return
+	}
+	premiums, err := s.client.GetAllPremiumIndex()
+	if err != nil {
+		utils.Warn("补齐标记价格失败，等待推送", zap.String("url", s.url), zap.Error(err))
+		return
+	}
+
+	events := make([]markPriceEvent, len(premiums))
+	for i, p := range premiums {
+		events[i] = markPriceEvent{
+			EventTime:       p.Time,
+			Symbol:          p.Symbol,
+			MarkPrice:       p.MarkPrice,
+			IndexPrice:      p.IndexPrice,
+			FundingRate:     p.LastFundingRate,
+			NextFundingTime: p.NextFundingTime,
+		}
+	}
+	s.apply(events, time.Now())
+
+	s.mu.Lock()
+	s.status.Backfills++
+	s.mu.Unlock()
+}
+
+// apply 更新内存中的标记价格，并交给订阅者
+func (s *MarkPriceStream) apply(events []markPriceEvent, now time.Time) {
+	publish := s.subscribers.active()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range events {
+		mark, err := strconv.ParseFloat(e.MarkPrice, 64)
+		if err != nil || mark <= 0 {
+			continue
+		}
+		index, _ := strconv.ParseFloat(e.IndexPrice, 64)
+		rate, _ := strconv.ParseFloat(e.FundingRate, 64)
+		update := MarkPriceUpdate{
+			Symbol:          e.Symbol,
+			MarkPrice:       mark,
+			IndexPrice:      index,
+			FundingRate:     rate,
+			NextFundingTime: e.NextFundingTime,
+			EventTime:       e.EventTime,
+			Received:        now,
+		}
+		s.prices[e.Symbol] = update
+		s.updateCandleLocked(e.Symbol, mark, now)
+		if publish {
+			s.subscribers.publish(e.Symbol, update)
+		}
+	}
+	s.status.LastMessage = now
+	s.status.Symbols = len(s.prices)
+}
+
+// updateCandleLocked 用一次标记价格更新交易对当前分钟的K线（调用方持有锁）
+func (s *MarkPriceStream) updateCandleLocked(symbol string, mark float64, now time.Time) {
+	candles, ok := s.candles[symbol]
+	if !ok {
+		candles = utils.NewRing[MarkPriceCandle](markCandleHistory)
+		s.candles[symbol] = candles
+	}
+	minute := now.Truncate(time.Minute)
+	latest, ok := candles.Latest()
+	if !ok || !latest.Time.Equal(minute) {
+		candles.Push(minute, MarkPriceCandle{Open: mark, High: mark, Low: mark, Close: mark, Updates: 1})
+		return
+	}
+	candle := latest.Value
+	candle.High = max(candle.High, mark)
+	candle.Low = min(candle.Low, mark)
+	candle.Close = mark
+	candle.Updates++
+	candles.Update(candle)
+}
+
+// Get 获取交易对最新的标记价格和资金费率（没有数据或超过maxAge未更新时返回false）
+func (s *MarkPriceStream) Get(symbol string) (MarkPriceUpdate, bool) {
+	if s == nil {
+		return MarkPriceUpdate{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	update, ok := s.prices[symbol]
+	if !ok || time.Since(update.Received) > s.maxAge {
+		return MarkPriceUpdate{}, false
+	}
+	return update, true
+}
+
+// Price 获取交易对最新的标记价格
+func (s *MarkPriceStream) Price(symbol string) (float64, bool) {
+	update, ok := s.Get(symbol)
+	return update.MarkPrice, ok
+}
+
+// FundingRate 获取交易对当前资金费率
+func (s *MarkPriceStream) FundingRate(symbol string) (float64, bool) {
+	update, ok := s.Get(symbol)
+	return update.FundingRate, ok
+}
+
+// Premium 获取交易对标记价格相对指数价格的偏离（%，(标记价格 - 指数价格) / 指数价格 × 100）
+// 没有数据、数据过期或指数价格缺失时返回false
+func (s *MarkPriceStream) Premium(symbol string) (float64, bool) {
+	update, ok := s.Get(symbol)
+	if !ok || update.IndexPrice <= 0 {
+		return 0, false
+	}
+	return (update.MarkPrice - update.IndexPrice) / update.IndexPrice * 100, true
+}
+
+// PremiumPct 标记价格相对指数价格的偏离（%），优先使用推送数据，推送不可用时通过REST接口获取
+// stream 可以为nil
+func PremiumPct(stream *MarkPriceStream, client *Client, symbol string) (float64, error) {
+	if premium, ok := stream.Premium(symbol); ok {
+		return premium, nil
+	}
+	index, err := client.GetPremiumIndex(symbol)
+	if err != nil {
+		return 0, err
+	}
+	if p, _ := strconv.ParseFloat(index.IndexPrice, 64); p <= 0 {
+		return 0, fmt.Errorf("指数价格缺失: %s", symbol)
+	}
+	return index.Basis() * 100, nil
+}
+
+// Snapshot 所有交易对的最新数据（含已过期的数据，按交易对排序）
+func (s *MarkPriceStream) Snapshot() []MarkPriceUpdate {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	updates := make([]MarkPriceUpdate, 0, len(s.prices))
+	for _, update := range s.prices {
+		updates = append(updates, update)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Symbol < updates[j].Symbol })
+	return updates
+}
+
+// Candles 交易对最近n根1分钟标记价格K线（从旧到新，最后一根为当前分钟，n<=0时返回全部）
+func (s *MarkPriceStream) Candles(symbol string, n int) []utils.Point[MarkPriceCandle] {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	candles, ok := s.candles[symbol]
+	if !ok {
+		return nil
+	}
+	points := candles.Snapshot()
+	if n > 0 && len(points) > n {
+		points = points[len(points)-n:]
+	}
+	return points
+}
+
+// Closes 最近n根1分钟K线的收盘价（从旧到新，供指标计算）
+func (s *MarkPriceStream) Closes(symbol string, n int) []float64 {
+	candles := s.Candles(symbol, n)
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Value.Close
+	}
+	return closes
+}
+
+// Status 连接状态
+func (s *MarkPriceStream) Status() MarkPriceStreamStatus {
+	if s == nil {
+		return MarkPriceStreamStatus{}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+// Subscribe 订阅标记价格更新（name用于统计，capacity为最多缓存的交易对数）
+// 消费者处理慢时同一交易对只保留最新一条，返回的通道在ctx取消后关闭
+func (s *MarkPriceStream) Subscribe(ctx context.Context, name string, capacity int) <-chan MarkPriceUpdate {
+	out := make(chan MarkPriceUpdate)
+	if s == nil {
+		close(out)
+		return out
+	}
+	s.subscribers.subscribe(ctx, name, QueueConflate, capacity, func(value interface{}) bool {
+		select {
+		case out <- value.(MarkPriceUpdate):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(out) })
+	return out
+}
+
+// Subscribers 各订阅队列的统计
+func (s *MarkPriceStream) Subscribers() []QueueStats {
+	if s == nil {
+		return nil
+	}
+	return s.subscribers.Stats()
+}
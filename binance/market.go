@@ -4,7 +4,14 @@ Package binance 市场数据相关API
 主要功能：
 - (c *Client) GetOpenInterest(symbol string) (*OpenInterest, error)                    // 获取持仓量
 - (c *Client) GetFundingRateHistory(symbol string, limit int) ([]FundingRate, error)   // 获取资金费率历史
+- (c *Client) GetFundingRateRange(symbol string, startTime, endTime int64) ([]FundingRate, error) // 获取时间范围内的资金费率
+- (r *FundingRate) RateFloat() float64                                                 // 资金费率数值
 - (c *Client) GetPremiumIndex(symbol string) (*PremiumIndex, error)                    // 获取当前资金费率和标记价格
+- (c *Client) GetAllPremiumIndex() ([]PremiumIndex, error)                             // 获取全部交易对的资金费率和标记价格
+- (p *PremiumIndex) FundingRateFloat() float64                                         // 最新资金费率数值
+- (p *PremiumIndex) Basis() float64                                                    // 基差（(标记价格 - 指数价格) / 指数价格）
+- (c *Client) GetBookTicker(symbol string) (*BookTicker, error)                         // 获取最优挂单价格
+- (c *Client) GetAllTickers24h() ([]Ticker24h, error)                                   // 获取全部交易对的24小时行情
 - CalculateOIChange(current, previous float64) float64                                 // 计算持仓量变化率
 */
 package binance
@@ -44,6 +51,28 @@ type PremiumIndex struct {
 	Time            int64  `json:"time"`            // 时间戳
 }
 
+// BookTicker 最优挂单
+type BookTicker struct {
+	Symbol   string `json:"symbol"`   // 交易对
+	BidPrice string `json:"bidPrice"` // 最优买价
+	BidQty   string `json:"bidQty"`   // 最优买价挂单量
+	AskPrice string `json:"askPrice"` // 最优卖价
+	AskQty   string `json:"askQty"`   // 最优卖价挂单量
+	Time     int64  `json:"time"`     // 时间戳
+}
+
+// Ticker24h 24小时滚动行情
+type Ticker24h struct {
+	Symbol             string `json:"symbol"`             // 交易对
+	PriceChangePercent string `json:"priceChangePercent"` // 24小时涨跌幅（%）
+	LastPrice          string `json:"lastPrice"`          // 最新价格
+	HighPrice          string `json:"highPrice"`          // 24小时最高价
+	LowPrice           string `json:"lowPrice"`           // 24小时最低价
+	Volume             string `json:"volume"`             // 24小时成交量
+	QuoteVolume        string `json:"quoteVolume"`        // 24小时成交额
+	CloseTime          int64  `json:"closeTime"`          // 统计结束时间
+}
+
 // GetOpenInterest 获取持仓量
 // symbol: 交易对，如 "BTCUSDT"
 func (c *Client) GetOpenInterest(symbol string) (*OpenInterest, error) {
@@ -106,6 +135,35 @@ func (c *Client) GetFundingRateHistory(symbol string, limit int) ([]FundingRate,
 	return fundingRates, nil
 }
 
+// GetFundingRateRange 获取时间范围内的资金费率（单次最多1000条，约333天）
+// startTime/endTime: 毫秒时间戳
+func (c *Client) GetFundingRateRange(symbol string, startTime, endTime int64) ([]FundingRate, error) {
+	params := map[string]string{
+		"symbol":    symbol,
+		"startTime": strconv.FormatInt(startTime, 10),
+		"endTime":   strconv.FormatInt(endTime, 10),
+		"limit":     "1000",
+	}
+
+	body, err := c.doRequest("GET", EndpointFundingRate, params, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取资金费率历史失败: %w", err)
+	}
+
+	var fundingRates []FundingRate
+	if err := json.Unmarshal(body, &fundingRates); err != nil {
+		return nil, fmt.Errorf("解析资金费率数据失败: %w", err)
+	}
+
+	return fundingRates, nil
+}
+
+// RateFloat 资金费率数值
+func (r *FundingRate) RateFloat() float64 {
+	rate, _ := strconv.ParseFloat(r.FundingRate, 64)
+	return rate
+}
+
 // GetPremiumIndex 获取当前资金费率和标记价格
 // symbol: 交易对，如 "BTCUSDT"
 func (c *Client) GetPremiumIndex(symbol string) (*PremiumIndex, error) {
@@ -121,7 +179,7 @@ func (c *Client) GetPremiumIndex(symbol string) (*PremiumIndex, error) {
 	}
 
 	var premium PremiumIndex
-	if err := json.Unmarshal(body, &premium); err != nil {
+	if err := unmarshalSingle(body, &premium); err != nil {
 		return nil, fmt.Errorf("解析溢价指数数据失败: %w", err)
 	}
 
@@ -134,6 +192,71 @@ func (c *Client) GetPremiumIndex(symbol string) (*PremiumIndex, error) {
 	return &premium, nil
 }
 
+// GetAllPremiumIndex 获取全部交易对的资金费率和标记价格（单次请求）
+func (c *Client) GetAllPremiumIndex() ([]PremiumIndex, error) {
+	body, err := c.doRequest("GET", EndpointPremiumIndex, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取溢价指数失败: %w", err)
+	}
+
+	var premiums []PremiumIndex
+	if err := json.Unmarshal(body, &premiums); err != nil {
+		return nil, fmt.Errorf("解析溢价指数数据失败: %w", err)
+	}
+
+	utils.Info("获取全部溢价指数成功", zap.Int("count", len(premiums)))
+
+	return premiums, nil
+}
+
+// FundingRateFloat 最新资金费率数值
+func (p *PremiumIndex) FundingRateFloat() float64 {
+	rate, _ := strconv.ParseFloat(p.LastFundingRate, 64)
+	return rate
+}
+
+// Basis 基差（(标记价格 - 指数价格) / 指数价格），指数价格缺失时返回0
+func (p *PremiumIndex) Basis() float64 {
+	mark, _ := strconv.ParseFloat(p.MarkPrice, 64)
+	index, _ := strconv.ParseFloat(p.IndexPrice, 64)
+	if index <= 0 {
+		return 0
+	}
+	return (mark - index) / index
+}
+
+// GetBookTicker 获取最优挂单价格
+// symbol: 交易对，如 "BTCUSDT"
+func (c *Client) GetBookTicker(symbol string) (*BookTicker, error) {
+	params := map[string]string{
+		"symbol": symbol,
+	}
+
+	body, err := c.doRequest("GET", EndpointBookTicker, params, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取最优挂单失败: %w", err)
+	}
+
+	var ticker BookTicker
+	if err := unmarshalSingle(body, &ticker); err != nil {
+		return nil, fmt.Errorf("解析最优挂单数据失败: %w", err)
+	}
+
+	return &ticker, nil
+}
+
+// BidPriceFloat 最优买价
+func (t *BookTicker) BidPriceFloat() float64 {
+	price, _ := strconv.ParseFloat(t.BidPrice, 64)
+	return price
+}
+
+// AskPriceFloat 最优卖价
+func (t *BookTicker) AskPriceFloat() float64 {
+	price, _ := strconv.ParseFloat(t.AskPrice, 64)
+	return price
+}
+
 // CalculateOIChange 计算持仓量变化率
 // current: 当前持仓量
 // previous: 之前的持仓量
@@ -144,3 +267,32 @@ func CalculateOIChange(current, previous float64) float64 {
 	}
 	return ((current - previous) / previous) * 100
 }
+
+// GetAllTickers24h 获取全部交易对的24小时行情（单次请求）
+func (c *Client) GetAllTickers24h() ([]Ticker24h, error) {
+	body, err := c.doRequest("GET", EndpointTicker24h, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取24小时行情失败: %w", err)
+	}
+
+	var tickers []Ticker24h
+	if err := json.Unmarshal(body, &tickers); err != nil {
+		return nil, fmt.Errorf("解析24小时行情数据失败: %w", err)
+	}
+
+	utils.Debug("获取全部24小时行情成功", zap.Int("count", len(tickers)))
+
+	return tickers, nil
+}
+
+// QuoteVolumeFloat 24小时成交额
+func (t *Ticker24h) QuoteVolumeFloat() float64 {
+	v, _ := strconv.ParseFloat(t.QuoteVolume, 64)
+	return v
+}
+
+// PriceChangePercentFloat 24小时涨跌幅（%）
+func (t *Ticker24h) PriceChangePercentFloat() float64 {
+	v, _ := strconv.ParseFloat(t.PriceChangePercent, 64)
+	return v
+}
@@ -0,0 +1,365 @@
+/*
+Package binance 订单相关API
+
+主要功能：
+- (c *Client) PlaceOrder(req *OrderRequest) (*Order, error)              // 下单
+- (c *Client) GetOrder(symbol string, orderID int64) (*Order, error)     // 查询订单
+- (c *Client) GetOrderByClientID(symbol, clientOrderID string) (*Order, error)  // 根据客户端订单ID查询订单
+- (c *Client) CancelOrder(symbol string, orderID int64) (*Order, error)  // 撤销订单
+- (c *Client) GetOpenOrders(symbol string) ([]Order, error)              // 查询当前挂单
+- (c *Client) CancelAllOrders(symbol string) error                       // 撤销交易对的全部挂单
+- (r *OrderRequest) Validate() error                                     // 验证下单参数（reduceOnly/closePosition组合）
+- (r *OrderRequest) IsExit() bool                                        // 是否为平仓单
+- IsValidTimeInForce(tif string) bool                                    // 是否为有效的有效方式（GTC/IOC/FOK/GTX）
+- (o *Order) IsFinal() bool                                              // 订单是否已结束
+- (o *Order) ExecutedQtyFloat() float64                                  // 已成交数量
+- (o *Order) AvgPriceFloat() float64                                     // 成交均价
+*/
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 订单方向
+const (
+	SideBuy  = "BUY"
+	SideSell = "SELL"
+)
+
+// 订单类型
+const (
+	OrderTypeLimit            = "LIMIT"
+	OrderTypeMarket           = "MARKET"
+	OrderTypeStopMarket       = "STOP_MARKET"
+	OrderTypeTakeProfitMarket = "TAKE_PROFIT_MARKET"
+)
+
+// 订单状态
+const (
+	OrderStatusNew             = "NEW"
+	OrderStatusPartiallyFilled = "PARTIALLY_FILLED"
+	OrderStatusFilled          = "FILLED"
+	OrderStatusCanceled        = "CANCELED"
+	OrderStatusRejected        = "REJECTED"
+	OrderStatusExpired         = "EXPIRED"
+)
+
+// 有效方式（限价单）
+const (
+	TimeInForceGTC = "GTC" // 成交为止
+	TimeInForceIOC = "IOC" // 立即成交，剩余撤销
+	TimeInForceFOK = "FOK" // 全部成交或全部撤销
+	TimeInForceGTX = "GTX" // 只做Maker（Post Only），会立即成交时撤销
+)
+
+// 触发价格类型
+const (
+	WorkingTypeMarkPrice     = "MARK_PRICE"
+	WorkingTypeContractPrice = "CONTRACT_PRICE"
+)
+
+// OrderRequest 下单请求
+type OrderRequest struct {
+	Symbol        string // 交易对
+	Side          string // BUY 或 SELL
+	PositionSide  string // 持仓方向（单向持仓模式留空，即BOTH）
+	Type          string // 订单类型
+	Quantity      string // 数量（已按stepSize格式化）
+	Price         string // 限价单价格（已按tickSize格式化）
+	StopPrice     string // 触发价格（止损/止盈单）
+	TimeInForce   string // 有效方式（限价单）
+	ReduceOnly    bool   // 只减仓（平仓单必须设置，防止过期的平仓单反向开仓）
+	ClosePosition bool   // 触发后平掉全部持仓（仅STOP_MARKET/TAKE_PROFIT_MARKET，不能与Quantity、ReduceOnly同时使用）
+	WorkingType   string // 触发价格类型（MARK_PRICE 或 CONTRACT_PRICE）
+
+	NewClientOrderID string // 客户端订单ID（用于幂等下单，最长36位）
+}
+
+// Order 订单信息
+type Order struct {
+	OrderID       int64  `json:"orderId"`             // 订单ID
+	Symbol        string `json:"symbol"`              // 交易对
+	Status        string `json:"status"`              // 订单状态
+	ClientOrderID string `json:"clientOrderId"`       // 客户端订单ID
+	Price         string `json:"price"`               // 委托价格
+	AvgPrice      string `json:"avgPrice"`            // 成交均价
+	OrigQty       string `json:"origQty"`             // 原始委托数量
+	ExecutedQty   string `json:"executedQty"`         // 已成交数量
+	CumQuote      string `json:"cumQuote"`            // 成交金额
+	SpotCumQuote  string `json:"cummulativeQuoteQty"` // 成交金额（现货）
+	TimeInForce   string `json:"timeInForce"`         // 有效方式
+	Type          string `json:"type"`                // 订单类型
+	ReduceOnly    bool   `json:"reduceOnly"`          // 是否只减仓
+	ClosePosition bool   `json:"closePosition"`       // 是否触发后全部平仓
+	Side          string `json:"side"`                // 买卖方向
+	PositionSide  string `json:"positionSide"`        // 持仓方向
+	StopPrice     string `json:"stopPrice"`           // 触发价格
+	WorkingType   string `json:"workingType"`         // 触发价格类型
+	OrigType      string `json:"origType"`            // 原始订单类型
+	UpdateTime    int64  `json:"updateTime"`          // 更新时间
+}
+
+// PlaceOrder 下单
+func (c *Client) PlaceOrder(req *OrderRequest) (*Order, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("下单参数无效: %w", err)
+	}
+
+	params := map[string]string{
+		"symbol":           req.Symbol,
+		"side":             req.Side,
+		"type":             req.Type,
+		"newOrderRespType": "RESULT",
+	}
+	if req.PositionSide != "" {
+		params["positionSide"] = req.PositionSide
+	}
+	if req.Quantity != "" {
+		params["quantity"] = req.Quantity
+	}
+	if req.Price != "" {
+		params["price"] = req.Price
+	}
+	if req.StopPrice != "" {
+		params["stopPrice"] = req.StopPrice
+	}
+	if req.TimeInForce != "" {
+		params["timeInForce"] = req.TimeInForce
+	}
+	if req.ReduceOnly {
+		params["reduceOnly"] = "true"
+	}
+	if req.ClosePosition {
+		params["closePosition"] = "true"
+	}
+	if req.WorkingType != "" {
+		params["workingType"] = req.WorkingType
+	}
+	if req.NewClientOrderID != "" {
+		params["newClientOrderId"] = req.NewClientOrderID
+	}
+
+	utils.Debug("下单",
+		zap.String("symbol", req.Symbol),
+		zap.String("side", req.Side),
+		zap.String("type", req.Type),
+		zap.String("quantity", req.Quantity),
+		zap.String("price", req.Price),
+		zap.String("stop_price", req.StopPrice),
+		zap.Bool("reduce_only", req.ReduceOnly),
+		zap.Bool("close_position", req.ClosePosition),
+		zap.String("client_order_id", req.NewClientOrderID),
+	)
+
+	body, err := c.doRequest("POST", EndpointOrder, params, true)
+	if err != nil {
+		return nil, fmt.Errorf("下单失败: %w", err)
+	}
+
+	var order Order
+	if err := json.Unmarshal(body, &order); err != nil {
+		return nil, fmt.Errorf("解析订单数据失败: %w", err)
+	}
+
+	utils.Info("下单成功",
+		zap.String("symbol", order.Symbol),
+		zap.Int64("order_id", order.OrderID),
+		zap.String("side", order.Side),
+		zap.String("type", order.Type),
+		zap.String("status", order.Status),
+		zap.String("executed_qty", order.ExecutedQty),
+	)
+
+	return &order, nil
+}
+
+// GetOrder 查询订单
+func (c *Client) GetOrder(symbol string, orderID int64) (*Order, error) {
+	params := map[string]string{
+		"symbol":  symbol,
+		"orderId": strconv.FormatInt(orderID, 10),
+	}
+
+	body, err := c.doRequest("GET", EndpointOrder, params, true)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单失败: %w", err)
+	}
+
+	var order Order
+	if err := json.Unmarshal(body, &order); err != nil {
+		return nil, fmt.Errorf("解析订单数据失败: %w", err)
+	}
+
+	return &order, nil
+}
+
+// GetOrderByClientID 根据客户端订单ID查询订单
+// 订单不存在时返回 (nil, nil)
+func (c *Client) GetOrderByClientID(symbol, clientOrderID string) (*Order, error) {
+	params := map[string]string{
+		"symbol":            symbol,
+		"origClientOrderId": clientOrderID,
+	}
+
+	body, err := c.doRequest("GET", EndpointOrder, params, true)
+	if err != nil {
+		if errors.Is(err, ErrOrderNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询订单失败: %w", err)
+	}
+
+	var order Order
+	if err := json.Unmarshal(body, &order); err != nil {
+		return nil, fmt.Errorf("解析订单数据失败: %w", err)
+	}
+
+	return &order, nil
+}
+
+// CancelOrder 撤销订单
+func (c *Client) CancelOrder(symbol string, orderID int64) (*Order, error) {
+	params := map[string]string{
+		"symbol":  symbol,
+		"orderId": strconv.FormatInt(orderID, 10),
+	}
+
+	body, err := c.doRequest("DELETE", EndpointOrder, params, true)
+	if err != nil {
+		return nil, fmt.Errorf("撤销订单失败: %w", err)
+	}
+
+	var order Order
+	if err := json.Unmarshal(body, &order); err != nil {
+		return nil, fmt.Errorf("解析订单数据失败: %w", err)
+	}
+
+	utils.Info("撤销订单成功",
+		zap.String("symbol", symbol),
+		zap.Int64("order_id", orderID),
+	)
+
+	return &order, nil
+}
+
+// GetOpenOrders 查询当前挂单（symbol为空时查询全部交易对）
+func (c *Client) GetOpenOrders(symbol string) ([]Order, error) {
+	params := make(map[string]string)
+	if symbol != "" {
+		params["symbol"] = symbol
+	}
+
+	body, err := c.doRequest("GET", EndpointOpenOrders, params, true)
+	if err != nil {
+		return nil, fmt.Errorf("查询挂单失败: %w", err)
+	}
+
+	var orders []Order
+	if err := json.Unmarshal(body, &orders); err != nil {
+		return nil, fmt.Errorf("解析挂单数据失败: %w", err)
+	}
+
+	return orders, nil
+}
+
+// CancelAllOrders 撤销交易对的全部挂单
+func (c *Client) CancelAllOrders(symbol string) error {
+	params := map[string]string{
+		"symbol": symbol,
+	}
+
+	if _, err := c.doRequest("DELETE", EndpointAllOpenOrders, params, true); err != nil {
+		return fmt.Errorf("撤销全部挂单失败: %w", err)
+	}
+
+	utils.Info("撤销全部挂单成功", zap.String("symbol", symbol))
+	return nil
+}
+
+// Validate 验证下单参数
+func (r *OrderRequest) Validate() error {
+	if r.Symbol == "" || r.Side == "" || r.Type == "" {
+		return fmt.Errorf("symbol、side、type不能为空")
+	}
+
+	if r.ClosePosition {
+		if r.Type != OrderTypeStopMarket && r.Type != OrderTypeTakeProfitMarket {
+			return fmt.Errorf("closePosition只能用于STOP_MARKET或TAKE_PROFIT_MARKET订单")
+		}
+		if r.Quantity != "" {
+			return fmt.Errorf("closePosition订单不能指定数量")
+		}
+		if r.ReduceOnly {
+			return fmt.Errorf("closePosition订单不能同时设置reduceOnly")
+		}
+		return nil
+	}
+
+	if r.Quantity == "" {
+		return fmt.Errorf("quantity不能为空")
+	}
+
+	if r.Type == OrderTypeLimit {
+		if r.Price == "" {
+			return fmt.Errorf("限价单price不能为空")
+		}
+		if !IsValidTimeInForce(r.TimeInForce) {
+			return fmt.Errorf("限价单timeInForce无效: %s (必须是 GTC、IOC、FOK 或 GTX)", r.TimeInForce)
+		}
+	}
+	return nil
+}
+
+// IsValidTimeInForce 是否为有效的有效方式
+func IsValidTimeInForce(tif string) bool {
+	switch tif {
+	case TimeInForceGTC, TimeInForceIOC, TimeInForceFOK, TimeInForceGTX:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsExit 是否为平仓单（只减仓或全部平仓）
+func (r *OrderRequest) IsExit() bool {
+	return r.ReduceOnly || r.ClosePosition
+}
+
+// IsFinal 订单是否已结束（完全成交、撤销、拒绝、过期）
+func (o *Order) IsFinal() bool {
+	switch o.Status {
+	case OrderStatusFilled, OrderStatusCanceled, OrderStatusRejected, OrderStatusExpired:
+		return true
+	default:
+		return false
+	}
+}
+
+// ExecutedQtyFloat 已成交数量
+func (o *Order) ExecutedQtyFloat() float64 {
+	qty, _ := strconv.ParseFloat(o.ExecutedQty, 64)
+	return qty
+}
+
+// AvgPriceFloat 成交均价
+func (o *Order) AvgPriceFloat() float64 {
+	price, _ := strconv.ParseFloat(o.AvgPrice, 64)
+	if price > 0 {
+		return price
+	}
+
+	// 现货订单没有avgPrice，按成交金额 / 成交数量计算
+	quote, _ := strconv.ParseFloat(o.SpotCumQuote, 64)
+	if qty := o.ExecutedQtyFloat(); qty > 0 {
+		return quote / qty
+	}
+	return 0
+}
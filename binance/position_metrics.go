@@ -0,0 +1,144 @@
+/*
+Package binance 持仓数值计算
+
+主要功能：
+- (p *PositionRisk) Quantity() float64                       // 持仓数量（多为正，空为负）
+- (p *PositionRisk) EntryPriceFloat() float64                // 开仓均价
+- (p *PositionRisk) MarkPriceFloat() float64                 // 标记价格
+- (p *PositionRisk) LiquidationPriceFloat() float64          // 强平价格（0表示没有）
+- (p *PositionRisk) UnrealizedPnL() float64                  // 未实现盈亏
+- (p *PositionRisk) LeverageInt() int                        // 杠杆倍数
+- (p *PositionRisk) PnLPct() float64                         // 按持仓方向的价格涨跌幅（%）
+- (p *PositionRisk) ROE() float64                            // 保证金收益率（%，涨跌幅 × 杠杆）
+- (p *PositionRisk) LiquidationDistancePct() (float64, bool) // 标记价格到强平价格的距离（%）
+- (p *PositionRisk) NotionalUSDT() float64                   // 名义价值（USDT，取绝对值）
+- LiquidationDistancePct(markPrice, liqPrice float64) (float64, bool)  // 价格到强平价格的距离（%）
+
+Position 与 PositionRisk 字段相同，提供同样的方法。接口返回的数值都是字符串，
+统一在这里解析，调用方不需要各自 strconv.ParseFloat；解析失败按0处理。
+币本位合约的持仓数量是合约张数，名义价值需要按合约面值计算（SymbolInfo.Notional），NotionalUSDT 只适用于U本位合约。
+*/
+package binance
+
+import (
+	"math"
+	"strconv"
+)
+
+// Quantity 持仓数量（多为正，空为负）
+func (p *PositionRisk) Quantity() float64 {
+	return parsePositionFloat(p.PositionAmt)
+}
+
+// EntryPriceFloat 开仓均价
+func (p *PositionRisk) EntryPriceFloat() float64 {
+	return parsePositionFloat(p.EntryPrice)
+}
+
+// MarkPriceFloat 标记价格
+func (p *PositionRisk) MarkPriceFloat() float64 {
+	return parsePositionFloat(p.MarkPrice)
+}
+
+// LiquidationPriceFloat 强平价格（0表示没有）
+func (p *PositionRisk) LiquidationPriceFloat() float64 {
+	return parsePositionFloat(p.LiquidationPrice)
+}
+
+// UnrealizedPnL 未实现盈亏
+func (p *PositionRisk) UnrealizedPnL() float64 {
+	return parsePositionFloat(p.UnRealizedProfit)
+}
+
+// LeverageInt 杠杆倍数
+func (p *PositionRisk) LeverageInt() int {
+	leverage, _ := strconv.Atoi(p.Leverage)
+	return leverage
+}
+
+// PnLPct 按持仓方向的价格涨跌幅（%，盈利为正；没有持仓或开仓均价缺失时返回0）
+func (p *PositionRisk) PnLPct() float64 {
+	qty, entry, mark := p.Quantity(), p.EntryPriceFloat(), p.MarkPriceFloat()
+	if qty == 0 || entry <= 0 || mark <= 0 {
+		return 0
+	}
+	pct := (mark - entry) / entry * 100
+	if qty < 0 {
+		pct = -pct
+	}
+	return pct
+}
+
+// ROE 保证金收益率（%，未实现盈亏 / 起始保证金，即涨跌幅 × 杠杆；杠杆缺失时按1倍）
+func (p *PositionRisk) ROE() float64 {
+	leverage := p.LeverageInt()
+	if leverage <= 0 {
+		leverage = 1
+	}
+	return p.PnLPct() * float64(leverage)
+}
+
+// LiquidationDistancePct 标记价格到强平价格的距离（%）；没有强平价格（如全仓保证金充足）时返回false
+func (p *PositionRisk) LiquidationDistancePct() (float64, bool) {
+	return LiquidationDistancePct(p.MarkPriceFloat(), p.LiquidationPriceFloat())
+}
+
+// NotionalUSDT 名义价值（USDT，取绝对值；接口未返回时按 数量 × 标记价格 计算）
+func (p *PositionRisk) NotionalUSDT() float64 {
+	if notional := parsePositionFloat(p.Notional); notional != 0 {
+		return math.Abs(notional)
+	}
+	return math.Abs(p.Quantity()) * p.MarkPriceFloat()
+}
+
+// LiquidationDistancePct 价格到强平价格的距离（%）；价格或强平价格无效时返回false
+func LiquidationDistancePct(markPrice, liqPrice float64) (float64, bool) {
+	if markPrice <= 0 || liqPrice <= 0 {
+		return 0, false
+	}
+	return math.Abs(markPrice-liqPrice) / markPrice * 100, true
+}
+
+// Quantity 持仓数量（多为正，空为负）
+func (p *Position) Quantity() float64 { return p.risk().Quantity() }
+
+// EntryPriceFloat 开仓均价
+func (p *Position) EntryPriceFloat() float64 { return p.risk().EntryPriceFloat() }
+
+// MarkPriceFloat 标记价格
+func (p *Position) MarkPriceFloat() float64 { return p.risk().MarkPriceFloat() }
+
+// LiquidationPriceFloat 强平价格（0表示没有）
+func (p *Position) LiquidationPriceFloat() float64 { return p.risk().LiquidationPriceFloat() }
+
+// UnrealizedPnL 未实现盈亏
+func (p *Position) UnrealizedPnL() float64 { return p.risk().UnrealizedPnL() }
+
+// LeverageInt 杠杆倍数
+func (p *Position) LeverageInt() int { return p.risk().LeverageInt() }
+
+// PnLPct 按持仓方向的价格涨跌幅（%）
+func (p *Position) PnLPct() float64 { return p.risk().PnLPct() }
+
+// ROE 保证金收益率（%）
+func (p *Position) ROE() float64 { return p.risk().ROE() }
+
+// LiquidationDistancePct 标记价格到强平价格的距离（%）
+func (p *Position) LiquidationDistancePct() (float64, bool) {
+	return p.risk().LiquidationDistancePct()
+}
+
+// NotionalUSDT 名义价值（USDT，取绝对值）
+func (p *Position) NotionalUSDT() float64 { return p.risk().NotionalUSDT() }
+
+// risk 按持仓风险计算（两者字段相同）
+func (p *Position) risk() *PositionRisk {
+	risk := PositionRisk(*p)
+	return &risk
+}
+
+// parsePositionFloat 解析数值字符串（失败返回0）
+func parsePositionFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
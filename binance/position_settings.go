@@ -0,0 +1,147 @@
+/*
+Package binance 持仓设置相关API（持仓模式、保证金模式、杠杆、逐仓保证金）
+
+主要功能：
+- (c *Client) GetPositionMode() (bool, error)                           // 查询持仓模式（true为双向持仓）
+- (c *Client) SetPositionMode(dualSide bool) error                      // 更改持仓模式（有持仓或挂单时交易所会拒绝）
+- (c *Client) SetMarginType(symbol, marginType string) error            // 更改交易对的保证金模式（有持仓时交易所会拒绝）
+- (c *Client) SetLeverage(symbol string, leverage int) error            // 调整交易对的杠杆倍数
+- (c *Client) AdjustIsolatedMargin(symbol string, amount float64, addOrReduce int) error  // 增加或减少逐仓保证金
+- NormalizeMarginType(marginType string) string                         // 持仓风险中的保证金模式转为下单格式（cross → CROSSED）
+
+持仓模式为账户级设置，保证金模式和杠杆为交易对级设置；币本位合约使用对应的 dapi 接口，现货不支持。
+*/
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 保证金模式
+const (
+	MarginTypeCrossed  = "CROSSED"  // 全仓
+	MarginTypeIsolated = "ISOLATED" // 逐仓
+)
+
+// 逐仓保证金调整方向
+const (
+	MarginAdd    = 1 // 增加保证金
+	MarginReduce = 2 // 减少保证金
+)
+
+// GetPositionMode 查询持仓模式（true为双向持仓，false为单向持仓）
+func (c *Client) GetPositionMode() (bool, error) {
+	body, err := c.doRequest("GET", EndpointPositionMode, nil, true)
+	if err != nil {
+		return false, fmt.Errorf("查询持仓模式失败: %w", err)
+	}
+
+	var result struct {
+		DualSidePosition bool `json:"dualSidePosition"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return false, fmt.Errorf("解析持仓模式失败: %w", err)
+	}
+
+	return result.DualSidePosition, nil
+}
+
+// SetPositionMode 更改持仓模式
+// dualSide: true为双向持仓，false为单向持仓
+func (c *Client) SetPositionMode(dualSide bool) error {
+	_, err := c.doRequest("POST", EndpointPositionMode, map[string]string{
+		"dualSidePosition": strconv.FormatBool(dualSide),
+	}, true)
+	if err != nil {
+		return fmt.Errorf("更改持仓模式失败: %w", err)
+	}
+
+	utils.Info("持仓模式已更改", zap.Bool("dual_side", dualSide))
+	return nil
+}
+
+// SetMarginType 更改交易对的保证金模式
+// marginType: CROSSED（全仓）或 ISOLATED（逐仓），已是目标模式时不报错
+func (c *Client) SetMarginType(symbol, marginType string) error {
+	_, err := c.doRequest("POST", EndpointMarginType, map[string]string{
+		"symbol":     symbol,
+		"marginType": marginType,
+	}, true)
+	if err != nil {
+		if errors.Is(err, ErrMarginTypeUnchanged) {
+			return nil
+		}
+		return fmt.Errorf("更改保证金模式失败: %w", err)
+	}
+
+	utils.Info("保证金模式已更改",
+		zap.String("symbol", symbol),
+		zap.String("margin_type", marginType),
+	)
+	return nil
+}
+
+// SetLeverage 调整交易对的杠杆倍数
+func (c *Client) SetLeverage(symbol string, leverage int) error {
+	_, err := c.doRequest("POST", EndpointLeverage, map[string]string{
+		"symbol":   symbol,
+		"leverage": strconv.Itoa(leverage),
+	}, true)
+	if err != nil {
+		return fmt.Errorf("调整杠杆失败: %w", err)
+	}
+
+	utils.Info("杠杆已调整",
+		zap.String("symbol", symbol),
+		zap.Int("leverage", leverage),
+	)
+	return nil
+}
+
+// AdjustIsolatedMargin 增加或减少逐仓保证金（仅逐仓持仓有效）
+// amount: 调整数量（U本位为USDT，币本位为标的币种）
+// addOrReduce: MarginAdd 或 MarginReduce
+func (c *Client) AdjustIsolatedMargin(symbol string, amount float64, addOrReduce int) error {
+	if addOrReduce != MarginAdd && addOrReduce != MarginReduce {
+		return fmt.Errorf("保证金调整方向无效: %d", addOrReduce)
+	}
+	if amount <= 0 {
+		return fmt.Errorf("保证金调整数量必须大于0: %v", amount)
+	}
+
+	_, err := c.doRequest("POST", EndpointPositionMargin, map[string]string{
+		"symbol": symbol,
+		"amount": strconv.FormatFloat(amount, 'f', -1, 64),
+		"type":   strconv.Itoa(addOrReduce),
+	}, true)
+	if err != nil {
+		return fmt.Errorf("调整逐仓保证金失败: %w", err)
+	}
+
+	utils.Info("逐仓保证金已调整",
+		zap.String("symbol", symbol),
+		zap.Float64("amount", amount),
+		zap.Int("type", addOrReduce),
+	)
+	return nil
+}
+
+// NormalizeMarginType 持仓风险中的保证金模式（cross / isolated）转为下单格式（CROSSED / ISOLATED）
+func NormalizeMarginType(marginType string) string {
+	switch strings.ToLower(marginType) {
+	case "cross", "crossed":
+		return MarginTypeCrossed
+	case "isolated":
+		return MarginTypeIsolated
+	default:
+		return strings.ToUpper(marginType)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Package binance 现货（api/v3）支持
+
+主要功能：
+- NewSpotClient(apiKey, apiSecret, baseURL, proxyURL string) *Client  // 创建现货客户端（baseURL如 https://api.binance.com）
+- (c *Client) IsSpot() bool                                        // 是否为现货客户端
+- (c *Client) GetSpotBalances() ([]SpotBalance, error)             // 获取现货账户余额
+- (c *Client) GetSpotAssetBalance(asset string) (float64, error)   // 获取单个资产余额（可用 + 冻结）
+
+现货与U本位合约的主要差异（由客户端统一转换，调用方使用相同的方法）：
+- 接口前缀 /api/v3，成交历史为 myTrades，手续费率为 account/commission
+- 没有持仓量、资金费率、资金流水、持仓风险接口，调用时返回 ErrUnsupportedMarket
+- 下单不支持 reduceOnly / closePosition / positionSide / workingType，这些参数会被忽略
+- 订单类型映射：STOP_MARKET → STOP_LOSS，TAKE_PROFIT_MARKET → TAKE_PROFIT，LIMIT + GTX → LIMIT_MAKER
+- 只能做多，"持仓"即标的资产余额
+*/
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// MarketTypeSpot 现货
+const MarketTypeSpot = "spot"
+
+// ErrUnsupportedMarket 当前市场类型不支持该接口
+var ErrUnsupportedMarket = errors.New("当前市场类型不支持该接口")
+
+// spotEndpoints 现货端点映射（不在表中的端点现货不支持）
+var spotEndpoints = map[string]string{
+	EndpointPing:          "/api/v3/ping",
+	EndpointServerTime:    "/api/v3/time",
+	EndpointAccount:       "/api/v3/account",
+	EndpointKlines:        "/api/v3/klines",
+	EndpointExchangeInfo:  "/api/v3/exchangeInfo",
+	EndpointBookTicker:    "/api/v3/ticker/bookTicker",
+	EndpointDepth:         "/api/v3/depth",
+	EndpointOrder:         "/api/v3/order",
+	EndpointOpenOrders:    "/api/v3/openOrders",
+	EndpointAllOpenOrders: "/api/v3/openOrders",
+	EndpointUserTrades:    "/api/v3/myTrades",
+	EndpointCommission:    "/api/v3/account/commission",
+	EndpointSystemStatus:  "/sapi/v1/system/status",
+	EndpointAssetTransfer: "/sapi/v1/asset/transfer",
+	EndpointAPIRestrict:   "/sapi/v1/account/apiRestrictions",
+}
+
+// spotOrderTypes 合约订单类型 → 现货订单类型
+var spotOrderTypes = map[string]string{
+	OrderTypeStopMarket:       "STOP_LOSS",
+	OrderTypeTakeProfitMarket: "TAKE_PROFIT",
+}
+
+// 现货下单不支持的参数
+var spotUnsupportedParams = []string{"reduceOnly", "closePosition", "positionSide", "workingType"}
+
+// SpotBalance 现货资产余额
+type SpotBalance struct {
+	Asset  string `json:"asset"`  // 资产
+	Free   string `json:"free"`   // 可用
+	Locked string `json:"locked"` // 冻结（挂单占用）
+}
+
+// spotAccountResponse 现货账户响应（只解析余额）
+type spotAccountResponse struct {
+	Balances []SpotBalance `json:"balances"`
+}
+
+// spotCommissionResponse 现货手续费率响应
+type spotCommissionResponse struct {
+	Symbol             string `json:"symbol"`
+	StandardCommission struct {
+		Maker string `json:"maker"`
+		Taker string `json:"taker"`
+	} `json:"standardCommission"`
+}
+
+// NewSpotClient 创建现货客户端
+func NewSpotClient(apiKey, apiSecret, baseURL, proxyURL string) *Client {
+	client := NewClient(apiKey, apiSecret, baseURL, proxyURL)
+	client.marketType = MarketTypeSpot
+	return client
+}
+
+// IsSpot 是否为现货客户端
+func (c *Client) IsSpot() bool {
+	return c.marketType == MarketTypeSpot
+}
+
+// resolveSpotEndpoint 合约端点转换为现货端点
+func resolveSpotEndpoint(endpoint string) (string, error) {
+	mapped, ok := spotEndpoints[endpoint]
+	if !ok {
+		return "", fmt.Errorf("%w: 现货 %s", ErrUnsupportedMarket, endpoint)
+	}
+	return mapped, nil
+}
+
+// resolveSpotParams 合约下单参数转换为现货参数
+func resolveSpotParams(params map[string]string) map[string]string {
+	if params == nil {
+		return nil
+	}
+
+	resolved := make(map[string]string, len(params))
+	for k, v := range params {
+		resolved[k] = v
+	}
+	for _, k := range spotUnsupportedParams {
+		delete(resolved, k)
+	}
+
+	if spotType, ok := spotOrderTypes[resolved["type"]]; ok {
+		resolved["type"] = spotType
+	}
+	// 只做Maker：现货没有GTX，使用LIMIT_MAKER订单类型
+	if resolved["type"] == OrderTypeLimit && resolved["timeInForce"] == "GTX" {
+		resolved["type"] = "LIMIT_MAKER"
+		delete(resolved, "timeInForce")
+	}
+	return resolved
+}
+
+// GetSpotBalances 获取现货账户余额（只返回非零余额）
+func (c *Client) GetSpotBalances() ([]SpotBalance, error) {
+	if !c.IsSpot() {
+		return nil, fmt.Errorf("%w: 非现货客户端", ErrUnsupportedMarket)
+	}
+
+	body, err := c.doRequest("GET", EndpointAccount, map[string]string{"omitZeroBalances": "true"}, true)
+	if err != nil {
+		return nil, fmt.Errorf("获取现货账户失败: %w", err)
+	}
+
+	var resp spotAccountResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析现货账户失败: %w", err)
+	}
+
+	return resp.Balances, nil
+}
+
+// GetSpotAssetBalance 获取单个资产余额（可用 + 冻结）
+func (c *Client) GetSpotAssetBalance(asset string) (float64, error) {
+	balances, err := c.GetSpotBalances()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, b := range balances {
+		if b.Asset == asset {
+			free, _ := strconv.ParseFloat(b.Free, 64)
+			locked, _ := strconv.ParseFloat(b.Locked, 64)
+			return free + locked, nil
+		}
+	}
+	return 0, nil
+}
+
+// parseSpotCommission 解析现货手续费率
+func parseSpotCommission(body []byte) (*CommissionRate, error) {
+	var resp spotCommissionResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	return &CommissionRate{
+		Symbol:              resp.Symbol,
+		MakerCommissionRate: resp.StandardCommission.Maker,
+		TakerCommissionRate: resp.StandardCommission.Taker,
+	}, nil
+}
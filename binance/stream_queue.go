@@ -0,0 +1,218 @@
+/*
+Package binance 推送订阅队列（有界，慢消费者不会导致内存无限增长）
+
+主要功能：
+- QueueDropOldest / QueueConflate                                    // 队列满时的处理策略
+- newStreamSubscribers() *streamSubscribers                          // 创建推送的订阅者集合
+- (s *streamSubscribers) subscribe(ctx context.Context, name string, policy QueuePolicy, capacity int, deliver func(interface{}) bool, done func())  // 新增订阅队列并启动投递协程
+- (s *streamSubscribers) publish(key string, value interface{})      // 向所有订阅队列写入一条数据（从不阻塞）
+- (s *streamSubscribers) Stats() []QueueStats                        // 各订阅队列的统计
+- (q *streamQueue) pop(done <-chan struct{}) (interface{}, bool)     // 取出最早的一条数据（队列为空时等待）
+
+推送读取协程写入订阅队列时从不阻塞，消费者处理不过来时按推送类型的策略处理：
+- drop_oldest：队列满时丢弃最旧的一条，适合逐条都有意义的事件（强平）
+- conflate：同一key（交易对）只保留最新一条并保持原来的排队位置，适合状态类数据（标记价格、盘口），不同交易对数超过容量时丢弃最旧的交易对
+每个订阅由一个协程把队列中的数据逐条交给消费者的通道，订阅的ctx取消后协程退出、通道关闭、队列移除。
+*/
+package binance
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// QueuePolicy 订阅队列满时的处理策略
+type QueuePolicy string
+
+const (
+	QueueDropOldest QueuePolicy = "drop_oldest" // 队列满时丢弃最旧的一条
+	QueueConflate   QueuePolicy = "conflate"    // 同一交易对只保留最新一条
+)
+
+// QueueStats 订阅队列统计
+type QueueStats struct {
+	Name      string      `json:"name"`
+	Policy    QueuePolicy `json:"policy"`
+	Capacity  int         `json:"capacity"`
+	Pending   int         `json:"pending"`   // 等待消费的条数
+	Delivered int64       `json:"delivered"` // 已交给消费者的条数
+	Dropped   int64       `json:"dropped"`   // 队列满时丢弃的条数
+	Conflated int64       `json:"conflated"` // 被同一交易对更新的数据覆盖的条数
+}
+
+// streamQueue 有界订阅队列
+type streamQueue struct {
+	mu     sync.Mutex
+	keys   []string       // 按到达顺序排列的key（drop_oldest时为空字符串）
+	values []interface{}  // 与keys一一对应
+	index  map[string]int // conflate：key -> 在keys中的位置
+	head   int            // keys[:head] 已出队
+	stats  QueueStats
+	notify chan struct{} // 有新数据时通知等待的消费者（容量1）
+}
+
+// newStreamQueue 创建订阅队列（capacity<=0时按1处理）
+func newStreamQueue(name string, policy QueuePolicy, capacity int) *streamQueue {
+	capacity = max(capacity, 1)
+	return &streamQueue{
+		index:  make(map[string]int),
+		stats:  QueueStats{Name: name, Policy: policy, Capacity: capacity},
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// push 写入一条数据（从不阻塞）
+func (q *streamQueue) push(key string, value interface{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stats.Policy == QueueConflate {
+		if i, ok := q.index[key]; ok {
+			q.values[i] = value
+			q.stats.Conflated++
+			return
+		}
+	}
+	if len(q.keys)-q.head >= q.stats.Capacity {
+		q.popLocked()
+		q.stats.Dropped++
+	}
+	if q.stats.Policy == QueueConflate {
+		q.index[key] = len(q.keys)
+	}
+	q.keys = append(q.keys, key)
+	q.values = append(q.values, value)
+	q.stats.Pending = len(q.keys) - q.head
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// popLocked 移除最早的一条数据（调用方持有锁，队列非空）
+func (q *streamQueue) popLocked() interface{} {
+	key, value := q.keys[q.head], q.values[q.head]
+	q.values[q.head] = nil
+	q.head++
+	if q.stats.Policy == QueueConflate {
+		delete(q.index, key)
+	}
+
+	// 已出队的部分超过一半时整理，底层数组不会无限增长
+	if q.head > len(q.keys)/2 {
+		q.keys = append(q.keys[:0], q.keys[q.head:]...)
+		q.values = append(q.values[:0], q.values[q.head:]...)
+		for k := range q.index {
+			q.index[k] -= q.head
+		}
+		q.head = 0
+	}
+	q.stats.Pending = len(q.keys) - q.head
+	return value
+}
+
+// pop 取出最早的一条数据（队列为空时等待，done关闭时返回false）
+func (q *streamQueue) pop(done <-chan struct{}) (interface{}, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.keys) > q.head {
+			value := q.popLocked()
+			q.stats.Delivered++
+			q.mu.Unlock()
+			return value, true
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.notify:
+		case <-done:
+			return nil, false
+		}
+	}
+}
+
+// streamSubscribers 推送的订阅者集合
+type streamSubscribers struct {
+	mu     sync.Mutex
+	queues []*streamQueue
+}
+
+// newStreamSubscribers 创建订阅者集合
+func newStreamSubscribers() *streamSubscribers {
+	return &streamSubscribers{}
+}
+
+// subscribe 新增订阅队列并启动投递协程：deliver把一条数据交给消费者（ctx取消时返回false），协程结束时移除队列并调用done
+func (s *streamSubscribers) subscribe(ctx context.Context, name string, policy QueuePolicy, capacity int, deliver func(interface{}) bool, done func()) {
+	q := s.add(name, policy, capacity)
+	go func() {
+		defer done()
+		defer s.remove(q)
+		for {
+			value, ok := q.pop(ctx.Done())
+			if !ok || !deliver(value) {
+				return
+			}
+		}
+	}()
+}
+
+// add 新增订阅队列
+func (s *streamSubscribers) add(name string, policy QueuePolicy, capacity int) *streamQueue {
+	q := newStreamQueue(name, policy, capacity)
+	s.mu.Lock()
+	s.queues = append(s.queues[:len(s.queues):len(s.queues)], q)
+	s.mu.Unlock()
+	return q
+}
+
+// remove 移除订阅队列
+func (s *streamSubscribers) remove(q *streamQueue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 重新分配切片，不影响 publish 正在遍历的旧切片
+	queues := make([]*streamQueue, 0, len(s.queues))
+	for _, queue := range s.queues {
+		if queue != q {
+			queues = append(queues, queue)
+		}
+	}
+	s.queues = queues
+}
+
+// active 是否有订阅者（没有时推送方可以跳过准备数据）
+func (s *streamSubscribers) active() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queues) > 0
+}
+
+// publish 向所有订阅队列写入一条数据（key为conflate策略合并的依据）
+func (s *streamSubscribers) publish(key string, value interface{}) {
+	s.mu.Lock()
+	queues := s.queues
+	s.mu.Unlock()
+
+	for _, q := range queues {
+		q.push(key, value)
+	}
+}
+
+// Stats 各订阅队列的统计（按名称排序）
+func (s *streamSubscribers) Stats() []QueueStats {
+	s.mu.Lock()
+	queues := append([]*streamQueue(nil), s.queues...)
+	s.mu.Unlock()
+
+	stats := make([]QueueStats, len(queues))
+	for i, q := range queues {
+		q.mu.Lock()
+		stats[i] = q.stats
+		q.mu.Unlock()
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
@@ -0,0 +1,258 @@
+/*
+Package binance 交易所系统状态与维护检测
+
+主要功能：
+- (c *Client) GetSystemStatus() (*SystemStatus, error)                          // 获取系统状态（只支持现货客户端）
+- NewStatusMonitor(system *Client) *StatusMonitor                                // 创建状态监控（system为现货客户端，nil表示只检查交易对状态）
+- (m *StatusMonitor) Watch(marketType string, client *Client, symbols []string)  // 检查该市场类型下交易对的交易状态
+- (m *StatusMonitor) Check()                                                     // 检查一次系统状态和交易对状态
+- (m *StatusMonitor) Run(ctx context.Context, interval time.Duration)            // 立即检查一次，然后按间隔定时检查，直到ctx取消
+- (m *StatusMonitor) Maintenance() (bool, string)                                // 是否处于系统维护中及说明
+- (m *StatusMonitor) Halted(marketType, symbol string) (string, bool)            // 交易对是否暂停交易及交易所返回的状态
+- (m *StatusMonitor) Status() ExchangeStatus                                     // 当前状态
+
+系统状态来自现货域名的 /sapi/v1/system/status（合约没有单独的系统状态接口，维护通常同时进行）；
+交易对状态来自各市场类型的交易规则（exchangeInfo），状态不是 TRADING（如 BREAK、SETTLING、HALT）的交易对视为暂停交易。
+请求失败时保留上一次的状态，只记录错误，维护期间接口不可用不会被误判为恢复正常。
+nil 的 *StatusMonitor 可以安全调用，始终视为正常。
+*/
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// SymbolStatusTrading 交易对正常交易的状态
+const SymbolStatusTrading = "TRADING"
+
+// SystemStatus 系统状态
+type SystemStatus struct {
+	Status int    `json:"status"` // 0正常，1维护中
+	Msg    string `json:"msg"`    // normal / system_maintenance
+}
+
+// Normal 系统是否正常
+func (s *SystemStatus) Normal() bool {
+	return s.Status == 0
+}
+
+// ExchangeStatus 交易所当前状态
+type ExchangeStatus struct {
+	Maintenance bool                         `json:"maintenance"`          // 是否处于系统维护中
+	Message     string                       `json:"message,omitempty"`    // 交易所返回的说明
+	Since       time.Time                    `json:"since"`                // 进入当前状态（维护或正常）的时间
+	Halted      map[string]map[string]string `json:"halted"`               // 市场类型 -> 暂停交易的交易对 -> 交易所返回的状态
+	CheckedAt   time.Time                    `json:"checked_at"`           // 最近一次检查的时间
+	LastError   string                       `json:"last_error,omitempty"` // 最近一次检查的错误（为空表示成功）
+}
+
+// GetSystemStatus 获取系统状态（只能通过现货客户端查询）
+func (c *Client) GetSystemStatus() (*SystemStatus, error) {
+	if !c.IsSpot() {
+		return nil, fmt.Errorf("%w: 系统状态只能通过现货客户端查询", ErrUnsupportedMarket)
+	}
+	body, err := c.doRequest("GET", EndpointSystemStatus, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取系统状态失败: %w", err)
+	}
+
+	var status SystemStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, fmt.Errorf("解析系统状态失败: %w", err)
+	}
+	return &status, nil
+}
+
+// statusMarket 一个市场类型需要检查的交易对
+type statusMarket struct {
+	client  *Client
+	symbols map[string]bool
+}
+
+// StatusMonitor 交易所系统状态与交易对交易状态监控
+type StatusMonitor struct {
+	system *Client
+
+	mu      sync.RWMutex
+	markets map[string]*statusMarket // 市场类型 -> 交易对
+	status  ExchangeStatus
+}
+
+// NewStatusMonitor 创建状态监控（system为现货客户端，nil表示不检查系统维护，只检查交易对状态）
+func NewStatusMonitor(system *Client) *StatusMonitor {
+	return &StatusMonitor{
+		system:  system,
+		markets: make(map[string]*statusMarket),
+		status:  ExchangeStatus{Since: time.Now(), Halted: make(map[string]map[string]string)},
+	}
+}
+
+// Watch 检查该市场类型下交易对的交易状态（同一市场类型多次调用时合并交易对，使用第一次传入的客户端）
+func (m *StatusMonitor) Watch(marketType string, client *Client, symbols []string) {
+	if m == nil || client == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	market, ok := m.markets[marketType]
+	if !ok {
+		market = &statusMarket{client: client, symbols: make(map[string]bool)}
+		m.markets[marketType] = market
+	}
+	for _, symbol := range symbols {
+		market.symbols[symbol] = true
+	}
+}
+
+// Run 立即检查一次，然后按间隔定时检查，直到ctx取消
+func (m *StatusMonitor) Run(ctx context.Context, interval time.Duration) {
+	if m == nil {
+		return
+	}
+	m.Check()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.Check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check 检查一次系统状态和交易对状态（请求失败的部分保留上一次的状态）
+func (m *StatusMonitor) Check() {
+	if m == nil {
+		return
+	}
+	var errs []string
+
+	var system *SystemStatus
+	if m.system != nil {
+		status, err := m.system.GetSystemStatus()
+		if err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			system = status
+		}
+	}
+
+	m.mu.RLock()
+	markets := make(map[string]*statusMarket, len(m.markets))
+	for marketType, market := range m.markets {
+		markets[marketType] = market
+	}
+	m.mu.RUnlock()
+
+	halted := make(map[string]map[string]string, len(markets))
+	for marketType, market := range markets {
+		info, err := market.client.GetExchangeInfo()
+		if err != nil {
+			errs = append(errs, marketType+": "+err.Error())
+			continue
+		}
+		halted[marketType] = haltedSymbols(info, market.symbols)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if system != nil && system.Normal() == m.status.Maintenance {
+		m.status.Maintenance, m.status.Since = !system.Normal(), now
+		if m.status.Maintenance {
+			utils.Warn("交易所进入系统维护", zap.String("msg", system.Msg))
+		} else {
+			utils.Info("交易所系统维护结束", zap.String("msg", system.Msg))
+		}
+	}
+	if system != nil {
+		m.status.Message = system.Msg
+	}
+	for marketType, symbols := range halted {
+		previous := m.status.Halted[marketType]
+		for symbol, state := range symbols {
+			if _, ok := previous[symbol]; !ok {
+				utils.Warn("交易对暂停交易", zap.String("market_type", marketType), zap.String("symbol", symbol), zap.String("status", state))
+			}
+		}
+		for symbol := range previous {
+			if _, ok := symbols[symbol]; !ok {
+				utils.Info("交易对恢复交易", zap.String("market_type", marketType), zap.String("symbol", symbol))
+			}
+		}
+		m.status.Halted[marketType] = symbols
+	}
+	m.status.CheckedAt = now
+	m.status.LastError = strings.Join(errs, "; ")
+	if len(errs) > 0 {
+		utils.Warn("检查交易所状态失败", zap.Strings("errors", errs))
+	}
+}
+
+// haltedSymbols 交易规则中状态不是 TRADING 的交易对（交易规则中没有的交易对不算暂停）
+func haltedSymbols(info *ExchangeInfo, symbols map[string]bool) map[string]string {
+	halted := make(map[string]string)
+	for _, s := range info.Symbols {
+		if symbols[s.Symbol] && s.Status != "" && s.Status != SymbolStatusTrading {
+			halted[s.Symbol] = s.Status
+		}
+	}
+	return halted
+}
+
+// Maintenance 是否处于系统维护中及交易所返回的说明
+func (m *StatusMonitor) Maintenance() (bool, string) {
+	if m == nil {
+		return false, ""
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.status.Maintenance, m.status.Message
+}
+
+// Halted 交易对是否暂停交易及交易所返回的状态
+func (m *StatusMonitor) Halted(marketType, symbol string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state, ok := m.status.Halted[marketType][symbol]
+	return state, ok
+}
+
+// Status 当前状态（副本）
+func (m *StatusMonitor) Status() ExchangeStatus {
+	if m == nil {
+		return ExchangeStatus{}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status := m.status
+	status.Halted = make(map[string]map[string]string, len(m.status.Halted))
+	for marketType, symbols := range m.status.Halted {
+		copied := make(map[string]string, len(symbols))
+		for symbol, state := range symbols {
+			copied[symbol] = state
+		}
+		status.Halted[marketType] = copied
+	}
+	return status
+}
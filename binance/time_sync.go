@@ -0,0 +1,64 @@
+/*
+Package binance 请求时间戳与服务器时间同步
+
+主要功能：
+- (c *Client) SetRecvWindow(ms int64)     // 设置签名请求的recvWindow（毫秒，0表示使用交易所默认值5000）
+- (c *Client) SyncTime() error            // 按服务器时间校准本地时间偏移
+- (c *Client) TimeOffset() time.Duration  // 当前时间偏移（服务器时间 - 本地时间）
+
+签名请求的时间戳为本地时间加上时间偏移。交易所返回 -1021（时间戳超出recvWindow）时，
+doRequest 自动同步一次服务器时间并重新签名重试一次，VPS时钟轻微漂移不会导致交易中断。
+*/
+package binance
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// MaxRecvWindow 交易所允许的最大recvWindow（毫秒）
+const MaxRecvWindow = 60000
+
+// SetRecvWindow 设置签名请求的recvWindow（毫秒，0表示不传，使用交易所默认值5000；超过上限时按上限）
+func (c *Client) SetRecvWindow(ms int64) {
+	if ms < 0 {
+		ms = 0
+	}
+	if ms > MaxRecvWindow {
+		ms = MaxRecvWindow
+	}
+	c.recvWindow = ms
+}
+
+// TimeOffset 当前时间偏移（服务器时间 - 本地时间）
+func (c *Client) TimeOffset() time.Duration {
+	return time.Duration(c.timeOffset.Load()) * time.Millisecond
+}
+
+// SyncTime 按服务器时间校准本地时间偏移（用请求往返的中点估计本地时间）
+func (c *Client) SyncTime() error {
+	start := time.Now()
+	serverTime, err := c.GetServerTime()
+	if err != nil {
+		return fmt.Errorf("同步服务器时间失败: %w", err)
+	}
+	local := start.Add(time.Since(start) / 2).UnixMilli()
+
+	offset := serverTime - local
+	previous := c.timeOffset.Swap(offset)
+	utils.Info("同步服务器时间",
+		zap.String("base_url", c.baseURL),
+		zap.Int64("offset_ms", offset),
+		zap.Int64("previous_offset_ms", previous),
+	)
+	return nil
+}
+
+// timestamp 签名请求的时间戳（本地时间加时间偏移，毫秒）
+func (c *Client) timestamp() int64 {
+	return time.Now().UnixMilli() + c.timeOffset.Load()
+}
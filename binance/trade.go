@@ -0,0 +1,76 @@
+/*
+Package binance 账户成交历史
+
+主要功能：
+- (c *Client) GetUserTrades(symbol string, startTime, endTime int64) ([]UserTrade, error)  // 查询账户成交历史
+- (t *UserTrade) PriceFloat() float64                                                    // 成交价格
+- (t *UserTrade) QtyFloat() float64                                                      // 成交数量
+- (t *UserTrade) CommissionFloat() float64                                               // 手续费
+*/
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// UserTrade 账户成交记录（一笔订单可能对应多条成交）
+type UserTrade struct {
+	ID              int64  `json:"id"`              // 成交ID
+	Symbol          string `json:"symbol"`          // 交易对
+	OrderID         int64  `json:"orderId"`         // 订单ID
+	Side            string `json:"side"`            // 方向
+	PositionSide    string `json:"positionSide"`    // 持仓方向
+	Price           string `json:"price"`           // 成交价格
+	Qty             string `json:"qty"`             // 成交数量
+	QuoteQty        string `json:"quoteQty"`        // 成交额
+	RealizedPnl     string `json:"realizedPnl"`     // 实现盈亏（不含手续费）
+	Commission      string `json:"commission"`      // 手续费
+	CommissionAsset string `json:"commissionAsset"` // 手续费资产
+	Maker           bool   `json:"maker"`           // 是否为Maker成交
+	Buyer           bool   `json:"buyer"`           // 是否为买方
+	Time            int64  `json:"time"`            // 成交时间
+}
+
+// GetUserTrades 查询账户成交历史
+// symbol: 交易对，如 "BTCUSDT"
+// startTime/endTime: 毫秒时间戳，时间跨度不能超过7天
+func (c *Client) GetUserTrades(symbol string, startTime, endTime int64) ([]UserTrade, error) {
+	params := map[string]string{
+		"symbol":    symbol,
+		"startTime": strconv.FormatInt(startTime, 10),
+		"endTime":   strconv.FormatInt(endTime, 10),
+		"limit":     "1000",
+	}
+
+	body, err := c.doRequest("GET", EndpointUserTrades, params, true)
+	if err != nil {
+		return nil, fmt.Errorf("查询成交历史失败: %w", err)
+	}
+
+	var trades []UserTrade
+	if err := json.Unmarshal(body, &trades); err != nil {
+		return nil, fmt.Errorf("解析成交历史失败: %w", err)
+	}
+
+	return trades, nil
+}
+
+// PriceFloat 成交价格
+func (t *UserTrade) PriceFloat() float64 {
+	price, _ := strconv.ParseFloat(t.Price, 64)
+	return price
+}
+
+// QtyFloat 成交数量
+func (t *UserTrade) QtyFloat() float64 {
+	qty, _ := strconv.ParseFloat(t.Qty, 64)
+	return qty
+}
+
+// CommissionFloat 手续费
+func (t *UserTrade) CommissionFloat() float64 {
+	commission, _ := strconv.ParseFloat(t.Commission, 64)
+	return commission
+}
@@ -0,0 +1,78 @@
+/*
+Package binance 现货与合约账户之间的资金划转
+
+主要功能：
+- (c *Client) Transfer(transferType, asset string, amount float64) (int64, error)  // 划转资金，返回划转ID（只支持现货客户端）
+- FuturesTransferTypes(marketType string) (in, out string, err error)              // 合约市场类型对应的转入、转出划转类型
+
+划转接口 /sapi/v1/asset/transfer 只在现货域名提供，合约账号需要用同一组API密钥创建现货客户端，
+API密钥需要开启万向划转权限。
+*/
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 划转类型
+const (
+	TransferMainToUMFuture = "MAIN_UMFUTURE" // 现货 → U本位合约
+	TransferUMFutureToMain = "UMFUTURE_MAIN" // U本位合约 → 现货
+	TransferMainToCMFuture = "MAIN_CMFUTURE" // 现货 → 币本位合约
+	TransferCMFutureToMain = "CMFUTURE_MAIN" // 币本位合约 → 现货
+)
+
+// transferResponse 划转返回
+type transferResponse struct {
+	TranID int64 `json:"tranId"`
+}
+
+// Transfer 在现货与合约账户之间划转资金，返回划转ID（只能通过现货客户端调用）
+func (c *Client) Transfer(transferType, asset string, amount float64) (int64, error) {
+	if !c.IsSpot() {
+		return 0, fmt.Errorf("%w: 资金划转只能通过现货客户端调用", ErrUnsupportedMarket)
+	}
+	if amount <= 0 {
+		return 0, fmt.Errorf("划转数量必须大于0: %v", amount)
+	}
+
+	params := map[string]string{
+		"type":   transferType,
+		"asset":  asset,
+		"amount": strconv.FormatFloat(amount, 'f', -1, 64),
+	}
+	body, err := c.doRequest("POST", EndpointAssetTransfer, params, true)
+	if err != nil {
+		return 0, fmt.Errorf("资金划转失败: %w", err)
+	}
+
+	var resp transferResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, fmt.Errorf("解析划转结果失败: %w", err)
+	}
+
+	utils.Info("资金划转成功",
+		zap.String("type", transferType),
+		zap.String("asset", asset),
+		zap.Float64("amount", amount),
+		zap.Int64("tran_id", resp.TranID),
+	)
+	return resp.TranID, nil
+}
+
+// FuturesTransferTypes 合约市场类型对应的划转类型：in 为现货转入合约，out 为合约转出到现货
+func FuturesTransferTypes(marketType string) (string, string, error) {
+	switch marketType {
+	case MarketTypeUSDTM:
+		return TransferMainToUMFuture, TransferUMFutureToMain, nil
+	case MarketTypeCoinM:
+		return TransferMainToCMFuture, TransferCMFutureToMain, nil
+	}
+	return "", "", fmt.Errorf("%w: %s 不是合约市场类型", ErrUnsupportedMarket, marketType)
+}
@@ -0,0 +1,410 @@
+/*
+Package binance WebSocket连接（行情推送使用的最小RFC 6455客户端，只接收服务端推送）
+
+主要功能：
+- dialWS(ctx context.Context, rawURL, proxyURL string) (*wsConn, error)  // 建立WebSocket连接（支持ws/wss，可经HTTP代理，ctx取消时自动关闭）
+- (c *wsConn) ReadMessage(timeout time.Duration) ([]byte, error)        // 读取一条完整消息（自动回复ping）
+- (c *wsConn) WriteMessage(payload []byte) error                        // 发送一条文本消息（如组合推送的订阅请求）
+- (c *wsConn) Close() error                                             // 关闭连接
+- (b *wsBackoff) next(connected time.Duration) time.Duration            // 断线重连前的等待时间（指数退避）
+
+币安推送只使用文本消息，服务端定时发送ping，客户端需要在10分钟内回复pong，否则断开连接。
+客户端发送的帧按协议要求加掩码；分片消息拼接后返回。
+
+连接存活检测：客户端每20秒发送一次ping，60秒内没有收到任何帧（包括pong）时视为连接失效并关闭，
+读取返回 errWSStale，调用方按断线处理。行情平静时没有推送的连接因此不会被误判，
+半开的连接（对端已消失但TCP未断开）也能在1分钟内发现。
+
+断线重连使用指数退避：第一次等待1秒，之后每次翻倍，最长1分钟，并加±20%随机抖动避免多个连接同时重连；
+上一次连接保持超过1分钟时从1秒重新开始。
+*/
+package binance
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	mrand "math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WebSocket帧类型
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+)
+
+// wsGUID 握手时计算Sec-WebSocket-Accept使用的固定GUID
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// wsMaxMessage 单条消息的最大长度（全市场标记价格约几百KB）
+const wsMaxMessage = 16 << 20
+
+// 连接存活检测
+const (
+	wsPingInterval = 20 * time.Second // 客户端发送ping的间隔
+	wsPongTimeout  = 60 * time.Second // 超过该时间没有收到任何帧视为连接失效
+)
+
+// 断线重连的退避时间
+const (
+	wsBackoffMin    = time.Second // 第一次重连的等待时间
+	wsBackoffMax    = time.Minute // 最长等待时间
+	wsBackoffStable = time.Minute // 连接保持超过该时间后退避从头开始
+)
+
+var (
+	// errWSClosed 服务端关闭了连接
+	errWSClosed = errors.New("WebSocket连接已被服务端关闭")
+	// errWSStale ping长时间没有响应，连接已失效
+	errWSStale = errors.New("WebSocket连接失效（ping无响应）")
+)
+
+// wsConn WebSocket连接
+type wsConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	writeMu   sync.Mutex
+	lastFrame atomic.Int64 // 最近一次收到帧的时间（毫秒时间戳）
+	stale     atomic.Bool  // 因ping无响应被关闭
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// dialWS 建立WebSocket连接（proxyURL为HTTP代理地址，为空时直连）
+func dialWS(ctx context.Context, rawURL, proxyURL string) (*wsConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("解析WebSocket地址失败: %w", err)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		switch u.Scheme {
+		case "wss":
+			host = net.JoinHostPort(u.Hostname(), "443")
+		case "ws":
+			host = net.JoinHostPort(u.Hostname(), "80")
+		default:
+			return nil, fmt.Errorf("不支持的WebSocket地址: %s", rawURL)
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	if proxyURL != "" {
+		conn, err = dialProxy(ctx, dialer, proxyURL, host)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", host)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("连接WebSocket服务器失败: %w", err)
+	}
+
+	if u.Scheme == "wss" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS握手失败: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	ws := &wsConn{conn: conn, reader: bufio.NewReader(conn), done: make(chan struct{})}
+	if err := ws.handshake(u); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	ws.lastFrame.Store(time.Now().UnixMilli())
+	go ws.keepAlive(ctx)
+	return ws, nil
+}
+
+// keepAlive 定时发送ping并检查连接是否存活，ctx取消或连接失效时关闭连接（使阻塞的读取返回）
+func (c *wsConn) keepAlive(ctx context.Context) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.Close()
+			return
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if time.Since(time.UnixMilli(c.lastFrame.Load())) > wsPongTimeout {
+				c.stale.Store(true)
+				c.Close()
+				return
+			}
+			if err := c.writeFrame(wsOpPing, nil); err != nil {
+				c.Close()
+				return
+			}
+		}
+	}
+}
+
+// dialProxy 通过HTTP代理的CONNECT方法建立到目标地址的隧道
+func dialProxy(ctx context.Context, dialer *net.Dialer, proxyURL, target string) (net.Conn, error) {
+	proxy, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("解析代理URL失败: %w", err)
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", proxy.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: target},
+		Host:   target,
+		Header: make(http.Header),
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("代理拒绝连接: %s", resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// handshake 发送升级请求并校验服务端响应
+func (c *wsConn) handshake(u *url.URL) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("生成握手密钥失败: %w", err)
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req := &http.Request{
+		Method: http.MethodGet,
+		URL:    &url.URL{Path: u.Path, RawPath: u.RawPath, RawQuery: u.RawQuery},
+		Host:   u.Host,
+		Header: http.Header{
+			"Upgrade":               {"websocket"},
+			"Connection":            {"Upgrade"},
+			"Sec-WebSocket-Key":     {key},
+			"Sec-WebSocket-Version": {"13"},
+		},
+	}
+	if req.URL.Path == "" {
+		req.URL.Path = "/"
+	}
+
+	c.conn.SetDeadline(time.Now().Add(10 * time.Second))
+	defer c.conn.SetDeadline(time.Time{})
+
+	if err := req.Write(c.conn); err != nil {
+		return fmt.Errorf("发送WebSocket握手请求失败: %w", err)
+	}
+	resp, err := http.ReadResponse(c.reader, req)
+	if err != nil {
+		return fmt.Errorf("读取WebSocket握手响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return fmt.Errorf("WebSocket握手失败: %s %s", resp.Status, string(body))
+	}
+
+	sum := sha1.Sum([]byte(key + wsGUID))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		return fmt.Errorf("WebSocket握手失败: Sec-WebSocket-Accept不匹配")
+	}
+	return nil
+}
+
+// ReadMessage 读取一条完整消息（timeout内没有收到数据消息时返回错误，ping/pong不计入，0表示不限制）
+// ping帧自动回复pong，pong帧忽略，收到close帧时返回errWSClosed，连接因ping无响应被关闭时返回errWSStale
+func (c *wsConn) ReadMessage(timeout time.Duration) ([]byte, error) {
+	if timeout > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(timeout))
+	} else {
+		c.conn.SetReadDeadline(time.Time{})
+	}
+
+	var message []byte
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			if c.stale.Load() {
+				return nil, errWSStale
+			}
+			return nil, err
+		}
+		c.lastFrame.Store(time.Now().UnixMilli())
+
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return nil, fmt.Errorf("回复pong失败: %w", err)
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			c.writeFrame(wsOpClose, payload)
+			return nil, errWSClosed
+		case wsOpText, wsOpBinary, wsOpContinuation:
+			message = append(message, payload...)
+			if len(message) > wsMaxMessage {
+				return nil, fmt.Errorf("WebSocket消息超过长度上限: %d", len(message))
+			}
+			if fin {
+				return message, nil
+			}
+		default:
+			return nil, fmt.Errorf("未知的WebSocket帧类型: %d", opcode)
+		}
+	}
+}
+
+// readFrame 读取一帧
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(c.reader, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.reader, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.reader, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > wsMaxMessage {
+		err = fmt.Errorf("WebSocket帧超过长度上限: %d", length)
+		return
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.reader, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.reader, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
+
+// writeFrame 发送一帧（客户端帧必须加掩码）
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	frame := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// WriteMessage 发送一条文本消息
+func (c *wsConn) WriteMessage(payload []byte) error {
+	return c.writeFrame(wsOpText, payload)
+}
+
+// Close 关闭连接（可重复调用）
+func (c *wsConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// wsBackoff 断线重连的指数退避
+type wsBackoff struct {
+	attempt int
+}
+
+// next 断线重连前的等待时间（connected为刚断开的连接保持的时间，超过1分钟时从头开始）
+func (b *wsBackoff) next(connected time.Duration) time.Duration {
+	if connected >= wsBackoffStable {
+		b.attempt = 0
+	}
+	delay := wsBackoffMax
+	if b.attempt < 6 {
+		delay = min(wsBackoffMin<<b.attempt, wsBackoffMax)
+	}
+	b.attempt++
+
+	// ±20%随机抖动
+	jitter := delay / 5
+	return delay - jitter + time.Duration(mrand.Int64N(int64(2*jitter)+1))
+}
@@ -5,8 +5,17 @@ Package config 账号配置管理
 - LoadAccounts(accountsPath string) ([]Account, error)  // 加载账号配置文件
 - (a *Account) Validate() error                          // 验证账号配置
 - (a *Account) GetStrategyName() string                  // 获取策略名称（中文）
+- (a *Account) GetMarketType() string                    // 获取市场类型（默认usdt_m）
+- (a *Account) GetExchange() string                      // 获取交易所（默认binance）
+- (a *Account) GetMode() string                          // 获取运行模式（live、paper、observe）
+- (a *Account) IsLive() bool                             // 是否实盘账号（会调用账户和下单接口）
+- (a *Account) GetSizingConfig() SizingConfig            // 获取仓位计算配置（含默认值）
+- (a *Account) GetShadowConfig() ShadowConfig            // 获取影子模式配置（含默认值）
 - (a *Account) GetPromptTypeName() string                // 获取提示词类型名称（中文）
 - (a *Account) GetPromptTypeDescription() string         // 获取提示词类型描述
+- (a *Account) GetPromptTemplates() []string              // 获取提示词模板候选名称（按优先级）
+- (a *Account) IsCompactPayload() bool                    // 提示词中的指标数据是否使用紧凑格式
+- (a *Account) IsDeltaPayload() bool                      // 提示词中的指标数据是否只列出变化明显的字段
 */
 package config
 
@@ -19,13 +28,123 @@ import (
 
 // Account 账号配置
 type Account struct {
-	ID         string `yaml:"id"`
-	Name       string `yaml:"name"`
-	Strategy   string `yaml:"strategy"`    // short_term 或 long_term
-	PromptType string `yaml:"prompt_type"` // minimal 或 detailed
-	APIKey     string `yaml:"api_key"`
-	APISecret  string `yaml:"api_secret"`
-	Enabled    bool   `yaml:"enabled"`
+	ID             string `yaml:"id"`
+	Name           string `yaml:"name"`
+	Strategy       string `yaml:"strategy"`        // 策略注册名称（内置 short_term 或 long_term）
+	PromptType     string `yaml:"prompt_type"`     // minimal 或 detailed
+	PromptTemplate string `yaml:"prompt_template"` // 提示词模板名称（configs/prompts/<名称>.tmpl，留空按策略和提示词类型选择）
+	PayloadFormat  string `yaml:"payload_format"`  // 提示词中指标数据的格式：full（格式化JSON，默认）、compact（短字段名、低精度、省略空值）或 delta（只列出变化明显的字段）
+	APIKey         string `yaml:"api_key"`
+	APISecret      string `yaml:"api_secret"`
+	Enabled        bool   `yaml:"enabled"`
+	Mode           string `yaml:"mode"`        // 运行模式：live（实盘，默认）、paper（模拟成交，同shadow.enabled）或 observe（只观察）
+	MarketType     string `yaml:"market_type"` // 市场类型：usdt_m（U本位合约，默认）、coin_m（币本位合约）或 spot（现货）
+	Exchange       string `yaml:"exchange"`    // 交易所：binance（默认）或 okx（仅U本位永续合约）
+	Passphrase     string `yaml:"passphrase"`  // API密码（OKX需要）
+	Leverage       int    `yaml:"leverage"`    // 杠杆倍数（覆盖全局 position.leverage）
+	MarginType     string `yaml:"margin_type"` // 保证金模式（覆盖全局 position.margin_type）
+
+	Sizing         SizingConfig         `yaml:"sizing"`          // 仓位计算方式
+	Cycle          CycleConfig          `yaml:"cycle"`           // 运行周期（覆盖策略默认的分析周期）
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"` // 最大回撤熔断
+	Shadow         ShadowConfig         `yaml:"shadow"`          // 影子模式（只记录决策和模拟成交，不下真实订单）
+	Flatten        FlattenConfig        `yaml:"flatten"`         // 低流动性时段前自动减仓或平仓
+	FlatTime       FlatTimeConfig       `yaml:"flat_time"`       // 每日定时平仓
+	Treasury       TreasuryConfig       `yaml:"treasury"`        // 合约账户资金自动调拨
+
+	StrategyParams map[string]interface{} `yaml:"strategy_params"` // 策略自定义参数（传给Strategy.Init）
+}
+
+// SizingConfig 仓位计算配置
+// volatility模式：数量 = 风险金额 / (ATR × 倍数)，名义价值与ATR%成反比，
+// 按 ATR×倍数 的止损距离计算时，每笔交易的风险金额大致相同
+type SizingConfig struct {
+	Mode            string  `yaml:"mode"`              // fixed（使用决策给出的数量，默认）或 volatility（按波动率计算）
+	RiskUSDT        float64 `yaml:"risk_usdt"`         // 每笔交易的风险金额（USDT）
+	RiskPct         float64 `yaml:"risk_pct"`          // 每笔交易的风险占账户余额的百分比（设置后优先于risk_usdt，不支持币本位）
+	Timeframe       string  `yaml:"timeframe"`         // 计算ATR的K线周期（默认1h）
+	ATRPeriod       int     `yaml:"atr_period"`        // ATR周期（默认14）
+	ATRMultiple     float64 `yaml:"atr_multiple"`      // 预期止损距离为ATR的倍数（默认1.5）
+	MaxNotionalUSDT float64 `yaml:"max_notional_usdt"` // 单笔名义价值上限（USDT，0表示不限）
+}
+
+// CycleConfig 运行周期配置
+// 分析周期覆盖策略默认的运行周期（如短线5分钟、中长线15分钟）；
+// 持仓管理周期在两次分析之间只对有持仓的交易对获取数据并请求AI决策，应短于分析周期
+type CycleConfig struct {
+	IntervalSec       int `yaml:"interval_sec"`        // 分析周期（秒，0表示使用策略默认周期）
+	ManageIntervalSec int `yaml:"manage_interval_sec"` // 持仓管理周期（秒，0表示不启用）
+}
+
+// Validate 验证运行周期配置
+func (c CycleConfig) Validate() error {
+	if c.IntervalSec < 0 || c.ManageIntervalSec < 0 {
+		return fmt.Errorf("interval_sec和manage_interval_sec不能为负数")
+	}
+	if c.IntervalSec > 0 && c.IntervalSec < 10 {
+		return fmt.Errorf("分析周期不能短于10秒: %d", c.IntervalSec)
+	}
+	if c.ManageIntervalSec > 0 && c.ManageIntervalSec < 10 {
+		return fmt.Errorf("持仓管理周期不能短于10秒: %d", c.ManageIntervalSec)
+	}
+	if c.IntervalSec > 0 && c.ManageIntervalSec >= c.IntervalSec {
+		return fmt.Errorf("持仓管理周期(%d秒)应短于分析周期(%d秒)", c.ManageIntervalSec, c.IntervalSec)
+	}
+	return nil
+}
+
+// CircuitBreakerConfig 最大回撤熔断（权益从峰值回撤超过上限时进入只平仓模式，需通过API手动恢复）
+type CircuitBreakerConfig struct {
+	MaxDrawdownPct float64 `yaml:"max_drawdown_pct"` // 最大回撤百分比（0表示不启用）
+}
+
+// ShadowConfig 影子模式配置
+// 影子账号与实盘账号并行运行，决策按最新价格模拟成交并写入交易日志，用于评估新的提示词和模型
+type ShadowConfig struct {
+	Enabled        bool    `yaml:"enabled"`         // 是否启用影子模式
+	InitialBalance float64 `yaml:"initial_balance"` // 虚拟初始资金（USDT，默认10000，用于计算收益率和绩效指标）
+}
+
+// 账号运行模式
+// observe 模式计算指标、请求AI决策并写入审计记录，但不调用任何账户和下单接口（适用于只读权限的API密钥或没有密钥）
+const (
+	AccountModeLive    = "live"
+	AccountModePaper   = "paper"
+	AccountModeObserve = "observe"
+)
+
+// 提示词中指标数据的格式
+const (
+	PayloadFormatFull    = "full"    // 格式化的完整JSON
+	PayloadFormatCompact = "compact" // 短字段名、低精度、省略空值的单行JSON，token更少
+	PayloadFormatDelta   = "delta"   // 只列出与上次发送相比变化明显的字段（定期发送完整数据，见 DeltaConfig）
+)
+
+// 仓位计算方式
+const (
+	SizingModeFixed      = "fixed"
+	SizingModeVolatility = "volatility"
+)
+
+// Validate 验证仓位计算配置
+func (s SizingConfig) Validate() error {
+	switch s.Mode {
+	case "", SizingModeFixed:
+		return nil
+	case SizingModeVolatility:
+	default:
+		return fmt.Errorf("仓位计算方式无效: %s (必须是 fixed 或 volatility)", s.Mode)
+	}
+	if s.RiskUSDT <= 0 && s.RiskPct <= 0 {
+		return fmt.Errorf("volatility模式必须设置risk_usdt或risk_pct")
+	}
+	if s.RiskUSDT < 0 || s.RiskPct < 0 || s.RiskPct > 100 {
+		return fmt.Errorf("风险金额无效: risk_usdt=%v risk_pct=%v", s.RiskUSDT, s.RiskPct)
+	}
+	if s.ATRPeriod < 0 || s.ATRMultiple < 0 || s.MaxNotionalUSDT < 0 {
+		return fmt.Errorf("atr_period、atr_multiple和max_notional_usdt不能为负数")
+	}
+	return nil
 }
 
 // AccountsConfig 账号配置文件结构
@@ -47,8 +166,12 @@ func LoadAccounts(accountsPath string) ([]Account, error) {
 		return nil, fmt.Errorf("解析账号配置文件失败: %w", err)
 	}
 
-	// 验证账号配置
-	for i, acc := range accountsCfg.Accounts {
+	// 验证账号配置（mode: paper 等同于 shadow.enabled: true）
+	for i := range accountsCfg.Accounts {
+		acc := &accountsCfg.Accounts[i]
+		if acc.Mode == AccountModePaper {
+			acc.Shadow.Enabled = true
+		}
 		if err := acc.Validate(); err != nil {
 			return nil, fmt.Errorf("账号[%d]配置无效: %w", i, err)
 		}
@@ -65,12 +188,94 @@ func (a *Account) Validate() error {
 	if a.Name == "" {
 		return fmt.Errorf("账号名称不能为空")
 	}
-	if a.Strategy != "short_term" && a.Strategy != "long_term" {
-		return fmt.Errorf("策略类型无效: %s (必须是 short_term 或 long_term)", a.Strategy)
+	// 策略名称是否已注册由 strategy 包在启动时校验
+	if a.Strategy == "" {
+		return fmt.Errorf("策略类型不能为空")
 	}
 	if a.PromptType != "minimal" && a.PromptType != "detailed" {
 		return fmt.Errorf("提示词类型无效: %s (必须是 minimal 或 detailed)", a.PromptType)
 	}
+	switch a.PayloadFormat {
+	case "", PayloadFormatFull, PayloadFormatCompact, PayloadFormatDelta:
+	default:
+		return fmt.Errorf("指标数据格式无效: %s (必须是 full、compact 或 delta)", a.PayloadFormat)
+	}
+	switch a.MarketType {
+	case "", "usdt_m", "coin_m", "spot":
+	default:
+		return fmt.Errorf("市场类型无效: %s (必须是 usdt_m、coin_m 或 spot)", a.MarketType)
+	}
+	switch a.Exchange {
+	case "", "binance":
+	case "okx":
+		if a.GetMarketType() != "usdt_m" {
+			return fmt.Errorf("OKX只支持U本位永续合约(usdt_m)，当前: %s", a.MarketType)
+		}
+	default:
+		return fmt.Errorf("交易所无效: %s (必须是 binance 或 okx)", a.Exchange)
+	}
+	switch a.Mode {
+	case "", AccountModeLive, AccountModePaper, AccountModeObserve:
+	default:
+		return fmt.Errorf("运行模式无效: %s (必须是 live、paper 或 observe)", a.Mode)
+	}
+	if a.Shadow.Enabled && a.Mode != "" && a.Mode != AccountModePaper {
+		return fmt.Errorf("启用影子模式(shadow.enabled)的账号运行模式只能是paper，当前: %s", a.Mode)
+	}
+	if err := a.Sizing.Validate(); err != nil {
+		return err
+	}
+	if err := a.Cycle.Validate(); err != nil {
+		return err
+	}
+	if a.Sizing.RiskPct > 0 && a.GetMarketType() == "coin_m" {
+		return fmt.Errorf("币本位账号不支持risk_pct，请使用risk_usdt")
+	}
+	if dd := a.CircuitBreaker.MaxDrawdownPct; dd < 0 || dd >= 100 {
+		return fmt.Errorf("最大回撤百分比无效: %v (必须在0-100之间，0表示不启用)", dd)
+	} else if dd > 0 && (a.GetExchange() != "binance" || a.GetMarketType() == "coin_m") {
+		return fmt.Errorf("最大回撤熔断只支持币安U本位合约和现货账号")
+	}
+	if err := a.Flatten.Validate(); err != nil {
+		return err
+	}
+	if err := a.FlatTime.Validate(); err != nil {
+		return err
+	}
+	if err := a.Treasury.Validate(); err != nil {
+		return err
+	}
+	if a.Treasury.Enabled {
+		if a.GetExchange() != "binance" || a.GetMarketType() == "spot" || !a.IsLive() {
+			return fmt.Errorf("资金调拨只支持币安合约实盘账号")
+		}
+		if a.GetMarketType() == "coin_m" && a.Treasury.Asset == "" {
+			return fmt.Errorf("币本位账号的资金调拨需要配置划转资产(asset)")
+		}
+	}
+	if a.GetMode() == AccountModeObserve {
+		// 观察账号只使用公开行情接口，API密钥可以留空或使用只读权限的密钥
+		if a.CircuitBreaker.MaxDrawdownPct > 0 {
+			return fmt.Errorf("观察账号不支持最大回撤熔断")
+		}
+		return nil
+	}
+	if a.Shadow.Enabled {
+		// 影子账号只使用公开行情接口，不需要API密钥
+		if a.GetMarketType() != "usdt_m" {
+			return fmt.Errorf("影子模式只支持U本位合约账号")
+		}
+		if a.CircuitBreaker.MaxDrawdownPct > 0 {
+			return fmt.Errorf("影子账号不支持最大回撤熔断")
+		}
+		if a.Shadow.InitialBalance < 0 {
+			return fmt.Errorf("影子账号初始资金不能为负数")
+		}
+		return nil
+	}
+	if a.GetExchange() == "okx" && a.Passphrase == "" {
+		return fmt.Errorf("OKX账号的API密码(passphrase)不能为空")
+	}
 	if a.APIKey == "" {
 		return fmt.Errorf("API Key不能为空")
 	}
@@ -80,6 +285,66 @@ func (a *Account) Validate() error {
 	return nil
 }
 
+// GetMarketType 获取市场类型（未配置时为U本位合约）
+func (a *Account) GetMarketType() string {
+	if a.MarketType == "" {
+		return "usdt_m"
+	}
+	return a.MarketType
+}
+
+// GetExchange 获取交易所（未配置时为币安）
+func (a *Account) GetExchange() string {
+	if a.Exchange == "" {
+		return "binance"
+	}
+	return a.Exchange
+}
+
+// GetMode 获取运行模式（未配置时影子账号为paper，其余为live）
+func (a *Account) GetMode() string {
+	switch {
+	case a.Mode != "":
+		return a.Mode
+	case a.Shadow.Enabled:
+		return AccountModePaper
+	default:
+		return AccountModeLive
+	}
+}
+
+// IsLive 是否实盘账号（会调用账户和下单接口）
+func (a *Account) IsLive() bool {
+	return a.GetMode() == AccountModeLive
+}
+
+// GetSizingConfig 获取仓位计算配置（含默认值）
+func (a *Account) GetSizingConfig() SizingConfig {
+	s := a.Sizing
+	if s.Mode == "" {
+		s.Mode = SizingModeFixed
+	}
+	if s.Timeframe == "" {
+		s.Timeframe = "1h"
+	}
+	if s.ATRPeriod == 0 {
+		s.ATRPeriod = 14
+	}
+	if s.ATRMultiple == 0 {
+		s.ATRMultiple = 1.5
+	}
+	return s
+}
+
+// GetShadowConfig 获取影子模式配置（含默认值）
+func (a *Account) GetShadowConfig() ShadowConfig {
+	s := a.Shadow
+	if s.InitialBalance == 0 {
+		s.InitialBalance = 10000
+	}
+	return s
+}
+
 // GetStrategyName 获取策略名称（中文）
 func (a *Account) GetStrategyName() string {
 	switch a.Strategy {
@@ -87,8 +352,16 @@ func (a *Account) GetStrategyName() string {
 		return "短线"
 	case "long_term":
 		return "中长线"
+	case "scalp":
+		return "剥头皮"
+	case "swing":
+		return "波段"
+	case "funding_scan":
+		return "资金费率扫描"
+	case "pair_trade":
+		return "配对交易"
 	default:
-		return "未知"
+		return a.Strategy
 	}
 }
 
@@ -115,3 +388,22 @@ func (a *Account) GetPromptTypeDescription() string {
 		return "未知类型"
 	}
 }
+
+// GetPromptTemplates 获取提示词模板候选名称（按优先级）
+// 指定了 prompt_template 时只使用该模板，否则依次尝试 <策略>_<提示词类型> 和 <提示词类型>
+func (a *Account) GetPromptTemplates() []string {
+	if a.PromptTemplate != "" {
+		return []string{a.PromptTemplate}
+	}
+	return []string{a.Strategy + "_" + a.PromptType, a.PromptType}
+}
+
+// IsCompactPayload 提示词中的指标数据是否使用紧凑格式
+func (a *Account) IsCompactPayload() bool {
+	return a.PayloadFormat == PayloadFormatCompact
+}
+
+// IsDeltaPayload 提示词中的指标数据是否只列出变化明显的字段
+func (a *Account) IsDeltaPayload() bool {
+	return a.PayloadFormat == PayloadFormatDelta
+}
@@ -6,8 +6,39 @@ Package config 配置管理模块
 - Get() *Config                                       // 获取全局配置
 - (c *Config) Validate() error                        // 验证配置
 - (c *Config) GetProxyURL() string                    // 获取代理URL
+- (c *Config) GetLocation() *time.Location            // 获取时区（每日边界、定时任务，默认UTC）
 - (c *Config) GetEnabledAccounts() []Account          // 获取所有启用的账号
 - (c *Config) GetAccountByID(id string) *Account      // 根据ID获取账号
+- (c *Config) GetExecutionConfig(strategy string) ExecutionConfig  // 获取策略的执行配置
+- (c *Config) GetJournalConfig() JournalConfig                       // 获取交易日志配置（含默认值）
+- (c *Config) GetAPIConfig() APIConfig                               // 获取状态API配置（含默认值）
+- (c *Config) GetTelegramConfig() TelegramConfig                     // 获取Telegram机器人配置（含默认值，令牌可来自环境变量）
+- (c *Config) GetSecretsConfig() SecretsConfig                       // 获取密钥来源配置（含默认值，Vault地址和令牌可来自环境变量）
+- (c *Config) GetPromptsConfig() PromptsConfig                       // 获取提示词模板配置（含默认值）
+- (c *Config) GetAIConfig() AIConfig                                 // 获取AI模型配置（含默认值，密钥可来自环境变量）
+- (c *Config) GetRankingConfig(strategy string) RankingConfig        // 获取策略的多交易对排名配置（含默认值）
+- (c *Config) GetTwoStageConfig(strategy string) TwoStageConfig      // 获取策略的两阶段分析配置（含默认值）
+- (c *Config) GetVotingConfig(strategy string) VotingConfig          // 获取策略的多次采样投票配置（含默认值）
+- (c *Config) GetToolsConfig(strategy string) ToolsConfig            // 获取策略的AI工具调用配置（含默认值）
+- (c *Config) GetDecisionCacheConfig(strategy string) DecisionCacheConfig  // 获取策略的AI决策缓存配置（含默认值）
+- (c *Config) GetVetoConfig(strategy string) VetoConfig              // 获取策略的AI决策风控否决规则（含默认值）
+- (c *Config) GetRiskReportConfig() RiskReportConfig                 // 获取组合风险报告配置（含默认值）
+- (c *Config) GetCalibrationConfig() CalibrationConfig               // 获取置信度校准报告配置（含默认值）
+- (c *Config) GetTelemetryConfig() TelemetryConfig                   // 获取指标计算耗时统计配置（含默认值）
+- (c *Config) GetMarketDataConfig() MarketDataConfig                 // 获取共享行情数据服务配置（含默认值）
+- (c *Config) GetSymbolBreakerConfig() SymbolBreakerConfig           // 获取交易对错误熔断配置（含默认值）
+- (c *Config) GetStreamsConfig() StreamsConfig                       // 获取WebSocket行情推送配置（含默认值）
+- (c *Config) GetPositionConfig(acc *Account) PositionConfig        // 获取账号的持仓设置（账号配置覆盖全局默认）
+- (c *Config) GetPyramidingConfig(strategy string) PyramidingConfig  // 获取策略的加仓规则（含默认值）
+- (c *Config) GetReentryConfig(strategy string) ReentryConfig        // 获取策略的止损后重新入场规则（含默认值）
+- (c *Config) GetStalenessConfig(strategy string) StalenessConfig    // 获取策略的决策过期规则（含默认值）
+- (s StalenessConfig) TTL(interval time.Duration) time.Duration      // 按策略运行周期计算决策有效期
+- (c *Config) GetFreshnessConfig(strategy string) FreshnessConfig    // 获取策略的输入数据过期检查（含默认值）
+- (c *Config) GetCandlesConfig(strategy string) CandlesConfig        // 获取策略的K线使用方式
+- (c *Config) GetDeltaConfig(strategy string) DeltaConfig            // 获取策略的指标变化输出规则（含默认值）
+- (c *Config) GetCooldownConfig(strategy string) CooldownConfig      // 获取策略的交易对决策冷却规则
+- (c *Config) GetMaxHoldingConfig(strategy string) MaxHoldingConfig  // 获取策略的最长持仓时间规则（含默认值）
+- (l SymbolLimitsConfig) ForSymbol(symbol string) (string, SymbolTier)  // 获取交易对所属的分级
 */
 package config
 
@@ -15,17 +46,453 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config 全局配置结构
 type Config struct {
-	Proxy          ProxyConfig       `yaml:"proxy"`
-	Binance        BinanceConfig     `yaml:"binance"`
-	SymbolPool     SymbolPoolConfig  `yaml:"symbol_pool"`
-	AccountsConfig string            `yaml:"accounts_config"`
-	Accounts       []Account         `yaml:"-"` // 从单独文件加载
+	Proxy          ProxyConfig      `yaml:"proxy"`
+	Binance        BinanceConfig    `yaml:"binance"`
+	OKX            OKXConfig        `yaml:"okx"`
+	SymbolPool     SymbolPoolConfig `yaml:"symbol_pool"`
+	AccountsConfig string           `yaml:"accounts_config"`
+	Accounts       []Account        `yaml:"-"`              // 从单独文件加载
+	SectorsConfig  string           `yaml:"sectors_config"` // 板块配置文件（可选）
+	Sectors        *SectorsConfig   `yaml:"-"`              // 从单独文件加载（未配置时为nil，不限制板块敞口）
+	Timezone       string           `yaml:"timezone"`       // 时区（IANA名称，如 Asia/Shanghai，默认UTC）
+
+	Execution     map[string]ExecutionConfig     `yaml:"execution"`      // 执行配置（按策略名称）
+	Pyramiding    map[string]PyramidingConfig    `yaml:"pyramiding"`     // 盈利加仓规则（按策略名称，未配置的策略不加仓）
+	Reentry       map[string]ReentryConfig       `yaml:"reentry"`        // 止损后重新入场规则（按策略名称，未配置的策略不限制）
+	Staleness     map[string]StalenessConfig     `yaml:"staleness"`      // 决策过期规则（按策略名称，未配置的策略不检查）
+	Freshness     map[string]FreshnessConfig     `yaml:"freshness"`      // 输入数据过期检查（按策略名称，未配置的策略不检查）
+	Candles       map[string]CandlesConfig       `yaml:"candles"`        // K线使用方式（按策略名称，未配置的策略只用已收盘K线计算指标）
+	Delta         map[string]DeltaConfig         `yaml:"delta"`          // 指标变化输出（按策略名称，未配置的策略使用默认阈值、不写日志）
+	Cooldown      map[string]CooldownConfig      `yaml:"cooldown"`       // 交易对决策冷却（按策略名称，未配置的策略不冷却）
+	MaxHolding    map[string]MaxHoldingConfig    `yaml:"max_holding"`    // 最长持仓时间（按策略名称，未配置的策略不限制）
+	Ranking       map[string]RankingConfig       `yaml:"ranking"`        // 多交易对排名模式（按策略名称，未配置的策略逐个分析）
+	TwoStage      map[string]TwoStageConfig      `yaml:"two_stage"`      // 两阶段分析（按策略名称，未配置的策略单次调用直接输出决策）
+	Voting        map[string]VotingConfig        `yaml:"voting"`         // 多次采样投票（按策略名称，未配置的策略只采样一次）
+	Tools         map[string]ToolsConfig         `yaml:"tools"`          // AI工具调用（按策略名称，未配置的策略不提供工具）
+	DecisionCache map[string]DecisionCacheConfig `yaml:"decision_cache"` // AI决策缓存（按策略名称，未配置的策略每次都调用AI）
+	Veto          map[string]VetoConfig          `yaml:"veto"`           // AI决策风控否决规则（按策略名称，未配置的策略不检查）
+	Journal       JournalConfig                  `yaml:"journal"`        // 交易日志配置
+	Position      PositionConfig                 `yaml:"position"`       // 持仓设置默认值（启动时检查）
+
+	SymbolLimits SymbolLimitsConfig `yaml:"symbol_limits"` // 按交易对分级的杠杆和名义价值上限
+	API          APIConfig          `yaml:"api"`           // 状态API
+	Telegram     TelegramConfig     `yaml:"telegram"`      // Telegram机器人命令
+	Secrets      SecretsConfig      `yaml:"secrets"`       // 密钥来源（环境变量、密钥文件、Vault）
+	Prompts      PromptsConfig      `yaml:"prompts"`       // 提示词模板
+	AI           AIConfig           `yaml:"ai"`            // AI模型（OpenAI兼容接口）
+	RiskReport   RiskReportConfig   `yaml:"risk_report"`   // 组合风险报告
+	Calibration  CalibrationConfig  `yaml:"calibration"`   // AI置信度校准报告
+	Telemetry    TelemetryConfig    `yaml:"telemetry"`     // 指标计算耗时统计
+	MarketData   MarketDataConfig   `yaml:"market_data"`   // 共享行情数据服务
+	Streams      StreamsConfig      `yaml:"streams"`       // WebSocket行情推送
+	Alerts       AlertsConfig       `yaml:"alerts"`        // 行情告警
+	Screener     ScreenerConfig     `yaml:"screener"`      // 机会筛选（交易对池之外的候选）
+
+	ExchangeStatus StatusConfig `yaml:"exchange_status"` // 交易所系统状态与维护检测
+
+	SymbolBreaker SymbolBreakerConfig `yaml:"symbol_breaker"` // 交易对错误熔断
+
+	KeyCheck KeyCheckConfig `yaml:"key_check"` // 启动时检查币安API密钥权限
+}
+
+// APIConfig 状态API配置
+type APIConfig struct {
+	Enabled bool   `yaml:"enabled"` // 是否启用
+	Listen  string `yaml:"listen"`  // 监听地址（默认 127.0.0.1:8080）
+	Token   string `yaml:"token"`   // 访问令牌（请求头 Authorization: Bearer <token>，为空时只注册GET接口）
+}
+
+// TelegramConfig Telegram机器人配置（长轮询接收命令，只响应允许的聊天）
+type TelegramConfig struct {
+	Enabled  bool    `yaml:"enabled"`   // 是否启用
+	BaseURL  string  `yaml:"base_url"`  // 接口地址（默认 https://api.telegram.org）
+	BotToken string  `yaml:"bot_token"` // 机器人令牌（留空时读取环境变量 TELEGRAM_BOT_TOKEN）
+	ChatIDs  []int64 `yaml:"chat_ids"`  // 允许发送命令的聊天ID（其他聊天的消息忽略）
+	PollSec  int     `yaml:"poll_sec"`  // 长轮询等待时间（秒，默认30）
+}
+
+// SecretsConfig 密钥来源（账号配置中的 api_key、api_secret、passphrase 可以写成 env:名称、file:名称 或 vault:路径#字段）
+type SecretsConfig struct {
+	File  string      `yaml:"file"`  // 密钥文件（YAML，名称: 值），file: 引用从该文件读取
+	Vault VaultConfig `yaml:"vault"` // HashiCorp Vault（KV v2），vault: 引用从Vault读取
+}
+
+// VaultConfig HashiCorp Vault 配置
+type VaultConfig struct {
+	Address    string `yaml:"address"`     // 地址（如 https://vault.example.com:8200，留空时读取环境变量 VAULT_ADDR）
+	Token      string `yaml:"token"`       // 访问令牌（留空时读取环境变量 VAULT_TOKEN）
+	Namespace  string `yaml:"namespace"`   // 命名空间（企业版，可选）
+	Mount      string `yaml:"mount"`       // KV v2 挂载路径（默认 secret）
+	TimeoutSec int    `yaml:"timeout_sec"` // 请求超时（秒，默认10）
+}
+
+// PromptsConfig 提示词模板配置
+type PromptsConfig struct {
+	Dir       string             `yaml:"dir"`        // 模板目录（默认 configs/prompts）
+	ReloadSec int                `yaml:"reload_sec"` // 检查模板文件变化的间隔（秒，默认5）
+	Budget    PromptBudgetConfig `yaml:"budget"`     // 提示词token预算
+}
+
+// AIConfig AI模型配置（OpenAI兼容的 /chat/completions 接口）
+type AIConfig struct {
+	Enabled        bool    `yaml:"enabled"`          // 是否启用（未启用时只生成提示词，不调用AI）
+	BaseURL        string  `yaml:"base_url"`         // 接口地址（默认 https://api.openai.com/v1）
+	APIKey         string  `yaml:"api_key"`          // API密钥（留空时读取环境变量 AI_API_KEY）
+	Model          string  `yaml:"model"`            // 模型名称
+	TimeoutSec     int     `yaml:"timeout_sec"`      // 每次AI对话的截止时间（秒，含工具调用的多轮请求，默认60）
+	Stream         bool    `yaml:"stream"`           // 是否使用流式回复（SSE，可检测接口停顿）
+	IdleTimeoutSec int     `yaml:"idle_timeout_sec"` // 流式回复停顿超时（秒，超过该时间没有收到数据即中断，默认20）
+	MaxTokens      int     `yaml:"max_tokens"`       // 回复最大token数（0表示由接口决定）
+	Temperature    float64 `yaml:"temperature"`      // 采样温度
+	AuditDir       string  `yaml:"audit_dir"`        // AI决策审计记录目录（默认 data/audit）
+}
+
+// RankingConfig 多交易对排名模式
+// 每个周期先把所有交易对的关键指标放在一张表里请AI挑选 top_n 个候选，只对候选做逐个详细分析
+type RankingConfig struct {
+	Enabled  bool   `yaml:"enabled"`  // 是否启用
+	TopN     int    `yaml:"top_n"`    // 挑选的候选数量（默认3）
+	Template string `yaml:"template"` // 排名提示词模板名称（默认 ranking）
+}
+
+// TwoStageConfig 两阶段分析（分析 → 决策）
+// 第一次调用只根据指标数据输出市场分析，第二次调用结合分析结论和账户状态输出交易决策
+type TwoStageConfig struct {
+	Enabled          bool   `yaml:"enabled"`           // 是否启用
+	AnalysisTemplate string `yaml:"analysis_template"` // 分析阶段提示词模板（默认 analysis）
+	DecisionTemplate string `yaml:"decision_template"` // 决策阶段提示词模板（默认 decision）
+}
+
+// VotingConfig 多次采样投票（对输出决策的调用独立采样多次，多数动作一致时才执行）
+type VotingConfig struct {
+	Enabled     bool    `yaml:"enabled"`     // 是否启用
+	Samples     int     `yaml:"samples"`     // 采样次数（默认3）
+	MinAgree    int     `yaml:"min_agree"`   // 采用决策需要的最少同意票数（默认过半数）
+	Temperature float64 `yaml:"temperature"` // 采样温度（默认0.7，需大于0才能得到独立的回复）
+}
+
+// ToolsConfig AI工具调用（AI在给出决策前可请求K线、订单簿深度、资金费率历史等额外数据）
+type ToolsConfig struct {
+	Enabled  bool `yaml:"enabled"`   // 是否启用（只支持币安账号）
+	MaxCalls int  `yaml:"max_calls"` // 每次调用AI时最多执行的工具调用次数（默认5）
+}
+
+// DecisionCacheConfig AI决策缓存（交易对的指标数据按有效数字取整后与上次相同时，复用上次决策，不再调用AI）
+type DecisionCacheConfig struct {
+	Enabled           bool `yaml:"enabled"`            // 是否启用
+	MaxAgeMinutes     int  `yaml:"max_age_minutes"`    // 上次决策最多复用多长时间（分钟，默认15）
+	SignificantDigits int  `yaml:"significant_digits"` // 比较时数值保留的有效数字位数（默认4）
+}
+
+// VetoConfig AI决策风控否决规则（开仓决策违反硬性约束时否决或缩减仓位，平仓和观望不检查）
+type VetoConfig struct {
+	Enabled        bool             `yaml:"enabled"`         // 是否启用
+	MinADX         float64          `yaml:"min_adx"`         // 主分析周期ADX低于此值时触发（0表示不检查，指标数据没有ADX时跳过）
+	Funding        bool             `yaml:"funding"`         // 资金费率不利于开仓方向时触发（按 indicators.ShouldTradeBasedOnFunding）
+	MaxSpreadBps   float64          `yaml:"max_spread_bps"`  // 买卖价差超过此值时触发（基点，0表示不检查，只支持币安账号）
+	Blackouts      []BlackoutWindow `yaml:"blackouts"`       // 禁止开仓的时间段（如重大数据或新闻发布前后）
+	Downsize       []string         `yaml:"downsize"`        // 触发时缩减仓位而不是否决的规则：adx / funding / spread / blackout
+	DownsizeFactor float64          `yaml:"downsize_factor"` // 缩减仓位的数量系数（默认0.5，多条规则触发时相乘）
+}
+
+// BlackoutWindow 禁止开仓的时间段
+type BlackoutWindow struct {
+	Start  time.Time `yaml:"start"`  // 开始时间（RFC3339，如 2026-03-18T17:45:00Z）
+	End    time.Time `yaml:"end"`    // 结束时间
+	Reason string    `yaml:"reason"` // 原因（如 FOMC）
+}
+
+// PromptBudgetConfig 提示词token预算（超出时依次去掉可选字段、数值取整、去掉时间周期）
+type PromptBudgetConfig struct {
+	MaxTokens         int      `yaml:"max_tokens"`         // 估算token数上限（0表示不限制）
+	OptionalFields    []string `yaml:"optional_fields"`    // 可去掉的可选字段（JSON字段名，默认为预留扩展指标、OI历史和强平统计）
+	SignificantDigits int      `yaml:"significant_digits"` // 数值保留的有效数字位数（默认6）
+	DropTimeframes    []string `yaml:"drop_timeframes"`    // 按顺序可去掉的时间周期（JSON字段名，如 5m，默认不去掉）
+}
+
+// RiskReportConfig 组合风险报告配置（API随时查询，启用后另按间隔定时生成并保存）
+type RiskReportConfig struct {
+	Enabled         bool   `yaml:"enabled"`          // 是否定时生成
+	IntervalMinutes int    `yaml:"interval_minutes"` // 生成间隔（分钟，默认60）
+	Dir             string `yaml:"dir"`              // 报告保存目录（默认 data/reports）
+	Timeframe       string `yaml:"timeframe"`        // 计算波动率和相关性的K线周期（默认1h）
+	Lookback        int    `yaml:"lookback"`         // 回看K线数量（默认168，至少20）
+	HorizonBars     int    `yaml:"horizon_bars"`     // 风险价值的持有期（K线数量，默认24）
+}
+
+// CalibrationConfig AI置信度校准报告配置（API随时查询，启用后另按间隔定时生成并保存）
+type CalibrationConfig struct {
+	Enabled         bool   `yaml:"enabled"`          // 是否定时生成
+	IntervalMinutes int    `yaml:"interval_minutes"` // 生成间隔（分钟，默认360）
+	Bins            int    `yaml:"bins"`             // 置信度分段数（默认10）
+	Dir             string `yaml:"dir"`              // 报告保存目录（默认 data/reports）
+}
+
+// TelemetryConfig 指标计算耗时统计配置（统计始终进行，API随时查询，启用后另按间隔输出日志）
+type TelemetryConfig struct {
+	Enabled         bool           `yaml:"enabled"`          // 是否定时输出耗时统计日志
+	IntervalMinutes int            `yaml:"interval_minutes"` // 输出间隔（分钟，默认60）
+	CycleBudgetMs   map[string]int `yaml:"cycle_budget_ms"`  // 每个周期指标计算耗时合计的上限（毫秒，按策略名称，未配置的策略不检查）
+}
+
+// MarketDataConfig 共享行情数据服务配置（同一交易所、市场类型的账号共用K线、持仓量、资金费率）
+type MarketDataConfig struct {
+	TTLSec int `yaml:"ttl_sec"` // 缓存有效期（秒，默认30；K线另外在当前K线收盘时失效）
+}
+
+// StatusConfig 交易所系统状态与维护检测（只支持币安）
+type StatusConfig struct {
+	Enabled     bool `yaml:"enabled"`
+	IntervalSec int  `yaml:"interval_sec"` // 检查间隔（秒，默认60）
+}
+
+// SymbolBreakerConfig 交易对错误熔断（连续获取K线、计算指标、获取持仓量失败的交易对暂时跳过，到期后探测恢复）
+type SymbolBreakerConfig struct {
+	Enabled       bool `yaml:"enabled"`
+	MaxFailures   int  `yaml:"max_failures"`    // 连续失败多少个周期后熔断（默认3）
+	BackoffSec    int  `yaml:"backoff_sec"`     // 熔断后跳过的时间（秒，默认300），探测失败时翻倍
+	MaxBackoffSec int  `yaml:"max_backoff_sec"` // 跳过时间上限（秒，默认3600）
+}
+
+// KeyCheckConfig 启动时检查币安API密钥权限（通过现货域名查询，需要配置 binance.spot_url）
+type KeyCheckConfig struct {
+	Enabled           bool `yaml:"enabled"`             // 是否启用（有账号不通过时输出每个账号的报告并退出）
+	RequireIPRestrict bool `yaml:"require_ip_restrict"` // 要求密钥开启IP白名单限制
+	AllowWithdrawals  bool `yaml:"allow_withdrawals"`   // 允许密钥开启提现权限（默认不允许）
+}
+
+// AlertsConfig 行情告警配置（告警写入日志，配置webhook时同时推送）
+type AlertsConfig struct {
+	Enabled         bool          `yaml:"enabled"`
+	WebhookURL      string        `yaml:"webhook_url"`      // 告警以JSON POST到该地址（为空时只写日志）
+	CooldownMinutes int           `yaml:"cooldown_minutes"` // 同一交易对同一类告警的最短间隔（分钟，默认30）
+	History         int           `yaml:"history"`          // 内存中保留的最近告警数（默认200）
+	Anomaly         AnomalyConfig `yaml:"anomaly"`          // 价格、持仓量异动检测
+	FundingFlip     bool          `yaml:"funding_flip"`     // 资金费率翻转时告警（交易对池中的所有交易对）
+	OIPrice         bool          `yaml:"oi_price"`         // 持仓中的交易对持仓量与价格关系改变时告警（如真实多头趋势 → 空头平仓推动）
+	Premium         PremiumConfig `yaml:"premium"`          // 标记价格偏离指数价格（溢价）告警
+}
+
+// PremiumConfig 标记价格偏离指数价格（溢价）告警（只支持币安合约，每个策略周期检查交易对池中的所有交易对）
+type PremiumConfig struct {
+	Enabled      bool    `yaml:"enabled"`
+	MaxPct       float64 `yaml:"max_pct"`       // 标记价格偏离指数价格的绝对值超过该百分比时告警（默认0.5）
+	PauseEntries bool    `yaml:"pause_entries"` // 偏离期间暂停该交易对开仓（平仓不受影响）
+}
+
+// AnomalyConfig 价格、持仓量异动检测（每个策略周期检查交易对池中的所有交易对，不要求有持仓）
+type AnomalyConfig struct {
+	Enabled         bool    `yaml:"enabled"`
+	ATRMultiple     float64 `yaml:"atr_multiple"`      // 主分析周期当前K线涨跌幅度超过该倍数的ATR时告警（默认3）
+	OIJumpPct       float64 `yaml:"oi_jump_pct"`       // 持仓量在窗口内变化超过该百分比时告警（默认5）
+	OIWindowMinutes int     `yaml:"oi_window_minutes"` // 持仓量变化的统计窗口（分钟，默认5）
+}
+
+// ScreenerConfig 机会筛选配置（在交易对池之外寻找临时候选，只支持币安U本位合约）
+type ScreenerConfig struct {
+	Volume   VolumeScreenerConfig   `yaml:"volume"`    // 成交量异动筛选
+	PumpDump PumpDumpScreenerConfig `yaml:"pump_dump"` // 拉盘/砸盘筛选（交易对池内）
+}
+
+// VolumeScreenerConfig 成交量异动筛选（当前K线成交量超过前N根平均的倍数）
+type VolumeScreenerConfig struct {
+	Enabled         bool     `yaml:"enabled"`
+	Interval        string   `yaml:"interval"`          // K线周期（默认5m）
+	Lookback        int      `yaml:"lookback"`          // 计算平均成交量的已收盘K线数（默认20）
+	Multiple        float64  `yaml:"multiple"`          // 当前K线成交量超过平均的倍数（默认3）
+	Universe        int      `yaml:"universe"`          // 按24小时成交额取前N个USDT永续合约扫描（默认50）
+	MinQuoteVolume  float64  `yaml:"min_quote_volume"`  // 24小时成交额下限（USDT，默认20000000）
+	MaxCandidates   int      `yaml:"max_candidates"`    // 每个策略周期最多加入的候选数（按倍数排序，默认3）
+	ScanIntervalSec int      `yaml:"scan_interval_sec"` // 扫描间隔（秒，默认60）
+	Strategies      []string `yaml:"strategies"`        // 使用候选的策略（默认 [short_term]）
+}
+
+// PumpDumpScreenerConfig 拉盘/砸盘筛选（每个策略周期筛选交易对池中短时间大幅涨跌且持仓量扩张的交易对）
+type PumpDumpScreenerConfig struct {
+	Enabled        bool    `yaml:"enabled"`
+	MinReturnPct   float64 `yaml:"min_return_pct"`    // 主分析周期当前K线涨跌幅绝对值下限（%，默认3）
+	MinOIChangePct float64 `yaml:"min_oi_change_pct"` // 持仓量变化率下限（%，默认2）
+	TopN           int     `yaml:"top_n"`             // 报告保留前N个（默认10）
+	Notify         bool    `yaml:"notify"`            // 通过行情告警发出 pump / dump 告警
+	WebhookURL     string  `yaml:"webhook_url"`       // notify 使用的单独推送地址（为空时使用行情告警）
+}
+
+// StreamsConfig WebSocket行情推送配置（只支持币安合约）
+type StreamsConfig struct {
+	FuturesURL  string                `yaml:"futures_url"`  // U本位合约推送地址（默认 wss://fstream.binance.com）
+	DeliveryURL string                `yaml:"delivery_url"` // 币本位合约推送地址（默认 wss://dstream.binance.com）
+	MarkPrice   MarkPriceStreamConfig `yaml:"mark_price"`   // 全市场标记价格推送
+	Depth       DepthStreamConfig     `yaml:"depth"`        // 持仓交易对的增量深度推送
+	Liquidation LiquidationConfig     `yaml:"liquidation"`  // 全市场强平订单推送（只支持U本位合约）
+	Indicators  LiveIndicatorsConfig  `yaml:"indicators"`   // 秒级增量指标（需要启用标记价格推送）
+}
+
+// MarkPriceStreamConfig 全市场标记价格推送配置（!markPrice@arr，风控监控和执行器优先使用推送的标记价格）
+type MarkPriceStreamConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	MaxAgeSec int  `yaml:"max_age_sec"` // 推送价格超过该时间未更新视为失效，改用REST接口（秒，默认5）
+}
+
+// LiveIndicatorsConfig 秒级增量指标配置（用标记价格推送生成的1分钟K线逐根更新EMA/RSI/ATR，供风控监控秒级读取）
+type LiveIndicatorsConfig struct {
+	Enabled     bool  `yaml:"enabled"`
+	EMAPeriods  []int `yaml:"ema_periods"`  // EMA周期（默认 [9, 21]）
+	RSIPeriod   int   `yaml:"rsi_period"`   // RSI周期（默认14）
+	ATRPeriod   int   `yaml:"atr_period"`   // ATR周期（默认14）
+	IntervalSec int   `yaml:"interval_sec"` // 检查新收盘K线的间隔（秒，默认1）
+}
+
+// DepthStreamConfig 增量深度推送配置（为入场中和持仓中的交易对维护本地订单簿，供滑点预估和Maker优先入场使用）
+type DepthStreamConfig struct {
+	Enabled                 bool `yaml:"enabled"`
+	MaxStreamsPerConnection int  `yaml:"max_streams_per_connection"` // 组合推送单连接最多交易对数，超过时自动增加连接（默认200，币安合约上限）
+}
+
+// LiquidationConfig 全市场强平订单推送配置（!forceOrder@arr，强平统计附加到市场数据，连环强平时可暂停开仓）
+type LiquidationConfig struct {
+	Enabled        bool                     `yaml:"enabled"`
+	WindowsMinutes []int                    `yaml:"windows_minutes"` // 强平统计的滚动窗口（分钟，默认 [5, 60]）
+	Cascade        LiquidationCascadeConfig `yaml:"cascade"`         // 连环强平判定
+}
+
+// LiquidationCascadeConfig 连环强平判定（窗口内强平名义价值达到任一阈值即视为连环强平，阈值为0表示不按该项判定）
+type LiquidationCascadeConfig struct {
+	WindowMinutes      int     `yaml:"window_minutes"`       // 统计窗口（分钟，默认5）
+	SymbolNotionalUSDT float64 `yaml:"symbol_notional_usdt"` // 单个交易对的阈值
+	MarketNotionalUSDT float64 `yaml:"market_notional_usdt"` // 全市场的阈值
+	PauseEntries       bool    `yaml:"pause_entries"`        // 连环强平期间拒绝开仓（交易对自身或全市场）
+}
+
+// SymbolLimitsConfig 交易对分级上限（如主流币与小市值币），未列入任何分级的交易对使用default
+type SymbolLimitsConfig struct {
+	Tiers   map[string]SymbolTier `yaml:"tiers"`   // 分级名称 -> 分级
+	Default SymbolTier            `yaml:"default"` // 其他交易对的上限
+}
+
+// SymbolTier 交易对分级
+type SymbolTier struct {
+	Symbols         []string `yaml:"symbols"`           // 属于该分级的交易对
+	MaxLeverage     int      `yaml:"max_leverage"`      // 最大杠杆（0表示不限）
+	MaxNotionalUSDT float64  `yaml:"max_notional_usdt"` // 单个交易对持仓名义价值上限（USDT，0表示不限）
+}
+
+// PyramidingConfig 盈利加仓规则（已有同方向括号订单时，开仓决策按此加仓）
+type PyramidingConfig struct {
+	Enabled       bool    `yaml:"enabled"`        // 是否启用
+	MaxAdds       int     `yaml:"max_adds"`       // 最多加仓次数（默认2）
+	MinMovePct    float64 `yaml:"min_move_pct"`   // 价格较上次入场价至少朝有利方向移动的百分比（默认1）
+	SizeRatio     float64 `yaml:"size_ratio"`     // 每次加仓数量为上一笔的比例（0~1，默认0.5）
+	BreakevenStop bool    `yaml:"breakeven_stop"` // 加仓后止损至少移到新的开仓均价（保本）
+}
+
+// ReentryConfig 止损后重新入场规则
+// 止损后 window_minutes 内同方向的开仓决策只允许作为一次重新入场：
+// 价格重新站回原入场价（做空为跌回），且共振评分不低于 min_score。重新入场的仓位再次止损后，窗口内不再同方向入场
+type ReentryConfig struct {
+	Enabled       bool    `yaml:"enabled"`        // 是否启用
+	WindowMinutes int     `yaml:"window_minutes"` // 止损后的观察窗口（分钟，默认60）
+	Timeframe     string  `yaml:"timeframe"`      // 计算共振评分的K线周期（默认15m）
+	MinScore      float64 `yaml:"min_score"`      // 最低共振评分（0-100，默认60）
+}
+
+// StalenessConfig 决策过期规则
+// 决策有效期 = 策略运行周期 × ttl_cycles（从决策时间起算）。开仓决策执行时已过期，
+// 或当前价格偏离决策分析时的收盘价超过 max_price_move_pct 时拒绝执行
+type StalenessConfig struct {
+	Enabled         bool    `yaml:"enabled"`            // 是否启用
+	TTLCycles       float64 `yaml:"ttl_cycles"`         // 决策有效期为策略运行周期的倍数（默认1）
+	MaxPriceMovePct float64 `yaml:"max_price_move_pct"` // 当前价格偏离分析时收盘价的最大百分比（默认1）
+}
+
+// TTL 按策略运行周期计算决策有效期
+func (s StalenessConfig) TTL(interval time.Duration) time.Duration {
+	return time.Duration(float64(interval) * s.TTLCycles)
+}
+
+// FreshnessConfig 输入数据过期检查
+// 调用AI前和执行决策前检查信号的K线、持仓量、资金费率的获取时间，任一项超过 max_age_sec 时
+// 该交易对本周期不调用AI、不执行决策（如重试导致排在后面的交易对等待过久）
+type FreshnessConfig struct {
+	Enabled   bool `yaml:"enabled"`     // 是否启用
+	MaxAgeSec int  `yaml:"max_age_sec"` // 输入数据的最长有效时间（秒，默认120）
+}
+
+// CandlesConfig K线使用方式
+// 默认去掉各周期未收盘的K线再计算指标（同一根K线收盘前指标不随价格跳动），
+// 指标数据中 close_price 为最近一根已收盘K线的收盘价，live_price 为未收盘K线的最新价
+type CandlesConfig struct {
+	IncludeForming bool `yaml:"include_forming"` // 是否包含未收盘的K线计算指标（默认false）
+}
+
+// DeltaConfig 指标变化输出规则（只输出与上次输出相比变化明显的字段）
+// 账号 payload_format: delta 时提示词中的指标数据只列出变化明显的字段（每 full_every 次发送一次完整数据）；
+// log 为true时每个周期把各交易对变化明显的字段写入日志（与账号的指标数据格式无关）
+type DeltaConfig struct {
+	Log        bool               `yaml:"log"`         // 是否把指标变化写入日志
+	DefaultPct float64            `yaml:"default_pct"` // 未配置阈值的数值字段相对变化超过该百分比时输出（默认0.5）
+	Thresholds map[string]float64 `yaml:"thresholds"`  // 按字段的绝对变化阈值（字段名如 rsi，或路径如 timeframes.15m.rsi，路径优先）
+	Ignore     []string           `yaml:"ignore"`      // 不比较的字段（字段名或路径，默认 timestamp）
+	FullEvery  int                `yaml:"full_every"`  // 每隔多少次输出一次完整数据（含第一次，默认12）
+}
+
+// CooldownConfig 交易对决策冷却规则
+// 交易对入场、加仓或出场后 minutes 分钟内，策略周期不再为它生成信号（不调用AI）
+type CooldownConfig struct {
+	Minutes int `yaml:"minutes"` // 冷却时间（分钟，0表示不冷却）
+}
+
+// MaxHoldingConfig 最长持仓时间规则
+// 持仓时间超过上限时按 action 处理：close 直接市价平仓；review 每个周期都为该交易对调用一次AI做出场评估
+// （即使在冷却期内或未被排名选中），提示词中标记持仓已超时，由AI决定继续持有或平仓
+type MaxHoldingConfig struct {
+	Enabled bool   `yaml:"enabled"` // 是否启用
+	Minutes int    `yaml:"minutes"` // 最长持仓时间（分钟，0表示使用策略预期持仓时间的上限，如短线90分钟）
+	Action  string `yaml:"action"`  // 超时处理：close（平仓，默认）或 review（AI出场评估）
+}
+
+// 持仓超时的处理方式
+const (
+	MaxHoldingClose  = "close"
+	MaxHoldingReview = "review"
+)
+
+// PositionConfig 持仓设置（启动时按此检查每个交易对，持仓模式固定要求单向持仓）
+type PositionConfig struct {
+	Leverage   int    `yaml:"leverage"`    // 杠杆倍数（0表示不检查）
+	MarginType string `yaml:"margin_type"` // 保证金模式：CROSSED（全仓）或 ISOLATED（逐仓），为空表示不检查
+	OnMismatch string `yaml:"on_mismatch"` // 与交易所设置不一致时：fix（自动修正，默认）、abort（输出报告并退出）或 warn（只记录警告）
+
+	MarginTopUp MarginTopUpConfig `yaml:"margin_top_up"` // 逐仓持仓自动追加保证金
+}
+
+// MarginTopUpConfig 逐仓自动追加保证金（标记价格与强平价格的距离低于下限时追加，仅U本位合约）
+type MarginTopUpConfig struct {
+	Enabled              bool    `yaml:"enabled"`                 // 是否启用
+	MinLiqDistancePct    float64 `yaml:"min_liq_distance_pct"`    // 强平距离下限（%，如5表示标记价格距强平价格不足5%时追加）
+	TargetLiqDistancePct float64 `yaml:"target_liq_distance_pct"` // 追加后的目标强平距离（%，默认为下限的2倍）
+	MaxAddUSDT           float64 `yaml:"max_add_usdt"`            // 单次最多追加（USDT，0表示不限）
+	MaxTotalUSDT         float64 `yaml:"max_total_usdt"`          // 每个持仓累计最多追加（USDT，0表示不限）
+}
+
+// JournalConfig 交易日志配置
+type JournalConfig struct {
+	Dir       string          `yaml:"dir"`       // 日志目录（默认 data/journal）
+	Reconcile ReconcileConfig `yaml:"reconcile"` // 与交易所资金流水对账
+}
+
+// ReconcileConfig 对账配置
+type ReconcileConfig struct {
+	Enabled         bool    `yaml:"enabled"`          // 是否启用定时对账
+	IntervalMinutes int     `yaml:"interval_minutes"` // 对账间隔（分钟，默认60）
+	WindowHours     int     `yaml:"window_hours"`     // 每次对账的时间范围（小时，默认24）
+	ToleranceUSDT   float64 `yaml:"tolerance_usdt"`   // 每项允许的差异（USDT，默认0.01）
 }
 
 // ProxyConfig 代理配置
@@ -37,7 +504,16 @@ type ProxyConfig struct {
 
 // BinanceConfig 币安API配置
 type BinanceConfig struct {
-	FuturesURL string `yaml:"futures_url"`
+	FuturesURL  string `yaml:"futures_url"`
+	DeliveryURL string `yaml:"delivery_url"` // 币本位合约URL（market_type为coin_m的账号使用）
+	SpotURL     string `yaml:"spot_url"`     // 现货URL（market_type为spot的账号使用）
+	RecvWindow  int64  `yaml:"recv_window"`  // 签名请求的recvWindow（毫秒，0表示使用交易所默认值5000，最大60000）
+}
+
+// OKXConfig OKX API配置（exchange为okx的账号使用）
+type OKXConfig struct {
+	BaseURL   string `yaml:"base_url"`  // API地址（如 https://www.okx.com）
+	Simulated bool   `yaml:"simulated"` // 是否使用模拟盘
 }
 
 // SymbolPoolConfig 交易对池配置
@@ -49,11 +525,72 @@ type SymbolPoolConfig struct {
 
 // ExternalSymbolsConfig 外部交易对配置
 type ExternalSymbolsConfig struct {
-	IsUse    bool    `yaml:"is_use"`     // 是否使用外部API
-	URL      string  `yaml:"url"`        // 外部API地址
-	MinScore float64 `yaml:"min_score"`  // 最低评分要求（默认75）
+	IsUse    bool    `yaml:"is_use"`    // 是否使用外部API
+	URL      string  `yaml:"url"`       // 外部API地址
+	MinScore float64 `yaml:"min_score"` // 最低评分要求（默认75）
+}
+
+// ExecutionConfig 执行配置（入场方式）
+type ExecutionConfig struct {
+	EntryType       string `yaml:"entry_type"`        // 入场方式：market（市价）、limit（限价）或 maker_first（只做Maker，多次改价）
+	TimeInForce     string `yaml:"time_in_force"`     // 限价单有效方式：GTC / IOC / FOK / GTX（只做Maker）
+	LimitTimeoutSec int    `yaml:"limit_timeout_sec"` // 限价单等待成交超时（秒）
+	Fallback        string `yaml:"fallback"`          // 超时未成交部分：market（市价补齐）或 none（放弃）
+
+	// maker_first 专用
+	MaxReprices      int `yaml:"max_reprices"`       // 最多重新挂单次数（每次等待 limit_timeout_sec 秒）
+	PriceOffsetTicks int `yaml:"price_offset_ticks"` // 挂单价格向价差内移动的tick数（0表示挂在买一/卖一）
+	// Maker与Taker费率差（基点）低于此值时不值得挂单等待，直接市价入场（0表示始终挂单）
+	MakerFirstMinSavingBps float64 `yaml:"maker_first_min_saving_bps"`
+
+	MaxSlippageBps float64 `yaml:"max_slippage_bps"` // 按盘口深度预估的最大允许滑点（基点），0表示不检查
+	SlippageAction string  `yaml:"slippage_action"`  // 超过滑点上限时：downsize（缩减数量）或 skip（放弃交易）
+
+	TWAP TWAPConfig `yaml:"twap"` // 大额订单拆单配置
+}
+
+// TWAPConfig 大额订单按时间拆单配置（名义价值超过阈值时拆成多笔，每笔按entry_type执行）
+type TWAPConfig struct {
+	ThresholdUSDT float64 `yaml:"threshold_usdt"` // 触发拆单的名义价值阈值（USDT），0表示不拆单
+	Slices        int     `yaml:"slices"`         // 拆单笔数
+	IntervalSec   int     `yaml:"interval_sec"`   // 每笔之间的间隔（秒）
+	SizeJitter    float64 `yaml:"size_jitter"`    // 每笔数量的随机浮动比例（0~0.9，如0.3表示±30%）
 }
 
+// 入场方式
+const (
+	EntryTypeMarket     = "market"
+	EntryTypeLimit      = "limit"
+	EntryTypeMakerFirst = "maker_first" // 只做Maker限价单，多次重新挂单后再市价补齐
+)
+
+// 预估滑点超限时的处理方式
+const (
+	SlippageActionDownsize = "downsize"
+	SlippageActionSkip     = "skip"
+)
+
+// 风控否决规则
+const (
+	VetoRuleADX      = "adx"      // 主分析周期ADX过低（没有趋势）
+	VetoRuleFunding  = "funding"  // 资金费率不利于开仓方向
+	VetoRuleSpread   = "spread"   // 买卖价差过大
+	VetoRuleBlackout = "blackout" // 禁止开仓时间段
+)
+
+// 持仓设置不一致时的处理方式
+const (
+	OnMismatchFix   = "fix"
+	OnMismatchAbort = "abort"
+	OnMismatchWarn  = "warn"
+)
+
+// 未成交部分的处理方式
+const (
+	FallbackMarket = "market"
+	FallbackNone   = "none"
+)
+
 var globalConfig *Config
 
 // Load 加载配置文件
@@ -75,7 +612,7 @@ func Load(configPath string) (*Config, error) {
 		// 获取主配置文件所在目录
 		configDir := filepath.Dir(configPath)
 		accountsPath := filepath.Join(configDir, cfg.AccountsConfig)
-		
+
 		accounts, err := LoadAccounts(accountsPath)
 		if err != nil {
 			return nil, fmt.Errorf("加载账号配置失败: %w", err)
@@ -83,6 +620,15 @@ func Load(configPath string) (*Config, error) {
 		cfg.Accounts = accounts
 	}
 
+	// 加载板块配置（可选）
+	if cfg.SectorsConfig != "" {
+		sectors, err := LoadSectors(filepath.Join(filepath.Dir(configPath), cfg.SectorsConfig))
+		if err != nil {
+			return nil, fmt.Errorf("加载板块配置失败: %w", err)
+		}
+		cfg.Sectors = sectors
+	}
+
 	// 验证配置
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("配置验证失败: %w", err)
@@ -103,12 +649,292 @@ func (c *Config) Validate() error {
 	if c.Binance.FuturesURL == "" {
 		return fmt.Errorf("币安合约URL不能为空")
 	}
+	if c.Binance.RecvWindow < 0 || c.Binance.RecvWindow > 60000 {
+		return fmt.Errorf("币安配置无效: recv_window需要在0到60000毫秒之间")
+	}
+
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("时区无效: %s (%w)", c.Timezone, err)
+	}
 
 	// 验证账号配置
 	if len(c.Accounts) == 0 {
 		return fmt.Errorf("至少需要配置一个账号")
 	}
 
+	// 验证币本位合约、现货、OKX配置
+	for _, acc := range c.Accounts {
+		if acc.GetMarketType() == "coin_m" && c.Binance.DeliveryURL == "" {
+			return fmt.Errorf("账号[%s]为币本位合约，币安币本位合约URL(delivery_url)不能为空", acc.ID)
+		}
+		if acc.GetMarketType() == "spot" && c.Binance.SpotURL == "" {
+			return fmt.Errorf("账号[%s]为现货，币安现货URL(spot_url)不能为空", acc.ID)
+		}
+		if acc.Treasury.Enabled && c.Binance.SpotURL == "" {
+			return fmt.Errorf("账号[%s]启用了资金调拨，币安现货URL(spot_url)不能为空", acc.ID)
+		}
+		if acc.GetExchange() == "okx" && c.OKX.BaseURL == "" {
+			return fmt.Errorf("账号[%s]为OKX账号，OKX API地址(okx.base_url)不能为空", acc.ID)
+		}
+	}
+
+	// 验证对账配置
+	r := c.Journal.Reconcile
+	if r.IntervalMinutes < 0 || r.WindowHours < 0 || r.ToleranceUSDT < 0 {
+		return fmt.Errorf("对账配置不能为负数")
+	}
+
+	// 验证持仓设置
+	if err := c.Position.Validate(); err != nil {
+		return fmt.Errorf("持仓设置无效: %w", err)
+	}
+	for _, acc := range c.Accounts {
+		if err := c.GetPositionConfig(&acc).Validate(); err != nil {
+			return fmt.Errorf("账号[%s]持仓设置无效: %w", acc.ID, err)
+		}
+	}
+
+	// 验证执行配置
+	for strategy, exec := range c.Execution {
+		if err := exec.Validate(); err != nil {
+			return fmt.Errorf("策略[%s]执行配置无效: %w", strategy, err)
+		}
+	}
+
+	// 验证交易对分级上限
+	if err := c.SymbolLimits.Validate(); err != nil {
+		return fmt.Errorf("交易对分级上限无效: %w", err)
+	}
+
+	// 验证加仓规则
+	for strategy, p := range c.Pyramiding {
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("策略[%s]加仓规则无效: %w", strategy, err)
+		}
+	}
+
+	// 验证重新入场规则
+	for strategy, r := range c.Reentry {
+		if r.WindowMinutes < 0 || r.MinScore < 0 || r.MinScore > 100 {
+			return fmt.Errorf("策略[%s]重新入场规则无效: window_minutes不能为负数，min_score必须在0-100之间", strategy)
+		}
+	}
+
+	// 验证决策过期规则
+	for strategy, st := range c.Staleness {
+		if st.TTLCycles < 0 || st.MaxPriceMovePct < 0 {
+			return fmt.Errorf("策略[%s]决策过期规则无效: ttl_cycles和max_price_move_pct不能为负数", strategy)
+		}
+	}
+
+	// 验证输入数据过期检查
+	for strategy, f := range c.Freshness {
+		if f.MaxAgeSec < 0 {
+			return fmt.Errorf("策略[%s]输入数据过期检查无效: max_age_sec不能为负数", strategy)
+		}
+	}
+
+	if p := c.Prompts; p.ReloadSec < 0 || p.Budget.MaxTokens < 0 || p.Budget.SignificantDigits < 0 {
+		return fmt.Errorf("提示词模板配置无效: reload_sec、budget.max_tokens和budget.significant_digits不能为负数")
+	}
+
+	// 验证AI模型配置
+	if ai := c.GetAIConfig(); ai.Enabled {
+		if ai.Model == "" || ai.APIKey == "" {
+			return fmt.Errorf("启用AI时model和api_key（或环境变量AI_API_KEY）不能为空")
+		}
+		if ai.TimeoutSec < 0 || ai.IdleTimeoutSec < 0 || ai.MaxTokens < 0 {
+			return fmt.Errorf("AI配置无效: timeout_sec、idle_timeout_sec和max_tokens不能为负数")
+		}
+	}
+	for strategy, r := range c.Ranking {
+		if r.TopN < 0 {
+			return fmt.Errorf("策略[%s]排名模式top_n不能为负数: %d", strategy, r.TopN)
+		}
+		if r.Enabled && !c.AI.Enabled {
+			return fmt.Errorf("策略[%s]启用了排名模式，需要先启用ai", strategy)
+		}
+	}
+	for strategy, t := range c.TwoStage {
+		if t.Enabled && !c.AI.Enabled {
+			return fmt.Errorf("策略[%s]启用了两阶段分析，需要先启用ai", strategy)
+		}
+	}
+	for strategy, t := range c.Tools {
+		if t.MaxCalls < 0 {
+			return fmt.Errorf("策略[%s]工具调用次数上限不能为负数: %d", strategy, t.MaxCalls)
+		}
+		if t.Enabled && !c.AI.Enabled {
+			return fmt.Errorf("策略[%s]启用了AI工具调用，需要先启用ai", strategy)
+		}
+	}
+	for strategy, dc := range c.DecisionCache {
+		if dc.MaxAgeMinutes < 0 || dc.SignificantDigits < 0 {
+			return fmt.Errorf("策略[%s]决策缓存配置无效: max_age_minutes和significant_digits不能为负数", strategy)
+		}
+		if dc.Enabled && !c.AI.Enabled {
+			return fmt.Errorf("策略[%s]启用了决策缓存，需要先启用ai", strategy)
+		}
+	}
+	for strategy := range c.Veto {
+		v := c.GetVetoConfig(strategy)
+		if v.MinADX < 0 || v.MaxSpreadBps < 0 || v.DownsizeFactor <= 0 || v.DownsizeFactor > 1 {
+			return fmt.Errorf("策略[%s]风控否决配置无效: min_adx和max_spread_bps不能为负数，downsize_factor必须在0-1之间", strategy)
+		}
+		for _, rule := range v.Downsize {
+			if rule != VetoRuleADX && rule != VetoRuleFunding && rule != VetoRuleSpread && rule != VetoRuleBlackout {
+				return fmt.Errorf("策略[%s]风控否决的downsize规则无效: %s（可选 adx / funding / spread / blackout）", strategy, rule)
+			}
+		}
+		for _, w := range v.Blackouts {
+			if w.Start.IsZero() || !w.End.After(w.Start) {
+				return fmt.Errorf("策略[%s]禁止开仓时间段无效: start和end不能为空且end必须晚于start", strategy)
+			}
+		}
+		if v.Enabled && !c.AI.Enabled {
+			return fmt.Errorf("策略[%s]启用了风控否决，需要先启用ai", strategy)
+		}
+	}
+	for strategy := range c.Voting {
+		v := c.GetVotingConfig(strategy)
+		if v.Samples < 1 || v.MinAgree < 1 || v.MinAgree > v.Samples || v.Temperature <= 0 {
+			return fmt.Errorf("策略[%s]投票配置无效: samples和min_agree至少为1且min_agree不能超过samples，temperature必须大于0", strategy)
+		}
+		if v.Enabled && !c.AI.Enabled {
+			return fmt.Errorf("策略[%s]启用了多次采样投票，需要先启用ai", strategy)
+		}
+	}
+
+	// 验证指标变化输出规则
+	for strategy, d := range c.Delta {
+		if d.DefaultPct < 0 || d.FullEvery < 0 {
+			return fmt.Errorf("策略[%s]指标变化输出规则无效: default_pct和full_every不能为负数", strategy)
+		}
+		for field, threshold := range d.Thresholds {
+			if threshold < 0 {
+				return fmt.Errorf("策略[%s]指标变化阈值无效: %s 不能为负数", strategy, field)
+			}
+		}
+	}
+
+	// 验证决策冷却规则
+	for strategy, cd := range c.Cooldown {
+		if cd.Minutes < 0 {
+			return fmt.Errorf("策略[%s]决策冷却时间不能为负数: %d", strategy, cd.Minutes)
+		}
+	}
+
+	// 验证最长持仓时间规则
+	for strategy := range c.MaxHolding {
+		m := c.GetMaxHoldingConfig(strategy)
+		if m.Minutes < 0 {
+			return fmt.Errorf("策略[%s]最长持仓时间不能为负数: %d", strategy, m.Minutes)
+		}
+		if m.Action != MaxHoldingClose && m.Action != MaxHoldingReview {
+			return fmt.Errorf("策略[%s]持仓超时处理方式无效: %s（可选 close、review）", strategy, m.Action)
+		}
+		if m.Enabled && m.Action == MaxHoldingReview && !c.AI.Enabled {
+			return fmt.Errorf("策略[%s]持仓超时使用AI出场评估，需要先启用ai", strategy)
+		}
+	}
+
+	// 验证组合风险报告配置
+	if r := c.RiskReport; r.IntervalMinutes < 0 || r.HorizonBars < 0 || (r.Lookback != 0 && r.Lookback < 20) {
+		return fmt.Errorf("组合风险报告配置无效: interval_minutes和horizon_bars不能为负数，lookback至少为20")
+	}
+	if cal := c.Calibration; cal.IntervalMinutes < 0 || cal.Bins < 0 {
+		return fmt.Errorf("置信度校准报告配置无效: interval_minutes和bins不能为负数")
+	}
+	if c.Calibration.Enabled && !c.AI.Enabled {
+		return fmt.Errorf("启用了置信度校准报告，需要先启用ai")
+	}
+	if b := c.SymbolBreaker; b.MaxFailures < 0 || b.BackoffSec < 0 || b.MaxBackoffSec < 0 {
+		return fmt.Errorf("交易对错误熔断配置无效: max_failures、backoff_sec和max_backoff_sec不能为负数")
+	}
+	if b := c.GetSymbolBreakerConfig(); b.MaxBackoffSec < b.BackoffSec {
+		return fmt.Errorf("交易对错误熔断配置无效: max_backoff_sec(%d)不能小于backoff_sec(%d)", b.MaxBackoffSec, b.BackoffSec)
+	}
+	if c.MarketData.TTLSec < 0 {
+		return fmt.Errorf("共享行情数据服务配置无效: ttl_sec不能为负数")
+	}
+	if c.Streams.MarkPrice.MaxAgeSec < 0 {
+		return fmt.Errorf("标记价格推送配置无效: max_age_sec不能为负数")
+	}
+	if c.Streams.Depth.MaxStreamsPerConnection < 0 {
+		return fmt.Errorf("增量深度推送配置无效: max_streams_per_connection不能为负数")
+	}
+	for _, minutes := range c.Streams.Liquidation.WindowsMinutes {
+		if minutes <= 0 {
+			return fmt.Errorf("强平推送配置无效: windows_minutes必须为正数")
+		}
+	}
+	if cascade := c.Streams.Liquidation.Cascade; cascade.WindowMinutes < 0 || cascade.SymbolNotionalUSDT < 0 || cascade.MarketNotionalUSDT < 0 {
+		return fmt.Errorf("连环强平配置无效: window_minutes和阈值不能为负数")
+	}
+	if live := c.Streams.Indicators; live.Enabled {
+		if !c.Streams.MarkPrice.Enabled {
+			return fmt.Errorf("启用了秒级增量指标，需要先启用标记价格推送（streams.mark_price）")
+		}
+		for _, period := range live.EMAPeriods {
+			if period <= 0 {
+				return fmt.Errorf("秒级增量指标配置无效: ema_periods必须为正数")
+			}
+		}
+	}
+	if live := c.Streams.Indicators; live.RSIPeriod < 0 || live.ATRPeriod < 0 || live.IntervalSec < 0 {
+		return fmt.Errorf("秒级增量指标配置无效: rsi_period、atr_period和interval_sec不能为负数")
+	}
+	if a := c.Alerts; a.CooldownMinutes < 0 || a.History < 0 {
+		return fmt.Errorf("行情告警配置无效: cooldown_minutes和history不能为负数")
+	}
+	if a := c.Alerts.Anomaly; a.ATRMultiple < 0 || a.OIJumpPct < 0 || a.OIWindowMinutes < 0 {
+		return fmt.Errorf("异动检测配置无效: atr_multiple、oi_jump_pct和oi_window_minutes不能为负数")
+	}
+	if c.Alerts.Anomaly.Enabled && !c.Alerts.Enabled {
+		return fmt.Errorf("启用了异动检测，需要先启用alerts")
+	}
+	if c.ExchangeStatus.IntervalSec < 0 {
+		return fmt.Errorf("交易所状态检测配置无效: interval_sec不能为负数")
+	}
+	if c.KeyCheck.Enabled && c.Binance.SpotURL == "" {
+		return fmt.Errorf("启用了API密钥检查，币安现货URL(spot_url)不能为空")
+	}
+	if c.Alerts.Premium.MaxPct < 0 {
+		return fmt.Errorf("溢价告警配置无效: max_pct不能为负数")
+	}
+	if c.Alerts.Premium.Enabled && !c.Alerts.Enabled {
+		return fmt.Errorf("启用了溢价告警，需要先启用alerts")
+	}
+	if c.Alerts.Premium.PauseEntries && !c.Alerts.Premium.Enabled {
+		return fmt.Errorf("溢价告警启用了pause_entries，需要先启用alerts.premium")
+	}
+	if v := c.Screener.Volume; v.Lookback < 0 || v.Multiple < 0 || v.Universe < 0 || v.MinQuoteVolume < 0 ||
+		v.MaxCandidates < 0 || v.ScanIntervalSec < 0 {
+		return fmt.Errorf("成交量异动筛选配置无效: lookback、multiple、universe、min_quote_volume、max_candidates和scan_interval_sec不能为负数")
+	}
+	if p := c.Screener.PumpDump; p.MinReturnPct < 0 || p.MinOIChangePct < 0 || p.TopN < 0 {
+		return fmt.Errorf("拉盘/砸盘筛选配置无效: min_return_pct、min_oi_change_pct和top_n不能为负数")
+	}
+	if p := c.Screener.PumpDump; p.Notify && p.WebhookURL == "" && !c.Alerts.Enabled {
+		return fmt.Errorf("拉盘/砸盘筛选启用了notify，需要先启用alerts或配置webhook_url")
+	}
+	if c.Telemetry.IntervalMinutes < 0 {
+		return fmt.Errorf("指标计算耗时统计配置无效: interval_minutes不能为负数")
+	}
+	for strategy, budget := range c.Telemetry.CycleBudgetMs {
+		if budget < 0 {
+			return fmt.Errorf("策略[%s]的指标计算耗时预算不能为负数", strategy)
+		}
+	}
+	if tg := c.GetTelegramConfig(); tg.Enabled {
+		if tg.BotToken == "" || len(tg.ChatIDs) == 0 {
+			return fmt.Errorf("启用Telegram时bot_token（或环境变量TELEGRAM_BOT_TOKEN）和chat_ids不能为空")
+		}
+		if tg.PollSec < 0 {
+			return fmt.Errorf("Telegram配置无效: poll_sec不能为负数")
+		}
+	}
+
 	return nil
 }
 
@@ -120,6 +946,15 @@ func (c *Config) GetProxyURL() string {
 	return fmt.Sprintf("http://%s:%d", c.Proxy.Host, c.Proxy.Port)
 }
 
+// GetLocation 获取时区（每日定时平仓、低流动性时段、绩效指标的每日边界，未配置时为UTC）
+func (c *Config) GetLocation() *time.Location {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 // GetEnabledAccounts 获取所有启用的账号
 func (c *Config) GetEnabledAccounts() []Account {
 	var enabled []Account
@@ -140,3 +975,624 @@ func (c *Config) GetAccountByID(id string) *Account {
 	}
 	return nil
 }
+
+// GetPositionConfig 获取账号的持仓设置（账号配置的杠杆、保证金模式覆盖全局默认）
+func (c *Config) GetPositionConfig(acc *Account) PositionConfig {
+	p := c.Position
+	if acc.Leverage > 0 {
+		p.Leverage = acc.Leverage
+	}
+	if acc.MarginType != "" {
+		p.MarginType = acc.MarginType
+	}
+	if p.OnMismatch == "" {
+		p.OnMismatch = OnMismatchFix
+	}
+	if p.MarginTopUp.TargetLiqDistancePct == 0 {
+		p.MarginTopUp.TargetLiqDistancePct = p.MarginTopUp.MinLiqDistancePct * 2
+	}
+	return p
+}
+
+// Validate 验证持仓设置
+func (p PositionConfig) Validate() error {
+	if p.Leverage < 0 || p.Leverage > 125 {
+		return fmt.Errorf("杠杆倍数无效: %d (必须在1-125之间，0表示不检查)", p.Leverage)
+	}
+	switch p.MarginType {
+	case "", "CROSSED", "ISOLATED":
+	default:
+		return fmt.Errorf("保证金模式无效: %s (必须是 CROSSED 或 ISOLATED)", p.MarginType)
+	}
+	switch p.OnMismatch {
+	case "", OnMismatchFix, OnMismatchAbort, OnMismatchWarn:
+	default:
+		return fmt.Errorf("不一致处理方式无效: %s (必须是 fix、abort 或 warn)", p.OnMismatch)
+	}
+	if t := p.MarginTopUp; t.Enabled {
+		if t.MinLiqDistancePct <= 0 || t.MinLiqDistancePct >= 100 {
+			return fmt.Errorf("强平距离下限无效: %v (必须在0-100之间)", t.MinLiqDistancePct)
+		}
+		if t.TargetLiqDistancePct != 0 && t.TargetLiqDistancePct <= t.MinLiqDistancePct {
+			return fmt.Errorf("目标强平距离(%v)必须大于下限(%v)", t.TargetLiqDistancePct, t.MinLiqDistancePct)
+		}
+		if t.MaxAddUSDT < 0 || t.MaxTotalUSDT < 0 {
+			return fmt.Errorf("追加保证金上限不能为负数")
+		}
+	}
+	return nil
+}
+
+// GetExecutionConfig 获取策略的执行配置（未配置时默认市价入场）
+func (c *Config) GetExecutionConfig(strategy string) ExecutionConfig {
+	exec, exists := c.Execution[strategy]
+	if !exists {
+		exec = ExecutionConfig{EntryType: EntryTypeMarket}
+	}
+	return exec.withDefaults()
+}
+
+// GetPyramidingConfig 获取策略的加仓规则（未配置时不加仓）
+func (c *Config) GetPyramidingConfig(strategy string) PyramidingConfig {
+	p := c.Pyramiding[strategy]
+	if p.MaxAdds == 0 {
+		p.MaxAdds = 2
+	}
+	if p.MinMovePct == 0 {
+		p.MinMovePct = 1
+	}
+	if p.SizeRatio == 0 {
+		p.SizeRatio = 0.5
+	}
+	return p
+}
+
+// GetReentryConfig 获取策略的止损后重新入场规则（未配置时不限制）
+func (c *Config) GetReentryConfig(strategy string) ReentryConfig {
+	r := c.Reentry[strategy]
+	if r.WindowMinutes == 0 {
+		r.WindowMinutes = 60
+	}
+	if r.Timeframe == "" {
+		r.Timeframe = "15m"
+	}
+	if r.MinScore == 0 {
+		r.MinScore = 60
+	}
+	return r
+}
+
+// GetStalenessConfig 获取策略的决策过期规则（未配置时不检查）
+func (c *Config) GetStalenessConfig(strategy string) StalenessConfig {
+	st := c.Staleness[strategy]
+	if st.TTLCycles == 0 {
+		st.TTLCycles = 1
+	}
+	if st.MaxPriceMovePct == 0 {
+		st.MaxPriceMovePct = 1
+	}
+	return st
+}
+
+// GetFreshnessConfig 获取策略的输入数据过期检查（未配置时不检查）
+func (c *Config) GetFreshnessConfig(strategy string) FreshnessConfig {
+	f := c.Freshness[strategy]
+	if f.MaxAgeSec == 0 {
+		f.MaxAgeSec = 120
+	}
+	return f
+}
+
+// GetCandlesConfig 获取策略的K线使用方式（未配置时只用已收盘K线）
+func (c *Config) GetCandlesConfig(strategy string) CandlesConfig {
+	return c.Candles[strategy]
+}
+
+// GetDeltaConfig 获取策略的指标变化输出规则（未配置时使用默认阈值、不写日志）
+func (c *Config) GetDeltaConfig(strategy string) DeltaConfig {
+	d := c.Delta[strategy]
+	if d.DefaultPct == 0 {
+		d.DefaultPct = 0.5
+	}
+	if d.FullEvery == 0 {
+		d.FullEvery = 12
+	}
+	if d.Ignore == nil {
+		d.Ignore = []string{"timestamp"}
+	}
+	return d
+}
+
+// GetCooldownConfig 获取策略的交易对决策冷却规则（未配置时不冷却）
+func (c *Config) GetCooldownConfig(strategy string) CooldownConfig {
+	return c.Cooldown[strategy]
+}
+
+// GetMaxHoldingConfig 获取策略的最长持仓时间规则（未配置时不限制）
+func (c *Config) GetMaxHoldingConfig(strategy string) MaxHoldingConfig {
+	m := c.MaxHolding[strategy]
+	if m.Action == "" {
+		m.Action = MaxHoldingClose
+	}
+	return m
+}
+
+// Validate 验证加仓规则
+func (p PyramidingConfig) Validate() error {
+	if p.MaxAdds < 0 || p.MinMovePct < 0 {
+		return fmt.Errorf("max_adds和min_move_pct不能为负数")
+	}
+	if p.SizeRatio < 0 || p.SizeRatio > 1 {
+		return fmt.Errorf("size_ratio必须在0~1之间: %v（加仓数量不能超过上一笔）", p.SizeRatio)
+	}
+	return nil
+}
+
+// ForSymbol 获取交易对所属的分级名称和上限（未列入任何分级时返回default）
+func (l SymbolLimitsConfig) ForSymbol(symbol string) (string, SymbolTier) {
+	for name, tier := range l.Tiers {
+		for _, s := range tier.Symbols {
+			if s == symbol {
+				return name, tier
+			}
+		}
+	}
+	return "default", l.Default
+}
+
+// Validate 验证交易对分级上限
+func (l SymbolLimitsConfig) Validate() error {
+	seen := make(map[string]string)
+	tiers := map[string]SymbolTier{"default": l.Default}
+	for name, tier := range l.Tiers {
+		tiers[name] = tier
+		for _, symbol := range tier.Symbols {
+			if other, ok := seen[symbol]; ok {
+				return fmt.Errorf("交易对%s同时属于分级%s和%s", symbol, other, name)
+			}
+			seen[symbol] = name
+		}
+	}
+	for name, tier := range tiers {
+		if tier.MaxLeverage < 0 || tier.MaxLeverage > 125 {
+			return fmt.Errorf("分级[%s]最大杠杆无效: %d (必须在1-125之间，0表示不限)", name, tier.MaxLeverage)
+		}
+		if tier.MaxNotionalUSDT < 0 {
+			return fmt.Errorf("分级[%s]名义价值上限不能为负数", name)
+		}
+	}
+	return nil
+}
+
+// GetAPIConfig 获取状态API配置（含默认值）
+func (c *Config) GetAPIConfig() APIConfig {
+	a := c.API
+	if a.Listen == "" {
+		a.Listen = "127.0.0.1:8080"
+	}
+	return a
+}
+
+// GetTelegramConfig 获取Telegram机器人配置（含默认值，bot_token留空时读取环境变量 TELEGRAM_BOT_TOKEN）
+func (c *Config) GetTelegramConfig() TelegramConfig {
+	t := c.Telegram
+	if t.BaseURL == "" {
+		t.BaseURL = "https://api.telegram.org"
+	}
+	if t.BotToken == "" {
+		t.BotToken = os.Getenv("TELEGRAM_BOT_TOKEN")
+	}
+	if t.PollSec == 0 {
+		t.PollSec = 30
+	}
+	return t
+}
+
+// GetSecretsConfig 获取密钥来源配置（含默认值，Vault地址和令牌留空时读取环境变量 VAULT_ADDR、VAULT_TOKEN）
+func (c *Config) GetSecretsConfig() SecretsConfig {
+	s := c.Secrets
+	if s.Vault.Address == "" {
+		s.Vault.Address = os.Getenv("VAULT_ADDR")
+	}
+	if s.Vault.Token == "" {
+		s.Vault.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if s.Vault.Mount == "" {
+		s.Vault.Mount = "secret"
+	}
+	if s.Vault.TimeoutSec == 0 {
+		s.Vault.TimeoutSec = 10
+	}
+	return s
+}
+
+// GetPromptsConfig 获取提示词模板配置（含默认值）
+func (c *Config) GetPromptsConfig() PromptsConfig {
+	p := c.Prompts
+	if p.Dir == "" {
+		p.Dir = "configs/prompts"
+	}
+	if p.ReloadSec == 0 {
+		p.ReloadSec = 5
+	}
+	if p.Budget.OptionalFields == nil {
+		p.Budget.OptionalFields = []string{"adx", "vwap", "stoch_rsi", "ichimoku", "cvd", "oi_history", "liquidations"}
+	}
+	if p.Budget.SignificantDigits == 0 {
+		p.Budget.SignificantDigits = 6
+	}
+	return p
+}
+
+// GetAIConfig 获取AI模型配置（含默认值，api_key留空时读取环境变量 AI_API_KEY）
+func (c *Config) GetAIConfig() AIConfig {
+	a := c.AI
+	if a.BaseURL == "" {
+		a.BaseURL = "https://api.openai.com/v1"
+	}
+	if a.APIKey == "" {
+		a.APIKey = os.Getenv("AI_API_KEY")
+	}
+	if a.TimeoutSec == 0 {
+		a.TimeoutSec = 60
+	}
+	if a.IdleTimeoutSec == 0 {
+		a.IdleTimeoutSec = 20
+	}
+	if a.AuditDir == "" {
+		a.AuditDir = "data/audit"
+	}
+	return a
+}
+
+// GetRankingConfig 获取策略的多交易对排名配置（未配置时逐个分析）
+func (c *Config) GetRankingConfig(strategy string) RankingConfig {
+	r := c.Ranking[strategy]
+	if r.TopN == 0 {
+		r.TopN = 3
+	}
+	if r.Template == "" {
+		r.Template = "ranking"
+	}
+	return r
+}
+
+// GetTwoStageConfig 获取策略的两阶段分析配置（未配置时单次调用直接输出决策）
+func (c *Config) GetTwoStageConfig(strategy string) TwoStageConfig {
+	t := c.TwoStage[strategy]
+	if t.AnalysisTemplate == "" {
+		t.AnalysisTemplate = "analysis"
+	}
+	if t.DecisionTemplate == "" {
+		t.DecisionTemplate = "decision"
+	}
+	return t
+}
+
+// GetVotingConfig 获取策略的多次采样投票配置（未配置时只采样一次）
+func (c *Config) GetVotingConfig(strategy string) VotingConfig {
+	v := c.Voting[strategy]
+	if v.Samples == 0 {
+		v.Samples = 3
+	}
+	if v.MinAgree == 0 {
+		v.MinAgree = v.Samples/2 + 1
+	}
+	if v.Temperature == 0 {
+		v.Temperature = 0.7
+	}
+	return v
+}
+
+// GetToolsConfig 获取策略的AI工具调用配置（未配置时不提供工具）
+func (c *Config) GetToolsConfig(strategy string) ToolsConfig {
+	t := c.Tools[strategy]
+	if t.MaxCalls == 0 {
+		t.MaxCalls = 5
+	}
+	return t
+}
+
+// GetDecisionCacheConfig 获取策略的AI决策缓存配置（未配置时每次都调用AI）
+func (c *Config) GetDecisionCacheConfig(strategy string) DecisionCacheConfig {
+	dc := c.DecisionCache[strategy]
+	if dc.MaxAgeMinutes == 0 {
+		dc.MaxAgeMinutes = 15
+	}
+	if dc.SignificantDigits == 0 {
+		dc.SignificantDigits = 4
+	}
+	return dc
+}
+
+// GetCalibrationConfig 获取置信度校准报告配置（含默认值）
+func (c *Config) GetCalibrationConfig() CalibrationConfig {
+	cal := c.Calibration
+	if cal.IntervalMinutes == 0 {
+		cal.IntervalMinutes = 360
+	}
+	if cal.Bins == 0 {
+		cal.Bins = 10
+	}
+	if cal.Dir == "" {
+		cal.Dir = "data/reports"
+	}
+	return cal
+}
+
+// GetTelemetryConfig 获取指标计算耗时统计配置（含默认值）
+func (c *Config) GetTelemetryConfig() TelemetryConfig {
+	t := c.Telemetry
+	if t.IntervalMinutes == 0 {
+		t.IntervalMinutes = 60
+	}
+	return t
+}
+
+// GetMarketDataConfig 获取共享行情数据服务配置（含默认值）
+func (c *Config) GetMarketDataConfig() MarketDataConfig {
+	m := c.MarketData
+	if m.TTLSec == 0 {
+		m.TTLSec = 30
+	}
+	return m
+}
+
+// GetSymbolBreakerConfig 获取交易对错误熔断配置（含默认值）
+func (c *Config) GetSymbolBreakerConfig() SymbolBreakerConfig {
+	b := c.SymbolBreaker
+	if b.MaxFailures == 0 {
+		b.MaxFailures = 3
+	}
+	if b.BackoffSec == 0 {
+		b.BackoffSec = 300
+	}
+	if b.MaxBackoffSec == 0 {
+		b.MaxBackoffSec = 3600
+	}
+	return b
+}
+
+// GetAlertsConfig 获取行情告警配置（含默认值）
+func (c *Config) GetAlertsConfig() AlertsConfig {
+	a := c.Alerts
+	if a.CooldownMinutes == 0 {
+		a.CooldownMinutes = 30
+	}
+	if a.History == 0 {
+		a.History = 200
+	}
+	if a.Anomaly.ATRMultiple == 0 {
+		a.Anomaly.ATRMultiple = 3
+	}
+	if a.Anomaly.OIJumpPct == 0 {
+		a.Anomaly.OIJumpPct = 5
+	}
+	if a.Anomaly.OIWindowMinutes == 0 {
+		a.Anomaly.OIWindowMinutes = 5
+	}
+	if a.Premium.MaxPct == 0 {
+		a.Premium.MaxPct = 0.5
+	}
+	return a
+}
+
+// GetStatusConfig 获取交易所状态检测配置（含默认值）
+func (c *Config) GetStatusConfig() StatusConfig {
+	s := c.ExchangeStatus
+	if s.IntervalSec == 0 {
+		s.IntervalSec = 60
+	}
+	return s
+}
+
+// GetScreenerConfig 获取机会筛选配置（含默认值）
+func (c *Config) GetScreenerConfig() ScreenerConfig {
+	s := c.Screener
+	if s.Volume.Interval == "" {
+		s.Volume.Interval = "5m"
+	}
+	if s.Volume.Lookback == 0 {
+		s.Volume.Lookback = 20
+	}
+	if s.Volume.Multiple == 0 {
+		s.Volume.Multiple = 3
+	}
+	if s.Volume.Universe == 0 {
+		s.Volume.Universe = 50
+	}
+	if s.Volume.MinQuoteVolume == 0 {
+		s.Volume.MinQuoteVolume = 20000000
+	}
+	if s.Volume.MaxCandidates == 0 {
+		s.Volume.MaxCandidates = 3
+	}
+	if s.Volume.ScanIntervalSec == 0 {
+		s.Volume.ScanIntervalSec = 60
+	}
+	if len(s.Volume.Strategies) == 0 {
+		s.Volume.Strategies = []string{"short_term"}
+	}
+	if s.PumpDump.MinReturnPct == 0 {
+		s.PumpDump.MinReturnPct = 3
+	}
+	if s.PumpDump.MinOIChangePct == 0 {
+		s.PumpDump.MinOIChangePct = 2
+	}
+	if s.PumpDump.TopN == 0 {
+		s.PumpDump.TopN = 10
+	}
+	return s
+}
+
+// GetStreamsConfig 获取WebSocket行情推送配置（含默认值）
+func (c *Config) GetStreamsConfig() StreamsConfig {
+	s := c.Streams
+	if s.FuturesURL == "" {
+		s.FuturesURL = "wss://fstream.binance.com"
+	}
+	if s.DeliveryURL == "" {
+		s.DeliveryURL = "wss://dstream.binance.com"
+	}
+	if s.MarkPrice.MaxAgeSec == 0 {
+		s.MarkPrice.MaxAgeSec = 5
+	}
+	if s.Depth.MaxStreamsPerConnection == 0 {
+		s.Depth.MaxStreamsPerConnection = 200
+	}
+	if len(s.Liquidation.WindowsMinutes) == 0 {
+		s.Liquidation.WindowsMinutes = []int{5, 60}
+	}
+	if s.Liquidation.Cascade.WindowMinutes == 0 {
+		s.Liquidation.Cascade.WindowMinutes = 5
+	}
+	if len(s.Indicators.EMAPeriods) == 0 {
+		s.Indicators.EMAPeriods = []int{9, 21}
+	}
+	if s.Indicators.RSIPeriod == 0 {
+		s.Indicators.RSIPeriod = 14
+	}
+	if s.Indicators.ATRPeriod == 0 {
+		s.Indicators.ATRPeriod = 14
+	}
+	if s.Indicators.IntervalSec == 0 {
+		s.Indicators.IntervalSec = 1
+	}
+	return s
+}
+
+// GetVetoConfig 获取策略的AI决策风控否决规则（未配置时不检查）
+func (c *Config) GetVetoConfig(strategy string) VetoConfig {
+	v := c.Veto[strategy]
+	if v.DownsizeFactor == 0 {
+		v.DownsizeFactor = 0.5
+	}
+	return v
+}
+
+// GetRiskReportConfig 获取组合风险报告配置（含默认值）
+func (c *Config) GetRiskReportConfig() RiskReportConfig {
+	r := c.RiskReport
+	if r.IntervalMinutes == 0 {
+		r.IntervalMinutes = 60
+	}
+	if r.Dir == "" {
+		r.Dir = "data/reports"
+	}
+	if r.Timeframe == "" {
+		r.Timeframe = "1h"
+	}
+	if r.Lookback == 0 {
+		r.Lookback = 168
+	}
+	if r.HorizonBars == 0 {
+		r.HorizonBars = 24
+	}
+	return r
+}
+
+// GetJournalConfig 获取交易日志配置（含默认值）
+func (c *Config) GetJournalConfig() JournalConfig {
+	j := c.Journal
+	if j.Dir == "" {
+		j.Dir = "data/journal"
+	}
+	if j.Reconcile.IntervalMinutes == 0 {
+		j.Reconcile.IntervalMinutes = 60
+	}
+	if j.Reconcile.WindowHours == 0 {
+		j.Reconcile.WindowHours = 24
+	}
+	if j.Reconcile.ToleranceUSDT == 0 {
+		j.Reconcile.ToleranceUSDT = 0.01
+	}
+	return j
+}
+
+// Validate 验证执行配置
+func (e ExecutionConfig) Validate() error {
+	switch e.EntryType {
+	case "", EntryTypeMarket:
+	case EntryTypeMakerFirst:
+		if e.MaxReprices < 0 || e.PriceOffsetTicks < 0 || e.MakerFirstMinSavingBps < 0 {
+			return fmt.Errorf("max_reprices、price_offset_ticks和maker_first_min_saving_bps不能为负数")
+		}
+	case EntryTypeLimit:
+		switch e.TimeInForce {
+		case "", "GTC", "IOC", "FOK", "GTX":
+		default:
+			return fmt.Errorf("time_in_force无效: %s (必须是 GTC、IOC、FOK 或 GTX)", e.TimeInForce)
+		}
+	default:
+		return fmt.Errorf("entry_type无效: %s (必须是 market、limit 或 maker_first)", e.EntryType)
+	}
+
+	switch e.Fallback {
+	case "", FallbackMarket, FallbackNone:
+	default:
+		return fmt.Errorf("fallback无效: %s (必须是 market 或 none)", e.Fallback)
+	}
+
+	if e.LimitTimeoutSec < 0 {
+		return fmt.Errorf("limit_timeout_sec不能为负数")
+	}
+
+	if e.MaxSlippageBps < 0 {
+		return fmt.Errorf("max_slippage_bps不能为负数")
+	}
+	switch e.SlippageAction {
+	case "", SlippageActionDownsize, SlippageActionSkip:
+	default:
+		return fmt.Errorf("slippage_action无效: %s (必须是 downsize 或 skip)", e.SlippageAction)
+	}
+
+	if e.TWAP.ThresholdUSDT < 0 || e.TWAP.Slices < 0 || e.TWAP.IntervalSec < 0 {
+		return fmt.Errorf("twap配置不能为负数")
+	}
+	if e.TWAP.SizeJitter < 0 || e.TWAP.SizeJitter > 0.9 {
+		return fmt.Errorf("twap.size_jitter必须在0~0.9之间: %v", e.TWAP.SizeJitter)
+	}
+	return nil
+}
+
+// withDefaults 填充默认值
+func (e ExecutionConfig) withDefaults() ExecutionConfig {
+	if e.EntryType == "" {
+		e.EntryType = EntryTypeMarket
+	}
+	if e.EntryType == EntryTypeLimit {
+		if e.TimeInForce == "" {
+			e.TimeInForce = "GTX"
+		}
+		if e.LimitTimeoutSec == 0 {
+			e.LimitTimeoutSec = 10
+		}
+		if e.Fallback == "" {
+			e.Fallback = FallbackMarket
+		}
+	}
+	if e.EntryType == EntryTypeMakerFirst {
+		e.TimeInForce = "GTX" // 只做Maker
+		if e.LimitTimeoutSec == 0 {
+			e.LimitTimeoutSec = 5
+		}
+		if e.MaxReprices == 0 {
+			e.MaxReprices = 3
+		}
+		if e.Fallback == "" {
+			e.Fallback = FallbackMarket
+		}
+	}
+	if e.MaxSlippageBps > 0 && e.SlippageAction == "" {
+		e.SlippageAction = SlippageActionDownsize
+	}
+	if e.TWAP.ThresholdUSDT > 0 {
+		if e.TWAP.Slices == 0 {
+			e.TWAP.Slices = 5
+		}
+		if e.TWAP.IntervalSec == 0 {
+			e.TWAP.IntervalSec = 30
+		}
+	}
+	return e
+}
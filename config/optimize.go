@@ -0,0 +1,142 @@
+/*
+Package config 参数优化配置管理
+
+主要功能：
+- LoadOptimize(optimizePath string) (*OptimizeConfig, error)  // 加载参数优化配置文件（含默认值）
+- (o *OptimizeConfig) Validate() error                      // 验证参数优化配置
+- (r ParamRange) Expand() []float64                         // 展开参数取值
+*/
+package config
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 参数优化排序指标
+const (
+	RankByNetPnL         = "net_pnl"         // 净盈亏
+	RankByReturnDrawdown = "return_drawdown" // 收益率 / 最大回撤
+	RankByWinRate        = "win_rate"        // 胜率
+)
+
+// maxOptimizeCombinations 参数组合数量上限（防止范围写错导致组合爆炸）
+const maxOptimizeCombinations = 20000
+
+// OptimizeConfig 参数优化配置文件结构
+type OptimizeConfig struct {
+	Strategy       string                `yaml:"strategy"`        // 回测决策函数名称（如 ema_cross）
+	Symbol         string                `yaml:"symbol"`          // 交易对
+	Interval       string                `yaml:"interval"`        // K线周期（默认1h）
+	Limit          int                   `yaml:"limit"`           // K线数量（默认1500）
+	InitialBalance float64               `yaml:"initial_balance"` // 初始资金（默认10000）
+	InSamplePct    float64               `yaml:"in_sample_pct"`   // 样本内数据占比（%，默认70，其余为样本外）
+	Workers        int                   `yaml:"workers"`         // 并行回测数（0表示CPU核数）
+	RankBy         string                `yaml:"rank_by"`         // 排序指标：net_pnl、return_drawdown（默认）或 win_rate
+	MinTrades      int                   `yaml:"min_trades"`      // 样本内最少交易笔数（默认20，不足时警告）
+	Top            int                   `yaml:"top"`             // 输出前多少名（默认20）
+	Seed           int64                 `yaml:"seed"`            // 回测随机种子（0表示按当前时间，实际种子记录在结果中）
+	Params         map[string]ParamRange `yaml:"params"`          // 参数名 -> 取值范围
+}
+
+// ParamRange 参数取值范围（设置values时使用列表，否则按 min 到 max 以 step 递增）
+type ParamRange struct {
+	Values []float64 `yaml:"values"`
+	Min    float64   `yaml:"min"`
+	Max    float64   `yaml:"max"`
+	Step   float64   `yaml:"step"`
+}
+
+// LoadOptimize 加载参数优化配置文件（含默认值）
+func LoadOptimize(optimizePath string) (*OptimizeConfig, error) {
+	data, err := os.ReadFile(optimizePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取参数优化配置文件失败: %w", err)
+	}
+
+	var opt OptimizeConfig
+	if err := yaml.Unmarshal(data, &opt); err != nil {
+		return nil, fmt.Errorf("解析参数优化配置文件失败: %w", err)
+	}
+
+	if opt.Interval == "" {
+		opt.Interval = "1h"
+	}
+	if opt.Limit == 0 {
+		opt.Limit = 1500
+	}
+	if opt.InitialBalance == 0 {
+		opt.InitialBalance = 10000
+	}
+	if opt.InSamplePct == 0 {
+		opt.InSamplePct = 70
+	}
+	if opt.RankBy == "" {
+		opt.RankBy = RankByReturnDrawdown
+	}
+	if opt.MinTrades == 0 {
+		opt.MinTrades = 20
+	}
+	if opt.Top == 0 {
+		opt.Top = 20
+	}
+
+	if err := opt.Validate(); err != nil {
+		return nil, err
+	}
+	return &opt, nil
+}
+
+// Validate 验证参数优化配置
+func (o *OptimizeConfig) Validate() error {
+	if o.Strategy == "" || o.Symbol == "" {
+		return fmt.Errorf("strategy和symbol不能为空")
+	}
+	if o.InSamplePct <= 0 || o.InSamplePct >= 100 {
+		return fmt.Errorf("in_sample_pct必须在0-100之间: %v", o.InSamplePct)
+	}
+	if o.Limit < 0 || o.Workers < 0 || o.MinTrades < 0 || o.Top < 0 || o.InitialBalance < 0 {
+		return fmt.Errorf("limit、workers、min_trades、top和initial_balance不能为负数")
+	}
+	switch o.RankBy {
+	case RankByNetPnL, RankByReturnDrawdown, RankByWinRate:
+	default:
+		return fmt.Errorf("排序指标无效: %s (必须是 net_pnl、return_drawdown 或 win_rate)", o.RankBy)
+	}
+	if len(o.Params) == 0 {
+		return fmt.Errorf("params不能为空")
+	}
+
+	combinations := 1
+	for name, r := range o.Params {
+		if len(r.Values) == 0 && (r.Step <= 0 || r.Max < r.Min) {
+			return fmt.Errorf("参数[%s]范围无效: 需要values，或 step>0 且 max>=min", name)
+		}
+		combinations *= len(r.Expand())
+		if combinations > maxOptimizeCombinations {
+			return fmt.Errorf("参数组合超过%d个，请缩小范围或增大步长", maxOptimizeCombinations)
+		}
+	}
+	return nil
+}
+
+// Expand 展开参数取值
+func (r ParamRange) Expand() []float64 {
+	if len(r.Values) > 0 {
+		return r.Values
+	}
+	if r.Step <= 0 || r.Max < r.Min {
+		return nil
+	}
+
+	// 加上一个很小的容差，避免浮点误差漏掉max
+	n := int(math.Floor((r.Max-r.Min)/r.Step+1e-9)) + 1
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = math.Round((r.Min+float64(i)*r.Step)*1e9) / 1e9
+	}
+	return values
+}
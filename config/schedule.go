@@ -0,0 +1,176 @@
+/*
+Package config 账号定时风控配置（低流动性时段前减仓或平仓、每日定时平仓）
+
+主要功能：
+- ParseClock(s string) (hour, minute int, err error)      // 解析 HH:MM
+- ParseWeekday(s string) (time.Weekday, error)            // 解析星期（monday 或 mon，不区分大小写）
+- (f FlattenConfig) Validate() error                      // 验证低流动性时段配置
+- (a *Account) GetFlattenConfig() FlattenConfig           // 获取低流动性时段配置（含默认值）
+- (f FlatTimeConfig) Validate() error                     // 验证每日定时平仓配置
+- (f FlatTimeConfig) BlockDuration() time.Duration        // 平仓后不开仓的时长（未配置恢复时间时为0）
+
+时间按 config.yml 的 timezone 解释（未配置时为UTC）。
+*/
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FlattenConfig 低流动性时段（如周五晚上到周末、重大节假日）前自动减仓或平仓
+type FlattenConfig struct {
+	Enabled      bool            `yaml:"enabled"`       // 是否启用
+	Windows      []FlattenWindow `yaml:"windows"`       // 低流动性时段
+	LeadMinutes  int             `yaml:"lead_minutes"`  // 时段开始前多少分钟执行（默认30）
+	Action       string          `yaml:"action"`        // close（全部平仓，默认）或 reduce（按比例减仓）
+	ReducePct    float64         `yaml:"reduce_pct"`    // reduce 时减掉的持仓比例（%，默认50）
+	BlockEntries bool            `yaml:"block_entries"` // 提前量和时段内不再开仓
+}
+
+// FlattenWindow 低流动性时段：weekday 为每周重复，date 为指定日期（如节假日），二选一
+type FlattenWindow struct {
+	Name    string  `yaml:"name"`    // 名称（如 weekend、christmas）
+	Weekday string  `yaml:"weekday"` // 每周开始的星期（如 friday）
+	Date    string  `yaml:"date"`    // 指定日期（YYYY-MM-DD）
+	Start   string  `yaml:"start"`   // 开始时间（HH:MM，默认00:00）
+	Hours   float64 `yaml:"hours"`   // 持续小时数
+}
+
+// FlatTimeConfig 每日定时平仓（不想隔夜持仓）：每天 time 平掉全部持仓并撤销全部挂单
+type FlatTimeConfig struct {
+	Enabled bool   `yaml:"enabled"` // 是否启用
+	Time    string `yaml:"time"`    // 平仓时间（HH:MM）
+	Resume  string `yaml:"resume"`  // 恢复开仓时间（HH:MM，留空表示平仓后照常开仓）
+}
+
+// 低流动性时段前的处理方式
+const (
+	FlattenClose  = "close"
+	FlattenReduce = "reduce"
+)
+
+// ParseClock 解析 HH:MM（空字符串为00:00）
+func ParseClock(s string) (int, int, error) {
+	if s == "" {
+		return 0, 0, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("时间格式无效: %s（应为 HH:MM）", s)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// ParseWeekday 解析星期（monday 或 mon，不区分大小写）
+func ParseWeekday(s string) (time.Weekday, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || name == full[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("星期无效: %s（如 friday 或 fri）", s)
+}
+
+// Validate 验证低流动性时段配置
+func (f FlattenConfig) Validate() error {
+	if f.LeadMinutes < 0 {
+		return fmt.Errorf("低流动性时段配置无效: lead_minutes不能为负数")
+	}
+	switch f.Action {
+	case "", FlattenClose, FlattenReduce:
+	default:
+		return fmt.Errorf("低流动性时段处理方式无效: %s（可选 close、reduce）", f.Action)
+	}
+	if f.ReducePct < 0 || f.ReducePct > 100 {
+		return fmt.Errorf("低流动性时段减仓比例无效: %v（必须在0-100之间）", f.ReducePct)
+	}
+	if f.Enabled && len(f.Windows) == 0 {
+		return fmt.Errorf("启用了低流动性时段减仓，需要配置windows")
+	}
+	for i, w := range f.Windows {
+		if (w.Weekday == "") == (w.Date == "") {
+			return fmt.Errorf("低流动性时段[%d]需要配置weekday或date之一", i)
+		}
+		if w.Weekday != "" {
+			if _, err := ParseWeekday(w.Weekday); err != nil {
+				return fmt.Errorf("低流动性时段[%d]: %w", i, err)
+			}
+		}
+		if w.Date != "" {
+			if _, err := time.Parse(time.DateOnly, w.Date); err != nil {
+				return fmt.Errorf("低流动性时段[%d]日期无效: %s（应为 YYYY-MM-DD）", i, w.Date)
+			}
+		}
+		if _, _, err := ParseClock(w.Start); err != nil {
+			return fmt.Errorf("低流动性时段[%d]: %w", i, err)
+		}
+		if w.Hours <= 0 {
+			return fmt.Errorf("低流动性时段[%d]持续小时数必须大于0", i)
+		}
+	}
+	return nil
+}
+
+// GetFlattenConfig 获取低流动性时段配置（含默认值）
+func (a *Account) GetFlattenConfig() FlattenConfig {
+	f := a.Flatten
+	if f.LeadMinutes == 0 {
+		f.LeadMinutes = 30
+	}
+	if f.Action == "" {
+		f.Action = FlattenClose
+	}
+	if f.ReducePct == 0 {
+		f.ReducePct = 50
+	}
+	for i := range f.Windows {
+		if f.Windows[i].Name == "" {
+			f.Windows[i].Name = fmt.Sprintf("window%d", i+1)
+		}
+	}
+	return f
+}
+
+// Validate 验证每日定时平仓配置
+func (f FlatTimeConfig) Validate() error {
+	if !f.Enabled {
+		return nil
+	}
+	if f.Time == "" {
+		return fmt.Errorf("启用了每日定时平仓，需要配置time")
+	}
+	if _, _, err := ParseClock(f.Time); err != nil {
+		return fmt.Errorf("每日定时平仓: %w", err)
+	}
+	if _, _, err := ParseClock(f.Resume); err != nil {
+		return fmt.Errorf("每日定时平仓恢复开仓: %w", err)
+	}
+	if f.Resume != "" && f.BlockDuration() == 0 {
+		return fmt.Errorf("每日定时平仓的恢复开仓时间不能与平仓时间相同")
+	}
+	return nil
+}
+
+// BlockDuration 平仓后到恢复开仓的时长（恢复时间早于平仓时间时跨天；未配置恢复时间时为0）
+func (f FlatTimeConfig) BlockDuration() time.Duration {
+	if f.Resume == "" {
+		return 0
+	}
+	h, m, err := ParseClock(f.Time)
+	if err != nil {
+		return 0
+	}
+	rh, rm, err := ParseClock(f.Resume)
+	if err != nil {
+		return 0
+	}
+	d := time.Duration(rh-h)*time.Hour + time.Duration(rm-m)*time.Minute
+	if d < 0 {
+		d += 24 * time.Hour
+	}
+	return d
+}
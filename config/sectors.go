@@ -0,0 +1,94 @@
+/*
+Package config 板块配置管理
+
+主要功能：
+- LoadSectors(sectorsPath string) (*SectorsConfig, error)     // 加载板块配置文件
+- (s *SectorsConfig) Validate() error                        // 验证板块配置
+- (s *SectorsConfig) SectorOf(symbol string) string          // 获取交易对所属板块（未归类返回空）
+- (s *SectorsConfig) MaxExposure(sector string) float64      // 获取板块的敞口上限（USDT，0表示不限）
+*/
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SectorsConfig 板块配置文件结构（交易对 → 板块，每个账号的板块合计敞口上限）
+type SectorsConfig struct {
+	Sectors                map[string]Sector `yaml:"sectors"`                   // 板块名称 -> 板块
+	DefaultMaxExposureUSDT float64           `yaml:"default_max_exposure_usdt"` // 未设置上限的板块使用的上限（0表示不限）
+}
+
+// Sector 板块
+type Sector struct {
+	Symbols         []string `yaml:"symbols"`           // 属于该板块的交易对
+	MaxExposureUSDT float64  `yaml:"max_exposure_usdt"` // 每个账号在该板块的持仓名义价值合计上限（USDT）
+}
+
+// LoadSectors 加载板块配置文件
+func LoadSectors(sectorsPath string) (*SectorsConfig, error) {
+	data, err := os.ReadFile(sectorsPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取板块配置文件失败: %w", err)
+	}
+
+	var sectors SectorsConfig
+	if err := yaml.Unmarshal(data, &sectors); err != nil {
+		return nil, fmt.Errorf("解析板块配置文件失败: %w", err)
+	}
+
+	if err := sectors.Validate(); err != nil {
+		return nil, err
+	}
+	return &sectors, nil
+}
+
+// Validate 验证板块配置（一个交易对只能属于一个板块）
+func (s *SectorsConfig) Validate() error {
+	if s.DefaultMaxExposureUSDT < 0 {
+		return fmt.Errorf("default_max_exposure_usdt不能为负数")
+	}
+
+	seen := make(map[string]string)
+	for name, sector := range s.Sectors {
+		if sector.MaxExposureUSDT < 0 {
+			return fmt.Errorf("板块[%s]敞口上限不能为负数", name)
+		}
+		for _, symbol := range sector.Symbols {
+			if other, ok := seen[symbol]; ok {
+				return fmt.Errorf("交易对%s同时属于板块%s和%s", symbol, other, name)
+			}
+			seen[symbol] = name
+		}
+	}
+	return nil
+}
+
+// SectorOf 获取交易对所属板块（未归类返回空）
+func (s *SectorsConfig) SectorOf(symbol string) string {
+	if s == nil {
+		return ""
+	}
+	for name, sector := range s.Sectors {
+		for _, sym := range sector.Symbols {
+			if sym == symbol {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// MaxExposure 获取板块的敞口上限（USDT，0表示不限）
+func (s *SectorsConfig) MaxExposure(sector string) float64 {
+	if s == nil || sector == "" {
+		return 0
+	}
+	if limit := s.Sectors[sector].MaxExposureUSDT; limit > 0 {
+		return limit
+	}
+	return s.DefaultMaxExposureUSDT
+}
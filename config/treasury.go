@@ -0,0 +1,63 @@
+/*
+Package config 合约账户资金自动调拨配置
+
+主要功能：
+- (t TreasuryConfig) Validate() error              // 验证资金调拨配置
+- (a *Account) GetTreasuryConfig() TreasuryConfig  // 获取资金调拨配置（含默认值）
+
+合约账户可用余额低于 floor 时从现货账户转入，高于 ceiling 时把多出的部分转回现货账户，
+两种情况都调整到 target。
+*/
+package config
+
+import "fmt"
+
+// TreasuryConfig 合约账户资金自动调拨（只支持币安合约实盘账号，需要配置 binance.spot_url）
+type TreasuryConfig struct {
+	Enabled         bool    `yaml:"enabled"`          // 是否启用
+	Asset           string  `yaml:"asset"`            // 划转资产（U本位默认USDT，币本位必须配置，如BTC）
+	Floor           float64 `yaml:"floor"`            // 可用余额下限，低于时从现货转入
+	Ceiling         float64 `yaml:"ceiling"`          // 可用余额上限，高于时转回现货（0表示不转出）
+	Target          float64 `yaml:"target"`           // 调拨后的目标可用余额（默认：有上限时为上下限中点，否则为下限的2倍）
+	MaxTransfer     float64 `yaml:"max_transfer"`     // 单次划转上限（0表示不限）
+	IntervalMinutes int     `yaml:"interval_minutes"` // 检查间隔（分钟，默认5）
+}
+
+// Validate 验证资金调拨配置
+func (t TreasuryConfig) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+	if t.Floor < 0 || t.Ceiling < 0 || t.Target < 0 || t.MaxTransfer < 0 || t.IntervalMinutes < 0 {
+		return fmt.Errorf("资金调拨配置不能为负数")
+	}
+	if t.Floor == 0 && t.Ceiling == 0 {
+		return fmt.Errorf("资金调拨需要配置可用余额下限(floor)或上限(ceiling)")
+	}
+	if t.Ceiling > 0 && t.Ceiling <= t.Floor {
+		return fmt.Errorf("资金调拨上限 %v 必须大于下限 %v", t.Ceiling, t.Floor)
+	}
+	if t.Target > 0 && (t.Target < t.Floor || (t.Ceiling > 0 && t.Target > t.Ceiling)) {
+		return fmt.Errorf("资金调拨目标 %v 必须在下限 %v 和上限 %v 之间", t.Target, t.Floor, t.Ceiling)
+	}
+	return nil
+}
+
+// GetTreasuryConfig 获取资金调拨配置（含默认值）
+func (a *Account) GetTreasuryConfig() TreasuryConfig {
+	t := a.Treasury
+	if t.Asset == "" && a.GetMarketType() == "usdt_m" {
+		t.Asset = "USDT"
+	}
+	if t.Target == 0 {
+		if t.Ceiling > 0 {
+			t.Target = (t.Floor + t.Ceiling) / 2
+		} else {
+			t.Target = t.Floor * 2
+		}
+	}
+	if t.IntervalMinutes == 0 {
+		t.IntervalMinutes = 5
+	}
+	return t
+}
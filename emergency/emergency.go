@@ -0,0 +1,251 @@
+/*
+Package emergency 紧急全部平仓（撤销全部挂单并市价平掉所选账号的全部持仓）
+
+主要功能：
+- New(dir string) *Switch                                                  // 创建紧急平仓开关（记录写入 <dir>/emergency.jsonl）
+- (s *Switch) Register(account Account)                                    // 注册可紧急平仓的账号
+- (s *Switch) OnExecute(fn func(record *Record))                           // 设置执行完成后的回调（如发送告警）
+- (s *Switch) Accounts() []string                                          // 已注册的账号ID
+- (s *Switch) Request(ids []string, source string) (*Confirmation, error)  // 申请紧急平仓，返回确认码
+- (s *Switch) Confirm(code, source string) (*Record, error)                // 用确认码确认并执行
+- (s *Switch) Execute(ids []string, source string) (*Record, error)        // 直接执行（调用方已完成确认，如命令行）
+
+HTTP接口和Telegram命令需要两步：先申请得到确认码，在有效期内用确认码确认后才执行，确认码只能使用一次。
+每次执行的来源、账号、平仓的交易对和错误追加写入记录文件，平掉的持仓照常写入交易日志。
+*/
+package emergency
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// ConfirmTTL 确认码有效期
+const ConfirmTTL = 2 * time.Minute
+
+// Account 可紧急平仓的账号
+type Account struct {
+	ID       string                   // 账号ID
+	CloseAll func() ([]string, error) // 撤销全部挂单并平掉全部持仓，返回平仓的交易对
+}
+
+// Confirmation 待确认的紧急平仓申请
+type Confirmation struct {
+	Code      string    `json:"code"`       // 确认码
+	Accounts  []string  `json:"accounts"`   // 将要平仓的账号
+	Source    string    `json:"source"`     // 申请来源
+	ExpiresAt time.Time `json:"expires_at"` // 过期时间
+}
+
+// Result 单个账号的紧急平仓结果
+type Result struct {
+	AccountID string   `json:"account_id"`      // 账号ID
+	Closed    []string `json:"closed"`          // 平仓的交易对
+	Error     string   `json:"error,omitempty"` // 错误（部分交易对失败时也会有已平仓的交易对）
+}
+
+// Record 一次紧急平仓的记录
+type Record struct {
+	Time       time.Time `json:"time"`        // 开始执行时间
+	Source     string    `json:"source"`      // 来源（cli、api、telegram:<聊天ID>）
+	Accounts   []string  `json:"accounts"`    // 所选账号
+	Results    []Result  `json:"results"`     // 各账号结果
+	DurationMs int64     `json:"duration_ms"` // 执行耗时（毫秒）
+}
+
+// Failed 是否有账号处理失败
+func (r *Record) Failed() bool {
+	return slices.ContainsFunc(r.Results, func(res Result) bool { return res.Error != "" })
+}
+
+// Switch 紧急平仓开关
+type Switch struct {
+	path     string
+	mu       sync.Mutex
+	accounts []Account
+	pending  map[string]*Confirmation // 确认码 -> 申请
+	notify   func(record *Record)
+}
+
+// New 创建紧急平仓开关（记录写入 <dir>/emergency.jsonl）
+func New(dir string) *Switch {
+	return &Switch{
+		path:    filepath.Join(dir, "emergency.jsonl"),
+		pending: make(map[string]*Confirmation),
+	}
+}
+
+// Register 注册可紧急平仓的账号
+func (s *Switch) Register(account Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accounts = append(s.accounts, account)
+}
+
+// OnExecute 设置执行完成后的回调
+func (s *Switch) OnExecute(fn func(record *Record)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notify = fn
+}
+
+// Accounts 已注册的账号ID
+func (s *Switch) Accounts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, len(s.accounts))
+	for i, a := range s.accounts {
+		ids[i] = a.ID
+	}
+	return ids
+}
+
+// Request 申请紧急平仓（ids为空表示全部账号），返回确认码
+func (s *Switch) Request(ids []string, source string) (*Confirmation, error) {
+	accounts, err := s.selectAccounts(ids)
+	if err != nil {
+		return nil, err
+	}
+	code, err := confirmCode()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Confirmation{Code: code, Source: source, ExpiresAt: time.Now().Add(ConfirmTTL)}
+	for _, a := range accounts {
+		c.Accounts = append(c.Accounts, a.ID)
+	}
+
+	s.mu.Lock()
+	for k, p := range s.pending {
+		if time.Now().After(p.ExpiresAt) {
+			delete(s.pending, k)
+		}
+	}
+	s.pending[code] = c
+	s.mu.Unlock()
+
+	utils.Warn("收到紧急平仓申请，等待确认",
+		zap.String("source", source),
+		zap.Strings("accounts", c.Accounts),
+		zap.Time("expires_at", c.ExpiresAt),
+	)
+	return c, nil
+}
+
+// Confirm 用确认码确认并执行（确认码只能使用一次）
+func (s *Switch) Confirm(code, source string) (*Record, error) {
+	s.mu.Lock()
+	c, ok := s.pending[code]
+	delete(s.pending, code)
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("确认码无效或已使用: %s", code)
+	}
+	if time.Now().After(c.ExpiresAt) {
+		return nil, fmt.Errorf("确认码已过期: %s", code)
+	}
+	return s.Execute(c.Accounts, source)
+}
+
+// Execute 撤销所选账号的全部挂单并市价平掉全部持仓（ids为空表示全部账号），各账号依次执行
+func (s *Switch) Execute(ids []string, source string) (*Record, error) {
+	accounts, err := s.selectAccounts(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &Record{Time: time.Now(), Source: source}
+	utils.Warn("开始紧急平仓", zap.String("source", source), zap.Int("accounts", len(accounts)))
+	for _, a := range accounts {
+		record.Accounts = append(record.Accounts, a.ID)
+		closed, err := a.CloseAll()
+		res := Result{AccountID: a.ID, Closed: closed}
+		if err != nil {
+			res.Error = err.Error()
+			utils.Error("紧急平仓失败", zap.String("account_id", a.ID), zap.Strings("closed", closed), zap.Error(err))
+		} else {
+			utils.Warn("紧急平仓完成", zap.String("account_id", a.ID), zap.Strings("closed", closed))
+		}
+		record.Results = append(record.Results, res)
+	}
+	record.DurationMs = time.Since(record.Time).Milliseconds()
+
+	if err := s.append(record); err != nil {
+		utils.Error("写入紧急平仓记录失败", zap.String("path", s.path), zap.Error(err))
+	}
+	s.mu.Lock()
+	notify := s.notify
+	s.mu.Unlock()
+	if notify != nil {
+		notify(record)
+	}
+	return record, nil
+}
+
+// selectAccounts 按ID选择账号（ids为空表示全部账号，有未注册的账号时返回错误）
+func (s *Switch) selectAccounts(ids []string) ([]Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.accounts) == 0 {
+		return nil, fmt.Errorf("没有可紧急平仓的账号")
+	}
+	if len(ids) == 0 {
+		return slices.Clone(s.accounts), nil
+	}
+	selected := make([]Account, 0, len(ids))
+	for _, id := range ids {
+		i := slices.IndexFunc(s.accounts, func(a Account) bool { return a.ID == id })
+		if i < 0 {
+			return nil, fmt.Errorf("账号不存在或不支持紧急平仓: %s", id)
+		}
+		if !slices.ContainsFunc(selected, func(a Account) bool { return a.ID == id }) {
+			selected = append(selected, s.accounts[i])
+		}
+	}
+	return selected, nil
+}
+
+// append 追加写入一条记录
+func (s *Switch) append(record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("序列化记录失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开记录文件失败: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// confirmCode 生成6位数字确认码
+func confirmCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("生成确认码失败: %w", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
@@ -0,0 +1,306 @@
+/*
+Package exchange 币安实现
+
+主要功能：
+- NewBinance(client *binance.Client) *Binance  // 包装币安客户端（U本位、币本位、现货均可）
+- (b *Binance) Client() *binance.Client        // 获取底层币安客户端（执行器等币安专用功能使用）
+- NewBinanceLiquidations(stream *binance.LiquidationStream) *BinanceLiquidations  // 包装币安强平推送（实现 LiquidationData）
+*/
+package exchange
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+
+	"crypto-ai-trader/binance"
+)
+
+// Binance 币安交易所实现
+type Binance struct {
+	client *binance.Client
+
+	rulesMu sync.RWMutex
+	rules   map[string]*binance.SymbolInfo // 交易规则缓存
+}
+
+// NewBinance 包装币安客户端
+func NewBinance(client *binance.Client) *Binance {
+	return &Binance{
+		client: client,
+		rules:  make(map[string]*binance.SymbolInfo),
+	}
+}
+
+// Client 获取底层币安客户端
+func (b *Binance) Client() *binance.Client {
+	return b.client
+}
+
+// Name 交易所名称
+func (b *Binance) Name() string {
+	return NameBinance
+}
+
+// HasDerivativesData 现货没有持仓量和资金费率
+func (b *Binance) HasDerivativesData() bool {
+	return !b.client.IsSpot()
+}
+
+// GetKlines 获取K线
+func (b *Binance) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+	return b.client.GetKlines(symbol, interval, limit)
+}
+
+// GetOpenInterest 获取持仓量
+func (b *Binance) GetOpenInterest(symbol string) (float64, error) {
+	if !b.HasDerivativesData() {
+		return 0, ErrUnsupported
+	}
+
+	oi, err := b.client.GetOpenInterest(symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.ParseFloat(oi.OpenInterest, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析持仓量失败: %w", err)
+	}
+	return value, nil
+}
+
+// GetFundingRate 获取当前资金费率
+func (b *Binance) GetFundingRate(symbol string) (float64, error) {
+	if !b.HasDerivativesData() {
+		return 0, ErrUnsupported
+	}
+
+	premium, err := b.client.GetPremiumIndex(symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	rate, err := strconv.ParseFloat(premium.LastFundingRate, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析资金费率失败: %w", err)
+	}
+	return rate, nil
+}
+
+// GetFundingRateHistory 获取最近limit次资金费率（从旧到新）
+func (b *Binance) GetFundingRateHistory(symbol string, limit int) ([]float64, error) {
+	if !b.HasDerivativesData() {
+		return nil, ErrUnsupported
+	}
+
+	history, err := b.client.GetFundingRateHistory(symbol, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]float64, 0, len(history))
+	for _, fr := range history {
+		rates = append(rates, fr.RateFloat())
+	}
+	return rates, nil
+}
+
+// GetBalance 获取资产余额
+func (b *Binance) GetBalance(asset string) (*Balance, error) {
+	if b.client.IsSpot() {
+		total, err := b.client.GetSpotAssetBalance(asset)
+		if err != nil {
+			return nil, err
+		}
+		return &Balance{Asset: asset, Total: total, Available: total}, nil
+	}
+
+	balance, err := b.client.GetAssetBalance(asset)
+	if err != nil {
+		return nil, err
+	}
+	return &Balance{
+		Asset:         asset,
+		Total:         parseFloat(balance.Balance),
+		Available:     parseFloat(balance.AvailableBalance),
+		UnrealizedPnL: parseFloat(balance.UnrealizedProfit),
+	}, nil
+}
+
+// GetPositions 获取全部非零持仓（现货没有合约持仓）
+func (b *Binance) GetPositions() ([]Position, error) {
+	if b.client.IsSpot() {
+		return nil, ErrUnsupported
+	}
+
+	risks, err := b.client.GetPositionRisk("")
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]Position, 0, len(risks))
+	for _, risk := range risks {
+		qty := risk.Quantity()
+		if qty == 0 {
+			continue
+		}
+		mark := risk.MarkPriceFloat()
+
+		// 名义价值：U本位为 数量×标记价格，币本位为 张数×合约面值
+		notional := math.Abs(qty) * mark
+		if b.client.IsCoinM() {
+			rules, err := b.symbolRules(risk.Symbol)
+			if err != nil {
+				return nil, err
+			}
+			notional = rules.Notional(math.Abs(qty), mark)
+		}
+
+		positions = append(positions, Position{
+			Symbol:        risk.Symbol,
+			Quantity:      qty,
+			EntryPrice:    risk.EntryPriceFloat(),
+			MarkPrice:     mark,
+			Notional:      notional,
+			UnrealizedPnL: risk.UnrealizedPnL(),
+			Leverage:      risk.LeverageInt(),
+		})
+	}
+	return positions, nil
+}
+
+// PlaceOrder 下单（数量和价格按交易规则格式化）
+// 币安下单不能附带止损止盈，括号订单由 executor 处理
+func (b *Binance) PlaceOrder(req *OrderRequest) (*Order, error) {
+	if req.StopLoss > 0 || req.TakeProfit > 0 {
+		return nil, fmt.Errorf("%w: 币安下单不能附带止损止盈", ErrUnsupported)
+	}
+	rules, err := b.symbolRules(req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &binance.OrderRequest{
+		Symbol:           req.Symbol,
+		Side:             req.Side,
+		Type:             req.Type,
+		Quantity:         rules.FormatQuantity(req.Quantity),
+		ReduceOnly:       req.ReduceOnly,
+		NewClientOrderID: req.ClientOrderID,
+	}
+	if req.Type == OrderTypeLimit {
+		order.Price = rules.FormatPrice(req.Price)
+		order.TimeInForce = binance.TimeInForceGTC
+		if req.PostOnly {
+			order.TimeInForce = binance.TimeInForceGTX
+		}
+	}
+
+	placed, err := b.client.PlaceOrder(order)
+	if err != nil {
+		return nil, err
+	}
+	return fromBinanceOrder(placed), nil
+}
+
+// GetOrder 查询订单
+func (b *Binance) GetOrder(symbol, orderID string) (*Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("订单ID无效: %s", orderID)
+	}
+
+	order, err := b.client.GetOrder(symbol, id)
+	if err != nil {
+		return nil, err
+	}
+	return fromBinanceOrder(order), nil
+}
+
+// CancelOrder 撤销订单
+func (b *Binance) CancelOrder(symbol, orderID string) error {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("订单ID无效: %s", orderID)
+	}
+
+	_, err = b.client.CancelOrder(symbol, id)
+	return err
+}
+
+// symbolRules 获取交易规则（缓存未命中时重新加载）
+func (b *Binance) symbolRules(symbol string) (*binance.SymbolInfo, error) {
+	b.rulesMu.RLock()
+	rules, ok := b.rules[symbol]
+	b.rulesMu.RUnlock()
+	if ok {
+		return rules, nil
+	}
+
+	info, err := b.client.GetExchangeInfo()
+	if err != nil {
+		return nil, fmt.Errorf("加载交易规则失败: %w", err)
+	}
+	rules = info.GetSymbol(symbol)
+	if rules == nil {
+		return nil, fmt.Errorf("未找到交易对规则: %s", symbol)
+	}
+
+	b.rulesMu.Lock()
+	b.rules[symbol] = rules
+	b.rulesMu.Unlock()
+	return rules, nil
+}
+
+// fromBinanceOrder 币安订单转通用订单
+func fromBinanceOrder(o *binance.Order) *Order {
+	status := o.Status
+	switch status {
+	case binance.OrderStatusRejected, binance.OrderStatusExpired:
+		status = OrderStatusCanceled
+	}
+
+	return &Order{
+		ID:            strconv.FormatInt(o.OrderID, 10),
+		ClientOrderID: o.ClientOrderID,
+		Symbol:        o.Symbol,
+		Side:          o.Side,
+		Status:        status,
+		Quantity:      parseFloat(o.OrigQty),
+		FilledQty:     o.ExecutedQtyFloat(),
+		AvgPrice:      o.AvgPriceFloat(),
+	}
+}
+
+// parseFloat 解析数字字符串（解析失败返回0）
+func parseFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+// BinanceLiquidations 币安强平推送的强平统计
+type BinanceLiquidations struct {
+	stream *binance.LiquidationStream
+}
+
+// NewBinanceLiquidations 包装币安强平推送
+func NewBinanceLiquidations(stream *binance.LiquidationStream) *BinanceLiquidations {
+	return &BinanceLiquidations{stream: stream}
+}
+
+// GetLiquidations 获取交易对各滚动窗口的强平统计
+func (l *BinanceLiquidations) GetLiquidations(symbol string) ([]LiquidationWindow, bool, error) {
+	stats := l.stream.Stats(symbol)
+	windows := make([]LiquidationWindow, len(stats.Windows))
+	for i, w := range stats.Windows {
+		windows[i] = LiquidationWindow{
+			Minutes:   w.Minutes,
+			LongUSDT:  w.LongNotional,
+			ShortUSDT: w.ShortNotional,
+			Count:     w.Count,
+		}
+	}
+	return windows, stats.Cascade, nil
+}
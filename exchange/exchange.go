@@ -0,0 +1,155 @@
+/*
+Package exchange 交易所抽象层
+
+主要功能：
+- MarketData   // 行情接口（K线、持仓量、资金费率），指标计算和策略只依赖该接口
+- LiquidationData // 强平统计（可选接口，行情接口同时实现时指标计算附加强平数据）
+- Trading      // 交易接口（余额、持仓、下单、查单、撤单）
+- Exchange     // 完整交易所接口（MarketData + Trading），实现见 NewBinance / NewOKX
+- (o *Order) IsFinal() bool  // 订单是否已结束
+
+约定：
+- 交易对统一使用币安格式（如 BTCUSDT），各实现内部转换为交易所格式（如OKX的 BTC-USDT-SWAP）
+- K线周期统一使用币安格式（1m、5m、1h、4h、1d），K线按时间从旧到新排列
+- 数量统一为标的资产数量（OKX张数由实现按合约面值换算；币安币本位合约与binance包一致，为张数）
+- 资金费率为小数（0.0001 表示 0.01%）
+*/
+package exchange
+
+import (
+	"errors"
+
+	"crypto-ai-trader/binance"
+)
+
+// 交易所名称
+const (
+	NameBinance = "binance"
+	NameOKX     = "okx"
+)
+
+// 订单方向
+const (
+	SideBuy  = "BUY"
+	SideSell = "SELL"
+)
+
+// 订单类型
+const (
+	OrderTypeMarket = "MARKET"
+	OrderTypeLimit  = "LIMIT"
+)
+
+// 订单状态（与币安一致，其他交易所的状态由实现转换）
+const (
+	OrderStatusNew             = "NEW"
+	OrderStatusPartiallyFilled = "PARTIALLY_FILLED"
+	OrderStatusFilled          = "FILLED"
+	OrderStatusCanceled        = "CANCELED"
+)
+
+// ErrUnsupported 交易所或市场类型不支持该操作（如现货没有持仓量和资金费率）
+var ErrUnsupported = errors.New("交易所不支持该操作")
+
+// Kline K线数据（沿用币安的K线结构，指标计算直接使用）
+type Kline = binance.Kline
+
+// Balance 资产余额
+type Balance struct {
+	Asset         string  // 资产（如USDT）
+	Total         float64 // 总余额（钱包余额，不含未实现盈亏）
+	Available     float64 // 可用余额
+	UnrealizedPnL float64 // 未实现盈亏
+}
+
+// Position 合约持仓
+type Position struct {
+	Symbol        string  // 交易对（币安格式）
+	Quantity      float64 // 持仓数量（多为正，空为负）
+	EntryPrice    float64 // 开仓均价
+	MarkPrice     float64 // 标记价格
+	Notional      float64 // 名义价值（美元计，取绝对值）
+	UnrealizedPnL float64 // 未实现盈亏
+	Leverage      int     // 杠杆倍数
+}
+
+// OrderRequest 下单请求
+type OrderRequest struct {
+	Symbol        string  // 交易对（币安格式）
+	Side          string  // BUY 或 SELL
+	Type          string  // MARKET 或 LIMIT
+	Quantity      float64 // 数量（实现按交易所精度向下取整）
+	Price         float64 // 限价单价格
+	PostOnly      bool    // 只做Maker（仅限价单）
+	ReduceOnly    bool    // 只减仓
+	ClientOrderID string  // 客户端订单ID（可选）
+	StopLoss      float64 // 附带的止损触发价（可选，成交后由交易所挂出，触发后市价平仓）
+	TakeProfit    float64 // 附带的止盈触发价（可选）
+}
+
+// Order 订单
+type Order struct {
+	ID            string  // 交易所订单ID
+	ClientOrderID string  // 客户端订单ID
+	Symbol        string  // 交易对（币安格式）
+	Side          string  // BUY 或 SELL
+	Status        string  // 订单状态（OrderStatus*）
+	Quantity      float64 // 委托数量
+	FilledQty     float64 // 已成交数量
+	AvgPrice      float64 // 成交均价
+}
+
+// IsFinal 订单是否已结束
+func (o *Order) IsFinal() bool {
+	return o.Status == OrderStatusFilled || o.Status == OrderStatusCanceled
+}
+
+// MarketData 行情接口
+type MarketData interface {
+	// Name 交易所名称
+	Name() string
+	// HasDerivativesData 是否有持仓量和资金费率（现货为false）
+	HasDerivativesData() bool
+	// GetKlines 获取K线（从旧到新）
+	GetKlines(symbol, interval string, limit int) ([]Kline, error)
+	// GetOpenInterest 获取持仓量（标的数量）
+	GetOpenInterest(symbol string) (float64, error)
+	// GetFundingRate 获取当前资金费率
+	GetFundingRate(symbol string) (float64, error)
+	// GetFundingRateHistory 获取最近limit次已结算的资金费率（从旧到新）
+	GetFundingRateHistory(symbol string, limit int) ([]float64, error)
+}
+
+// LiquidationWindow 滚动窗口内的强平统计
+type LiquidationWindow struct {
+	Minutes   int     // 窗口长度（分钟）
+	LongUSDT  float64 // 多头被强平的名义价值
+	ShortUSDT float64 // 空头被强平的名义价值
+	Count     int     // 强平订单数
+}
+
+// LiquidationData 强平统计
+type LiquidationData interface {
+	// GetLiquidations 获取交易对各滚动窗口的强平统计，以及交易对或全市场是否处于连环强平中
+	GetLiquidations(symbol string) ([]LiquidationWindow, bool, error)
+}
+
+// Trading 交易接口
+type Trading interface {
+	// GetBalance 获取资产余额
+	GetBalance(asset string) (*Balance, error)
+	// GetPositions 获取全部非零持仓
+	GetPositions() ([]Position, error)
+	// PlaceOrder 下单
+	PlaceOrder(req *OrderRequest) (*Order, error)
+	// GetOrder 查询订单
+	GetOrder(symbol, orderID string) (*Order, error)
+	// CancelOrder 撤销订单
+	CancelOrder(symbol, orderID string) error
+}
+
+// Exchange 完整交易所接口
+type Exchange interface {
+	MarketData
+	Trading
+}
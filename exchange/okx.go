@@ -0,0 +1,302 @@
+/*
+Package exchange OKX永续合约实现
+
+主要功能：
+- NewOKX(client *okx.Client) *OKX  // 包装OKX客户端（U本位永续合约，全仓）
+- (o *OKX) Client() *okx.Client    // 获取底层OKX客户端
+
+OKX以张为单位下单和返回持仓，实现按合约面值（ctVal）与标的数量互相换算。
+*/
+package exchange
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"unicode"
+
+	"crypto-ai-trader/okx"
+)
+
+// OKX客户端订单ID最长32位，只能包含字母和数字
+const okxClientOrderIDMaxLen = 32
+
+// okxMarketPx 止盈止损委托价为-1时触发后按市价成交
+const okxMarketPx = "-1"
+
+// OKX OKX交易所实现
+type OKX struct {
+	client *okx.Client
+	tdMode string // 交易模式（全仓）
+
+	instMu      sync.RWMutex
+	instruments map[string]*okx.Instrument // 合约信息缓存（instID → 合约）
+}
+
+// NewOKX 包装OKX客户端
+func NewOKX(client *okx.Client) *OKX {
+	return &OKX{
+		client:      client,
+		tdMode:      okx.TdModeCross,
+		instruments: make(map[string]*okx.Instrument),
+	}
+}
+
+// Client 获取底层OKX客户端
+func (o *OKX) Client() *okx.Client {
+	return o.client
+}
+
+// Name 交易所名称
+func (o *OKX) Name() string {
+	return NameOKX
+}
+
+// HasDerivativesData 永续合约有持仓量和资金费率
+func (o *OKX) HasDerivativesData() bool {
+	return true
+}
+
+// GetKlines 获取K线（转换为币安K线结构）
+func (o *OKX) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+	candles, err := o.client.GetCandles(okx.InstID(symbol), interval, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	klines := make([]Kline, 0, len(candles))
+	for _, c := range candles {
+		klines = append(klines, Kline{
+			OpenTime:         c.OpenTime,
+			Open:             c.Open,
+			High:             c.High,
+			Low:              c.Low,
+			Close:            c.Close,
+			Volume:           c.Volume,
+			CloseTime:        c.CloseTime,
+			QuoteAssetVolume: c.QuoteVolume,
+		})
+	}
+	return klines, nil
+}
+
+// GetOpenInterest 获取持仓量（标的币种数量）
+func (o *OKX) GetOpenInterest(symbol string) (float64, error) {
+	oi, err := o.client.GetOpenInterest(okx.InstID(symbol))
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.ParseFloat(oi.OiCcy, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析持仓量失败: %w", err)
+	}
+	return value, nil
+}
+
+// GetFundingRate 获取当前资金费率
+func (o *OKX) GetFundingRate(symbol string) (float64, error) {
+	rate, err := o.client.GetFundingRate(okx.InstID(symbol))
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.ParseFloat(rate.FundingRate, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析资金费率失败: %w", err)
+	}
+	return value, nil
+}
+
+// GetFundingRateHistory 获取最近limit次已结算的资金费率（OKX从新到旧返回，转换为从旧到新）
+func (o *OKX) GetFundingRateHistory(symbol string, limit int) ([]float64, error) {
+	history, err := o.client.GetFundingRateHistory(okx.InstID(symbol), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]float64, len(history))
+	for i, fr := range history {
+		rates[len(history)-1-i] = fr.RateFloat()
+	}
+	return rates, nil
+}
+
+// GetBalance 获取资产余额
+func (o *OKX) GetBalance(asset string) (*Balance, error) {
+	detail, err := o.client.GetBalance(asset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Balance{
+		Asset:         asset,
+		Total:         parseFloat(detail.CashBal),
+		Available:     parseFloat(detail.AvailBal),
+		UnrealizedPnL: parseFloat(detail.Upl),
+	}, nil
+}
+
+// GetPositions 获取全部非零持仓（张数换算为标的数量）
+func (o *OKX) GetPositions() ([]Position, error) {
+	okxPositions, err := o.client.GetPositions("")
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]Position, 0, len(okxPositions))
+	for _, pos := range okxPositions {
+		inst, err := o.instrument(pos.InstID)
+		if err != nil {
+			return nil, err
+		}
+		leverage, _ := strconv.Atoi(pos.Lever)
+		qty := pos.Contracts() * inst.CtValFloat()
+		positions = append(positions, Position{
+			Symbol:        okx.Symbol(pos.InstID),
+			Quantity:      qty,
+			EntryPrice:    parseFloat(pos.AvgPx),
+			MarkPrice:     parseFloat(pos.MarkPx),
+			Notional:      math.Abs(qty) * parseFloat(pos.MarkPx),
+			UnrealizedPnL: parseFloat(pos.Upl),
+			Leverage:      leverage,
+		})
+	}
+	return positions, nil
+}
+
+// PlaceOrder 下单（标的数量换算为张数后按lotSz向下取整）
+func (o *OKX) PlaceOrder(req *OrderRequest) (*Order, error) {
+	instID := okx.InstID(req.Symbol)
+	inst, err := o.instrument(instID)
+	if err != nil {
+		return nil, err
+	}
+
+	contracts := inst.ContractsForQuantity(req.Quantity)
+	if contracts < parseFloat(inst.MinSz) {
+		return nil, fmt.Errorf("下单数量小于最小下单张数: qty=%v contracts=%v min_sz=%s", req.Quantity, contracts, inst.MinSz)
+	}
+
+	order := &okx.OrderRequest{
+		InstID:     instID,
+		TdMode:     o.tdMode,
+		Side:       okx.SideBuy,
+		OrdType:    okx.OrdTypeMarket,
+		Sz:         inst.FormatSize(contracts),
+		ClOrdID:    okxClientOrderID(req.ClientOrderID),
+		ReduceOnly: req.ReduceOnly,
+	}
+	if req.Side == SideSell {
+		order.Side = okx.SideSell
+	}
+	if req.Type == OrderTypeLimit {
+		order.OrdType = okx.OrdTypeLimit
+		if req.PostOnly {
+			order.OrdType = okx.OrdTypePostOnly
+		}
+		order.Px = inst.FormatPrice(req.Price)
+	}
+	// 止损止盈随订单附带，成交后由交易所挂出，触发后市价平仓
+	if req.StopLoss > 0 || req.TakeProfit > 0 {
+		var algo okx.AttachAlgoOrd
+		if req.StopLoss > 0 {
+			algo.SlTriggerPx, algo.SlOrdPx = inst.FormatPrice(req.StopLoss), okxMarketPx
+		}
+		if req.TakeProfit > 0 {
+			algo.TpTriggerPx, algo.TpOrdPx = inst.FormatPrice(req.TakeProfit), okxMarketPx
+		}
+		order.AttachAlgoOrds = []okx.AttachAlgoOrd{algo}
+	}
+
+	placed, err := o.client.PlaceOrder(order)
+	if err != nil {
+		return nil, err
+	}
+	return fromOKXOrder(placed, inst), nil
+}
+
+// GetOrder 查询订单
+func (o *OKX) GetOrder(symbol, orderID string) (*Order, error) {
+	instID := okx.InstID(symbol)
+	inst, err := o.instrument(instID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := o.client.GetOrder(instID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return fromOKXOrder(order, inst), nil
+}
+
+// CancelOrder 撤销订单
+func (o *OKX) CancelOrder(symbol, orderID string) error {
+	return o.client.CancelOrder(okx.InstID(symbol), orderID)
+}
+
+// instrument 获取合约信息（缓存）
+func (o *OKX) instrument(instID string) (*okx.Instrument, error) {
+	o.instMu.RLock()
+	inst, ok := o.instruments[instID]
+	o.instMu.RUnlock()
+	if ok {
+		return inst, nil
+	}
+
+	inst, err := o.client.GetInstrument(instID)
+	if err != nil {
+		return nil, err
+	}
+
+	o.instMu.Lock()
+	o.instruments[instID] = inst
+	o.instMu.Unlock()
+	return inst, nil
+}
+
+// fromOKXOrder OKX订单转通用订单（张数换算为标的数量）
+func fromOKXOrder(order *okx.Order, inst *okx.Instrument) *Order {
+	status := OrderStatusNew
+	switch order.State {
+	case okx.StatePartiallyFilled:
+		status = OrderStatusPartiallyFilled
+	case okx.StateFilled:
+		status = OrderStatusFilled
+	case okx.StateCanceled, okx.StateMMPCanceled:
+		status = OrderStatusCanceled
+	}
+
+	side := SideBuy
+	if order.Side == okx.SideSell {
+		side = SideSell
+	}
+
+	ctVal := inst.CtValFloat()
+	return &Order{
+		ID:            order.OrdID,
+		ClientOrderID: order.ClOrdID,
+		Symbol:        okx.Symbol(order.InstID),
+		Side:          side,
+		Status:        status,
+		Quantity:      parseFloat(order.Sz) * ctVal,
+		FilledQty:     parseFloat(order.AccFillSz) * ctVal,
+		AvgPrice:      parseFloat(order.AvgPx),
+	}
+}
+
+// okxClientOrderID 去掉客户端订单ID中的非字母数字字符并截断到32位
+func okxClientOrderID(id string) string {
+	cleaned := make([]rune, 0, len(id))
+	for _, r := range id {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			cleaned = append(cleaned, r)
+		}
+	}
+	if len(cleaned) > okxClientOrderIDMaxLen {
+		cleaned = cleaned[:okxClientOrderIDMaxLen]
+	}
+	return string(cleaned)
+}
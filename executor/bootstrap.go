@@ -0,0 +1,199 @@
+/*
+Package executor 启动时检查账户持仓设置
+
+主要功能：
+- (e *Executor) Bootstrap(symbols []string, cfg config.PositionConfig) (*BootstrapReport, error)  // 检查持仓模式、保证金模式、杠杆并按配置修正
+- (r *BootstrapReport) Failed() bool                                                            // 是否存在未修正的不一致
+- (r *BootstrapReport) String() string                                                          // 报告文本（每项一行）
+
+检查项：
+1. 持仓模式：执行器按单向持仓下单（positionSide为BOTH），双向持仓时必须改为单向
+2. 保证金模式：每个交易对与 margin_type 一致
+3. 杠杆：每个交易对与 leverage 一致
+
+有持仓或挂单时交易所会拒绝更改持仓模式和保证金模式，修正失败的项保留在报告中。
+*/
+package executor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 检查项名称
+const (
+	SettingPositionMode = "position_mode"
+	SettingMarginType   = "margin_type"
+	SettingLeverage     = "leverage"
+)
+
+// 持仓模式
+const (
+	positionModeOneWay = "one_way"
+	positionModeHedge  = "hedge"
+)
+
+// SettingMismatch 一项不一致的设置
+type SettingMismatch struct {
+	Symbol   string // 交易对（持仓模式为账户级设置，为空）
+	Setting  string // 检查项
+	Expected string // 配置值
+	Actual   string // 交易所当前值
+	Fixed    bool   // 是否已修正
+	Error    string // 修正失败的原因
+}
+
+// BootstrapReport 持仓设置检查报告
+type BootstrapReport struct {
+	AccountID  string
+	Checked    int               // 检查的交易对数量
+	Mismatches []SettingMismatch // 不一致的设置
+}
+
+// Failed 是否存在未修正的不一致
+func (r *BootstrapReport) Failed() bool {
+	for _, m := range r.Mismatches {
+		if !m.Fixed {
+			return true
+		}
+	}
+	return false
+}
+
+// String 报告文本（每项一行）
+func (r *BootstrapReport) String() string {
+	if len(r.Mismatches) == 0 {
+		return fmt.Sprintf("账号[%s]持仓设置检查通过（%d个交易对）", r.AccountID, r.Checked)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "账号[%s]持仓设置不一致（%d项）:", r.AccountID, len(r.Mismatches))
+	for _, m := range r.Mismatches {
+		target := m.Symbol
+		if target == "" {
+			target = "账户"
+		}
+		status := "未修正"
+		if m.Fixed {
+			status = "已修正"
+		} else if m.Error != "" {
+			status = "修正失败: " + m.Error
+		}
+		fmt.Fprintf(&b, "\n  %s %s: 配置=%s 实际=%s [%s]", target, m.Setting, m.Expected, m.Actual, status)
+	}
+	return b.String()
+}
+
+// Bootstrap 检查持仓模式、保证金模式、杠杆
+// on_mismatch 为 fix 时自动修正；abort 时不修正，存在不一致即返回错误；warn 时只记录警告
+// 现货账户没有这些设置，直接返回空报告
+func (e *Executor) Bootstrap(symbols []string, cfg config.PositionConfig) (*BootstrapReport, error) {
+	report := &BootstrapReport{AccountID: e.accountID}
+	if e.client.IsSpot() {
+		return report, nil
+	}
+	fix := cfg.OnMismatch == config.OnMismatchFix
+
+	// 1. 持仓模式（账户级）
+	dualSide, err := e.client.GetPositionMode()
+	if err != nil {
+		return nil, err
+	}
+	if dualSide {
+		m := SettingMismatch{Setting: SettingPositionMode, Expected: positionModeOneWay, Actual: positionModeHedge}
+		if fix {
+			e.applyFix(&m, func() error { return e.client.SetPositionMode(false) })
+		}
+		report.Mismatches = append(report.Mismatches, m)
+	}
+
+	// 2. 保证金模式和杠杆（交易对级，配置了分级杠杆上限时也需要检查）
+	if cfg.MarginType != "" || cfg.Leverage > 0 || e.hasLeverageCaps() {
+		risks, err := e.client.GetPositionRisk("")
+		if err != nil {
+			return nil, err
+		}
+		current := make(map[string]binance.PositionRisk, len(risks))
+		for _, risk := range risks {
+			if _, ok := current[risk.Symbol]; !ok {
+				current[risk.Symbol] = risk
+			}
+		}
+
+		for _, symbol := range symbols {
+			risk, ok := current[symbol]
+			if !ok {
+				utils.Warn("持仓风险中没有该交易对，跳过持仓设置检查",
+					zap.String("account_id", e.accountID),
+					zap.String("symbol", symbol),
+				)
+				continue
+			}
+			report.Checked++
+			report.Mismatches = append(report.Mismatches, e.checkSymbolSettings(symbol, risk, cfg, fix)...)
+		}
+	}
+
+	if report.Failed() {
+		if cfg.OnMismatch != config.OnMismatchWarn {
+			return report, fmt.Errorf("持仓设置与配置不一致:\n%s", report.String())
+		}
+		utils.Warn(report.String())
+	} else {
+		utils.Info(report.String())
+	}
+
+	return report, nil
+}
+
+// checkSymbolSettings 检查单个交易对的保证金模式和杠杆
+// 先改保证金模式再调杠杆（逐仓、全仓的最大杠杆可能不同）
+func (e *Executor) checkSymbolSettings(symbol string, risk binance.PositionRisk, cfg config.PositionConfig, fix bool) []SettingMismatch {
+	var mismatches []SettingMismatch
+
+	if actual := binance.NormalizeMarginType(risk.MarginType); cfg.MarginType != "" && actual != cfg.MarginType {
+		m := SettingMismatch{Symbol: symbol, Setting: SettingMarginType, Expected: cfg.MarginType, Actual: actual}
+		if fix {
+			e.applyFix(&m, func() error { return e.client.SetMarginType(symbol, cfg.MarginType) })
+		}
+		mismatches = append(mismatches, m)
+	}
+
+	// 目标杠杆不超过交易对分级上限；未配置杠杆时只在超过上限时调低
+	actual := risk.LeverageInt()
+	leverage := e.capLeverage(symbol, cfg.Leverage)
+	if leverage == 0 && e.capLeverage(symbol, actual) < actual {
+		leverage = e.capLeverage(symbol, actual)
+	}
+	if leverage > 0 && actual != leverage {
+		m := SettingMismatch{Symbol: symbol, Setting: SettingLeverage, Expected: strconv.Itoa(leverage), Actual: risk.Leverage}
+		if fix {
+			e.applyFix(&m, func() error { return e.client.SetLeverage(symbol, leverage) })
+		}
+		mismatches = append(mismatches, m)
+	}
+
+	return mismatches
+}
+
+// applyFix 执行修正并记录结果
+func (e *Executor) applyFix(m *SettingMismatch, fix func() error) {
+	if err := fix(); err != nil {
+		m.Error = err.Error()
+		utils.Warn("修正持仓设置失败",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", m.Symbol),
+			zap.String("setting", m.Setting),
+			zap.Error(err),
+		)
+		return
+	}
+	m.Fixed = true
+}
@@ -0,0 +1,517 @@
+/*
+Package executor 括号订单（入场 + 止损 + 止盈）
+
+主要功能：
+- (e *Executor) PlaceBracket(decision *Decision) (*Bracket, error)  // 入场成交后立即挂出只减仓的止损止盈单
+- (e *Executor) CheckBrackets()                                     // 检查所有括号订单，一边触发后撤销另一边（OCO）
+- (e *Executor) Monitor(ctx context.Context, interval time.Duration) // 定时检查括号订单、逐仓保证金和权益回撤
+*/
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// PlaceBracket 按执行配置入场，成交后立即挂出只减仓的止损止盈单
+// 止损单挂出失败时会立即市价平仓，避免出现无保护的持仓；拆单入场时第一笔成交后即挂出止损单
+func (e *Executor) PlaceBracket(decision *Decision) (*Bracket, error) {
+	if err := validateBracketDecision(decision); err != nil {
+		return nil, err
+	}
+	if err := e.checkCloseOnly(decision.Symbol); err != nil {
+		return nil, err
+	}
+	if err := e.checkCascade(decision.Symbol); err != nil {
+		return nil, err
+	}
+	if err := e.checkPremium(decision.Symbol); err != nil {
+		return nil, err
+	}
+
+	side := binance.SideBuy
+	if decision.Action == ActionOpenShort {
+		if e.client.IsSpot() {
+			return nil, fmt.Errorf("现货不支持做空: %s", decision.Symbol)
+		}
+		side = binance.SideSell
+	}
+
+	// 登记持仓逻辑：同方向入场进行中或已有括号订单时视为重复信号
+	if err := e.claimThesis(decision, side); err != nil {
+		return nil, err
+	}
+	e.SyncOrderBookSymbols()
+	opened := false
+	defer func() {
+		if !opened {
+			e.releaseThesis(decision.Symbol)
+		}
+	}()
+
+	rules, err := e.getSymbolRules(decision.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按仓位计算配置确定标的数量（固定模式使用决策数量），风控缩减仓位时乘以数量系数
+	baseQty, err := e.sizeQuantity(decision)
+	if err != nil {
+		return nil, err
+	}
+	if decision.SizeFactor > 0 {
+		baseQty *= decision.SizeFactor
+	}
+
+	// 决策数量为标的数量，币本位合约需换算为合约张数
+	quantity, err := e.orderQuantity(decision.Symbol, baseQty, rules)
+	if err != nil {
+		return nil, err
+	}
+	// 按交易对分级截断名义价值
+	quantity, capNote, err := e.capNotional(decision.Symbol, quantity, 0, rules)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkSectorExposure(decision.Symbol, quantity, rules); err != nil {
+		return nil, err
+	}
+	if formatted := rules.FormatQuantity(quantity); quantity < rules.MinQty || formatted == rules.FormatQuantity(0) {
+		return nil, fmt.Errorf("下单数量过小: %s (最小数量 %v)", formatted, rules.MinQty)
+	}
+
+	// 止损后窗口期内同方向开仓需满足重新入场规则
+	reentry, err := e.checkReentry(decision, side)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.applyDecisionLeverage(decision); err != nil {
+		return nil, err
+	}
+
+	// 1. 按执行配置入场（客户端订单ID由决策哈希确定，重试不会重复开仓）
+	// 拆单入场时第一笔成交后立即挂出止损止盈单，之后每笔成交后按累计数量同步
+	decisionID := decision.Hash()
+	entryStartedAt := time.Now()
+	var bracket *Bracket
+	protect := func(filled *entryResult) error {
+		if bracket != nil {
+			return e.syncSliceExits(bracket, filled, rules)
+		}
+
+		b := &Bracket{
+			AccountID:       e.accountID,
+			Symbol:          decision.Symbol,
+			DecisionID:      decisionID,
+			Side:            side,
+			Quantity:        filled.FilledQty,
+			EntryOrderID:    filled.OrderID,
+			EntryPrice:      filled.AvgPrice,
+			StopLoss:        decision.StopLoss,
+			TakeProfit:      decision.TakeProfit,
+			Status:          BracketStatusActive,
+			ExecutionNote:   filled.Note,
+			Reentry:         reentry,
+			InitialQuantity: filled.FilledQty,
+			LastEntryPrice:  filled.AvgPrice,
+			EntryStartedAt:  entryStartedAt,
+			CreatedAt:       time.Now(),
+		}
+		if capNote != "" {
+			b.ExecutionNote = appendNote(b.ExecutionNote, capNote)
+		}
+		if reentry {
+			b.ExecutionNote = appendNote(b.ExecutionNote, "止损后重新入场")
+		}
+
+		// 2. 挂止损止盈单
+		if err := e.protectEntry(b, rules); err != nil {
+			return err
+		}
+		bracket = b
+		return nil
+	}
+
+	entry, err := e.enter(decision.Symbol, side, quantity, rules, decisionID, protect)
+	if err != nil {
+		return nil, err
+	}
+	if entry.FilledQty <= 0 {
+		return nil, fmt.Errorf("入场订单未成交")
+	}
+	if bracket == nil {
+		if err := protect(entry); err != nil {
+			return nil, err
+		}
+	} else if entry.Note != "" {
+		bracket.ExecutionNote = appendNote(bracket.ExecutionNote, entry.Note)
+	}
+
+	e.mu.Lock()
+	e.brackets[bracket.Symbol] = bracket
+	e.mu.Unlock()
+	e.saveBrackets()
+	e.confirmThesis(bracket.Symbol)
+	opened = true
+	e.recordAction(bracket.Symbol)
+	if reentry {
+		e.clearStopOut(bracket.Symbol)
+	}
+
+	utils.Info("括号订单已建立",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", bracket.Symbol),
+		zap.String("side", bracket.Side),
+		zap.Float64("quantity", bracket.Quantity),
+		zap.Float64("entry_price", bracket.EntryPrice),
+		zap.Float64("stop_loss", bracket.StopLoss),
+		zap.Float64("take_profit", bracket.TakeProfit),
+	)
+
+	return bracket, nil
+}
+
+// protectEntry 入场成交后挂出止损止盈单
+// 成交价已越过止损价或止损单挂出失败时立即市价平仓，止盈单失败时只保留止损单
+func (e *Executor) protectEntry(bracket *Bracket, rules *binance.SymbolInfo) error {
+	// 成交价已越过止损价时，止损单会被交易所拒绝（立即触发），直接平仓
+	if bracket.EntryPrice > 0 && !stopLossValid(bracket) {
+		e.emergencyClose(bracket, "成交价已越过止损价")
+		return fmt.Errorf("成交价 %v 已越过止损价 %v", bracket.EntryPrice, bracket.StopLoss)
+	}
+
+	// 现货买入手续费以标的资产扣除，止损数量按实际到账的余额
+	if e.client.IsSpot() {
+		qty, err := e.spotExitQuantity(bracket.Symbol, bracket.Quantity, rules)
+		if err != nil {
+			e.emergencyClose(bracket, "查询现货余额失败")
+			return err
+		}
+		bracket.Quantity = qty
+	}
+
+	stopOrder, err := e.placeExitLeg(bracket, rules, binance.OrderTypeStopMarket, bracket.StopLoss, bracket.Quantity)
+	if err != nil {
+		reason := "止损单挂出失败"
+		if errors.Is(err, binance.ErrWouldTrigger) {
+			reason = "止损价已被越过（止损单会立即触发）"
+		}
+		e.emergencyClose(bracket, reason)
+		return fmt.Errorf("%s: %w", reason, err)
+	}
+	bracket.StopLossOrderID = stopOrder.OrderID
+
+	// 止盈单失败时保留止损单，持仓仍受保护
+	// 现货的止损单已冻结全部余额，止盈由监控按价格触发（见 checkSpotTakeProfit）
+	if bracket.TakeProfit > 0 && !e.client.IsSpot() {
+		tpOrder, err := e.placeExitLeg(bracket, rules, binance.OrderTypeTakeProfitMarket, bracket.TakeProfit, bracket.Quantity)
+		if err != nil {
+			utils.Warn("止盈单挂出失败，仅保留止损单",
+				zap.String("account_id", e.accountID),
+				zap.String("symbol", bracket.Symbol),
+				zap.Error(err),
+			)
+		} else {
+			bracket.TakeProfitOrderID = tpOrder.OrderID
+		}
+	}
+	return nil
+}
+
+// syncSliceExits 拆单入场后续每笔成交后按累计成交同步止损止盈单
+// 同步失败时新增部分没有止损保护，撤销止损止盈单并市价平掉全部已成交数量
+func (e *Executor) syncSliceExits(bracket *Bracket, filled *entryResult, rules *binance.SymbolInfo) error {
+	// 累计成交按stepSize取整，避免浮点累加误差
+	positionAmt, _ := strconv.ParseFloat(rules.FormatQuantity(filled.FilledQty), 64)
+	if !bracket.IsLong() {
+		positionAmt = -positionAmt
+	}
+
+	if err := e.SyncExitOrders(bracket, positionAmt, filled.AvgPrice); err != nil {
+		e.mu.Lock()
+		bracket.Quantity = filled.FilledQty
+		e.mu.Unlock()
+		// 先撤销剩余的腿（现货的止损单冻结了余额，不撤销无法卖出）
+		e.cancelExitLegs(bracket)
+		e.emergencyClose(bracket, "拆单成交后止损单同步失败")
+		return fmt.Errorf("拆单成交后同步止损止盈单失败，已平仓: %w", err)
+	}
+
+	e.mu.Lock()
+	bracket.InitialQuantity = filled.FilledQty
+	bracket.LastEntryPrice = filled.AvgPrice
+	e.mu.Unlock()
+	return nil
+}
+
+// CheckBrackets 检查所有括号订单，一边触发后撤销另一边（OCO）
+func (e *Executor) CheckBrackets() {
+	defer e.saveBrackets()
+
+	for _, bracket := range e.GetBrackets() {
+		if err := e.checkBracket(bracket); err != nil {
+			utils.Error("检查括号订单失败",
+				zap.String("account_id", e.accountID),
+				zap.String("symbol", bracket.Symbol),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// Monitor 定时检查括号订单、逐仓保证金和权益回撤，并更新本地订单簿的订阅，直到ctx取消
+func (e *Executor) Monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.CheckBrackets()
+			e.CheckMarginTopUp()
+			e.CheckDrawdown()
+			e.SyncOrderBookSymbols()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkBracket 检查单个括号订单
+func (e *Executor) checkBracket(bracket *Bracket) error {
+	stopOrder, err := e.client.GetOrder(bracket.Symbol, bracket.StopLossOrderID)
+	if err != nil {
+		return err
+	}
+
+	var tpOrder *binance.Order
+	if bracket.TakeProfitOrderID != 0 {
+		tpOrder, err = e.client.GetOrder(bracket.Symbol, bracket.TakeProfitOrderID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 止损触发：撤销止盈单
+	if stopOrder.Status == binance.OrderStatusFilled {
+		if tpOrder != nil && !tpOrder.IsFinal() {
+			e.cancelLeg(bracket, bracket.TakeProfitOrderID)
+		}
+		e.closeBracket(bracket, CloseReasonStopLoss)
+		return nil
+	}
+
+	// 现货止盈：价格达到止盈价时撤销止损单并市价卖出
+	if e.client.IsSpot() && !stopOrder.IsFinal() {
+		if hit, err := e.checkSpotTakeProfit(bracket); err != nil || hit {
+			return err
+		}
+	}
+
+	// 止盈触发：撤销止损单
+	if tpOrder != nil && tpOrder.Status == binance.OrderStatusFilled {
+		if !stopOrder.IsFinal() {
+			e.cancelLeg(bracket, bracket.StopLossOrderID)
+		}
+		e.closeBracket(bracket, CloseReasonTakeProfit)
+		return nil
+	}
+
+	// 持仓已被其他方式平掉（手动平仓、强平等）：撤销剩余的腿
+	positionAmt, entryPrice, err := e.getPosition(bracket.Symbol)
+	if err != nil {
+		return err
+	}
+	if positionAmt == 0 {
+		if !stopOrder.IsFinal() {
+			e.cancelLeg(bracket, bracket.StopLossOrderID)
+		}
+		if tpOrder != nil && !tpOrder.IsFinal() {
+			e.cancelLeg(bracket, bracket.TakeProfitOrderID)
+		}
+		e.closeBracket(bracket, CloseReasonPositionClosed)
+		return nil
+	}
+
+	// 部分平仓或加仓：按最新持仓数量重新挂出止损止盈单（执行器自身加仓时由加仓流程同步）
+	e.mu.Lock()
+	adding := bracket.adding
+	e.mu.Unlock()
+	if !adding && e.positionSizeChanged(bracket, positionAmt) {
+		return e.SyncExitOrders(bracket, positionAmt, entryPrice)
+	}
+
+	if stopOrder.IsFinal() {
+		utils.Warn("止损单已失效但仍有持仓",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", bracket.Symbol),
+			zap.String("stop_status", stopOrder.Status),
+			zap.Float64("position_amt", positionAmt),
+		)
+	}
+
+	return nil
+}
+
+// placeExitLeg 挂出括号订单的一条腿（只减仓的止损或止盈单）
+func (e *Executor) placeExitLeg(bracket *Bracket, rules *binance.SymbolInfo, orderType string, stopPrice, quantity float64) (*binance.Order, error) {
+	leg := LegStopLoss
+	if orderType == binance.OrderTypeTakeProfitMarket {
+		leg = LegTakeProfit
+	}
+	qty := rules.FormatQuantity(quantity)
+
+	return e.submitExitOrder(&binance.OrderRequest{
+		Symbol:           bracket.Symbol,
+		Side:             bracket.exitSide(),
+		Type:             orderType,
+		Quantity:         qty,
+		StopPrice:        rules.FormatPrice(stopPrice),
+		WorkingType:      binance.WorkingTypeMarkPrice,
+		NewClientOrderID: e.exitLegID(bracket, leg, qty),
+	})
+}
+
+// exitLegID 止损止盈单的客户端订单ID（按挂单序号区分）
+// 数量同步时可能按之前用过的数量重新挂单（如减仓后又加回原数量），
+// 只用决策和数量生成ID会与已撤销的旧单重复，每次挂单都使用新的序号
+func (e *Executor) exitLegID(bracket *Bracket, leg, qty string) string {
+	e.mu.Lock()
+	bracket.ExitSeq++
+	seq := bracket.ExitSeq
+	e.mu.Unlock()
+
+	return ClientOrderID(leg, e.accountID, bracket.Symbol, bracket.DecisionID, qty, strconv.Itoa(seq))
+}
+
+// cancelExitLegs 撤销括号订单的止损止盈单
+func (e *Executor) cancelExitLegs(bracket *Bracket) {
+	e.cancelLeg(bracket, bracket.StopLossOrderID)
+	if bracket.TakeProfitOrderID != 0 {
+		e.cancelLeg(bracket, bracket.TakeProfitOrderID)
+	}
+}
+
+// cancelLeg 撤销括号订单的一条腿
+func (e *Executor) cancelLeg(bracket *Bracket, orderID int64) {
+	if _, err := e.client.CancelOrder(bracket.Symbol, orderID); err != nil {
+		utils.Warn("撤销括号订单腿失败",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", bracket.Symbol),
+			zap.Int64("order_id", orderID),
+			zap.Error(err),
+		)
+	}
+}
+
+// closeBracket 标记括号订单结束并移除
+func (e *Executor) closeBracket(bracket *Bracket, reason string) {
+	bracket.Status = BracketStatusClosed
+	bracket.CloseReason = reason
+	bracket.ClosedAt = time.Now()
+
+	e.mu.Lock()
+	delete(e.brackets, bracket.Symbol)
+	e.lastActions[bracket.Symbol] = time.Now()
+	e.mu.Unlock()
+	e.saveBrackets()
+	e.releaseThesis(bracket.Symbol)
+
+	utils.Info("括号订单已结束",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", bracket.Symbol),
+		zap.String("reason", reason),
+	)
+
+	if reason == CloseReasonStopLoss {
+		e.recordStopOut(bracket)
+	}
+
+	e.recordTrade(bracket)
+}
+
+// emergencyClose 市价平掉括号订单的持仓（止损无法挂出时使用）
+func (e *Executor) emergencyClose(bracket *Bracket, reason string) {
+	utils.Error("紧急平仓",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", bracket.Symbol),
+		zap.String("reason", reason),
+	)
+
+	rules, err := e.getSymbolRules(bracket.Symbol)
+	if err != nil {
+		utils.Error("紧急平仓失败", zap.String("symbol", bracket.Symbol), zap.Error(err))
+		return
+	}
+
+	quantity := bracket.Quantity
+	if e.client.IsSpot() {
+		if quantity, err = e.spotExitQuantity(bracket.Symbol, quantity, rules); err != nil {
+			utils.Error("紧急平仓失败", zap.String("symbol", bracket.Symbol), zap.Error(err))
+			return
+		}
+	}
+	qty := rules.FormatQuantity(quantity)
+	_, err = e.submitExitOrder(&binance.OrderRequest{
+		Symbol:           bracket.Symbol,
+		Side:             bracket.exitSide(),
+		Type:             binance.OrderTypeMarket,
+		Quantity:         qty,
+		NewClientOrderID: ClientOrderID(LegClose, e.accountID, bracket.Symbol, bracket.DecisionID, qty),
+	})
+	if err != nil {
+		utils.Error("紧急平仓失败", zap.String("symbol", bracket.Symbol), zap.Error(err))
+	}
+}
+
+// orderQuantity 决策数量换算为下单数量（U本位原样返回，币本位按最新价格换算为合约张数）
+func (e *Executor) orderQuantity(symbol string, quantity float64, rules *binance.SymbolInfo) (float64, error) {
+	if rules.ContractSize <= 0 {
+		return quantity, nil
+	}
+
+	price, err := e.referencePrice(symbol)
+	if err != nil {
+		return 0, fmt.Errorf("获取价格失败，无法换算合约张数: %w", err)
+	}
+	return rules.ContractsForQuantity(quantity, price), nil
+}
+
+// validateBracketDecision 验证开仓决策
+func validateBracketDecision(d *Decision) error {
+	if d.Action != ActionOpenLong && d.Action != ActionOpenShort {
+		return fmt.Errorf("括号订单只支持开仓动作: %s", d.Action)
+	}
+	if d.Quantity < 0 {
+		return fmt.Errorf("开仓数量不能为负数")
+	}
+	if d.StopLoss <= 0 {
+		return fmt.Errorf("括号订单必须设置止损价")
+	}
+	if d.TakeProfit > 0 {
+		if d.Action == ActionOpenLong && d.TakeProfit <= d.StopLoss {
+			return fmt.Errorf("做多止盈价必须高于止损价")
+		}
+		if d.Action == ActionOpenShort && d.TakeProfit >= d.StopLoss {
+			return fmt.Errorf("做空止盈价必须低于止损价")
+		}
+	}
+	return nil
+}
+
+// stopLossValid 止损价是否仍在成交价的正确一侧
+func stopLossValid(b *Bracket) bool {
+	if b.IsLong() {
+		return b.StopLoss < b.EntryPrice
+	}
+	return b.StopLoss > b.EntryPrice
+}
@@ -0,0 +1,91 @@
+/*
+Package executor 括号订单持久化
+
+主要功能：
+- (e *Executor) LoadBrackets(stateDir string)  // 设置保存目录并加载上次运行时生效中的括号订单
+
+生效中的括号订单保存在 <stateDir>/<账号ID>.brackets.json，建立、结束括号订单，执行决策、减仓和每次监控检查后保存（内容未变化时不写文件）。
+重启后加载的括号订单由下一次监控检查与交易所核对：止损或止盈单已成交、持仓已被平掉时结束并写入交易日志，
+持仓数量变化时重新挂出止损止盈单，停机期间的出场不会遗漏，出场同步、持仓超时和低流动性时段平仓也覆盖重启前的持仓。
+*/
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// LoadBrackets 设置保存目录并加载上次运行时生效中的括号订单
+func (e *Executor) LoadBrackets(stateDir string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.bracketsPath = filepath.Join(stateDir, e.accountID+".brackets.json")
+
+	data, err := os.ReadFile(e.bracketsPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			utils.Warn("读取括号订单失败", zap.String("account_id", e.accountID), zap.Error(err))
+		}
+		return
+	}
+	var brackets []*Bracket
+	if err := json.Unmarshal(data, &brackets); err != nil {
+		utils.Warn("解析括号订单失败", zap.String("account_id", e.accountID), zap.Error(err))
+		return
+	}
+	e.savedBrackets = data
+
+	for _, b := range brackets {
+		if b.Symbol == "" || b.Status == BracketStatusClosed {
+			continue
+		}
+		e.brackets[b.Symbol] = b
+		utils.Info("加载上次运行的括号订单，等待监控与交易所核对",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", b.Symbol),
+			zap.String("side", b.Side),
+			zap.Float64("quantity", b.Quantity),
+			zap.Int64("stop_loss_order_id", b.StopLossOrderID),
+		)
+	}
+}
+
+// saveBrackets 保存生效中的括号订单（未设置保存目录或内容未变化时不写文件）
+func (e *Executor) saveBrackets() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.bracketsPath == "" {
+		return
+	}
+
+	brackets := make([]*Bracket, 0, len(e.brackets))
+	for _, b := range e.brackets {
+		brackets = append(brackets, b)
+	}
+	sort.Slice(brackets, func(i, j int) bool { return brackets[i].Symbol < brackets[j].Symbol })
+
+	data, err := json.MarshalIndent(brackets, "", "  ")
+	if err == nil && bytes.Equal(data, e.savedBrackets) {
+		return
+	}
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(e.bracketsPath), 0755); err == nil {
+			err = os.WriteFile(e.bracketsPath, data, 0644)
+		}
+	}
+	if err != nil {
+		utils.Warn("保存括号订单失败", zap.String("account_id", e.accountID), zap.Error(err))
+		return
+	}
+	e.savedBrackets = data
+}
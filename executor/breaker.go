@@ -0,0 +1,284 @@
+/*
+Package executor 最大回撤熔断
+
+主要功能：
+- (e *Executor) SetCircuitBreaker(cfg config.CircuitBreakerConfig, stateDir string)  // 设置熔断规则并加载保存的熔断状态
+- (e *Executor) CheckDrawdown()                                                    // 按当前权益更新峰值和回撤，超过上限时进入只平仓模式
+- (e *Executor) CloseOnly() bool                                                   // 是否处于只平仓模式
+- (e *Executor) Rearm() (BreakerStatus, error)                                     // 手动恢复交易（以当前权益作为新的峰值）
+- (e *Executor) BreakerStatus() BreakerStatus                                      // 获取熔断状态
+- (e *Executor) AdjustForTransfer(amount float64)                                  // 资金划转后按划转金额调整峰值（转入为正，转出为负）
+
+权益以USDT计价，由 Monitor 定时采样，回撤 = (峰值 - 当前) / 峰值：
+- U本位合约：USDT钱包余额 + 未实现盈亏
+- 现货：USDT余额 + 其他资产（可用 + 冻结）按最优买价折算，没有USDT交易对的资产不计入
+- 币本位合约：保证金按币种计算，不支持熔断（设置时忽略并记录警告）
+熔断后拒绝所有开仓和加仓决策，平仓决策和已有括号订单的止损止盈照常执行。
+资金划转不是盈亏：划转后峰值和最近权益同步加减划转金额，转出不会被计为回撤，转入也不会抬高峰值。
+峰值和熔断状态保存在 <stateDir>/<账号ID>.breaker.json，重启后不会自动恢复交易。
+*/
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// BreakerStatus 熔断状态（同时作为持久化格式）
+type BreakerStatus struct {
+	MaxDrawdownPct float64    `json:"max_drawdown_pct"`     // 最大回撤上限（%）
+	PeakEquity     float64    `json:"peak_equity"`          // 权益峰值
+	Equity         float64    `json:"equity"`               // 最近一次采样的权益
+	DrawdownPct    float64    `json:"drawdown_pct"`         // 当前回撤（%）
+	CloseOnly      bool       `json:"close_only"`           // 是否处于只平仓模式
+	TrippedAt      *time.Time `json:"tripped_at,omitempty"` // 熔断时间
+	UpdatedAt      time.Time  `json:"updated_at"`           // 最近一次采样时间
+}
+
+// SetCircuitBreaker 设置熔断规则并加载保存的熔断状态
+func (e *Executor) SetCircuitBreaker(cfg config.CircuitBreakerConfig, stateDir string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if cfg.MaxDrawdownPct > 0 && e.client.IsCoinM() {
+		utils.Warn("币本位合约账号不支持最大回撤熔断，已忽略", zap.String("account_id", e.accountID))
+		cfg.MaxDrawdownPct = 0
+	}
+	e.circuitBreaker = cfg
+	e.breakerPath = filepath.Join(stateDir, e.accountID+".breaker.json")
+
+	data, err := os.ReadFile(e.breakerPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			utils.Warn("读取熔断状态失败", zap.String("account_id", e.accountID), zap.Error(err))
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &e.breaker); err != nil {
+		utils.Warn("解析熔断状态失败", zap.String("account_id", e.accountID), zap.Error(err))
+		return
+	}
+	e.breaker.MaxDrawdownPct = cfg.MaxDrawdownPct
+
+	if e.breaker.CloseOnly {
+		utils.Warn("账号处于回撤熔断状态，只允许平仓（通过API恢复交易）",
+			zap.String("account_id", e.accountID),
+			zap.Float64("peak_equity", e.breaker.PeakEquity),
+			zap.Float64("drawdown_pct", e.breaker.DrawdownPct),
+		)
+	}
+}
+
+// CheckDrawdown 按当前权益更新峰值和回撤，超过上限时进入只平仓模式
+func (e *Executor) CheckDrawdown() {
+	e.mu.Lock()
+	maxDD := e.circuitBreaker.MaxDrawdownPct
+	e.mu.Unlock()
+	if maxDD <= 0 {
+		return
+	}
+
+	equity, err := e.equity()
+	if err != nil {
+		utils.Warn("查询权益失败，跳过回撤检查", zap.String("account_id", e.accountID), zap.Error(err))
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	b := &e.breaker
+	b.MaxDrawdownPct = maxDD
+	b.Equity = equity
+	b.UpdatedAt = time.Now()
+	newPeak := equity > b.PeakEquity
+	if newPeak {
+		b.PeakEquity = equity
+	}
+	b.DrawdownPct = 0
+	if b.PeakEquity > 0 {
+		b.DrawdownPct = roundPct((b.PeakEquity - equity) / b.PeakEquity * 100)
+	}
+
+	tripped := !b.CloseOnly && b.DrawdownPct >= maxDD
+	if tripped {
+		now := time.Now()
+		b.CloseOnly = true
+		b.TrippedAt = &now
+		utils.Error("权益回撤超过上限，账号进入只平仓模式",
+			zap.String("account_id", e.accountID),
+			zap.Float64("peak_equity", b.PeakEquity),
+			zap.Float64("equity", equity),
+			zap.Float64("drawdown_pct", b.DrawdownPct),
+			zap.Float64("max_drawdown_pct", maxDD),
+		)
+	}
+
+	// 只在峰值或熔断状态变化时保存
+	if newPeak || tripped {
+		e.saveBreakerLocked()
+	}
+}
+
+// CloseOnly 是否处于只平仓模式
+func (e *Executor) CloseOnly() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.breaker.CloseOnly
+}
+
+// Rearm 手动恢复交易（以当前权益作为新的峰值）
+func (e *Executor) Rearm() (BreakerStatus, error) {
+	equity, err := e.equity()
+	if err != nil {
+		return e.BreakerStatus(), fmt.Errorf("查询权益失败: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	wasCloseOnly := e.breaker.CloseOnly
+	e.breaker.PeakEquity = equity
+	e.breaker.Equity = equity
+	e.breaker.DrawdownPct = 0
+	e.breaker.CloseOnly = false
+	e.breaker.TrippedAt = nil
+	e.breaker.UpdatedAt = time.Now()
+	e.saveBreakerLocked()
+
+	utils.Info("回撤熔断已手动恢复",
+		zap.String("account_id", e.accountID),
+		zap.Bool("was_close_only", wasCloseOnly),
+		zap.Float64("peak_equity", equity),
+	)
+
+	return e.breaker, nil
+}
+
+// BreakerStatus 获取熔断状态
+func (e *Executor) BreakerStatus() BreakerStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	status := e.breaker
+	status.MaxDrawdownPct = e.circuitBreaker.MaxDrawdownPct
+	return status
+}
+
+// AdjustForTransfer 资金划转后按划转金额调整峰值（转入为正，转出为负）
+func (e *Executor) AdjustForTransfer(amount float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	b := &e.breaker
+	if e.circuitBreaker.MaxDrawdownPct <= 0 || b.PeakEquity <= 0 || amount == 0 {
+		return
+	}
+
+	b.PeakEquity = math.Max(b.PeakEquity+amount, 0)
+	b.Equity = math.Max(b.Equity+amount, 0)
+	b.DrawdownPct = 0
+	if b.PeakEquity > 0 {
+		b.DrawdownPct = roundPct((b.PeakEquity - b.Equity) / b.PeakEquity * 100)
+	}
+	e.saveBreakerLocked()
+
+	utils.Info("资金划转，已调整回撤熔断的权益峰值",
+		zap.String("account_id", e.accountID),
+		zap.Float64("amount", amount),
+		zap.Float64("peak_equity", b.PeakEquity),
+	)
+}
+
+// checkCloseOnly 只平仓模式下拒绝开仓和加仓
+func (e *Executor) checkCloseOnly(symbol string) error {
+	if e.CloseOnly() {
+		return fmt.Errorf("账号处于回撤熔断的只平仓模式，拒绝开仓: %s", symbol)
+	}
+	return nil
+}
+
+// equity 当前权益（USDT计价，按市场类型计算）
+func (e *Executor) equity() (float64, error) {
+	switch {
+	case e.client.IsSpot():
+		return e.spotEquity()
+	case e.client.IsCoinM():
+		return 0, fmt.Errorf("%w: 币本位合约的保证金按币种计算，无法汇总为USDT权益", binance.ErrUnsupportedMarket)
+	}
+
+	balance, err := e.client.GetBalance()
+	if err != nil {
+		return 0, err
+	}
+	wallet, _ := strconv.ParseFloat(balance.Balance, 64)
+	unrealized, _ := strconv.ParseFloat(balance.UnrealizedProfit, 64)
+	return wallet + unrealized, nil
+}
+
+// spotEquity 现货权益（USDT余额 + 其他资产按最优买价折算，可用和冻结都计入）
+func (e *Executor) spotEquity() (float64, error) {
+	balances, err := e.client.GetSpotBalances()
+	if err != nil {
+		return 0, err
+	}
+
+	var equity float64
+	for _, b := range balances {
+		free, _ := strconv.ParseFloat(b.Free, 64)
+		locked, _ := strconv.ParseFloat(b.Locked, 64)
+		amount := free + locked
+		if amount <= 0 {
+			continue
+		}
+		if b.Asset == "USDT" {
+			equity += amount
+			continue
+		}
+
+		// 没有USDT交易对的资产无法折算，不计入权益
+		ticker, err := e.client.GetBookTicker(b.Asset + "USDT")
+		if err != nil {
+			utils.Debug("资产无法按USDT折算，不计入权益", zap.String("asset", b.Asset), zap.Error(err))
+			continue
+		}
+		bid, _ := strconv.ParseFloat(ticker.BidPrice, 64)
+		equity += amount * bid
+	}
+	return equity, nil
+}
+
+// saveBreakerLocked 保存熔断状态（调用方需持有e.mu）
+func (e *Executor) saveBreakerLocked() {
+	if e.breakerPath == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(e.breaker, "", "  ")
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(e.breakerPath), 0755); err == nil {
+			err = os.WriteFile(e.breakerPath, data, 0644)
+		}
+	}
+	if err != nil {
+		utils.Warn("保存熔断状态失败", zap.String("account_id", e.accountID), zap.Error(err))
+	}
+}
+
+// roundPct 百分比保留两位小数
+func roundPct(v float64) float64 {
+	return math.Round(v*100) / 100
+}
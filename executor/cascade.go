@@ -0,0 +1,52 @@
+/*
+Package executor 连环强平期间暂停开仓
+
+主要功能：
+- (e *Executor) SetLiquidations(stream *binance.LiquidationStream)        // 设置强平推送（nil表示不检查）
+- (s *ShadowExecutor) SetLiquidations(stream *binance.LiquidationStream)  // 设置强平推送（影子账号同样拒绝开仓）
+
+交易对或全市场处于连环强平中（强平推送按规则判定）时拒绝开仓和加仓，平仓不受影响。
+*/
+package executor
+
+import (
+	"fmt"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// SetLiquidations 设置强平推送（nil表示不检查）
+func (e *Executor) SetLiquidations(stream *binance.LiquidationStream) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.liquidations = stream
+}
+
+// checkCascade 连环强平进行中时拒绝开仓（PlaceBracket 和 AddToPosition 都会检查）
+func (e *Executor) checkCascade(symbol string) error {
+	e.mu.Lock()
+	stream := e.liquidations
+	e.mu.Unlock()
+
+	if active, reason := stream.Cascade(symbol); active {
+		utils.Warn("连环强平进行中，暂停开仓",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", symbol),
+			zap.String("reason", reason),
+		)
+		return fmt.Errorf("连环强平进行中，暂停开仓: %s", reason)
+	}
+	return nil
+}
+
+// SetLiquidations 设置强平推送（nil表示不检查）
+func (s *ShadowExecutor) SetLiquidations(stream *binance.LiquidationStream) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.liquidations = stream
+}
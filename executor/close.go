@@ -0,0 +1,130 @@
+/*
+Package executor 平仓与决策分发
+
+主要功能：
+- (e *Executor) Execute(decision *Decision) error        // 执行交易决策（开仓 → 过期检查后括号订单或加仓，重复信号忽略，平仓 → 全部平仓）
+- (e *Executor) ClosePosition(symbol string) error       // 市价平掉交易对全部持仓并撤销止损止盈单
+
+所有平仓单都通过 submitExitOrder 发出：未设置 closePosition 的平仓单强制带上 reduceOnly，
+避免过期的平仓单在持仓已平后反向开仓。
+现货的止损单冻结了余额，平仓前先撤销括号订单的腿，卖出失败时恢复止损单。
+*/
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// Execute 执行交易决策
+func (e *Executor) Execute(decision *Decision) error {
+	// 开仓、加仓、平仓都会修改括号订单
+	defer e.saveBrackets()
+
+	switch decision.Action {
+	case ActionOpenLong, ActionOpenShort:
+		if err := e.checkStaleness(decision); err != nil {
+			return err
+		}
+		// 已有同方向括号订单且启用加仓时按加仓规则处理
+		if existing := e.GetBracket(decision.Symbol); existing != nil && e.Pyramiding().Enabled &&
+			(decision.Action == ActionOpenLong) == existing.IsLong() {
+			_, err := e.AddToPosition(decision)
+			return err
+		}
+		// 同方向持仓逻辑已存在时忽略重复信号，不再尝试入场
+		if _, err := e.PlaceBracket(decision); err != nil && !errors.Is(err, ErrDuplicateSignal) {
+			return err
+		}
+		return nil
+	case ActionClose:
+		return e.closePosition(decision.Symbol, decision.Hash())
+	case ActionHold, "":
+		return nil
+	default:
+		return fmt.Errorf("未知的决策动作: %s", decision.Action)
+	}
+}
+
+// ClosePosition 市价平掉交易对全部持仓并撤销止损止盈单
+func (e *Executor) ClosePosition(symbol string) error {
+	return e.closePosition(symbol, "")
+}
+
+// closePosition 市价平仓（decisionID非空时使用确定性的客户端订单ID）
+func (e *Executor) closePosition(symbol, decisionID string) error {
+	positionAmt, _, err := e.getPosition(symbol)
+	if err != nil {
+		return fmt.Errorf("查询持仓失败: %w", err)
+	}
+
+	bracket := e.GetBracket(symbol)
+	spot := e.client.IsSpot()
+	if positionAmt != 0 {
+		rules, err := e.getSymbolRules(symbol)
+		if err != nil {
+			return err
+		}
+		// 现货先撤销止损单释放冻结的余额，再按余额卖出
+		if spot && bracket != nil {
+			e.cancelExitLegs(bracket)
+			if positionAmt, err = e.spotExitQuantity(symbol, positionAmt, rules); err != nil {
+				e.restoreSpotStop(bracket, rules, bracket.Quantity)
+				return err
+			}
+		}
+
+		side := binance.SideSell
+		if positionAmt < 0 {
+			side = binance.SideBuy
+		}
+
+		req := &binance.OrderRequest{
+			Symbol:   symbol,
+			Side:     side,
+			Type:     binance.OrderTypeMarket,
+			Quantity: rules.FormatQuantity(math.Abs(positionAmt)),
+		}
+		if decisionID != "" {
+			req.NewClientOrderID = ClientOrderID(LegClose, e.accountID, symbol, decisionID)
+		}
+
+		// 市价单不支持closePosition，使用reduceOnly + 全部持仓数量
+		if _, err := e.submitExitOrder(req); err != nil {
+			if spot && bracket != nil {
+				e.restoreSpotStop(bracket, rules, bracket.Quantity)
+			}
+			return fmt.Errorf("平仓失败: %w", err)
+		}
+	}
+
+	// 撤销括号订单剩余的腿（现货已在卖出前撤销）
+	if bracket != nil {
+		if !spot || positionAmt == 0 {
+			e.cancelExitLegs(bracket)
+		}
+		e.closeBracket(bracket, CloseReasonPositionClosed)
+	}
+
+	utils.Info("平仓完成",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", symbol),
+		zap.Float64("position_amt", positionAmt),
+	)
+
+	return nil
+}
+
+// submitExitOrder 发出平仓单（未设置closePosition时强制reduceOnly）
+func (e *Executor) submitExitOrder(req *binance.OrderRequest) (*binance.Order, error) {
+	if !req.ClosePosition {
+		req.ReduceOnly = true
+	}
+	return e.placeOrderIdempotent(req)
+}
@@ -0,0 +1,163 @@
+/*
+Package executor 全部平仓
+
+主要功能：
+- (e *Executor) CloseAll(symbols []string) ([]string, error)         // 撤销全部挂单后市价平掉全部持仓，返回平仓的交易对
+- (e *Executor) PositionSymbols(symbols []string) ([]string, error)  // 有持仓的交易对（按交易所持仓，不只是括号订单）
+- (s *ShadowExecutor) CloseAll() ([]string, error)                   // 模拟平掉全部持仓，返回平仓的交易对
+
+检查范围为传入的交易对、括号订单的交易对、有挂单的交易对以及合约账号的全部持仓
+（现货持仓即资产余额，只检查传入的交易对和括号订单的交易对）。
+每个交易对先撤销全部挂单再查询持仓并平仓，避免挂单在平仓后成交。
+单个交易对失败不影响其他交易对，全部处理完后返回合并的错误。
+*/
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// CloseAll 撤销全部挂单（括号订单的止损止盈单和未成交的入场单）后市价平掉全部持仓，返回平仓的交易对
+func (e *Executor) CloseAll(symbols []string) ([]string, error) {
+	symbols, err := e.positionCandidates(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	// 一次查询全部挂单，只对有挂单的交易对撤单
+	orders, err := e.client.GetOpenOrders("")
+	if err != nil {
+		return nil, fmt.Errorf("查询挂单失败: %w", err)
+	}
+	pending := make(map[string]int)
+	for _, o := range orders {
+		pending[o.Symbol]++
+		if !slices.Contains(symbols, o.Symbol) {
+			symbols = append(symbols, o.Symbol)
+		}
+	}
+
+	var closed []string
+	var errs []error
+	for _, symbol := range symbols {
+		// 先撤单再平仓：未撤的入场单或止损止盈单可能在平仓后成交而重新开仓，现货的止损单还冻结着要卖出的余额
+		if pending[symbol] > 0 {
+			if err := e.client.CancelAllOrders(symbol); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", symbol, err))
+			}
+		}
+
+		positionAmt, _, err := e.getPosition(symbol)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s 查询持仓失败: %w", symbol, err))
+			continue
+		}
+		if positionAmt != 0 || e.GetBracket(symbol) != nil {
+			if err := e.ClosePosition(symbol); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", symbol, err))
+				continue
+			}
+			if positionAmt != 0 {
+				closed = append(closed, symbol)
+			}
+		}
+	}
+
+	utils.Info("全部平仓完成",
+		zap.String("account_id", e.accountID),
+		zap.Strings("closed", closed),
+		zap.Int("open_orders", len(orders)),
+		zap.Int("failed", len(errs)),
+	)
+	return closed, errors.Join(errs...)
+}
+
+// PositionSymbols 有持仓的交易对（合约查询账号全部持仓，含手动开仓和没有括号订单的持仓；现货检查传入的交易对和括号订单的交易对）
+func (e *Executor) PositionSymbols(symbols []string) ([]string, error) {
+	var open []string
+	if !e.client.IsSpot() {
+		risks, err := e.client.GetPositionRisk("")
+		if err != nil {
+			return nil, fmt.Errorf("查询持仓失败: %w", err)
+		}
+		for _, risk := range risks {
+			if risk.Quantity() != 0 && !slices.Contains(open, risk.Symbol) {
+				open = append(open, risk.Symbol)
+			}
+		}
+		return open, nil
+	}
+
+	candidates, err := e.positionCandidates(symbols)
+	if err != nil {
+		return nil, err
+	}
+	for _, symbol := range candidates {
+		positionAmt, _, err := e.getPosition(symbol)
+		if err != nil {
+			return nil, fmt.Errorf("%s 查询持仓失败: %w", symbol, err)
+		}
+		if positionAmt != 0 {
+			open = append(open, symbol)
+		}
+	}
+	return open, nil
+}
+
+// positionCandidates 可能有持仓的交易对：传入的交易对、括号订单的交易对，合约账号再加上全部非零持仓（返回新的切片）
+func (e *Executor) positionCandidates(symbols []string) ([]string, error) {
+	symbols = slices.Clone(symbols)
+	for _, b := range e.GetBrackets() {
+		if !slices.Contains(symbols, b.Symbol) {
+			symbols = append(symbols, b.Symbol)
+		}
+	}
+	if e.client.IsSpot() {
+		return symbols, nil
+	}
+
+	// 合约账号一次查询全部持仓，不在交易对池中的持仓（如手动开的仓）也包含在内
+	risks, err := e.client.GetPositionRisk("")
+	if err != nil {
+		return nil, fmt.Errorf("查询持仓失败: %w", err)
+	}
+	for _, risk := range risks {
+		if risk.Quantity() != 0 && !slices.Contains(symbols, risk.Symbol) {
+			symbols = append(symbols, risk.Symbol)
+		}
+	}
+	return symbols, nil
+}
+
+// CloseAll 模拟平掉全部持仓，返回平仓的交易对（获取价格失败的交易对保留持仓）
+func (s *ShadowExecutor) CloseAll() ([]string, error) {
+	var closed []string
+	var errs []error
+	for _, b := range s.GetBrackets() {
+		price, err := s.lastPrice(b.Symbol)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s 获取最新价格失败: %w", b.Symbol, err))
+			continue
+		}
+		s.mu.Lock()
+		if pos, ok := s.positions[b.Symbol]; ok {
+			s.closeLocked(pos, price, CloseReasonPositionClosed, time.Now())
+			closed = append(closed, b.Symbol)
+		}
+		s.mu.Unlock()
+	}
+
+	utils.Info("模拟全部平仓完成",
+		zap.String("account_id", s.accountID),
+		zap.Strings("closed", closed),
+		zap.Int("failed", len(errs)),
+	)
+	return closed, errors.Join(errs...)
+}
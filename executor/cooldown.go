@@ -0,0 +1,37 @@
+/*
+Package executor 交易对最近动作时间（决策冷却）
+
+主要功能：
+- (e *Executor) LastActionAt(symbol string) time.Time        // 交易对最近一次入场、加仓或出场的时间
+- (s *ShadowExecutor) LastActionAt(symbol string) time.Time  // 交易对最近一次模拟开仓或平仓的时间
+
+策略运行器在冷却期内不再为刚入场或出场的交易对生成信号（不调用AI），
+避免同一根K线内反复开平仓，也节省AI调用。动作时间只保存在内存中，重启后不冷却。
+*/
+package executor
+
+import "time"
+
+// LastActionAt 交易对最近一次入场、加仓或出场的时间（没有记录时返回零值）
+func (e *Executor) LastActionAt(symbol string) time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.lastActions[symbol]
+}
+
+// recordAction 记录交易对的入场或出场时间
+func (e *Executor) recordAction(symbol string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.lastActions[symbol] = time.Now()
+}
+
+// LastActionAt 交易对最近一次模拟开仓或平仓的时间（没有记录时返回零值）
+func (s *ShadowExecutor) LastActionAt(symbol string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastActions[symbol]
+}
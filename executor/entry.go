@@ -0,0 +1,238 @@
+/*
+Package executor 入场执行（按执行配置选择市价或限价入场）
+
+主要功能：
+- (e *Executor) SetExecutionConfig(cfg config.ExecutionConfig)  // 设置执行配置
+- (e *Executor) ExecutionConfig() config.ExecutionConfig        // 获取执行配置
+
+限价入场流程：
+1. 按最优挂单价格挂限价单（做多挂买一价，做空挂卖一价），有效方式由配置决定（如GTX只做Maker）
+2. 等待 limit_timeout_sec 秒
+3. 未完全成交时撤销剩余部分，按 fallback 配置市价补齐或放弃
+*/
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// entryResult 入场结果（可能由多笔订单组成）
+type entryResult struct {
+	OrderID   int64   // 第一笔入场订单ID
+	FilledQty float64 // 总成交数量
+	AvgPrice  float64 // 成交均价（按数量加权）
+	Note      string  // 执行备注（如因滑点缩减数量）
+}
+
+// add 累加一笔订单的成交
+func (r *entryResult) add(order *binance.Order) {
+	qty := order.ExecutedQtyFloat()
+	if r.OrderID == 0 {
+		r.OrderID = order.OrderID
+	}
+	if qty <= 0 {
+		return
+	}
+
+	total := r.FilledQty + qty
+	r.AvgPrice = (r.AvgPrice*r.FilledQty + order.AvgPriceFloat()*qty) / total
+	r.FilledQty = total
+}
+
+// merge 合并另一个入场结果
+func (r *entryResult) merge(other *entryResult) {
+	if r.OrderID == 0 {
+		r.OrderID = other.OrderID
+	}
+	if other.FilledQty <= 0 {
+		return
+	}
+
+	total := r.FilledQty + other.FilledQty
+	r.AvgPrice = (r.AvgPrice*r.FilledQty + other.AvgPrice*other.FilledQty) / total
+	r.FilledQty = total
+}
+
+// SetExecutionConfig 设置执行配置
+func (e *Executor) SetExecutionConfig(cfg config.ExecutionConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.execution = cfg
+}
+
+// ExecutionConfig 获取执行配置
+func (e *Executor) ExecutionConfig() config.ExecutionConfig {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.execution
+}
+
+// enter 按执行配置入场（先做滑点检查，名义价值超过TWAP阈值时拆单执行）
+// onSlice: 拆单入场时每笔成交后的回调（参数为累计成交，返回错误时停止拆单），不拆单时不调用
+func (e *Executor) enter(symbol, side string, quantity float64, rules *binance.SymbolInfo, decisionID string, onSlice func(filled *entryResult) error) (*entryResult, error) {
+	exec := e.ExecutionConfig()
+	twap := e.needTWAP(symbol, quantity, rules, exec)
+
+	// 下单前按盘口深度预估滑点（拆单时按单笔数量估算）
+	checkQty := quantity
+	if twap {
+		checkQty = quantity / float64(exec.TWAP.Slices)
+	}
+	allowed, note, err := e.limitSlippage(symbol, side, checkQty, rules, exec)
+	if err != nil {
+		return nil, err
+	}
+	if allowed < checkQty {
+		quantity *= allowed / checkQty
+	}
+
+	var result *entryResult
+	if twap {
+		result, err = e.enterTWAP(symbol, side, quantity, rules, decisionID, exec, onSlice)
+	} else {
+		result, err = e.enterOnce(symbol, side, quantity, rules, decisionID, exec)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Note = note
+	return result, nil
+}
+
+// enterOnce 按entry_type执行一笔入场
+func (e *Executor) enterOnce(symbol, side string, quantity float64, rules *binance.SymbolInfo, decisionID string, exec config.ExecutionConfig) (*entryResult, error) {
+	switch exec.EntryType {
+	case config.EntryTypeLimit:
+		return e.enterLimit(symbol, side, quantity, rules, decisionID, exec)
+	case config.EntryTypeMakerFirst:
+		if !e.makerFirstWorthIt(symbol, exec) {
+			return e.enterMarket(symbol, side, quantity, rules, decisionID, "")
+		}
+		return e.enterMakerFirst(symbol, side, quantity, rules, decisionID, exec)
+	default:
+		return e.enterMarket(symbol, side, quantity, rules, decisionID, "")
+	}
+}
+
+// enterMarket 市价入场
+func (e *Executor) enterMarket(symbol, side string, quantity float64, rules *binance.SymbolInfo, decisionID, suffix string) (*entryResult, error) {
+	entry, err := e.placeOrderIdempotent(&binance.OrderRequest{
+		Symbol:           symbol,
+		Side:             side,
+		Type:             binance.OrderTypeMarket,
+		Quantity:         rules.FormatQuantity(quantity),
+		NewClientOrderID: ClientOrderID(LegEntry, e.accountID, symbol, decisionID, suffix),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("入场下单失败: %w", err)
+	}
+
+	entry, err = e.waitForFill(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entryResult{}
+	result.add(entry)
+	return result, nil
+}
+
+// enterLimit 限价入场（超时未成交部分按fallback处理）
+func (e *Executor) enterLimit(symbol, side string, quantity float64, rules *binance.SymbolInfo, decisionID string, exec config.ExecutionConfig) (*entryResult, error) {
+	ticker, err := e.client.GetBookTicker(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// 做多挂买一价，做空挂卖一价（GTX下不会吃单）
+	price := ticker.BidPriceFloat()
+	if side == binance.SideSell {
+		price = ticker.AskPriceFloat()
+	}
+
+	result := &entryResult{}
+	e.limitAttempt(result, symbol, side, quantity, price, rules, exec.TimeInForce,
+		time.Duration(exec.LimitTimeoutSec)*time.Second, ClientOrderID(LegEntry, e.accountID, symbol, decisionID))
+
+	return e.fillRemainder(result, symbol, side, quantity, rules, decisionID, exec)
+}
+
+// limitAttempt 挂一笔限价单，等待timeout后撤销未成交部分，成交累加到result
+func (e *Executor) limitAttempt(result *entryResult, symbol, side string, quantity, price float64, rules *binance.SymbolInfo, tif string, timeout time.Duration, clientOrderID string) {
+	order, err := e.placeOrderIdempotent(&binance.OrderRequest{
+		Symbol:           symbol,
+		Side:             side,
+		Type:             binance.OrderTypeLimit,
+		Quantity:         rules.FormatQuantity(quantity),
+		Price:            rules.FormatPrice(price),
+		TimeInForce:      tif,
+		NewClientOrderID: clientOrderID,
+	})
+	if err != nil {
+		utils.Warn("限价入场下单失败",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", symbol),
+			zap.Error(err),
+		)
+		return
+	}
+
+	order = e.waitForOrder(order, timeout)
+	if !order.IsFinal() {
+		if canceled, err := e.client.CancelOrder(symbol, order.OrderID); err == nil {
+			order = canceled
+		}
+	}
+	result.add(order)
+}
+
+// fillRemainder 未成交部分按fallback配置处理（市价补齐或放弃）
+func (e *Executor) fillRemainder(result *entryResult, symbol, side string, quantity float64, rules *binance.SymbolInfo, decisionID string, exec config.ExecutionConfig) (*entryResult, error) {
+	remaining := quantity - result.FilledQty
+	if isDust(remaining, rules) {
+		return result, nil
+	}
+
+	if exec.Fallback != config.FallbackMarket {
+		utils.Info("限价入场未完全成交，按配置放弃剩余部分",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", symbol),
+			zap.Float64("filled", result.FilledQty),
+			zap.Float64("remaining", remaining),
+		)
+		return result, nil
+	}
+
+	utils.Info("限价入场未完全成交，市价补齐",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", symbol),
+		zap.Float64("filled", result.FilledQty),
+		zap.Float64("remaining", remaining),
+	)
+
+	fallback, err := e.enterMarket(symbol, side, remaining, rules, decisionID, "fallback")
+	if err != nil {
+		if result.FilledQty > 0 {
+			utils.Warn("市价补齐失败，仅保留已成交部分", zap.String("symbol", symbol), zap.Error(err))
+			return result, nil
+		}
+		return nil, err
+	}
+	result.merge(fallback)
+	return result, nil
+}
+
+// isDust 剩余数量是否小到无法下单
+func isDust(qty float64, rules *binance.SymbolInfo) bool {
+	return qty < rules.MinQty || rules.FormatQuantity(qty) == rules.FormatQuantity(0)
+}
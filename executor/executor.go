@@ -0,0 +1,209 @@
+/*
+Package executor 交易执行器
+
+主要功能：
+- NewExecutor(accountID string, client *binance.Client) *Executor   // 创建执行器
+- (e *Executor) LoadSymbolRules() error                             // 加载交易规则（精度、最小数量）
+- (e *Executor) GetBracket(symbol string) *Bracket                  // 获取交易对当前生效的括号订单
+- (e *Executor) GetBrackets() []*Bracket                            // 获取所有生效中的括号订单
+*/
+package executor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/journal"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// Executor 交易执行器（每个账号一个）
+type Executor struct {
+	accountID string
+	client    *binance.Client
+
+	symbolRules map[string]*binance.SymbolInfo // 交易规则缓存
+	rulesMu     sync.RWMutex
+
+	feeRates map[string]cachedFeeModel // symbol -> 账号手续费率缓存
+	feeMu    sync.Mutex
+
+	brackets    map[string]*Bracket    // symbol -> 生效中的括号订单
+	theses      map[string]*Thesis     // symbol -> 持仓逻辑（入场中或括号订单生效中）
+	lastActions map[string]time.Time   // symbol -> 最近一次入场、加仓或出场的时间
+	execution   config.ExecutionConfig // 执行配置（入场方式）
+	journal     *journal.Journal       // 交易日志（为nil时不记录）
+
+	sizing       config.SizingConfig       // 仓位计算配置
+	pyramiding   config.PyramidingConfig   // 盈利加仓规则
+	reentry      config.ReentryConfig      // 止损后重新入场规则
+	symbolLimits config.SymbolLimitsConfig // 交易对分级上限
+	sectors      *config.SectorsConfig     // 板块配置（nil表示不限制板块敞口）
+	stopOuts     map[string]*stopOut       // symbol -> 最近一次止损事件
+	marginTopUp  config.MarginTopUpConfig  // 逐仓自动追加保证金规则
+	marginAdded  map[string]float64        // symbol -> 当前持仓已自动追加的保证金（USDT）
+
+	staleness   config.StalenessConfig // 决策过期规则
+	decisionTTL time.Duration          // 决策有效期（按策略运行周期计算）
+
+	markPrices   *binance.MarkPriceStream   // 标记价格推送（nil表示只使用REST接口）
+	orderBooks   *binance.DepthStream       // 推送维护的本地订单簿（nil表示只使用REST接口）
+	liquidations *binance.LiquidationStream // 强平推送（连环强平期间暂停开仓，nil表示不检查）
+	maxPremium   float64                    // 标记价格偏离指数价格超过该百分比时暂停开仓（0表示不检查）
+
+	circuitBreaker config.CircuitBreakerConfig // 最大回撤熔断规则
+	breaker        BreakerStatus               // 熔断状态（权益峰值、是否只平仓）
+	breakerPath    string                      // 熔断状态保存路径
+
+	bracketsPath  string // 括号订单保存路径（为空时不保存）
+	savedBrackets []byte // 最近一次保存的内容（未变化时不重复写文件）
+
+	mu sync.Mutex
+
+	fillTimeout  time.Duration // 等待入场成交的超时时间
+	pollInterval time.Duration // 查询订单状态的间隔
+}
+
+// NewExecutor 创建执行器
+func NewExecutor(accountID string, client *binance.Client) *Executor {
+	return &Executor{
+		accountID:    accountID,
+		client:       client,
+		symbolRules:  make(map[string]*binance.SymbolInfo),
+		feeRates:     make(map[string]cachedFeeModel),
+		brackets:     make(map[string]*Bracket),
+		theses:       make(map[string]*Thesis),
+		lastActions:  make(map[string]time.Time),
+		marginAdded:  make(map[string]float64),
+		stopOuts:     make(map[string]*stopOut),
+		execution:    config.ExecutionConfig{EntryType: config.EntryTypeMarket},
+		sizing:       config.SizingConfig{Mode: config.SizingModeFixed},
+		fillTimeout:  30 * time.Second,
+		pollInterval: 1 * time.Second,
+	}
+}
+
+// LoadSymbolRules 加载交易规则（精度、最小数量）
+func (e *Executor) LoadSymbolRules() error {
+	info, err := e.client.GetExchangeInfo()
+	if err != nil {
+		return err
+	}
+
+	e.rulesMu.Lock()
+	defer e.rulesMu.Unlock()
+
+	for i := range info.Symbols {
+		e.symbolRules[info.Symbols[i].Symbol] = &info.Symbols[i]
+	}
+
+	utils.Info("加载交易规则完成",
+		zap.String("account_id", e.accountID),
+		zap.Int("symbols", len(e.symbolRules)),
+	)
+
+	return nil
+}
+
+// GetBracket 获取交易对当前生效的括号订单
+func (e *Executor) GetBracket(symbol string) *Bracket {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.brackets[symbol]
+}
+
+// GetBrackets 获取所有生效中的括号订单
+func (e *Executor) GetBrackets() []*Bracket {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	brackets := make([]*Bracket, 0, len(e.brackets))
+	for _, b := range e.brackets {
+		brackets = append(brackets, b)
+	}
+	return brackets
+}
+
+// getSymbolRules 获取交易对规则（缓存未命中时重新加载）
+func (e *Executor) getSymbolRules(symbol string) (*binance.SymbolInfo, error) {
+	e.rulesMu.RLock()
+	rules, exists := e.symbolRules[symbol]
+	e.rulesMu.RUnlock()
+
+	if exists {
+		return rules, nil
+	}
+
+	if err := e.LoadSymbolRules(); err != nil {
+		return nil, fmt.Errorf("加载交易规则失败: %w", err)
+	}
+
+	e.rulesMu.RLock()
+	defer e.rulesMu.RUnlock()
+
+	rules, exists = e.symbolRules[symbol]
+	if !exists {
+		return nil, fmt.Errorf("未找到交易对规则: %s", symbol)
+	}
+	return rules, nil
+}
+
+// waitForFill 等待订单结束（成交、撤销或超时）
+// 超时时返回最后一次查询到的订单状态
+func (e *Executor) waitForFill(order *binance.Order) (*binance.Order, error) {
+	return e.waitForOrder(order, e.fillTimeout), nil
+}
+
+// waitForOrder 在timeout内轮询订单直到结束，返回最后一次查询到的订单状态
+func (e *Executor) waitForOrder(order *binance.Order, timeout time.Duration) *binance.Order {
+	deadline := time.Now().Add(timeout)
+
+	for !order.IsFinal() && time.Now().Before(deadline) {
+		time.Sleep(e.pollInterval)
+
+		latest, err := e.client.GetOrder(order.Symbol, order.OrderID)
+		if err != nil {
+			utils.Warn("查询订单状态失败",
+				zap.String("symbol", order.Symbol),
+				zap.Int64("order_id", order.OrderID),
+				zap.Error(err),
+			)
+			continue
+		}
+		order = latest
+	}
+
+	return order
+}
+
+// getPosition 获取交易对当前持仓数量（多为正，空为负）和开仓均价
+func (e *Executor) getPosition(symbol string) (float64, float64, error) {
+	if e.client.IsSpot() {
+		return e.spotPosition(symbol)
+	}
+
+	risks, err := e.client.GetPositionRisk(symbol)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	total := 0.0
+	entryPrice := 0.0
+	for _, risk := range risks {
+		if risk.Symbol != symbol {
+			continue
+		}
+		amt := risk.Quantity()
+		if amt != 0 {
+			entryPrice = risk.EntryPriceFloat()
+		}
+		total += amt
+	}
+	return total, entryPrice, nil
+}
@@ -0,0 +1,104 @@
+/*
+Package executor 止损止盈单与持仓数量同步
+
+主要功能：
+- (e *Executor) SyncExitOrders(bracket *Bracket, positionAmt, entryPrice float64) error  // 按最新持仓数量重新挂出止损止盈单
+
+部分平仓或加仓后，原有止损止盈单的数量与实际持仓不一致：
+数量偏大会在触发时只减掉剩余仓位（问题不大），数量偏小则加仓部分无保护。
+同步时先挂新单再撤旧单，保证同步过程中持仓始终有止损保护。
+现货的止损单冻结了余额，新单无法先挂出，改为先撤旧单再挂新单，失败时恢复止损单（见 syncSpotStop）。
+每次挂单使用新的客户端订单ID（见 exitLegID），数量变回之前用过的值时不会复用已撤销的旧单。
+*/
+package executor
+
+import (
+	"fmt"
+	"math"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// SyncExitOrders 按最新持仓数量重新挂出止损止盈单
+// positionAmt: 当前持仓数量（多为正，空为负）
+// entryPrice: 当前开仓均价（加仓后会变化）
+func (e *Executor) SyncExitOrders(bracket *Bracket, positionAmt, entryPrice float64) error {
+	if (positionAmt > 0) != bracket.IsLong() {
+		return fmt.Errorf("持仓方向与括号订单不一致: position_amt=%v side=%s", positionAmt, bracket.Side)
+	}
+
+	rules, err := e.getSymbolRules(bracket.Symbol)
+	if err != nil {
+		return err
+	}
+
+	newQty := math.Abs(positionAmt)
+	oldQty := bracket.Quantity
+
+	// 现货只有止损单（止盈由监控按价格触发），数量不超过标的资产余额
+	if e.client.IsSpot() {
+		qty, err := e.syncSpotStop(bracket, rules, newQty)
+		if err != nil {
+			return fmt.Errorf("重新挂出止损单失败: %w", err)
+		}
+		newQty = qty
+	} else {
+		// 1. 先挂新的止损单，失败时保留旧止损单
+		stopOrder, err := e.placeExitLeg(bracket, rules, binance.OrderTypeStopMarket, bracket.StopLoss, newQty)
+		if err != nil {
+			return fmt.Errorf("重新挂出止损单失败: %w", err)
+		}
+		oldStopID := bracket.StopLossOrderID
+		bracket.StopLossOrderID = stopOrder.OrderID
+		if oldStopID != stopOrder.OrderID {
+			e.cancelLeg(bracket, oldStopID)
+		}
+	}
+
+	// 2. 再同步止盈单
+	if bracket.TakeProfitOrderID != 0 {
+		tpOrder, err := e.placeExitLeg(bracket, rules, binance.OrderTypeTakeProfitMarket, bracket.TakeProfit, newQty)
+		if err != nil {
+			utils.Warn("重新挂出止盈单失败，保留原止盈单",
+				zap.String("account_id", e.accountID),
+				zap.String("symbol", bracket.Symbol),
+				zap.Error(err),
+			)
+		} else {
+			oldTPID := bracket.TakeProfitOrderID
+			bracket.TakeProfitOrderID = tpOrder.OrderID
+			if oldTPID != tpOrder.OrderID {
+				e.cancelLeg(bracket, oldTPID)
+			}
+		}
+	}
+
+	e.mu.Lock()
+	bracket.Quantity = newQty
+	if entryPrice > 0 {
+		bracket.EntryPrice = entryPrice
+	}
+	e.mu.Unlock()
+
+	utils.Info("止损止盈单已同步持仓数量",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", bracket.Symbol),
+		zap.Float64("old_quantity", oldQty),
+		zap.Float64("new_quantity", newQty),
+		zap.Float64("entry_price", bracket.EntryPrice),
+	)
+
+	return nil
+}
+
+// positionSizeChanged 持仓数量是否与括号订单记录的数量不一致（按stepSize比较）
+func (e *Executor) positionSizeChanged(bracket *Bracket, positionAmt float64) bool {
+	rules, err := e.getSymbolRules(bracket.Symbol)
+	if err != nil {
+		return false
+	}
+	return rules.FormatQuantity(math.Abs(positionAmt)) != rules.FormatQuantity(bracket.Quantity)
+}
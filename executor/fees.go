@@ -0,0 +1,85 @@
+/*
+Package executor 账号手续费率（按交易对缓存）
+
+主要功能：
+- (e *Executor) FeeModel(symbol string) journal.FeeModel  // 获取账号在交易对上的Maker/Taker费率（缓存24小时）
+
+费率随VIP等级和BNB抵扣变化，每个账号单独查询：
+- 交易日志：手续费不是以USDT支付时（如BNB抵扣），按费率估算
+- 执行策略：Maker与Taker费率差低于 maker_first_min_saving_bps 时，maker_first 直接市价入场
+*/
+package executor
+
+import (
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/journal"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const feeRateTTL = 24 * time.Hour // 手续费率缓存时间
+
+// cachedFeeModel 缓存的手续费率
+type cachedFeeModel struct {
+	model     journal.FeeModel
+	fetchedAt time.Time
+}
+
+// FeeModel 获取账号在交易对上的Maker/Taker费率
+// 查询失败时返回默认费率（不缓存，下次重试）
+func (e *Executor) FeeModel(symbol string) journal.FeeModel {
+	e.feeMu.Lock()
+	cached, ok := e.feeRates[symbol]
+	e.feeMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < feeRateTTL {
+		return cached.model
+	}
+
+	rate, err := e.client.GetCommissionRate(symbol)
+	if err != nil {
+		utils.Warn("获取手续费率失败，使用默认费率",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", symbol),
+			zap.Error(err),
+		)
+		return journal.DefaultFeeModel
+	}
+
+	model := journal.FeeModel{MakerRate: rate.MakerRate(), TakerRate: rate.TakerRate()}
+	e.feeMu.Lock()
+	e.feeRates[symbol] = cachedFeeModel{model: model, fetchedAt: time.Now()}
+	e.feeMu.Unlock()
+
+	utils.Debug("手续费率已更新",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", symbol),
+		zap.Float64("maker", model.MakerRate),
+		zap.Float64("taker", model.TakerRate),
+	)
+
+	return model
+}
+
+// makerFirstWorthIt Maker与Taker的费率差是否值得挂单等待
+func (e *Executor) makerFirstWorthIt(symbol string, exec config.ExecutionConfig) bool {
+	if exec.MakerFirstMinSavingBps <= 0 {
+		return true
+	}
+
+	fees := e.FeeModel(symbol)
+	savingBps := (fees.TakerRate - fees.MakerRate) * 10000
+	if savingBps >= exec.MakerFirstMinSavingBps {
+		return true
+	}
+
+	utils.Info("Maker/Taker费率差过小，直接市价入场",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", symbol),
+		zap.Float64("saving_bps", savingBps),
+		zap.Float64("min_saving_bps", exec.MakerFirstMinSavingBps),
+	)
+	return false
+}
@@ -0,0 +1,145 @@
+/*
+Package executor 幂等下单
+
+主要功能：
+- ClientOrderID(leg string, parts ...string) string                      // 生成确定性的客户端订单ID
+- (d *Decision) Hash() string                                            // 决策哈希（账号+交易对+动作+数量+价格+时间）
+- (e *Executor) placeOrderIdempotent(req *binance.OrderRequest) (*binance.Order, error)  // 幂等下单（重试前先按ID查单）
+
+同一个决策无论重试多少次都会生成相同的 newClientOrderId，
+重试前先按该ID查询订单，已存在则直接返回，保证网络重试不会重复开仓。
+下单前查到的已结束订单（已撤销、已过期、被拒绝）不会当作已下单：该ID已被之前的订单用掉，
+返回 ErrClientOrderIDUsed，调用方需要换一个ID（止损止盈单按挂单序号生成ID，见 exitLegID）。
+*/
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 订单腿标识（客户端订单ID前缀）
+const (
+	LegEntry      = "e"  // 入场单
+	LegStopLoss   = "sl" // 止损单
+	LegTakeProfit = "tp" // 止盈单
+	LegClose      = "c"  // 平仓单
+)
+
+// ErrClientOrderIDUsed 客户端订单ID已被已结束的订单使用（不能当作本次下单的结果）
+var ErrClientOrderIDUsed = errors.New("客户端订单ID已被已结束的订单使用")
+
+const (
+	maxOrderRetries  = 3                      // 最大下单重试次数
+	orderRetryDelay  = 500 * time.Millisecond // 重试间隔
+	clientIDHashSize = 28                     // 客户端订单ID中哈希部分的长度
+)
+
+// ClientOrderID 生成确定性的客户端订单ID
+// 格式：ai-<leg>-<哈希>，总长度不超过币安限制的36位
+func ClientOrderID(leg string, parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return fmt.Sprintf("ai-%s-%s", leg, hex.EncodeToString(sum[:])[:clientIDHashSize])
+}
+
+// Hash 决策哈希（账号+交易对+动作+数量+价格+时间）
+func (d *Decision) Hash() string {
+	raw := strings.Join([]string{
+		d.AccountID,
+		d.Symbol,
+		d.Action,
+		strconv.FormatFloat(d.Quantity, 'f', -1, 64),
+		strconv.FormatFloat(d.StopLoss, 'f', -1, 64),
+		strconv.FormatFloat(d.TakeProfit, 'f', -1, 64),
+		strconv.FormatInt(d.Timestamp, 10),
+	}, "|")
+
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// placeOrderIdempotent 幂等下单
+// 设置了NewClientOrderID时：下单前及每次重试前先按ID查询，查到可复用的订单则直接返回（见 reusableOrder）
+// 未设置时：直接下单，不重试
+func (e *Executor) placeOrderIdempotent(req *binance.OrderRequest) (*binance.Order, error) {
+	if req.NewClientOrderID == "" {
+		return e.client.PlaceOrder(req)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxOrderRetries; attempt++ {
+		existing, err := e.client.GetOrderByClientID(req.Symbol, req.NewClientOrderID)
+		if err == nil && existing != nil {
+			if !reusableOrder(existing, attempt > 0) {
+				return nil, fmt.Errorf("%w: %s (%s)", ErrClientOrderIDUsed, req.NewClientOrderID, existing.Status)
+			}
+			utils.Info("订单已存在，跳过重复下单",
+				zap.String("account_id", e.accountID),
+				zap.String("symbol", req.Symbol),
+				zap.String("client_order_id", req.NewClientOrderID),
+				zap.String("status", existing.Status),
+			)
+			return existing, nil
+		}
+
+		order, err := e.client.PlaceOrder(req)
+		if err == nil {
+			return order, nil
+		}
+		lastErr = err
+
+		// 明确被交易所拒绝的请求不重试，只有结果未知（网络错误、5xx）时才重试
+		if !isUncertainOrderError(err) {
+			return nil, err
+		}
+
+		utils.Warn("下单结果未知，准备重试",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", req.Symbol),
+			zap.String("client_order_id", req.NewClientOrderID),
+			zap.Int("attempt", attempt+1),
+			zap.Error(err),
+		)
+		time.Sleep(orderRetryDelay)
+	}
+
+	// 最后再确认一次，避免最后一次请求实际已成功
+	if existing, err := e.client.GetOrderByClientID(req.Symbol, req.NewClientOrderID); err == nil && existing != nil && reusableOrder(existing, true) {
+		return existing, nil
+	}
+
+	return nil, fmt.Errorf("下单重试%d次仍失败: %w", maxOrderRetries, lastErr)
+}
+
+// reusableOrder 按客户端订单ID查到的订单能否当作本次下单的结果
+// 挂单中、部分成交、已成交的订单可以复用；已撤销、已过期、被拒绝的订单是之前用过该ID的旧单，
+// 当作已下单会把已撤销的止损单当成新的止损单（持仓无保护）。
+// sent: 本次调用已用该ID发出过下单请求（结果未知），此时立即过期的 GTX/IOC 限价单也是本次下单的结果
+func reusableOrder(order *binance.Order, sent bool) bool {
+	switch order.Status {
+	case binance.OrderStatusNew, binance.OrderStatusPartiallyFilled, binance.OrderStatusFilled:
+		return true
+	case binance.OrderStatusExpired:
+		return sent
+	}
+	return false
+}
+
+// isUncertainOrderError 下单结果是否未知（网络错误、服务端5xx或交易所后端超时）
+func isUncertainOrderError(err error) bool {
+	var apiErr *binance.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || errors.Is(err, binance.ErrStatusUnknown)
+	}
+	return strings.Contains(err.Error(), "请求失败")
+}
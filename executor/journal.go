@@ -0,0 +1,162 @@
+/*
+Package executor 括号订单结束后写入交易日志
+
+主要功能：
+- (e *Executor) SetJournal(j *journal.Journal)  // 设置交易日志
+
+盈亏按实际成本计算：
+- 手续费：按成交历史（userTrades）中入场和出场成交的实际手续费累加（非USDT支付时按账号费率估算）
+- 资金费：按持仓期间的资金费率历史 × 持仓名义价值计算
+*/
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/journal"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	tradeLookback   = time.Minute        // 查询成交历史时向前多取的时间（防止本地与服务器时间偏差）
+	maxTradesWindow = 7 * 24 * time.Hour // userTrades 单次查询最大时间跨度
+	settleAssetUSDT = "USDT"             // U本位合约的结算资产
+)
+
+// SetJournal 设置交易日志
+func (e *Executor) SetJournal(j *journal.Journal) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.journal = j
+}
+
+// recordTrade 将已结束的括号订单写入交易日志
+func (e *Executor) recordTrade(bracket *Bracket) {
+	e.mu.Lock()
+	j := e.journal
+	e.mu.Unlock()
+	if j == nil {
+		return
+	}
+
+	trade := e.buildTrade(bracket)
+	if err := j.Record(trade); err != nil {
+		utils.Error("记录交易失败",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", bracket.Symbol),
+			zap.Error(err),
+		)
+	}
+}
+
+// buildTrade 按成交历史和资金费率计算交易盈亏
+func (e *Executor) buildTrade(bracket *Bracket) *journal.Trade {
+	entryTime := bracket.EntryStartedAt
+	if entryTime.IsZero() {
+		entryTime = bracket.CreatedAt
+	}
+
+	trade := &journal.Trade{
+		AccountID:   e.accountID,
+		Symbol:      bracket.Symbol,
+		Side:        bracket.Side,
+		Quantity:    bracket.Quantity,
+		EntryPrice:  bracket.EntryPrice,
+		StopLoss:    bracket.StopLoss,
+		TakeProfit:  bracket.TakeProfit,
+		EntryTime:   entryTime,
+		ExitTime:    bracket.ClosedAt,
+		CloseReason: bracket.CloseReason,
+		DecisionID:  bracket.DecisionID,
+		Note:        bracket.ExecutionNote,
+	}
+
+	// 1. 成交历史：出场均价和实际手续费
+	start := entryTime.Add(-tradeLookback)
+	if bracket.ClosedAt.Sub(start) > maxTradesWindow {
+		start = bracket.ClosedAt.Add(-maxTradesWindow)
+	}
+	fills, err := e.client.GetUserTrades(bracket.Symbol, start.UnixMilli(), bracket.ClosedAt.Add(tradeLookback).UnixMilli())
+	if err != nil {
+		utils.Warn("查询成交历史失败，交易日志缺少出场价和手续费",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", bracket.Symbol),
+			zap.Error(err),
+		)
+	}
+
+	// 结算资产：U本位为USDT，币本位为保证金币种
+	var contractSize float64
+	trade.Asset = settleAssetUSDT
+	if rules, err := e.getSymbolRules(bracket.Symbol); err == nil {
+		contractSize = rules.ContractSize
+		if rules.MarginAsset != "" {
+			trade.Asset = rules.MarginAsset
+		}
+	}
+
+	exitSide := bracket.exitSide()
+	var exitQty, exitCost float64
+	for i := range fills {
+		fill := &fills[i]
+		if fill.CommissionAsset == trade.Asset {
+			trade.Commission += fill.CommissionFloat()
+		} else {
+			// 以BNB等抵扣时按账号费率估算结算资产计价的手续费
+			notional := fill.QtyFloat() * fill.PriceFloat()
+			if contractSize > 0 {
+				notional = fill.QtyFloat() * contractSize / fill.PriceFloat()
+			}
+			trade.Commission += e.FeeModel(bracket.Symbol).Commission(notional, fill.Maker)
+			trade.Note = appendNote(trade.Note, fmt.Sprintf("手续费以%s支付，按费率估算", fill.CommissionAsset))
+		}
+		if fill.Side == exitSide {
+			exitQty += fill.QtyFloat()
+			exitCost += fill.QtyFloat() * fill.PriceFloat()
+		}
+	}
+	if exitQty > 0 {
+		trade.ExitPrice = exitCost / exitQty
+	}
+	if contractSize > 0 {
+		trade.GrossPnL = journal.InverseGrossPnL(bracket.Side, bracket.Quantity, contractSize, trade.EntryPrice, trade.ExitPrice)
+	}
+
+	// 2. 持仓期间的资金费（现货没有资金费）
+	if e.client.IsSpot() {
+		return trade
+	}
+	rates, err := e.client.GetFundingRateRange(bracket.Symbol, entryTime.UnixMilli(), bracket.ClosedAt.UnixMilli())
+	if err != nil {
+		utils.Warn("查询资金费率失败，交易日志缺少资金费",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", bracket.Symbol),
+			zap.Error(err),
+		)
+	} else {
+		qty, price := bracket.Quantity, bracket.EntryPrice
+		if contractSize > 0 && price > 0 {
+			// 币本位：资金费以标的币种计，名义价值 = 张数 × 面值 / 价格
+			qty, price = bracket.Quantity*contractSize/price, 1
+		}
+		trade.Funding = journal.FundingCost(bracket.Side, qty, price, rates, entryTime, bracket.ClosedAt)
+	}
+
+	return trade
+}
+
+// appendNote 追加备注（去重）
+func appendNote(note, extra string) string {
+	if note == "" {
+		return extra
+	}
+	if strings.Contains(note, extra) {
+		return note
+	}
+	return note + "；" + extra
+}
@@ -0,0 +1,118 @@
+/*
+Package executor 交易对分级上限（杠杆、名义价值）
+
+主要功能：
+- (e *Executor) SetSymbolLimits(cfg config.SymbolLimitsConfig)  // 设置交易对分级上限
+- (e *Executor) SymbolLimits() config.SymbolLimitsConfig        // 获取交易对分级上限
+
+开仓和加仓前按交易对所属分级截断：
+- 下单数量：持仓名义价值（含已有持仓）不超过 max_notional_usdt，超出部分直接缩减
+- 杠杆：决策建议的杠杆、启动检查的目标杠杆都不超过 max_leverage
+*/
+package executor
+
+import (
+	"fmt"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// SetSymbolLimits 设置交易对分级上限
+func (e *Executor) SetSymbolLimits(cfg config.SymbolLimitsConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.symbolLimits = cfg
+}
+
+// SymbolLimits 获取交易对分级上限
+func (e *Executor) SymbolLimits() config.SymbolLimitsConfig {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.symbolLimits
+}
+
+// capLeverage 按分级上限截断杠杆（leverage为0时原样返回）
+func (e *Executor) capLeverage(symbol string, leverage int) int {
+	_, tier := e.SymbolLimits().ForSymbol(symbol)
+	if tier.MaxLeverage > 0 && leverage > tier.MaxLeverage {
+		return tier.MaxLeverage
+	}
+	return leverage
+}
+
+// hasLeverageCaps 是否配置了任何分级杠杆上限
+func (e *Executor) hasLeverageCaps() bool {
+	limits := e.SymbolLimits()
+	if limits.Default.MaxLeverage > 0 {
+		return true
+	}
+	for _, tier := range limits.Tiers {
+		if tier.MaxLeverage > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// applyDecisionLeverage 按决策建议设置杠杆（截断到分级上限，现货和未给出杠杆时跳过）
+func (e *Executor) applyDecisionLeverage(decision *Decision) error {
+	if decision.Leverage <= 0 || e.client.IsSpot() {
+		return nil
+	}
+
+	leverage := e.capLeverage(decision.Symbol, decision.Leverage)
+	if leverage != decision.Leverage {
+		utils.Info("建议杠杆超过分级上限，已截断",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", decision.Symbol),
+			zap.Int("suggested", decision.Leverage),
+			zap.Int("leverage", leverage),
+		)
+	}
+	if err := e.client.SetLeverage(decision.Symbol, leverage); err != nil {
+		return fmt.Errorf("设置杠杆失败: %w", err)
+	}
+	return nil
+}
+
+// capNotional 按分级上限截断下单数量（下单单位，币本位为合约张数）
+// existing: 已有持仓数量（同为下单单位），加仓时计入上限
+// 返回截断后的数量和执行备注（未截断时备注为空）
+func (e *Executor) capNotional(symbol string, quantity, existing float64, rules *binance.SymbolInfo) (float64, string, error) {
+	tierName, tier := e.SymbolLimits().ForSymbol(symbol)
+	if tier.MaxNotionalUSDT <= 0 {
+		return quantity, "", nil
+	}
+
+	price, err := e.referencePrice(symbol)
+	if err != nil {
+		return 0, "", fmt.Errorf("获取价格失败，无法检查名义价值上限: %w", err)
+	}
+
+	current := rules.Notional(existing, price)
+	if current+rules.Notional(quantity, price) <= tier.MaxNotionalUSDT {
+		return quantity, "", nil
+	}
+
+	remaining := tier.MaxNotionalUSDT - current
+	if remaining <= 0 {
+		return 0, "", fmt.Errorf("持仓名义价值 %.2f 已达到分级[%s]上限 %.2f", current, tierName, tier.MaxNotionalUSDT)
+	}
+	capped := quantity * remaining / rules.Notional(quantity, price)
+
+	utils.Info("下单数量超过分级名义价值上限，已缩减",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", symbol),
+		zap.String("tier", tierName),
+		zap.Float64("quantity", quantity),
+		zap.Float64("capped", capped),
+		zap.Float64("max_notional", tier.MaxNotionalUSDT),
+	)
+	return capped, fmt.Sprintf("按分级[%s]名义价值上限%.0f缩减数量", tierName, tier.MaxNotionalUSDT), nil
+}
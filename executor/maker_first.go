@@ -0,0 +1,90 @@
+/*
+Package executor Maker优先入场（只挂被动单，多次改价后再市价补齐）
+
+主要功能：
+- (e *Executor) enterMakerFirst(...)  // Maker优先入场（entry_type: maker_first）
+
+流程：
+1. 读取最优挂单价格（优先使用本地订单簿），做多挂买一价、做空挂卖一价，可按 price_offset_ticks 向价差内移动，但不会越过对手价
+2. 以 GTX（Post Only）挂单，若会立即成交则被交易所拒绝，保证只付Maker手续费
+3. 等待 limit_timeout_sec 秒，未成交部分撤单后按最新盘口重新挂单，最多 max_reprices 次
+4. 仍未完全成交时按 fallback 配置市价补齐或放弃
+*/
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// enterMakerFirst Maker优先入场
+func (e *Executor) enterMakerFirst(symbol, side string, quantity float64, rules *binance.SymbolInfo, decisionID string, exec config.ExecutionConfig) (*entryResult, error) {
+	result := &entryResult{}
+	timeout := time.Duration(exec.LimitTimeoutSec) * time.Second
+
+	// 首次挂单 + max_reprices 次改价
+	for attempt := 0; attempt <= exec.MaxReprices; attempt++ {
+		remaining := quantity - result.FilledQty
+		if isDust(remaining, rules) {
+			break
+		}
+
+		bid, ask, err := e.bestBidAsk(symbol)
+		if err != nil {
+			utils.Warn("获取盘口失败，停止Maker挂单",
+				zap.String("account_id", e.accountID),
+				zap.String("symbol", symbol),
+				zap.Error(err),
+			)
+			break
+		}
+
+		price := makerPrice(side, bid, ask, rules.TickSize, exec.PriceOffsetTicks)
+		clientOrderID := ClientOrderID(LegEntry, e.accountID, symbol, decisionID, fmt.Sprintf("mf%d", attempt))
+
+		utils.Debug("Maker挂单",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", symbol),
+			zap.Int("attempt", attempt),
+			zap.Float64("price", price),
+			zap.Float64("quantity", remaining),
+		)
+
+		e.limitAttempt(result, symbol, side, remaining, price, rules, "GTX", timeout, clientOrderID)
+	}
+
+	return e.fillRemainder(result, symbol, side, quantity, rules, decisionID, exec)
+}
+
+// makerPrice 计算Maker挂单价格
+// 做多：买一价 + offset个tick，但不超过 卖一价 - 1个tick
+// 做空：卖一价 - offset个tick，但不低于 买一价 + 1个tick
+func makerPrice(side string, bid, ask, tickSize float64, offsetTicks int) float64 {
+	offset := float64(offsetTicks) * tickSize
+
+	if side == binance.SideBuy {
+		price := bid + offset
+		if limit := ask - tickSize; tickSize > 0 && price > limit {
+			price = limit
+		}
+		if price < bid {
+			price = bid
+		}
+		return price
+	}
+
+	price := ask - offset
+	if limit := bid + tickSize; tickSize > 0 && price < limit {
+		price = limit
+	}
+	if price > ask {
+		price = ask
+	}
+	return price
+}
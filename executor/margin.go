@@ -0,0 +1,138 @@
+/*
+Package executor 逐仓持仓自动追加保证金
+
+主要功能：
+- (e *Executor) SetMarginTopUp(cfg config.MarginTopUpConfig)  // 设置自动追加保证金规则
+- (e *Executor) CheckMarginTopUp()                            // 检查所有逐仓持仓，强平距离低于下限时追加保证金
+
+强平距离 = |标记价格 - 强平价格| / 标记价格（设置了标记价格推送时使用推送的最新标记价格）。
+逐仓U本位合约每追加 ΔM USDT 保证金，强平价格约向远离标记价格的方向移动 ΔM / |持仓数量|，
+按此估算把强平距离恢复到目标值所需的保证金（忽略维持保证金率的阶梯变化，追加后下一轮监控会再次检查）。
+*/
+package executor
+
+import (
+	"math"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// SetMarginTopUp 设置自动追加保证金规则
+func (e *Executor) SetMarginTopUp(cfg config.MarginTopUpConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.marginTopUp = cfg
+}
+
+// CheckMarginTopUp 检查所有逐仓持仓，强平距离低于下限时追加保证金
+// 只支持U本位合约（币本位保证金以标的币种计，现货没有强平）
+func (e *Executor) CheckMarginTopUp() {
+	e.mu.Lock()
+	cfg := e.marginTopUp
+	e.mu.Unlock()
+
+	if !cfg.Enabled || e.client.MarketType() != binance.MarketTypeUSDTM {
+		return
+	}
+
+	risks, err := e.client.GetPositionRisk("")
+	if err != nil {
+		utils.Warn("查询持仓风险失败，跳过保证金检查", zap.String("account_id", e.accountID), zap.Error(err))
+		return
+	}
+
+	open := make(map[string]bool)
+	for _, risk := range risks {
+		qty := risk.Quantity()
+		if qty == 0 || binance.NormalizeMarginType(risk.MarginType) != binance.MarginTypeIsolated {
+			continue
+		}
+		open[risk.Symbol] = true
+		e.topUpPosition(risk, qty, cfg)
+	}
+
+	// 已平仓的交易对清零累计追加额
+	e.mu.Lock()
+	for symbol := range e.marginAdded {
+		if !open[symbol] {
+			delete(e.marginAdded, symbol)
+		}
+	}
+	e.mu.Unlock()
+}
+
+// topUpPosition 检查单个逐仓持仓的强平距离，低于下限时追加保证金
+func (e *Executor) topUpPosition(risk binance.PositionRisk, qty float64, cfg config.MarginTopUpConfig) {
+	markPrice := risk.MarkPriceFloat()
+	if live, ok := e.livePrice(risk.Symbol); ok {
+		markPrice = live
+	}
+	distancePct, ok := binance.LiquidationDistancePct(markPrice, risk.LiquidationPriceFloat())
+	if !ok {
+		return
+	}
+	if distancePct >= cfg.MinLiqDistancePct {
+		return
+	}
+
+	amount := marginForDistance(markPrice, distancePct, cfg.TargetLiqDistancePct, qty)
+
+	e.mu.Lock()
+	added := e.marginAdded[risk.Symbol]
+	e.mu.Unlock()
+
+	if cfg.MaxAddUSDT > 0 {
+		amount = math.Min(amount, cfg.MaxAddUSDT)
+	}
+	if cfg.MaxTotalUSDT > 0 {
+		amount = math.Min(amount, cfg.MaxTotalUSDT-added)
+	}
+	// 保证金按USDT两位小数追加
+	amount = math.Floor(amount*100) / 100
+	if amount <= 0 {
+		utils.Warn("强平距离低于下限，但已达到累计追加上限",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", risk.Symbol),
+			zap.Float64("liq_distance_pct", distancePct),
+			zap.Float64("added_usdt", added),
+		)
+		return
+	}
+
+	if err := e.client.AdjustIsolatedMargin(risk.Symbol, amount, binance.MarginAdd); err != nil {
+		utils.Error("自动追加保证金失败",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", risk.Symbol),
+			zap.Float64("amount", amount),
+			zap.Error(err),
+		)
+		return
+	}
+
+	e.mu.Lock()
+	e.marginAdded[risk.Symbol] += amount
+	e.mu.Unlock()
+
+	utils.Info("强平距离低于下限，已追加逐仓保证金",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", risk.Symbol),
+		zap.Float64("mark_price", markPrice),
+		zap.Float64("liq_price", risk.LiquidationPriceFloat()),
+		zap.Float64("liq_distance_pct", distancePct),
+		zap.Float64("target_pct", cfg.TargetLiqDistancePct),
+		zap.Float64("amount", amount),
+	)
+}
+
+// marginForDistance 把强平距离从currentPct恢复到targetPct需要追加的保证金（USDT）
+func marginForDistance(markPrice, currentPct, targetPct, qty float64) float64 {
+	if targetPct <= currentPct {
+		return 0
+	}
+	return (targetPct - currentPct) / 100 * markPrice * math.Abs(qty)
+}
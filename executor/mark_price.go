@@ -0,0 +1,62 @@
+/*
+Package executor 推送标记价格
+
+主要功能：
+- (e *Executor) SetMarkPrices(stream *binance.MarkPriceStream)        // 设置标记价格推送（nil表示只使用REST接口）
+- (s *ShadowExecutor) SetMarkPrices(stream *binance.MarkPriceStream)  // 设置标记价格推送（nil表示只使用K线收盘价）
+
+设置后，决策过期检查、分级名义价值上限、板块敞口、币本位张数换算使用推送的标记价格代替最优挂单中间价，
+逐仓保证金检查使用推送的标记价格计算强平距离；影子账号按推送的标记价格模拟成交和计算资金费。
+推送断开或数据超过有效期时自动回退到原来的REST接口。
+*/
+package executor
+
+import (
+	"crypto-ai-trader/binance"
+)
+
+// SetMarkPrices 设置标记价格推送（nil表示只使用REST接口）
+func (e *Executor) SetMarkPrices(stream *binance.MarkPriceStream) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.markPrices = stream
+}
+
+// livePrice 推送的最新标记价格（未设置推送或数据已失效时返回false）
+func (e *Executor) livePrice(symbol string) (float64, bool) {
+	e.mu.Lock()
+	stream := e.markPrices
+	e.mu.Unlock()
+
+	return stream.Price(symbol)
+}
+
+// referencePrice 参考价格：推送的标记价格，不可用时为最优挂单中间价
+func (e *Executor) referencePrice(symbol string) (float64, error) {
+	if price, ok := e.livePrice(symbol); ok {
+		return price, nil
+	}
+	ticker, err := e.client.GetBookTicker(symbol)
+	if err != nil {
+		return 0, err
+	}
+	return (ticker.BidPriceFloat() + ticker.AskPriceFloat()) / 2, nil
+}
+
+// SetMarkPrices 设置标记价格推送（nil表示只使用K线收盘价）
+func (s *ShadowExecutor) SetMarkPrices(stream *binance.MarkPriceStream) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.markPrices = stream
+}
+
+// liveMarkPrice 推送的最新标记价格和资金费率（未设置推送或数据已失效时返回false）
+func (s *ShadowExecutor) liveMarkPrice(symbol string) (binance.MarkPriceUpdate, bool) {
+	s.mu.Lock()
+	stream := s.markPrices
+	s.mu.Unlock()
+
+	return stream.Get(symbol)
+}
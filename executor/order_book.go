@@ -0,0 +1,72 @@
+/*
+Package executor 推送维护的本地订单簿
+
+主要功能：
+- (e *Executor) SetOrderBooks(books *binance.DepthStream)  // 设置本地订单簿（nil表示只使用REST接口）
+- (e *Executor) SyncOrderBookSymbols()                     // 按入场中和持仓中的交易对更新本地订单簿的订阅
+
+持仓中（括号订单生效）和入场中的交易对通过增量深度推送维护本地订单簿，
+滑点预估和Maker优先入场的挂单价格直接读取本地订单簿，不再每次请求REST深度和最优挂单接口。
+新开始入场的交易对需要先完成快照同步，同步完成前以及推送中断时自动回退到REST接口。
+*/
+package executor
+
+import (
+	"crypto-ai-trader/binance"
+)
+
+// SetOrderBooks 设置本地订单簿（nil表示只使用REST接口）
+func (e *Executor) SetOrderBooks(books *binance.DepthStream) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.orderBooks = books
+}
+
+// SyncOrderBookSymbols 按入场中和持仓中的交易对更新本地订单簿的订阅
+func (e *Executor) SyncOrderBookSymbols() {
+	e.mu.Lock()
+	books := e.orderBooks
+	symbols := make([]string, 0, len(e.theses)+len(e.brackets))
+	seen := make(map[string]bool)
+	for symbol := range e.theses {
+		seen[symbol] = true
+		symbols = append(symbols, symbol)
+	}
+	for symbol := range e.brackets {
+		if !seen[symbol] {
+			symbols = append(symbols, symbol)
+		}
+	}
+	e.mu.Unlock()
+
+	books.SetSymbols(e.accountID, symbols)
+}
+
+// getOrderBook 订单簿深度：本地订单簿已同步时直接读取，否则请求REST接口
+func (e *Executor) getOrderBook(symbol string, limit int) (*binance.OrderBook, error) {
+	e.mu.Lock()
+	books := e.orderBooks
+	e.mu.Unlock()
+
+	if book, ok := books.OrderBook(symbol, limit); ok {
+		return book, nil
+	}
+	return e.client.GetOrderBook(symbol, limit)
+}
+
+// bestBidAsk 买一卖一价：本地订单簿已同步时直接读取，否则请求最优挂单接口
+func (e *Executor) bestBidAsk(symbol string) (float64, float64, error) {
+	e.mu.Lock()
+	books := e.orderBooks
+	e.mu.Unlock()
+
+	if bid, ask, ok := books.BestBidAsk(symbol); ok {
+		return bid, ask, nil
+	}
+	ticker, err := e.client.GetBookTicker(symbol)
+	if err != nil {
+		return 0, 0, err
+	}
+	return ticker.BidPriceFloat(), ticker.AskPriceFloat(), nil
+}
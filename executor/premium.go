@@ -0,0 +1,76 @@
+/*
+Package executor 标记价格偏离指数价格时暂停开仓
+
+主要功能：
+- (e *Executor) SetPremiumGuard(maxPct float64)        // 设置溢价上限（0表示不检查）
+- (s *ShadowExecutor) SetPremiumGuard(maxPct float64)  // 设置溢价上限（影子账号同样拒绝开仓）
+
+标记价格相对指数价格的偏离（溢价）绝对值超过上限时拒绝开仓和加仓，平仓不受影响。
+永续合约溢价严重失真说明合约价格脱离现货（资金费率、强平价格随之失真），此时入场的风险难以估计。
+执行器优先使用标记价格推送，推送不可用时通过REST接口获取；影子账号只使用推送，没有推送数据时不检查。
+*/
+package executor
+
+import (
+	"fmt"
+	"math"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// SetPremiumGuard 设置溢价上限（%，标记价格偏离指数价格超过该值时暂停开仓，0表示不检查）
+func (e *Executor) SetPremiumGuard(maxPct float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.maxPremium = maxPct
+}
+
+// checkPremium 溢价超过上限时拒绝开仓和加仓（获取溢价失败时不拒绝）
+func (e *Executor) checkPremium(symbol string) error {
+	e.mu.Lock()
+	maxPct, stream := e.maxPremium, e.markPrices
+	e.mu.Unlock()
+
+	if maxPct <= 0 {
+		return nil
+	}
+	premium, err := binance.PremiumPct(stream, e.client, symbol)
+	if err != nil {
+		utils.Warn("获取溢价失败，跳过溢价检查", zap.String("account_id", e.accountID), zap.String("symbol", symbol), zap.Error(err))
+		return nil
+	}
+	if math.Abs(premium) > maxPct {
+		utils.Warn("标记价格偏离指数价格，暂停开仓",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", symbol),
+			zap.Float64("premium_pct", premium),
+			zap.Float64("max_pct", maxPct),
+		)
+		return fmt.Errorf("标记价格偏离指数价格 %.3f%%（上限 %.3f%%），暂停开仓", premium, maxPct)
+	}
+	return nil
+}
+
+// SetPremiumGuard 设置溢价上限（%，0表示不检查）
+func (s *ShadowExecutor) SetPremiumGuard(maxPct float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maxPremium = maxPct
+}
+
+// premiumReason 溢价超过上限时返回拒绝原因（调用方持有s.mu，没有推送数据时返回空）
+func (s *ShadowExecutor) premiumReason(symbol string) string {
+	if s.maxPremium <= 0 {
+		return ""
+	}
+	premium, ok := s.markPrices.Premium(symbol)
+	if !ok || math.Abs(premium) <= s.maxPremium {
+		return ""
+	}
+	return fmt.Sprintf("标记价格偏离指数价格 %.3f%%（上限 %.3f%%），暂停开仓", premium, s.maxPremium)
+}
@@ -0,0 +1,209 @@
+/*
+Package executor 盈利加仓（金字塔加仓）
+
+主要功能：
+- (e *Executor) SetPyramiding(cfg config.PyramidingConfig)         // 设置加仓规则
+- (e *Executor) Pyramiding() config.PyramidingConfig               // 获取加仓规则
+- (e *Executor) AddToPosition(decision *Decision) (*Bracket, error)  // 按加仓规则在已有括号订单上加仓
+
+加仓规则：
+ 1. 只在同方向已有括号订单时加仓，次数不超过 max_adds；只平仓模式、连环强平、溢价超限时与开仓一样拒绝加仓
+ 2. 价格较上次入场价朝有利方向移动至少 min_move_pct
+ 3. 第n次加仓数量 = 首笔成交数量 × size_ratio^n（越加越少）
+ 4. 成交后重新计算整体止损：取原止损、决策止损中更紧的一个，启用 breakeven_stop 时至少移到新的开仓均价；
+    止损只会收紧不会放宽，然后按新的持仓数量同步止损止盈单
+*/
+package executor
+
+import (
+	"fmt"
+	"math"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// SetPyramiding 设置加仓规则
+func (e *Executor) SetPyramiding(cfg config.PyramidingConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pyramiding = cfg
+}
+
+// Pyramiding 获取加仓规则
+func (e *Executor) Pyramiding() config.PyramidingConfig {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.pyramiding
+}
+
+// AddToPosition 按加仓规则在已有括号订单上加仓，成交后重新计算整体止损并同步止损止盈单
+// 不满足加仓规则时返回错误，不会下单
+func (e *Executor) AddToPosition(decision *Decision) (*Bracket, error) {
+	cfg := e.Pyramiding()
+	if !cfg.Enabled {
+		return nil, fmt.Errorf("未启用加仓: %s", decision.Symbol)
+	}
+	// 加仓同样增加敞口，与开仓一样受只平仓模式、连环强平和溢价限制
+	if err := e.checkCloseOnly(decision.Symbol); err != nil {
+		return nil, err
+	}
+	if err := e.checkCascade(decision.Symbol); err != nil {
+		return nil, err
+	}
+	if err := e.checkPremium(decision.Symbol); err != nil {
+		return nil, err
+	}
+
+	bracket := e.GetBracket(decision.Symbol)
+	if bracket == nil {
+		return nil, fmt.Errorf("交易对没有生效中的括号订单，无法加仓: %s", decision.Symbol)
+	}
+	if (decision.Action == ActionOpenLong) != bracket.IsLong() {
+		return nil, fmt.Errorf("加仓方向与持仓方向不一致: %s", decision.Action)
+	}
+	if bracket.Adds >= cfg.MaxAdds {
+		return nil, fmt.Errorf("已达到最多加仓次数: %d", cfg.MaxAdds)
+	}
+
+	rules, err := e.getSymbolRules(decision.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker, err := e.client.GetBookTicker(decision.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取价格失败: %w", err)
+	}
+	price := (ticker.BidPriceFloat() + ticker.AskPriceFloat()) / 2
+
+	if move := favorableMovePct(bracket, price); move < cfg.MinMovePct {
+		return nil, fmt.Errorf("价格较上次入场有利移动%.2f%%，未达到加仓要求%.2f%%", move, cfg.MinMovePct)
+	}
+
+	initial := bracket.InitialQuantity
+	if initial <= 0 {
+		initial = bracket.Quantity
+	}
+	quantity := initial * math.Pow(cfg.SizeRatio, float64(bracket.Adds+1))
+	quantity, capNote, err := e.capNotional(decision.Symbol, quantity, bracket.Quantity, rules)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkSectorExposure(decision.Symbol, quantity, rules); err != nil {
+		return nil, err
+	}
+	if isDust(quantity, rules) {
+		return nil, fmt.Errorf("加仓数量过小: %s (最小数量 %v)", rules.FormatQuantity(quantity), rules.MinQty)
+	}
+
+	// 加仓期间监控不按持仓数量同步止损止盈单，成交后统一同步
+	e.mu.Lock()
+	bracket.adding = true
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		bracket.adding = false
+		e.mu.Unlock()
+	}()
+
+	// 拆单加仓时每笔成交后按累计数量同步止损止盈单，加仓部分不会在拆单期间失去保护
+	baseQty := bracket.Quantity
+	syncSlice := func(filled *entryResult) error {
+		positionAmt := baseQty + filled.FilledQty
+		if !bracket.IsLong() {
+			positionAmt = -positionAmt
+		}
+		return e.SyncExitOrders(bracket, positionAmt, 0)
+	}
+
+	entry, err := e.enter(decision.Symbol, bracket.Side, quantity, rules, decision.Hash(), syncSlice)
+	if err != nil {
+		return nil, fmt.Errorf("加仓下单失败: %w", err)
+	}
+	if entry.FilledQty <= 0 {
+		return nil, fmt.Errorf("加仓订单未成交")
+	}
+
+	positionAmt, avgEntry, err := e.getPosition(decision.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("加仓后查询持仓失败: %w", err)
+	}
+	if e.client.IsSpot() {
+		// 现货没有开仓均价，按成交加权计算
+		avgEntry = (bracket.EntryPrice*bracket.Quantity + entry.AvgPrice*entry.FilledQty) / (bracket.Quantity + entry.FilledQty)
+	}
+
+	e.mu.Lock()
+	oldStop := bracket.StopLoss
+	bracket.StopLoss = combinedStop(bracket, decision.StopLoss, avgEntry, price, cfg.BreakevenStop)
+	if decision.TakeProfit > 0 && (decision.TakeProfit > price) == bracket.IsLong() {
+		bracket.TakeProfit = decision.TakeProfit
+	}
+	bracket.Adds++
+	bracket.LastEntryPrice = entry.AvgPrice
+	if capNote != "" {
+		bracket.ExecutionNote = appendNote(bracket.ExecutionNote, capNote)
+	}
+	e.mu.Unlock()
+	e.recordAction(bracket.Symbol)
+
+	utils.Info("盈利加仓成交",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", bracket.Symbol),
+		zap.Int("adds", bracket.Adds),
+		zap.Float64("add_quantity", entry.FilledQty),
+		zap.Float64("add_price", entry.AvgPrice),
+		zap.Float64("avg_entry", avgEntry),
+		zap.Float64("old_stop_loss", oldStop),
+		zap.Float64("stop_loss", bracket.StopLoss),
+	)
+
+	if err := e.SyncExitOrders(bracket, positionAmt, avgEntry); err != nil {
+		return bracket, fmt.Errorf("加仓后同步止损止盈单失败: %w", err)
+	}
+	return bracket, nil
+}
+
+// favorableMovePct 当前价格较上次入场价朝有利方向移动的百分比（不利方向为负）
+func favorableMovePct(bracket *Bracket, price float64) float64 {
+	last := bracket.LastEntryPrice
+	if last <= 0 {
+		last = bracket.EntryPrice
+	}
+	if last <= 0 {
+		return 0
+	}
+
+	move := (price - last) / last * 100
+	if !bracket.IsLong() {
+		move = -move
+	}
+	return move
+}
+
+// combinedStop 加仓后的整体止损价（只收紧不放宽，且不越过当前价格）
+func combinedStop(bracket *Bracket, decisionStop, avgEntry, price float64, breakeven bool) float64 {
+	stop := bracket.StopLoss
+	tighter := func(candidate float64) {
+		if candidate <= 0 {
+			return
+		}
+		if bracket.IsLong() && candidate > stop && candidate < price {
+			stop = candidate
+		}
+		if !bracket.IsLong() && candidate < stop && candidate > price {
+			stop = candidate
+		}
+	}
+
+	tighter(decisionStop)
+	if breakeven {
+		tighter(avgEntry)
+	}
+	return stop
+}
@@ -0,0 +1,141 @@
+/*
+Package executor 按比例减仓
+
+主要功能：
+- (e *Executor) ReducePosition(symbol string, fraction float64) (float64, error)        // 市价减掉持仓的一部分并同步止损止盈单
+- (s *ShadowExecutor) ReducePosition(symbol string, fraction float64) (float64, error)  // 模拟减仓并把减掉的部分写入交易日志
+
+fraction 为减掉的比例（0-1），大于等于1时全部平仓。返回实际减掉的数量，没有持仓时返回0。
+减仓后剩余仓位仍由原括号订单保护：实盘按剩余数量重新挂出止损止盈单，同步失败时由括号订单监控在下一次检查时重试。
+*/
+package executor
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// ReducePosition 市价减掉持仓的一部分并同步止损止盈单
+func (e *Executor) ReducePosition(symbol string, fraction float64) (float64, error) {
+	if fraction <= 0 {
+		return 0, fmt.Errorf("减仓比例必须大于0: %v", fraction)
+	}
+	defer e.saveBrackets()
+
+	positionAmt, entryPrice, err := e.getPosition(symbol)
+	if err != nil {
+		return 0, fmt.Errorf("查询持仓失败: %w", err)
+	}
+	if positionAmt == 0 {
+		return 0, nil
+	}
+	if fraction >= 1 {
+		if err := e.ClosePosition(symbol); err != nil {
+			return 0, err
+		}
+		return math.Abs(positionAmt), nil
+	}
+
+	rules, err := e.getSymbolRules(symbol)
+	if err != nil {
+		return 0, err
+	}
+	quantity := rules.FormatQuantity(math.Abs(positionAmt) * fraction)
+	reduced, _ := strconv.ParseFloat(quantity, 64)
+	if reduced <= 0 {
+		return 0, fmt.Errorf("减仓数量小于最小下单数量: %s %v × %v", symbol, positionAmt, fraction)
+	}
+
+	side := binance.SideSell
+	if positionAmt < 0 {
+		side = binance.SideBuy
+	}
+	req := &binance.OrderRequest{
+		Symbol:   symbol,
+		Side:     side,
+		Type:     binance.OrderTypeMarket,
+		Quantity: quantity,
+	}
+	if _, err := e.submitExitOrder(req); err != nil {
+		return 0, fmt.Errorf("减仓失败: %w", err)
+	}
+	e.recordAction(symbol)
+
+	// 剩余仓位按新数量重新挂出止损止盈单
+	remaining := positionAmt - math.Copysign(reduced, positionAmt)
+	if bracket := e.GetBracket(symbol); bracket != nil && remaining != 0 {
+		if err := e.SyncExitOrders(bracket, remaining, entryPrice); err != nil {
+			utils.Warn("减仓后同步止损止盈单失败，等待括号订单监控重试",
+				zap.String("account_id", e.accountID),
+				zap.String("symbol", symbol),
+				zap.Error(err),
+			)
+		}
+	}
+
+	utils.Info("减仓完成",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", symbol),
+		zap.Float64("position_amt", positionAmt),
+		zap.Float64("reduced", reduced),
+	)
+	return reduced, nil
+}
+
+// ReducePosition 模拟减仓并把减掉的部分写入交易日志
+func (s *ShadowExecutor) ReducePosition(symbol string, fraction float64) (float64, error) {
+	if fraction <= 0 {
+		return 0, fmt.Errorf("减仓比例必须大于0: %v", fraction)
+	}
+
+	s.mu.Lock()
+	_, exists := s.positions[symbol]
+	s.mu.Unlock()
+	if !exists {
+		return 0, nil
+	}
+
+	price, err := s.lastPrice(symbol)
+	if err != nil {
+		return 0, fmt.Errorf("获取最新价格失败: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pos, ok := s.positions[symbol]
+	if !ok {
+		return 0, nil
+	}
+	b := pos.Bracket
+	now := time.Now()
+	if fraction >= 1 {
+		reduced := b.Quantity
+		s.closeLocked(pos, price, CloseReasonPositionClosed, now)
+		return reduced, nil
+	}
+
+	reduced := b.Quantity * fraction
+	s.recordLocked(pos, reduced, price, CloseReasonReduced, now)
+	pos.Commission *= 1 - fraction
+	pos.Funding *= 1 - fraction
+	b.Quantity -= reduced
+	s.lastActions[symbol] = now
+	s.saveLocked()
+
+	utils.Info("模拟减仓完成",
+		zap.String("account_id", s.accountID),
+		zap.String("symbol", symbol),
+		zap.Float64("price", price),
+		zap.Float64("reduced", reduced),
+		zap.Float64("remaining", b.Quantity),
+	)
+	return reduced, nil
+}
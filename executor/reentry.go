@@ -0,0 +1,134 @@
+/*
+Package executor 止损后重新入场
+
+主要功能：
+- (e *Executor) SetReentry(cfg config.ReentryConfig)  // 设置止损后重新入场规则
+- (e *Executor) Reentry() config.ReentryConfig        // 获取止损后重新入场规则
+
+括号订单止损后记录止损事件（方向、原入场价、时间）。窗口期内同方向的开仓决策只允许作为一次重新入场：
+1. 价格重新站回原入场价（做多高于、做空低于原入场价），即原交易逻辑重新成立
+2. 按 timeframe 计算的共振评分不低于 min_score
+重新入场的括号订单标记为 Reentry，它再次止损后窗口内不再允许同方向入场，避免反复止损、反复入场。
+反方向开仓不受限制，窗口结束后恢复正常入场。
+*/
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// confluenceKlines 计算共振评分获取的K线数量
+const confluenceKlines = 100
+
+// stopOut 止损事件
+type stopOut struct {
+	side        string    // 被止损的入场方向
+	level       float64   // 原入场价（重新入场需站回的价位）
+	at          time.Time // 止损时间
+	reentryUsed bool      // 被止损的是否已经是重新入场的仓位
+}
+
+// SetReentry 设置止损后重新入场规则
+func (e *Executor) SetReentry(cfg config.ReentryConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.reentry = cfg
+}
+
+// Reentry 获取止损后重新入场规则
+func (e *Executor) Reentry() config.ReentryConfig {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.reentry
+}
+
+// recordStopOut 记录止损事件（未启用重新入场规则时不记录）
+func (e *Executor) recordStopOut(bracket *Bracket) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.reentry.Enabled {
+		return
+	}
+	e.stopOuts[bracket.Symbol] = &stopOut{
+		side:        bracket.Side,
+		level:       bracket.EntryPrice,
+		at:          bracket.ClosedAt,
+		reentryUsed: bracket.Reentry,
+	}
+}
+
+// checkReentry 检查开仓决策是否受止损后重新入场规则限制
+// 返回true表示本次开仓是止损后的重新入场，不满足规则时返回错误
+func (e *Executor) checkReentry(decision *Decision, side string) (bool, error) {
+	cfg := e.Reentry()
+
+	e.mu.Lock()
+	event := e.stopOuts[decision.Symbol]
+	if event != nil && time.Since(event.at) > time.Duration(cfg.WindowMinutes)*time.Minute {
+		delete(e.stopOuts, decision.Symbol)
+		event = nil
+	}
+	e.mu.Unlock()
+
+	if !cfg.Enabled || event == nil || event.side != side {
+		return false, nil
+	}
+	if event.reentryUsed {
+		return false, fmt.Errorf("重新入场的仓位已再次止损，%d分钟窗口内不再同方向入场", cfg.WindowMinutes)
+	}
+
+	klines, err := e.client.GetKlines(decision.Symbol, cfg.Timeframe, confluenceKlines)
+	if err != nil {
+		return false, fmt.Errorf("获取K线失败，无法检查重新入场条件: %w", err)
+	}
+	ticker, err := e.client.GetBookTicker(decision.Symbol)
+	if err != nil {
+		return false, fmt.Errorf("获取价格失败，无法检查重新入场条件: %w", err)
+	}
+	price := (ticker.BidPriceFloat() + ticker.AskPriceFloat()) / 2
+
+	direction := indicators.DirectionLong
+	reclaimed := price > event.level
+	if side != binance.SideBuy {
+		direction = indicators.DirectionShort
+		reclaimed = price < event.level
+	}
+	score := indicators.CalculateConfluenceScore(klines, direction)
+
+	utils.Info("检查止损后重新入场条件",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", decision.Symbol),
+		zap.String("side", side),
+		zap.Float64("level", event.level),
+		zap.Float64("price", price),
+		zap.Bool("reclaimed", reclaimed),
+		zap.Float64("score", score),
+	)
+
+	if !reclaimed {
+		return false, fmt.Errorf("止损后价格未站回原入场价 %v（当前 %v），不重新入场", event.level, price)
+	}
+	if score < cfg.MinScore {
+		return false, fmt.Errorf("止损后共振评分 %.0f 低于 %.0f，不重新入场", score, cfg.MinScore)
+	}
+	return true, nil
+}
+
+// clearStopOut 重新入场后清除止损事件（再次止损时按新仓位记录）
+func (e *Executor) clearStopOut(symbol string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.stopOuts, symbol)
+}
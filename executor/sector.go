@@ -0,0 +1,73 @@
+/*
+Package executor 板块敞口限制
+
+主要功能：
+- (e *Executor) SetSectors(sectors *config.SectorsConfig)  // 设置板块配置（nil表示不限制）
+
+开仓和加仓前按板块配置计算同板块（如L1、meme、DeFi、AI）所有生效中括号订单的名义价值合计（按开仓均价），
+加上本次下单后超过板块上限时拒绝执行，避免账号集中押注同一题材。未归类的交易对不受限制。
+*/
+package executor
+
+import (
+	"fmt"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// SetSectors 设置板块配置（nil表示不限制）
+func (e *Executor) SetSectors(sectors *config.SectorsConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.sectors = sectors
+}
+
+// checkSectorExposure 检查本次下单后板块敞口是否超过上限
+// quantity: 本次下单数量（下单单位，币本位为合约张数）
+func (e *Executor) checkSectorExposure(symbol string, quantity float64, rules *binance.SymbolInfo) error {
+	e.mu.Lock()
+	sectors := e.sectors
+	e.mu.Unlock()
+
+	sector := sectors.SectorOf(symbol)
+	limit := sectors.MaxExposure(sector)
+	if limit <= 0 {
+		return nil
+	}
+
+	exposure := 0.0
+	for _, bracket := range e.GetBrackets() {
+		if sectors.SectorOf(bracket.Symbol) != sector {
+			continue
+		}
+		bracketRules, err := e.getSymbolRules(bracket.Symbol)
+		if err != nil {
+			return err
+		}
+		exposure += bracketRules.Notional(bracket.Quantity, bracket.EntryPrice)
+	}
+
+	price, err := e.referencePrice(symbol)
+	if err != nil {
+		return fmt.Errorf("获取价格失败，无法检查板块敞口: %w", err)
+	}
+	added := rules.Notional(quantity, price)
+
+	if exposure+added > limit {
+		utils.Warn("板块敞口超过上限，拒绝下单",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", symbol),
+			zap.String("sector", sector),
+			zap.Float64("exposure", exposure),
+			zap.Float64("added", added),
+			zap.Float64("limit", limit),
+		)
+		return fmt.Errorf("板块[%s]敞口 %.2f + 本次 %.2f 超过上限 %.2f", sector, exposure, added, limit)
+	}
+	return nil
+}
@@ -0,0 +1,568 @@
+/*
+Package executor 影子模式执行器（只记录决策和模拟成交，不下真实订单）
+
+主要功能：
+- NewShadowExecutor(accountID string, market exchange.MarketData, j *journal.Journal, stateDir string, initialBalance float64) *ShadowExecutor  // 创建影子执行器并加载保存的模拟持仓
+- (s *ShadowExecutor) Execute(decision *Decision) (*ShadowDecision, error)  // 记录决策并模拟开仓/平仓
+- (s *ShadowExecutor) SetStaleness(cfg config.StalenessConfig, ttl time.Duration)  // 设置决策过期规则（与实盘执行器相同）
+- (s *ShadowExecutor) Monitor(ctx context.Context, interval time.Duration)  // 定时检查模拟持仓的止损止盈和资金费
+- (s *ShadowExecutor) CheckPositions()                                      // 检查一次模拟持仓
+- (s *ShadowExecutor) GetBrackets() []*Bracket                              // 获取模拟持仓（与实盘括号订单格式相同）
+- (s *ShadowExecutor) Status() (*ShadowStatus, error)                       // 获取虚拟账户状态
+- (s *ShadowExecutor) InitialBalance() float64                              // 虚拟初始资金
+
+模拟规则：
+- 开仓、平仓按最新1分钟K线收盘价成交（设置了标记价格推送时按推送的标记价格），入场和出场都按Taker费率计手续费（journal.DefaultFeeModel）
+- 止损止盈按入场之后的1分钟K线最高/最低价及推送的标记价格判断，同一根K线同时触及时按止损处理，按止损/止盈价成交
+- 资金费在每个结算时间（UTC 0/8/16点）之后的第一次检查时按当前资金费率和最新价格计算（有推送时使用推送的资金费率）
+- 开仓数量使用决策给出的数量，不做仓位计算、交易对限额和板块敞口检查
+
+决策记录追加写入 <stateDir>/<账号ID>.decisions.jsonl，模拟持仓保存在 <stateDir>/<账号ID>.shadow.json，
+结束的模拟交易与实盘交易一样写入交易日志（备注为"影子模式"），绩效指标接口可直接使用。
+*/
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/journal"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 影子决策处理结果
+const (
+	ShadowResultOpened    = "opened"    // 模拟开仓
+	ShadowResultClosed    = "closed"    // 模拟平仓
+	ShadowResultIgnored   = "ignored"   // 观望或没有可平的持仓
+	ShadowResultRejected  = "rejected"  // 决策无效或已有反方向持仓
+	ShadowResultDuplicate = "duplicate" // 已有同方向持仓，重复信号已忽略
+)
+
+// shadowNote 影子交易在交易日志中的备注
+const shadowNote = "影子模式"
+
+// fundingInterval 资金费结算间隔
+const fundingInterval = 8 * time.Hour
+
+// ShadowDecision 一条影子决策记录
+type ShadowDecision struct {
+	Time     time.Time `json:"time"`           // 收到决策的时间
+	Decision Decision  `json:"decision"`       // 决策内容
+	Price    float64   `json:"price"`          // 决策时的最新价格
+	Result   string    `json:"result"`         // 处理结果
+	Note     string    `json:"note,omitempty"` // 说明（如拒绝原因）
+}
+
+// ShadowStatus 虚拟账户状态
+type ShadowStatus struct {
+	InitialBalance float64    `json:"initial_balance"` // 虚拟初始资金
+	RealizedPnL    float64    `json:"realized_pnl"`    // 已结束交易的净盈亏
+	UnrealizedPnL  float64    `json:"unrealized_pnl"`  // 模拟持仓按最近价格计算的浮动盈亏（不含费用）
+	Equity         float64    `json:"equity"`          // 初始资金 + 已实现 + 未实现
+	Trades         int        `json:"trades"`          // 已结束的交易笔数
+	Positions      []*Bracket `json:"positions"`       // 模拟持仓
+}
+
+// shadowPosition 模拟持仓（同时作为持久化格式）
+type shadowPosition struct {
+	Bracket     *Bracket  `json:"bracket"`
+	Commission  float64   `json:"commission"`   // 入场手续费
+	Funding     float64   `json:"funding"`      // 累计资金费
+	LastPrice   float64   `json:"last_price"`   // 最近一次检查的价格
+	CheckedBar  int64     `json:"checked_bar"`  // 已检查完的最后一根已收盘1分钟K线的开盘时间（毫秒）
+	LastFunding time.Time `json:"last_funding"` // 最近一次计算资金费的时间
+}
+
+// ShadowExecutor 影子模式执行器
+type ShadowExecutor struct {
+	accountID      string
+	market         exchange.MarketData
+	journal        *journal.Journal
+	fees           journal.FeeModel
+	initialBalance float64
+	decisionsPath  string
+	statePath      string
+
+	staleness   config.StalenessConfig // 决策过期规则
+	decisionTTL time.Duration          // 决策有效期
+
+	markPrices   *binance.MarkPriceStream   // 标记价格推送（nil表示只使用K线收盘价）
+	liquidations *binance.LiquidationStream // 强平推送（连环强平期间拒绝开仓，nil表示不检查）
+	maxPremium   float64                    // 标记价格偏离指数价格超过该百分比时拒绝开仓（0表示不检查）
+
+	mu          sync.Mutex
+	positions   map[string]*shadowPosition // symbol -> 模拟持仓
+	lastActions map[string]time.Time       // symbol -> 最近一次模拟开仓或平仓的时间
+}
+
+// NewShadowExecutor 创建影子执行器并加载保存的模拟持仓
+func NewShadowExecutor(accountID string, market exchange.MarketData, j *journal.Journal, stateDir string, initialBalance float64) *ShadowExecutor {
+	s := &ShadowExecutor{
+		accountID:      accountID,
+		market:         market,
+		journal:        j,
+		fees:           journal.DefaultFeeModel,
+		initialBalance: initialBalance,
+		decisionsPath:  filepath.Join(stateDir, accountID+".decisions.jsonl"),
+		statePath:      filepath.Join(stateDir, accountID+".shadow.json"),
+		positions:      make(map[string]*shadowPosition),
+		lastActions:    make(map[string]time.Time),
+	}
+
+	data, err := os.ReadFile(s.statePath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			utils.Warn("读取影子持仓失败", zap.String("account_id", accountID), zap.Error(err))
+		}
+		return s
+	}
+	if err := json.Unmarshal(data, &s.positions); err != nil {
+		utils.Warn("解析影子持仓失败", zap.String("account_id", accountID), zap.Error(err))
+		s.positions = make(map[string]*shadowPosition)
+	}
+	return s
+}
+
+// SetStaleness 设置决策过期规则（与实盘执行器相同，过期的开仓决策记录为拒绝）
+func (s *ShadowExecutor) SetStaleness(cfg config.StalenessConfig, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.staleness = cfg
+	s.decisionTTL = ttl
+}
+
+// Execute 记录决策并模拟开仓/平仓
+// 决策无效、已有同交易对持仓等情况不返回错误，处理结果记录在返回值和决策日志中
+func (s *ShadowExecutor) Execute(decision *Decision) (*ShadowDecision, error) {
+	d := *decision
+	d.AccountID = s.accountID
+	record := &ShadowDecision{Time: time.Now(), Decision: d}
+
+	if d.Action == ActionHold {
+		record.Result = ShadowResultIgnored
+		s.appendDecision(record)
+		return record, nil
+	}
+
+	price, err := s.lastPrice(d.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新价格失败: %w", err)
+	}
+	record.Price = price
+
+	s.mu.Lock()
+	switch d.Action {
+	case ActionOpenLong, ActionOpenShort:
+		if active, reason := s.liquidations.Cascade(d.Symbol); active {
+			record.Result, record.Note = ShadowResultRejected, "连环强平进行中，暂停开仓: "+reason
+			break
+		}
+		if reason := s.premiumReason(d.Symbol); reason != "" {
+			record.Result, record.Note = ShadowResultRejected, reason
+			break
+		}
+		if s.staleness.Enabled {
+			d.SetExpiry(s.decisionTTL)
+			record.Decision.ExpiresAt = d.ExpiresAt
+			if err := staleReason(&d, s.staleness, price, record.Time); err != nil {
+				record.Result, record.Note = ShadowResultRejected, err.Error()
+				break
+			}
+		}
+		s.openLocked(&d, price, record)
+	case ActionClose:
+		if pos, ok := s.positions[d.Symbol]; ok {
+			s.closeLocked(pos, price, CloseReasonPositionClosed, record.Time)
+			record.Result = ShadowResultClosed
+		} else {
+			record.Result, record.Note = ShadowResultIgnored, "没有模拟持仓"
+		}
+	default:
+		record.Result, record.Note = ShadowResultRejected, "未知的决策动作: "+d.Action
+	}
+	s.mu.Unlock()
+
+	s.appendDecision(record)
+	utils.Info("影子决策",
+		zap.String("account_id", s.accountID),
+		zap.String("symbol", d.Symbol),
+		zap.String("action", d.Action),
+		zap.Float64("price", price),
+		zap.String("result", record.Result),
+		zap.String("note", record.Note),
+	)
+	return record, nil
+}
+
+// openLocked 模拟开仓（调用方持有锁）
+func (s *ShadowExecutor) openLocked(d *Decision, price float64, record *ShadowDecision) {
+	if err := validateBracketDecision(d); err != nil {
+		record.Result, record.Note = ShadowResultRejected, err.Error()
+		return
+	}
+	if d.Quantity <= 0 {
+		record.Result, record.Note = ShadowResultRejected, "开仓数量必须大于0"
+		return
+	}
+	quantity := d.Quantity
+	if d.SizeFactor > 0 {
+		quantity *= d.SizeFactor
+	}
+
+	side := binance.SideBuy
+	if d.Action == ActionOpenShort {
+		side = binance.SideSell
+	}
+	// 与实盘执行器一致：同方向持仓期间的开仓信号视为重复信号
+	if pos, exists := s.positions[d.Symbol]; exists {
+		if pos.Bracket.Side == side {
+			record.Result, record.Note = ShadowResultDuplicate, "已有同方向模拟持仓"
+		} else {
+			record.Result, record.Note = ShadowResultRejected, "已有反方向模拟持仓"
+		}
+		return
+	}
+
+	bracket := &Bracket{
+		AccountID:       s.accountID,
+		Symbol:          d.Symbol,
+		DecisionID:      d.Hash(),
+		Side:            side,
+		Quantity:        quantity,
+		EntryPrice:      price,
+		StopLoss:        d.StopLoss,
+		TakeProfit:      d.TakeProfit,
+		Status:          BracketStatusActive,
+		ExecutionNote:   shadowNote,
+		InitialQuantity: quantity,
+		LastEntryPrice:  price,
+		EntryStartedAt:  record.Time,
+		CreatedAt:       record.Time,
+	}
+	// 止损已在当前价格错误一侧时不开仓（实盘会立即触发止损）
+	if !stopLossValid(bracket) {
+		record.Result, record.Note = ShadowResultRejected, "止损价已被当前价格越过"
+		return
+	}
+
+	s.positions[d.Symbol] = &shadowPosition{
+		Bracket:     bracket,
+		Commission:  s.fees.Commission(quantity*price, false),
+		LastPrice:   price,
+		CheckedBar:  record.Time.Truncate(time.Minute).UnixMilli(),
+		LastFunding: record.Time,
+	}
+	s.lastActions[d.Symbol] = time.Now()
+	s.saveLocked()
+	record.Result = ShadowResultOpened
+}
+
+// closeLocked 模拟平仓并写入交易日志（调用方持有锁）
+func (s *ShadowExecutor) closeLocked(pos *shadowPosition, price float64, reason string, at time.Time) {
+	b := pos.Bracket
+	b.Status = BracketStatusClosed
+	b.CloseReason = reason
+	b.ClosedAt = at
+	delete(s.positions, b.Symbol)
+	s.lastActions[b.Symbol] = time.Now()
+	s.saveLocked()
+
+	s.recordLocked(pos, b.Quantity, price, reason, at)
+}
+
+// recordLocked 把模拟持仓中平掉的数量写入交易日志，入场手续费和资金费按数量分摊（调用方持有锁）
+func (s *ShadowExecutor) recordLocked(pos *shadowPosition, quantity, price float64, reason string, at time.Time) {
+	if s.journal == nil {
+		return
+	}
+	b := pos.Bracket
+	share := 1.0
+	if b.Quantity > 0 && quantity < b.Quantity {
+		share = quantity / b.Quantity
+	}
+	trade := &journal.Trade{
+		AccountID:   s.accountID,
+		Symbol:      b.Symbol,
+		Side:        b.Side,
+		Quantity:    quantity,
+		Asset:       settleAssetUSDT,
+		EntryPrice:  b.EntryPrice,
+		ExitPrice:   price,
+		StopLoss:    b.StopLoss,
+		TakeProfit:  b.TakeProfit,
+		EntryTime:   b.EntryStartedAt,
+		ExitTime:    at,
+		Commission:  pos.Commission*share + s.fees.Commission(quantity*price, false),
+		Funding:     pos.Funding * share,
+		CloseReason: reason,
+		DecisionID:  b.DecisionID,
+		Note:        shadowNote,
+	}
+	if err := s.journal.Record(trade); err != nil {
+		utils.Error("记录影子交易失败",
+			zap.String("account_id", s.accountID),
+			zap.String("symbol", b.Symbol),
+			zap.Error(err),
+		)
+	}
+}
+
+// Monitor 定时检查模拟持仓，直到ctx取消
+func (s *ShadowExecutor) Monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.CheckPositions()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// CheckPositions 检查一次模拟持仓的止损止盈和资金费
+func (s *ShadowExecutor) CheckPositions() {
+	s.mu.Lock()
+	symbols := make([]string, 0, len(s.positions))
+	for symbol := range s.positions {
+		symbols = append(symbols, symbol)
+	}
+	s.mu.Unlock()
+
+	for _, symbol := range symbols {
+		if err := s.checkPosition(symbol); err != nil {
+			utils.Warn("检查影子持仓失败",
+				zap.String("account_id", s.accountID),
+				zap.String("symbol", symbol),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// checkPosition 检查单个模拟持仓
+func (s *ShadowExecutor) checkPosition(symbol string) error {
+	s.mu.Lock()
+	pos, ok := s.positions[symbol]
+	var checkedBar int64
+	var lastFunding time.Time
+	if ok {
+		checkedBar, lastFunding = pos.CheckedBar, pos.LastFunding
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	// 取上次检查之后的1分钟K线（含当前未收盘的K线）
+	minutes := int(time.Since(time.UnixMilli(checkedBar)).Minutes()) + 2
+	klines, err := s.market.GetKlines(symbol, "1m", min(max(minutes, 2), 1000))
+	if err != nil {
+		return fmt.Errorf("获取K线失败: %w", err)
+	}
+	if len(klines) == 0 {
+		return nil
+	}
+
+	var rate float64
+	now := time.Now()
+	live, hasLive := s.liveMarkPrice(symbol)
+	if fundingDue(lastFunding, now) {
+		if hasLive {
+			rate = live.FundingRate
+		} else if rate, err = s.market.GetFundingRate(symbol); err != nil && !errors.Is(err, exchange.ErrUnsupported) {
+			return fmt.Errorf("获取资金费率失败: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 检查期间持仓可能已被平仓决策关闭
+	if s.positions[symbol] != pos {
+		return nil
+	}
+
+	b := pos.Bracket
+	for _, k := range klines {
+		if k.OpenTime <= pos.CheckedBar {
+			continue
+		}
+		high, low, closePrice, err := parseHLC(k)
+		if err != nil {
+			return err
+		}
+		pos.LastPrice = closePrice
+		if price, reason, hit := shadowExit(b, high, low); hit {
+			s.closeLocked(pos, price, reason, now)
+			return nil
+		}
+		if k.CloseTime < now.UnixMilli() {
+			pos.CheckedBar = k.OpenTime
+		}
+	}
+	// 推送的标记价格比1分钟K线更及时，触及止损止盈时同样按止损/止盈价成交
+	if hasLive {
+		pos.LastPrice = live.MarkPrice
+		if price, reason, hit := shadowExit(b, live.MarkPrice, live.MarkPrice); hit {
+			s.closeLocked(pos, price, reason, now)
+			return nil
+		}
+	}
+
+	if fundingDue(pos.LastFunding, now) {
+		pos.Funding += journal.FundingPayment(b.Side, b.Quantity, pos.LastPrice, rate)
+		pos.LastFunding = now
+	}
+	s.saveLocked()
+	return nil
+}
+
+// GetBrackets 获取模拟持仓
+func (s *ShadowExecutor) GetBrackets() []*Bracket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	brackets := make([]*Bracket, 0, len(s.positions))
+	for _, pos := range s.positions {
+		b := *pos.Bracket
+		brackets = append(brackets, &b)
+	}
+	return brackets
+}
+
+// Status 获取虚拟账户状态（已实现盈亏按交易日志计算）
+func (s *ShadowExecutor) Status() (*ShadowStatus, error) {
+	var summary journal.Summary
+	if s.journal != nil {
+		trades, err := s.journal.Load(s.accountID)
+		if err != nil {
+			return nil, err
+		}
+		summary = journal.Summarize(trades)
+	}
+
+	status := &ShadowStatus{
+		InitialBalance: s.initialBalance,
+		RealizedPnL:    summary.NetPnL,
+		Trades:         summary.Trades,
+		Positions:      s.GetBrackets(),
+	}
+
+	s.mu.Lock()
+	for _, pos := range s.positions {
+		b := pos.Bracket
+		status.UnrealizedPnL += journal.GrossPnL(b.Side, b.Quantity, b.EntryPrice, pos.LastPrice)
+	}
+	s.mu.Unlock()
+
+	status.Equity = status.InitialBalance + status.RealizedPnL + status.UnrealizedPnL
+	return status, nil
+}
+
+// InitialBalance 虚拟初始资金
+func (s *ShadowExecutor) InitialBalance() float64 {
+	return s.initialBalance
+}
+
+// lastPrice 推送的最新标记价格，不可用时为最新1分钟K线收盘价
+func (s *ShadowExecutor) lastPrice(symbol string) (float64, error) {
+	if update, ok := s.liveMarkPrice(symbol); ok {
+		return update.MarkPrice, nil
+	}
+	klines, err := s.market.GetKlines(symbol, "1m", 1)
+	if err != nil {
+		return 0, err
+	}
+	if len(klines) == 0 {
+		return 0, fmt.Errorf("没有K线数据: %s", symbol)
+	}
+	_, _, price, err := parseHLC(klines[len(klines)-1])
+	return price, err
+}
+
+// appendDecision 追加写入决策日志
+func (s *ShadowExecutor) appendDecision(record *ShadowDecision) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line, err := json.Marshal(record)
+	if err == nil {
+		var f *os.File
+		if f, err = os.OpenFile(s.decisionsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
+			_, err = f.Write(append(line, '\n'))
+			f.Close()
+		}
+	}
+	if err != nil {
+		utils.Warn("写入影子决策日志失败", zap.String("account_id", s.accountID), zap.Error(err))
+	}
+}
+
+// saveLocked 保存模拟持仓（调用方持有锁）
+func (s *ShadowExecutor) saveLocked() {
+	data, err := json.MarshalIndent(s.positions, "", "  ")
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(s.statePath), 0755); err == nil {
+			err = os.WriteFile(s.statePath, data, 0644)
+		}
+	}
+	if err != nil {
+		utils.Warn("保存影子持仓失败", zap.String("account_id", s.accountID), zap.Error(err))
+	}
+}
+
+// shadowExit 按K线最高/最低价判断止损止盈（同时触及按止损处理）
+func shadowExit(b *Bracket, high, low float64) (float64, string, bool) {
+	if b.IsLong() {
+		if low <= b.StopLoss {
+			return b.StopLoss, CloseReasonStopLoss, true
+		}
+		if b.TakeProfit > 0 && high >= b.TakeProfit {
+			return b.TakeProfit, CloseReasonTakeProfit, true
+		}
+		return 0, "", false
+	}
+
+	if high >= b.StopLoss {
+		return b.StopLoss, CloseReasonStopLoss, true
+	}
+	if b.TakeProfit > 0 && low <= b.TakeProfit {
+		return b.TakeProfit, CloseReasonTakeProfit, true
+	}
+	return 0, "", false
+}
+
+// fundingDue 上次计算之后是否经过了资金费结算时间
+func fundingDue(last, now time.Time) bool {
+	next := last.UTC().Truncate(fundingInterval).Add(fundingInterval)
+	return !now.Before(next)
+}
+
+// parseHLC 解析K线最高价、最低价、收盘价
+func parseHLC(k binance.Kline) (float64, float64, float64, error) {
+	var values [3]float64
+	for i, raw := range []string{k.High, k.Low, k.Close} {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("解析K线价格失败: %w", err)
+		}
+		values[i] = v
+	}
+	return values[0], values[1], values[2], nil
+}
@@ -0,0 +1,128 @@
+/*
+Package executor 仓位计算（固定数量或按波动率计算）
+
+主要功能：
+- (e *Executor) SetSizing(cfg config.SizingConfig)  // 设置仓位计算配置
+- (e *Executor) Sizing() config.SizingConfig        // 获取仓位计算配置
+
+volatility模式下忽略决策给出的数量：
+数量 = 风险金额 / (ATR × atr_multiple)（即 indicators.CalculateLevels 的建议数量），名义价值 = 风险金额 / (ATR% × atr_multiple)。
+波动大的交易对仓位小、波动小的仓位大，按 ATR×倍数 的止损距离计算每笔风险大致相同。
+*/
+package executor
+
+import (
+	"fmt"
+	"strconv"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// SetSizing 设置仓位计算配置
+func (e *Executor) SetSizing(cfg config.SizingConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.sizing = cfg
+}
+
+// Sizing 获取仓位计算配置
+func (e *Executor) Sizing() config.SizingConfig {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.sizing
+}
+
+// sizeQuantity 计算开仓数量（标的资产数量）
+func (e *Executor) sizeQuantity(decision *Decision) (float64, error) {
+	cfg := e.Sizing()
+	if cfg.Mode != config.SizingModeVolatility {
+		if decision.Quantity <= 0 {
+			return 0, fmt.Errorf("开仓数量必须大于0")
+		}
+		return decision.Quantity, nil
+	}
+
+	// 多取几倍周期的K线，让ATR的平滑收敛
+	klines, err := e.client.GetKlines(decision.Symbol, cfg.Timeframe, cfg.ATRPeriod*3+1)
+	if err != nil {
+		return 0, fmt.Errorf("获取K线失败，无法按波动率计算仓位: %w", err)
+	}
+	atrPct := indicators.CalculateATRPercent(klines, cfg.ATRPeriod)
+	if atrPct <= 0 {
+		return 0, fmt.Errorf("ATR无效，无法按波动率计算仓位: %s", decision.Symbol)
+	}
+	price, _ := strconv.ParseFloat(klines[len(klines)-1].Close, 64)
+	if price <= 0 {
+		return 0, fmt.Errorf("价格无效，无法按波动率计算仓位: %s", decision.Symbol)
+	}
+
+	risk, err := e.riskAmount(cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	// 与AI数据中的建议价位使用同一个计算器
+	direction := indicators.DirectionLong
+	if decision.Action == ActionOpenShort {
+		direction = indicators.DirectionShort
+	}
+	levels := indicators.CalculateLevels(price, direction, price*atrPct/100, indicators.LevelParams{StopATR: cfg.ATRMultiple}, risk)
+	if levels == nil {
+		return 0, fmt.Errorf("止损距离无效，无法按波动率计算仓位: %s", decision.Symbol)
+	}
+
+	notional := levels.Quantity * price
+	capped := cfg.MaxNotionalUSDT > 0 && notional > cfg.MaxNotionalUSDT
+	if capped {
+		notional = cfg.MaxNotionalUSDT
+	}
+
+	utils.Info("按波动率计算仓位",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", decision.Symbol),
+		zap.Float64("atr_pct", atrPct),
+		zap.Float64("risk_usdt", risk),
+		zap.Float64("notional", notional),
+		zap.Bool("capped", capped),
+		zap.Float64("decision_quantity", decision.Quantity),
+	)
+
+	return notional / price, nil
+}
+
+// riskAmount 每笔交易的风险金额（USDT），设置了risk_pct时按账户余额计算
+func (e *Executor) riskAmount(cfg config.SizingConfig) (float64, error) {
+	if cfg.RiskPct <= 0 {
+		return cfg.RiskUSDT, nil
+	}
+
+	var equity float64
+	switch e.client.MarketType() {
+	case binance.MarketTypeSpot:
+		balance, err := e.client.GetSpotAssetBalance("USDT")
+		if err != nil {
+			return 0, fmt.Errorf("查询余额失败: %w", err)
+		}
+		equity = balance
+	case binance.MarketTypeUSDTM:
+		balance, err := e.client.GetBalance()
+		if err != nil {
+			return 0, fmt.Errorf("查询余额失败: %w", err)
+		}
+		equity, _ = strconv.ParseFloat(balance.Balance, 64)
+	default:
+		return 0, fmt.Errorf("币本位账号不支持按余额百分比计算风险")
+	}
+
+	if equity <= 0 {
+		return 0, fmt.Errorf("账户余额为0，无法计算仓位")
+	}
+	return equity * cfg.RiskPct / 100, nil
+}
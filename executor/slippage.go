@@ -0,0 +1,107 @@
+/*
+Package executor 下单前滑点预估
+
+主要功能：
+- (e *Executor) limitSlippage(...)  // 按盘口深度预估滑点，超过 max_slippage_bps 时缩减数量或放弃交易
+
+滑点按相对中间价计算（包含半个价差），盘口深度不足以成交全部数量时视为超限。
+设置了本地订单簿时使用推送维护的盘口，未同步时请求REST深度接口。
+TWAP拆单时按单笔数量估算。
+*/
+package executor
+
+import (
+	"fmt"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	slippageDepthLimit   = 100 // 预估滑点使用的盘口档位数
+	slippageSearchRounds = 30  // 二分查找最大可下单数量的迭代次数
+)
+
+// limitSlippage 按盘口深度预估滑点
+// 返回：允许的下单数量、备注（数量被缩减时说明原因）
+// slippage_action 为 skip 且超限时返回错误
+func (e *Executor) limitSlippage(symbol, side string, quantity float64, rules *binance.SymbolInfo, exec config.ExecutionConfig) (float64, string, error) {
+	if exec.MaxSlippageBps <= 0 {
+		return quantity, "", nil
+	}
+
+	book, err := e.getOrderBook(symbol, slippageDepthLimit)
+	if err != nil {
+		utils.Warn("获取订单簿失败，跳过滑点检查",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", symbol),
+			zap.Error(err),
+		)
+		return quantity, "", nil
+	}
+
+	est := book.EstimateFill(side, quantity)
+	if est.FilledQty >= quantity && est.SlippageBps <= exec.MaxSlippageBps {
+		utils.Debug("预估滑点",
+			zap.String("symbol", symbol),
+			zap.Float64("quantity", quantity),
+			zap.Float64("slippage_bps", est.SlippageBps),
+		)
+		return quantity, "", nil
+	}
+
+	reason := fmt.Sprintf("预估滑点%.1fbps超过上限%.1fbps", est.SlippageBps, exec.MaxSlippageBps)
+	if est.FilledQty < quantity {
+		reason = fmt.Sprintf("盘口深度不足（%d档内仅可成交%v）", slippageDepthLimit, est.FilledQty)
+	}
+
+	if exec.SlippageAction == config.SlippageActionSkip {
+		utils.Warn("预估滑点超限，放弃交易",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", symbol),
+			zap.Float64("quantity", quantity),
+			zap.String("reason", reason),
+		)
+		return 0, reason, fmt.Errorf("放弃交易: %s", reason)
+	}
+
+	allowed := maxQtyWithinSlippage(book, side, quantity, exec.MaxSlippageBps)
+	if isDust(allowed, rules) {
+		utils.Warn("预估滑点超限且缩减后数量过小，放弃交易",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", symbol),
+			zap.Float64("quantity", quantity),
+			zap.String("reason", reason),
+		)
+		return 0, reason, fmt.Errorf("放弃交易: %s，缩减后数量低于最小下单数量", reason)
+	}
+
+	note := fmt.Sprintf("%s，数量从%v缩减为%s", reason, quantity, rules.FormatQuantity(allowed))
+	utils.Warn("预估滑点超限，缩减下单数量",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", symbol),
+		zap.Float64("quantity", quantity),
+		zap.Float64("allowed", allowed),
+		zap.String("reason", reason),
+	)
+
+	return allowed, note, nil
+}
+
+// maxQtyWithinSlippage 二分查找滑点不超过上限的最大数量
+func maxQtyWithinSlippage(book *binance.OrderBook, side string, quantity, maxBps float64) float64 {
+	lo, hi := 0.0, quantity
+	for i := 0; i < slippageSearchRounds; i++ {
+		mid := (lo + hi) / 2
+		est := book.EstimateFill(side, mid)
+		if est.FilledQty >= mid && est.SlippageBps <= maxBps {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
@@ -0,0 +1,146 @@
+/*
+Package executor 现货账号的持仓与止盈处理
+
+主要功能：
+- (e *Executor) spotPosition(symbol string) (float64, float64, error)                                         // 现货持仓（标的资产余额，零头视为无持仓）
+- (e *Executor) spotExitQuantity(symbol string, quantity float64, rules *binance.SymbolInfo) (float64, error) // 现货卖出数量（不超过标的资产余额）
+- (e *Executor) syncSpotStop(bracket *Bracket, rules *binance.SymbolInfo, quantity float64) (float64, error)  // 现货按新数量重新挂出止损单
+- (e *Executor) restoreSpotStop(bracket *Bracket, rules *binance.SymbolInfo, quantity float64)                // 现货止损单撤销后卖出失败时重新挂出
+- (e *Executor) checkSpotTakeProfit(bracket *Bracket) (bool, error)                                           // 现货止盈检查（最优买价达到止盈价时市价卖出）
+
+现货只能做多，"持仓"即标的资产余额（可用 + 冻结）。与合约的差异：
+- 买入手续费以标的资产扣除，到账数量少于成交数量，止损和卖出数量按余额截断
+- 止损单冻结全部余额，无法同时挂出止盈单，也无法在撤销止损单前卖出或挂出新的止损单
+*/
+package executor
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// spotPosition 现货持仓（标的资产余额），开仓均价取括号订单的成交均价
+func (e *Executor) spotPosition(symbol string) (float64, float64, error) {
+	rules, err := e.getSymbolRules(symbol)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	amt, err := e.client.GetSpotAssetBalance(rules.BaseAsset)
+	if err != nil {
+		return 0, 0, err
+	}
+	if isDust(amt, rules) {
+		return 0, 0, nil
+	}
+
+	var entryPrice float64
+	if bracket := e.GetBracket(symbol); bracket != nil {
+		entryPrice = bracket.EntryPrice
+	}
+	return amt, entryPrice, nil
+}
+
+// checkSpotTakeProfit 现货止盈检查，触发时返回true
+func (e *Executor) checkSpotTakeProfit(bracket *Bracket) (bool, error) {
+	if bracket.TakeProfit <= 0 {
+		return false, nil
+	}
+
+	ticker, err := e.client.GetBookTicker(bracket.Symbol)
+	if err != nil {
+		return false, err
+	}
+	if ticker.BidPriceFloat() < bracket.TakeProfit {
+		return false, nil
+	}
+
+	utils.Info("现货达到止盈价，撤销止损单并市价卖出",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", bracket.Symbol),
+		zap.Float64("bid", ticker.BidPriceFloat()),
+		zap.Float64("take_profit", bracket.TakeProfit),
+	)
+
+	rules, err := e.getSymbolRules(bracket.Symbol)
+	if err != nil {
+		return false, err
+	}
+
+	// 先撤销止损单释放冻结的余额，再按余额卖出
+	e.cancelLeg(bracket, bracket.StopLossOrderID)
+	quantity, err := e.spotExitQuantity(bracket.Symbol, bracket.Quantity, rules)
+	if err != nil {
+		e.restoreSpotStop(bracket, rules, bracket.Quantity)
+		return false, err
+	}
+	qty := rules.FormatQuantity(quantity)
+	if _, err := e.submitExitOrder(&binance.OrderRequest{
+		Symbol:           bracket.Symbol,
+		Side:             bracket.exitSide(),
+		Type:             binance.OrderTypeMarket,
+		Quantity:         qty,
+		NewClientOrderID: ClientOrderID(LegTakeProfit, e.accountID, bracket.Symbol, bracket.DecisionID, qty),
+	}); err != nil {
+		e.restoreSpotStop(bracket, rules, quantity)
+		return false, fmt.Errorf("现货止盈卖出失败: %w", err)
+	}
+
+	e.closeBracket(bracket, CloseReasonTakeProfit)
+	return true, nil
+}
+
+// spotExitQuantity 现货卖出数量：不超过标的资产余额（可用 + 冻结），按stepSize向下取整
+// 买入手续费以标的资产扣除，按成交数量卖出会因余额不足被拒绝
+func (e *Executor) spotExitQuantity(symbol string, quantity float64, rules *binance.SymbolInfo) (float64, error) {
+	balance, err := e.client.GetSpotAssetBalance(rules.BaseAsset)
+	if err != nil {
+		return 0, fmt.Errorf("查询现货余额失败: %w", err)
+	}
+	qty, _ := strconv.ParseFloat(rules.FormatQuantity(math.Min(quantity, balance)), 64)
+	return qty, nil
+}
+
+// syncSpotStop 现货按新数量重新挂出止损单，返回实际挂单数量
+// 旧止损单冻结了余额，新单在旧单撤销前会因余额不足被拒绝：先撤销旧单再挂新单，挂出失败时恢复止损单
+func (e *Executor) syncSpotStop(bracket *Bracket, rules *binance.SymbolInfo, quantity float64) (float64, error) {
+	newQty, err := e.spotExitQuantity(bracket.Symbol, quantity, rules)
+	if err != nil {
+		return 0, err
+	}
+	oldQty := bracket.Quantity
+
+	e.cancelLeg(bracket, bracket.StopLossOrderID)
+	stopOrder, err := e.placeExitLeg(bracket, rules, binance.OrderTypeStopMarket, bracket.StopLoss, newQty)
+	if err != nil {
+		e.restoreSpotStop(bracket, rules, oldQty)
+		return 0, err
+	}
+	bracket.StopLossOrderID = stopOrder.OrderID
+	return newQty, nil
+}
+
+// restoreSpotStop 止损单已撤销但后续卖出或挂单失败时重新挂出止损单，避免持仓无保护
+// 使用新的挂单序号（原ID对应的订单已撤销），数量不超过当前余额
+func (e *Executor) restoreSpotStop(bracket *Bracket, rules *binance.SymbolInfo, quantity float64) {
+	if qty, err := e.spotExitQuantity(bracket.Symbol, quantity, rules); err == nil {
+		quantity = qty
+	}
+	stopOrder, err := e.placeExitLeg(bracket, rules, binance.OrderTypeStopMarket, bracket.StopLoss, quantity)
+	if err != nil {
+		utils.Error("现货止损单无法恢复，持仓无保护",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", bracket.Symbol),
+			zap.Float64("quantity", quantity),
+			zap.Error(err),
+		)
+		return
+	}
+	bracket.StopLossOrderID = stopOrder.OrderID
+}
@@ -0,0 +1,102 @@
+/*
+Package executor 决策过期检查
+
+主要功能：
+- (e *Executor) SetStaleness(cfg config.StalenessConfig, ttl time.Duration)  // 设置决策过期规则（ttl为按策略运行周期计算的有效期）
+- (d *Decision) SetExpiry(ttl time.Duration)                                 // 按决策时间附加过期时间（已有过期时间时不覆盖）
+- (d *Decision) Expired(now time.Time) bool                                  // 决策是否已过期
+
+AI分析耗时、网络重试都会推迟决策的执行时间。启用后，执行前检查开仓决策：
+1. 决策带有过期时间（expires_at）或可由决策时间 + 有效期推算，执行时已过期则拒绝
+2. 决策带有分析时的收盘价（analyzed_price），当前价格偏离超过 max_price_move_pct 则拒绝
+平仓决策不检查（减仓晚执行也比不执行好）。拒绝时返回 ErrStaleDecision。
+*/
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// ErrStaleDecision 决策已过期或价格已偏离分析时的收盘价
+var ErrStaleDecision = errors.New("决策已失效")
+
+// SetStaleness 设置决策过期规则
+func (e *Executor) SetStaleness(cfg config.StalenessConfig, ttl time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.staleness = cfg
+	e.decisionTTL = ttl
+}
+
+// SetExpiry 按决策时间附加过期时间（已有过期时间或没有决策时间时不处理）
+func (d *Decision) SetExpiry(ttl time.Duration) {
+	if d.ExpiresAt != 0 || d.Timestamp == 0 || ttl <= 0 {
+		return
+	}
+	d.ExpiresAt = d.Timestamp + ttl.Milliseconds()
+}
+
+// Expired 决策是否已过期（没有过期时间时视为未过期）
+func (d *Decision) Expired(now time.Time) bool {
+	return d.ExpiresAt != 0 && now.UnixMilli() > d.ExpiresAt
+}
+
+// checkStaleness 检查开仓决策是否过期或价格已偏离分析时的收盘价
+func (e *Executor) checkStaleness(decision *Decision) error {
+	e.mu.Lock()
+	cfg, ttl := e.staleness, e.decisionTTL
+	e.mu.Unlock()
+
+	if !cfg.Enabled {
+		return nil
+	}
+	decision.SetExpiry(ttl)
+
+	price := 0.0
+	if decision.AnalyzedPrice > 0 && cfg.MaxPriceMovePct > 0 {
+		var err error
+		if price, err = e.referencePrice(decision.Symbol); err != nil {
+			return fmt.Errorf("获取价格失败，无法检查决策是否失效: %w", err)
+		}
+	}
+
+	if err := staleReason(decision, cfg, price, time.Now()); err != nil {
+		utils.Warn("开仓决策已失效，拒绝执行",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", decision.Symbol),
+			zap.String("action", decision.Action),
+			zap.Int64("timestamp", decision.Timestamp),
+			zap.Int64("expires_at", decision.ExpiresAt),
+			zap.Float64("analyzed_price", decision.AnalyzedPrice),
+			zap.Float64("price", price),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// staleReason 按过期时间和价格偏离判断决策是否失效（price为0时不检查价格偏离）
+func staleReason(d *Decision, cfg config.StalenessConfig, price float64, now time.Time) error {
+	if d.Expired(now) {
+		late := now.Sub(time.UnixMilli(d.ExpiresAt)).Round(time.Second)
+		return fmt.Errorf("%w: 已超过有效期 %s", ErrStaleDecision, late)
+	}
+	if price > 0 && d.AnalyzedPrice > 0 {
+		movePct := math.Abs(price-d.AnalyzedPrice) / d.AnalyzedPrice * 100
+		if movePct > cfg.MaxPriceMovePct {
+			return fmt.Errorf("%w: 价格较分析时的收盘价 %v 偏离 %.2f%%（当前 %v，上限 %v%%）",
+				ErrStaleDecision, d.AnalyzedPrice, movePct, price, cfg.MaxPriceMovePct)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+/*
+Package executor 持仓逻辑跟踪与重复信号去重
+
+主要功能：
+- (e *Executor) GetThesis(symbol string) *Thesis  // 获取交易对当前的持仓逻辑
+- (e *Executor) GetTheses() []*Thesis            // 获取所有持仓逻辑
+
+AI每个周期都会重新给出判断，持仓期间连续给出同方向的开仓决策是对原交易逻辑的确认，不是新的入场信号。
+开仓决策开始入场时记录该交易对的持仓逻辑（方向、决策、时间），入场失败或括号订单结束时清除：
+1. 入场进行中或已有同方向括号订单时，同方向开仓决策视为重复信号，记录确认次数后忽略（不下单、不报错）
+2. 启用盈利加仓且已有同方向括号订单时，同方向开仓决策仍按加仓规则处理
+3. 反方向开仓决策不受影响（已有括号订单时拒绝，需先平仓）
+*/
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// ErrDuplicateSignal 同方向持仓逻辑已存在，开仓决策被视为重复信号
+var ErrDuplicateSignal = errors.New("重复信号")
+
+// Thesis 交易对当前的持仓逻辑（每个账号每个交易对最多一个）
+type Thesis struct {
+	Symbol        string    `json:"symbol"`         // 交易对
+	Side          string    `json:"side"`           // 方向（BUY做多 / SELL做空）
+	DecisionID    string    `json:"decision_id"`    // 建立持仓的决策哈希
+	Reason        string    `json:"reason"`         // 建立持仓的决策理由
+	OpenedAt      time.Time `json:"opened_at"`      // 开始入场时间
+	Confirmations int       `json:"confirmations"`  // 持仓期间收到的同方向重复信号次数
+	LastSignalAt  time.Time `json:"last_signal_at"` // 最近一次收到同方向信号的时间
+	Entering      bool      `json:"entering"`       // 是否仍在入场中（括号订单尚未建立）
+}
+
+// GetThesis 获取交易对当前的持仓逻辑（没有时返回nil）
+func (e *Executor) GetThesis(symbol string) *Thesis {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if t := e.thesisLocked(symbol); t != nil {
+		copied := *t
+		return &copied
+	}
+	return nil
+}
+
+// GetTheses 获取所有持仓逻辑
+func (e *Executor) GetTheses() []*Thesis {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for symbol := range e.brackets {
+		e.thesisLocked(symbol)
+	}
+	theses := make([]*Thesis, 0, len(e.theses))
+	for _, t := range e.theses {
+		copied := *t
+		theses = append(theses, &copied)
+	}
+	return theses
+}
+
+// thesisLocked 获取交易对的持仓逻辑（调用方持有锁）
+// 启动时恢复或接管的括号订单没有入场记录，按括号订单补建持仓逻辑
+func (e *Executor) thesisLocked(symbol string) *Thesis {
+	if t, ok := e.theses[symbol]; ok {
+		return t
+	}
+	bracket, ok := e.brackets[symbol]
+	if !ok {
+		return nil
+	}
+	t := &Thesis{
+		Symbol:     symbol,
+		Side:       bracket.Side,
+		DecisionID: bracket.DecisionID,
+		OpenedAt:   bracket.CreatedAt,
+	}
+	e.theses[symbol] = t
+	return t
+}
+
+// claimThesis 开仓前登记持仓逻辑
+// 同方向持仓逻辑已存在时记录一次确认并返回 ErrDuplicateSignal，反方向时返回错误
+func (e *Executor) claimThesis(decision *Decision, side string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t := e.thesisLocked(decision.Symbol)
+	if t == nil {
+		e.theses[decision.Symbol] = &Thesis{
+			Symbol:     decision.Symbol,
+			Side:       side,
+			DecisionID: decision.Hash(),
+			Reason:     decision.Reason,
+			OpenedAt:   time.Now(),
+			Entering:   true,
+		}
+		return nil
+	}
+
+	if t.Side != side {
+		if t.Entering {
+			return fmt.Errorf("交易对正在反方向入场: %s", decision.Symbol)
+		}
+		return fmt.Errorf("交易对已有生效中的括号订单: %s", decision.Symbol)
+	}
+
+	t.Confirmations++
+	t.LastSignalAt = time.Now()
+	utils.Info("同方向持仓逻辑已存在，忽略重复开仓信号",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", decision.Symbol),
+		zap.String("side", side),
+		zap.String("thesis_decision_id", t.DecisionID),
+		zap.Bool("entering", t.Entering),
+		zap.Int("confirmations", t.Confirmations),
+	)
+	return fmt.Errorf("%w: %s 已有%s持仓逻辑", ErrDuplicateSignal, decision.Symbol, sideLabel(side))
+}
+
+// confirmThesis 括号订单建立后标记入场完成
+func (e *Executor) confirmThesis(symbol string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if t, ok := e.theses[symbol]; ok {
+		t.Entering = false
+	}
+}
+
+// releaseThesis 入场失败或括号订单结束时清除持仓逻辑
+func (e *Executor) releaseThesis(symbol string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.theses, symbol)
+}
+
+// sideLabel 方向的中文说明
+func sideLabel(side string) string {
+	if side == binance.SideBuy {
+		return "做多"
+	}
+	return "做空"
+}
@@ -0,0 +1,135 @@
+/*
+Package executor TWAP拆单入场（大额订单按时间拆成多笔）
+
+主要功能：
+- (e *Executor) enterTWAP(...)  // 名义价值超过 twap.threshold_usdt 时拆单入场
+
+流程：
+1. 按盘口中间价估算名义价值，超过阈值才拆单
+2. 拆成 twap.slices 笔，每笔数量在平均值基础上随机浮动 ±size_jitter，避免被识别为固定节奏的大单
+3. 每笔按 entry_type（market / limit / maker_first）执行，笔与笔之间间隔 twap.interval_sec 秒
+4. 第一笔成交后立即挂出止损止盈单，之后每笔成交后按累计数量同步（见 PlaceBracket 的 onSlice 回调）
+5. 某一笔失败时停止拆单，保留已成交部分（已受止损保护）；止损单挂出或同步失败时平掉全部已成交数量
+*/
+package executor
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// needTWAP 订单名义价值是否超过TWAP阈值
+func (e *Executor) needTWAP(symbol string, quantity float64, rules *binance.SymbolInfo, exec config.ExecutionConfig) bool {
+	if exec.TWAP.ThresholdUSDT <= 0 || exec.TWAP.Slices <= 1 {
+		return false
+	}
+
+	ticker, err := e.client.GetBookTicker(symbol)
+	if err != nil {
+		utils.Warn("获取盘口失败，不拆单",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", symbol),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	mid := (ticker.BidPriceFloat() + ticker.AskPriceFloat()) / 2
+	return rules.Notional(quantity, mid) > exec.TWAP.ThresholdUSDT
+}
+
+// enterTWAP 拆单入场，每笔成交后以累计成交调用 onSlice（可为nil），回调返回错误时停止拆单
+func (e *Executor) enterTWAP(symbol, side string, quantity float64, rules *binance.SymbolInfo, decisionID string, exec config.ExecutionConfig, onSlice func(filled *entryResult) error) (*entryResult, error) {
+	slices := twapSlices(quantity, exec.TWAP.Slices, exec.TWAP.SizeJitter, rules)
+	interval := time.Duration(exec.TWAP.IntervalSec) * time.Second
+
+	utils.Info("大额订单拆单入场",
+		zap.String("account_id", e.accountID),
+		zap.String("symbol", symbol),
+		zap.Float64("quantity", quantity),
+		zap.Int("slices", len(slices)),
+		zap.Duration("interval", interval),
+	)
+
+	result := &entryResult{}
+	for i, qty := range slices {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+
+		// 每笔使用独立的决策ID后缀，保证客户端订单ID互不冲突且重试幂等
+		slice, err := e.enterOnce(symbol, side, qty, rules, fmt.Sprintf("%s|tw%d", decisionID, i), exec)
+		if err != nil {
+			if result.FilledQty > 0 {
+				utils.Warn("拆单执行失败，保留已成交部分",
+					zap.String("account_id", e.accountID),
+					zap.String("symbol", symbol),
+					zap.Int("slice", i),
+					zap.Float64("filled", result.FilledQty),
+					zap.Error(err),
+				)
+				break
+			}
+			return nil, err
+		}
+
+		result.merge(slice)
+		utils.Debug("拆单成交",
+			zap.String("account_id", e.accountID),
+			zap.String("symbol", symbol),
+			zap.Int("slice", i),
+			zap.Float64("slice_filled", slice.FilledQty),
+			zap.Float64("total_filled", result.FilledQty),
+		)
+
+		if onSlice != nil && slice.FilledQty > 0 {
+			if err := onSlice(result); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// twapSlices 将数量拆成n笔，每笔在平均值基础上随机浮动 ±jitter
+// 每笔都不小于最小下单数量，数量不足时减少笔数；最后一笔补齐剩余数量
+func twapSlices(quantity float64, n int, jitter float64, rules *binance.SymbolInfo) []float64 {
+	if rules.MinQty > 0 {
+		if maxSlices := int(quantity / rules.MinQty); maxSlices < n {
+			n = maxSlices
+		}
+	}
+	if n <= 1 {
+		return []float64{quantity}
+	}
+
+	weights := make([]float64, n)
+	var sum float64
+	for i := range weights {
+		weights[i] = 1 + jitter*(rand.Float64()*2-1)
+		sum += weights[i]
+	}
+
+	slices := make([]float64, 0, n)
+	var allocated float64
+	for i := 0; i < n-1; i++ {
+		qty := quantity * weights[i] / sum
+		if qty < rules.MinQty {
+			qty = rules.MinQty
+		}
+		if quantity-allocated-qty < rules.MinQty {
+			break
+		}
+		slices = append(slices, qty)
+		allocated += qty
+	}
+	return append(slices, quantity-allocated)
+}
@@ -0,0 +1,118 @@
+/*
+Package executor 交易执行器数据结构定义
+
+主要功能：
+- (t *DecisionTags) Cohort() string  // 对照组标识（模板@版本 / 模型 / 配置哈希）
+- (b *Bracket) IsLong() bool         // 是否为多头括号订单
+
+数据结构：
+- Decision       // 交易决策（由AI或策略生成，交给执行器执行）
+- DecisionTags   // 决策标签（提示词模板版本、模型、配置哈希）
+- Bracket        // 括号订单（入场 + 止损 + 止盈）
+*/
+package executor
+
+import (
+	"time"
+
+	"crypto-ai-trader/binance"
+)
+
+// 决策动作
+const (
+	ActionOpenLong  = "open_long"  // 开多
+	ActionOpenShort = "open_short" // 开空
+	ActionClose     = "close"      // 平仓
+	ActionHold      = "hold"       // 观望
+)
+
+// 括号订单状态
+const (
+	BracketStatusActive = "active" // 持仓中，止损止盈单生效
+	BracketStatusClosed = "closed" // 已结束
+)
+
+// 括号订单结束原因
+const (
+	CloseReasonStopLoss       = "stop_loss"       // 止损触发
+	CloseReasonTakeProfit     = "take_profit"     // 止盈触发
+	CloseReasonPositionClosed = "position_closed" // 持仓已被其他方式平掉
+	CloseReasonReduced        = "reduced"         // 主动减仓（部分平仓）
+)
+
+// Decision 交易决策
+type Decision struct {
+	AccountID  string  `json:"account_id"`  // 账号ID
+	Symbol     string  `json:"symbol"`      // 交易对
+	Action     string  `json:"action"`      // 动作：open_long / open_short / close / hold
+	Quantity   float64 `json:"quantity"`    // 开仓数量（标的资产数量，按波动率计算仓位时忽略）
+	StopLoss   float64 `json:"stop_loss"`   // 止损价
+	TakeProfit float64 `json:"take_profit"` // 止盈价
+	Leverage   int     `json:"leverage"`    // 建议杠杆（可选，按交易对分级上限截断）
+	Confidence float64 `json:"confidence"`  // 置信度（0-1）
+	Reason     string  `json:"reason"`      // 决策理由
+	Timestamp  int64   `json:"timestamp"`   // 决策时间
+
+	ExpiresAt     int64   `json:"expires_at,omitempty"`     // 过期时间（毫秒，为0时按决策时间 + 策略有效期计算）
+	AnalyzedPrice float64 `json:"analyzed_price,omitempty"` // 分析时的收盘价（用于检查执行时的价格偏离，0表示不检查）
+	SizeFactor    float64 `json:"size_factor,omitempty"`    // 开仓数量系数（风控缩减仓位时设置，0表示不缩减）
+
+	Tags *DecisionTags `json:"tags,omitempty"` // 生成决策的提示词模板、模型和配置（手动提交的决策为nil）
+}
+
+// DecisionTags 决策标签（按标签分组统计绩效，修改提示词、更换模型或调整配置后自动形成新的对照组）
+type DecisionTags struct {
+	Template        string `json:"template"`         // 提示词模板（两阶段为 分析模板+决策模板）
+	TemplateVersion string `json:"template_version"` // 模板版本（模板内容的哈希）
+	Model           string `json:"model"`            // 实际使用的模型
+	ConfigHash      string `json:"config_hash"`      // 影响决策的配置（策略参数、AI参数等）的哈希
+}
+
+// Cohort 对照组标识（模板@版本 / 模型 / 配置哈希）
+func (t *DecisionTags) Cohort() string {
+	if t == nil {
+		return ""
+	}
+	return t.Template + "@" + t.TemplateVersion + "/" + t.Model + "/" + t.ConfigHash
+}
+
+// Bracket 括号订单（入场成交后挂出的止损止盈对）
+type Bracket struct {
+	AccountID         string    `json:"account_id"`           // 账号ID
+	Symbol            string    `json:"symbol"`               // 交易对
+	DecisionID        string    `json:"decision_id"`          // 决策哈希（用于生成客户端订单ID）
+	Side              string    `json:"side"`                 // 入场方向（BUY开多 / SELL开空）
+	Quantity          float64   `json:"quantity"`             // 实际成交数量
+	EntryOrderID      int64     `json:"entry_order_id"`       // 入场订单ID
+	EntryPrice        float64   `json:"entry_price"`          // 成交均价
+	StopLoss          float64   `json:"stop_loss"`            // 止损价
+	TakeProfit        float64   `json:"take_profit"`          // 止盈价
+	StopLossOrderID   int64     `json:"stop_loss_order_id"`   // 止损单ID
+	TakeProfitOrderID int64     `json:"take_profit_order_id"` // 止盈单ID（0表示未挂出）
+	Status            string    `json:"status"`               // 状态
+	CloseReason       string    `json:"close_reason"`         // 结束原因
+	ExecutionNote     string    `json:"execution_note"`       // 执行备注（如因滑点缩减数量）
+	InitialQuantity   float64   `json:"initial_quantity"`     // 首笔成交数量（加仓数量按此递减）
+	Adds              int       `json:"adds"`                 // 已加仓次数
+	LastEntryPrice    float64   `json:"last_entry_price"`     // 最近一笔入场（含加仓）的成交均价
+	Reentry           bool      `json:"reentry"`              // 是否为止损后的重新入场
+	ExitSeq           int       `json:"exit_seq"`             // 止损止盈单的挂单序号（每次挂出加1，写入客户端订单ID）
+	EntryStartedAt    time.Time `json:"entry_started_at"`     // 开始入场时间（限价/拆单入场可能持续较长时间）
+	CreatedAt         time.Time `json:"created_at"`           // 创建时间
+	ClosedAt          time.Time `json:"closed_at"`            // 结束时间
+
+	adding bool // 正在加仓（监控暂不按持仓数量同步止损止盈单）
+}
+
+// IsLong 是否为多头括号订单
+func (b *Bracket) IsLong() bool {
+	return b.Side == binance.SideBuy
+}
+
+// exitSide 平仓方向（与入场方向相反）
+func (b *Bracket) exitSide() string {
+	if b.IsLong() {
+		return binance.SideSell
+	}
+	return binance.SideBuy
+}
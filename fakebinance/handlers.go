@@ -0,0 +1,511 @@
+/*
+Package fakebinance 模拟接口的请求处理（行情、账户、持仓设置）
+
+主要功能：
+- (s *Server) route(method, path string) (handlerFunc, bool)  // 按方法和路径查找处理函数（返回是否需要签名）
+
+接口路径与 binance/endpoints.go 一致；未实现的请求返回404并记入 Unhandled，测试据此发现新增的接口调用。
+*/
+package fakebinance
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/binance"
+)
+
+// handlerFunc 接口处理函数（调用时已持有 s.mu）
+type handlerFunc func(w http.ResponseWriter, q url.Values)
+
+// route 按方法和路径查找处理函数，返回是否需要签名
+func (s *Server) route(method, path string) (handlerFunc, bool) {
+	if s.spot {
+		return s.spotRoute(method, path)
+	}
+	type key struct{ method, path string }
+	public := map[key]handlerFunc{
+		{"GET", binance.EndpointPing}:         func(w http.ResponseWriter, q url.Values) { writeJSON(w, struct{}{}) },
+		{"GET", binance.EndpointServerTime}:   s.handleTime,
+		{"GET", binance.EndpointExchangeInfo}: s.handleExchangeInfo,
+		{"GET", binance.EndpointKlines}:       s.handleKlines,
+		{"GET", binance.EndpointOpenInterest}: s.handleOpenInterest,
+		{"GET", binance.EndpointFundingRate}:  s.handleFundingRate,
+		{"GET", binance.EndpointPremiumIndex}: s.handlePremiumIndex,
+		{"GET", binance.EndpointBookTicker}:   s.handleBookTicker,
+		{"GET", binance.EndpointTicker24h}:    s.handleTicker24h,
+		{"GET", binance.EndpointDepth}:        s.handleDepth,
+	}
+	signed := map[key]handlerFunc{
+		{"GET", binance.EndpointAccount}:          s.handleAccount,
+		{"GET", binance.EndpointBalance}:          s.handleBalance,
+		{"GET", binance.EndpointPositionRisk}:     s.handlePositionRisk,
+		{"GET", binance.EndpointCommission}:       s.handleCommission,
+		{"GET", binance.EndpointIncome}:           func(w http.ResponseWriter, q url.Values) { writeJSON(w, []binance.Income{}) },
+		{"GET", binance.EndpointPositionMode}:     s.handleGetPositionMode,
+		{"POST", binance.EndpointPositionMode}:    s.handleSetPositionMode,
+		{"POST", binance.EndpointMarginType}:      s.handleMarginType,
+		{"POST", binance.EndpointLeverage}:        s.handleLeverage,
+		{"POST", binance.EndpointOrder}:           s.handlePlaceOrder,
+		{"GET", binance.EndpointOrder}:            s.handleGetOrder,
+		{"DELETE", binance.EndpointOrder}:         s.handleCancelOrder,
+		{"GET", binance.EndpointOpenOrders}:       s.handleOpenOrders,
+		{"DELETE", binance.EndpointAllOpenOrders}: s.handleCancelAll,
+		{"GET", binance.EndpointUserTrades}:       s.handleUserTrades,
+	}
+	if h, ok := public[key{method, path}]; ok {
+		return h, false
+	}
+	if h, ok := signed[key{method, path}]; ok {
+		return h, true
+	}
+	return nil, false
+}
+
+// symbolOrError 查询参数中的交易对，不存在时输出 -1121 错误
+func (s *Server) symbolOrError(w http.ResponseWriter, q url.Values) *symbolState {
+	state := s.symbols[q.Get("symbol")]
+	if state == nil {
+		writeError(w, http.StatusBadRequest, -1121, "Invalid symbol.")
+	}
+	return state
+}
+
+// sortedSymbols 按名称排序的交易对
+func (s *Server) sortedSymbols() []*symbolState {
+	list := make([]*symbolState, 0, len(s.symbols))
+	for _, state := range s.symbols {
+		list = append(list, state)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].cfg.Symbol < list[j].cfg.Symbol })
+	return list
+}
+
+// handleTime 服务器时间
+func (s *Server) handleTime(w http.ResponseWriter, q url.Values) {
+	writeJSON(w, map[string]int64{"serverTime": time.Now().UnixMilli()})
+}
+
+// handleExchangeInfo 交易规则
+func (s *Server) handleExchangeInfo(w http.ResponseWriter, q url.Values) {
+	info := binance.ExchangeInfo{ServerTime: time.Now().UnixMilli(), Symbols: []binance.SymbolInfo{}}
+	for _, state := range s.sortedSymbols() {
+		cfg := state.cfg
+		info.Symbols = append(info.Symbols, binance.SymbolInfo{
+			Symbol:            cfg.Symbol,
+			Status:            "TRADING",
+			ContractType:      "PERPETUAL",
+			BaseAsset:         cfg.Symbol[:len(cfg.Symbol)-4],
+			QuoteAsset:        "USDT",
+			MarginAsset:       "USDT",
+			PricePrecision:    decimals(cfg.TickSize),
+			QuantityPrecision: decimals(cfg.StepSize),
+			Filters: []binance.SymbolFilter{
+				{FilterType: "PRICE_FILTER", TickSize: formatFloat(cfg.TickSize)},
+				{FilterType: "LOT_SIZE", StepSize: formatFloat(cfg.StepSize), MinQty: formatFloat(cfg.MinQty)},
+				{FilterType: "MIN_NOTIONAL", Notional: formatFloat(cfg.MinNotional)},
+			},
+		})
+	}
+	writeJSON(w, info)
+}
+
+// handleKlines K线（按 limit 返回最后的若干根，格式为数组的数组）
+func (s *Server) handleKlines(w http.ResponseWriter, q url.Values) {
+	state := s.symbolOrError(w, q)
+	if state == nil {
+		return
+	}
+	klines, ok := state.klines[q.Get("interval")]
+	if !ok {
+		writeError(w, http.StatusBadRequest, -1120, "Invalid interval.")
+		return
+	}
+	if limit, _ := strconv.Atoi(q.Get("limit")); limit > 0 && limit < len(klines) {
+		klines = klines[len(klines)-limit:]
+	}
+	rows := make([][]interface{}, 0, len(klines))
+	for _, k := range klines {
+		rows = append(rows, []interface{}{
+			k.OpenTime, k.Open, k.High, k.Low, k.Close, k.Volume,
+			k.CloseTime, k.QuoteAssetVolume, k.NumberOfTrades, k.TakerBuyBaseAssetVolume, k.TakerBuyQuoteAssetVolume, "0",
+		})
+	}
+	writeJSON(w, rows)
+}
+
+// handleOpenInterest 持仓量
+func (s *Server) handleOpenInterest(w http.ResponseWriter, q url.Values) {
+	state := s.symbolOrError(w, q)
+	if state == nil {
+		return
+	}
+	writeJSON(w, binance.OpenInterest{Symbol: state.cfg.Symbol, OpenInterest: formatFloat(state.cfg.OpenInterest), Time: time.Now().UnixMilli()})
+}
+
+// handleFundingRate 资金费率历史（每8小时一次，费率均为交易对配置的值；按 startTime/endTime 或 limit 返回）
+func (s *Server) handleFundingRate(w http.ResponseWriter, q url.Values) {
+	state := s.symbolOrError(w, q)
+	if state == nil {
+		return
+	}
+	now := time.Now()
+	end := now
+	if ms, err := strconv.ParseInt(q.Get("endTime"), 10, 64); err == nil {
+		end = time.UnixMilli(ms)
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 100
+	}
+	start := end.Add(-time.Duration(limit) * fundingInterval)
+	if ms, err := strconv.ParseInt(q.Get("startTime"), 10, 64); err == nil {
+		start = time.UnixMilli(ms)
+	}
+
+	rates := []binance.FundingRate{}
+	for t := start.Truncate(fundingInterval); !t.After(end) && !t.After(now); t = t.Add(fundingInterval) {
+		if t.Before(start) {
+			continue
+		}
+		rates = append(rates, binance.FundingRate{
+			Symbol:      state.cfg.Symbol,
+			FundingRate: formatFloat(state.cfg.FundingRate),
+			FundingTime: t.UnixMilli(),
+			Time:        t.UnixMilli(),
+		})
+	}
+	if len(rates) > limit {
+		rates = rates[len(rates)-limit:]
+	}
+	writeJSON(w, rates)
+}
+
+// handlePremiumIndex 标记价格和资金费率（没有 symbol 时返回全部交易对）
+func (s *Server) handlePremiumIndex(w http.ResponseWriter, q url.Values) {
+	index := func(state *symbolState) binance.PremiumIndex {
+		now := time.Now()
+		indexPrice := state.cfg.IndexPrice
+		if indexPrice <= 0 {
+			indexPrice = state.cfg.Price
+		}
+		return binance.PremiumIndex{
+			Symbol:          state.cfg.Symbol,
+			MarkPrice:       formatFloat(state.cfg.Price),
+			IndexPrice:      formatFloat(indexPrice),
+			LastFundingRate: formatFloat(state.cfg.FundingRate),
+			NextFundingTime: now.Truncate(fundingInterval).Add(fundingInterval).UnixMilli(),
+			Time:            now.UnixMilli(),
+		}
+	}
+	if q.Get("symbol") == "" {
+		all := []binance.PremiumIndex{}
+		for _, state := range s.sortedSymbols() {
+			all = append(all, index(state))
+		}
+		writeJSON(w, all)
+		return
+	}
+	if state := s.symbolOrError(w, q); state != nil {
+		writeJSON(w, index(state))
+	}
+}
+
+// handleBookTicker 最优挂单（买一、卖一为最新价 ∓ 一个价格步长）
+func (s *Server) handleBookTicker(w http.ResponseWriter, q url.Values) {
+	state := s.symbolOrError(w, q)
+	if state == nil {
+		return
+	}
+	bid, ask := state.quotes()
+	writeJSON(w, binance.BookTicker{
+		Symbol:   state.cfg.Symbol,
+		BidPrice: formatFloat(bid),
+		BidQty:   "100",
+		AskPrice: formatFloat(ask),
+		AskQty:   "100",
+		Time:     time.Now().UnixMilli(),
+	})
+}
+
+// handleTicker24h 全部交易对的24小时行情（按1h K线统计，没有时只有最新价）
+func (s *Server) handleTicker24h(w http.ResponseWriter, q url.Values) {
+	tickers := []binance.Ticker24h{}
+	for _, state := range s.sortedSymbols() {
+		price := state.cfg.Price
+		ticker := binance.Ticker24h{
+			Symbol:      state.cfg.Symbol,
+			LastPrice:   formatFloat(price),
+			HighPrice:   formatFloat(price),
+			LowPrice:    formatFloat(price),
+			Volume:      "0",
+			QuoteVolume: "0",
+			CloseTime:   time.Now().UnixMilli(),
+		}
+		if klines := state.klines["1h"]; len(klines) > 0 {
+			if len(klines) > 24 {
+				klines = klines[len(klines)-24:]
+			}
+			open, _ := strconv.ParseFloat(klines[0].Open, 64)
+			high, low, volume, quote := price, price, 0.0, 0.0
+			for _, k := range klines {
+				h, _ := strconv.ParseFloat(k.High, 64)
+				l, _ := strconv.ParseFloat(k.Low, 64)
+				v, _ := strconv.ParseFloat(k.Volume, 64)
+				qv, _ := strconv.ParseFloat(k.QuoteAssetVolume, 64)
+				high, low = max(high, h), min(low, l)
+				volume, quote = volume+v, quote+qv
+			}
+			ticker.HighPrice, ticker.LowPrice = formatFloat(high), formatFloat(low)
+			ticker.Volume, ticker.QuoteVolume = formatFloat(volume), formatFloat(quote)
+			if open > 0 {
+				ticker.PriceChangePercent = strconv.FormatFloat((price-open)/open*100, 'f', 3, 64)
+			}
+		}
+		tickers = append(tickers, ticker)
+	}
+	writeJSON(w, tickers)
+}
+
+// handleDepth 订单簿（以最优挂单为起点，每档间隔一个价格步长、数量100）
+func (s *Server) handleDepth(w http.ResponseWriter, q url.Values) {
+	state := s.symbolOrError(w, q)
+	if state == nil {
+		return
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 20
+	}
+	bid, ask := state.quotes()
+	book := binance.OrderBook{LastUpdateID: time.Now().UnixMilli()}
+	for i := 0; i < limit; i++ {
+		step := float64(i) * state.cfg.TickSize
+		book.Bids = append(book.Bids, [2]string{formatFloat(round(bid-step, state.cfg.TickSize)), "100"})
+		book.Asks = append(book.Asks, [2]string{formatFloat(round(ask+step, state.cfg.TickSize)), "100"})
+	}
+	writeJSON(w, book)
+}
+
+// handleAccount 账户信息（USDT资产和全部交易对的持仓）
+func (s *Server) handleAccount(w http.ResponseWriter, q url.Values) {
+	unrealized := s.unrealizedPnL()
+	balance := formatFloat(s.balance)
+	margin := formatFloat(s.balance + unrealized)
+	available := formatFloat(s.available())
+	positions := []binance.Position{}
+	for _, state := range s.sortedSymbols() {
+		risk := state.risk()
+		positions = append(positions, binance.Position{
+			Symbol:           risk.Symbol,
+			PositionAmt:      risk.PositionAmt,
+			EntryPrice:       risk.EntryPrice,
+			MarkPrice:        risk.MarkPrice,
+			UnRealizedProfit: risk.UnRealizedProfit,
+			Leverage:         risk.Leverage,
+			MarginType:       risk.MarginType,
+			PositionSide:     risk.PositionSide,
+			Notional:         risk.Notional,
+			UpdateTime:       risk.UpdateTime,
+		})
+	}
+	writeJSON(w, map[string]interface{}{
+		"totalWalletBalance":    balance,
+		"totalUnrealizedProfit": formatFloat(unrealized),
+		"totalMarginBalance":    margin,
+		"availableBalance":      available,
+		"assets": []binance.Asset{{
+			Asset:              "USDT",
+			WalletBalance:      balance,
+			UnrealizedProfit:   formatFloat(unrealized),
+			MarginBalance:      margin,
+			CrossWalletBalance: balance,
+			AvailableBalance:   available,
+			MaxWithdrawAmount:  available,
+		}},
+		"positions": positions,
+	})
+}
+
+// handleBalance 账户余额（只有USDT）
+func (s *Server) handleBalance(w http.ResponseWriter, q url.Values) {
+	writeJSON(w, []binance.Balance{{
+		Asset:            "USDT",
+		Balance:          formatFloat(s.balance),
+		AvailableBalance: formatFloat(s.available()),
+		UnrealizedProfit: formatFloat(s.unrealizedPnL()),
+	}})
+}
+
+// handlePositionRisk 持仓风险（没有 symbol 时返回全部交易对）
+func (s *Server) handlePositionRisk(w http.ResponseWriter, q url.Values) {
+	risks := []binance.PositionRisk{}
+	if q.Get("symbol") != "" {
+		state := s.symbolOrError(w, q)
+		if state == nil {
+			return
+		}
+		risks = append(risks, state.risk())
+	} else {
+		for _, state := range s.sortedSymbols() {
+			risks = append(risks, state.risk())
+		}
+	}
+	writeJSON(w, risks)
+}
+
+// handleCommission 手续费率
+func (s *Server) handleCommission(w http.ResponseWriter, q url.Values) {
+	state := s.symbolOrError(w, q)
+	if state == nil {
+		return
+	}
+	writeJSON(w, binance.CommissionRate{
+		Symbol:              state.cfg.Symbol,
+		MakerCommissionRate: formatFloat(defaultMakerRate),
+		TakerCommissionRate: formatFloat(defaultTakerRate),
+	})
+}
+
+// handleGetPositionMode 查询持仓模式
+func (s *Server) handleGetPositionMode(w http.ResponseWriter, q url.Values) {
+	writeJSON(w, map[string]bool{"dualSidePosition": s.dualSide})
+}
+
+// handleSetPositionMode 更改持仓模式（与当前相同时返回 -4059，有持仓时返回 -4068）
+func (s *Server) handleSetPositionMode(w http.ResponseWriter, q url.Values) {
+	dual := q.Get("dualSidePosition") == "true"
+	if dual == s.dualSide {
+		writeError(w, http.StatusBadRequest, -4059, "No need to change position side.")
+		return
+	}
+	for _, state := range s.symbols {
+		if state.position != 0 {
+			writeError(w, http.StatusBadRequest, -4068, "Position side cannot be changed if there exists position.")
+			return
+		}
+	}
+	s.dualSide = dual
+	writeJSON(w, map[string]interface{}{"code": 200, "msg": "success"})
+}
+
+// handleMarginType 更改保证金模式（与当前相同时返回 -4046）
+func (s *Server) handleMarginType(w http.ResponseWriter, q url.Values) {
+	state := s.symbolOrError(w, q)
+	if state == nil {
+		return
+	}
+	marginType := q.Get("marginType")
+	if marginType == state.marginType {
+		writeError(w, http.StatusBadRequest, -4046, "No need to change margin type.")
+		return
+	}
+	if state.position != 0 {
+		writeError(w, http.StatusBadRequest, -4048, "Margin type cannot be changed if there exists position.")
+		return
+	}
+	state.marginType = marginType
+	writeJSON(w, map[string]interface{}{"code": 200, "msg": "success"})
+}
+
+// handleLeverage 调整杠杆倍数（1-125）
+func (s *Server) handleLeverage(w http.ResponseWriter, q url.Values) {
+	state := s.symbolOrError(w, q)
+	if state == nil {
+		return
+	}
+	leverage, err := strconv.Atoi(q.Get("leverage"))
+	if err != nil || leverage < 1 || leverage > 125 {
+		writeError(w, http.StatusBadRequest, -4028, "Leverage is not valid.")
+		return
+	}
+	state.leverage = leverage
+	writeJSON(w, map[string]interface{}{"symbol": state.cfg.Symbol, "leverage": leverage, "maxNotionalValue": "1000000"})
+}
+
+// handleUserTrades 成交记录（按 startTime/endTime 过滤）
+func (s *Server) handleUserTrades(w http.ResponseWriter, q url.Values) {
+	state := s.symbolOrError(w, q)
+	if state == nil {
+		return
+	}
+	start, _ := strconv.ParseInt(q.Get("startTime"), 10, 64)
+	end, err := strconv.ParseInt(q.Get("endTime"), 10, 64)
+	if err != nil {
+		end = time.Now().UnixMilli()
+	}
+	trades := []binance.UserTrade{}
+	for _, t := range s.trades {
+		if t.Symbol == state.cfg.Symbol && t.Time >= start && t.Time <= end {
+			trades = append(trades, t)
+		}
+	}
+	writeJSON(w, trades)
+}
+
+// quotes 买一、卖一价格（最新价 ∓ 一个价格步长）
+func (st *symbolState) quotes() (bid, ask float64) {
+	return round(st.cfg.Price-st.cfg.TickSize, st.cfg.TickSize), round(st.cfg.Price+st.cfg.TickSize, st.cfg.TickSize)
+}
+
+// risk 交易对的持仓风险
+func (st *symbolState) risk() binance.PositionRisk {
+	marginType := "cross"
+	if st.marginType == binance.MarginTypeIsolated {
+		marginType = "isolated"
+	}
+	return binance.PositionRisk{
+		Symbol:           st.cfg.Symbol,
+		PositionAmt:      formatFloat(st.position),
+		EntryPrice:       formatFloat(st.entry),
+		MarkPrice:        formatFloat(st.cfg.Price),
+		UnRealizedProfit: formatFloat(st.unrealized()),
+		LiquidationPrice: "0",
+		Leverage:         strconv.Itoa(st.leverage),
+		MaxNotionalValue: "1000000",
+		MarginType:       marginType,
+		IsolatedMargin:   "0",
+		IsAutoAddMargin:  "false",
+		PositionSide:     "BOTH",
+		Notional:         formatFloat(st.position * st.cfg.Price),
+		IsolatedWallet:   "0",
+		UpdateTime:       time.Now().UnixMilli(),
+	}
+}
+
+// unrealized 持仓的未实现盈亏
+func (st *symbolState) unrealized() float64 {
+	return st.position * (st.cfg.Price - st.entry)
+}
+
+// unrealizedPnL 全部持仓的未实现盈亏
+func (s *Server) unrealizedPnL() float64 {
+	var total float64
+	for _, state := range s.symbols {
+		total += state.unrealized()
+	}
+	return total
+}
+
+// available 可用余额（钱包余额 + 未实现盈亏 - 持仓起始保证金）
+func (s *Server) available() float64 {
+	available := s.balance + s.unrealizedPnL()
+	for _, state := range s.symbols {
+		if state.position != 0 {
+			available -= abs(state.position) * state.cfg.Price / float64(state.leverage)
+		}
+	}
+	return max(available, 0)
+}
+
+// decimals 步长的小数位数（如 0.001 为3）
+func decimals(step float64) int {
+	if i := strings.Index(formatFloat(step), "."); i >= 0 {
+		return len(formatFloat(step)) - i - 1
+	}
+	return 0
+}
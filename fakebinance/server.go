@@ -0,0 +1,448 @@
+/*
+Package fakebinance 模拟币安U本位合约接口（基于 httptest，用于不需要真实API密钥的全流程测试）
+
+主要功能：
+- New(apiKey, apiSecret string) *Server                                          // 启动模拟服务器（binance.NewClient 的 baseURL 使用 s.URL）
+- (s *Server) Close()                                                            // 关闭服务器
+- (s *Server) AddSymbol(sym Symbol)                                              // 添加交易对（价格、交易规则、持仓量、资金费率）
+- (s *Server) SetKlines(symbol, interval string, klines []binance.Kline)         // 设置K线（最后一根的收盘价为最新价）
+- (s *Server) SetPrice(symbol string, price float64)                             // 设置最新价（越过触发价的止损止盈单按最新价成交）
+- (s *Server) SetIndexPrice(symbol string, price float64)                        // 设置指数价格（模拟标记价格偏离指数价格，0表示与最新价相同）
+- (s *Server) SetBalance(usdt float64)                                           // 设置USDT钱包余额
+- (s *Server) FailNext(method, path string, status, code int, msg string)        // 下一次该请求返回错误（模拟交易所拒绝或服务端故障）
+- (s *Server) TimeoutNext(method, path string)                                   // 下一次该请求照常处理，但返回 -1007 后端超时（结果未知）
+- (s *Server) RejectNextOrder(orderType string, code int, msg string)            // 下一次该类型的下单请求被拒绝（如止损单）
+- (s *Server) RejectOrderAfter(orderType string, skip, code int, msg string)     // 该类型的下单请求放行skip次后，下一次被拒绝（如拆单的第2笔）
+- (s *Server) PartialFill(orderID int64, qty float64)                            // 挂单部分成交（模拟大额限价单分多次成交）
+- (s *Server) Orders(symbol string) []binance.Order                              // 交易对的全部订单（按下单顺序）
+- (s *Server) Position(symbol string) (amt, entryPrice float64)                  // 交易对的持仓数量（空仓为负）和开仓均价
+- (s *Server) Balance() float64                                                  // USDT钱包余额（含已实现盈亏和手续费）
+- (s *Server) Trades(symbol string) []binance.UserTrade                          // 交易对的成交记录
+- (s *Server) Requests(method, path string) int                                  // 该请求的次数
+- (s *Server) Unhandled() []string                                               // 未实现的请求（方法 路径）
+- MakeKlines(base float64, count int, interval time.Duration, end time.Time) []binance.Kline  // 生成震荡上涨的K线（最后一根在end所在的周期）
+
+只模拟单向持仓模式：市价单和可立即成交的限价单按最新价立即成交（Taker），其余限价单挂单等待价格到达（Maker）；
+STOP_MARKET、TAKE_PROFIT_MARKET 挂单后在 SetPrice 越过触发价时成交，下单时已越过触发价返回 -2021；
+reduceOnly 订单不会增加或反向开仓。成交时按 taker/maker 费率扣手续费，平仓部分的盈亏计入钱包余额并记入成交记录。
+签名请求校验 X-MBX-APIKEY 和 HMAC-SHA256 签名，错误响应与币安一致（{"code":...,"msg":...}），
+客户端据此得到与真实接口相同的 *binance.APIError。现货接口（api/v3）见 spot.go。
+*/
+package fakebinance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/binance"
+)
+
+// 默认手续费率
+const (
+	defaultMakerRate = 0.0002
+	defaultTakerRate = 0.0004
+	defaultLeverage  = 20
+	firstOrderID     = 1000001
+	fundingInterval  = 8 * time.Hour
+)
+
+// Symbol 交易对配置
+type Symbol struct {
+	Symbol       string  // 交易对（如 BTCUSDT）
+	Price        float64 // 最新价（设置K线后为最后一根的收盘价）
+	TickSize     float64 // 价格步长
+	StepSize     float64 // 数量步长
+	MinQty       float64 // 最小数量
+	MinNotional  float64 // 最小名义价值
+	OpenInterest float64 // 持仓量（张数）
+	FundingRate  float64 // 资金费率（如 0.0001 表示0.01%，历史资金费率均为该值）
+	IndexPrice   float64 // 指数价格（0表示与最新价相同，标记价格始终为最新价）
+}
+
+// Server 模拟币安合约接口
+type Server struct {
+	URL string // 服务器地址
+
+	server    *httptest.Server
+	apiKey    string
+	apiSecret string
+
+	mu        sync.Mutex
+	symbols   map[string]*symbolState
+	balance   float64                   // USDT钱包余额
+	dualSide  bool                      // 双向持仓模式（只记录设置，撮合按单向持仓）
+	orders    []*binance.Order          // 全部订单（按下单顺序）
+	byID      map[int64]*binance.Order  // 订单ID → 订单
+	byClient  map[string]*binance.Order // 客户端订单ID → 订单
+	trades    []binance.UserTrade       // 成交记录
+	nextID    int64                     // 下一个订单ID
+	nextTrade int64                     // 下一个成交ID
+	requests  map[string]int            // "方法 路径" → 次数
+	unhandled []string                  // 未实现的请求
+	failures  map[string]failure        // "方法 路径"（下单可加 " 订单类型"）→ 下一次返回的错误
+	spot      bool                      // 现货模式（NewSpot 创建，只提供 api/v3 接口）
+	assets    map[string]*spotAsset     // 现货资产余额
+}
+
+// symbolState 交易对状态
+type symbolState struct {
+	cfg        Symbol
+	klines     map[string][]binance.Kline // 周期 → K线
+	position   float64                    // 持仓数量（空仓为负）
+	entry      float64                    // 开仓均价
+	leverage   int                        // 杠杆倍数
+	marginType string                     // 保证金模式（CROSSED/ISOLATED）
+}
+
+// failure 注入的错误响应
+type failure struct {
+	status  int
+	code    int
+	msg     string
+	process bool // 照常处理请求后再返回错误（模拟已执行但响应超时）
+	skip    int  // 先放行的请求次数
+}
+
+// New 启动模拟服务器，签名请求需使用相同的API密钥
+func New(apiKey, apiSecret string) *Server {
+	s := &Server{
+		apiKey:    apiKey,
+		apiSecret: apiSecret,
+		symbols:   make(map[string]*symbolState),
+		balance:   10000,
+		byID:      make(map[int64]*binance.Order),
+		byClient:  make(map[string]*binance.Order),
+		nextID:    firstOrderID,
+		nextTrade: 1,
+		requests:  make(map[string]int),
+		failures:  make(map[string]failure),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close 关闭服务器
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// AddSymbol 添加交易对（未设置的交易规则使用默认值：tickSize 0.01、stepSize 0.001、最小名义价值5）
+func (s *Server) AddSymbol(sym Symbol) {
+	if sym.TickSize <= 0 {
+		sym.TickSize = 0.01
+	}
+	if sym.StepSize <= 0 {
+		sym.StepSize = 0.001
+	}
+	if sym.MinQty <= 0 {
+		sym.MinQty = sym.StepSize
+	}
+	if sym.MinNotional <= 0 {
+		sym.MinNotional = 5
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.symbols[sym.Symbol] = &symbolState{
+		cfg:        sym,
+		klines:     make(map[string][]binance.Kline),
+		leverage:   defaultLeverage,
+		marginType: "CROSSED",
+	}
+}
+
+// SetKlines 设置交易对某周期的K线，最新价更新为最后一根的收盘价（交易对不存在时忽略）
+func (s *Server) SetKlines(symbol, interval string, klines []binance.Kline) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.symbols[symbol]
+	if state == nil {
+		return
+	}
+	state.klines[interval] = klines
+	if len(klines) > 0 {
+		if price, err := strconv.ParseFloat(klines[len(klines)-1].Close, 64); err == nil && price > 0 {
+			state.cfg.Price = price
+		}
+	}
+}
+
+// SetPrice 设置最新价（同时更新各周期最后一根K线），越过触发价的止损止盈单、价格到达的限价单成交
+func (s *Server) SetPrice(symbol string, price float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.symbols[symbol]
+	if state == nil {
+		return
+	}
+	state.cfg.Price = price
+	for _, klines := range state.klines {
+		if len(klines) == 0 {
+			continue
+		}
+		last := &klines[len(klines)-1]
+		last.Close = formatFloat(price)
+		if high, _ := strconv.ParseFloat(last.High, 64); price > high {
+			last.High = last.Close
+		}
+		if low, _ := strconv.ParseFloat(last.Low, 64); price < low {
+			last.Low = last.Close
+		}
+	}
+	s.matchResting(state)
+}
+
+// SetIndexPrice 设置指数价格（标记价格仍为最新价，用于模拟溢价；0表示与最新价相同）
+func (s *Server) SetIndexPrice(symbol string, price float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state := s.symbols[symbol]; state != nil {
+		state.cfg.IndexPrice = price
+	}
+}
+
+// SetBalance 设置USDT钱包余额
+func (s *Server) SetBalance(usdt float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balance = usdt
+}
+
+// FailNext 下一次该请求（如 "POST", "/fapi/v1/order"）返回错误；status 为5xx时模拟结果未知的服务端故障
+func (s *Server) FailNext(method, path string, status, code int, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method+" "+path] = failure{status: status, code: code, msg: msg}
+}
+
+// TimeoutNext 下一次该请求照常处理（如订单已成交），但返回 -1007 后端超时，客户端无法得知结果
+func (s *Server) TimeoutNext(method, path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method+" "+path] = failure{
+		status:  http.StatusServiceUnavailable,
+		code:    -1007,
+		msg:     "Timeout waiting for response from backend server. Send status unknown; execution status unknown.",
+		process: true,
+	}
+}
+
+// RejectNextOrder 下一次该类型（如 STOP_MARKET，现货为 STOP_LOSS）的下单请求被拒绝，其他类型的下单不受影响
+func (s *Server) RejectNextOrder(orderType string, code int, msg string) {
+	s.RejectOrderAfter(orderType, 0, code, msg)
+}
+
+// RejectOrderAfter 该类型的下单请求先放行skip次，之后的下一次被拒绝
+func (s *Server) RejectOrderAfter(orderType string, skip, code int, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[http.MethodPost+" "+s.orderEndpoint()+" "+orderType] = failure{status: http.StatusBadRequest, code: code, msg: msg, skip: skip}
+}
+
+// PartialFill 挂单成交qty（不超过剩余数量）：限价单按委托价成交（Maker），其他订单按最新价成交，只支持合约
+func (s *Server) PartialFill(orderID int64, qty float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	order := s.byID[orderID]
+	if s.spot || order == nil || order.IsFinal() {
+		return
+	}
+	state := s.symbols[order.Symbol]
+	price, maker := state.cfg.Price, false
+	if order.Type == binance.OrderTypeLimit {
+		price, _ = strconv.ParseFloat(order.Price, 64)
+		maker = true
+	}
+	orig, _ := strconv.ParseFloat(order.OrigQty, 64)
+	s.execute(state, order, min(qty, orig-order.ExecutedQtyFloat()), price, maker)
+}
+
+// Orders 交易对的全部订单（按下单顺序）
+func (s *Server) Orders(symbol string) []binance.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var orders []binance.Order
+	for _, o := range s.orders {
+		if o.Symbol == symbol {
+			orders = append(orders, *o)
+		}
+	}
+	return orders
+}
+
+// Position 交易对的持仓数量（空仓为负）和开仓均价
+func (s *Server) Position(symbol string) (amt, entryPrice float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state := s.symbols[symbol]; state != nil {
+		return state.position, state.entry
+	}
+	return 0, 0
+}
+
+// Balance USDT钱包余额（含已实现盈亏和手续费）
+func (s *Server) Balance() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balance
+}
+
+// Trades 交易对的成交记录
+func (s *Server) Trades(symbol string) []binance.UserTrade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var trades []binance.UserTrade
+	for _, t := range s.trades {
+		if t.Symbol == symbol {
+			trades = append(trades, t)
+		}
+	}
+	return trades
+}
+
+// Requests 该请求（如 "GET", "/fapi/v1/klines"）的次数
+func (s *Server) Requests(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[method+" "+path]
+}
+
+// Unhandled 未实现的请求（方法 路径，去重后按字母排序）
+func (s *Server) Unhandled() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[string]bool)
+	var list []string
+	for _, r := range s.unhandled {
+		if !seen[r] {
+			seen[r] = true
+			list = append(list, r)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// MakeKlines 生成count根震荡上涨的K线：第i根收盘价为 base×(1+0.002×i)×(1+0.01×sin(i/3))，最高、最低价为收盘价±0.4%
+// 最后一根K线的开盘时间为end所在周期的开始时间（未收盘）
+func MakeKlines(base float64, count int, interval time.Duration, end time.Time) []binance.Kline {
+	start := end.Truncate(interval).Add(-time.Duration(count-1) * interval)
+	klines := make([]binance.Kline, count)
+	for i := range klines {
+		c := base * (1 + 0.002*float64(i)) * (1 + 0.01*math.Sin(float64(i)/3))
+		open := start.Add(time.Duration(i) * interval)
+		klines[i] = binance.Kline{
+			OpenTime:                 open.UnixMilli(),
+			Open:                     formatFloat(c * 0.999),
+			High:                     formatFloat(c * 1.004),
+			Low:                      formatFloat(c * 0.996),
+			Close:                    formatFloat(c),
+			Volume:                   "1000",
+			CloseTime:                open.Add(interval).UnixMilli() - 1,
+			QuoteAssetVolume:         formatFloat(c * 1000),
+			NumberOfTrades:           500,
+			TakerBuyBaseAssetVolume:  "500",
+			TakerBuyQuoteAssetVolume: formatFloat(c * 500),
+		}
+	}
+	return klines
+}
+
+// serveHTTP 记录请求、校验签名后分发到各接口
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[key]++
+	f, failing := s.failures[key]
+	if !failing && r.URL.Path == s.orderEndpoint() {
+		typed := key + " " + r.URL.Query().Get("type")
+		if f, failing = s.failures[typed]; failing {
+			key = typed
+		}
+	}
+	if failing && f.skip > 0 {
+		f.skip--
+		s.failures[key] = f
+		failing = false
+	}
+	if failing {
+		delete(s.failures, key)
+		if !f.process {
+			writeError(w, f.status, f.code, f.msg)
+			return
+		}
+	}
+
+	handler, signed := s.route(r.Method, r.URL.Path)
+	if handler == nil {
+		s.unhandled = append(s.unhandled, r.Method+" "+r.URL.Path)
+		writeError(w, http.StatusNotFound, -5000, "Path "+r.URL.Path+" not found")
+		return
+	}
+	if signed && !s.verify(r) {
+		writeError(w, http.StatusUnauthorized, -2015, "Invalid API-key, IP, or permissions for action.")
+		return
+	}
+	if failing {
+		// 请求照常执行，丢弃正常响应
+		handler(httptest.NewRecorder(), r.URL.Query())
+		writeError(w, f.status, f.code, f.msg)
+		return
+	}
+	handler(w, r.URL.Query())
+}
+
+// verify 校验API密钥和签名（签名为 signature 之前的查询字符串的HMAC-SHA256）
+func (s *Server) verify(r *http.Request) bool {
+	if r.Header.Get("X-MBX-APIKEY") != s.apiKey {
+		return false
+	}
+	query := r.URL.RawQuery
+	i := strings.LastIndex(query, "&signature=")
+	if i < 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(s.apiSecret))
+	mac.Write([]byte(query[:i]))
+	return hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(query[i+len("&signature="):]))
+}
+
+// writeJSON 输出JSON响应
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError 输出币安格式的错误响应
+func writeError(w http.ResponseWriter, status, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": code, "msg": msg})
+}
+
+// formatFloat 格式化数值（不带多余的0）
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// round 按步长取整（再按步长的小数位数取整，消除 700×0.001 这类浮点误差）
+func round(v, step float64) float64 {
+	if step <= 0 {
+		return v
+	}
+	scale := math.Pow(10, math.Max(0, math.Ceil(-math.Log10(step))))
+	return math.Round(math.Round(v/step)*step*scale) / scale
+}
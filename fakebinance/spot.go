@@ -0,0 +1,355 @@
+/*
+Package fakebinance 模拟币安现货接口（api/v3）
+
+主要功能：
+- NewSpot(apiKey, apiSecret string) *Server                    // 启动模拟现货服务器（binance.NewSpotClient 的 baseURL 使用 s.URL）
+- (s *Server) SetSpotBalance(asset string, free float64)       // 设置资产的可用余额（如已持有的标的资产）
+- (s *Server) SpotBalance(asset string) (free, locked float64) // 资产的可用和冻结余额
+
+与真实现货一致的行为：
+- 余额不足（可用余额，不含挂单冻结的部分）时下单返回 -2010
+- 卖单挂出后冻结标的资产，买入限价单冻结USDT，撤单或成交时释放
+- 买入手续费以标的资产扣除（到账数量 = 成交数量 × (1 - 费率)），卖出手续费以USDT扣除
+- STOP_LOSS、TAKE_PROFIT 挂单后在 SetPrice 越过触发价时按最新价成交，下单时已越过触发价返回 -2010
+*/
+package fakebinance
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/binance"
+)
+
+// 现货接口路径
+const (
+	spotEndpointPing         = "/api/v3/ping"
+	spotEndpointTime         = "/api/v3/time"
+	spotEndpointExchangeInfo = "/api/v3/exchangeInfo"
+	spotEndpointKlines       = "/api/v3/klines"
+	spotEndpointBookTicker   = "/api/v3/ticker/bookTicker"
+	spotEndpointDepth        = "/api/v3/depth"
+	spotEndpointAccount      = "/api/v3/account"
+	spotEndpointOrder        = "/api/v3/order"
+	spotEndpointOpenOrders   = "/api/v3/openOrders"
+	spotEndpointMyTrades     = "/api/v3/myTrades"
+	spotEndpointCommission   = "/api/v3/account/commission"
+)
+
+// 现货订单类型（合约的 STOP_MARKET、TAKE_PROFIT_MARKET 由客户端转换）
+const (
+	spotOrderTypeStopLoss   = "STOP_LOSS"
+	spotOrderTypeTakeProfit = "TAKE_PROFIT"
+	spotOrderTypeLimitMaker = "LIMIT_MAKER"
+)
+
+// spotAsset 现货资产余额
+type spotAsset struct {
+	free   float64 // 可用
+	locked float64 // 挂单冻结
+}
+
+// NewSpot 启动模拟现货服务器（初始USDT可用余额10000）
+func NewSpot(apiKey, apiSecret string) *Server {
+	s := New(apiKey, apiSecret)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spot = true
+	s.assets = map[string]*spotAsset{"USDT": {free: 10000}}
+	return s
+}
+
+// SetSpotBalance 设置资产的可用余额
+func (s *Server) SetSpotBalance(asset string, free float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.asset(asset).free = free
+}
+
+// SpotBalance 资产的可用和冻结余额
+func (s *Server) SpotBalance(asset string) (free, locked float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.asset(asset)
+	return a.free, a.locked
+}
+
+// asset 资产余额（不存在时创建）
+func (s *Server) asset(name string) *spotAsset {
+	if s.assets == nil {
+		s.assets = make(map[string]*spotAsset)
+	}
+	a := s.assets[name]
+	if a == nil {
+		a = &spotAsset{}
+		s.assets[name] = a
+	}
+	return a
+}
+
+// orderEndpoint 下单接口路径（现货为 /api/v3/order）
+func (s *Server) orderEndpoint() string {
+	if s.spot {
+		return spotEndpointOrder
+	}
+	return binance.EndpointOrder
+}
+
+// spotRoute 现货接口的处理函数，返回是否需要签名
+func (s *Server) spotRoute(method, path string) (handlerFunc, bool) {
+	type key struct{ method, path string }
+	public := map[key]handlerFunc{
+		{"GET", spotEndpointPing}:         func(w http.ResponseWriter, q url.Values) { writeJSON(w, struct{}{}) },
+		{"GET", spotEndpointTime}:         s.handleTime,
+		{"GET", spotEndpointExchangeInfo}: s.handleExchangeInfo,
+		{"GET", spotEndpointKlines}:       s.handleKlines,
+		{"GET", spotEndpointBookTicker}:   s.handleBookTicker,
+		{"GET", spotEndpointDepth}:        s.handleDepth,
+	}
+	signed := map[key]handlerFunc{
+		{"GET", spotEndpointAccount}:       s.handleSpotAccount,
+		{"GET", spotEndpointCommission}:    s.handleSpotCommission,
+		{"POST", spotEndpointOrder}:        s.handleSpotPlaceOrder,
+		{"GET", spotEndpointOrder}:         s.handleGetOrder,
+		{"DELETE", spotEndpointOrder}:      s.handleCancelOrder,
+		{"GET", spotEndpointOpenOrders}:    s.handleOpenOrders,
+		{"DELETE", spotEndpointOpenOrders}: s.handleCancelAll,
+		{"GET", spotEndpointMyTrades}:      s.handleUserTrades,
+	}
+	if h, ok := public[key{method, path}]; ok {
+		return h, false
+	}
+	if h, ok := signed[key{method, path}]; ok {
+		return h, true
+	}
+	return nil, false
+}
+
+// handleSpotAccount 现货账户余额（omitZeroBalances=true 时省略零余额）
+func (s *Server) handleSpotAccount(w http.ResponseWriter, q url.Values) {
+	names := make([]string, 0, len(s.assets))
+	for name := range s.assets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	balances := []binance.SpotBalance{}
+	for _, name := range names {
+		a := s.assets[name]
+		if q.Get("omitZeroBalances") == "true" && a.free == 0 && a.locked == 0 {
+			continue
+		}
+		balances = append(balances, binance.SpotBalance{Asset: name, Free: formatFloat(a.free), Locked: formatFloat(a.locked)})
+	}
+	writeJSON(w, map[string]interface{}{"balances": balances})
+}
+
+// handleSpotCommission 现货手续费率
+func (s *Server) handleSpotCommission(w http.ResponseWriter, q url.Values) {
+	state := s.symbolOrError(w, q)
+	if state == nil {
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"symbol": state.cfg.Symbol,
+		"standardCommission": map[string]string{
+			"maker": formatFloat(defaultMakerRate),
+			"taker": formatFloat(defaultTakerRate),
+		},
+	})
+}
+
+// handleSpotPlaceOrder 现货下单（可用余额不足时返回 -2010）
+func (s *Server) handleSpotPlaceOrder(w http.ResponseWriter, q url.Values) {
+	state := s.symbolOrError(w, q)
+	if state == nil {
+		return
+	}
+	side, orderType := q.Get("side"), q.Get("type")
+	if side != binance.SideBuy && side != binance.SideSell {
+		writeError(w, http.StatusBadRequest, -1117, "Invalid side.")
+		return
+	}
+	clientID := q.Get("newClientOrderId")
+	if clientID != "" && s.byClient[clientID] != nil {
+		writeError(w, http.StatusBadRequest, -2010, "Duplicate order sent.")
+		return
+	}
+
+	qty, _ := strconv.ParseFloat(q.Get("quantity"), 64)
+	price, _ := strconv.ParseFloat(q.Get("price"), 64)
+	stopPrice, _ := strconv.ParseFloat(q.Get("stopPrice"), 64)
+	conditional := orderType == spotOrderTypeStopLoss || orderType == spotOrderTypeTakeProfit
+	limit := orderType == binance.OrderTypeLimit || orderType == spotOrderTypeLimitMaker
+	marketable := limit && ((side == binance.SideBuy && price >= state.cfg.Price) || (side == binance.SideSell && price <= state.cfg.Price))
+
+	switch {
+	case orderType != binance.OrderTypeMarket && !limit && !conditional:
+		writeError(w, http.StatusBadRequest, -1116, "Invalid orderType.")
+		return
+	case qty < state.cfg.MinQty:
+		writeError(w, http.StatusBadRequest, -1013, "Filter failure: LOT_SIZE")
+		return
+	case limit && price <= 0:
+		writeError(w, http.StatusBadRequest, -1013, "Filter failure: PRICE_FILTER")
+		return
+	case conditional && (stopPrice <= 0 || triggered(futuresType(orderType), side, stopPrice, state.cfg.Price)):
+		writeError(w, http.StatusBadRequest, -2010, "Stop price would trigger immediately.")
+		return
+	case orderType == spotOrderTypeLimitMaker && marketable:
+		writeError(w, http.StatusBadRequest, -2010, "Order would immediately match and take.")
+		return
+	case !conditional && qty*state.cfg.Price < state.cfg.MinNotional:
+		writeError(w, http.StatusBadRequest, -1013, "Filter failure: NOTIONAL")
+		return
+	}
+
+	// 卖出需要标的资产，买入需要USDT（市价单按最新价估算）
+	asset, amount := state.baseAsset(), qty
+	if side == binance.SideBuy {
+		cost := price
+		if orderType == binance.OrderTypeMarket || marketable || cost <= 0 {
+			cost = state.cfg.Price
+		}
+		asset, amount = "USDT", qty*cost
+	}
+	if s.asset(asset).free < amount-1e-9 {
+		writeError(w, http.StatusBadRequest, -2010, "Account has insufficient balance for requested action.")
+		return
+	}
+
+	if clientID == "" {
+		clientID = "fake_" + strconv.FormatInt(s.nextID, 10)
+	}
+	order := &binance.Order{
+		OrderID:       s.nextID,
+		Symbol:        state.cfg.Symbol,
+		Status:        binance.OrderStatusNew,
+		ClientOrderID: clientID,
+		Price:         formatFloat(price),
+		OrigQty:       formatFloat(qty),
+		ExecutedQty:   "0",
+		SpotCumQuote:  "0",
+		TimeInForce:   q.Get("timeInForce"),
+		Type:          orderType,
+		Side:          side,
+		StopPrice:     formatFloat(stopPrice),
+		UpdateTime:    time.Now().UnixMilli(),
+	}
+	if order.TimeInForce == "" {
+		order.TimeInForce = binance.TimeInForceGTC
+	}
+	s.nextID++
+
+	s.orders = append(s.orders, order)
+	s.byID[order.OrderID] = order
+	s.byClient[clientID] = order
+
+	switch {
+	case orderType == binance.OrderTypeMarket || marketable:
+		s.fillSpot(state, order, state.cfg.Price, false)
+	case orderType == binance.OrderTypeLimit && (order.TimeInForce == binance.TimeInForceIOC || order.TimeInForce == binance.TimeInForceFOK):
+		order.Status = binance.OrderStatusExpired
+	default:
+		s.lock(state, order)
+	}
+	writeJSON(w, order)
+}
+
+// fillSpot 现货订单全部成交：挂单冻结的余额先释放，再按成交结算两种资产和手续费
+func (s *Server) fillSpot(state *symbolState, order *binance.Order, price float64, maker bool) {
+	s.release(state, order)
+
+	qty, _ := strconv.ParseFloat(order.OrigQty, 64)
+	qty = round(qty, state.cfg.StepSize)
+	price = round(price, state.cfg.TickSize)
+	quote := qty * price
+
+	rate := defaultTakerRate
+	if maker {
+		rate = defaultMakerRate
+	}
+	base, usdt := s.asset(state.baseAsset()), s.asset("USDT")
+	commission, commissionAsset := quote*rate, "USDT"
+	if order.Side == binance.SideBuy {
+		// 买入手续费以标的资产扣除，到账数量少于成交数量
+		commission, commissionAsset = qty*rate, state.baseAsset()
+		usdt.free -= quote
+		base.free += qty - commission
+	} else {
+		base.free -= qty
+		usdt.free += quote - commission
+	}
+
+	now := time.Now().UnixMilli()
+	order.Status = binance.OrderStatusFilled
+	order.ExecutedQty = formatFloat(qty)
+	order.SpotCumQuote = formatFloat(quote)
+	order.UpdateTime = now
+
+	s.trades = append(s.trades, binance.UserTrade{
+		ID:              s.nextTrade,
+		Symbol:          state.cfg.Symbol,
+		OrderID:         order.OrderID,
+		Side:            order.Side,
+		Price:           formatFloat(price),
+		Qty:             formatFloat(qty),
+		QuoteQty:        formatFloat(quote),
+		RealizedPnl:     "0",
+		Commission:      formatFloat(commission),
+		CommissionAsset: commissionAsset,
+		Maker:           maker,
+		Buyer:           order.Side == binance.SideBuy,
+		Time:            now,
+	})
+	s.nextTrade++
+}
+
+// lock 冻结挂单占用的余额（卖单冻结标的资产，买单冻结USDT）
+func (s *Server) lock(state *symbolState, order *binance.Order) {
+	a, amount := s.lockedBy(state, order)
+	a.free -= amount
+	a.locked += amount
+}
+
+// release 释放挂单冻结的余额（非现货或已成交的订单不处理）
+func (s *Server) release(state *symbolState, order *binance.Order) {
+	if !s.spot || order.Status != binance.OrderStatusNew {
+		return
+	}
+	if order.Type == binance.OrderTypeMarket {
+		return
+	}
+	a, amount := s.lockedBy(state, order)
+	a.locked -= amount
+	a.free += amount
+}
+
+// lockedBy 挂单冻结的资产和数量
+func (s *Server) lockedBy(state *symbolState, order *binance.Order) (*spotAsset, float64) {
+	qty, _ := strconv.ParseFloat(order.OrigQty, 64)
+	if order.Side == binance.SideSell {
+		return s.asset(state.baseAsset()), qty
+	}
+	price, _ := strconv.ParseFloat(order.Price, 64)
+	if price <= 0 {
+		price, _ = strconv.ParseFloat(order.StopPrice, 64)
+	}
+	return s.asset("USDT"), qty * price
+}
+
+// baseAsset 交易对的标的资产（如 BTCUSDT → BTC）
+func (st *symbolState) baseAsset() string {
+	return st.cfg.Symbol[:len(st.cfg.Symbol)-4]
+}
+
+// futuresType 现货条件单类型对应的合约类型（用于判断触发方向）
+func futuresType(orderType string) string {
+	if orderType == spotOrderTypeTakeProfit {
+		return binance.OrderTypeTakeProfitMarket
+	}
+	return binance.OrderTypeStopMarket
+}
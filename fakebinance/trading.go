@@ -0,0 +1,354 @@
+/*
+Package fakebinance 模拟撮合（下单、查询、撤单、条件单触发、持仓和余额结算）
+
+主要功能：
+- (s *Server) matchResting(state *symbolState)  // 按最新价检查挂单：条件单越过触发价、限价单价格到达时成交
+
+订单返回 newOrderRespType=RESULT 格式：市价单和可立即成交的限价单在响应中已是 FILLED。
+*/
+package fakebinance
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/binance"
+)
+
+// handlePlaceOrder 下单
+func (s *Server) handlePlaceOrder(w http.ResponseWriter, q url.Values) {
+	state := s.symbolOrError(w, q)
+	if state == nil {
+		return
+	}
+	side, orderType := q.Get("side"), q.Get("type")
+	if side != binance.SideBuy && side != binance.SideSell {
+		writeError(w, http.StatusBadRequest, -1117, "Invalid side.")
+		return
+	}
+	clientID := q.Get("newClientOrderId")
+	if clientID != "" && s.byClient[clientID] != nil {
+		writeError(w, http.StatusBadRequest, -4116, "ClientOrderId is duplicated.")
+		return
+	}
+
+	qty, _ := strconv.ParseFloat(q.Get("quantity"), 64)
+	price, _ := strconv.ParseFloat(q.Get("price"), 64)
+	stopPrice, _ := strconv.ParseFloat(q.Get("stopPrice"), 64)
+	reduceOnly := q.Get("reduceOnly") == "true"
+	closePosition := q.Get("closePosition") == "true"
+	conditional := orderType == binance.OrderTypeStopMarket || orderType == binance.OrderTypeTakeProfitMarket
+
+	switch {
+	case orderType != binance.OrderTypeMarket && orderType != binance.OrderTypeLimit && !conditional:
+		writeError(w, http.StatusBadRequest, -1116, "Invalid orderType.")
+		return
+	case !closePosition && qty < state.cfg.MinQty:
+		writeError(w, http.StatusBadRequest, -4003, "Quantity less than or equal to zero.")
+		return
+	case orderType == binance.OrderTypeLimit && price <= 0:
+		writeError(w, http.StatusBadRequest, -4014, "Price not increased by tick size.")
+		return
+	case conditional && stopPrice <= 0:
+		writeError(w, http.StatusBadRequest, -2021, "Order would immediately trigger.")
+		return
+	case conditional && triggered(orderType, side, stopPrice, state.cfg.Price):
+		writeError(w, http.StatusBadRequest, -2021, "Order would immediately trigger.")
+		return
+	case !reduceOnly && !closePosition && !conditional && qty*state.cfg.Price < state.cfg.MinNotional:
+		writeError(w, http.StatusBadRequest, -4164, "Order's notional must be no smaller than "+formatFloat(state.cfg.MinNotional))
+		return
+	case reduceOnly && !conditional && reducible(state, side) <= 0:
+		// 只减仓的订单没有可减的持仓时被拒绝（条件单挂单时不检查，触发时再按持仓成交）
+		writeError(w, http.StatusBadRequest, -2022, "ReduceOnly Order is rejected.")
+		return
+	case !reduceOnly && !closePosition && s.requiredMargin(state, side, qty) > s.available():
+		writeError(w, http.StatusBadRequest, -2019, "Margin is insufficient.")
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	if clientID == "" {
+		clientID = "fake_" + strconv.FormatInt(s.nextID, 10)
+	}
+	order := &binance.Order{
+		OrderID:       s.nextID,
+		Symbol:        state.cfg.Symbol,
+		Status:        binance.OrderStatusNew,
+		ClientOrderID: clientID,
+		Price:         formatFloat(price),
+		AvgPrice:      "0",
+		OrigQty:       formatFloat(qty),
+		ExecutedQty:   "0",
+		CumQuote:      "0",
+		TimeInForce:   q.Get("timeInForce"),
+		Type:          orderType,
+		ReduceOnly:    reduceOnly,
+		ClosePosition: closePosition,
+		Side:          side,
+		PositionSide:  "BOTH",
+		StopPrice:     formatFloat(stopPrice),
+		WorkingType:   q.Get("workingType"),
+		OrigType:      orderType,
+		UpdateTime:    now,
+	}
+	if order.TimeInForce == "" {
+		order.TimeInForce = binance.TimeInForceGTC
+	}
+	s.nextID++
+
+	s.orders = append(s.orders, order)
+	s.byID[order.OrderID] = order
+	s.byClient[clientID] = order
+
+	switch orderType {
+	case binance.OrderTypeMarket:
+		s.fill(state, order, state.cfg.Price, false)
+	case binance.OrderTypeLimit:
+		marketable := (side == binance.SideBuy && price >= state.cfg.Price) || (side == binance.SideSell && price <= state.cfg.Price)
+		switch {
+		case marketable && order.TimeInForce == binance.TimeInForceGTX:
+			order.Status = binance.OrderStatusExpired
+		case marketable:
+			s.fill(state, order, state.cfg.Price, false)
+		case order.TimeInForce == binance.TimeInForceIOC || order.TimeInForce == binance.TimeInForceFOK:
+			order.Status = binance.OrderStatusExpired
+		}
+	}
+	writeJSON(w, order)
+}
+
+// handleGetOrder 查询订单（orderId 或 origClientOrderId）
+func (s *Server) handleGetOrder(w http.ResponseWriter, q url.Values) {
+	if order := s.findOrder(w, q, -2013, "Order does not exist."); order != nil {
+		writeJSON(w, order)
+	}
+}
+
+// handleCancelOrder 撤销订单（已结束的订单返回 -2011）
+func (s *Server) handleCancelOrder(w http.ResponseWriter, q url.Values) {
+	order := s.findOrder(w, q, -2011, "Unknown order sent.")
+	if order == nil {
+		return
+	}
+	if order.IsFinal() {
+		writeError(w, http.StatusBadRequest, -2011, "Unknown order sent.")
+		return
+	}
+	s.release(s.symbols[order.Symbol], order)
+	order.Status = binance.OrderStatusCanceled
+	order.UpdateTime = time.Now().UnixMilli()
+	writeJSON(w, order)
+}
+
+// handleOpenOrders 当前挂单（没有 symbol 时返回全部交易对）
+func (s *Server) handleOpenOrders(w http.ResponseWriter, q url.Values) {
+	symbol := q.Get("symbol")
+	orders := []binance.Order{}
+	for _, o := range s.orders {
+		if (symbol == "" || o.Symbol == symbol) && !o.IsFinal() {
+			orders = append(orders, *o)
+		}
+	}
+	writeJSON(w, orders)
+}
+
+// handleCancelAll 撤销交易对的全部挂单
+func (s *Server) handleCancelAll(w http.ResponseWriter, q url.Values) {
+	state := s.symbolOrError(w, q)
+	if state == nil {
+		return
+	}
+	for _, o := range s.orders {
+		if o.Symbol == state.cfg.Symbol && !o.IsFinal() {
+			s.release(state, o)
+			o.Status = binance.OrderStatusCanceled
+			o.UpdateTime = time.Now().UnixMilli()
+		}
+	}
+	writeJSON(w, map[string]interface{}{"code": 200, "msg": "The operation of cancel all open order is done."})
+}
+
+// findOrder 按 orderId 或 origClientOrderId 查找交易对的订单，不存在时输出错误
+func (s *Server) findOrder(w http.ResponseWriter, q url.Values, code int, msg string) *binance.Order {
+	var order *binance.Order
+	if id, err := strconv.ParseInt(q.Get("orderId"), 10, 64); err == nil {
+		order = s.byID[id]
+	} else if clientID := q.Get("origClientOrderId"); clientID != "" {
+		order = s.byClient[clientID]
+	}
+	if order == nil || order.Symbol != q.Get("symbol") {
+		writeError(w, http.StatusBadRequest, code, msg)
+		return nil
+	}
+	return order
+}
+
+// matchResting 按最新价检查挂单：条件单越过触发价时按最新价成交（持仓已平的只减仓条件单失效），限价单价格到达时按限价成交
+// 现货的 STOP_LOSS、TAKE_PROFIT 同样在越过触发价时成交
+func (s *Server) matchResting(state *symbolState) {
+	price := state.cfg.Price
+	for _, o := range s.orders {
+		if o.Symbol != state.cfg.Symbol || o.IsFinal() {
+			continue
+		}
+		switch o.Type {
+		case binance.OrderTypeStopMarket, binance.OrderTypeTakeProfitMarket:
+			stop, _ := strconv.ParseFloat(o.StopPrice, 64)
+			if !triggered(o.Type, o.Side, stop, price) {
+				continue
+			}
+			if (o.ReduceOnly || o.ClosePosition) && reducible(state, o.Side) <= 0 {
+				o.Status = binance.OrderStatusExpired
+				o.UpdateTime = time.Now().UnixMilli()
+				continue
+			}
+			s.fill(state, o, price, false)
+		case spotOrderTypeStopLoss, spotOrderTypeTakeProfit:
+			stop, _ := strconv.ParseFloat(o.StopPrice, 64)
+			if triggered(futuresType(o.Type), o.Side, stop, price) {
+				s.fillSpot(state, o, price, false)
+			}
+		case binance.OrderTypeLimit, spotOrderTypeLimitMaker:
+			limit, _ := strconv.ParseFloat(o.Price, 64)
+			if (o.Side == binance.SideBuy && price <= limit) || (o.Side == binance.SideSell && price >= limit) {
+				s.fill(state, o, limit, true)
+			}
+		}
+	}
+}
+
+// fill 订单剩余数量全部成交
+func (s *Server) fill(state *symbolState, order *binance.Order, price float64, maker bool) {
+	if s.spot {
+		s.fillSpot(state, order, price, maker)
+		return
+	}
+	orig, _ := strconv.ParseFloat(order.OrigQty, 64)
+	s.execute(state, order, orig-order.ExecutedQtyFloat(), price, maker)
+}
+
+// execute 订单成交qty：更新持仓、开仓均价和钱包余额（已实现盈亏 - 手续费），记录成交
+// 累计成交达到委托数量时订单为 FILLED，否则为 PARTIALLY_FILLED；只减仓和 closePosition 订单的成交数量不超过当前可减的持仓
+func (s *Server) execute(state *symbolState, order *binance.Order, qty, price float64, maker bool) {
+	if order.ReduceOnly || order.ClosePosition {
+		qty = min(qty, reducible(state, order.Side))
+		if order.ClosePosition {
+			qty = reducible(state, order.Side)
+		}
+	}
+	qty = round(qty, state.cfg.StepSize)
+	price = round(price, state.cfg.TickSize)
+
+	signed := qty
+	if order.Side == binance.SideSell {
+		signed = -qty
+	}
+
+	// 反方向成交先减仓（计算已实现盈亏），剩余部分按新方向开仓
+	var realized float64
+	if state.position != 0 && (state.position > 0) != (signed > 0) {
+		closed := min(abs(signed), abs(state.position))
+		if state.position > 0 {
+			realized = closed * (price - state.entry)
+		} else {
+			realized = closed * (state.entry - price)
+		}
+	}
+	next := round(state.position+signed, state.cfg.StepSize)
+	switch {
+	case next == 0:
+		state.entry = 0
+	case state.position == 0 || (state.position > 0) != (next > 0):
+		state.entry = price
+	case (state.position > 0) == (signed > 0):
+		state.entry = (abs(state.position)*state.entry + qty*price) / abs(next)
+	}
+	state.position = next
+
+	rate := defaultTakerRate
+	if maker {
+		rate = defaultMakerRate
+	}
+	commission := qty * price * rate
+	s.balance += realized - commission
+
+	now := time.Now().UnixMilli()
+	orig, _ := strconv.ParseFloat(order.OrigQty, 64)
+	prior := order.ExecutedQtyFloat()
+	executed := round(prior+qty, state.cfg.StepSize)
+	cumQuote, _ := strconv.ParseFloat(order.CumQuote, 64)
+	cumQuote += qty * price
+	// 只减仓订单没有剩余可减的持仓时视为完成
+	order.Status = binance.OrderStatusPartiallyFilled
+	if executed >= orig || order.ClosePosition || (order.ReduceOnly && reducible(state, order.Side) <= 0) {
+		order.Status = binance.OrderStatusFilled
+	}
+	order.ExecutedQty = formatFloat(executed)
+	order.AvgPrice = formatFloat(price)
+	if prior > 0 {
+		order.AvgPrice = formatFloat(round(cumQuote/executed, state.cfg.TickSize/100))
+	}
+	order.CumQuote = formatFloat(cumQuote)
+	order.UpdateTime = now
+
+	s.trades = append(s.trades, binance.UserTrade{
+		ID:              s.nextTrade,
+		Symbol:          state.cfg.Symbol,
+		OrderID:         order.OrderID,
+		Side:            order.Side,
+		PositionSide:    "BOTH",
+		Price:           formatFloat(price),
+		Qty:             formatFloat(qty),
+		QuoteQty:        formatFloat(qty * price),
+		RealizedPnl:     formatFloat(realized),
+		Commission:      formatFloat(commission),
+		CommissionAsset: "USDT",
+		Maker:           maker,
+		Buyer:           order.Side == binance.SideBuy,
+		Time:            now,
+	})
+	s.nextTrade++
+}
+
+// requiredMargin 下单需要的起始保证金（减仓部分不需要保证金）
+func (s *Server) requiredMargin(state *symbolState, side string, qty float64) float64 {
+	opening := qty - reducible(state, side)
+	if opening <= 0 {
+		return 0
+	}
+	return opening * state.cfg.Price / float64(state.leverage)
+}
+
+// triggered 条件单在该价格是否触发（卖出止损：价格 ≤ 触发价；卖出止盈：价格 ≥ 触发价；买入相反）
+func triggered(orderType, side string, stopPrice, price float64) bool {
+	below := orderType == binance.OrderTypeStopMarket
+	if side == binance.SideBuy {
+		below = !below
+	}
+	if below {
+		return price <= stopPrice
+	}
+	return price >= stopPrice
+}
+
+// reducible 该方向的订单可减少的持仓数量（买入减空仓，卖出减多仓）
+func reducible(state *symbolState, side string) float64 {
+	if side == binance.SideBuy && state.position < 0 {
+		return -state.position
+	}
+	if side == binance.SideSell && state.position > 0 {
+		return state.position
+	}
+	return 0
+}
+
+// abs 绝对值
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -1,11 +1,12 @@
 module crypto-ai-trader
 
-go 1.23.2
-
-require gopkg.in/yaml.v3 v3.0.1
+go 1.26.0
 
 require (
-	github.com/markcheno/go-talib v0.0.0-20250114000313-ec55a20c902f // indirect
-	go.uber.org/multierr v1.11.0 // indirect
-	go.uber.org/zap v1.27.1 // indirect
+	github.com/markcheno/go-talib v0.0.0-20250114000313-ec55a20c902f
+	go.uber.org/zap v1.27.1
+	golang.org/x/sync v0.23.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require go.uber.org/multierr v1.11.0 // indirect
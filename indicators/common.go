@@ -2,18 +2,27 @@
 Package indicators 通用指标计算函数
 
 主要功能：
-- CalculateEMA(klines []binance.Kline, period int) float64                             // 计算EMA
-- CalculateMACD(klines []binance.Kline) *MACDData                                      // 计算MACD
-- CalculateRSI(klines []binance.Kline, period int) float64                             // 计算RSI
-- CalculateBollingerBands(klines []binance.Kline, period int, stdDev float64) *BBData  // 计算布林带
-- CalculateATR(klines []binance.Kline, period int) float64                             // 计算ATR
-- CalculateADX(klines []binance.Kline, period int) float64                             // 计算ADX
-- CalculateStochRSI(klines []binance.Kline, period int) *StochRSIData                  // 计算Stochastic RSI
-- CalculateVWAP(klines []binance.Kline) float64                                        // 计算VWAP
-- GetVolume(kline binance.Kline) float64                                               // 获取成交量
-- formatPrice(value float64) float64                                                   // 格式化价格（2位小数）
-- formatMACD(value float64) float64                                                    // 格式化MACD（4位小数）
-- formatPercent(value float64) float64                                                 // 格式化百分比（2位小数）
+- CalculateEMA(klines []binance.Kline, period int) float64                              // 计算EMA
+- CalculateEMAState(klines []binance.Kline, atrPeriod int) *EMAStateData                // 计算EMA排列、斜率与交叉状态
+- CalculateMACD(klines []binance.Kline) *MACDData                                       // 计算MACD
+- CalculateRSI(klines []binance.Kline, period int) float64                              // 计算RSI
+- CalculateBollingerBands(klines []binance.Kline, period int, stdDev float64) *BBData   // 计算布林带
+- CalculateBBWidthSeries(klines []binance.Kline, period int, stdDev float64) []float64  // 计算布林带宽度百分比序列
+- CalculateATR(klines []binance.Kline, period int) float64                              // 计算ATR
+- CalculateATRPercent(klines []binance.Kline, period int) float64                       // 计算ATR占收盘价的百分比
+- CalculateADX(klines []binance.Kline, period int) float64                              // 计算ADX
+- CalculateStochRSI(klines []binance.Kline, period int) *StochRSIData                   // 计算Stochastic RSI
+- CalculateVWAP(klines []binance.Kline) float64                                         // 计算VWAP
+- GetVolume(kline binance.Kline) float64                                                // 获取成交量
+- GetQuoteVolume(kline binance.Kline) float64                                           // 获取成交额
+- CalculateTakerBuyPercent(kline binance.Kline) (float64, bool)                         // 计算主动买入量占成交量的百分比
+- CalculateVolumeRatio(klines []binance.Kline, period int) float64                      // 计算当前成交量与前period根K线平均成交量的比值
+- DefaultIndicatorParams() IndicatorParams                                              // 默认指标参数（短线/中长线）
+- ScalpIndicatorParams() IndicatorParams                                                // 剥头皮指标参数
+- SwingIndicatorParams() IndicatorParams                                                // 波段指标参数
+- formatPrice(value float64) float64                                                    // 格式化价格（至少2位小数，保留6位有效数字）
+- formatMACD(value float64) float64                                                     // 格式化MACD（至少4位小数，保留6位有效数字）
+- formatPercent(value float64) float64                                                  // 格式化百分比（2位小数）
 */
 package indicators
 
@@ -21,6 +30,7 @@ import (
 	"crypto-ai-trader/binance"
 	"math"
 	"strconv"
+	"time"
 
 	"github.com/markcheno/go-talib"
 )
@@ -29,6 +39,8 @@ import (
 // period: EMA周期（如9, 21, 55）
 // 返回：最新的EMA值
 func CalculateEMA(klines []binance.Kline, period int) float64 {
+	defer trackFunc("ema", time.Now())
+
 	if len(klines) < period {
 		return 0
 	}
@@ -43,10 +55,69 @@ func CalculateEMA(klines []binance.Kline, period int) float64 {
 	return formatPrice(ema[len(ema)-1])
 }
 
+// EMA21斜率的计算跨度（K线根数）
+const emaSlopeBars = 5
+
+// EMA交叉方向
+const (
+	CrossGolden = "golden" // EMA9上穿EMA21
+	CrossDead   = "dead"   // EMA9下穿EMA21
+)
+
+// CalculateEMAState 计算EMA排列、EMA21斜率、距最近一次EMA9/21交叉的K线根数和收盘价与EMA55的ATR距离
+// 使用未取整的均线值（低价币的均线不会因为取整而相等）
+// 返回：状态数据，K线不足55根时返回nil
+func CalculateEMAState(klines []binance.Kline, atrPeriod int) *EMAStateData {
+	defer trackFunc("ema_state", time.Now())
+
+	if len(klines) < 55 {
+		return nil
+	}
+
+	closes := extractCloses(klines)
+	ema9 := talib.Ema(closes, 9)
+	ema21 := talib.Ema(closes, 21)
+	ema55 := talib.Ema(closes, 55)
+	latest := len(closes) - 1
+
+	state := &EMAStateData{Order: TrendMixed}
+	switch {
+	case ema9[latest] > ema21[latest] && ema21[latest] > ema55[latest]:
+		state.Order = TrendUp
+	case ema9[latest] < ema21[latest] && ema21[latest] < ema55[latest]:
+		state.Order = TrendDown
+	}
+
+	if prev := ema21[latest-emaSlopeBars]; prev > 0 {
+		state.EMA21Slope = math.Round((ema21[latest]-prev)/prev*100*10000) / 10000
+	}
+
+	// 从最新一根向前找EMA9与EMA21的大小关系发生变化的K线（EMA21从第21根开始有值）
+	for i := latest; i > 21; i-- {
+		cur, prev := ema9[i]-ema21[i], ema9[i-1]-ema21[i-1]
+		if cur > 0 && prev <= 0 || cur < 0 && prev >= 0 {
+			bars := latest - i
+			state.CrossBars = &bars
+			state.Cross = CrossGolden
+			if cur < 0 {
+				state.Cross = CrossDead
+			}
+			break
+		}
+	}
+
+	if atr := calculateATRRaw(klines, atrPeriod); atr > 0 {
+		state.EMA55DistATR = formatPercent((closes[latest] - ema55[latest]) / atr)
+	}
+	return state
+}
+
 // CalculateMACD 计算MACD指标（使用ta-lib）
 // 使用标准参数：快线12，慢线26，信号线9
 // 返回：最新的MACD数据
 func CalculateMACD(klines []binance.Kline) *MACDData {
+	defer trackFunc("macd", time.Now())
+
 	if len(klines) < 26 {
 		return nil
 	}
@@ -71,6 +142,8 @@ func CalculateMACD(klines []binance.Kline) *MACDData {
 // period: RSI周期（通常为14）
 // 返回：最新的RSI值（0-100）
 func CalculateRSI(klines []binance.Kline, period int) float64 {
+	defer trackFunc("rsi", time.Now())
+
 	if len(klines) < period+1 {
 		return 0
 	}
@@ -90,6 +163,8 @@ func CalculateRSI(klines []binance.Kline, period int) float64 {
 // stdDev: 标准差倍数（通常为2）
 // 返回：最新的布林带数据
 func CalculateBollingerBands(klines []binance.Kline, period int, stdDev float64) *BBData {
+	defer trackFunc("bbands", time.Now())
+
 	if len(klines) < period {
 		return nil
 	}
@@ -110,10 +185,30 @@ func CalculateBollingerBands(klines []binance.Kline, period int, stdDev float64)
 	}
 }
 
+// CalculateBBWidthSeries 计算布林带宽度占中轨的百分比序列（(上轨-下轨)/中轨×100，从旧到新，不受价格量级影响）
+// 返回：各根K线的宽度百分比（前period-1根没有值，不包含），K线不足时返回nil
+func CalculateBBWidthSeries(klines []binance.Kline, period int, stdDev float64) []float64 {
+	if len(klines) < period {
+		return nil
+	}
+
+	upper, middle, lower := talib.BBands(extractCloses(klines), period, stdDev, stdDev, talib.SMA)
+	series := make([]float64, 0, len(klines)-period+1)
+	for i := period - 1; i < len(klines); i++ {
+		if middle[i] <= 0 {
+			continue
+		}
+		series = append(series, (upper[i]-lower[i])/middle[i]*100)
+	}
+	return series
+}
+
 // CalculateATR 计算平均真实波幅（使用ta-lib）
 // period: ATR周期（通常为14）
 // 返回：最新的ATR值
 func CalculateATR(klines []binance.Kline, period int) float64 {
+	defer trackFunc("atr", time.Now())
+
 	if len(klines) < period+1 {
 		return 0
 	}
@@ -128,10 +223,27 @@ func CalculateATR(klines []binance.Kline, period int) float64 {
 	return formatPrice(atr[len(atr)-1])
 }
 
+// CalculateATRPercent 计算ATR占最新收盘价的百分比（不受价格量级影响，低价币也不会被舍入为0）
+// period: ATR周期（通常为14）
+// 返回：ATR% （如1.5表示ATR为价格的1.5%），K线不足时返回0
+func CalculateATRPercent(klines []binance.Kline, period int) float64 {
+	if len(klines) < period+1 {
+		return 0
+	}
+
+	last, _ := strconv.ParseFloat(klines[len(klines)-1].Close, 64)
+	if last <= 0 {
+		return 0
+	}
+	return math.Round(calculateATRRaw(klines, period)/last*100*10000) / 10000
+}
+
 // CalculateADX 计算平均趋向指标（使用ta-lib）
 // period: ADX周期（通常为14）
 // 返回：最新的ADX值
 func CalculateADX(klines []binance.Kline, period int) float64 {
+	defer trackFunc("adx", time.Now())
+
 	if len(klines) < period*2 {
 		return 0
 	}
@@ -150,6 +262,8 @@ func CalculateADX(klines []binance.Kline, period int) float64 {
 // period: 周期（通常为14）
 // 返回：最新的Stochastic RSI数据
 func CalculateStochRSI(klines []binance.Kline, period int) *StochRSIData {
+	defer trackFunc("stoch_rsi", time.Now())
+
 	if len(klines) < period*2 {
 		return nil
 	}
@@ -172,6 +286,8 @@ func CalculateStochRSI(klines []binance.Kline, period int) *StochRSIData {
 // CalculateVWAP 计算成交量加权平均价
 // 返回：最新的VWAP值
 func CalculateVWAP(klines []binance.Kline) float64 {
+	defer trackFunc("vwap", time.Now())
+
 	if len(klines) == 0 {
 		return 0
 	}
@@ -204,6 +320,80 @@ func GetVolume(kline binance.Kline) float64 {
 	return formatPrice(volume)
 }
 
+// GetQuoteVolume 获取K线成交额（计价币种，如USDT）
+func GetQuoteVolume(kline binance.Kline) float64 {
+	quoteVolume, _ := strconv.ParseFloat(kline.QuoteAssetVolume, 64)
+	return formatPrice(quoteVolume)
+}
+
+// CalculateTakerBuyPercent 计算K线主动买入量占成交量的百分比（50以上买方主导）
+// 返回false：成交量为0或交易所不提供主动买入量（如OKX）
+func CalculateTakerBuyPercent(kline binance.Kline) (float64, bool) {
+	volume, _ := strconv.ParseFloat(kline.Volume, 64)
+	takerBuy, err := strconv.ParseFloat(kline.TakerBuyBaseAssetVolume, 64)
+	if err != nil || volume <= 0 {
+		return 0, false
+	}
+	return formatPercent(takerBuy / volume * 100), true
+}
+
+// CalculateVolumeRatio 计算最新K线成交量与之前period根K线平均成交量的比值（放量大于1）
+// 返回：比值（K线不足或平均成交量为0时返回0）
+func CalculateVolumeRatio(klines []binance.Kline, period int) float64 {
+	if period <= 0 || len(klines) < period+1 {
+		return 0
+	}
+
+	latest := len(klines) - 1
+	total := 0.0
+	for _, kline := range klines[latest-period : latest] {
+		volume, _ := strconv.ParseFloat(kline.Volume, 64)
+		total += volume
+	}
+	if total <= 0 {
+		return 0
+	}
+
+	volume, _ := strconv.ParseFloat(klines[latest].Volume, 64)
+	return formatPercent(volume / (total / float64(period)))
+}
+
+// DefaultIndicatorParams 默认指标参数（短线/中长线使用）
+func DefaultIndicatorParams() IndicatorParams {
+	return IndicatorParams{
+		RSIPeriod:      14,
+		ATRPeriod:      14,
+		BBPeriod:       20,
+		BBStdDev:       2.0,
+		ADXPeriod:      14,
+		StochRSIPeriod: 14,
+	}
+}
+
+// ScalpIndicatorParams 剥头皮指标参数（周期更短，对1m/5m波动更敏感）
+func ScalpIndicatorParams() IndicatorParams {
+	return IndicatorParams{
+		RSIPeriod:      7,
+		ATRPeriod:      7,
+		BBPeriod:       20,
+		BBStdDev:       2.0,
+		ADXPeriod:      10,
+		StochRSIPeriod: 9,
+	}
+}
+
+// SwingIndicatorParams 波段指标参数（周期更长，过滤日内噪音）
+func SwingIndicatorParams() IndicatorParams {
+	return IndicatorParams{
+		RSIPeriod:      14,
+		ATRPeriod:      21,
+		BBPeriod:       20,
+		BBStdDev:       2.5,
+		ADXPeriod:      14,
+		StochRSIPeriod: 14,
+	}
+}
+
 // extractCloses 提取收盘价数组（辅助函数）
 func extractCloses(klines []binance.Kline) []float64 {
 	closes := make([]float64, len(klines))
@@ -228,14 +418,29 @@ func extractHLC(klines []binance.Kline) ([]float64, []float64, []float64) {
 	return highs, lows, closes
 }
 
-// formatPrice 格式化价格（2位小数）
+// priceSignificantDigits 价格类数值至少保留的有效数字位数
+const priceSignificantDigits = 6
+
+// formatPrice 格式化价格（至少2位小数，小数位不足6位有效数字时增加，SHIBUSDT、PEPEUSDT等低价币不会被舍入为0）
 func formatPrice(value float64) float64 {
-	return math.Round(value*100) / 100
+	return roundPrice(value, 2)
 }
 
-// formatMACD 格式化MACD值（4位小数）
+// formatMACD 格式化MACD值（至少4位小数，低价币保留6位有效数字）
 func formatMACD(value float64) float64 {
-	return math.Round(value*10000) / 10000
+	return roundPrice(value, 4)
+}
+
+// roundPrice 保留 minDecimals 位小数和 priceSignificantDigits 位有效数字中较多的一个
+func roundPrice(value float64, minDecimals int) float64 {
+	decimals := minDecimals
+	if abs := math.Abs(value); abs > 0 && !math.IsInf(abs, 0) {
+		if d := priceSignificantDigits - 1 - int(math.Floor(math.Log10(abs))); d > decimals {
+			decimals = d
+		}
+	}
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
 }
 
 // formatPercent 格式化百分比值（2位小数）
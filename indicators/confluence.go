@@ -0,0 +1,63 @@
+/*
+Package indicators 多指标共振评分
+
+主要功能：
+- CalculateConfluenceScore(klines []binance.Kline, direction string) float64  // 计算指定方向的共振评分（0-100）
+
+评分由5个条件组成，每满足一个得20分（以做多为例，做空反之）：
+1. 收盘价在EMA21之上
+2. EMA9在EMA21之上
+3. MACD柱状图为正
+4. RSI(14)在50~75之间（偏强但未超买）
+5. 收盘价在VWAP之上
+*/
+package indicators
+
+import (
+	"time"
+
+	"crypto-ai-trader/binance"
+
+	"github.com/markcheno/go-talib"
+)
+
+// 共振评分需要的最少K线数量（MACD慢线26 + 信号线9）
+const minConfluenceKlines = 35
+
+// CalculateConfluenceScore 计算指定方向的共振评分
+// klines: K线数据（至少35根）
+// direction: long 或 short
+// 返回：0-100，K线不足或方向无效时返回0
+func CalculateConfluenceScore(klines []binance.Kline, direction string) float64 {
+	defer trackFunc("confluence", time.Now())
+
+	if len(klines) < minConfluenceKlines || (direction != DirectionLong && direction != DirectionShort) {
+		return 0
+	}
+
+	// 使用原始值比较，避免低价币的指标被舍入
+	closes := extractCloses(klines)
+	last := closes[len(closes)-1]
+	ema9 := getLatestValue(talib.Ema(closes, 9))
+	ema21 := getLatestValue(talib.Ema(closes, 21))
+	_, _, hist := talib.Macd(closes, 12, 26, 9)
+	rsi := getLatestValue(talib.Rsi(closes, 14))
+	vwap := CalculateVWAP(klines)
+
+	long := direction == DirectionLong
+	conditions := []bool{
+		(last > ema21) == long,
+		(ema9 > ema21) == long,
+		(getLatestValue(hist) > 0) == long,
+		(long && rsi > 50 && rsi < 75) || (!long && rsi < 50 && rsi > 25),
+		vwap > 0 && (last > vwap) == long,
+	}
+
+	score := 0.0
+	for _, ok := range conditions {
+		if ok {
+			score += 20
+		}
+	}
+	return score
+}
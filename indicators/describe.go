@@ -0,0 +1,146 @@
+/*
+Package indicators 多周期趋势摘要（提示词中与原始JSON一起提供）
+
+主要功能：
+- Describe(data interface{}) string  // 按指标数据生成多周期趋势摘要（不支持的类型返回空字符串）
+
+摘要从大周期到小周期逐个描述：均线排列、相对EMA55的位置（ATR倍数）、回调/反弹至EMA21、近期EMA9/21交叉、
+RSI超买超卖、布林带收口、放量，最后是资金费率、持仓量与价格关系和波动率状态，例如：
+"4h 上涨趋势，在EMA55上方1.8ATR；1h 上涨趋势，回调至EMA21附近；15m 震荡，RSI 28 超卖；资金费率中性"。
+只由指标数值按固定规则生成（相同数据得到相同摘要），用于减少AI对原始数值的误读，不替代原始数据。
+*/
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// timeframeOrder 摘要中周期的顺序（从大到小）
+var timeframeOrder = []string{"1d", "4h", "1h", "15m", "5m", "1m"}
+
+// 摘要的判断阈值
+const (
+	describeNearEMA21ATR = 0.5   // 收盘价与EMA21的距离不超过该ATR倍数时为回调/反弹至EMA21
+	describeRecentCross  = 3     // EMA9/21交叉在最近几根K线内时描述
+	describeRSIHigh      = 70.0  // RSI超买
+	describeRSILow       = 30.0  // RSI超卖
+	describeVolumeSpike  = 2.0   // 成交量为平均的倍数以上时为放量
+	describeFundingHigh  = 0.03  // 资金费率(%)不低于该值为偏高
+	describeFundingLow   = -0.01 // 资金费率(%)不高于该值为负
+)
+
+// Describe 按指标数据生成多周期趋势摘要（不支持的类型返回空字符串）
+func Describe(data interface{}) string {
+	timeframes := Timeframes(data)
+	if len(timeframes) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for _, interval := range timeframeOrder {
+		if tf, ok := timeframes[interval]; ok {
+			parts = append(parts, interval+" "+describeTimeframe(tf))
+		}
+	}
+	if _, _, _, market := primary(data); market != nil {
+		parts = append(parts, describeMarket(market)...)
+	}
+	return strings.Join(parts, "；")
+}
+
+// describeTimeframe 描述一个周期
+func describeTimeframe(tf *TimeframeData) string {
+	order := trendOf(tf)
+	if tf.EMAState != nil {
+		order = tf.EMAState.Order
+	}
+	var items []string
+	switch order {
+	case TrendUp:
+		items = append(items, "上涨趋势")
+	case TrendDown:
+		items = append(items, "下跌趋势")
+	default:
+		items = append(items, "震荡")
+	}
+
+	if s := tf.EMAState; s != nil {
+		switch {
+		case s.EMA55DistATR > 0:
+			items = append(items, fmt.Sprintf("在EMA55上方%.1fATR", s.EMA55DistATR))
+		case s.EMA55DistATR < 0:
+			items = append(items, fmt.Sprintf("在EMA55下方%.1fATR", -s.EMA55DistATR))
+		}
+	}
+
+	if tf.ATR > 0 && tf.EMA21 > 0 && math.Abs(tf.ClosePrice-tf.EMA21) <= describeNearEMA21ATR*tf.ATR {
+		switch order {
+		case TrendUp:
+			items = append(items, "回调至EMA21附近")
+		case TrendDown:
+			items = append(items, "反弹至EMA21附近")
+		}
+	}
+
+	if s := tf.EMAState; s != nil && s.CrossBars != nil && *s.CrossBars <= describeRecentCross {
+		name := "金叉"
+		if s.Cross == CrossDead {
+			name = "死叉"
+		}
+		if *s.CrossBars == 0 {
+			items = append(items, "EMA9/21刚刚"+name)
+		} else {
+			items = append(items, fmt.Sprintf("EMA9/21在%d根K线前%s", *s.CrossBars, name))
+		}
+	}
+
+	switch {
+	case tf.RSI >= describeRSIHigh:
+		items = append(items, fmt.Sprintf("RSI %.0f 超买", tf.RSI))
+	case tf.RSI > 0 && tf.RSI <= describeRSILow:
+		items = append(items, fmt.Sprintf("RSI %.0f 超卖", tf.RSI))
+	}
+	if tf.BBSqueeze {
+		items = append(items, "布林带收口")
+	}
+	if tf.VolumeRatio != nil && *tf.VolumeRatio >= describeVolumeSpike {
+		items = append(items, fmt.Sprintf("放量%.1f倍", *tf.VolumeRatio))
+	}
+	return strings.Join(items, "，")
+}
+
+// describeMarket 描述资金费率、持仓量与价格关系和波动率状态
+func describeMarket(m *MarketData) []string {
+	var parts []string
+	switch {
+	case m.FundingRate >= describeFundingHigh:
+		parts = append(parts, fmt.Sprintf("资金费率%.4f%%（偏高，多头拥挤）", m.FundingRate))
+	case m.FundingRate <= describeFundingLow:
+		parts = append(parts, fmt.Sprintf("资金费率%.4f%%（为负，空头拥挤）", m.FundingRate))
+	default:
+		parts = append(parts, "资金费率中性")
+	}
+
+	switch m.OIPriceState {
+	case OIPriceLongBuildup:
+		parts = append(parts, "持仓量随价格上涨增加")
+	case OIPriceShortCovering:
+		parts = append(parts, "价格上涨但持仓量减少（空头平仓）")
+	case OIPriceShortBuildup:
+		parts = append(parts, "持仓量随价格下跌增加")
+	case OIPriceLongLiquidation:
+		parts = append(parts, "价格下跌且持仓量减少（多头平仓）")
+	}
+
+	switch m.VolatilityRegime {
+	case VolatilityQuiet:
+		parts = append(parts, "波动率处于低位")
+	case VolatilityElevated:
+		parts = append(parts, "波动率偏高")
+	case VolatilityExtreme:
+		parts = append(parts, "波动率极高")
+	}
+	return parts
+}
@@ -0,0 +1,256 @@
+/*
+Package indicators 增量指标（每根收盘K线O(1)更新，不重新计算整个序列）
+
+主要功能：
+- NewEMAUpdater(period int) *EMAUpdater                                   // 创建增量EMA
+- (u *EMAUpdater) Update(price float64) (float64, bool)                   // 加入一根收盘K线，返回最新EMA（数据不足时返回false）
+- NewRSIUpdater(period int) *RSIUpdater                                   // 创建增量RSI（Wilder平滑）
+- (u *RSIUpdater) Update(price float64) (float64, bool)                   // 加入一根收盘K线，返回最新RSI
+- NewATRUpdater(period int) *ATRUpdater                                   // 创建增量ATR（Wilder平滑）
+- (u *ATRUpdater) Update(high, low, price float64) (float64, bool)        // 加入一根收盘K线，返回最新ATR
+- NewLiveIndicators(emaPeriods []int, rsiPeriod, atrPeriod int) *LiveIndicators  // 一个序列的EMA/RSI/ATR组合
+- (l *LiveIndicators) Seed(klines []binance.Kline)                        // 用历史K线预热（只加入比已有数据新的K线）
+- (l *LiveIndicators) Add(openTime time.Time, high, low, price float64) bool  // 加入一根收盘K线（不晚于最近一根时忽略）
+- (l *LiveIndicators) Snapshot() LiveSnapshot                             // 当前的指标值
+
+初始值与 ta-lib（CalculateEMA/CalculateRSI/CalculateATR）一致：EMA以前period个收盘价的简单平均开始，
+RSI以前period个涨跌幅的平均开始，ATR以第2~period+1根K线真实波幅的平均开始，之后逐根平滑，
+用同一段K线预热后的结果与全量计算相同（未格式化）。不加锁，调用方负责并发保护。
+*/
+package indicators
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/binance"
+)
+
+// EMAUpdater 增量EMA
+type EMAUpdater struct {
+	period int
+	k      float64
+	count  int
+	sum    float64 // 前period个收盘价之和（初始值）
+	value  float64
+}
+
+// NewEMAUpdater 创建增量EMA（period<1时按1处理）
+func NewEMAUpdater(period int) *EMAUpdater {
+	period = max(period, 1)
+	return &EMAUpdater{period: period, k: 2 / float64(period+1)}
+}
+
+// Update 加入一根收盘K线的收盘价，返回最新EMA（不足period根时返回false）
+func (u *EMAUpdater) Update(price float64) (float64, bool) {
+	u.count++
+	switch {
+	case u.count < u.period:
+		u.sum += price
+		return 0, false
+	case u.count == u.period:
+		u.value = (u.sum + price) / float64(u.period)
+	default:
+		u.value += (price - u.value) * u.k
+	}
+	return u.value, true
+}
+
+// Value 最新EMA（不足period根时返回false）
+func (u *EMAUpdater) Value() (float64, bool) {
+	return u.value, u.count >= u.period
+}
+
+// RSIUpdater 增量RSI（Wilder平滑）
+type RSIUpdater struct {
+	period    int
+	count     int // 已加入的收盘价数
+	prevClose float64
+	avgGain   float64
+	avgLoss   float64
+}
+
+// NewRSIUpdater 创建增量RSI（period<2时按2处理）
+func NewRSIUpdater(period int) *RSIUpdater {
+	return &RSIUpdater{period: max(period, 2)}
+}
+
+// Update 加入一根收盘K线的收盘价，返回最新RSI（0-100，不足period+1根时返回false）
+func (u *RSIUpdater) Update(price float64) (float64, bool) {
+	u.count++
+	if u.count == 1 {
+		u.prevClose = price
+		return 0, false
+	}
+	change := price - u.prevClose
+	u.prevClose = price
+	gain, loss := max(change, 0), max(-change, 0)
+
+	n := float64(u.period)
+	switch {
+	case u.count <= u.period:
+		u.avgGain += gain
+		u.avgLoss += loss
+		return 0, false
+	case u.count == u.period+1:
+		u.avgGain = (u.avgGain + gain) / n
+		u.avgLoss = (u.avgLoss + loss) / n
+	default:
+		u.avgGain = (u.avgGain*(n-1) + gain) / n
+		u.avgLoss = (u.avgLoss*(n-1) + loss) / n
+	}
+	return u.rsi(), true
+}
+
+// rsi 按平均涨跌幅计算RSI（与ta-lib相同，涨跌幅都为0时为0）
+func (u *RSIUpdater) rsi() float64 {
+	total := u.avgGain + u.avgLoss
+	if math.Abs(total) < 1e-14 {
+		return 0
+	}
+	return 100 * u.avgGain / total
+}
+
+// Value 最新RSI（不足period+1根时返回false）
+func (u *RSIUpdater) Value() (float64, bool) {
+	if u.count <= u.period {
+		return 0, false
+	}
+	return u.rsi(), true
+}
+
+// ATRUpdater 增量ATR（Wilder平滑）
+type ATRUpdater struct {
+	period    int
+	count     int // 已加入的K线数
+	prevClose float64
+	value     float64
+}
+
+// NewATRUpdater 创建增量ATR（period<1时按1处理）
+func NewATRUpdater(period int) *ATRUpdater {
+	return &ATRUpdater{period: max(period, 1)}
+}
+
+// Update 加入一根收盘K线，返回最新ATR（第一根K线没有真实波幅，不足period+1根时返回false）
+func (u *ATRUpdater) Update(high, low, price float64) (float64, bool) {
+	u.count++
+	prevClose := u.prevClose
+	u.prevClose = price
+	if u.count == 1 {
+		return 0, false
+	}
+	tr := max(high, prevClose) - min(low, prevClose)
+
+	n := float64(u.period)
+	switch {
+	case u.count <= u.period:
+		u.value += tr
+		return 0, false
+	case u.count == u.period+1:
+		u.value = (u.value + tr) / n
+	default:
+		u.value = (u.value*(n-1) + tr) / n
+	}
+	return u.value, true
+}
+
+// Value 最新ATR（不足period+1根时返回false）
+func (u *ATRUpdater) Value() (float64, bool) {
+	return u.value, u.count > u.period
+}
+
+// LiveSnapshot 增量指标的当前值（未预热完成的指标为0）
+type LiveSnapshot struct {
+	Symbol     string          `json:"symbol,omitempty"`
+	Time       time.Time       `json:"time"`    // 最近一根收盘K线的开盘时间
+	Candles    int             `json:"candles"` // 已加入的K线数
+	Close      float64         `json:"close"`
+	EMA        map[int]float64 `json:"ema"` // 周期 -> EMA
+	RSI        float64         `json:"rsi"`
+	ATR        float64         `json:"atr"`
+	ATRPercent float64         `json:"atr_percent"` // ATR占收盘价的百分比
+	Ready      bool            `json:"ready"`       // 所有指标都已预热完成
+}
+
+// LiveIndicators 一个K线序列的增量EMA/RSI/ATR
+type LiveIndicators struct {
+	emaPeriods []int
+	ema        []*EMAUpdater
+	rsi        *RSIUpdater
+	atr        *ATRUpdater
+	last       time.Time // 最近一根K线的开盘时间
+	close      float64
+	candles    int
+}
+
+// NewLiveIndicators 创建增量指标组合（emaPeriods可以为空）
+func NewLiveIndicators(emaPeriods []int, rsiPeriod, atrPeriod int) *LiveIndicators {
+	l := &LiveIndicators{
+		emaPeriods: emaPeriods,
+		rsi:        NewRSIUpdater(rsiPeriod),
+		atr:        NewATRUpdater(atrPeriod),
+	}
+	for _, period := range emaPeriods {
+		l.ema = append(l.ema, NewEMAUpdater(period))
+	}
+	return l
+}
+
+// Seed 用历史K线预热（klines按时间从旧到新，只包含已收盘的K线；不晚于已有数据的K线被忽略）
+func (l *LiveIndicators) Seed(klines []binance.Kline) {
+	for _, k := range klines {
+		high, _ := strconv.ParseFloat(k.High, 64)
+		low, _ := strconv.ParseFloat(k.Low, 64)
+		price, _ := strconv.ParseFloat(k.Close, 64)
+		l.Add(time.UnixMilli(k.OpenTime), high, low, price)
+	}
+}
+
+// Add 加入一根收盘K线（开盘时间不晚于最近一根时忽略，返回是否加入）
+func (l *LiveIndicators) Add(openTime time.Time, high, low, price float64) bool {
+	if price <= 0 || !openTime.After(l.last) {
+		return false
+	}
+	for _, ema := range l.ema {
+		ema.Update(price)
+	}
+	l.rsi.Update(price)
+	l.atr.Update(high, low, price)
+	l.last = openTime
+	l.close = price
+	l.candles++
+	return true
+}
+
+// Last 最近一根K线的开盘时间（没有数据时为零值）
+func (l *LiveIndicators) Last() time.Time {
+	return l.last
+}
+
+// Snapshot 当前的指标值（按 formatPrice/formatPercent 格式化，与全量计算的输出一致）
+func (l *LiveIndicators) Snapshot() LiveSnapshot {
+	snapshot := LiveSnapshot{
+		Time:    l.last,
+		Candles: l.candles,
+		Close:   l.close,
+		EMA:     make(map[int]float64, len(l.ema)),
+		Ready:   true,
+	}
+	for i, ema := range l.ema {
+		value, ok := ema.Value()
+		snapshot.EMA[l.emaPeriods[i]] = formatPrice(value)
+		snapshot.Ready = snapshot.Ready && ok
+	}
+	rsi, ok := l.rsi.Value()
+	snapshot.RSI = formatPercent(rsi)
+	snapshot.Ready = snapshot.Ready && ok
+	atr, ok := l.atr.Value()
+	snapshot.ATR = formatPrice(atr)
+	if ok && l.close > 0 {
+		snapshot.ATRPercent = math.Round(atr/l.close*100*10000) / 10000
+	}
+	snapshot.Ready = snapshot.Ready && ok
+	return snapshot
+}
@@ -0,0 +1,64 @@
+/*
+Package indicators 指标数据类型标识（保存后按类型还原）
+
+主要功能：
+- Kind(data interface{}) string                       // 策略指标数据的类型标识（不支持的类型返回空字符串）
+- Decode(kind string, raw []byte) (interface{}, error)  // 按类型标识把JSON还原为指标结构
+
+审计记录只保存指标数据的JSON，回放时用新的提示词模板渲染需要还原为原来的结构（模板按字段名引用，如 .Timeframes.M15.RSI）。
+Decode 不检查数据结构版本，保存的旧版本数据先用 schemas.Decode 迁移到当前版本。
+*/
+package indicators
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion 指标数据结构版本（写入各指标结构的 schema_version 字段）
+// 新增、修改或删除字段时加1，并在 schemas 包中登记变更和从上一版本的迁移
+const SchemaVersion = 2
+
+// 指标数据类型标识
+const (
+	KindShortTerm = "short_term" // ShortTermIndicators
+	KindLongTerm  = "long_term"  // LongTermIndicators
+	KindScalp     = "scalp"      // ScalpIndicators
+	KindSwing     = "swing"      // SwingIndicators
+)
+
+// Kind 策略指标数据的类型标识（不支持的类型返回空字符串）
+func Kind(data interface{}) string {
+	switch data.(type) {
+	case *ShortTermIndicators:
+		return KindShortTerm
+	case *LongTermIndicators:
+		return KindLongTerm
+	case *ScalpIndicators:
+		return KindScalp
+	case *SwingIndicators:
+		return KindSwing
+	}
+	return ""
+}
+
+// Decode 按类型标识把JSON还原为指标结构（返回指针）
+func Decode(kind string, raw []byte) (interface{}, error) {
+	var data interface{}
+	switch kind {
+	case KindShortTerm:
+		data = &ShortTermIndicators{}
+	case KindLongTerm:
+		data = &LongTermIndicators{}
+	case KindScalp:
+		data = &ScalpIndicators{}
+	case KindSwing:
+		data = &SwingIndicators{}
+	default:
+		return nil, fmt.Errorf("不支持的指标数据类型: %q", kind)
+	}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, fmt.Errorf("解析指标数据失败: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,153 @@
+/*
+Package indicators 基于ATR的止损止盈计算
+
+主要功能：
+- CalculateLevels(entry float64, direction string, atr float64, params LevelParams, riskAmount float64) *TradeLevels  // 计算止损价、止盈价、R倍数和建议数量
+- CalculateSuggestedLevels(mainKlines, entryKlines []binance.Kline, timeframe string, atrPeriod int, params LevelParams) *SuggestedLevels  // 计算多空两个方向的建议价位（放入AI数据）
+
+止损距离 = ATR × stop_atr，止盈距离 = ATR × target_atr，R倍数 = 止盈距离 / 止损距离。
+建议数量 = 风险金额 / 止损距离，按止损价离场时亏损约为风险金额。
+AI数据中的建议价位与执行器按波动率计算仓位使用同一套公式，提示词和执行使用的价位一致。
+*/
+package indicators
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/binance"
+
+	"github.com/markcheno/go-talib"
+)
+
+// 交易方向
+const (
+	DirectionLong  = "long"
+	DirectionShort = "short"
+)
+
+// LevelParams 止损止盈的ATR倍数
+type LevelParams struct {
+	StopATR   float64 `json:"stop_atr"`   // 止损距离为ATR的倍数
+	TargetATR float64 `json:"target_atr"` // 止盈距离为ATR的倍数
+}
+
+// TradeLevels 单个方向的止损止盈价位
+type TradeLevels struct {
+	Direction   string  `json:"direction"`          // long 或 short
+	Entry       float64 `json:"entry"`              // 入场价
+	StopLoss    float64 `json:"stop_loss"`          // 止损价
+	TakeProfit  float64 `json:"take_profit"`        // 止盈价
+	RiskPerUnit float64 `json:"risk_per_unit"`      // 每单位标的的止损亏损（止损距离）
+	RMultiple   float64 `json:"r_multiple"`         // 盈亏比（止盈距离 / 止损距离）
+	Quantity    float64 `json:"quantity,omitempty"` // 按风险金额计算的建议数量（未给出风险金额时为0）
+}
+
+// SuggestedLevels AI数据中的建议价位（以入场周期收盘价为入场价，主分析周期ATR计算距离）
+type SuggestedLevels struct {
+	Timeframe string       `json:"timeframe"` // ATR所用的K线周期
+	ATR       float64      `json:"atr"`       // ATR值
+	ATRPct    float64      `json:"atr_pct"`   // ATR占价格的百分比
+	Params    LevelParams  `json:"params"`    // ATR倍数
+	Long      *TradeLevels `json:"long"`      // 做多价位
+	Short     *TradeLevels `json:"short"`     // 做空价位
+}
+
+// 各策略默认的ATR倍数
+var (
+	ShortTermLevelParams = LevelParams{StopATR: 1.5, TargetATR: 3.0}
+	LongTermLevelParams  = LevelParams{StopATR: 2.0, TargetATR: 4.0}
+	ScalpLevelParams     = LevelParams{StopATR: 1.0, TargetATR: 1.5}
+	SwingLevelParams     = LevelParams{StopATR: 2.0, TargetATR: 5.0}
+)
+
+// CalculateLevels 计算止损价、止盈价、R倍数和建议数量
+// entry: 入场价
+// direction: long 或 short
+// atr: ATR值（与价格同单位）
+// params: 止损、止盈的ATR倍数（TargetATR为0时不计算止盈）
+// riskAmount: 每笔风险金额（计价货币，0表示不计算建议数量）
+// 返回：价位数据，参数无效时返回nil
+func CalculateLevels(entry float64, direction string, atr float64, params LevelParams, riskAmount float64) *TradeLevels {
+	if entry <= 0 || atr <= 0 || params.StopATR <= 0 {
+		return nil
+	}
+	sign := 1.0
+	switch direction {
+	case DirectionLong:
+	case DirectionShort:
+		sign = -1
+	default:
+		return nil
+	}
+
+	stopDist := atr * params.StopATR
+	targetDist := atr * params.TargetATR
+	if sign > 0 && stopDist >= entry {
+		return nil // 做多止损价不能小于等于0
+	}
+
+	levels := &TradeLevels{
+		Direction:   direction,
+		Entry:       formatLevel(entry),
+		StopLoss:    formatLevel(entry - sign*stopDist),
+		RiskPerUnit: formatLevel(stopDist),
+	}
+	if tp := entry + sign*targetDist; targetDist > 0 && tp > 0 {
+		levels.TakeProfit = formatLevel(tp)
+		levels.RMultiple = math.Round(targetDist/stopDist*100) / 100
+	}
+	if riskAmount > 0 {
+		levels.Quantity = formatLevel(riskAmount / stopDist)
+	}
+	return levels
+}
+
+// CalculateSuggestedLevels 计算多空两个方向的建议价位
+// mainKlines: 主分析周期K线（计算ATR）
+// entryKlines: 入场周期K线（最新收盘价作为入场价）
+// timeframe: 主分析周期（如15m）
+// atrPeriod: ATR周期
+// params: 止损、止盈的ATR倍数
+// 返回：建议价位，K线不足时返回nil
+func CalculateSuggestedLevels(mainKlines, entryKlines []binance.Kline, timeframe string, atrPeriod int, params LevelParams) *SuggestedLevels {
+	atr := calculateATRRaw(mainKlines, atrPeriod)
+	if atr <= 0 || len(entryKlines) == 0 {
+		return nil
+	}
+	entry, _ := strconv.ParseFloat(entryKlines[len(entryKlines)-1].Close, 64)
+	if entry <= 0 {
+		return nil
+	}
+
+	return &SuggestedLevels{
+		Timeframe: timeframe,
+		ATR:       formatLevel(atr),
+		ATRPct:    math.Round(atr/entry*100*10000) / 10000,
+		Params:    params,
+		Long:      CalculateLevels(entry, DirectionLong, atr, params, 0),
+		Short:     CalculateLevels(entry, DirectionShort, atr, params, 0),
+	}
+}
+
+// calculateATRRaw 计算ATR（不做舍入，低价币的ATR不会被舍入为0）
+func calculateATRRaw(klines []binance.Kline, period int) float64 {
+	defer trackFunc("atr_raw", time.Now())
+
+	if period <= 0 || len(klines) < period+1 {
+		return 0
+	}
+	highs, lows, closes := extractHLC(klines)
+	atr := talib.Atr(highs, lows, closes, period)
+	return atr[len(atr)-1]
+}
+
+// formatLevel 按6位有效数字格式化价位（兼容高价币和低价币）
+func formatLevel(value float64) float64 {
+	if value == 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0
+	}
+	scale := math.Pow(10, 5-math.Floor(math.Log10(math.Abs(value))))
+	return math.Round(value*scale) / scale
+}
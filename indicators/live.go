@@ -0,0 +1,184 @@
+/*
+Package indicators 秒级增量指标（由标记价格推送生成的1分钟K线驱动）
+
+主要功能：
+- NewLiveTracker(stream *binance.MarkPriceStream, client *binance.Client, emaPeriods []int, rsiPeriod, atrPeriod int) *LiveTracker  // 创建秒级增量指标
+- (t *LiveTracker) Track(symbols []string)                   // 增加需要计算的交易对（已有的保持不变）
+- (t *LiveTracker) Run(ctx context.Context, interval time.Duration)  // 每interval加入新收盘的K线，直到ctx取消
+- (t *LiveTracker) Get(symbol string) (LiveSnapshot, bool)   // 交易对当前的指标值（未预热完成时返回false）
+- (t *LiveTracker) Snapshot() []LiveSnapshot                 // 所有交易对当前的指标值（按交易对排序）
+
+每个交易对第一次更新时用REST接口获取最近的1分钟K线预热（client为nil时只使用推送生成的K线，需要等待足够的K线），
+之后每次只加入推送新收盘的1分钟K线（O(1)），不再每个周期用100根K线重新计算，风控监控可以秒级读取最新的EMA/RSI/ATR。
+推送断开期间没有新K线，指标停留在最近一根；重连后只加入晚于最近一根的K线，中间缺失的分钟不补齐。
+预热使用成交价K线、之后使用标记价格K线，两者的差异通常很小。
+nil 的 *LiveTracker 可以安全调用，Get 始终返回false。
+*/
+package indicators
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// liveSeedLimit 预热时获取的1分钟K线数
+const liveSeedLimit = 100
+
+// LiveTracker 秒级增量指标
+type LiveTracker struct {
+	stream     *binance.MarkPriceStream
+	client     *binance.Client // 为nil时不预热
+	emaPeriods []int
+	rsiPeriod  int
+	atrPeriod  int
+
+	mu     sync.RWMutex
+	series map[string]*LiveIndicators // symbol -> 增量指标（未预热时为nil）
+}
+
+// NewLiveTracker 创建秒级增量指标（client用于预热，为nil时只使用推送生成的K线）
+func NewLiveTracker(stream *binance.MarkPriceStream, client *binance.Client, emaPeriods []int, rsiPeriod, atrPeriod int) *LiveTracker {
+	return &LiveTracker{
+		stream:     stream,
+		client:     client,
+		emaPeriods: emaPeriods,
+		rsiPeriod:  rsiPeriod,
+		atrPeriod:  atrPeriod,
+		series:     make(map[string]*LiveIndicators),
+	}
+}
+
+// Track 增加需要计算的交易对（已有的保持不变，多个账号分别调用取并集）
+func (t *LiveTracker) Track(symbols []string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, symbol := range symbols {
+		if _, ok := t.series[symbol]; !ok {
+			t.series[symbol] = nil
+		}
+	}
+}
+
+// Run 每interval加入各交易对新收盘的K线，直到ctx取消
+func (t *LiveTracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		t.update()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// update 加入各交易对新收盘的K线（未预热的交易对先预热）
+func (t *LiveTracker) update() {
+	t.mu.RLock()
+	var pending []string
+	for symbol, series := range t.series {
+		if series == nil {
+			pending = append(pending, symbol)
+		}
+	}
+	t.mu.RUnlock()
+
+	// 预热需要网络请求，不持有锁
+	sort.Strings(pending)
+	for _, symbol := range pending {
+		series := t.seed(symbol)
+		t.mu.Lock()
+		t.series[symbol] = series
+		t.mu.Unlock()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	for symbol, series := range t.series {
+		if series == nil {
+			continue // 预热期间新增的交易对，下次更新时预热
+		}
+		// 只取最近一根之后的K线（正常情况下2~3根），没有数据时取全部
+		n := 0
+		if last := series.Last(); !last.IsZero() {
+			n = int(now.Sub(last)/time.Minute) + 2
+		}
+		candles := t.stream.Candles(symbol, n)
+		// 最后一根是当前未走完的一分钟
+		for i := 0; i < len(candles)-1; i++ {
+			c := candles[i]
+			series.Add(c.Time, c.Value.High, c.Value.Low, c.Value.Close)
+		}
+	}
+}
+
+// seed 创建交易对的增量指标，用REST接口最近的1分钟K线预热（失败时只记录日志，等待推送的K线）
+func (t *LiveTracker) seed(symbol string) *LiveIndicators {
+	series := NewLiveIndicators(t.emaPeriods, t.rsiPeriod, t.atrPeriod)
+	if t.client == nil {
+		return series
+	}
+	klines, err := t.client.GetKlines(symbol, "1m", liveSeedLimit+1)
+	if err != nil {
+		utils.Warn("预热秒级指标失败，等待推送的K线", zap.String("symbol", symbol), zap.Error(err))
+		return series
+	}
+	// 最后一根是当前未收盘的K线
+	if len(klines) > 0 && klines[len(klines)-1].CloseTime >= time.Now().UnixMilli() {
+		klines = klines[:len(klines)-1]
+	}
+	series.Seed(klines)
+	utils.Info("秒级指标预热完成", zap.String("symbol", symbol), zap.Int("candles", len(klines)))
+	return series
+}
+
+// Get 交易对当前的指标值（未跟踪或未预热完成时返回false）
+func (t *LiveTracker) Get(symbol string) (LiveSnapshot, bool) {
+	if t == nil {
+		return LiveSnapshot{}, false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	series := t.series[symbol]
+	if series == nil {
+		return LiveSnapshot{}, false
+	}
+	snapshot := series.Snapshot()
+	snapshot.Symbol = symbol
+	return snapshot, snapshot.Ready
+}
+
+// Snapshot 所有交易对当前的指标值（含未预热完成的，按交易对排序）
+func (t *LiveTracker) Snapshot() []LiveSnapshot {
+	if t == nil {
+		return nil
+	}
+	t.mu.RLock()
+	snapshots := make([]LiveSnapshot, 0, len(t.series))
+	for symbol, series := range t.series {
+		snapshot := LiveSnapshot{Symbol: symbol}
+		if series != nil {
+			snapshot = series.Snapshot()
+			snapshot.Symbol = symbol
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	t.mu.RUnlock()
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Symbol < snapshots[j].Symbol })
+	return snapshots
+}
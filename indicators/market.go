@@ -2,15 +2,30 @@
 Package indicators 市场数据指标计算
 
 主要功能：
-- CalculateOIMetrics(client *binance.Client, symbol string, currentPrice float64) *OIMetrics  // 计算持仓量指标
-- CalculateFundingMetrics(client *binance.Client, symbol string) *FundingMetrics              // 计算资金费率指标
+- CalculateOIMetrics(market exchange.MarketData, symbol string, currentPrice float64) *OIMetrics  // 计算持仓量指标
+- CalculateFundingMetrics(market exchange.MarketData, symbol string) *FundingMetrics              // 计算资金费率指标
+- FundingFlip(current, last, avg3 float64) string                                                // 资金费率翻转方向（未翻转时为空）
+- ClassifyOIAndPrice(priceChange, oiChange float64) string                                       // 持仓量与价格关系的分类（AnalyzeOIAndPrice 的状态代码）
+- ApplyOIPriceState(marketData *MarketData, tf *TimeframeData)                                     // 按主分析周期涨跌幅和持仓量变化率设置市场数据的 oi_price_state
+
+资金费率翻转：当前（预测）资金费率与最近一次已结算费率异号（穿越0），或与最近3次平均异号，
+市场数据的 funding_flip 标记翻转后的方向。资金费率翻转常常预示轧空/轧多，与30-90分钟的持仓周期相关。
+
+持仓量与价格关系：主分析周期当前K线的涨跌幅与持仓量最近的变化率（优先15分钟，否则5分钟）同向为真实趋势，
+反向为平仓推动（可能反转），每个周期写入市场数据的 oi_price_state；没有持仓量历史时不设置。
+
+波动率分位：1小时ATR%在交易对自身30天分布中的百分位和波动率状态（计算见volatility.go），
+策略和AI可以区分"对BTC来说平静"和"对小币种来说极端"，而不是只看原始ATR。
+
+行情接口同时实现 exchange.LiquidationData 时，市场数据附加各滚动窗口的强平统计和连环强平标记。
 */
 package indicators
 
 import (
-	"crypto-ai-trader/binance"
+	"math"
+
+	"crypto-ai-trader/exchange"
 	"crypto-ai-trader/utils"
-	"strconv"
 
 	"go.uber.org/zap"
 )
@@ -24,8 +39,16 @@ type OIMetrics struct {
 type FundingMetrics struct {
 	Current float64 // 当前资金费率(%)
 	Avg3    float64 // 最近3次平均(%)
+	Last    float64 // 最近一次已结算资金费率(%)
+	Flip    string  // 资金费率翻转方向（未翻转时为空）
 }
 
+// 资金费率翻转方向
+const (
+	FundingFlipToPositive = "to_positive" // 由负转正（空头开始支付资金费）
+	FundingFlipToNegative = "to_negative" // 由正转负（多头开始收取资金费）
+)
+
 // OICache 持仓量缓存（用于计算变化率）
 type OICache struct {
 	Symbol    string    // 交易对
@@ -34,20 +57,25 @@ type OICache struct {
 }
 
 // CalculateMarketData 计算市场数据（OI + 资金费率）
-// client: 币安客户端
+// market: 交易所行情接口
 // symbol: 交易对
 // currentPrice: 当前价格
 // oiCache: OI缓存（可选，用于计算变化率）
 // 返回：市场数据
-func CalculateMarketData(client *binance.Client, symbol string, currentPrice float64, oiCache *OICache) *MarketData {
+func CalculateMarketData(market exchange.MarketData, symbol string, currentPrice float64, oiCache *OICache) *MarketData {
+	// 现货没有持仓量和资金费率
+	if !market.HasDerivativesData() {
+		return nil
+	}
+
 	// 获取当前OI
-	oiMetrics := CalculateOIMetrics(client, symbol, currentPrice)
+	oiMetrics := CalculateOIMetrics(market, symbol, currentPrice)
 	if oiMetrics == nil {
 		return nil
 	}
 
 	// 获取资金费率
-	fundingMetrics := CalculateFundingMetrics(client, symbol)
+	fundingMetrics := CalculateFundingMetrics(market, symbol)
 	if fundingMetrics == nil {
 		return nil
 	}
@@ -56,6 +84,7 @@ func CalculateMarketData(client *binance.Client, symbol string, currentPrice flo
 		OICurrent:   formatPrice(oiMetrics.Current / 1000000), // 转换为百万美元
 		FundingRate: fundingMetrics.Current,
 		FundingAvg3: fundingMetrics.Avg3,
+		FundingFlip: fundingMetrics.Flip,
 	}
 
 	// 如果有缓存，计算OI变化率
@@ -79,29 +108,40 @@ func CalculateMarketData(client *binance.Client, symbol string, currentPrice flo
 		}
 	}
 
+	// 波动率分位（1小时K线，按交易对缓存一小时）
+	ApplyVolatilityRegime(marketData, market, symbol)
+
+	// 强平统计（行情接口提供时）
+	if source, ok := market.(exchange.LiquidationData); ok {
+		if windows, cascade, err := source.GetLiquidations(symbol); err == nil {
+			for _, w := range windows {
+				marketData.Liquidations = append(marketData.Liquidations, LiquidationWindow{
+					WindowMinutes: w.Minutes,
+					LongUSDT:      math.Round(w.LongUSDT),
+					ShortUSDT:     math.Round(w.ShortUSDT),
+					Count:         w.Count,
+				})
+			}
+			marketData.LiquidationCascade = cascade
+		}
+	}
+
 	return marketData
 }
 
 // CalculateOIMetrics 计算持仓量指标
-// client: 币安客户端
+// market: 交易所行情接口
 // symbol: 交易对
 // currentPrice: 当前价格（用于计算USDT价值）
 // 返回：持仓量指标数据
-func CalculateOIMetrics(client *binance.Client, symbol string, currentPrice float64) *OIMetrics {
-	// 获取当前持仓量
-	oi, err := client.GetOpenInterest(symbol)
+func CalculateOIMetrics(market exchange.MarketData, symbol string, currentPrice float64) *OIMetrics {
+	// 获取当前持仓量（标的数量）
+	oiValue, err := market.GetOpenInterest(symbol)
 	if err != nil {
 		utils.Error("获取持仓量失败", zap.Error(err))
 		return nil
 	}
 
-	// 解析持仓量（张数）
-	oiValue, err := strconv.ParseFloat(oi.OpenInterest, 64)
-	if err != nil {
-		utils.Error("解析持仓量失败", zap.Error(err))
-		return nil
-	}
-
 	// 计算USDT价值（持仓量 * 当前价格）
 	currentOIValue := oiValue * currentPrice
 
@@ -118,25 +158,19 @@ func CalculateOIMetrics(client *binance.Client, symbol string, currentPrice floa
 }
 
 // CalculateFundingMetrics 计算资金费率指标
-// client: 币安客户端
+// market: 交易所行情接口
 // symbol: 交易对
 // 返回：资金费率指标数据
-func CalculateFundingMetrics(client *binance.Client, symbol string) *FundingMetrics {
+func CalculateFundingMetrics(market exchange.MarketData, symbol string) *FundingMetrics {
 	// 获取当前资金费率
-	premium, err := client.GetPremiumIndex(symbol)
+	currentRate, err := market.GetFundingRate(symbol)
 	if err != nil {
 		utils.Error("获取当前资金费率失败", zap.Error(err))
 		return nil
 	}
 
-	currentRate, err := strconv.ParseFloat(premium.LastFundingRate, 64)
-	if err != nil {
-		utils.Error("解析当前资金费率失败", zap.Error(err))
-		return nil
-	}
-
 	// 获取最近3次资金费率历史
-	fundingRates, err := client.GetFundingRateHistory(symbol, 3)
+	fundingRates, err := market.GetFundingRateHistory(symbol, 3)
 	if err != nil {
 		utils.Error("获取资金费率历史失败", zap.Error(err))
 		return &FundingMetrics{
@@ -148,12 +182,9 @@ func CalculateFundingMetrics(client *binance.Client, symbol string) *FundingMetr
 	// 计算最近3次平均
 	sum := 0.0
 	count := 0
-	for _, fr := range fundingRates {
-		rate, err := strconv.ParseFloat(fr.FundingRate, 64)
-		if err == nil {
-			sum += rate
-			count++
-		}
+	for _, rate := range fundingRates {
+		sum += rate
+		count++
 	}
 
 	avg3 := 0.0
@@ -165,16 +196,35 @@ func CalculateFundingMetrics(client *binance.Client, symbol string) *FundingMetr
 		Current: formatPercent(currentRate * 100),
 		Avg3:    formatPercent(avg3 * 100),
 	}
+	// 历史从旧到新，最后一条是最近一次结算
+	if count > 0 {
+		metrics.Last = formatPercent(fundingRates[count-1] * 100)
+		metrics.Flip = FundingFlip(metrics.Current, metrics.Last, metrics.Avg3)
+	}
 
 	utils.Debug("资金费率指标计算完成",
 		zap.String("symbol", symbol),
 		zap.Float64("current", metrics.Current),
 		zap.Float64("avg3", metrics.Avg3),
+		zap.String("flip", metrics.Flip),
 	)
 
 	return metrics
 }
 
+// FundingFlip 资金费率翻转方向（当前费率与最近一次已结算费率或最近3次平均异号时，返回当前费率的方向）
+// current为0时不算翻转：费率回到0只是中性，还没有转向另一侧
+func FundingFlip(current, last, avg3 float64) string {
+	switch {
+	case current > 0 && (last < 0 || avg3 < 0):
+		return FundingFlipToPositive
+	case current < 0 && (last > 0 || avg3 > 0):
+		return FundingFlipToNegative
+	default:
+		return ""
+	}
+}
+
 // CalculateOIChangeWithHistory 计算持仓量变化率（需要历史数据）
 // currentOI: 当前持仓量
 // historicalOI: 历史持仓量数据（按时间倒序）
@@ -249,24 +299,71 @@ func ShouldTradeBasedOnFunding(fundingRate float64, direction string) (bool, str
 	return false, "未知交易方向"
 }
 
+// 持仓量与价格关系（ClassifyOIAndPrice 的返回值）
+const (
+	OIPriceLongBuildup     = "long_buildup"     // 价格上涨+OI增加：真实多头趋势
+	OIPriceShortCovering   = "short_covering"   // 价格上涨+OI减少：空头平仓推动
+	OIPriceShortBuildup    = "short_buildup"    // 价格下跌+OI增加：真实空头趋势
+	OIPriceLongLiquidation = "long_liquidation" // 价格下跌+OI减少：多头平仓推动
+	OIPriceNeutral         = "neutral"          // 价格或OI无明显变化
+)
+
+// oiPriceDescriptions 持仓量与价格关系的说明
+var oiPriceDescriptions = map[string]string{
+	OIPriceLongBuildup:     "价格上涨+OI增加：真实多头趋势，新资金进场",
+	OIPriceShortCovering:   "价格上涨+OI减少：空头平仓推动，可能反转",
+	OIPriceShortBuildup:    "价格下跌+OI增加：真实空头趋势，新空单进场",
+	OIPriceLongLiquidation: "价格下跌+OI减少：多头平仓推动，可能反转",
+	OIPriceNeutral:         "价格和OI无明显变化，市场震荡",
+}
+
+// ClassifyOIAndPrice 持仓量与价格关系的分类
+// priceChange: 价格变化率(%)
+// oiChange: 持仓量变化率(%)
+// 返回：OIPriceLongBuildup 等状态代码
+func ClassifyOIAndPrice(priceChange, oiChange float64) string {
+	switch {
+	case priceChange > 0 && oiChange > 0:
+		return OIPriceLongBuildup
+	case priceChange > 0 && oiChange < 0:
+		return OIPriceShortCovering
+	case priceChange < 0 && oiChange > 0:
+		return OIPriceShortBuildup
+	case priceChange < 0 && oiChange < 0:
+		return OIPriceLongLiquidation
+	default:
+		return OIPriceNeutral
+	}
+}
+
 // AnalyzeOIAndPrice 分析持仓量和价格的关系
 // priceChange: 价格变化率(%)
 // oiChange: 持仓量变化率(%)
 // 返回：市场状态描述
 func AnalyzeOIAndPrice(priceChange, oiChange float64) string {
-	if priceChange > 0 && oiChange > 0 {
-		return "价格上涨+OI增加：真实多头趋势，新资金进场"
-	}
-	if priceChange > 0 && oiChange < 0 {
-		return "价格上涨+OI减少：空头平仓推动，可能反转"
+	return OIPriceDescription(ClassifyOIAndPrice(priceChange, oiChange))
+}
+
+// OIPriceDescription 持仓量与价格关系的说明（未知状态返回空）
+func OIPriceDescription(state string) string {
+	return oiPriceDescriptions[state]
+}
+
+// ApplyOIPriceState 按主分析周期当前K线涨跌幅和持仓量变化率（优先15分钟，否则5分钟）设置 oi_price_state
+// 没有持仓量变化率（OI缓存不足）或没有主分析周期数据时不设置
+func ApplyOIPriceState(marketData *MarketData, tf *TimeframeData) {
+	if marketData == nil || tf == nil || tf.OpenPrice <= 0 {
+		return
 	}
-	if priceChange < 0 && oiChange > 0 {
-		return "价格下跌+OI增加：真实空头趋势，新空单进场"
+	oiChange := marketData.OIChange15m
+	if oiChange == nil {
+		oiChange = marketData.OIChange5m
 	}
-	if priceChange < 0 && oiChange < 0 {
-		return "价格下跌+OI减少：多头平仓推动，可能反转"
+	if oiChange == nil {
+		return
 	}
-	return "价格和OI无明显变化，市场震荡"
+	priceChange := (tf.ClosePrice - tf.OpenPrice) / tf.OpenPrice * 100
+	marketData.OIPriceState = ClassifyOIAndPrice(priceChange, *oiChange)
 }
 
 // calculateOIChangeRate 计算OI变化率
@@ -284,6 +381,7 @@ func calculateOIChangeRate(current, previous float64) float64 {
 // timestamp: 时间戳
 // maxSize: 最大缓存数量（建议5个）
 // 返回：更新后的缓存
+// 每次更新都重新分配切片，持续更新的缓存请使用 utils.OICacheManager（固定容量的环形缓冲）
 func UpdateOICache(cache *OICache, newOI float64, timestamp int64, maxSize int) *OICache {
 	if cache == nil {
 		cache = &OICache{
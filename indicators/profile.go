@@ -0,0 +1,245 @@
+/*
+Package indicators 多周期策略指标配置（趋势周期 → 主分析周期 → 入场周期）
+
+主要功能：
+- ProfileOf(kind string) *Profile                                                                  // 按指标类型标识获取配置（不存在时返回nil）
+- (p *Profile) Calculate(symbol string, klines map[string][]binance.Kline) interface{}            // 计算指标（K线不足时返回nil）
+- (p *Profile) CalculateWithMarket(symbol string, klines map[string][]binance.Kline, market exchange.MarketData, oiCache *OICache) interface{}  // 计算指标（包含市场数据）
+- CalculateShortTermIndicators(symbol string, klines1h, klines15m, klines5m []binance.Kline) *ShortTermIndicators  // 计算短线策略指标
+- CalculateShortTermIndicatorsWithMarket(symbol string, klines1h, klines15m, klines5m []binance.Kline, market exchange.MarketData, oiCache *OICache) *ShortTermIndicators  // 计算短线策略指标（包含市场数据）
+- CalculateLongTermIndicators(symbol string, klines4h, klines1h, klines15m []binance.Kline) *LongTermIndicators    // 计算中长线策略指标
+
+短线、中长线、剥头皮、波段策略的计算流程相同，只有周期、指标参数和建议价位的ATR倍数不同，
+新增同类策略只需增加一个 Profile 并在 profiles 中登记。输出结构按策略区分（JSON字段和模板字段名不变）。
+*/
+package indicators
+
+import (
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// Profile 多周期策略的指标配置
+type Profile struct {
+	Kind      string          // 指标类型标识（与策略注册名称相同，提示词模板按 <策略>_<提示词类型> 查找）
+	Label     string          // 策略名称（中文，用于日志）
+	Intervals [3]string       // K线周期：趋势周期、主分析周期、入场周期
+	MinKlines int             // 每个周期至少需要的K线根数
+	Params    IndicatorParams // 指标参数
+	Levels    LevelParams     // 建议价位的ATR倍数
+
+	build func(f *profileFrames) interface{} // 组装为该策略的指标结构
+}
+
+// profileFrames 按配置计算出的指标（组装为各策略的指标结构）
+type profileFrames struct {
+	symbol     string
+	timestamp  int64
+	params     IndicatorParams
+	market     *MarketData
+	timeframes [3]*TimeframeData
+	levels     *SuggestedLevels
+}
+
+// 各策略的指标配置
+var (
+	ShortTermProfile = &Profile{
+		Kind:      KindShortTerm,
+		Label:     "短线",
+		Intervals: [3]string{"1h", "15m", "5m"},
+		MinKlines: 55,
+		Params:    DefaultIndicatorParams(),
+		Levels:    ShortTermLevelParams,
+		build: func(f *profileFrames) interface{} {
+			return &ShortTermIndicators{
+				SchemaVersion: SchemaVersion,
+				Symbol:        f.symbol,
+				Timestamp:     f.timestamp,
+				MarketData:    f.market,
+				Timeframes:    &ShortTermTimeframes{H1: f.timeframes[0], M15: f.timeframes[1], M5: f.timeframes[2]},
+				Levels:        f.levels,
+			}
+		},
+	}
+	LongTermProfile = &Profile{
+		Kind:      KindLongTerm,
+		Label:     "中长线",
+		Intervals: [3]string{"4h", "1h", "15m"},
+		MinKlines: 55,
+		Params:    DefaultIndicatorParams(),
+		Levels:    LongTermLevelParams,
+		build: func(f *profileFrames) interface{} {
+			return &LongTermIndicators{
+				SchemaVersion: SchemaVersion,
+				Symbol:        f.symbol,
+				Timestamp:     f.timestamp,
+				MarketData:    f.market,
+				Timeframes:    &LongTermTimeframes{H4: f.timeframes[0], H1: f.timeframes[1], M15: f.timeframes[2]},
+				Levels:        f.levels,
+			}
+		},
+	}
+	ScalpProfile = &Profile{
+		Kind:      KindScalp,
+		Label:     "剥头皮",
+		Intervals: [3]string{"15m", "5m", "1m"},
+		MinKlines: 55,
+		Params:    ScalpIndicatorParams(),
+		Levels:    ScalpLevelParams,
+		build: func(f *profileFrames) interface{} {
+			return &ScalpIndicators{
+				SchemaVersion: SchemaVersion,
+				Symbol:        f.symbol,
+				Timestamp:     f.timestamp,
+				MarketData:    f.market,
+				Params:        &f.params,
+				Timeframes:    &ScalpTimeframes{M15: f.timeframes[0], M5: f.timeframes[1], M1: f.timeframes[2]},
+				Levels:        f.levels,
+			}
+		},
+	}
+	SwingProfile = &Profile{
+		Kind:      KindSwing,
+		Label:     "波段",
+		Intervals: [3]string{"1d", "4h", "1h"},
+		MinKlines: 55,
+		Params:    SwingIndicatorParams(),
+		Levels:    SwingLevelParams,
+		build: func(f *profileFrames) interface{} {
+			return &SwingIndicators{
+				SchemaVersion: SchemaVersion,
+				Symbol:        f.symbol,
+				Timestamp:     f.timestamp,
+				MarketData:    f.market,
+				Params:        &f.params,
+				Timeframes:    &SwingTimeframes{D1: f.timeframes[0], H4: f.timeframes[1], H1: f.timeframes[2]},
+				Levels:        f.levels,
+			}
+		},
+	}
+)
+
+// profiles 按指标类型标识登记的配置
+var profiles = map[string]*Profile{
+	KindShortTerm: ShortTermProfile,
+	KindLongTerm:  LongTermProfile,
+	KindScalp:     ScalpProfile,
+	KindSwing:     SwingProfile,
+}
+
+// ProfileOf 按指标类型标识获取配置（不存在时返回nil）
+func ProfileOf(kind string) *Profile {
+	return profiles[kind]
+}
+
+// Calculate 计算指标
+// klines: K线周期 -> K线数据（每个周期建议100根以上）
+// 返回：该策略的指标结构（如 *ShortTermIndicators），K线不足时返回nil
+func (p *Profile) Calculate(symbol string, klines map[string][]binance.Kline) interface{} {
+	f := p.calculate(symbol, klines)
+	if f == nil {
+		return nil
+	}
+	return p.build(f)
+}
+
+// CalculateWithMarket 计算指标（包含市场数据）
+// market: 交易所行情接口（用于获取OI和资金费率）
+// oiCache: OI缓存（用于计算变化率）
+// 返回：该策略的指标结构（包含OI和资金费率），K线不足时返回nil
+func (p *Profile) CalculateWithMarket(symbol string, klines map[string][]binance.Kline, market exchange.MarketData, oiCache *OICache) interface{} {
+	f := p.calculate(symbol, klines)
+	if f == nil {
+		return nil
+	}
+
+	// 以入场周期收盘价作为当前价格
+	marketData := CalculateMarketData(market, symbol, f.timeframes[2].ClosePrice, oiCache)
+	if marketData == nil {
+		return p.build(f)
+	}
+	f.market = marketData
+	ApplyOIPriceState(marketData, f.timeframes[1])
+
+	utils.Info(p.Label+"策略指标计算完成（含市场数据）",
+		zap.String("symbol", symbol),
+		zap.Float64("oi_current", marketData.OICurrent),
+		zap.Float64("funding_rate", marketData.FundingRate),
+	)
+
+	return p.build(f)
+}
+
+// calculate 计算各周期指标和建议价位（K线不足时返回nil）
+func (p *Profile) calculate(symbol string, klines map[string][]binance.Kline) *profileFrames {
+	defer trackSymbol(p.Kind, symbol, time.Now())
+
+	counts := make([]zap.Field, 0, len(p.Intervals))
+	enough := true
+	for _, interval := range p.Intervals {
+		counts = append(counts, zap.Int(interval, len(klines[interval])))
+		if len(klines[interval]) < p.MinKlines {
+			enough = false
+		}
+	}
+	utils.Debug("计算"+p.Label+"策略指标", append([]zap.Field{zap.String("symbol", symbol)}, counts...)...)
+
+	// 验证数据充足性
+	if !enough {
+		utils.Error("K线数据不足，无法计算指标", counts...)
+		return nil
+	}
+
+	f := &profileFrames{
+		symbol:    symbol,
+		timestamp: time.Now().Unix(),
+		params:    p.Params,
+	}
+	for i, interval := range p.Intervals {
+		f.timeframes[i] = calculateTimeframeDataWithParams(klines[interval], interval, p.Params)
+	}
+	// 主分析周期ATR、入场周期收盘价
+	f.levels = CalculateSuggestedLevels(klines[p.Intervals[1]], klines[p.Intervals[2]], p.Intervals[1], p.Params.ATRPeriod, p.Levels)
+
+	utils.Info(p.Label+"策略指标计算完成",
+		zap.String("symbol", symbol),
+		zap.Float64(p.Intervals[0]+"_close", f.timeframes[0].ClosePrice),
+		zap.Float64(p.Intervals[1]+"_close", f.timeframes[1].ClosePrice),
+		zap.Float64(p.Intervals[2]+"_close", f.timeframes[2].ClosePrice),
+	)
+
+	return f
+}
+
+// CalculateShortTermIndicators 计算短线策略指标
+// symbol: 交易对（如BTCUSDT）
+// klines1h: 1小时K线数据（建议100根以上）
+// klines15m: 15分钟K线数据（建议100根以上）
+// klines5m: 5分钟K线数据（建议100根以上）
+// 返回：短线策略指标数据
+func CalculateShortTermIndicators(symbol string, klines1h, klines15m, klines5m []binance.Kline) *ShortTermIndicators {
+	data, _ := ShortTermProfile.Calculate(symbol, map[string][]binance.Kline{"1h": klines1h, "15m": klines15m, "5m": klines5m}).(*ShortTermIndicators)
+	return data
+}
+
+// CalculateShortTermIndicatorsWithMarket 计算短线策略指标（包含市场数据）
+func CalculateShortTermIndicatorsWithMarket(symbol string, klines1h, klines15m, klines5m []binance.Kline, market exchange.MarketData, oiCache *OICache) *ShortTermIndicators {
+	data, _ := ShortTermProfile.CalculateWithMarket(symbol, map[string][]binance.Kline{"1h": klines1h, "15m": klines15m, "5m": klines5m}, market, oiCache).(*ShortTermIndicators)
+	return data
+}
+
+// CalculateLongTermIndicators 计算中长线策略指标
+// symbol: 交易对（如BTCUSDT）
+// klines4h: 4小时K线数据（建议100根以上）
+// klines1h: 1小时K线数据（建议100根以上）
+// klines15m: 15分钟K线数据（建议100根以上）
+// 返回：中长线策略指标数据
+func CalculateLongTermIndicators(symbol string, klines4h, klines1h, klines15m []binance.Kline) *LongTermIndicators {
+	data, _ := LongTermProfile.Calculate(symbol, map[string][]binance.Kline{"4h": klines4h, "1h": klines1h, "15m": klines15m}).(*LongTermIndicators)
+	return data
+}
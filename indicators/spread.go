@@ -0,0 +1,166 @@
+/*
+Package indicators 配对交易价差计算
+
+主要功能：
+- CalculateSpread(symbolA, symbolB string, klinesA, klinesB []binance.Kline, lookback int) *SpreadData  // 计算两个交易对的价差和Z分数
+
+价差使用对数价格：spread = ln(A) - β × ln(B)，β为回看窗口内 ln(A) 对 ln(B) 的OLS回归系数，
+使多空两腿按名义价值 1 : β 配比时对共同波动近似中性。两组K线按开盘时间对齐，只使用双方都有的K线。
+*/
+package indicators
+
+import (
+	"math"
+	"strconv"
+
+	"crypto-ai-trader/binance"
+)
+
+// CalculateSpread 计算两个交易对的价差和Z分数
+// lookback: 回看K线数量（对齐后不足时使用全部，少于20根返回nil）
+func CalculateSpread(symbolA, symbolB string, klinesA, klinesB []binance.Kline, lookback int) *SpreadData {
+	logA, logB := alignLogCloses(klinesA, klinesB)
+	if lookback > 0 && len(logA) > lookback {
+		logA = logA[len(logA)-lookback:]
+		logB = logB[len(logB)-lookback:]
+	}
+	n := len(logA)
+	if n < 20 {
+		return nil
+	}
+
+	beta := olsSlope(logB, logA)
+	spreads := make([]float64, n)
+	for i := range logA {
+		spreads[i] = logA[i] - beta*logB[i]
+	}
+	mean, std := meanStd(spreads)
+
+	latest := spreads[n-1]
+	z := 0.0
+	if std > 0 {
+		z = (latest - mean) / std
+	}
+
+	priceA := math.Exp(logA[n-1])
+	priceB := math.Exp(logB[n-1])
+
+	return &SpreadData{
+		SymbolA:     symbolA,
+		SymbolB:     symbolB,
+		PriceA:      priceA,
+		PriceB:      priceB,
+		Ratio:       priceA / priceB,
+		HedgeRatio:  math.Round(beta*10000) / 10000,
+		Spread:      latest,
+		Mean:        mean,
+		StdDev:      std,
+		ZScore:      formatPercent(z),
+		Correlation: formatMACD(returnsCorrelation(logA, logB)),
+		HalfLife:    formatPercent(halfLife(spreads)),
+		Bars:        n,
+	}
+}
+
+// alignLogCloses 按开盘时间对齐两组K线，返回对数收盘价
+func alignLogCloses(klinesA, klinesB []binance.Kline) ([]float64, []float64) {
+	closesB := make(map[int64]float64, len(klinesB))
+	for _, k := range klinesB {
+		if c, err := strconv.ParseFloat(k.Close, 64); err == nil && c > 0 {
+			closesB[k.OpenTime] = c
+		}
+	}
+
+	logA := make([]float64, 0, len(klinesA))
+	logB := make([]float64, 0, len(klinesA))
+	for _, k := range klinesA {
+		cb, ok := closesB[k.OpenTime]
+		if !ok {
+			continue
+		}
+		ca, err := strconv.ParseFloat(k.Close, 64)
+		if err != nil || ca <= 0 {
+			continue
+		}
+		logA = append(logA, math.Log(ca))
+		logB = append(logB, math.Log(cb))
+	}
+	return logA, logB
+}
+
+// olsSlope y对x的最小二乘回归斜率（x无波动时返回1）
+func olsSlope(x, y []float64) float64 {
+	mx, _ := meanStd(x)
+	my, _ := meanStd(y)
+	cov, varX := 0.0, 0.0
+	for i := range x {
+		cov += (x[i] - mx) * (y[i] - my)
+		varX += (x[i] - mx) * (x[i] - mx)
+	}
+	if varX == 0 {
+		return 1
+	}
+	return cov / varX
+}
+
+// meanStd 均值和总体标准差
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// returnsCorrelation 两组对数价格的收益率相关系数
+func returnsCorrelation(logA, logB []float64) float64 {
+	n := len(logA) - 1
+	if n < 2 {
+		return 0
+	}
+	retA := make([]float64, n)
+	retB := make([]float64, n)
+	for i := 0; i < n; i++ {
+		retA[i] = logA[i+1] - logA[i]
+		retB[i] = logB[i+1] - logB[i]
+	}
+
+	ma, sa := meanStd(retA)
+	mb, sb := meanStd(retB)
+	if sa == 0 || sb == 0 {
+		return 0
+	}
+	cov := 0.0
+	for i := range retA {
+		cov += (retA[i] - ma) * (retB[i] - mb)
+	}
+	return cov / float64(n) / (sa * sb)
+}
+
+// halfLife 价差均值回归半衰期（Δspread 对 spread 回归，λ<0时半衰期 = -ln2/λ）
+func halfLife(spreads []float64) float64 {
+	n := len(spreads) - 1
+	if n < 2 {
+		return 0
+	}
+	lagged := spreads[:n]
+	delta := make([]float64, n)
+	for i := 0; i < n; i++ {
+		delta[i] = spreads[i+1] - spreads[i]
+	}
+
+	lambda := olsSlope(lagged, delta)
+	if lambda >= 0 {
+		return 0
+	}
+	return -math.Ln2 / lambda
+}
@@ -0,0 +1,163 @@
+/*
+Package indicators 关键指标摘要（多交易对排名表）
+
+主要功能：
+- Summarize(data interface{}) *KeyMetrics                                   // 从策略指标数据中提取主分析周期的关键指标（不支持的类型返回nil）
+- PrimaryTimeframe(data interface{}) (string, *TimeframeData, *MarketData)  // 策略指标数据的主分析周期、该周期指标和市场数据
+- Timeframes(data interface{}) map[string]*TimeframeData                    // 策略指标数据的全部周期指标
+- Timestamp(data interface{}) int64                                          // 策略指标数据的生成时间（Unix秒，不支持的类型返回0）
+- (tf *TimeframeData) CurrentPrice() float64                                // 当前价格（未收盘K线的最新价，没有时为收盘价）
+
+排名提示词把所有交易对的关键指标放在一张表里，由AI挑选值得详细分析的候选，
+每个交易对只占一行，比完整的指标数据小得多。
+*/
+package indicators
+
+import "math"
+
+// 趋势状态（主分析周期 EMA9/EMA21/EMA55 排列）
+const (
+	TrendUp    = "up"    // EMA9 > EMA21 > EMA55
+	TrendDown  = "down"  // EMA9 < EMA21 < EMA55
+	TrendMixed = "mixed" // 均线交织
+)
+
+// KeyMetrics 交易对的关键指标（主分析周期）
+type KeyMetrics struct {
+	Symbol      string   `json:"symbol"`
+	Timeframe   string   `json:"timeframe"`               // 主分析周期
+	Price       float64  `json:"price"`                   // 收盘价
+	ChangePct   float64  `json:"change_pct"`              // 当前K线涨跌幅(%)
+	Trend       string   `json:"trend"`                   // 均线排列：up / down / mixed
+	RSI         float64  `json:"rsi"`                     // RSI(14)
+	MACDHist    float64  `json:"macd_hist"`               // MACD柱状图
+	ATRPct      float64  `json:"atr_pct"`                 // ATR占价格的百分比
+	FundingRate float64  `json:"funding_rate"`            // 当前资金费率(%)
+	OIChangePct *float64 `json:"oi_change_pct,omitempty"` // 持仓量15分钟变化率(%)
+}
+
+// Summarize 从策略指标数据中提取主分析周期的关键指标（不支持的类型返回nil）
+func Summarize(data interface{}) *KeyMetrics {
+	symbol, timeframe, tf, market := primary(data)
+	if tf == nil || tf.ClosePrice <= 0 {
+		return nil
+	}
+
+	m := &KeyMetrics{
+		Symbol:    symbol,
+		Timeframe: timeframe,
+		Price:     tf.ClosePrice,
+		Trend:     trendOf(tf),
+		RSI:       round2(tf.RSI),
+		ATRPct:    round2(tf.ATRPct),
+	}
+	if tf.OpenPrice > 0 {
+		m.ChangePct = round2((tf.ClosePrice - tf.OpenPrice) / tf.OpenPrice * 100)
+	}
+	if tf.MACD != nil {
+		m.MACDHist = tf.MACD.Histogram
+	}
+	if market != nil {
+		m.FundingRate = market.FundingRate
+		m.OIChangePct = market.OIChange15m
+	}
+	return m
+}
+
+// PrimaryTimeframe 策略指标数据的主分析周期、该周期指标和市场数据（不支持的类型返回空）
+func PrimaryTimeframe(data interface{}) (string, *TimeframeData, *MarketData) {
+	_, timeframe, tf, market := primary(data)
+	return timeframe, tf, market
+}
+
+// Timeframes 策略指标数据的全部周期指标（周期 -> 指标，计算失败的周期不包含，不支持的类型返回nil）
+func Timeframes(data interface{}) map[string]*TimeframeData {
+	var all map[string]*TimeframeData
+	switch d := data.(type) {
+	case *ShortTermIndicators:
+		if d.Timeframes != nil {
+			all = map[string]*TimeframeData{"1h": d.Timeframes.H1, "15m": d.Timeframes.M15, "5m": d.Timeframes.M5}
+		}
+	case *LongTermIndicators:
+		if d.Timeframes != nil {
+			all = map[string]*TimeframeData{"4h": d.Timeframes.H4, "1h": d.Timeframes.H1, "15m": d.Timeframes.M15}
+		}
+	case *ScalpIndicators:
+		if d.Timeframes != nil {
+			all = map[string]*TimeframeData{"15m": d.Timeframes.M15, "5m": d.Timeframes.M5, "1m": d.Timeframes.M1}
+		}
+	case *SwingIndicators:
+		if d.Timeframes != nil {
+			all = map[string]*TimeframeData{"1d": d.Timeframes.D1, "4h": d.Timeframes.H4, "1h": d.Timeframes.H1}
+		}
+	}
+	for interval, tf := range all {
+		if tf == nil {
+			delete(all, interval)
+		}
+	}
+	return all
+}
+
+// Timestamp 策略指标数据的生成时间（Unix秒，不支持的类型返回0）
+func Timestamp(data interface{}) int64 {
+	switch d := data.(type) {
+	case *ShortTermIndicators:
+		return d.Timestamp
+	case *LongTermIndicators:
+		return d.Timestamp
+	case *ScalpIndicators:
+		return d.Timestamp
+	case *SwingIndicators:
+		return d.Timestamp
+	}
+	return 0
+}
+
+// CurrentPrice 当前价格：只用已收盘K线计算时为未收盘K线的最新价，否则为收盘价
+func (tf *TimeframeData) CurrentPrice() float64 {
+	if tf.LivePrice != nil {
+		return *tf.LivePrice
+	}
+	return tf.ClosePrice
+}
+
+// primary 按策略指标类型取交易对、主分析周期、该周期指标和市场数据
+func primary(data interface{}) (string, string, *TimeframeData, *MarketData) {
+	switch d := data.(type) {
+	case *ShortTermIndicators:
+		if d.Timeframes != nil {
+			return d.Symbol, "15m", d.Timeframes.M15, d.MarketData
+		}
+	case *LongTermIndicators:
+		if d.Timeframes != nil {
+			return d.Symbol, "1h", d.Timeframes.H1, d.MarketData
+		}
+	case *ScalpIndicators:
+		if d.Timeframes != nil {
+			return d.Symbol, "5m", d.Timeframes.M5, d.MarketData
+		}
+	case *SwingIndicators:
+		if d.Timeframes != nil {
+			return d.Symbol, "4h", d.Timeframes.H4, d.MarketData
+		}
+	}
+	return "", "", nil, nil
+}
+
+// trendOf 按EMA排列判断趋势
+func trendOf(tf *TimeframeData) string {
+	switch {
+	case tf.EMA9 > tf.EMA21 && tf.EMA21 > tf.EMA55:
+		return TrendUp
+	case tf.EMA9 < tf.EMA21 && tf.EMA21 < tf.EMA55:
+		return TrendDown
+	default:
+		return TrendMixed
+	}
+}
+
+// round2 保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,250 @@
+/*
+Package indicators 指标计算耗时统计
+
+主要功能：
+- Telemetry() *TelemetrySnapshot                                                   // 获取耗时统计快照（按指标函数、策略类型、交易对）
+- ResetTelemetry()                                                                 // 清空耗时统计
+- CycleLatency(kind string, symbols []string) time.Duration                        // 一个周期内交易对最近一次指标计算的耗时合计
+- CheckCycleBudget(kind string, symbols []string, budget time.Duration) (time.Duration, bool)  // 检查周期的指标计算耗时是否超出预算（超出时计数）
+- RunTelemetryLog(ctx context.Context, interval time.Duration)                     // 定时输出耗时统计日志，直到ctx取消
+
+进程内统计，所有账号共用。每个ta-lib指标函数（ema、macd、rsi等）记录调用次数和耗时；
+Calculate*Indicators 按策略指标类型（KindShortTerm等）和交易对记录整体耗时（不含获取OI、资金费率等网络请求）。
+新增较重的指标后可以对比各函数和交易对的平均、最大耗时，发现性能退化。
+*/
+package indicators
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// LatencyStats 耗时统计（毫秒）
+type LatencyStats struct {
+	Count   int64   `json:"count"`    // 调用次数
+	TotalMs float64 `json:"total_ms"` // 总耗时
+	AvgMs   float64 `json:"avg_ms"`   // 平均耗时
+	MaxMs   float64 `json:"max_ms"`   // 最大耗时
+	LastMs  float64 `json:"last_ms"`  // 最近一次耗时
+}
+
+// KindTelemetry 一种策略指标类型的耗时统计
+type KindTelemetry struct {
+	Total      LatencyStats            `json:"total"`       // 所有交易对合计
+	Symbols    map[string]LatencyStats `json:"symbols"`     // 按交易对
+	OverBudget int64                   `json:"over_budget"` // 指标计算耗时超出周期预算的次数
+}
+
+// TelemetrySnapshot 耗时统计快照
+type TelemetrySnapshot struct {
+	Since     time.Time                 `json:"since"`     // 开始统计的时间（启动或上次清空）
+	Functions map[string]LatencyStats   `json:"functions"` // 按指标函数
+	Kinds     map[string]*KindTelemetry `json:"kinds"`     // 按策略指标类型
+}
+
+// telemetry 全局耗时统计
+var telemetry = newTelemetryRecorder()
+
+// telemetryRecorder 耗时统计（并发安全）
+type telemetryRecorder struct {
+	mu        sync.Mutex
+	since     time.Time
+	functions map[string]*LatencyStats
+	kinds     map[string]*kindRecorder
+}
+
+// kindRecorder 一种策略指标类型的统计
+type kindRecorder struct {
+	total      LatencyStats
+	symbols    map[string]*LatencyStats
+	overBudget int64
+}
+
+// newTelemetryRecorder 创建空的耗时统计
+func newTelemetryRecorder() *telemetryRecorder {
+	return &telemetryRecorder{
+		since:     time.Now(),
+		functions: make(map[string]*LatencyStats),
+		kinds:     make(map[string]*kindRecorder),
+	}
+}
+
+// observe 累加一次耗时
+func (s *LatencyStats) observe(d time.Duration) {
+	ms := float64(d.Microseconds()) / 1000
+	s.Count++
+	s.TotalMs += ms
+	s.LastMs = ms
+	if ms > s.MaxMs {
+		s.MaxMs = ms
+	}
+}
+
+// snapshot 计算平均耗时后返回副本
+func (s *LatencyStats) snapshot() LatencyStats {
+	c := *s
+	if c.Count > 0 {
+		c.AvgMs = c.TotalMs / float64(c.Count)
+	}
+	return c
+}
+
+// kind 获取策略指标类型的统计（不存在时创建，调用方持有锁）
+func (t *telemetryRecorder) kind(kind string) *kindRecorder {
+	k, ok := t.kinds[kind]
+	if !ok {
+		k = &kindRecorder{symbols: make(map[string]*LatencyStats)}
+		t.kinds[kind] = k
+	}
+	return k
+}
+
+// trackFunc 记录指标函数的耗时（用法：defer trackFunc("ema", time.Now())）
+func trackFunc(name string, start time.Time) {
+	d := time.Since(start)
+	telemetry.mu.Lock()
+	defer telemetry.mu.Unlock()
+
+	s, ok := telemetry.functions[name]
+	if !ok {
+		s = &LatencyStats{}
+		telemetry.functions[name] = s
+	}
+	s.observe(d)
+}
+
+// trackSymbol 记录一个交易对整体指标计算的耗时（用法：defer trackSymbol(KindShortTerm, symbol, time.Now())）
+func trackSymbol(kind, symbol string, start time.Time) {
+	d := time.Since(start)
+	telemetry.mu.Lock()
+	defer telemetry.mu.Unlock()
+
+	k := telemetry.kind(kind)
+	k.total.observe(d)
+	s, ok := k.symbols[symbol]
+	if !ok {
+		s = &LatencyStats{}
+		k.symbols[symbol] = s
+	}
+	s.observe(d)
+}
+
+// Telemetry 获取耗时统计快照
+func Telemetry() *TelemetrySnapshot {
+	telemetry.mu.Lock()
+	defer telemetry.mu.Unlock()
+
+	snapshot := &TelemetrySnapshot{
+		Since:     telemetry.since,
+		Functions: make(map[string]LatencyStats, len(telemetry.functions)),
+		Kinds:     make(map[string]*KindTelemetry, len(telemetry.kinds)),
+	}
+	for name, s := range telemetry.functions {
+		snapshot.Functions[name] = s.snapshot()
+	}
+	for kind, k := range telemetry.kinds {
+		kt := &KindTelemetry{
+			Total:      k.total.snapshot(),
+			Symbols:    make(map[string]LatencyStats, len(k.symbols)),
+			OverBudget: k.overBudget,
+		}
+		for symbol, s := range k.symbols {
+			kt.Symbols[symbol] = s.snapshot()
+		}
+		snapshot.Kinds[kind] = kt
+	}
+	return snapshot
+}
+
+// ResetTelemetry 清空耗时统计
+func ResetTelemetry() {
+	fresh := newTelemetryRecorder()
+	telemetry.mu.Lock()
+	defer telemetry.mu.Unlock()
+
+	telemetry.since, telemetry.functions, telemetry.kinds = fresh.since, fresh.functions, fresh.kinds
+}
+
+// CycleLatency 一个周期内交易对最近一次指标计算的耗时合计（没有统计的交易对不计入）
+func CycleLatency(kind string, symbols []string) time.Duration {
+	telemetry.mu.Lock()
+	defer telemetry.mu.Unlock()
+
+	k, ok := telemetry.kinds[kind]
+	if !ok {
+		return 0
+	}
+	var ms float64
+	for _, symbol := range symbols {
+		if s, ok := k.symbols[symbol]; ok {
+			ms += s.LastMs
+		}
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// CheckCycleBudget 检查周期的指标计算耗时是否超出预算，超出时计入 OverBudget
+// 返回耗时合计及是否超出（budget<=0时不检查）
+func CheckCycleBudget(kind string, symbols []string, budget time.Duration) (time.Duration, bool) {
+	latency := CycleLatency(kind, symbols)
+	if budget <= 0 || latency <= budget {
+		return latency, false
+	}
+
+	telemetry.mu.Lock()
+	defer telemetry.mu.Unlock()
+	telemetry.kind(kind).overBudget++
+	return latency, true
+}
+
+// RunTelemetryLog 定时输出耗时统计日志（每种策略指标类型的整体耗时和最慢的交易对，各指标函数的耗时），直到ctx取消
+func RunTelemetryLog(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			snapshot := Telemetry()
+			for kind, k := range snapshot.Kinds {
+				slowest, slowestMs := "", 0.0
+				for symbol, s := range k.Symbols {
+					if s.AvgMs > slowestMs {
+						slowest, slowestMs = symbol, s.AvgMs
+					}
+				}
+				utils.Info("指标计算耗时",
+					zap.String("kind", kind),
+					zap.Int64("count", k.Total.Count),
+					zap.Float64("avg_ms", k.Total.AvgMs),
+					zap.Float64("max_ms", k.Total.MaxMs),
+					zap.String("slowest_symbol", slowest),
+					zap.Float64("slowest_avg_ms", slowestMs),
+					zap.Int64("over_budget", k.OverBudget),
+				)
+			}
+			names := make([]string, 0, len(snapshot.Functions))
+			for name := range snapshot.Functions {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				s := snapshot.Functions[name]
+				utils.Info("指标函数耗时",
+					zap.String("function", name),
+					zap.Int64("count", s.Count),
+					zap.Float64("avg_ms", s.AvgMs),
+					zap.Float64("max_ms", s.MaxMs),
+				)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
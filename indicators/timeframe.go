@@ -0,0 +1,141 @@
+/*
+Package indicators 单个时间周期的指标计算
+
+主要功能：
+- calculateTimeframeDataWithParams(klines []binance.Kline, timeframe string, params IndicatorParams) *TimeframeData  // 使用指定参数计算单个时间周期的指标数据
+
+各策略按 Profile 中的周期和参数分别调用（见 profile.go）。
+*/
+package indicators
+
+import (
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// volumeRatioPeriod 相对成交量（volume_ratio）的平均窗口（K线根数）
+const volumeRatioPeriod = 20
+
+// 布林带收口：宽度处于最近 bbSqueezeLookback 根K线的最低 bbSqueezePercentile 百分位
+const (
+	bbSqueezeLookback   = 50
+	bbSqueezePercentile = 10
+)
+
+// calculateTimeframeDataWithParams 使用指定参数计算单个时间周期的指标数据
+func calculateTimeframeDataWithParams(klines []binance.Kline, timeframe string, params IndicatorParams) *TimeframeData {
+	if len(klines) == 0 {
+		return nil
+	}
+
+	latest := len(klines) - 1
+
+	// 获取价格信息（至少2位小数，低价币保留6位有效数字）
+	closePrice, _ := strconv.ParseFloat(klines[latest].Close, 64)
+	highPrice, _ := strconv.ParseFloat(klines[latest].High, 64)
+	lowPrice, _ := strconv.ParseFloat(klines[latest].Low, 64)
+	openPrice, _ := strconv.ParseFloat(klines[latest].Open, 64)
+	volume := GetVolume(klines[latest])
+	quoteVolume := GetQuoteVolume(klines[latest])
+
+	// 计算趋势指标
+	ema9 := CalculateEMA(klines, 9)
+	ema21 := CalculateEMA(klines, 21)
+	ema55 := CalculateEMA(klines, 55)
+	emaState := CalculateEMAState(klines, params.ATRPeriod)
+
+	// 计算动能指标
+	macd := CalculateMACD(klines)
+	rsi := CalculateRSI(klines, params.RSIPeriod)
+
+	// 计算波动率指标
+	bb := CalculateBollingerBands(klines, params.BBPeriod, params.BBStdDev)
+	atr := CalculateATR(klines, params.ATRPeriod)
+	atrPct := CalculateATRPercent(klines, params.ATRPeriod)
+	bbWidthPct, bbSqueeze := bbWidth(klines, params)
+
+	// 成交量结构：主动买入占比、相对平均成交量
+	var takerBuyPct, volumeRatio *float64
+	if pct, ok := CalculateTakerBuyPercent(klines[latest]); ok {
+		takerBuyPct = &pct
+	}
+	if ratio := CalculateVolumeRatio(klines, volumeRatioPeriod); ratio > 0 {
+		volumeRatio = &ratio
+	}
+
+	// 第二阶段指标（可选）
+	var adx *float64
+	var vwap *float64
+	var stochRSI *StochRSIData
+	if len(klines) >= params.ADXPeriod*2 {
+		adxValue := CalculateADX(klines, params.ADXPeriod)
+		if adxValue > 0 {
+			adx = &adxValue
+		}
+		vwapValue := CalculateVWAP(klines)
+		if vwapValue > 0 {
+			vwap = &vwapValue
+		}
+		stochRSI = CalculateStochRSI(klines, params.StochRSIPeriod)
+	}
+
+	data := &TimeframeData{
+		ClosePrice:  formatPrice(closePrice),
+		HighPrice:   formatPrice(highPrice),
+		LowPrice:    formatPrice(lowPrice),
+		OpenPrice:   formatPrice(openPrice),
+		EMA9:        ema9,
+		EMA21:       ema21,
+		EMA55:       ema55,
+		EMAState:    emaState,
+		MACD:        macd,
+		RSI:         rsi,
+		BB:          bb,
+		ATR:         atr,
+		ATRPct:      atrPct,
+		BBWidthPct:  bbWidthPct,
+		BBSqueeze:   bbSqueeze,
+		Volume:      volume,
+		QuoteVolume: quoteVolume,
+		TakerBuyPct: takerBuyPct,
+		VolumeRatio: volumeRatio,
+		ADX:         adx,
+		VWAP:        vwap,
+		StochRSI:    stochRSI,
+	}
+
+	utils.Debug("时间周期指标计算完成",
+		zap.String("timeframe", timeframe),
+		zap.Float64("close", data.ClosePrice),
+		zap.Float64("ema9", data.EMA9),
+		zap.Float64("ema21", data.EMA21),
+		zap.Float64("ema55", data.EMA55),
+		zap.Float64("rsi", rsi),
+	)
+
+	return data
+}
+
+// bbWidth 最新的布林带宽度百分比，以及是否收口（K线不足 bbSqueezeLookback 根宽度时不判断收口）
+func bbWidth(klines []binance.Kline, params IndicatorParams) (float64, bool) {
+	series := CalculateBBWidthSeries(klines, params.BBPeriod, params.BBStdDev)
+	if len(series) == 0 {
+		return 0, false
+	}
+	current := series[len(series)-1]
+	if len(series) < bbSqueezeLookback {
+		return formatPercent(current), false
+	}
+
+	// 比当前更窄的K线占比（持续收口时宽度相同的K线不计入）
+	narrower := 0
+	for _, width := range series[len(series)-bbSqueezeLookback:] {
+		if width < current {
+			narrower++
+		}
+	}
+	return formatPercent(current), float64(narrower)/bbSqueezeLookback*100 < bbSqueezePercentile
+}
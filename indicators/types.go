@@ -4,28 +4,62 @@ Package indicators 指标数据结构定义
 数据结构：
 - ShortTermIndicators   // 短线策略指标（1h → 15m → 5m）
 - LongTermIndicators    // 中长线策略指标（4h → 1h → 15m）
+- ScalpIndicators       // 剥头皮策略指标（15m → 5m → 1m）
+- SwingIndicators       // 波段策略指标（1d → 4h → 1h）
+- IndicatorParams       // 指标参数
 - TimeframeData         // 单个时间周期的指标数据
 - MACDData              // MACD指标数据
+- EMAStateData          // EMA排列、斜率与交叉状态
 - BBData                // 布林带数据
+- SpreadData            // 配对交易价差数据
+- SuggestedLevels       // 基于ATR的建议止损止盈价位（定义见levels.go）
 */
 package indicators
 
 // ShortTermIndicators 短线策略指标（持仓30-90分钟）
 // 时间周期：1h（方向过滤） → 15m（主分析） → 5m（入场）
 type ShortTermIndicators struct {
+	SchemaVersion int              `json:"schema_version"` // 数据结构版本（SchemaVersion，旧版本数据用 schemas 包迁移）
 	Symbol     string              `json:"symbol"`
 	Timestamp  int64               `json:"timestamp"`
 	MarketData *MarketData         `json:"market_data,omitempty"` // 市场数据（OI、资金费率）
 	Timeframes *ShortTermTimeframes `json:"timeframes"`            // 各时间周期指标
+	Levels     *SuggestedLevels    `json:"suggested_levels,omitempty"` // 基于ATR的建议止损止盈价位
 }
 
 // LongTermIndicators 中长线策略指标（持仓2-4小时）
 // 时间周期：4h（大趋势） → 1h（主分析） → 15m（入场）
 type LongTermIndicators struct {
+	SchemaVersion int             `json:"schema_version"` // 数据结构版本
 	Symbol     string             `json:"symbol"`
 	Timestamp  int64              `json:"timestamp"`
 	MarketData *MarketData        `json:"market_data,omitempty"` // 市场数据（OI、资金费率）
 	Timeframes *LongTermTimeframes `json:"timeframes"`            // 各时间周期指标
+	Levels     *SuggestedLevels    `json:"suggested_levels,omitempty"` // 基于ATR的建议止损止盈价位
+}
+
+// ScalpIndicators 剥头皮策略指标（持仓5-20分钟）
+// 时间周期：15m（方向过滤） → 5m（主分析） → 1m（入场）
+type ScalpIndicators struct {
+	SchemaVersion int           `json:"schema_version"` // 数据结构版本
+	Symbol     string           `json:"symbol"`
+	Timestamp  int64            `json:"timestamp"`
+	Params     *IndicatorParams `json:"params"`                // 指标参数
+	MarketData *MarketData      `json:"market_data,omitempty"` // 市场数据（OI、资金费率）
+	Timeframes *ScalpTimeframes `json:"timeframes"`            // 各时间周期指标
+	Levels     *SuggestedLevels `json:"suggested_levels,omitempty"` // 基于ATR的建议止损止盈价位
+}
+
+// SwingIndicators 波段策略指标（持仓1-5天）
+// 时间周期：1d（宏观趋势） → 4h（主分析） → 1h（入场）
+type SwingIndicators struct {
+	SchemaVersion int           `json:"schema_version"` // 数据结构版本
+	Symbol     string           `json:"symbol"`
+	Timestamp  int64            `json:"timestamp"`
+	Params     *IndicatorParams `json:"params"`                // 指标参数
+	MarketData *MarketData      `json:"market_data,omitempty"` // 市场数据（OI、资金费率）
+	Timeframes *SwingTimeframes `json:"timeframes"`            // 各时间周期指标
+	Levels     *SuggestedLevels `json:"suggested_levels,omitempty"` // 基于ATR的建议止损止盈价位
 }
 
 // ShortTermTimeframes 短线策略各时间周期
@@ -42,6 +76,30 @@ type LongTermTimeframes struct {
 	M15 *TimeframeData `json:"15m"` // 15分钟 - 入场周期
 }
 
+// ScalpTimeframes 剥头皮策略各时间周期
+type ScalpTimeframes struct {
+	M15 *TimeframeData `json:"15m"` // 15分钟 - 方向过滤
+	M5  *TimeframeData `json:"5m"`  // 5分钟 - 主分析周期
+	M1  *TimeframeData `json:"1m"`  // 1分钟 - 入场周期
+}
+
+// SwingTimeframes 波段策略各时间周期
+type SwingTimeframes struct {
+	D1 *TimeframeData `json:"1d"` // 日线 - 宏观趋势
+	H4 *TimeframeData `json:"4h"` // 4小时 - 主分析周期
+	H1 *TimeframeData `json:"1h"` // 1小时 - 入场周期
+}
+
+// IndicatorParams 指标参数（EMA固定为9/21/55）
+type IndicatorParams struct {
+	RSIPeriod      int     `json:"rsi_period"`       // RSI周期
+	ATRPeriod      int     `json:"atr_period"`       // ATR周期
+	BBPeriod       int     `json:"bb_period"`        // 布林带周期
+	BBStdDev       float64 `json:"bb_std_dev"`       // 布林带标准差倍数
+	ADXPeriod      int     `json:"adx_period"`       // ADX周期
+	StochRSIPeriod int     `json:"stoch_rsi_period"` // Stochastic RSI周期
+}
+
 // MarketData 市场数据（symbol级别）
 type MarketData struct {
 	// 持仓量数据
@@ -52,35 +110,62 @@ type MarketData struct {
 	OIChange25m *float64 `json:"oi_change_25m,omitempty"` // 25分钟变化率(%)
 	OIChange45m *float64 `json:"oi_change_45m,omitempty"` // 45分钟变化率(%)
 	OIChange75m *float64 `json:"oi_change_75m,omitempty"` // 75分钟变化率(%)
+	OIPriceState string  `json:"oi_price_state,omitempty"` // 持仓量与价格关系：long_buildup / short_covering / short_buildup / long_liquidation / neutral
 	
 	// 资金费率数据
 	FundingRate float64 `json:"funding_rate"` // 当前资金费率(%)
 	FundingAvg3 float64 `json:"funding_avg_3"` // 最近3次平均(%)
+	FundingFlip string  `json:"funding_flip,omitempty"` // 资金费率翻转：to_positive（由负转正）/ to_negative（由正转负），未翻转时为空
+
+	// 波动率分位（1小时ATR%在交易对自身30天分布中的位置）
+	ATRPct1h         float64  `json:"atr_pct_1h,omitempty"`         // 最近一根已收盘1小时K线的ATR(14)%
+	ATRPctPercentile *float64 `json:"atr_pct_percentile,omitempty"` // ATR%在30天分布中的百分位（0最平静，100最剧烈）
+	VolatilityRegime string   `json:"volatility_regime,omitempty"`  // 波动率状态：quiet / normal / elevated / extreme
+
+	// 强平数据（行情接口提供强平统计时）
+	Liquidations       []LiquidationWindow `json:"liquidations,omitempty"`        // 各滚动窗口的强平名义价值
+	LiquidationCascade bool                `json:"liquidation_cascade,omitempty"` // 交易对或全市场是否处于连环强平中
+}
+
+// LiquidationWindow 滚动窗口内的强平统计
+type LiquidationWindow struct {
+	WindowMinutes int     `json:"window_minutes"` // 窗口长度（分钟）
+	LongUSDT      float64 `json:"long_usdt"`      // 多头被强平的名义价值（USDT）
+	ShortUSDT     float64 `json:"short_usdt"`     // 空头被强平的名义价值（USDT）
+	Count         int     `json:"count"`          // 强平订单数
 }
 
 // TimeframeData 单个时间周期的指标数据（第一阶段：核心指标）
 type TimeframeData struct {
 	// 价格信息
-	ClosePrice float64 `json:"close_price"` // 收盘价
-	HighPrice  float64 `json:"high_price"`  // 最高价
-	LowPrice   float64 `json:"low_price"`   // 最低价
-	OpenPrice  float64 `json:"open_price"`  // 开盘价
+	ClosePrice float64  `json:"close_price"`          // 收盘价（只用已收盘K线计算时为最近一根已收盘K线的收盘价）
+	HighPrice  float64  `json:"high_price"`           // 最高价
+	LowPrice   float64  `json:"low_price"`            // 最低价
+	OpenPrice  float64  `json:"open_price"`           // 开盘价
+	LivePrice  *float64 `json:"live_price,omitempty"` // 未收盘K线的最新价（只用已收盘K线计算时设置）
 
 	// 趋势指标
-	EMA9  float64 `json:"ema9"`  // 9周期指数移动平均线
-	EMA21 float64 `json:"ema21"` // 21周期指数移动平均线
-	EMA55 float64 `json:"ema55"` // 55周期指数移动平均线
+	EMA9     float64       `json:"ema9"`                // 9周期指数移动平均线
+	EMA21    float64       `json:"ema21"`               // 21周期指数移动平均线
+	EMA55    float64       `json:"ema55"`               // 55周期指数移动平均线
+	EMAState *EMAStateData `json:"ema_state,omitempty"` // EMA排列、斜率与交叉状态（K线不足55根时为空）
 
 	// 动能指标
 	MACD *MACDData `json:"macd"` // MACD指标
 	RSI  float64   `json:"rsi"`  // RSI指标(14)
 
 	// 波动率指标
-	BB  *BBData `json:"bb"`  // 布林带(20, 2)
-	ATR float64 `json:"atr"` // 平均真实波幅(14)
+	BB         *BBData `json:"bb"`           // 布林带(20, 2)
+	ATR        float64 `json:"atr"`          // 平均真实波幅(14)
+	ATRPct     float64 `json:"atr_pct"`      // ATR占收盘价的百分比（不同价格量级的交易对可以直接比较）
+	BBWidthPct float64 `json:"bb_width_pct"` // 布林带宽度占中轨的百分比
+	BBSqueeze  bool    `json:"bb_squeeze"`   // 布林带收口（宽度处于最近50根K线的最低10%）
 
 	// 成交量
-	Volume float64 `json:"volume"` // 当前成交量
+	Volume      float64  `json:"volume"`                  // 当前成交量
+	QuoteVolume float64  `json:"quote_volume"`            // 当前成交额（计价币种）
+	TakerBuyPct *float64 `json:"taker_buy_pct,omitempty"` // 主动买入量占成交量的百分比（交易所不提供时为空）
+	VolumeRatio *float64 `json:"volume_ratio,omitempty"`  // 成交量与前20根K线平均成交量的比值（K线不足时为空）
 
 	// 第二阶段扩展（预留）
 	ADX      *float64      `json:"adx,omitempty"`       // 平均趋向指标
@@ -99,6 +184,15 @@ type MACDData struct {
 	Histogram float64 `json:"histogram"` // 柱状图（DIF-DEA）
 }
 
+// EMAStateData EMA排列、斜率与交叉状态（由均线序列推导，避免AI从原始数值自行判断）
+type EMAStateData struct {
+	Order        string  `json:"order"`                // EMA9/21/55排列：up（多头排列）、down（空头排列）、mixed（交织）
+	EMA21Slope   float64 `json:"ema21_slope_pct"`      // EMA21最近5根K线的变化百分比
+	CrossBars    *int    `json:"cross_bars,omitempty"` // 距最近一次EMA9/21交叉的K线根数（0为最新一根，没有交叉时为空）
+	Cross        string  `json:"cross,omitempty"`      // 最近一次交叉的方向：golden（EMA9上穿EMA21）、dead（EMA9下穿EMA21）
+	EMA55DistATR float64 `json:"ema55_dist_atr"`       // 收盘价与EMA55的距离（ATR倍数，正数在EMA55上方）
+}
+
 // BBData 布林带数据
 type BBData struct {
 	Upper  float64 `json:"upper"`  // 上轨
@@ -120,3 +214,20 @@ type IchimokuData struct {
 	SenkouSpanB float64 `json:"senkou_span_b"` // 先行带B
 	ChikouSpan  float64 `json:"chikou_span"`  // 迟行线
 }
+
+// SpreadData 配对交易价差数据（对数价格价差：ln(A) - HedgeRatio × ln(B)）
+type SpreadData struct {
+	SymbolA     string  `json:"symbol_a"`     // 腿A（如ETHUSDT）
+	SymbolB     string  `json:"symbol_b"`     // 腿B（如BTCUSDT）
+	PriceA      float64 `json:"price_a"`      // 腿A最新价格
+	PriceB      float64 `json:"price_b"`      // 腿B最新价格
+	Ratio       float64 `json:"ratio"`        // 价格比 A/B
+	HedgeRatio  float64 `json:"hedge_ratio"`  // 对冲比例（对数价格OLS回归系数）
+	Spread      float64 `json:"spread"`       // 最新价差
+	Mean        float64 `json:"mean"`         // 回看窗口内价差均值
+	StdDev      float64 `json:"std_dev"`      // 回看窗口内价差标准差
+	ZScore      float64 `json:"z_score"`      // 价差Z分数
+	Correlation float64 `json:"correlation"`  // 对数收益率相关系数
+	HalfLife    float64 `json:"half_life"`    // 价差均值回归半衰期（K线根数，不回归时为0）
+	Bars        int     `json:"bars"`         // 参与计算的K线数量
+}
@@ -0,0 +1,169 @@
+/*
+Package indicators 波动率分位（当前ATR%在交易对自身30天分布中的位置）
+
+主要功能：
+- ATRPercentSeries(klines []binance.Kline, period int) []float64           // 每根K线的ATR%序列（前period根没有ATR，不包含）
+- PercentileRank(values []float64, v float64) float64                      // v在values中的百分位（不大于v的比例，0-100）
+- VolatilityRegime(percentile float64) string                              // 按百分位划分的波动率状态
+- ApplyVolatilityRegime(marketData *MarketData, market exchange.MarketData, symbol string)  // 设置市场数据的1小时ATR%、30天百分位和波动率状态
+
+同样的ATR%对BTC可能是极端行情，对小币种只是平常波动，原始ATR无法直接比较。
+使用1小时K线：最近一根已收盘K线的ATR(14)%，在最近30天（720根）已收盘K线的ATR%分布中的百分位，
+0表示30天内最平静，100表示30天内最剧烈。交易所单次返回的K线不足30天时（如OKX最多300根）使用返回的全部，
+样本少于 volatilityMinSamples 时不设置。
+
+每个交易对的结果缓存到下一根1小时K线收盘，一小时内只请求一次交易所，多个账号、策略共用。
+请求失败不缓存，下次调用重新请求。
+*/
+package indicators
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/utils"
+
+	"github.com/markcheno/go-talib"
+	"go.uber.org/zap"
+)
+
+// 波动率状态
+const (
+	VolatilityQuiet    = "quiet"    // 百分位低于20：30天内偏平静
+	VolatilityNormal   = "normal"   // 百分位20-80
+	VolatilityElevated = "elevated" // 百分位80-95：波动放大
+	VolatilityExtreme  = "extreme"  // 百分位95以上：30天内最剧烈的5%
+)
+
+const (
+	volatilityInterval   = "1h"
+	volatilityLookback   = 30 * 24 // 30天的1小时K线
+	volatilityATRPeriod  = 14
+	volatilityMinSamples = 7 * 24 // 至少7天的样本才计算百分位
+)
+
+// volatilityEntry 一个交易对的波动率分位缓存
+type volatilityEntry struct {
+	expires    time.Time
+	ok         bool // 样本是否足够
+	atrPct     float64
+	percentile float64
+}
+
+var (
+	volatilityMu    sync.Mutex
+	volatilityCache = make(map[string]*volatilityEntry) // "交易所|交易对" -> 缓存
+)
+
+// ATRPercentSeries 每根K线的ATR%（ATR占该K线收盘价的百分比），从旧到新
+// 前period根K线没有ATR，返回的序列长度为 len(klines)-period，K线不足时返回nil
+func ATRPercentSeries(klines []binance.Kline, period int) []float64 {
+	if len(klines) < period+1 {
+		return nil
+	}
+	highs, lows, closes := extractHLC(klines)
+	atr := talib.Atr(highs, lows, closes, period)
+
+	series := make([]float64, 0, len(klines)-period)
+	for i := period; i < len(klines); i++ {
+		if closes[i] <= 0 {
+			continue
+		}
+		series = append(series, atr[i]/closes[i]*100)
+	}
+	return series
+}
+
+// PercentileRank v在values中的百分位（values中不大于v的比例×100），values为空时返回0
+func PercentileRank(values []float64, v float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := sort.Search(len(sorted), func(i int) bool { return sorted[i] > v })
+	return float64(n) / float64(len(sorted)) * 100
+}
+
+// VolatilityRegime 按百分位划分的波动率状态
+func VolatilityRegime(percentile float64) string {
+	switch {
+	case percentile >= 95:
+		return VolatilityExtreme
+	case percentile >= 80:
+		return VolatilityElevated
+	case percentile < 20:
+		return VolatilityQuiet
+	default:
+		return VolatilityNormal
+	}
+}
+
+// ApplyVolatilityRegime 设置市场数据的1小时ATR%、30天百分位和波动率状态
+// 获取K线失败或样本不足时不设置（结果按交易对缓存到下一根1小时K线收盘）
+func ApplyVolatilityRegime(marketData *MarketData, market exchange.MarketData, symbol string) {
+	if marketData == nil || market == nil {
+		return
+	}
+	entry, err := volatilityPercentile(market, symbol)
+	if err != nil {
+		utils.Warn("计算波动率分位失败", zap.String("symbol", symbol), zap.Error(err))
+		return
+	}
+	if !entry.ok {
+		return
+	}
+	percentile := entry.percentile
+	marketData.ATRPct1h = entry.atrPct
+	marketData.ATRPctPercentile = &percentile
+	marketData.VolatilityRegime = VolatilityRegime(percentile)
+}
+
+// volatilityPercentile 获取交易对的波动率分位（缓存有效时直接返回，样本不足时ok为false）
+func volatilityPercentile(market exchange.MarketData, symbol string) (*volatilityEntry, error) {
+	key := market.Name() + "|" + symbol
+	now := time.Now()
+
+	volatilityMu.Lock()
+	cached, ok := volatilityCache[key]
+	volatilityMu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached, nil
+	}
+
+	// 多取1根：最后一根是未收盘的K线
+	klines, err := market.GetKlines(symbol, volatilityInterval, volatilityLookback+volatilityATRPeriod+1)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(klines); n > 0 && klines[n-1].CloseTime >= now.UnixMilli() {
+		klines = klines[:n-1]
+	}
+
+	entry := &volatilityEntry{expires: now.Truncate(time.Hour).Add(time.Hour)}
+	series := ATRPercentSeries(klines, volatilityATRPeriod)
+	if len(series) >= volatilityMinSamples {
+		if len(series) > volatilityLookback {
+			series = series[len(series)-volatilityLookback:]
+		}
+		current := series[len(series)-1]
+		entry.ok = true
+		entry.atrPct = math.Round(current*10000) / 10000
+		entry.percentile = math.Round(PercentileRank(series, current)*10) / 10
+	} else {
+		utils.Debug("波动率分位样本不足",
+			zap.String("symbol", symbol),
+			zap.Int("samples", len(series)),
+			zap.Int("min_samples", volatilityMinSamples),
+		)
+	}
+
+	volatilityMu.Lock()
+	volatilityCache[key] = entry
+	volatilityMu.Unlock()
+	return entry, nil
+}
@@ -0,0 +1,132 @@
+/*
+Package journal 交易日志（按账号追加写入JSON Lines文件）
+
+主要功能：
+- New(dir string) (*Journal, error)                        // 创建交易日志（目录不存在时自动创建）
+- (j *Journal) Record(trade *Trade) error                  // 记录一笔已结束的交易
+- (j *Journal) Load(accountID string) ([]Trade, error)     // 读取账号的全部交易
+- Summarize(trades []Trade) Summary                        // 汇总盈亏（价格盈亏、手续费、资金费、净盈亏）
+*/
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// Journal 交易日志
+type Journal struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// New 创建交易日志
+func New(dir string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建交易日志目录失败: %w", err)
+	}
+	return &Journal{dir: dir}, nil
+}
+
+// Record 记录一笔已结束的交易（写入前计算净盈亏）
+func (j *Journal) Record(trade *Trade) error {
+	trade.Finalize()
+
+	line, err := json.Marshal(trade)
+	if err != nil {
+		return fmt.Errorf("序列化交易记录失败: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.OpenFile(j.path(trade.AccountID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开交易日志失败: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("写入交易日志失败: %w", err)
+	}
+
+	utils.Info("交易已记录",
+		zap.String("account_id", trade.AccountID),
+		zap.String("symbol", trade.Symbol),
+		zap.Float64("gross_pnl", trade.GrossPnL),
+		zap.Float64("commission", trade.Commission),
+		zap.Float64("funding", trade.Funding),
+		zap.Float64("net_pnl", trade.NetPnL),
+	)
+
+	return nil
+}
+
+// Load 读取账号的全部交易（文件不存在时返回空）
+func (j *Journal) Load(accountID string) ([]Trade, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.Open(j.path(accountID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开交易日志失败: %w", err)
+	}
+	defer f.Close()
+
+	var trades []Trade
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var trade Trade
+		if err := json.Unmarshal(scanner.Bytes(), &trade); err != nil {
+			return nil, fmt.Errorf("解析交易记录失败: %w", err)
+		}
+		trades = append(trades, trade)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取交易日志失败: %w", err)
+	}
+
+	return trades, nil
+}
+
+// Summarize 汇总盈亏
+func Summarize(trades []Trade) Summary {
+	var s Summary
+	for _, t := range trades {
+		s.Trades++
+		if t.NetPnL > 0 {
+			s.Wins++
+		}
+		s.GrossPnL += t.GrossPnL
+		s.Commission += t.Commission
+		s.Funding += t.Funding
+		s.NetPnL += t.NetPnL
+	}
+
+	if s.Trades > 0 {
+		s.WinRate = float64(s.Wins) / float64(s.Trades)
+	}
+	if s.GrossPnL > 0 {
+		s.FeeDrag = (s.Commission + s.Funding) / s.GrossPnL
+	}
+	return s
+}
+
+// path 账号的日志文件路径
+func (j *Journal) path(accountID string) string {
+	return filepath.Join(j.dir, accountID+".jsonl")
+}
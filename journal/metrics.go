@@ -0,0 +1,271 @@
+/*
+Package journal 绩效指标（实盘日志和回测共用）
+
+主要功能：
+- CalculateMetrics(trades []Trade, initialBalance float64, start, end time.Time) *Metrics                        // 按交易列表计算绩效指标（每日边界为UTC）
+- CalculateMetricsIn(trades []Trade, initialBalance float64, start, end time.Time, loc *time.Location) *Metrics  // 按交易列表计算绩效指标（每日边界按指定时区）
+- MaxDrawdown(initial float64, pnls []float64) float64                                                           // 按交易顺序计算最大回撤（%）
+
+收益按交易出场时间（默认UTC，可指定时区）归入每日，日收益率 = 当日净盈亏 / 当日开始时的权益，
+夏普、索提诺按日收益率年化（加密货币全年交易，按365天），卡玛 = 年化收益率 / 最大回撤。
+最大回撤按交易出场顺序的权益曲线计算（不含持仓期间的浮动盈亏）。
+*/
+package journal
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// tradingDaysPerYear 年化使用的天数
+const tradingDaysPerYear = 365
+
+// Metrics 绩效指标
+type Metrics struct {
+	Start          time.Time `json:"start"`           // 统计开始时间
+	End            time.Time `json:"end"`             // 统计结束时间
+	InitialBalance float64   `json:"initial_balance"` // 初始资金
+	FinalBalance   float64   `json:"final_balance"`   // 最终资金（初始资金 + 净盈亏）
+	Trades         int       `json:"trades"`          // 交易笔数
+
+	TotalReturnPct  float64 `json:"total_return_pct"`  // 总收益率（%）
+	AnnualReturnPct float64 `json:"annual_return_pct"` // 年化收益率（%，按复利折算）
+	MaxDrawdown     float64 `json:"max_drawdown"`      // 最大回撤金额
+	MaxDrawdownPct  float64 `json:"max_drawdown_pct"`  // 最大回撤（%）
+
+	Sharpe  float64 `json:"sharpe"`  // 夏普比率（年化，无风险利率按0）
+	Sortino float64 `json:"sortino"` // 索提诺比率（年化，只计下行波动）
+	Calmar  float64 `json:"calmar"`  // 卡玛比率（年化收益率 / 最大回撤）
+
+	WinRate      float64 `json:"win_rate"`      // 胜率（0-1）
+	AvgWin       float64 `json:"avg_win"`       // 平均盈利
+	AvgLoss      float64 `json:"avg_loss"`      // 平均亏损（正数）
+	ProfitFactor float64 `json:"profit_factor"` // 盈利因子（盈利合计 / 亏损合计，没有亏损时为0）
+	Expectancy   float64 `json:"expectancy"`    // 期望值（每笔平均净盈亏）
+
+	AvgHoldingHours float64 `json:"avg_holding_hours"` // 平均持仓时间（小时）
+	ExposurePct     float64 `json:"exposure_pct"`      // 持仓时间占统计区间的比例（%，重叠的持仓只算一次）
+}
+
+// CalculateMetrics 按交易列表计算绩效指标（每日边界为UTC）
+// initialBalance: 初始资金（必须大于0，否则收益率类指标为0）
+// start, end: 统计区间（为零值时分别取第一笔入场时间和最后一笔出场时间）
+func CalculateMetrics(trades []Trade, initialBalance float64, start, end time.Time) *Metrics {
+	return CalculateMetricsIn(trades, initialBalance, start, end, time.UTC)
+}
+
+// CalculateMetricsIn 按交易列表计算绩效指标，日收益率按 loc 时区的自然日汇总
+func CalculateMetricsIn(trades []Trade, initialBalance float64, start, end time.Time, loc *time.Location) *Metrics {
+	sorted := make([]Trade, len(trades))
+	copy(sorted, trades)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ExitTime.Before(sorted[j].ExitTime) })
+
+	m := &Metrics{
+		Start:          start,
+		End:            end,
+		InitialBalance: initialBalance,
+		FinalBalance:   initialBalance,
+		Trades:         len(sorted),
+	}
+	if len(sorted) == 0 {
+		return m
+	}
+	if m.Start.IsZero() {
+		m.Start = sorted[0].EntryTime
+		for _, t := range sorted {
+			if t.EntryTime.Before(m.Start) {
+				m.Start = t.EntryTime
+			}
+		}
+	}
+	if m.End.IsZero() {
+		m.End = sorted[len(sorted)-1].ExitTime
+	}
+
+	// 盈亏分布
+	pnls := make([]float64, len(sorted))
+	var wins, losses int
+	var grossWin, grossLoss, totalPnL float64
+	var holding time.Duration
+	for i, t := range sorted {
+		pnls[i] = t.NetPnL
+		totalPnL += t.NetPnL
+		holding += t.ExitTime.Sub(t.EntryTime)
+		switch {
+		case t.NetPnL > 0:
+			wins++
+			grossWin += t.NetPnL
+		case t.NetPnL < 0:
+			losses++
+			grossLoss -= t.NetPnL
+		}
+	}
+
+	n := float64(len(sorted))
+	m.FinalBalance = initialBalance + totalPnL
+	m.WinRate = round4(float64(wins) / n)
+	m.Expectancy = round4(totalPnL / n)
+	m.AvgHoldingHours = round4(holding.Hours() / n)
+	if wins > 0 {
+		m.AvgWin = round4(grossWin / float64(wins))
+	}
+	if losses > 0 {
+		m.AvgLoss = round4(grossLoss / float64(losses))
+		m.ProfitFactor = round4(grossWin / grossLoss)
+	}
+	m.ExposurePct = round4(exposure(sorted, m.Start, m.End) * 100)
+
+	if initialBalance <= 0 {
+		return m
+	}
+
+	// 收益率和回撤
+	m.TotalReturnPct = round4(totalPnL / initialBalance * 100)
+	m.MaxDrawdownPct = round4(MaxDrawdown(initialBalance, pnls))
+	m.MaxDrawdown = round4(maxDrawdownAmount(initialBalance, pnls))
+
+	days := m.End.Sub(m.Start).Hours() / 24
+	if days >= 1 && m.FinalBalance > 0 {
+		m.AnnualReturnPct = round4((math.Pow(m.FinalBalance/initialBalance, tradingDaysPerYear/days) - 1) * 100)
+	}
+	if m.MaxDrawdownPct > 0 {
+		m.Calmar = round4(m.AnnualReturnPct / m.MaxDrawdownPct)
+	}
+
+	// 日收益率 → 夏普、索提诺
+	returns := dailyReturns(sorted, initialBalance, m.Start, m.End, loc)
+	if len(returns) >= 2 {
+		mean, std := meanStd(returns)
+		annualize := math.Sqrt(tradingDaysPerYear)
+		if std > 0 {
+			m.Sharpe = round4(mean / std * annualize)
+		}
+		if downside := downsideDeviation(returns); downside > 0 {
+			m.Sortino = round4(mean / downside * annualize)
+		}
+	}
+
+	return m
+}
+
+// MaxDrawdown 按交易顺序计算最大回撤（%，以权益峰值为基准）
+func MaxDrawdown(initial float64, pnls []float64) float64 {
+	equity, peak, maxDD := initial, initial, 0.0
+	for _, pnl := range pnls {
+		equity += pnl
+		if equity > peak {
+			peak = equity
+		}
+		if peak > 0 {
+			maxDD = math.Max(maxDD, (peak-equity)/peak*100)
+		}
+	}
+	return maxDD
+}
+
+// maxDrawdownAmount 按交易顺序计算最大回撤金额
+func maxDrawdownAmount(initial float64, pnls []float64) float64 {
+	equity, peak, maxDD := initial, initial, 0.0
+	for _, pnl := range pnls {
+		equity += pnl
+		peak = math.Max(peak, equity)
+		maxDD = math.Max(maxDD, peak-equity)
+	}
+	return maxDD
+}
+
+// dailyReturns 按出场日期（loc 时区）汇总的日收益率（区间内没有交易的日期收益率为0）
+func dailyReturns(sorted []Trade, initial float64, start, end time.Time, loc *time.Location) []float64 {
+	day := func(t time.Time) time.Time {
+		t = t.In(loc)
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+	first, last := day(start), day(end)
+	if last.Before(first) {
+		return nil
+	}
+
+	pnlByDay := make(map[time.Time]float64)
+	for _, t := range sorted {
+		pnlByDay[day(t.ExitTime)] += t.NetPnL
+	}
+
+	var returns []float64
+	equity := initial
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		if equity <= 0 {
+			break
+		}
+		pnl := pnlByDay[d]
+		returns = append(returns, pnl/equity)
+		equity += pnl
+	}
+	return returns
+}
+
+// downsideDeviation 下行偏差（只计负收益，分母为全部样本数）
+func downsideDeviation(returns []float64) float64 {
+	sum := 0.0
+	for _, r := range returns {
+		if r < 0 {
+			sum += r * r
+		}
+	}
+	return math.Sqrt(sum / float64(len(returns)))
+}
+
+// exposure 持仓时间占统计区间的比例（合并重叠的持仓区间）
+func exposure(trades []Trade, start, end time.Time) float64 {
+	total := end.Sub(start)
+	if total <= 0 {
+		return 0
+	}
+
+	intervals := make([][2]time.Time, 0, len(trades))
+	for _, t := range trades {
+		if t.ExitTime.After(t.EntryTime) {
+			intervals = append(intervals, [2]time.Time{t.EntryTime, t.ExitTime})
+		}
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i][0].Before(intervals[j][0]) })
+
+	var held time.Duration
+	var curStart, curEnd time.Time
+	for i, iv := range intervals {
+		if i == 0 || iv[0].After(curEnd) {
+			held += curEnd.Sub(curStart)
+			curStart, curEnd = iv[0], iv[1]
+			continue
+		}
+		if iv[1].After(curEnd) {
+			curEnd = iv[1]
+		}
+	}
+	held += curEnd.Sub(curStart)
+
+	return math.Min(held.Seconds()/total.Seconds(), 1)
+}
+
+// meanStd 均值和总体标准差
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// round4 保留四位小数
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
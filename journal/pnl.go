@@ -0,0 +1,89 @@
+/*
+Package journal 盈亏与费用计算（实盘日志和回测共用）
+
+主要功能：
+- GrossPnL(side string, qty, entryPrice, exitPrice float64) float64           // 价格盈亏
+- InverseGrossPnL(side string, contracts, contractSize, entryPrice, exitPrice float64) float64  // 币本位合约价格盈亏（以标的币种计）
+- FundingPayment(side string, qty, markPrice, rate float64) float64           // 单次资金费（正数为支付）
+- FundingCost(side string, qty, price float64, rates []binance.FundingRate, from, to time.Time) float64  // 持仓期间的资金费合计
+- (f FeeModel) Commission(notional float64, maker bool) float64               // 按费率计算手续费
+- (t *Trade) Finalize()                                                       // 计算净盈亏
+*/
+package journal
+
+import (
+	"time"
+
+	"crypto-ai-trader/binance"
+)
+
+// FeeModel 手续费费率
+type FeeModel struct {
+	MakerRate float64 `json:"maker_rate" yaml:"maker_rate"` // Maker费率（如0.0002表示0.02%）
+	TakerRate float64 `json:"taker_rate" yaml:"taker_rate"` // Taker费率
+}
+
+// DefaultFeeModel U本位合约普通用户费率（Maker 0.02%，Taker 0.05%）
+var DefaultFeeModel = FeeModel{MakerRate: 0.0002, TakerRate: 0.0005}
+
+// Commission 按费率计算手续费
+func (f FeeModel) Commission(notional float64, maker bool) float64 {
+	if maker {
+		return notional * f.MakerRate
+	}
+	return notional * f.TakerRate
+}
+
+// GrossPnL 价格盈亏（不含手续费和资金费）
+func GrossPnL(side string, qty, entryPrice, exitPrice float64) float64 {
+	if side == binance.SideSell {
+		return (entryPrice - exitPrice) * qty
+	}
+	return (exitPrice - entryPrice) * qty
+}
+
+// InverseGrossPnL 币本位（反向）合约价格盈亏，以标的币种计
+// 盈亏 = 张数 × 面值 × (1/入场价 - 1/出场价)，做空取反
+func InverseGrossPnL(side string, contracts, contractSize, entryPrice, exitPrice float64) float64 {
+	if entryPrice <= 0 || exitPrice <= 0 {
+		return 0
+	}
+	pnl := contracts * contractSize * (1/entryPrice - 1/exitPrice)
+	if side == binance.SideSell {
+		return -pnl
+	}
+	return pnl
+}
+
+// FundingPayment 单次资金费
+// 资金费率为正时多头支付、空头收取；返回正数表示支付，负数表示收取
+func FundingPayment(side string, qty, markPrice, rate float64) float64 {
+	payment := qty * markPrice * rate
+	if side == binance.SideSell {
+		return -payment
+	}
+	return payment
+}
+
+// FundingCost 持仓期间 (from, to] 内所有资金费结算的合计
+// price: 用于估算名义价值的价格（通常为入场价）
+func FundingCost(side string, qty, price float64, rates []binance.FundingRate, from, to time.Time) float64 {
+	fromMs, toMs := from.UnixMilli(), to.UnixMilli()
+
+	var total float64
+	for i := range rates {
+		if rates[i].FundingTime <= fromMs || rates[i].FundingTime > toMs {
+			continue
+		}
+		total += FundingPayment(side, qty, price, rates[i].RateFloat())
+	}
+	return total
+}
+
+// Finalize 计算价格盈亏（未设置时）和净盈亏
+func (t *Trade) Finalize() {
+	if t.GrossPnL == 0 && t.EntryPrice > 0 && t.ExitPrice > 0 {
+		t.GrossPnL = GrossPnL(t.Side, t.Quantity, t.EntryPrice, t.ExitPrice)
+	}
+	t.NetPnL = t.GrossPnL - t.Commission - t.Funding
+}
@@ -0,0 +1,211 @@
+/*
+Package journal 交易日志与交易所资金流水对账
+
+主要功能：
+- Reconcile(client *binance.Client, j *Journal, accountID string, from, to time.Time, tolerance float64) (*Reconciliation, error)  // 对账
+- RunReconciler(ctx context.Context, client *binance.Client, j *Journal, accountID string, interval, window time.Duration, tolerance float64)  // 定时对账
+
+对账口径（按交易对汇总）：
+- 价格盈亏：日志 GrossPnL  ↔ 流水 REALIZED_PNL
+- 手续费：  日志 Commission ↔ 流水 COMMISSION 取反
+- 资金费：  日志 Funding    ↔ 流水 FUNDING_FEE 取反
+日志按出场时间落在 [from, to] 内的交易统计；手动交易、未被括号订单覆盖的持仓会导致差异。
+*/
+package journal
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// PnLBreakdown 盈亏构成
+type PnLBreakdown struct {
+	GrossPnL   float64 `json:"gross_pnl"`  // 价格盈亏
+	Commission float64 `json:"commission"` // 手续费（正数为支付）
+	Funding    float64 `json:"funding"`    // 资金费（正数为支付）
+	NetPnL     float64 `json:"net_pnl"`    // 净盈亏
+}
+
+// ReconcileLine 单个交易对的对账结果
+type ReconcileLine struct {
+	Symbol   string       `json:"symbol"`   // 交易对
+	Local    PnLBreakdown `json:"local"`    // 本地交易日志
+	Exchange PnLBreakdown `json:"exchange"` // 交易所资金流水
+	Matched  bool         `json:"matched"`  // 各项差异是否都在容差内
+}
+
+// Reconciliation 对账结果
+type Reconciliation struct {
+	AccountID string          `json:"account_id"` // 账号ID
+	From      time.Time       `json:"from"`       // 开始时间
+	To        time.Time       `json:"to"`         // 结束时间
+	Tolerance float64         `json:"tolerance"`  // 容差（USDT）
+	Lines     []ReconcileLine `json:"lines"`      // 按交易对的对账结果
+	Local     PnLBreakdown    `json:"local"`      // 本地合计
+	Exchange  PnLBreakdown    `json:"exchange"`   // 交易所合计
+	Matched   bool            `json:"matched"`    // 是否全部一致
+}
+
+// Reconcile 对比本地交易日志与交易所资金流水
+// tolerance: 每项允许的绝对差异（USDT）
+func Reconcile(client *binance.Client, j *Journal, accountID string, from, to time.Time, tolerance float64) (*Reconciliation, error) {
+	trades, err := j.Load(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	incomes, err := client.GetIncomeHistory("", "", from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+
+	local := make(map[string]*PnLBreakdown)
+	exchange := make(map[string]*PnLBreakdown)
+	get := func(m map[string]*PnLBreakdown, symbol string) *PnLBreakdown {
+		if m[symbol] == nil {
+			m[symbol] = &PnLBreakdown{}
+		}
+		return m[symbol]
+	}
+
+	for _, t := range trades {
+		if t.ExitTime.Before(from) || t.ExitTime.After(to) {
+			continue
+		}
+		b := get(local, t.Symbol)
+		b.GrossPnL += t.GrossPnL
+		b.Commission += t.Commission
+		b.Funding += t.Funding
+	}
+
+	for i := range incomes {
+		in := &incomes[i]
+		if in.Symbol == "" {
+			continue
+		}
+		switch in.IncomeType {
+		case binance.IncomeTypeRealizedPnL:
+			get(exchange, in.Symbol).GrossPnL += in.IncomeFloat()
+		case binance.IncomeTypeCommission:
+			get(exchange, in.Symbol).Commission -= in.IncomeFloat()
+		case binance.IncomeTypeFundingFee:
+			get(exchange, in.Symbol).Funding -= in.IncomeFloat()
+		}
+	}
+
+	symbols := make(map[string]bool)
+	for s := range local {
+		symbols[s] = true
+	}
+	for s := range exchange {
+		symbols[s] = true
+	}
+
+	result := &Reconciliation{
+		AccountID: accountID,
+		From:      from,
+		To:        to,
+		Tolerance: tolerance,
+		Matched:   true,
+	}
+	for symbol := range symbols {
+		line := ReconcileLine{Symbol: symbol}
+		if b := local[symbol]; b != nil {
+			line.Local = *b
+		}
+		if b := exchange[symbol]; b != nil {
+			line.Exchange = *b
+		}
+		line.Local.finalize()
+		line.Exchange.finalize()
+		line.Matched = line.Local.within(line.Exchange, tolerance)
+
+		result.Local.add(line.Local)
+		result.Exchange.add(line.Exchange)
+		result.Matched = result.Matched && line.Matched
+		result.Lines = append(result.Lines, line)
+	}
+	sort.Slice(result.Lines, func(a, b int) bool { return result.Lines[a].Symbol < result.Lines[b].Symbol })
+
+	return result, nil
+}
+
+// RunReconciler 定时对账（每隔interval对最近window时间段对账），直到ctx取消
+// 差异超过容差时输出警告日志
+func RunReconciler(ctx context.Context, client *binance.Client, j *Journal, accountID string, interval, window time.Duration, tolerance float64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			to := time.Now()
+			result, err := Reconcile(client, j, accountID, to.Add(-window), to, tolerance)
+			if err != nil {
+				utils.Error("对账失败", zap.String("account_id", accountID), zap.Error(err))
+				continue
+			}
+			logReconciliation(result)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// logReconciliation 输出对账结果
+func logReconciliation(r *Reconciliation) {
+	if r.Matched {
+		utils.Info("对账一致",
+			zap.String("account_id", r.AccountID),
+			zap.Int("symbols", len(r.Lines)),
+			zap.Float64("net_pnl", r.Exchange.NetPnL),
+		)
+		return
+	}
+
+	for _, line := range r.Lines {
+		if line.Matched {
+			continue
+		}
+		utils.Warn("对账差异",
+			zap.String("account_id", r.AccountID),
+			zap.String("symbol", line.Symbol),
+			zap.String("gross_pnl", diffString(line.Local.GrossPnL, line.Exchange.GrossPnL)),
+			zap.String("commission", diffString(line.Local.Commission, line.Exchange.Commission)),
+			zap.String("funding", diffString(line.Local.Funding, line.Exchange.Funding)),
+		)
+	}
+}
+
+// finalize 计算净盈亏
+func (b *PnLBreakdown) finalize() {
+	b.NetPnL = b.GrossPnL - b.Commission - b.Funding
+}
+
+// add 累加
+func (b *PnLBreakdown) add(other PnLBreakdown) {
+	b.GrossPnL += other.GrossPnL
+	b.Commission += other.Commission
+	b.Funding += other.Funding
+	b.NetPnL += other.NetPnL
+}
+
+// within 各项差异是否都在容差内
+func (b PnLBreakdown) within(other PnLBreakdown, tolerance float64) bool {
+	return math.Abs(b.GrossPnL-other.GrossPnL) <= tolerance &&
+		math.Abs(b.Commission-other.Commission) <= tolerance &&
+		math.Abs(b.Funding-other.Funding) <= tolerance
+}
+
+// diffString 格式化差异：本地 / 交易所 (差值)
+func diffString(local, exchange float64) string {
+	return fmt.Sprintf("%.4f / %.4f (%+.4f)", local, exchange, local-exchange)
+}
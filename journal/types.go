@@ -0,0 +1,45 @@
+/*
+Package journal 交易日志数据结构定义
+
+主要功能：
+- Trade    // 一笔已结束的交易（含手续费和资金费）
+- Summary  // 交易汇总（笔数、胜率、价格盈亏、费用和净盈亏）
+*/
+package journal
+
+import "time"
+
+// Trade 一笔已结束的交易
+// 盈亏口径：NetPnL = GrossPnL - Commission - Funding
+type Trade struct {
+	AccountID   string    `json:"account_id"`            // 账号ID
+	Symbol      string    `json:"symbol"`                // 交易对
+	Side        string    `json:"side"`                  // 入场方向（BUY开多 / SELL开空）
+	Quantity    float64   `json:"quantity"`              // 数量（币本位合约为张数）
+	Asset       string    `json:"asset"`                 // 盈亏结算资产（U本位为USDT，币本位为标的币种）
+	EntryPrice  float64   `json:"entry_price"`           // 入场均价
+	ExitPrice   float64   `json:"exit_price"`            // 出场均价
+	StopLoss    float64   `json:"stop_loss,omitempty"`   // 止损价（离场时生效的止损价）
+	TakeProfit  float64   `json:"take_profit,omitempty"` // 止盈价
+	EntryTime   time.Time `json:"entry_time"`            // 入场时间
+	ExitTime    time.Time `json:"exit_time"`             // 出场时间
+	GrossPnL    float64   `json:"gross_pnl"`             // 价格盈亏（不含费用）
+	Commission  float64   `json:"commission"`            // 手续费（入场 + 出场）
+	Funding     float64   `json:"funding"`               // 资金费（正数为支付，负数为收取）
+	NetPnL      float64   `json:"net_pnl"`               // 净盈亏
+	CloseReason string    `json:"close_reason"`          // 结束原因
+	DecisionID  string    `json:"decision_id"`           // 决策哈希
+	Note        string    `json:"note"`                  // 备注
+}
+
+// Summary 交易汇总
+type Summary struct {
+	Trades     int     `json:"trades"`     // 交易笔数
+	Wins       int     `json:"wins"`       // 盈利笔数（按净盈亏）
+	GrossPnL   float64 `json:"gross_pnl"`  // 价格盈亏合计
+	Commission float64 `json:"commission"` // 手续费合计
+	Funding    float64 `json:"funding"`    // 资金费合计
+	NetPnL     float64 `json:"net_pnl"`    // 净盈亏合计
+	WinRate    float64 `json:"win_rate"`   // 胜率（0-1）
+	FeeDrag    float64 `json:"fee_drag"`   // 费用占价格盈亏的比例（价格盈亏为正时有效）
+}
@@ -2,27 +2,87 @@
 Package main 加密货币AI交易系统主程序
 
 主要功能：
-- 初始化系统（日志、配置、币安客户端）
+- 初始化系统（日志、配置、交易所客户端：币安或OKX）
 - 获取交易对池
 - 创建OI缓存管理器
-- 启动定时任务（短线5分钟、长线15分钟更新OI）
-- 计算指标并输出JSON数据
+- 按账号配置的策略名称创建策略实例（strategy包注册表）
+- 启动时检查各账号的持仓模式、保证金模式、杠杆，与配置不一致时修正或退出
+- 创建交易日志（记录扣除手续费和资金费后的净盈亏），可选与交易所资金流水定时对账
+- 启动定时任务（按各策略的运行周期，如短线5分钟、长线15分钟，账号可配置自己的分析周期和更短的持仓管理周期）
+- 计算指标，按账号选择的提示词模板（configs/prompts/*.tmpl，修改后自动重新加载）生成AI提示词，附带由指标数据生成的多周期趋势摘要
+- 账号可配置提示词中的指标数据使用紧凑格式（短字段名、低精度、省略空值），减少token
+- 账号可配置提示词中的指标数据只列出与上次相比变化明显的字段（按字段配置阈值，定期发送完整数据），也可把指标变化写入日志
+- 启用AI时发送提示词给AI分析；排名模式先用一个提示词从交易对池中挑选候选，只详细分析候选
+- 两阶段分析模式先请AI输出市场分析，再结合分析结论和账户状态输出决策
+- 投票模式对输出决策的调用独立采样多次，多数动作一致时才执行
+- 启用工具调用时AI可在决策前请求额外行情数据（K线、订单簿深度、资金费率历史）
+- 启用决策缓存时，交易对的指标数据（按有效数字取整）与上次相同则复用上次决策，不再调用AI
+- 启用风控否决时，开仓决策违反硬性约束（ADX过低、资金费率不利、价差过大、禁止开仓时间段）则否决或缩减仓位
+- AI输出的决策交给执行器执行，每次分析的各阶段提示词、回复、决策和执行结果写入审计记录
+- 按审计记录和交易日志统计AI置信度与实际胜率的校准曲线（可定时生成报告）
+- 影子账号（shadow.enabled 或 mode: paper）不下真实订单，决策按最新价格模拟成交并写入交易日志
+- 观察账号（mode: observe）计算指标、请求AI决策并写入审计记录，不调用任何账户和下单接口
+- 账号配置了低流动性时段（flatten）时，在时段开始前按配置平仓或减仓并告警，可选在时段内不再开仓
+- 账号配置了每日定时平仓（flat_time）时，每天定时平掉全部持仓、撤销全部挂单，可选到恢复时间前不再开仓
+- 每日定时平仓、低流动性时段、绩效指标的每日边界和提示词中的时间按配置的时区（timezone，默认UTC）
+- 可选启动状态API（账号状态、跨账号汇总敞口）
+- 状态API按交易对、时间周期、时间范围查询审计记录中的历史指标快照和持仓量、资金费率序列（供看板、回测使用）
+- 紧急平仓（撤销全部挂单并市价平掉所选账号的全部持仓）：命令行 close-all 子命令、状态API、Telegram命令，均需确认
+- 不重启轮换账号的API密钥：状态API提交新密钥，或修改账号配置后发送SIGHUP
+- 状态API停用/恢复账号（停用后不再开仓，已有持仓继续管理或立即平仓），状态保存到交易日志目录，重启后保持
+- 状态API向全部账号或单个账号的交易对池加入（按交易规则检查）、移除交易对，调整保存到交易日志目录，重启后保持
+- 可选输入数据过期检查：调用AI前和执行前检查K线、持仓量、资金费率的获取时间，过期时跳过该交易对
+- 可选交易对错误熔断：连续多个周期获取K线、计算指标或获取持仓量失败的交易对暂时跳过，到期后探测恢复
+- 默认只用已收盘K线计算指标（按策略可改为包含未收盘K线），指标数据同时给出最近已收盘K线的收盘价和未收盘K线的最新价
 */
 package main
 
 import (
+	"bufio"
+	"context"
+	"crypto-ai-trader/ai"
+	"crypto-ai-trader/alert"
 	"crypto-ai-trader/binance"
 	"crypto-ai-trader/config"
+	"crypto-ai-trader/emergency"
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/executor"
 	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/journal"
+	"crypto-ai-trader/marketdata"
+	"crypto-ai-trader/okx"
+	"crypto-ai-trader/portfolio"
+	"crypto-ai-trader/prompt"
+	"crypto-ai-trader/scanner"
+	"crypto-ai-trader/scheduler"
+	"crypto-ai-trader/schemas"
+	"crypto-ai-trader/secrets"
+	"crypto-ai-trader/server"
+	"crypto-ai-trader/strategy"
+	"crypto-ai-trader/symbolpool"
+	"crypto-ai-trader/telegram"
+	"crypto-ai-trader/toggle"
+	"crypto-ai-trader/treasury"
 	"crypto-ai-trader/utils"
+	"crypto-ai-trader/veto"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"math"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // 内置时区数据（运行环境没有系统时区数据时 timezone 仍然可用）
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
 func main() {
@@ -33,6 +93,13 @@ func main() {
 	}
 	defer utils.Sync()
 
+	// 紧急平仓子命令：crypto-ai-trader close-all [-accounts a,b] [-yes]
+	if len(os.Args) > 1 && os.Args[1] == "close-all" {
+		code := closeAllCommand(os.Args[2:])
+		utils.Sync()
+		os.Exit(code)
+	}
+
 	utils.Info("=== 加密货币AI交易系统启动 ===")
 
 	// 2. 加载配置
@@ -41,6 +108,11 @@ func main() {
 		utils.Error("加载配置失败", zap.Error(err))
 		os.Exit(1)
 	}
+	// 账号配置中的密钥引用（env:、file:、vault:）替换为密钥值
+	if err := resolveSecrets(cfg, cfg.Accounts); err != nil {
+		utils.Error("读取账号密钥失败", zap.Error(err))
+		os.Exit(1)
+	}
 	utils.Info("配置加载成功",
 		zap.Int("accounts", len(cfg.Accounts)),
 		zap.String("futures_url", cfg.Binance.FuturesURL),
@@ -68,224 +140,3308 @@ func main() {
 	oiCacheManager := utils.NewOICacheManager(5)
 	utils.Info("OI缓存管理器创建完成")
 
-	// 5. 为每个账号创建币安客户端
-	clients := make(map[string]*binance.Client)
+	// 5. 创建交易日志（所有账号共用，按账号分文件）
+	journalCfg := cfg.GetJournalConfig()
+	tradeJournal, err := journal.New(journalCfg.Dir)
+	if err != nil {
+		utils.Error("创建交易日志失败", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// 提示词模板（所有账号共用，修改模板文件后自动重新加载）
+	promptsCfg := cfg.GetPromptsConfig()
+	prompts, err := prompt.NewStore(promptsCfg.Dir)
+	if err != nil {
+		utils.Error("加载提示词模板失败", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// AI客户端（所有账号共用，未启用时只生成提示词）
+	// 每次AI分析的提示词、回复、决策和执行结果写入审计记录
+	var aiClient *ai.Client
+	var aiAudit *ai.Audit
+	if aiCfg := cfg.GetAIConfig(); aiCfg.Enabled {
+		aiClient = ai.NewClient(aiCfg, cfg.GetProxyURL())
+		if aiAudit, err = ai.NewAudit(aiCfg.AuditDir); err != nil {
+			utils.Error("创建AI审计记录失败", zap.Error(err))
+			os.Exit(1)
+		}
+	}
+
+	// 行情告警（所有账号共用，同一交易对同一类告警在冷却期内只发出一次）
+	// 异动检测覆盖整个交易对池，没有持仓的交易对也会告警
+	var notifier *alert.Notifier
+	var anomaly *alert.AnomalyDetector
+	var fundingFlips, premiums *alert.StateTracker
+	premiumCfg := cfg.GetAlertsConfig().Premium
+	if alertsCfg := cfg.GetAlertsConfig(); alertsCfg.Enabled {
+		notifier = alert.NewNotifier(alertsCfg, cfg.GetProxyURL())
+		if alertsCfg.Anomaly.Enabled {
+			anomaly = alert.NewAnomalyDetector(alertsCfg.Anomaly)
+		}
+		if alertsCfg.FundingFlip {
+			fundingFlips = alert.NewStateTracker()
+		}
+		if alertsCfg.Premium.Enabled {
+			premiums = alert.NewStateTracker()
+		}
+	}
+	// 持仓量与价格关系按账号跟踪（只对账号持仓中的交易对告警）
+	oiPriceStates := func() *alert.StateTracker {
+		if notifier == nil || !cfg.GetAlertsConfig().OIPrice {
+			return nil
+		}
+		return alert.NewStateTracker()
+	}
+
+	// 共享行情数据服务（同一交易所、市场类型的账号共用，每份数据每个周期只请求一次）
+	marketPool := marketdata.NewPool(time.Duration(cfg.GetMarketDataConfig().TTLSec) * time.Second)
+
+	// WebSocket行情推送（按合约市场类型，有币安合约账号使用时才创建，账号共用）
+	// 全市场标记价格；全市场强平（只支持U本位合约）；入场中和持仓中交易对的本地订单簿
+	// 标记价格和订单簿使用第一个使用该市场类型的账号的客户端，在（重新）连接后通过REST接口补齐数据
+	streamsCfg := cfg.GetStreamsConfig()
+	streamBaseURL := func(marketType string) string {
+		if marketType == binance.MarketTypeCoinM {
+			return streamsCfg.DeliveryURL
+		}
+		return streamsCfg.FuturesURL
+	}
+	markPrices := make(map[string]*binance.MarkPriceStream)
+	markPriceStream := func(marketType string, client *binance.Client) *binance.MarkPriceStream {
+		if !streamsCfg.MarkPrice.Enabled || marketType == binance.MarketTypeSpot {
+			return nil
+		}
+		if stream, ok := markPrices[marketType]; ok {
+			return stream
+		}
+		stream := binance.NewMarkPriceStream(client, streamBaseURL(marketType), cfg.GetProxyURL(), time.Duration(streamsCfg.MarkPrice.MaxAgeSec)*time.Second)
+		markPrices[marketType] = stream
+		return stream
+	}
+	var liquidations *binance.LiquidationStream
+	liquidationStream := func(account *config.Account) *binance.LiquidationStream {
+		liqCfg := streamsCfg.Liquidation
+		if !liqCfg.Enabled || account.GetExchange() != exchange.NameBinance || account.GetMarketType() != binance.MarketTypeUSDTM {
+			return nil
+		}
+		if liquidations == nil {
+			windows := make([]time.Duration, len(liqCfg.WindowsMinutes))
+			for i, minutes := range liqCfg.WindowsMinutes {
+				windows[i] = time.Duration(minutes) * time.Minute
+			}
+			liquidations = binance.NewLiquidationStream(streamsCfg.FuturesURL, cfg.GetProxyURL(), windows, binance.CascadeRule{
+				Window:         time.Duration(liqCfg.Cascade.WindowMinutes) * time.Minute,
+				SymbolNotional: liqCfg.Cascade.SymbolNotionalUSDT,
+				MarketNotional: liqCfg.Cascade.MarketNotionalUSDT,
+			})
+		}
+		return liquidations
+	}
+	depthBooks := make(map[string]*binance.DepthStream)
+	depthStream := func(marketType string, client *binance.Client) *binance.DepthStream {
+		if !streamsCfg.Depth.Enabled || marketType == binance.MarketTypeSpot {
+			return nil
+		}
+		if books, ok := depthBooks[marketType]; ok {
+			return books
+		}
+		books := binance.NewDepthStream(client, streamBaseURL(marketType), cfg.GetProxyURL(), streamsCfg.Depth.MaxStreamsPerConnection)
+		depthBooks[marketType] = books
+		return books
+	}
+	// 秒级增量指标（由标记价格推送的1分钟K线驱动，按合约市场类型）
+	liveIndicators := make(map[string]*indicators.LiveTracker)
+	liveTracker := func(marketType string, client *binance.Client) *indicators.LiveTracker {
+		stream := markPriceStream(marketType, client)
+		if !streamsCfg.Indicators.Enabled || stream == nil {
+			return nil
+		}
+		if tracker, ok := liveIndicators[marketType]; ok {
+			return tracker
+		}
+		live := streamsCfg.Indicators
+		tracker := indicators.NewLiveTracker(stream, client, live.EMAPeriods, live.RSIPeriod, live.ATRPeriod)
+		liveIndicators[marketType] = tracker
+		return tracker
+	}
+
+	// 成交量异动筛选（交易对池之外的临时候选，只支持币安U本位合约，使用第一个需要候选的账号的客户端）
+	screenerCfg := cfg.GetScreenerConfig()
+	var volumeScreener *scanner.VolumeScreener
+	spikeScreener := func(account *config.Account, client *binance.Client) *scanner.VolumeScreener {
+		volCfg := screenerCfg.Volume
+		if !volCfg.Enabled || client == nil || account.GetMarketType() != binance.MarketTypeUSDTM ||
+			!slices.Contains(volCfg.Strategies, account.Strategy) {
+			return nil
+		}
+		if volumeScreener == nil {
+			volumeScreener = scanner.NewVolumeScreener(client, scanner.VolumeConfig{
+				Interval:       volCfg.Interval,
+				Lookback:       volCfg.Lookback,
+				Multiple:       volCfg.Multiple,
+				Universe:       volCfg.Universe,
+				MinQuoteVolume: volCfg.MinQuoteVolume,
+				Exclude:        cfg.SymbolPool.ExcludeSymbols,
+			})
+		}
+		return volumeScreener
+	}
+
+	// 拉盘/砸盘筛选（每个策略周期用已计算的指标筛选交易对池，报告按账号保存）
+	// 配置了单独的推送地址时使用单独的告警通知（冷却等设置与行情告警相同）
+	var pumpDump *scanner.PumpDumpScreener
+	var pumpAlerts, pumpNotifier *alert.Notifier
+	if pdCfg := screenerCfg.PumpDump; pdCfg.Enabled {
+		pumpDump = scanner.NewPumpDumpScreener(scanner.PumpDumpConfig{
+			MinReturnPct:   pdCfg.MinReturnPct,
+			MinOIChangePct: pdCfg.MinOIChangePct,
+			TopN:           pdCfg.TopN,
+		})
+		switch {
+		case pdCfg.Notify && pdCfg.WebhookURL != "":
+			alertsCfg := cfg.GetAlertsConfig()
+			alertsCfg.WebhookURL = pdCfg.WebhookURL
+			pumpNotifier = alert.NewNotifier(alertsCfg, cfg.GetProxyURL())
+			pumpAlerts = pumpNotifier
+		case pdCfg.Notify:
+			pumpAlerts = notifier
+		}
+	}
+
+	// 交易所系统状态与维护检测（只支持币安：系统状态通过现货域名查询，交易对状态按账号的市场类型检查）
+	var exchangeStatus *binance.StatusMonitor
+	if cfg.GetStatusConfig().Enabled {
+		exchangeStatus = binance.NewStatusMonitor(binance.NewSpotClient("", "", cfg.Binance.SpotURL, cfg.GetProxyURL()))
+	}
+
+	// 检查币安API密钥权限，有账号不通过时输出每个账号的报告并退出（不等到第一次签名请求才失败）
+	if cfg.KeyCheck.Enabled && !checkAPIKeys(cfg) {
+		os.Exit(1)
+	}
+
+	// 6. 为每个账号创建币安客户端和策略
+	// 运行时停用的账号（状态API）保存在交易日志目录，重启后保持停用
+	accountToggles := toggle.New(journalCfg.Dir)
+	// 运行时加入、移除的交易对（状态API）保存在交易日志目录，重启后保持
+	symbolOverrides := symbolpool.New(journalCfg.Dir)
+	var runners []*accountRunner
 	for _, account := range cfg.GetEnabledAccounts() {
-		client := binance.NewClient(
-			cfg.Binance.FuturesURL,
-			account.APIKey,
-			account.APISecret,
-			cfg.GetProxyURL(),
-		)
-		clients[account.ID] = client
-		utils.Info("创建币安客户端",
+		// 按交易所和市场类型创建客户端：币本位合约交易对转换为币本位永续合约（BTCUSDT → BTCUSD_PERP），现货只做多
+		var client *binance.Client
+		var market exchange.Exchange
+		accountSymbols := symbols
+		switch {
+		case account.GetExchange() == exchange.NameOKX:
+			okxClient := okx.NewClient(account.APIKey, account.APISecret, account.Passphrase, cfg.OKX.BaseURL, cfg.GetProxyURL())
+			okxClient.SetSimulated(cfg.OKX.Simulated)
+			market = exchange.NewOKX(okxClient)
+		case account.GetMarketType() == binance.MarketTypeCoinM:
+			client = binanceClient(cfg, &account)
+			accountSymbols = make([]string, len(symbols))
+			for i, s := range symbols {
+				accountSymbols[i] = binance.CoinMSymbol(s)
+			}
+		default:
+			client = binanceClient(cfg, &account)
+		}
+		if client != nil {
+			market = exchange.NewBinance(client)
+		}
+		// 持仓设置检查、增量指标和交易对状态包含运行时加入的交易对
+		poolSymbols := symbolOverrides.Apply(account.ID, accountSymbols, symbolConverter(account))
+
+		strat, err := strategy.New(account.Strategy)
+		if err != nil {
+			utils.Error("创建策略失败", zap.String("account_id", account.ID), zap.Error(err))
+			os.Exit(1)
+		}
+		if err := strat.Init(account.StrategyParams); err != nil {
+			utils.Error("初始化策略失败", zap.String("account_id", account.ID), zap.Error(err))
+			os.Exit(1)
+		}
+
+		promptTemplate := prompts.Lookup(account.GetPromptTemplates()...)
+		if promptTemplate == "" {
+			utils.Error("提示词模板不存在",
+				zap.String("account_id", account.ID),
+				zap.Strings("candidates", account.GetPromptTemplates()),
+				zap.Strings("available", prompts.Names()),
+			)
+			os.Exit(1)
+		}
+		if ranking := cfg.GetRankingConfig(account.Strategy); ranking.Enabled && prompts.Lookup(ranking.Template) == "" {
+			utils.Error("排名提示词模板不存在", zap.String("account_id", account.ID), zap.String("template", ranking.Template))
+			os.Exit(1)
+		}
+		// AI工具调用由币安客户端提供数据
+		var tools []*ai.Tool
+		toolsCfg := cfg.GetToolsConfig(account.Strategy)
+		if toolsCfg.Enabled {
+			if client != nil {
+				tools = ai.MarketTools(client)
+			} else {
+				utils.Warn("AI工具调用只支持币安账号，已忽略", zap.String("account_id", account.ID))
+			}
+		}
+		// AI决策缓存（每个账号独立）
+		var decisionCache *ai.Cache
+		cacheCfg := cfg.GetDecisionCacheConfig(account.Strategy)
+		if cacheCfg.Enabled {
+			decisionCache = ai.NewCache(time.Duration(cacheCfg.MaxAgeMinutes) * time.Minute)
+		}
+		// 风控否决规则，价差由币安订单簿计算
+		vetoCfg := cfg.GetVetoConfig(account.Strategy)
+		var spread veto.SpreadFunc
+		if vetoCfg.Enabled && vetoCfg.MaxSpreadBps > 0 {
+			if client != nil {
+				spread = orderBookSpread(client)
+			} else {
+				utils.Warn("风控否决的价差规则只支持币安账号，已忽略", zap.String("account_id", account.ID))
+			}
+		}
+		twoStage := cfg.GetTwoStageConfig(account.Strategy)
+		configHash := decisionConfigHash(cfg, &account)
+		if twoStage.Enabled {
+			for _, name := range []string{twoStage.AnalysisTemplate, twoStage.DecisionTemplate} {
+				if prompts.Lookup(name) == "" {
+					utils.Error("两阶段分析提示词模板不存在", zap.String("account_id", account.ID), zap.String("template", name))
+					os.Exit(1)
+				}
+			}
+		}
+
+		// 分析周期（账号配置覆盖策略默认周期）和持仓管理周期
+		interval, manage := cycleIntervals(&account, strat)
+
+		// 执行器（入场方式按策略的执行配置），括号订单等执行逻辑目前只支持币安
+		// 影子账号只记录决策和模拟成交，不创建下单执行器（只使用公开行情接口，不检查持仓设置）
+		// 观察账号不创建任何执行器，决策只写入审计记录
+		// 决策有效期按策略运行周期计算
+		var exec *executor.Executor
+		var shadow *executor.ShadowExecutor
+		staleness := cfg.GetStalenessConfig(account.Strategy)
+		switch {
+		case account.Shadow.Enabled:
+			shadow = executor.NewShadowExecutor(account.ID, market, tradeJournal, journalCfg.Dir, account.GetShadowConfig().InitialBalance)
+			shadow.SetStaleness(staleness, staleness.TTL(interval))
+			if client != nil {
+				shadow.SetMarkPrices(markPriceStream(account.GetMarketType(), client))
+			}
+			if streamsCfg.Liquidation.Cascade.PauseEntries {
+				shadow.SetLiquidations(liquidationStream(&account))
+			}
+			if premiumCfg.PauseEntries {
+				shadow.SetPremiumGuard(premiumCfg.MaxPct)
+			}
+		case account.GetMode() == config.AccountModeObserve:
+		case client != nil:
+			exec = executor.NewExecutor(account.ID, client)
+			exec.SetExecutionConfig(cfg.GetExecutionConfig(account.Strategy))
+			exec.SetJournal(tradeJournal)
+			exec.SetSizing(account.GetSizingConfig())
+			exec.SetPyramiding(cfg.GetPyramidingConfig(account.Strategy))
+			exec.SetReentry(cfg.GetReentryConfig(account.Strategy))
+			exec.SetStaleness(staleness, staleness.TTL(interval))
+			exec.SetSymbolLimits(cfg.SymbolLimits)
+			exec.SetSectors(cfg.Sectors)
+			exec.SetMarkPrices(markPriceStream(account.GetMarketType(), client))
+			exec.SetOrderBooks(depthStream(account.GetMarketType(), client))
+			if streamsCfg.Liquidation.Cascade.PauseEntries {
+				exec.SetLiquidations(liquidationStream(&account))
+			}
+			if premiumCfg.PauseEntries && account.GetMarketType() != binance.MarketTypeSpot {
+				exec.SetPremiumGuard(premiumCfg.MaxPct)
+			}
+			// 熔断状态、括号订单与交易日志保存在同一目录
+			exec.SetCircuitBreaker(account.CircuitBreaker, journalCfg.Dir)
+			exec.LoadBrackets(journalCfg.Dir)
+
+			// 检查持仓模式、保证金模式、杠杆与配置是否一致
+			posCfg := cfg.GetPositionConfig(&account)
+			exec.SetMarginTopUp(posCfg.MarginTopUp)
+			if _, err := exec.Bootstrap(poolSymbols, posCfg); err != nil {
+				utils.Error("持仓设置检查失败", zap.String("account_id", account.ID), zap.Error(err))
+				os.Exit(1)
+			}
+		}
+
+		var live *indicators.LiveTracker
+		if client != nil {
+			live = liveTracker(account.GetMarketType(), client)
+			live.Track(poolSymbols)
+		}
+
+		var watcher *binance.StatusMonitor
+		if client != nil {
+			watcher = exchangeStatus
+			watcher.Watch(account.GetMarketType(), client, poolSymbols)
+		}
+
+		// 溢价告警只支持币安合约（优先使用标记价格推送）
+		var accountPremiums *alert.StateTracker
+		var marks *binance.MarkPriceStream
+		if client != nil && account.GetMarketType() != binance.MarketTypeSpot {
+			accountPremiums, marks = premiums, markPriceStream(account.GetMarketType(), client)
+		}
+
+		// 最长持仓时间：未配置分钟数时使用策略预期持仓时间的上限
+		holding := cfg.GetMaxHoldingConfig(account.Strategy)
+		var maxHolding time.Duration
+		if holding.Enabled {
+			if maxHolding = time.Duration(holding.Minutes) * time.Minute; maxHolding == 0 {
+				_, maxHolding = strat.HoldingTime()
+			}
+		}
+
+		// 输入数据过期检查（只在启用时生效）
+		var freshness time.Duration
+		if f := cfg.GetFreshnessConfig(account.Strategy); f.Enabled {
+			freshness = time.Duration(f.MaxAgeSec) * time.Second
+		}
+
+		// 指标变化：账号指标数据格式为delta或启用变化日志时跟踪
+		deltaCfg := cfg.GetDeltaConfig(account.Strategy)
+		var deltas *prompt.DeltaTracker
+		if account.IsDeltaPayload() || deltaCfg.Log {
+			deltas = prompt.NewDeltaTracker(deltaCfg)
+		}
+
+		// 低流动性时段（只在启用时生效）
+		flatten := account.GetFlattenConfig()
+		var windows []scheduler.Window
+		if flatten.Enabled {
+			windows = flattenWindows(flatten, cfg.GetLocation())
+		}
+		var flatTime *scheduler.Window
+		if account.FlatTime.Enabled {
+			flatTime = flatTimeWindow(account.FlatTime, cfg.GetLocation())
+		}
+
+		// 强平统计随持仓量、资金费率一起附加到市场数据
+		marketData := marketPool.Get(account.GetExchange()+"/"+account.GetMarketType(), market)
+		if stream := liquidationStream(&account); stream != nil {
+			marketData.SetLiquidations(exchange.NewBinanceLiquidations(stream))
+		}
+
+		runners = append(runners, &accountRunner{
+			accountID:   account.ID,
+			account:     account,
+			symbols:     accountSymbols,
+			client:      client,
+			market:      market,
+			marketData:  marketData,
+			strategy:    strat,
+			interval:    interval,
+			manage:      manage,
+			executor:    exec,
+			shadow:      shadow,
+			cooldown:    time.Duration(cfg.GetCooldownConfig(account.Strategy).Minutes) * time.Minute,
+			freshness:   freshness,
+			closedOnly:  !cfg.GetCandlesConfig(account.Strategy).IncludeForming,
+			maxHolding:  maxHolding,
+			holdAction:  holding.Action,
+			flatten:     flatten,
+			windows:     windows,
+			flatTime:    flatTime,
+			location:    cfg.GetLocation(),
+			toggles:     accountToggles,
+			pool:        symbolOverrides,
+			posCfg:      cfg.GetPositionConfig(&account),
+			live:        live,
+			keys:        accountKeys(cfg, account, client),
+			prompts:     prompts,
+			template:    promptTemplate,
+			budget:      promptsCfg.Budget,
+			deltas:      deltas,
+			deltaLog:    deltaCfg.Log,
+			ai:          aiClient,
+			ranking:     cfg.GetRankingConfig(account.Strategy),
+			twoStage:    twoStage,
+			voting:      cfg.GetVotingConfig(account.Strategy),
+			tools:       tools,
+			maxCalls:    toolsCfg.MaxCalls,
+			audit:       aiAudit,
+			cache:       decisionCache,
+			cacheDigits: cacheCfg.SignificantDigits,
+			veto:        veto.New(vetoCfg, spread),
+			configHash:  configHash,
+			model:       cfg.AI.Model,
+			calcBudget:  time.Duration(cfg.GetTelemetryConfig().CycleBudgetMs[account.Strategy]) * time.Millisecond,
+			alerts:      notifier,
+			anomaly:     anomaly,
+			flips:       fundingFlips,
+			oiStates:    oiPriceStates(),
+			premiums:    accountPremiums,
+			premium:     premiumCfg,
+			marks:       marks,
+			status:      watcher,
+			pumpDump:    pumpDump,
+			pumpAlerts:  pumpAlerts,
+			spikes:      spikeScreener(&account, client),
+			maxSpikes:   screenerCfg.Volume.MaxCandidates,
+			breaker:     symbolBreaker(cfg, account.ID),
+		})
+		minHold, maxHold := strat.HoldingTime()
+		utils.Info("创建交易所客户端",
 			zap.String("account_id", account.ID),
+			zap.String("exchange", account.GetExchange()),
 			zap.String("strategy", account.Strategy),
+			zap.String("market_type", account.GetMarketType()),
+			zap.String("mode", account.GetMode()),
+			zap.String("prompt_template", promptTemplate),
+			zap.String("config_hash", configHash),
+			zap.Strings("timeframes", strat.Timeframes()),
+			zap.Duration("interval", interval),
+			zap.Duration("manage_interval", manage),
+			zap.Duration("min_hold", minHold),
+			zap.Duration("max_hold", maxHold),
 		)
 	}
 
-	// 6. 启动定时任务
+	// 7. 启动定时任务（每个账号按策略的运行周期独立调度）
+	// 任一任务返回错误时取消其他任务并以该错误退出
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+
 	utils.Info("启动定时任务...")
-	
-	// 短线策略：每5分钟更新一次OI
-	shortTermTicker := time.NewTicker(5 * time.Minute)
-	defer shortTermTicker.Stop()
+	g.Go(func() error {
+		prompts.Watch(ctx, time.Duration(promptsCfg.ReloadSec)*time.Second)
+		return nil
+	})
+
+	for _, stream := range markPrices {
+		g.Go(func() error {
+			stream.Run(ctx)
+			return nil
+		})
+	}
+	if liquidations != nil {
+		g.Go(func() error {
+			liquidations.Run(ctx)
+			return nil
+		})
+	}
+	for _, books := range depthBooks {
+		g.Go(func() error {
+			books.Run(ctx)
+			return nil
+		})
+	}
+	if volumeScreener != nil {
+		g.Go(func() error {
+			volumeScreener.Run(ctx, time.Duration(screenerCfg.Volume.ScanIntervalSec)*time.Second)
+			return nil
+		})
+	}
+	if exchangeStatus != nil {
+		g.Go(func() error {
+			exchangeStatus.Run(ctx, time.Duration(cfg.GetStatusConfig().IntervalSec)*time.Second)
+			return nil
+		})
+	}
+	for _, n := range []*alert.Notifier{notifier, pumpNotifier} {
+		if n == nil {
+			continue
+		}
+		g.Go(func() error {
+			n.Run(ctx)
+			return nil
+		})
+	}
+	for _, tracker := range liveIndicators {
+		g.Go(func() error {
+			tracker.Run(ctx, time.Duration(streamsCfg.Indicators.IntervalSec)*time.Second)
+			return nil
+		})
+	}
 
-	// 长线策略：每15分钟更新一次OI
-	longTermTicker := time.NewTicker(15 * time.Minute)
-	defer longTermTicker.Stop()
+	// 低流动性时段前的平仓/减仓、每日定时平仓任务
+	jobs := accountJobs(runners)
+	if jobs.Len() > 0 {
+		g.Go(func() error {
+			jobs.Run(ctx, 30*time.Second)
+			return nil
+		})
+	}
 
-	// 立即执行一次
-	utils.Info("执行初始数据采集...")
-	for _, account := range cfg.GetEnabledAccounts() {
-		client := clients[account.ID]
-		if account.Strategy == "short_term" {
-			processShortTermStrategy(client, symbols, oiCacheManager, account.ID)
-		} else if account.Strategy == "long_term" {
-			processLongTermStrategy(client, symbols, oiCacheManager, account.ID)
+	for _, runner := range runners {
+		g.Go(func() error {
+			runner.run(ctx, oiCacheManager)
+			return nil
+		})
+
+		// 影子账号定时检查模拟持仓的止损止盈和资金费
+		if runner.shadow != nil {
+			g.Go(func() error {
+				runner.shadow.Monitor(ctx, 10*time.Second)
+				return nil
+			})
+		}
+
+		// 以下执行相关任务只支持币安实盘账号
+		if runner.executor == nil {
+			continue
+		}
+
+		// 括号订单监控（止损/止盈一边触发后撤销另一边）及逐仓保证金自动追加
+		g.Go(func() error {
+			runner.executor.Monitor(ctx, 10*time.Second)
+			return nil
+		})
+
+		// 交易日志与交易所资金流水定时对账（现货没有资金流水接口）
+		if rc := journalCfg.Reconcile; rc.Enabled && !runner.client.IsSpot() {
+			g.Go(func() error {
+				journal.RunReconciler(ctx, runner.client, tradeJournal, runner.accountID,
+					time.Duration(rc.IntervalMinutes)*time.Minute,
+					time.Duration(rc.WindowHours)*time.Hour,
+					rc.ToleranceUSDT,
+				)
+				return nil
+			})
+		}
+
+		// 合约账户资金自动调拨（通过同一组API密钥的现货客户端划转）
+		if runner.account.Treasury.Enabled {
+			if t := accountTreasury(cfg, runner); t != nil {
+				g.Go(func() error {
+					t.Run(ctx)
+					return nil
+				})
+			}
 		}
 	}
 
+	// 组合风险报告定时生成
+	riskCfg := cfg.GetRiskReportConfig()
+	if riskCfg.Enabled {
+		g.Go(func() error {
+			portfolio.RunRiskReports(ctx, portfolioAccounts(runners), klineMarket(runners), riskCfg)
+			return nil
+		})
+	}
+
+	// AI置信度校准报告定时生成
+	calibrationCfg := cfg.GetCalibrationConfig()
+	if calibrationCfg.Enabled && aiAudit != nil {
+		g.Go(func() error {
+			ai.RunCalibrationReports(ctx, aiAudit, tradeJournal, runnerIDs(runners), calibrationCfg)
+			return nil
+		})
+	}
+
+	// 指标计算耗时统计定时输出
+	if telemetryCfg := cfg.GetTelemetryConfig(); telemetryCfg.Enabled {
+		g.Go(func() error {
+			indicators.RunTelemetryLog(ctx, time.Duration(telemetryCfg.IntervalMinutes)*time.Minute)
+			return nil
+		})
+	}
+
+	// 紧急平仓（状态API和Telegram命令共用，记录写入交易日志目录）
+	panicSwitch := emergencySwitch(runners, journalCfg.Dir, notifier)
+	if tgCfg := cfg.GetTelegramConfig(); tgCfg.Enabled {
+		bot := telegram.NewBot(tgCfg, cfg.GetProxyURL())
+		registerTelegramCommands(bot, panicSwitch)
+		g.Go(func() error {
+			bot.Run(ctx)
+			return nil
+		})
+	}
+
+	// 状态API（账号状态、跨账号敞口、组合风险报告）
+	if apiCfg := cfg.GetAPIConfig(); apiCfg.Enabled {
+		srv := server.New(apiCfg)
+		registerRoutes(srv, runners, riskCfg, tradeJournal, aiAudit, calibrationCfg, marketPool, markPrices, depthBooks, liquidations, liveIndicators, notifier, volumeScreener, pumpDump, exchangeStatus, panicSwitch, cfg.GetLocation())
+		g.Go(func() error {
+			// 端口被占用等启动失败时返回错误，程序退出
+			return srv.Run(ctx)
+		})
+	}
+
 	// 监听系统信号
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
-	// 主循环
 	utils.Info("系统运行中，按 Ctrl+C 退出...")
+wait:
 	for {
 		select {
-		case <-shortTermTicker.C:
-			utils.Info("=== 短线策略定时任务触发 ===")
-			for _, account := range cfg.GetEnabledAccounts() {
-				if account.Strategy == "short_term" {
-					client := clients[account.ID]
-					processShortTermStrategy(client, symbols, oiCacheManager, account.ID)
-				}
+		case sig := <-sigChan:
+			if sig == syscall.SIGHUP {
+				// SIGHUP：重新读取账号配置，轮换API密钥有变化的账号
+				utils.Info("收到SIGHUP，重新读取账号API密钥")
+				reloadAPIKeys(cfg, runners)
+				continue
 			}
+			utils.Info("收到退出信号", zap.String("signal", sig.String()))
+			break wait
+		case <-ctx.Done():
+			// 有任务返回错误，其他任务已被取消
+			break wait
+		}
+	}
+	cancel()
+	if err := g.Wait(); err != nil {
+		utils.Error("任务运行失败，系统退出", zap.Error(err))
+		os.Exit(1)
+	}
+	utils.Info("=== 系统正常退出 ===")
+}
 
-		case <-longTermTicker.C:
-			utils.Info("=== 长线策略定时任务触发 ===")
-			for _, account := range cfg.GetEnabledAccounts() {
-				if account.Strategy == "long_term" {
-					client := clients[account.ID]
-					processLongTermStrategy(client, symbols, oiCacheManager, account.ID)
-				}
+// accountRunner 单个账号的策略运行器
+type accountRunner struct {
+	accountID   string
+	account     config.Account
+	symbols     []string            // 配置的交易对池（币本位账号已转换为币本位合约，运行时的调整见 pool）
+	client      *binance.Client     // 币安客户端（非币安账号为nil）
+	market      exchange.Exchange   // 交易所接口（组合持仓汇总使用）
+	marketData  exchange.MarketData // 共享行情数据服务（策略周期K线、指标计算的持仓量和资金费率）
+	strategy    strategy.Strategy
+	executor    *executor.Executor
+	shadow      *executor.ShadowExecutor  // 影子执行器（仅影子账号）
+	interval    time.Duration             // 分析周期（账号配置覆盖策略默认周期）
+	manage      time.Duration             // 持仓管理周期（未启用时为0）
+	cooldown    time.Duration             // 交易对入场或出场后不再生成信号的时间
+	freshness   time.Duration             // 输入数据的最长有效时间（超过时不调用AI、不执行，未启用时为0）
+	closedOnly  bool                      // 只用已收盘K线计算指标（去掉未收盘的K线）
+	maxHolding  time.Duration             // 最长持仓时间（未启用时为0）
+	holdAction  string                    // 持仓超时的处理方式：close（平仓）或 review（AI出场评估）
+	flatten     config.FlattenConfig      // 低流动性时段配置
+	windows     []scheduler.Window        // 低流动性时段（未启用时为空）
+	flatTime    *scheduler.Window         // 每日定时平仓（未启用时为nil，持续时间为平仓后不开仓的时长）
+	location    *time.Location            // 时区（提示词中的时间）
+	toggles     *toggle.Toggles           // 运行时停用/恢复（所有账号共用）
+	pool        *symbolpool.Overrides     // 运行时加入、移除的交易对（所有账号共用）
+	posCfg      config.PositionConfig     // 持仓设置（运行时加入交易对时检查杠杆、保证金模式）
+	live        *indicators.LiveTracker   // 秒级增量指标（未启用或非币安账号为nil）
+	keys        *binance.KeyRing          // 账号API密钥（轮换时替换全部客户端的密钥，非币安实盘账号为nil）
+	prompts     *prompt.Store             // 提示词模板
+	template    string                    // 账号使用的提示词模板名称
+	budget      config.PromptBudgetConfig // 提示词token预算
+	deltas      *prompt.DeltaTracker      // 各交易对指标变化（账号指标数据格式为delta或启用变化日志时有值）
+	deltaLog    bool                      // 是否把指标变化写入日志
+	ai          *ai.Client                // AI客户端（未启用AI时为nil，只生成提示词）
+	ranking     config.RankingConfig      // 多交易对排名模式
+	twoStage    config.TwoStageConfig     // 两阶段分析（分析 → 决策）
+	voting      config.VotingConfig       // 多次采样投票
+	tools       []*ai.Tool                // AI可调用的行情工具（未启用时为nil）
+	maxCalls    int                       // 每次调用AI时最多执行的工具调用次数
+	audit       *ai.Audit                 // AI决策审计记录（未启用AI时为nil）
+	cache       *ai.Cache                 // AI决策缓存（未启用时为nil）
+	cacheDigits int                       // 计算指标数据指纹时保留的有效数字位数
+	veto        *veto.Engine              // 风控否决规则（未启用时为nil）
+	configHash  string                    // 影响决策的配置哈希（决策标签）
+	model       string                    // 配置的模型名称（接口没有返回模型名称时用于决策标签）
+	calcBudget  time.Duration             // 每个周期指标计算耗时合计的上限（0表示不检查）
+	alerts      *alert.Notifier           // 行情告警（未启用时为nil）
+	anomaly     *alert.AnomalyDetector    // 价格、持仓量异动检测（未启用时为nil）
+	flips       *alert.StateTracker       // 资金费率翻转状态（未启用翻转告警时为nil）
+	oiStates    *alert.StateTracker       // 持仓量与价格关系（未启用时为nil）
+	premiums    *alert.StateTracker       // 标记价格偏离指数价格的状态（未启用或非币安合约账号为nil）
+	premium     config.PremiumConfig      // 溢价告警配置
+	marks       *binance.MarkPriceStream  // 标记价格推送（溢价告警使用，未启用时通过REST接口获取）
+	status      *binance.StatusMonitor    // 交易所系统状态（未启用或非币安账号为nil）
+	down        bool                      // 上一个周期交易所是否处于维护中
+	pumpDump    *scanner.PumpDumpScreener // 拉盘/砸盘筛选（未启用时为nil）
+	pumpAlerts  *alert.Notifier           // 拉盘/砸盘告警（未启用notify时为nil）
+	spikes      *scanner.VolumeScreener   // 成交量异动筛选（未启用或策略不使用候选时为nil）
+	maxSpikes   int                       // 每个周期最多加入的成交量异动候选数
+	breaker     *strategy.SymbolBreaker   // 交易对错误熔断（未启用时为nil）
+}
+
+// run 立即执行一次，然后按分析周期定时执行
+// 启用持仓管理周期时，两次分析之间按管理周期只分析有持仓的交易对（每次分析后重新计时，与分析周期不重叠）
+func (r *accountRunner) run(ctx context.Context, oiCacheManager *utils.OICacheManager) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	var manage <-chan time.Time
+	var manageTicker *time.Ticker
+	if r.manage > 0 {
+		manageTicker = time.NewTicker(r.manage)
+		defer manageTicker.Stop()
+		manage = manageTicker.C
+	}
+
+	utils.Info("执行初始数据采集...", zap.String("account_id", r.accountID))
+	r.runCycle(ctx, oiCacheManager)
+
+	for {
+		select {
+		case <-ticker.C:
+			utils.Info("=== 策略定时任务触发 ===",
+				zap.String("account_id", r.accountID),
+				zap.String("strategy", r.strategy.Name()),
+			)
+			r.runCycle(ctx, oiCacheManager)
+			if manageTicker != nil {
+				manageTicker.Reset(r.manage)
 			}
 
-		case sig := <-sigChan:
-			utils.Info("收到退出信号", zap.String("signal", sig.String()))
-			utils.Info("=== 系统正常退出 ===")
+		case <-manage:
+			r.manageCycle(ctx, oiCacheManager, r.manage)
+
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-// processShortTermStrategy 处理短线策略
-func processShortTermStrategy(client *binance.Client, symbols []string, oiCacheManager *utils.OICacheManager, accountID string) {
-	utils.Info("处理短线策略", zap.String("account_id", accountID), zap.Int("symbols", len(symbols)))
+// runCycle 执行一个策略周期：获取K线 → 策略计算 → 输出信号
+// 冷却期内的交易对不获取数据、不生成信号（需要出场评估的超时持仓除外）；AI分析超过一个策略周期时跳过剩余交易对，不拖到下一周期
+// 停用的账号只分析有持仓的交易对（与持仓管理周期相同）
+func (r *accountRunner) runCycle(ctx context.Context, oiCacheManager *utils.OICacheManager) {
+	if r.toggles.Get(r.accountID) != nil {
+		r.manageCycle(ctx, oiCacheManager, r.interval)
+		return
+	}
 
-	for _, symbol := range symbols {
-		// 获取K线数据
-		klines1h, err := client.GetKlines(symbol, "1h", 100)
-		if err != nil {
-			utils.Error("获取1h K线失败", zap.String("symbol", symbol), zap.Error(err))
-			continue
-		}
+	ctx, cancel := context.WithTimeout(ctx, r.interval)
+	defer cancel()
 
-		klines15m, err := client.GetKlines(symbol, "15m", 100)
-		if err != nil {
-			utils.Error("获取15m K线失败", zap.String("symbol", symbol), zap.Error(err))
-			continue
+	if !r.checkExchangeStatus() {
+		return
+	}
+	review := r.checkMaxHolding()
+	symbols := r.breaker.Allow(r.tradableSymbols(withSymbols(r.withVolumeSpikes(r.activeSymbols()), review)))
+	if len(symbols) == 0 {
+		return
+	}
+	data := strategy.FetchCycleData(r.marketData, r.accountID, symbols, r.strategy.Timeframes(), 100, oiCacheManager)
+	if r.closedOnly {
+		data.DropForming(time.Now())
+	}
+
+	signals := r.strategy.OnCycle(ctx, data)
+	strategy.StampInputs(r.marketData, r.strategy.Timeframes(), signals)
+	data.StampLive(signals)
+	r.recordSymbolErrors(symbols, data)
+	r.checkCalcBudget(symbols, signals)
+	r.checkMarketAlerts(signals)
+	r.screenPumpDump(signals)
+	if r.ranking.Enabled {
+		signals = keepReviewSignals(r.rankSignals(ctx, signals), signals, review)
+	}
+	for i, sig := range signals {
+		if ctx.Err() != nil {
+			utils.Warn("策略周期时间已用完，跳过剩余交易对",
+				zap.String("account_id", r.accountID),
+				zap.Int("skipped", len(signals)-i),
+			)
+			break
 		}
+		r.analyzeSignal(ctx, sig)
+	}
+}
 
-		klines5m, err := client.GetKlines(symbol, "5m", 100)
-		if err != nil {
-			utils.Error("获取5m K线失败", zap.String("symbol", symbol), zap.Error(err))
-			continue
+// manageCycle 持仓管理周期：只对有持仓的交易对获取数据、计算指标并请求AI决策（出场、调整止损止盈）
+// 处于决策冷却期的持仓跳过，没有持仓时不执行；不做排名、行情告警和异动筛选
+func (r *accountRunner) manageCycle(ctx context.Context, oiCacheManager *utils.OICacheManager, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if !r.checkExchangeStatus() {
+		return
+	}
+	r.checkMaxHolding()
+	var symbols []string
+	for _, b := range r.openBrackets() {
+		if !r.cooling(b.Symbol) {
+			symbols = append(symbols, b.Symbol)
 		}
+	}
+	symbols = r.breaker.Allow(r.tradableSymbols(symbols))
+	if len(symbols) == 0 {
+		return
+	}
+	utils.Info("=== 持仓管理周期 ===", zap.String("account_id", r.accountID), zap.Strings("symbols", symbols))
+	data := strategy.FetchCycleData(r.marketData, r.accountID, symbols, r.strategy.Timeframes(), 100, oiCacheManager)
+	if r.closedOnly {
+		data.DropForming(time.Now())
+	}
 
-		// 获取OI缓存
-		oiCache := oiCacheManager.Get(symbol)
-		if oiCache == nil {
-			oiCache = &utils.OICache{
-				Symbol:     symbol,
-				History:    []float64{},
-				Timestamps: []int64{},
-			}
+	signals := r.strategy.OnCycle(ctx, data)
+	strategy.StampInputs(r.marketData, r.strategy.Timeframes(), signals)
+	data.StampLive(signals)
+	r.recordSymbolErrors(symbols, data)
+	for i, sig := range signals {
+		if ctx.Err() != nil {
+			utils.Warn("持仓管理周期时间已用完，跳过剩余交易对",
+				zap.String("account_id", r.accountID),
+				zap.Int("skipped", len(signals)-i),
+			)
+			break
 		}
+		r.analyzeSignal(ctx, sig)
+	}
+}
 
-		// 转换为indicators.OICache类型
-		indicatorOICache := &indicators.OICache{
-			Symbol:     oiCache.Symbol,
-			History:    oiCache.History,
-			Timestamps: oiCache.Timestamps,
+// checkExchangeStatus 交易所维护期间暂停策略周期（返回false），维护结束后自动恢复
+// 进入维护时检查持仓的止损止盈单（接口仍可用时同步已触发的订单）并告警
+func (r *accountRunner) checkExchangeStatus() bool {
+	down, message := r.status.Maintenance()
+	if down == r.down {
+		if down {
+			utils.Warn("交易所维护中，暂停策略周期", zap.String("account_id", r.accountID), zap.String("msg", message))
 		}
+		return !down
+	}
+	r.down = down
 
-		// 计算指标（包含市场数据）
-		result := indicators.CalculateShortTermIndicatorsWithMarket(
-			symbol,
-			klines1h,
-			klines15m,
-			klines5m,
-			client,
-			indicatorOICache,
+	now := time.Now()
+	if down {
+		positions := r.protectPositions("交易所系统维护")
+		utils.Warn("交易所进入系统维护，暂停策略周期",
+			zap.String("account_id", r.accountID),
+			zap.String("msg", message),
+			zap.Int("positions", positions),
 		)
+		r.alerts.Notify(alert.Event{
+			Time:    now,
+			Kind:    "exchange_maintenance",
+			Level:   alert.LevelCritical,
+			Message: fmt.Sprintf("币安进入系统维护（%s），暂停策略周期，持仓依靠交易所挂出的止损止盈单保护", message),
+		})
+		return false
+	}
+	utils.Info("交易所系统维护结束，恢复策略周期", zap.String("account_id", r.accountID))
+	r.alerts.Notify(alert.Event{
+		Time:    now,
+		Kind:    "exchange_resumed",
+		Level:   alert.LevelInfo,
+		Message: "币安系统维护结束，恢复策略周期",
+	})
+	return true
+}
 
-		if result == nil {
-			utils.Error("计算短线指标失败", zap.String("symbol", symbol))
+// recordSymbolErrors 按本周期获取K线、计算指标、获取持仓量的结果更新交易对错误熔断，熔断和恢复时发送告警
+// 熔断中的持仓不再分析，依靠交易所挂出的止损止盈单保护
+func (r *accountRunner) recordSymbolErrors(symbols []string, data *strategy.CycleData) {
+	for _, e := range r.breaker.Record(symbols, data) {
+		if !e.Open {
+			r.alerts.Notify(alert.Event{
+				Time:    time.Now(),
+				Kind:    "symbol_breaker_closed",
+				Symbol:  e.Symbol,
+				Level:   alert.LevelInfo,
+				Message: fmt.Sprintf("%s 探测成功，账号 %s 恢复分析", e.Symbol, r.accountID),
+			})
 			continue
 		}
-
-		// 更新OI缓存
-		if result.MarketData != nil {
-			oiCacheManager.Update(symbol, result.MarketData.OICurrent, time.Now().Unix())
+		message := fmt.Sprintf("%s 连续%d个周期失败（%s），账号 %s 跳过 %s 后探测", e.Symbol, e.Failures, e.Reason, r.accountID, e.Backoff)
+		if e.Probe {
+			message = fmt.Sprintf("%s 探测失败（%s），账号 %s 再跳过 %s", e.Symbol, e.Reason, r.accountID, e.Backoff)
 		}
-
-		// 输出JSON（可以发送给AI或保存到文件）
-		outputIndicators(result, accountID, "short_term")
+		if r.openBracket(e.Symbol) != nil {
+			message += "；有持仓，依靠交易所挂出的止损止盈单保护"
+		}
+		r.alerts.Notify(alert.Event{
+			Time:    time.Now(),
+			Kind:    "symbol_breaker_open",
+			Symbol:  e.Symbol,
+			Level:   alert.LevelWarning,
+			Message: message,
+		})
 	}
 }
 
-// processLongTermStrategy 处理长线策略
-func processLongTermStrategy(client *binance.Client, symbols []string, oiCacheManager *utils.OICacheManager, accountID string) {
-	utils.Info("处理长线策略", zap.String("account_id", accountID), zap.Int("symbols", len(symbols)))
-
+// tradableSymbols 去掉暂停交易的交易对（本周期不分析）；暂停的交易对有持仓时检查止损止盈单并告警
+func (r *accountRunner) tradableSymbols(symbols []string) []string {
+	if r.status == nil {
+		return symbols
+	}
+	tradable := make([]string, 0, len(symbols))
+	protect := false
 	for _, symbol := range symbols {
-		// 获取K线数据
-		klines4h, err := client.GetKlines(symbol, "4h", 100)
-		if err != nil {
-			utils.Error("获取4h K线失败", zap.String("symbol", symbol), zap.Error(err))
+		state, halted := r.status.Halted(r.account.GetMarketType(), symbol)
+		if !halted {
+			tradable = append(tradable, symbol)
 			continue
 		}
-
-		klines1h, err := client.GetKlines(symbol, "1h", 100)
-		if err != nil {
-			utils.Error("获取1h K线失败", zap.String("symbol", symbol), zap.Error(err))
+		utils.Warn("交易对暂停交易，本周期跳过",
+			zap.String("account_id", r.accountID),
+			zap.String("symbol", symbol),
+			zap.String("status", state),
+		)
+		if r.openBracket(symbol) == nil {
 			continue
 		}
+		protect = true
+		r.alerts.Notify(alert.Event{
+			Time:    time.Now(),
+			Kind:    "symbol_halted",
+			Symbol:  symbol,
+			Level:   alert.LevelCritical,
+			Message: fmt.Sprintf("%s 暂停交易（%s），账号 %s 有持仓，依靠交易所挂出的止损止盈单保护", symbol, state, r.accountID),
+		})
+	}
+	if protect {
+		r.protectPositions("交易对暂停交易")
+	}
+	return tradable
+}
 
-		klines15m, err := client.GetKlines(symbol, "15m", 100)
-		if err != nil {
-			utils.Error("获取15m K线失败", zap.String("symbol", symbol), zap.Error(err))
-			continue
-		}
+// protectPositions 检查实盘持仓的止损止盈单（同步已触发的订单，止损单失效时记录警告），返回持仓数
+func (r *accountRunner) protectPositions(reason string) int {
+	if r.executor == nil {
+		return 0
+	}
+	r.executor.CheckBrackets()
+	brackets := r.executor.GetBrackets()
+	for _, b := range brackets {
+		utils.Warn("持仓依靠交易所挂出的止损止盈单保护",
+			zap.String("account_id", r.accountID),
+			zap.String("symbol", b.Symbol),
+			zap.String("reason", reason),
+			zap.Float64("quantity", b.Quantity),
+			zap.Float64("stop_loss", b.StopLoss),
+			zap.Float64("take_profit", b.TakeProfit),
+		)
+	}
+	return len(brackets)
+}
 
-		// 获取OI缓存
-		oiCache := oiCacheManager.Get(symbol)
-		if oiCache == nil {
-			oiCache = &utils.OICache{
-				Symbol:     symbol,
-				History:    []float64{},
-				Timestamps: []int64{},
-			}
-		}
+// checkCalcBudget 检查本周期指标计算耗时合计是否超出预算（超出时记录警告并计入耗时统计）
+func (r *accountRunner) checkCalcBudget(symbols []string, signals []strategy.Signal) {
+	if r.calcBudget <= 0 || len(signals) == 0 {
+		return
+	}
+	kind := indicators.Kind(signals[0].Data)
+	if latency, over := indicators.CheckCycleBudget(kind, symbols, r.calcBudget); over {
+		utils.Warn("指标计算耗时超出周期预算",
+			zap.String("account_id", r.accountID),
+			zap.String("strategy", r.strategy.Name()),
+			zap.Int("symbols", len(symbols)),
+			zap.Duration("latency", latency),
+			zap.Duration("budget", r.calcBudget),
+		)
+	}
+}
 
-		// 转换为indicators.OICache类型
-		indicatorOICache := &indicators.OICache{
-			Symbol:     oiCache.Symbol,
-			History:    oiCache.History,
-			Timestamps: oiCache.Timestamps,
+// checkMarketAlerts 检查交易对池的价格、持仓量异动和资金费率翻转（不论是否有持仓），发出告警
+func (r *accountRunner) checkMarketAlerts(signals []strategy.Signal) {
+	if r.anomaly == nil && r.flips == nil && r.oiStates == nil && r.premiums == nil {
+		return
+	}
+	now := time.Now()
+	for _, sig := range signals {
+		timeframe, tf, market := indicators.PrimaryTimeframe(sig.Data)
+		for _, event := range r.anomaly.Check(sig.Symbol, timeframe, tf, market, now) {
+			r.alerts.Notify(event)
 		}
+		if r.flips != nil && market != nil {
+			r.checkFundingFlip(sig.Symbol, market, now)
+		}
+		if r.oiStates != nil && market != nil {
+			r.checkOIPrice(sig.Symbol, market, now)
+		}
+		if r.premiums != nil {
+			r.checkPremium(sig.Symbol, now)
+		}
+	}
+}
 
-		// 计算指标（包含市场数据）
-		result := indicators.CalculateLongTermIndicatorsWithMarket(
-			symbol,
-			klines4h,
-			klines1h,
-			klines15m,
-			client,
-			indicatorOICache,
-		)
+// checkFundingFlip 资金费率由不翻转变为翻转时发出告警（翻转状态持续期间不重复告警）
+func (r *accountRunner) checkFundingFlip(symbol string, market *indicators.MarketData, now time.Time) {
+	if _, changed := r.flips.Update("funding_flip/"+symbol, market.FundingFlip); !changed || market.FundingFlip == "" {
+		return
+	}
+	direction := "由负转正"
+	if market.FundingFlip == indicators.FundingFlipToNegative {
+		direction = "由正转负"
+	}
+	r.alerts.Notify(alert.Event{
+		Time:    now,
+		Kind:    "funding_flip",
+		Symbol:  symbol,
+		Level:   alert.LevelWarning,
+		Message: fmt.Sprintf("%s 资金费率%s：当前 %.4f%%，最近3次平均 %.4f%%", symbol, direction, market.FundingRate, market.FundingAvg3),
+		Values: map[string]float64{
+			"funding_rate":  market.FundingRate,
+			"funding_avg_3": market.FundingAvg3,
+		},
+	})
+}
 
-		if result == nil {
-			utils.Error("计算长线指标失败", zap.String("symbol", symbol))
+// screenPumpDump 筛选本周期短时间大幅涨跌且持仓量扩张的交易对，启用notify时逐个发出 pump / dump 告警
+func (r *accountRunner) screenPumpDump(signals []strategy.Signal) {
+	if r.pumpDump == nil {
+		return
+	}
+	inputs := make([]scanner.PumpDumpInput, 0, len(signals))
+	for _, sig := range signals {
+		timeframe, tf, market := indicators.PrimaryTimeframe(sig.Data)
+		if tf == nil || tf.OpenPrice <= 0 || market == nil {
 			continue
 		}
+		oiChange := market.OIChange15m
+		if oiChange == nil {
+			oiChange = market.OIChange5m
+		}
+		if oiChange == nil {
+			continue
+		}
+		inputs = append(inputs, scanner.PumpDumpInput{
+			Symbol:      sig.Symbol,
+			Timeframe:   timeframe,
+			Price:       tf.CurrentPrice(),
+			ReturnPct:   (tf.ClosePrice - tf.OpenPrice) / tf.OpenPrice * 100,
+			OIChangePct: *oiChange,
+			FundingRate: market.FundingRate,
+		})
+	}
 
-		// 更新OI缓存
-		if result.MarketData != nil {
-			oiCacheManager.Update(symbol, result.MarketData.OICurrent, time.Now().Unix())
+	report := r.pumpDump.Screen(r.accountID, inputs)
+	if len(report.Events) == 0 {
+		return
+	}
+	symbols := make([]string, 0, len(report.Events))
+	for _, e := range report.Events {
+		symbols = append(symbols, e.Symbol)
+		direction := "拉盘"
+		if e.Kind == scanner.PumpDumpDump {
+			direction = "砸盘"
 		}
+		r.pumpAlerts.Notify(alert.Event{
+			Kind:    e.Kind,
+			Symbol:  e.Symbol,
+			Level:   alert.LevelWarning,
+			Message: fmt.Sprintf("%s 疑似%s：%s K线涨跌 %.2f%%，持仓量增加 %.2f%%，资金费率 %.4f%%", e.Symbol, direction, e.Timeframe, e.ReturnPct, e.OIChangePct, e.FundingRate),
+			Values: map[string]float64{
+				"return_pct":    e.ReturnPct,
+				"oi_change_pct": e.OIChangePct,
+				"score":         e.Score,
+			},
+		})
+	}
+	utils.Info("拉盘/砸盘筛选",
+		zap.String("account_id", r.accountID),
+		zap.Int("scanned", report.Scanned),
+		zap.Strings("symbols", symbols),
+	)
+}
 
-		// 输出JSON（可以发送给AI或保存到文件）
-		outputIndicators(result, accountID, "long_term")
+// checkOIPrice 持仓中的交易对持仓量与价格关系改变时发出告警（如真实多头趋势 → 空头平仓推动）
+// 不持仓的交易对同样记录状态，入场后的第一次改变即可告警；无明显变化（neutral）不算改变
+func (r *accountRunner) checkOIPrice(symbol string, market *indicators.MarketData, now time.Time) {
+	state := market.OIPriceState
+	if state == "" || state == indicators.OIPriceNeutral {
+		return
+	}
+	previous, changed := r.oiStates.Update(symbol, state)
+	if !changed {
+		return
 	}
+	bracket := r.openBracket(symbol)
+	if bracket == nil {
+		return
+	}
+	side := "空头"
+	if bracket.IsLong() {
+		side = "多头"
+	}
+	r.alerts.Notify(alert.Event{
+		Time:    now,
+		Kind:    "oi_price_flip",
+		Symbol:  symbol,
+		Level:   alert.LevelWarning,
+		Message: fmt.Sprintf("%s 账号 %s 持有%s仓位，持仓量与价格关系改变：%s → %s", symbol, r.accountID, side, indicators.OIPriceDescription(previous), indicators.OIPriceDescription(state)),
+		Values: map[string]float64{
+			"entry_price": bracket.EntryPrice,
+			"quantity":    bracket.Quantity,
+		},
+	})
 }
 
-// outputIndicators 输出指标数据（JSON格式）
-func outputIndicators(data interface{}, accountID, strategy string) {
-	jsonData, err := json.MarshalIndent(data, "", "  ")
+// checkPremium 标记价格偏离指数价格超过上限时告警，恢复正常时再通知一次（偏离持续期间不重复告警）
+// 程序启动时已经处于偏离状态的交易对也会告警
+func (r *accountRunner) checkPremium(symbol string, now time.Time) {
+	premium, err := binance.PremiumPct(r.marks, r.client, symbol)
 	if err != nil {
-		utils.Error("序列化JSON失败", zap.Error(err))
+		utils.Warn("获取溢价失败", zap.String("account_id", r.accountID), zap.String("symbol", symbol), zap.Error(err))
 		return
 	}
+	state := ""
+	switch {
+	case premium > r.premium.MaxPct:
+		state = "above"
+	case premium < -r.premium.MaxPct:
+		state = "below"
+	}
+	previous, changed := r.premiums.Update("premium/"+symbol, state)
+	values := map[string]float64{"premium_pct": premium, "max_pct": r.premium.MaxPct}
 
-	utils.Info("指标数据",
-		zap.String("account_id", accountID),
-		zap.String("strategy", strategy),
-		zap.String("json", string(jsonData)),
-	)
+	switch {
+	case state != "" && previous != state:
+		level := alert.LevelWarning
+		if math.Abs(premium) >= 2*r.premium.MaxPct {
+			level = alert.LevelCritical
+		}
+		message := fmt.Sprintf("%s 标记价格偏离指数价格 %+.3f%%（上限 %.3f%%），永续合约价格失真", symbol, premium, r.premium.MaxPct)
+		if r.premium.PauseEntries {
+			message += "，暂停该交易对开仓"
+		}
+		r.alerts.Notify(alert.Event{Time: now, Kind: "premium_deviation", Symbol: symbol, Level: level, Message: message, Values: values})
+	case state == "" && changed:
+		message := fmt.Sprintf("%s 标记价格偏离指数价格恢复正常：%+.3f%%", symbol, premium)
+		if r.premium.PauseEntries {
+			message += "，恢复开仓"
+		}
+		r.alerts.Notify(alert.Event{Time: now, Kind: "premium_recovered", Symbol: symbol, Level: alert.LevelInfo, Message: message, Values: values})
+	}
+}
 
-	// TODO: 这里可以将JSON数据发送给AI进行分析
-	// 或者保存到文件、数据库等
+// openBracket 账号在该交易对上生效中的括号订单（没有持仓时为nil）
+func (r *accountRunner) openBracket(symbol string) *executor.Bracket {
+	switch {
+	case r.executor != nil:
+		return r.executor.GetBracket(symbol)
+	case r.shadow != nil:
+		for _, b := range r.shadow.GetBrackets() {
+			if b.Symbol == symbol {
+				return b
+			}
+		}
+	}
+	return nil
+}
+
+// openBrackets 账号当前的全部持仓（影子账号为模拟持仓，没有执行器的账号为空）
+func (r *accountRunner) openBrackets() []*executor.Bracket {
+	switch {
+	case r.executor != nil:
+		return r.executor.GetBrackets()
+	case r.shadow != nil:
+		return r.shadow.GetBrackets()
+	}
+	return nil
+}
+
+// holdingOverdue 持仓时间是否已超过最长持仓时间
+func (r *accountRunner) holdingOverdue(b *executor.Bracket, now time.Time) bool {
+	return r.maxHolding > 0 && !b.CreatedAt.IsZero() && now.Sub(b.CreatedAt) >= r.maxHolding
+}
+
+// checkMaxHolding 检查持仓时间：超过最长持仓时间的持仓按配置直接平仓，或返回本周期需要AI出场评估的交易对
+func (r *accountRunner) checkMaxHolding() []string {
+	if r.maxHolding <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	var review []string
+	for _, b := range r.openBrackets() {
+		if !r.holdingOverdue(b, now) {
+			continue
+		}
+		held := formatHolding(now.Sub(b.CreatedAt))
+		if r.holdAction == config.MaxHoldingReview && r.ai != nil {
+			utils.Info("持仓超过最长持仓时间，本周期进行AI出场评估",
+				zap.String("account_id", r.accountID),
+				zap.String("symbol", b.Symbol),
+				zap.String("held", held),
+				zap.Duration("max_holding", r.maxHolding),
+			)
+			review = append(review, b.Symbol)
+			continue
+		}
+
+		reason := fmt.Sprintf("已持仓%s，超过最长持仓时间%s", held, formatHolding(r.maxHolding))
+		result, err := r.executeDecision(&executor.Decision{
+			AccountID: r.accountID,
+			Symbol:    b.Symbol,
+			Action:    executor.ActionClose,
+			Reason:    reason,
+			Timestamp: now.UnixMilli(),
+		})
+		if err != nil {
+			utils.Error("持仓超时平仓失败", zap.String("account_id", r.accountID), zap.String("symbol", b.Symbol), zap.Error(err))
+			continue
+		}
+		utils.Warn("持仓超过最长持仓时间，已平仓",
+			zap.String("account_id", r.accountID),
+			zap.String("symbol", b.Symbol),
+			zap.String("held", held),
+			zap.String("result", result),
+		)
+		r.alerts.Notify(alert.Event{
+			Time:    now,
+			Kind:    "max_holding",
+			Symbol:  b.Symbol,
+			Level:   alert.LevelWarning,
+			Message: fmt.Sprintf("%s %s，已平仓", b.Symbol, reason),
+		})
+	}
+	return review
+}
+
+// flattenWindows 按配置生成低流动性时段（时间按 loc 时区解释；配置已验证，解析失败的时段忽略）
+func flattenWindows(f config.FlattenConfig, loc *time.Location) []scheduler.Window {
+	windows := make([]scheduler.Window, 0, len(f.Windows))
+	for _, w := range f.Windows {
+		hour, minute, err := config.ParseClock(w.Start)
+		if err != nil {
+			continue
+		}
+		var start scheduler.Schedule
+		if w.Date != "" {
+			date, err := time.ParseInLocation(time.DateOnly, w.Date, loc)
+			if err != nil {
+				continue
+			}
+			start = scheduler.At(date.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute))
+		} else {
+			weekday, err := config.ParseWeekday(w.Weekday)
+			if err != nil {
+				continue
+			}
+			start = scheduler.Weekly(weekday, hour, minute, loc)
+		}
+		windows = append(windows, scheduler.Window{
+			Name:     w.Name,
+			Start:    start,
+			Duration: time.Duration(w.Hours * float64(time.Hour)),
+		})
+	}
+	return windows
+}
+
+// cycleIntervals 账号的分析周期和持仓管理周期（未配置分析周期时使用策略默认周期，管理周期不短于分析周期时不启用）
+func cycleIntervals(account *config.Account, strat strategy.Strategy) (time.Duration, time.Duration) {
+	interval := strat.Interval()
+	if account.Cycle.IntervalSec > 0 {
+		interval = time.Duration(account.Cycle.IntervalSec) * time.Second
+	}
+	manage := time.Duration(account.Cycle.ManageIntervalSec) * time.Second
+	if manage >= interval {
+		utils.Warn("持仓管理周期不短于分析周期，已忽略",
+			zap.String("account_id", account.ID),
+			zap.Duration("interval", interval),
+			zap.Duration("manage_interval", manage),
+		)
+		manage = 0
+	}
+	return interval, manage
+}
+
+// symbolBreaker 创建账号的交易对错误熔断（未启用时为nil）
+func symbolBreaker(cfg *config.Config, accountID string) *strategy.SymbolBreaker {
+	b := cfg.GetSymbolBreakerConfig()
+	if !b.Enabled {
+		return nil
+	}
+	return strategy.NewSymbolBreaker(accountID, b.MaxFailures, time.Duration(b.BackoffSec)*time.Second, time.Duration(b.MaxBackoffSec)*time.Second)
+}
+
+// symbolConverter 交易对池中的交易对转换为账号的交易对格式（币安币本位账号转换为币本位永续合约，其他账号为nil）
+func symbolConverter(account config.Account) func(string) string {
+	if account.GetExchange() != exchange.NameOKX && account.GetMarketType() == binance.MarketTypeCoinM {
+		return binance.CoinMSymbol
+	}
+	return nil
+}
+
+// flatTimeWindow 每日定时平仓时段：每天平仓时间开始，持续到恢复开仓时间（时间按 loc 时区解释）
+func flatTimeWindow(f config.FlatTimeConfig, loc *time.Location) *scheduler.Window {
+	hour, minute, err := config.ParseClock(f.Time)
+	if err != nil {
+		return nil
+	}
+	return &scheduler.Window{
+		Name:     "flat_time",
+		Start:    scheduler.Daily(hour, minute, loc),
+		Duration: f.BlockDuration(),
+	}
+}
+
+// accountJobs 为各账号添加定时任务：低流动性时段开始前 lead_minutes 平仓或减仓，每日定时平仓
+func accountJobs(runners []*accountRunner) *scheduler.Scheduler {
+	jobs := scheduler.New()
+	for _, r := range runners {
+		lead := time.Duration(r.flatten.LeadMinutes) * time.Minute
+		for _, w := range r.windows {
+			jobs.Add(r.accountID+"/flatten/"+w.Name, scheduler.Offset(w.Start, -lead), func(time.Time) {
+				r.flattenPositions(w)
+			})
+		}
+		if r.flatTime != nil {
+			jobs.Add(r.accountID+"/flat_time", r.flatTime.Start, func(time.Time) {
+				r.closeAll("每日定时平仓")
+			})
+		}
+	}
+	return jobs
+}
+
+// entryBlock 当前不开仓的原因（低流动性时段含开始前的提前量、每日定时平仓后到恢复时间前；可以开仓时为空）
+func (r *accountRunner) entryBlock(now time.Time) string {
+	if r.flatten.BlockEntries {
+		lead := time.Duration(r.flatten.LeadMinutes) * time.Minute
+		for _, w := range r.windows {
+			if w.Active(now, lead) {
+				return "低流动性时段 " + w.Name
+			}
+		}
+	}
+	if r.flatTime != nil && r.flatTime.Active(now, 0) {
+		return "每日定时平仓后，恢复开仓时间 " + r.account.FlatTime.Resume
+	}
+	if r.toggles.Get(r.accountID) != nil {
+		return "账号已停用（通过API恢复）"
+	}
+	return ""
+}
+
+// closePositions 平掉全部持仓并撤销全部挂单（影子账号模拟平仓），返回平仓的交易对
+func (r *accountRunner) closePositions() ([]string, error) {
+	if r.shadow != nil {
+		return r.shadow.CloseAll()
+	}
+	return r.executor.CloseAll(withSymbols(r.symbols, r.poolSymbols()))
+}
+
+// closeAll 平掉全部持仓并撤销全部挂单，发送告警
+func (r *accountRunner) closeAll(reason string) {
+	if r.shadow == nil && r.executor == nil {
+		utils.Warn("账号没有执行器，不平仓", zap.String("account_id", r.accountID), zap.String("reason", reason))
+		return
+	}
+	closed, err := r.closePositions()
+
+	message := fmt.Sprintf("账号 %s %s: 已平仓 %d 个交易对", r.accountID, reason, len(closed))
+	if len(closed) > 0 {
+		message += "（" + strings.Join(closed, ", ") + "）"
+	}
+	level := alert.LevelInfo
+	if err != nil {
+		utils.Error("全部平仓部分失败", zap.String("account_id", r.accountID), zap.String("reason", reason), zap.Error(err))
+		message += "；失败: " + err.Error()
+		level = alert.LevelCritical
+	} else {
+		utils.Info("全部平仓完成", zap.String("account_id", r.accountID), zap.String("reason", reason), zap.Strings("closed", closed))
+	}
+	r.alerts.Notify(alert.Event{
+		Time:    time.Now(),
+		Kind:    "close_all",
+		Level:   level,
+		Message: message,
+	})
+}
+
+// disable 停用账号（不再开仓）并发送告警；positions 为 close 时立即平掉全部持仓并撤销全部挂单
+func (r *accountRunner) disable(positions, reason, source string) (*toggle.State, error) {
+	if positions == toggle.PositionsClose && r.shadow == nil && r.executor == nil {
+		return nil, fmt.Errorf("账号[%s]没有执行器，不能平仓", r.accountID)
+	}
+	state, err := r.toggles.Disable(r.accountID, positions, reason, source)
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("账号 %s 已停用（来源 %s），不再开仓", r.accountID, source)
+	if state.Positions == toggle.PositionsClose {
+		message += "，立即平掉全部持仓"
+	} else {
+		message += "，已有持仓继续管理"
+	}
+	if reason != "" {
+		message += "；原因: " + reason
+	}
+	r.alerts.Notify(alert.Event{
+		Time:    time.Now(),
+		Kind:    "account_disabled",
+		Level:   alert.LevelWarning,
+		Message: message,
+	})
+	if state.Positions == toggle.PositionsClose {
+		r.closeAll("账号停用")
+	}
+	return state, nil
+}
+
+// enable 恢复停用的账号并发送告警，账号未停用时返回false
+func (r *accountRunner) enable(source string) (bool, error) {
+	enabled, err := r.toggles.Enable(r.accountID, source)
+	if err != nil || !enabled {
+		return enabled, err
+	}
+	r.alerts.Notify(alert.Event{
+		Time:    time.Now(),
+		Kind:    "account_enabled",
+		Level:   alert.LevelInfo,
+		Message: fmt.Sprintf("账号 %s 已恢复（来源 %s），照常开仓", r.accountID, source),
+	})
+	return true, nil
+}
+
+// poolSymbols 账号当前的交易对池（配置的交易对池应用运行时的加入、移除）
+func (r *accountRunner) poolSymbols() []string {
+	return r.pool.Apply(r.accountID, r.symbols, symbolConverter(r.account))
+}
+
+// nativeSymbols 交易对转换为账号的交易对格式（返回新的切片）
+func (r *accountRunner) nativeSymbols(symbols []string) []string {
+	convert := symbolConverter(r.account)
+	native := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		if convert != nil {
+			symbol = convert(symbol)
+		}
+		native[i] = symbol
+	}
+	return native
+}
+
+// prepareSymbols 运行时加入交易对前检查：币安账号按交易规则检查交易对存在且状态为 TRADING，
+// 其他交易所获取一根K线；实盘账号检查杠杆、保证金模式（按 on_mismatch 处理）
+// 交易对不可用时返回 server.BadRequest，请求交易所失败时返回普通错误
+func (r *accountRunner) prepareSymbols(symbols []string) error {
+	symbols = r.nativeSymbols(symbols)
+	if r.client == nil {
+		for _, symbol := range symbols {
+			if _, err := r.market.GetKlines(symbol, r.strategy.Timeframes()[0], 1); err != nil {
+				return server.BadRequest("账号[%s]的交易所没有交易对 %s: %v", r.accountID, symbol, err)
+			}
+		}
+		return nil
+	}
+
+	info, err := r.client.GetExchangeInfo()
+	if err != nil {
+		return fmt.Errorf("账号[%s]获取交易规则失败: %w", r.accountID, err)
+	}
+	for _, symbol := range symbols {
+		rules := info.GetSymbol(symbol)
+		if rules == nil {
+			return server.BadRequest("账号[%s]的交易规则中没有交易对 %s", r.accountID, symbol)
+		}
+		if rules.Status != "" && rules.Status != binance.SymbolStatusTrading {
+			return server.BadRequest("账号[%s]的交易对 %s 状态为 %s，不可交易", r.accountID, symbol, rules.Status)
+		}
+	}
+	if r.executor != nil {
+		if _, err := r.executor.Bootstrap(symbols, r.posCfg); err != nil {
+			return server.BadRequest("账号[%s]持仓设置检查失败: %v", r.accountID, err)
+		}
+	}
+	return nil
+}
+
+// watchSymbols 运行时加入的交易对开始计算增量指标、检查交易对状态
+func (r *accountRunner) watchSymbols(symbols []string) {
+	symbols = r.nativeSymbols(symbols)
+	r.live.Track(symbols)
+	r.status.Watch(r.account.GetMarketType(), r.client, symbols)
+}
+
+// rotateKey 轮换账号的API密钥（先检查新密钥，再替换全部客户端的密钥），成功后发送告警；密钥未变化时返回false
+func (r *accountRunner) rotateKey(apiKey, apiSecret, source string) (bool, error) {
+	if r.keys == nil {
+		return false, fmt.Errorf("账号[%s]不是币安实盘账号，不支持轮换API密钥", r.accountID)
+	}
+	changed, err := r.keys.Rotate(apiKey, apiSecret)
+	if err != nil {
+		utils.Error("轮换API密钥失败，继续使用旧密钥", zap.String("account_id", r.accountID), zap.String("source", source), zap.Error(err))
+		return false, err
+	}
+	if !changed {
+		return false, nil
+	}
+
+	// 日志和告警只记录账号和来源，不包含密钥的任何部分
+	utils.Warn("API密钥已轮换",
+		zap.String("account_id", r.accountID),
+		zap.String("source", source),
+	)
+	r.alerts.Notify(alert.Event{
+		Time:    time.Now(),
+		Kind:    "api_key_rotated",
+		Level:   alert.LevelWarning,
+		Message: fmt.Sprintf("账号 %s 的API密钥已轮换（来源 %s）", r.accountID, source),
+	})
+	return true, nil
+}
+
+// flattenPositions 低流动性时段开始前按配置平仓或减仓，并发送告警
+// 实盘账号按交易所的持仓处理，手动开的仓、重启前的仓和没有括号订单的仓同样处理
+func (r *accountRunner) flattenPositions(w scheduler.Window) {
+	now := time.Now()
+	reason := fmt.Sprintf("低流动性时段 %s 前", w.Name)
+
+	symbols, err := r.positionSymbols()
+	if err != nil {
+		utils.Error("低流动性时段前查询持仓失败", zap.String("account_id", r.accountID), zap.String("window", w.Name), zap.Error(err))
+		r.alerts.Notify(alert.Event{
+			Time:    now,
+			Kind:    "flatten",
+			Level:   alert.LevelCritical,
+			Message: fmt.Sprintf("账号 %s %s查询持仓失败，未处理持仓: %v", r.accountID, reason, err),
+		})
+		return
+	}
+	if len(symbols) == 0 {
+		utils.Info("低流动性时段前没有持仓", zap.String("account_id", r.accountID), zap.String("window", w.Name))
+		return
+	}
+
+	var done, failed []string
+	for _, symbol := range symbols {
+		var err error
+		if r.flatten.Action == config.FlattenReduce {
+			err = r.reducePosition(symbol, r.flatten.ReducePct/100)
+		} else {
+			_, err = r.executeDecision(&executor.Decision{
+				AccountID: r.accountID,
+				Symbol:    symbol,
+				Action:    executor.ActionClose,
+				Reason:    reason + "平仓",
+				Timestamp: now.UnixMilli(),
+			})
+		}
+		if err != nil {
+			utils.Error("低流动性时段前处理持仓失败",
+				zap.String("account_id", r.accountID),
+				zap.String("symbol", symbol),
+				zap.String("action", r.flatten.Action),
+				zap.Error(err),
+			)
+			failed = append(failed, symbol)
+			continue
+		}
+		done = append(done, symbol)
+	}
+
+	action := "平仓"
+	if r.flatten.Action == config.FlattenReduce {
+		action = fmt.Sprintf("减仓%v%%", r.flatten.ReducePct)
+	}
+	utils.Warn("低流动性时段前已处理持仓",
+		zap.String("account_id", r.accountID),
+		zap.String("window", w.Name),
+		zap.String("action", action),
+		zap.Strings("done", done),
+		zap.Strings("failed", failed),
+	)
+	message := fmt.Sprintf("账号 %s %s%s: %s", r.accountID, reason, action, strings.Join(done, ", "))
+	level := alert.LevelWarning
+	if len(failed) > 0 {
+		message += fmt.Sprintf("；失败: %s", strings.Join(failed, ", "))
+		level = alert.LevelCritical
+	}
+	r.alerts.Notify(alert.Event{
+		Time:    now,
+		Kind:    "flatten",
+		Level:   level,
+		Message: message,
+	})
+}
+
+// positionSymbols 账号有持仓的交易对（实盘查询交易所持仓，影子账号为模拟持仓，没有执行器的账号为空）
+func (r *accountRunner) positionSymbols() ([]string, error) {
+	if r.executor != nil {
+		return r.executor.PositionSymbols(withSymbols(r.symbols, r.poolSymbols()))
+	}
+	var symbols []string
+	for _, b := range r.openBrackets() {
+		symbols = append(symbols, b.Symbol)
+	}
+	return symbols, nil
+}
+
+// reducePosition 按比例减仓（影子账号模拟减仓，没有执行器的账号只记录）
+func (r *accountRunner) reducePosition(symbol string, fraction float64) error {
+	switch {
+	case r.shadow != nil:
+		_, err := r.shadow.ReducePosition(symbol, fraction)
+		return err
+	case r.executor != nil:
+		_, err := r.executor.ReducePosition(symbol, fraction)
+		return err
+	default:
+		utils.Warn("账号没有执行器，不减仓", zap.String("account_id", r.accountID), zap.String("symbol", symbol))
+		return nil
+	}
+}
+
+// withSymbols 在交易对列表后追加不在其中的交易对（返回新的切片，不修改交易对池）
+func withSymbols(symbols, extra []string) []string {
+	if len(extra) == 0 {
+		return symbols
+	}
+	symbols = slices.Clone(symbols)
+	for _, symbol := range extra {
+		if !slices.Contains(symbols, symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+// keepReviewSignals 排名后补回需要出场评估但未被选中的交易对信号
+func keepReviewSignals(ranked, all []strategy.Signal, review []string) []strategy.Signal {
+	for _, sig := range all {
+		if !slices.Contains(review, sig.Symbol) {
+			continue
+		}
+		if !slices.ContainsFunc(ranked, func(s strategy.Signal) bool { return s.Symbol == sig.Symbol }) {
+			ranked = append(ranked, sig)
+		}
+	}
+	return ranked
+}
+
+// rankSignals 排名模式：把所有交易对的关键指标放在一个提示词里请AI挑选 top_n 个候选，只保留候选的信号
+// 无法提取关键指标的信号（如资金费率扫描）不参与排名，直接保留；排名失败时保留全部信号
+func (r *accountRunner) rankSignals(ctx context.Context, signals []strategy.Signal) []strategy.Signal {
+	rows := make([]*indicators.KeyMetrics, 0, len(signals))
+	candidates := make([]string, 0, len(signals))
+	for _, sig := range signals {
+		if m := indicators.Summarize(sig.Data); m != nil {
+			rows = append(rows, m)
+			candidates = append(candidates, m.Symbol)
+		}
+	}
+	if len(rows) <= r.ranking.TopN {
+		return signals
+	}
+
+	text, err := r.prompts.Render(r.ranking.Template, &prompt.RankingData{
+		AccountID:    r.accountID,
+		Strategy:     r.account.Strategy,
+		StrategyName: r.account.GetStrategyName(),
+		Time:         time.Now().In(r.location),
+		TopN:         r.ranking.TopN,
+		Rows:         rows,
+	})
+	if err != nil {
+		utils.Error("生成排名提示词失败，逐个分析全部交易对", zap.String("account_id", r.accountID), zap.Error(err))
+		return signals
+	}
+	resp, err := r.ai.Complete(ctx, &ai.Request{Prompt: text})
+	if err != nil {
+		utils.Error("AI排名失败，逐个分析全部交易对", zap.String("account_id", r.accountID), zap.Error(err))
+		return signals
+	}
+	picked, err := ai.ParseRanking(resp.Text, candidates, r.ranking.TopN)
+	if err != nil {
+		utils.Error("解析AI排名失败，逐个分析全部交易对", zap.String("account_id", r.accountID), zap.String("reply", resp.Text), zap.Error(err))
+		return signals
+	}
+
+	utils.Info("AI排名完成",
+		zap.String("account_id", r.accountID),
+		zap.Int("symbols", len(rows)),
+		zap.Strings("picked", picked),
+		zap.Int("prompt_tokens", resp.PromptTokens),
+		zap.Int("completion_tokens", resp.CompletionTokens),
+		zap.Duration("latency", resp.Latency),
+	)
+
+	keep := make(map[string]bool, len(picked))
+	for _, symbol := range picked {
+		keep[symbol] = true
+	}
+	selected := make([]strategy.Signal, 0, len(picked))
+	for _, sig := range signals {
+		if keep[sig.Symbol] || indicators.Summarize(sig.Data) == nil {
+			selected = append(selected, sig)
+		}
+	}
+	return selected
+}
+
+// analyzeSignal 按账号的提示词模板生成AI提示词（超出token预算时压缩指标数据，渲染失败时输出指标JSON）
+// 启用AI时发送给AI分析（两阶段模式先分析再结合账户状态决策），解析出的决策交给执行器，全过程写入审计记录
+// 启用决策缓存且指标数据与上次相同时复用上次决策，不调用AI
+func (r *accountRunner) analyzeSignal(ctx context.Context, sig strategy.Signal) {
+	data := &prompt.Data{
+		AccountID:    sig.AccountID,
+		Strategy:     sig.Strategy,
+		StrategyName: r.account.GetStrategyName(),
+		Symbol:       sig.Symbol,
+		Time:         time.Now().In(r.location),
+		Indicators:   sig.Data,
+		Summary:      indicators.Describe(sig.Data),
+		Compact:      r.account.IsCompactPayload(),
+	}
+	var price float64
+	if _, tf, _ := indicators.PrimaryTimeframe(sig.Data); tf != nil {
+		price = tf.CurrentPrice()
+	}
+	data.Position = r.position(sig.Symbol, price)
+	r.trackDelta(data)
+	template := r.template
+	if r.twoStage.Enabled {
+		template = r.twoStage.AnalysisTemplate
+	}
+	text, err := r.renderPrompt(template, data)
+	if err != nil {
+		utils.Error("生成提示词失败", zap.String("account_id", r.accountID), zap.String("symbol", sig.Symbol), zap.Error(err))
+		outputIndicators(sig.Data, sig.AccountID, sig.Strategy)
+		return
+	}
+	if r.ai == nil {
+		return
+	}
+
+	rec := &ai.AuditRecord{
+		AccountID: r.accountID,
+		Strategy:  r.account.Strategy,
+		Symbol:    sig.Symbol,
+		Time:      data.Time,
+		Mode:      ai.ModeSingle,
+	}
+	// 保存完整指标数据，回放时可以用新的提示词模板重新渲染
+	if kind := indicators.Kind(sig.Data); kind != "" {
+		if raw, err := json.Marshal(sig.Data); err == nil {
+			rec.IndicatorKind, rec.Indicators = kind, raw
+		}
+	}
+	if r.cache != nil {
+		// 持仓变化（开平仓、调整止损止盈）后不复用之前的决策
+		rec.PayloadHash = prompt.PayloadHash(template+positionKey(data.Position), sig.Data, r.cacheDigits, cacheIgnoredFields)
+	}
+	// 输入数据过期（如重试导致等待过久）时不调用AI
+	if stale := r.staleInputs(sig); stale != "" {
+		r.skipStale(rec, stale, "跳过AI分析")
+		return
+	}
+	decision := r.cachedDecision(rec)
+	if decision == nil {
+		decision, err = r.decide(ctx, rec, template, text, price)
+		if err == nil && decision != nil {
+			r.cache.Put(sig.Symbol, rec.PayloadHash, decision, rec.Time)
+		}
+	}
+	if err == nil && decision == nil {
+		rec.Result = "no_consensus"
+	} else if err == nil {
+		rec.Decision = decision
+		if rec.Veto = r.checkVeto(decision, sig.Data, rec.Time); rec.Veto != nil && rec.Veto.Vetoed {
+			rec.Result = "vetoed"
+		} else if stale := r.staleInputs(sig); stale != "" && decision.Action != executor.ActionClose {
+			// AI分析期间输入数据过期时不执行（平仓决策照常执行）
+			r.skipStale(rec, stale, "不执行决策")
+			return
+		} else {
+			rec.Veto.Apply(decision)
+			rec.Result, err = r.executeDecision(decision)
+		}
+	}
+	if err != nil {
+		rec.Error = err.Error()
+		utils.Error("AI分析失败", zap.String("account_id", r.accountID), zap.String("symbol", sig.Symbol), zap.String("mode", rec.Mode), zap.Error(err))
+	}
+	if err := r.audit.Record(rec); err != nil {
+		utils.Error("写入AI审计记录失败", zap.String("account_id", r.accountID), zap.String("symbol", sig.Symbol), zap.Error(err))
+	}
+}
+
+// staleInputs 超过最长有效时间的输入数据（按获取时间排序的说明，如 "klines:1h（获取于2m5s前）"），没有时返回空字符串
+func (r *accountRunner) staleInputs(sig strategy.Signal) string {
+	if r.freshness <= 0 {
+		return ""
+	}
+	now := time.Now()
+	inputs := make([]string, 0, len(sig.FetchedAt))
+	for input, at := range sig.FetchedAt {
+		if now.Sub(at) > r.freshness {
+			inputs = append(inputs, input)
+		}
+	}
+	slices.SortFunc(inputs, func(a, b string) int { return sig.FetchedAt[a].Compare(sig.FetchedAt[b]) })
+	stale := make([]string, len(inputs))
+	for i, input := range inputs {
+		stale[i] = fmt.Sprintf("%s（获取于%s前）", input, now.Sub(sig.FetchedAt[input]).Round(time.Second))
+	}
+	return strings.Join(stale, ", ")
+}
+
+// skipStale 输入数据过期，记录原因并写入审计记录（执行结果为 stale_data）
+func (r *accountRunner) skipStale(rec *ai.AuditRecord, stale, action string) {
+	rec.Result = "stale_data"
+	rec.Error = "输入数据过期: " + stale
+	utils.Warn("输入数据过期，"+action,
+		zap.String("account_id", r.accountID),
+		zap.String("symbol", rec.Symbol),
+		zap.String("stale", stale),
+		zap.Duration("max_age", r.freshness),
+	)
+	if err := r.audit.Record(rec); err != nil {
+		utils.Error("写入AI审计记录失败", zap.String("account_id", r.accountID), zap.String("symbol", rec.Symbol), zap.Error(err))
+	}
+}
+
+// trackDelta 与该交易对上次生成提示词时的指标数据比较：账号指标数据格式为delta时提示词只列出变化明显的字段，启用变化日志时写入日志
+func (r *accountRunner) trackDelta(data *prompt.Data) {
+	if r.deltas == nil {
+		return
+	}
+	delta, err := r.deltas.Update(data.Symbol, data.Indicators, data.Time)
+	if err != nil {
+		utils.Warn("比较指标变化失败，输出完整指标数据", zap.String("account_id", r.accountID), zap.String("symbol", data.Symbol), zap.Error(err))
+		return
+	}
+	if r.account.IsDeltaPayload() {
+		data.Delta = delta
+	}
+	if r.deltaLog && !delta.Full {
+		utils.Info("指标变化",
+			zap.String("account_id", r.accountID),
+			zap.String("symbol", data.Symbol),
+			zap.Int("fields", len(delta.Changes)),
+			zap.String("changes", delta.String()),
+		)
+	}
+}
+
+// renderPrompt 渲染提示词，超出token预算时压缩指标数据并记录压缩步骤
+func (r *accountRunner) renderPrompt(template string, data *prompt.Data) (string, error) {
+	text, reduction, err := r.prompts.RenderBudget(template, data, r.budget)
+	if err != nil {
+		return "", err
+	}
+
+	if reduction != nil {
+		log := utils.Info
+		if reduction.OverBudget {
+			log = utils.Warn
+		}
+		log("提示词超出token预算，已压缩指标数据",
+			zap.String("account_id", r.accountID),
+			zap.String("symbol", data.Symbol),
+			zap.Int("original_tokens", reduction.OriginalTokens),
+			zap.Int("tokens", reduction.Tokens),
+			zap.Int("max_tokens", r.budget.MaxTokens),
+			zap.Strings("removed", reduction.Removed),
+			zap.Bool("over_budget", reduction.OverBudget),
+		)
+	}
+
+	utils.Info("AI提示词",
+		zap.String("account_id", r.accountID),
+		zap.String("symbol", data.Symbol),
+		zap.String("template", template),
+		zap.Int("tokens", prompt.EstimateTokens(text)),
+		zap.String("prompt", text),
+	)
+	return text, nil
+}
+
+// decide 调用AI得到交易决策，每个阶段的调用记录追加到审计记录中
+// 单次模式直接解析回复中的决策；两阶段模式先取得市场分析，再把分析结论和账户状态交给决策模板
+// 启用投票时输出决策的调用采样多次，未达成多数时返回nil（不执行）
+func (r *accountRunner) decide(ctx context.Context, rec *ai.AuditRecord, template, text string, price float64) (*executor.Decision, error) {
+	name := ai.StageSingle
+	if r.twoStage.Enabled {
+		rec.Mode = ai.ModeTwoStage
+		stage, err := r.ai.RunStage(ctx, ai.StageAnalysis, template, r.request(text))
+		rec.Stages = append(rec.Stages, stage)
+		if err != nil {
+			return nil, err
+		}
+		r.logStage(rec.Symbol, stage)
+
+		name, template = ai.StageDecision, r.twoStage.DecisionTemplate
+		text, err = r.prompts.Render(template, &prompt.DecisionData{
+			AccountID:    r.accountID,
+			Strategy:     r.account.Strategy,
+			StrategyName: r.account.GetStrategyName(),
+			Symbol:       rec.Symbol,
+			Time:         time.Now().In(r.location),
+			Analysis:     stage.Reply,
+			Account:      r.accountState(rec.Symbol, price),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("生成决策提示词失败: %w", err)
+		}
+	}
+
+	var decision *executor.Decision
+	if r.voting.Enabled {
+		stages := r.ai.SampleStage(ctx, name, template, r.request(text), r.voting.Samples, r.voting.Temperature)
+		rec.Stages = append(rec.Stages, stages...)
+		for _, stage := range stages {
+			if stage.Error == "" {
+				r.logStage(rec.Symbol, stage)
+			}
+		}
+		var vote *ai.VoteResult
+		decision, vote = ai.Vote(stages, rec.Symbol, r.voting.MinAgree)
+		rec.Vote = vote
+		if vote.Valid == 0 {
+			return nil, fmt.Errorf("%d次采样都没有得到有效决策: %s", vote.Samples, stages[0].Error)
+		}
+		if !vote.Agreed() {
+			utils.Info("AI采样未达成多数，不执行",
+				zap.String("account_id", r.accountID),
+				zap.String("symbol", rec.Symbol),
+				zap.Int("samples", vote.Samples),
+				zap.Int("required", vote.Required),
+				zap.Any("counts", vote.Counts),
+			)
+			return nil, nil
+		}
+	} else {
+		stage, err := r.ai.RunStage(ctx, name, template, r.request(text))
+		rec.Stages = append(rec.Stages, stage)
+		if err != nil {
+			return nil, err
+		}
+		r.logStage(rec.Symbol, stage)
+		if decision, err = ai.ParseDecision(stage.Reply, rec.Symbol); err != nil {
+			return nil, err
+		}
+	}
+
+	decision.AccountID = r.accountID
+	decision.Timestamp = rec.Time.UnixMilli()
+	decision.Tags = r.tags(rec)
+	return decision, nil
+}
+
+// tags 生成决策标签（模板版本按当前加载的模板计算，模型取接口返回的实际模型）
+func (r *accountRunner) tags(rec *ai.AuditRecord) *executor.DecisionTags {
+	tags := &executor.DecisionTags{
+		Template:        r.template,
+		TemplateVersion: r.prompts.Version(r.template),
+		Model:           r.model,
+		ConfigHash:      r.configHash,
+	}
+	if r.twoStage.Enabled {
+		tags.Template = r.twoStage.AnalysisTemplate + "+" + r.twoStage.DecisionTemplate
+		tags.TemplateVersion = r.prompts.Version(r.twoStage.AnalysisTemplate) + "+" + r.prompts.Version(r.twoStage.DecisionTemplate)
+	}
+	for i := len(rec.Stages) - 1; i >= 0; i-- {
+		if rec.Stages[i] != nil && rec.Stages[i].Model != "" {
+			tags.Model = rec.Stages[i].Model
+			break
+		}
+	}
+	return tags
+}
+
+// decisionConfigHash 影响账号AI决策的配置哈希（策略参数、AI参数、提示词预算及各项决策相关配置，不含API密钥）
+// 配置变化后新决策的标签随之变化，与之前的决策分为不同的对照组
+func decisionConfigHash(cfg *config.Config, account *config.Account) string {
+	aiCfg := cfg.AI
+	aiCfg.APIKey, aiCfg.AuditDir = "", ""
+	return ai.ConfigHash(map[string]interface{}{
+		"strategy":        account.Strategy,
+		"strategy_params": account.StrategyParams,
+		"ai":              aiCfg,
+		"budget":          cfg.GetPromptsConfig().Budget,
+		"ranking":         cfg.GetRankingConfig(account.Strategy),
+		"two_stage":       cfg.GetTwoStageConfig(account.Strategy),
+		"voting":          cfg.GetVotingConfig(account.Strategy),
+		"tools":           cfg.GetToolsConfig(account.Strategy),
+		"decision_cache":  cfg.GetDecisionCacheConfig(account.Strategy),
+		"veto":            cfg.GetVetoConfig(account.Strategy),
+	})
+}
+
+// cacheIgnoredFields 计算指标数据指纹时忽略的字段（每个周期都会变化，不影响判断）
+var cacheIgnoredFields = []string{"timestamp"}
+
+// cachedDecision 指标数据指纹与上次相同且未过期时返回上次决策（决策时间更新为本次分析时间），未命中时返回nil
+func (r *accountRunner) cachedDecision(rec *ai.AuditRecord) *executor.Decision {
+	decision, decidedAt := r.cache.Get(rec.Symbol, rec.PayloadHash, rec.Time)
+	if decision == nil {
+		return nil
+	}
+	rec.Mode, rec.CachedFrom = ai.ModeCached, &decidedAt
+	decision.Timestamp = rec.Time.UnixMilli()
+	utils.Info("指标数据与上次相同，复用上次AI决策",
+		zap.String("account_id", r.accountID),
+		zap.String("symbol", rec.Symbol),
+		zap.String("action", decision.Action),
+		zap.String("payload_hash", rec.PayloadHash),
+		zap.Time("decided_at", decidedAt),
+	)
+	return decision
+}
+
+// checkVeto 用风控否决规则检查决策，记录触发的规则（没有触发时返回nil）
+func (r *accountRunner) checkVeto(decision *executor.Decision, data interface{}, now time.Time) *veto.Result {
+	result := r.veto.Check(decision, data, now)
+	if result == nil {
+		return nil
+	}
+	for _, v := range result.Verdicts {
+		utils.Warn("AI决策触发风控规则",
+			zap.String("account_id", r.accountID),
+			zap.String("symbol", decision.Symbol),
+			zap.String("action", decision.Action),
+			zap.String("rule", v.Rule),
+			zap.String("veto_action", v.Action),
+			zap.String("reason", v.Reason),
+		)
+	}
+	return result
+}
+
+// orderBookSpread 按币安订单簿计算买卖价差（基点）
+func orderBookSpread(client *binance.Client) veto.SpreadFunc {
+	return func(symbol string) (float64, error) {
+		book, err := client.GetOrderBook(symbol, 5)
+		if err != nil {
+			return 0, err
+		}
+		if book.MidPrice() <= 0 {
+			return 0, fmt.Errorf("订单簿为空: %s", symbol)
+		}
+		return book.SpreadBps(), nil
+	}
+}
+
+// request 生成AI请求（启用工具调用时附带行情工具）
+func (r *accountRunner) request(text string) *ai.Request {
+	return &ai.Request{Prompt: text, Tools: r.tools, MaxToolCalls: r.maxCalls}
+}
+
+// logStage 记录一个阶段的AI回复
+func (r *accountRunner) logStage(symbol string, stage *ai.Stage) {
+	utils.Info("AI分析结果",
+		zap.String("account_id", r.accountID),
+		zap.String("symbol", symbol),
+		zap.String("stage", stage.Name),
+		zap.String("model", stage.Model),
+		zap.Int("prompt_tokens", stage.PromptTokens),
+		zap.Int("completion_tokens", stage.CompletionTokens),
+		zap.Int64("latency_ms", stage.LatencyMs),
+		zap.Int("tool_calls", len(stage.ToolCalls)),
+		zap.Bool("partial", stage.Partial),
+		zap.String("reply", stage.Reply),
+	)
+}
+
+// executeDecision 把决策交给执行器（影子账号模拟成交），返回执行结果
+// 没有执行器的实盘账号（OKX账号）通过交易所接口下单，观察账号的结果为observed；低流动性时段或每日定时平仓后不开仓期间，开仓决策不执行
+func (r *accountRunner) executeDecision(d *executor.Decision) (string, error) {
+	if r.account.GetMode() == config.AccountModeObserve {
+		utils.Info("观察账号只记录决策", zap.String("account_id", r.accountID), zap.String("symbol", d.Symbol), zap.String("action", d.Action))
+		return "observed", nil
+	}
+
+	if d.Action == executor.ActionOpenLong || d.Action == executor.ActionOpenShort {
+		if reason := r.entryBlock(time.Now()); reason != "" {
+			utils.Info("当前时段不开仓", zap.String("account_id", r.accountID), zap.String("symbol", d.Symbol), zap.String("reason", reason))
+			return "blocked: " + reason, nil
+		}
+	}
+
+	switch {
+	case r.shadow != nil:
+		record, err := r.shadow.Execute(d)
+		if err != nil {
+			return "", err
+		}
+		if record.Note != "" {
+			return record.Result + ": " + record.Note, nil
+		}
+		return record.Result, nil
+	case r.executor != nil:
+		if err := r.executor.Execute(d); err != nil {
+			return "", fmt.Errorf("执行决策失败: %w", err)
+		}
+		return "executed", nil
+	case r.market != nil && r.account.IsLive():
+		return r.executeOnExchange(d)
+	default:
+		utils.Warn("账号没有执行器，只记录决策", zap.String("account_id", r.accountID), zap.String("symbol", d.Symbol), zap.String("action", d.Action))
+		return "not_executed", nil
+	}
+}
+
+// executeOnExchange 通过交易所接口执行决策（没有币安执行器的实盘账号，如OKX）
+// 开仓按决策数量市价下单并附带止损止盈，已有持仓时忽略；平仓按持仓数量市价只减仓
+func (r *accountRunner) executeOnExchange(d *executor.Decision) (string, error) {
+	if d.Action != executor.ActionOpenLong && d.Action != executor.ActionOpenShort && d.Action != executor.ActionClose {
+		return "", nil
+	}
+
+	positions, err := r.market.GetPositions()
+	if err != nil {
+		return "", fmt.Errorf("查询持仓失败: %w", err)
+	}
+	var position float64
+	for _, p := range positions {
+		if p.Symbol == d.Symbol {
+			position = p.Quantity
+		}
+	}
+
+	req := &exchange.OrderRequest{Symbol: d.Symbol, Type: exchange.OrderTypeMarket, ClientOrderID: d.Hash()}
+	switch {
+	case d.Action == executor.ActionClose:
+		if position == 0 {
+			return "skipped: 没有持仓", nil
+		}
+		req.Side, req.Quantity, req.ReduceOnly = exchange.SideSell, position, true
+		if position < 0 {
+			req.Side, req.Quantity = exchange.SideBuy, -position
+		}
+	case position != 0:
+		return "skipped: 已有持仓", nil
+	case d.Quantity <= 0 || d.StopLoss <= 0:
+		return "", fmt.Errorf("开仓决策必须设置数量和止损价: quantity=%v stop_loss=%v", d.Quantity, d.StopLoss)
+	default:
+		req.Side, req.Quantity, req.StopLoss, req.TakeProfit = exchange.SideBuy, d.Quantity, d.StopLoss, d.TakeProfit
+		if d.Action == executor.ActionOpenShort {
+			req.Side = exchange.SideSell
+		}
+	}
+
+	order, err := r.market.PlaceOrder(req)
+	if err != nil {
+		return "", fmt.Errorf("下单失败: %w", err)
+	}
+	utils.Info("已通过交易所接口下单",
+		zap.String("account_id", r.accountID),
+		zap.String("exchange", r.market.Name()),
+		zap.String("symbol", d.Symbol),
+		zap.String("side", req.Side),
+		zap.Float64("quantity", req.Quantity),
+		zap.String("order_id", order.ID),
+		zap.String("status", order.Status),
+	)
+	return "executed", nil
+}
+
+// accountState 决策阶段使用的账户状态（余额获取失败时为0，不影响决策；price为该交易对的最新价）
+func (r *accountRunner) accountState(symbol string, price float64) *prompt.AccountState {
+	state := &prompt.AccountState{Shadow: r.shadow != nil}
+	var brackets []*executor.Bracket
+	var err error
+	switch {
+	case r.shadow != nil:
+		var status *executor.ShadowStatus
+		if status, err = r.shadow.Status(); err == nil {
+			state.Balance, brackets = status.Equity, status.Positions
+		}
+	case r.executor != nil:
+		brackets = r.executor.GetBrackets()
+		switch r.client.MarketType() {
+		case binance.MarketTypeSpot:
+			state.Balance, err = r.client.GetSpotAssetBalance("USDT")
+		case binance.MarketTypeUSDTM:
+			var balance *binance.Balance
+			if balance, err = r.client.GetBalance(); err == nil {
+				state.Balance, _ = strconv.ParseFloat(balance.Balance, 64)
+			}
+		}
+	}
+	if err != nil {
+		utils.Warn("获取账户余额失败", zap.String("account_id", r.accountID), zap.Error(err))
+	}
+
+	now := time.Now()
+	state.Positions = make([]*prompt.PositionState, 0, len(brackets))
+	for _, b := range brackets {
+		var pos *prompt.PositionState
+		if b.Symbol == symbol {
+			pos = r.positionState(b, price, now)
+			state.Position = pos
+		} else {
+			pos = r.positionState(b, 0, now)
+		}
+		state.Positions = append(state.Positions, pos)
+	}
+	return state
+}
+
+// position 账号在交易对的持仓（没有持仓时为nil；price为最新价，用于估算浮动盈亏）
+func (r *accountRunner) position(symbol string, price float64) *prompt.PositionState {
+	bracket := r.openBracket(symbol)
+	if bracket == nil {
+		return nil
+	}
+	return r.positionState(bracket, price, time.Now())
+}
+
+// positionState 括号订单转为提示词中的持仓状态（price为0时不计算浮动盈亏）
+func (r *accountRunner) positionState(b *executor.Bracket, price float64, now time.Time) *prompt.PositionState {
+	pos := &prompt.PositionState{
+		Symbol:     b.Symbol,
+		Side:       b.Side,
+		Quantity:   b.Quantity,
+		EntryPrice: b.EntryPrice,
+		StopLoss:   b.StopLoss,
+		TakeProfit: b.TakeProfit,
+		Adds:       b.Adds,
+		OpenedAt:   b.CreatedAt,
+		Holding:    formatHolding(now.Sub(b.CreatedAt)),
+	}
+	if r.maxHolding > 0 {
+		pos.MaxHolding = formatHolding(r.maxHolding)
+		pos.Overdue = r.holdingOverdue(b, now)
+	}
+	if price > 0 && b.EntryPrice > 0 {
+		pos.MarkPrice = price
+		pos.PnLPct = math.Round((price-b.EntryPrice)/b.EntryPrice*10000) / 100
+		if !b.IsLong() {
+			pos.PnLPct = -pos.PnLPct
+		}
+		// 币本位数量为合约张数，盈亏以标的币种结算，只给出涨跌幅
+		if r.client == nil || !r.client.IsCoinM() {
+			pos.PnL = math.Round(journal.GrossPnL(b.Side, b.Quantity, b.EntryPrice, price)*100) / 100
+		}
+	}
+	return pos
+}
+
+// formatHolding 持仓时长（如 2小时15分钟）
+func formatHolding(d time.Duration) string {
+	if d < time.Minute {
+		return "不到1分钟"
+	}
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%d分钟", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%d小时", hours)
+	default:
+		return fmt.Sprintf("%d小时%d分钟", hours, minutes)
+	}
+}
+
+// positionKey 持仓在决策缓存指纹中的部分（不含浮动盈亏，没有持仓时为空）
+func positionKey(pos *prompt.PositionState) string {
+	if pos == nil {
+		return ""
+	}
+	return fmt.Sprintf("|%s %g@%g sl=%g tp=%g adds=%d overdue=%v", pos.Side, pos.Quantity, pos.EntryPrice, pos.StopLoss, pos.TakeProfit, pos.Adds, pos.Overdue)
+}
+
+// activeSymbols 交易对池中不在冷却期内的交易对（刚入场或出场的交易对跳过，避免同一根K线内反复开平仓）
+// 不在交易对池中的持仓（如运行时移除的交易对）继续分析直到平仓
+func (r *accountRunner) activeSymbols() []string {
+	pool := r.poolSymbols()
+	for _, b := range r.openBrackets() {
+		if !slices.Contains(pool, b.Symbol) {
+			pool = append(pool, b.Symbol)
+		}
+	}
+	if r.cooldown <= 0 {
+		return pool
+	}
+
+	symbols := make([]string, 0, len(pool))
+	var cooling []string
+	for _, symbol := range pool {
+		if r.cooling(symbol) {
+			cooling = append(cooling, symbol)
+			continue
+		}
+		symbols = append(symbols, symbol)
+	}
+
+	if len(cooling) > 0 {
+		utils.Info("交易对处于决策冷却期，本周期跳过",
+			zap.String("account_id", r.accountID),
+			zap.Strings("symbols", cooling),
+			zap.Duration("cooldown", r.cooldown),
+		)
+	}
+	return symbols
+}
+
+// cooling 交易对是否处于决策冷却期（入场或出场后 cooldown 时间内）
+func (r *accountRunner) cooling(symbol string) bool {
+	if r.cooldown <= 0 {
+		return false
+	}
+	var last time.Time
+	switch {
+	case r.executor != nil:
+		last = r.executor.LastActionAt(symbol)
+	case r.shadow != nil:
+		last = r.shadow.LastActionAt(symbol)
+	}
+	return !last.IsZero() && time.Since(last) < r.cooldown
+}
+
+// withVolumeSpikes 把成交量异动的交易对作为临时候选加入本周期（已在交易对池中的、处于决策冷却期的不重复加入）
+// 候选与交易对池一样经过策略计算和AI分析，启用行情告警时同时发出 volume_spike 告警
+func (r *accountRunner) withVolumeSpikes(symbols []string) []string {
+	spikes := r.spikes.Candidates(r.maxSpikes)
+	if len(spikes) == 0 {
+		return symbols
+	}
+
+	var added []string
+	for _, spike := range spikes {
+		if slices.Contains(symbols, spike.Symbol) {
+			continue
+		}
+		var last time.Time
+		switch {
+		case r.executor != nil:
+			last = r.executor.LastActionAt(spike.Symbol)
+		case r.shadow != nil:
+			last = r.shadow.LastActionAt(spike.Symbol)
+		}
+		if !last.IsZero() && time.Since(last) < r.cooldown {
+			continue
+		}
+		added = append(added, spike.Symbol)
+		r.alerts.Notify(alert.Event{
+			Kind:    "volume_spike",
+			Symbol:  spike.Symbol,
+			Level:   alert.LevelInfo,
+			Message: fmt.Sprintf("%s %s K线成交量为前%d根平均的%.1f倍，涨跌 %.2f%%", spike.Symbol, spike.Interval, r.spikes.Lookback(), spike.Ratio, spike.ChangePct),
+			Values: map[string]float64{
+				"ratio":      spike.Ratio,
+				"volume":     spike.Volume,
+				"avg_volume": spike.AvgVolume,
+				"change_pct": spike.ChangePct,
+			},
+		})
+	}
+	if len(added) == 0 {
+		return symbols
+	}
+	utils.Info("成交量异动候选加入本周期分析",
+		zap.String("account_id", r.accountID),
+		zap.Strings("symbols", added),
+	)
+	return append(append(make([]string, 0, len(symbols)+len(added)), symbols...), added...)
+}
+
+// outputIndicators 输出指标数据（JSON格式）
+func outputIndicators(data interface{}, accountID, strategy string) {
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		utils.Error("序列化JSON失败", zap.Error(err))
+		return
+	}
+
+	utils.Info("指标数据",
+		zap.String("account_id", accountID),
+		zap.String("strategy", strategy),
+		zap.String("json", string(jsonData)),
+	)
+
+}
+
+// accountStatus 账号状态（状态API返回）
+type accountStatus struct {
+	AccountID  string                  `json:"account_id"`
+	Name       string                  `json:"name"`
+	Strategy   string                  `json:"strategy"`
+	Exchange   string                  `json:"exchange"`
+	MarketType string                  `json:"market_type"`
+	Mode       string                  `json:"mode"`
+	Symbols    int                     `json:"symbols"`            // 交易对池数量
+	Brackets   []*executor.Bracket     `json:"brackets,omitempty"` // 生效中的括号订单（仅币安账号）
+	Theses     []*executor.Thesis      `json:"theses,omitempty"`   // 持仓逻辑及重复信号确认次数（仅币安账号）
+	Breaker    *executor.BreakerStatus `json:"breaker,omitempty"`  // 回撤熔断状态（仅启用熔断的账号）
+	Shadow     *executor.ShadowStatus  `json:"shadow,omitempty"`   // 虚拟账户状态（仅影子账号）
+	Disabled   *toggle.State           `json:"disabled,omitempty"` // 运行时停用状态（仅停用的账号）
+}
+
+// registerRoutes 注册状态API接口
+// GET /api/status              各账号状态
+// GET /api/portfolio/exposure  跨账号按交易对汇总的多空及净敞口
+// GET /api/portfolio/risk      组合风险报告（敞口、相关性调整风险、保证金使用率、止损全部触发的亏损）
+// POST /api/accounts/{id}/rearm 回撤熔断后手动恢复交易
+// POST /api/accounts/{id}/api-key 轮换账号的API密钥（请求体 api_key、api_secret，先检查新密钥的权限；需配置api.token）
+// POST /api/accounts/{id}/disable 停用账号（请求体可选 positions: manage 或 close、reason）
+// POST /api/accounts/{id}/enable  恢复停用的账号
+// POST /api/accounts/{id}/symbols/add 向账号的交易对池加入交易对（请求体 symbols，先按交易规则检查）
+// POST /api/accounts/{id}/symbols/remove 从账号的交易对池移除交易对（请求体 symbols）
+// GET /api/symbols             运行时的交易对池调整、各账号当前的交易对池和交易对错误熔断状态
+// POST /api/symbols/add        向全部账号的交易对池加入交易对（请求体 symbols，每个账号都通过检查才生效）
+// POST /api/symbols/remove     从全部账号的交易对池移除交易对（请求体 symbols）
+// GET /api/accounts/{id}/metrics 按交易日志计算的绩效指标（可选参数 initial_balance）
+// POST /api/accounts/{id}/decisions 向影子账号提交决策（模拟成交，不下真实订单）
+// GET /api/ai/calibration      各账号、各模型的AI置信度校准曲线（可选参数 bins）
+// GET /api/ai/cohorts          各账号按决策标签（模板版本、模型、配置哈希）分组的决策绩效
+// GET /api/marketdata/stats    共享行情数据服务的缓存命中统计（按交易所/市场类型）及公开行情请求合并统计
+// GET /api/indicators/telemetry 指标计算耗时统计（按指标函数、策略指标类型、交易对；可选参数 reset=true 读取后清空）
+// GET /api/indicators/schema   指标数据结构版本和各版本的字段变更
+// GET /api/indicators/history  审计记录中的指标快照（参数 symbol，可选参数 timeframe、from、to、limit、account_id）
+// GET /api/indicators/series   审计记录中的持仓量、资金费率序列（参数 symbol，可选参数 from、to、limit、account_id）
+// GET /api/streams/markprice   标记价格推送的连接状态和最新数据（按合约市场类型；可选参数 symbol 只返回指定交易对，并返回1分钟K线）
+// GET /api/streams/depth       本地订单簿的同步状态（按合约市场类型；可选参数 symbol、limit 返回指定交易对的前limit档）
+// GET /api/streams/liquidations 强平推送的连接状态和进行中的连环强平（可选参数 symbol 返回该交易对各窗口的强平统计和每分钟汇总）
+// GET /api/streams/indicators  秒级增量指标（按合约市场类型；可选参数 symbol 只返回指定交易对）
+// GET /api/alerts              最近的行情告警及统计（可选参数 limit、kind、symbol）
+// GET /api/screener/volume     最近一次成交量异动筛选的结果
+// GET /api/screener/pumpdump   各账号最近一个策略周期的拉盘/砸盘筛选报告（可选参数 account_id）
+// GET /api/exchange/status     交易所系统状态和暂停交易的交易对
+func registerRoutes(srv *server.Server, runners []*accountRunner, riskCfg config.RiskReportConfig, tradeJournal *journal.Journal,
+	aiAudit *ai.Audit, calibrationCfg config.CalibrationConfig, marketPool *marketdata.Pool,
+	markPrices map[string]*binance.MarkPriceStream, depthBooks map[string]*binance.DepthStream, liquidations *binance.LiquidationStream,
+	liveIndicators map[string]*indicators.LiveTracker, notifier *alert.Notifier, volumeScreener *scanner.VolumeScreener,
+	pumpDump *scanner.PumpDumpScreener, exchangeStatus *binance.StatusMonitor, panicSwitch *emergency.Switch, loc *time.Location) {
+	srv.HandleJSON("GET", "/api/status", func(r *http.Request) (interface{}, error) {
+		statuses := make([]accountStatus, 0, len(runners))
+		for _, runner := range runners {
+			status := accountStatus{
+				AccountID:  runner.accountID,
+				Name:       runner.account.Name,
+				Strategy:   runner.account.Strategy,
+				Exchange:   runner.account.GetExchange(),
+				MarketType: runner.account.GetMarketType(),
+				Mode:       runner.account.GetMode(),
+				Symbols:    len(runner.poolSymbols()),
+				Disabled:   runner.toggles.Get(runner.accountID),
+			}
+			if runner.executor != nil {
+				status.Brackets = runner.executor.GetBrackets()
+				status.Theses = runner.executor.GetTheses()
+				if runner.account.CircuitBreaker.MaxDrawdownPct > 0 {
+					breaker := runner.executor.BreakerStatus()
+					status.Breaker = &breaker
+				}
+			}
+			if runner.shadow != nil {
+				shadowStatus, err := runner.shadow.Status()
+				if err != nil {
+					return nil, err
+				}
+				status.Brackets = shadowStatus.Positions
+				status.Shadow = shadowStatus
+			}
+			statuses = append(statuses, status)
+		}
+		return statuses, nil
+	})
+
+	srv.HandleJSON("GET", "/api/portfolio/exposure", func(r *http.Request) (interface{}, error) {
+		return portfolio.Aggregate(portfolioAccounts(runners)), nil
+	})
+
+	srv.HandleJSON("GET", "/api/portfolio/risk", func(r *http.Request) (interface{}, error) {
+		return portfolio.BuildRiskReport(portfolioAccounts(runners), klineMarket(runners), riskCfg), nil
+	})
+
+	srv.HandleJSON("POST", "/api/accounts/{id}/rearm", func(r *http.Request) (interface{}, error) {
+		runner, err := findRunner(runners, r.PathValue("id"))
+		if err != nil {
+			return nil, err
+		}
+		if runner.executor == nil {
+			return nil, server.BadRequest("账号[%s]没有执行器（非币安账号）", runner.accountID)
+		}
+		return runner.executor.Rearm()
+	})
+
+	// 轮换API密钥：检查新密钥通过后替换账号全部客户端的密钥，进行中的请求用旧密钥完成
+	srv.HandleJSON("POST", "/api/accounts/{id}/api-key", func(r *http.Request) (interface{}, error) {
+		runner, err := findRunner(runners, r.PathValue("id"))
+		if err != nil {
+			return nil, err
+		}
+		var req struct {
+			APIKey    string `json:"api_key"`
+			APISecret string `json:"api_secret"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, server.BadRequest("解析请求失败: %v", err)
+		}
+		if runner.keys == nil {
+			return nil, server.BadRequest("账号[%s]不是币安实盘账号，不支持轮换API密钥", runner.accountID)
+		}
+		rotated, err := runner.rotateKey(req.APIKey, req.APISecret, "api")
+		if err != nil {
+			return nil, server.BadRequest("%v", err)
+		}
+		return map[string]interface{}{"account_id": runner.accountID, "rotated": rotated}, nil
+	})
+
+	// 停用账号：不再开仓，已有持仓继续管理（manage）或立即平仓（close）；状态保存到文件，重启后保持
+	srv.HandleJSON("POST", "/api/accounts/{id}/disable", func(r *http.Request) (interface{}, error) {
+		runner, err := findRunner(runners, r.PathValue("id"))
+		if err != nil {
+			return nil, err
+		}
+		var req struct {
+			Positions string `json:"positions"` // manage（默认）或 close
+			Reason    string `json:"reason"`    // 停用原因
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, server.BadRequest("解析请求失败: %v", err)
+			}
+		}
+		if req.Positions != "" && req.Positions != toggle.PositionsManage && req.Positions != toggle.PositionsClose {
+			return nil, server.BadRequest("positions无效: %s (必须是 manage 或 close)", req.Positions)
+		}
+		if req.Positions == toggle.PositionsClose && runner.executor == nil && runner.shadow == nil {
+			return nil, server.BadRequest("账号[%s]没有执行器，不能平仓", runner.accountID)
+		}
+		state, err := runner.disable(req.Positions, req.Reason, "api")
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"account_id": runner.accountID, "disabled": state}, nil
+	})
+
+	srv.HandleJSON("POST", "/api/accounts/{id}/enable", func(r *http.Request) (interface{}, error) {
+		runner, err := findRunner(runners, r.PathValue("id"))
+		if err != nil {
+			return nil, err
+		}
+		enabled, err := runner.enable("api")
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"account_id": runner.accountID, "enabled": enabled}, nil
+	})
+
+	// 交易对池：运行时的调整（* 为全部账号）、各账号当前的交易对池和交易对错误熔断状态（只含有连续失败的账号）
+	srv.HandleJSON("GET", "/api/symbols", func(r *http.Request) (interface{}, error) {
+		pools := make(map[string][]string, len(runners))
+		breakers := make(map[string][]strategy.SymbolBreakerState)
+		for _, runner := range runners {
+			pools[runner.accountID] = runner.poolSymbols()
+			if states := runner.breaker.Status(); len(states) > 0 {
+				breakers[runner.accountID] = states
+			}
+		}
+		overrides := map[string]*symbolpool.Changes{}
+		if len(runners) > 0 {
+			overrides = runners[0].pool.All()
+		}
+		return map[string]interface{}{"overrides": overrides, "pools": pools, "breakers": breakers}, nil
+	})
+
+	srv.HandleJSON("POST", "/api/symbols/add", func(r *http.Request) (interface{}, error) {
+		return changeSymbols(r, runners, symbolpool.ScopeAll, true)
+	})
+
+	srv.HandleJSON("POST", "/api/symbols/remove", func(r *http.Request) (interface{}, error) {
+		return changeSymbols(r, runners, symbolpool.ScopeAll, false)
+	})
+
+	srv.HandleJSON("POST", "/api/accounts/{id}/symbols/add", func(r *http.Request) (interface{}, error) {
+		runner, err := findRunner(runners, r.PathValue("id"))
+		if err != nil {
+			return nil, err
+		}
+		return changeSymbols(r, []*accountRunner{runner}, runner.accountID, true)
+	})
+
+	srv.HandleJSON("POST", "/api/accounts/{id}/symbols/remove", func(r *http.Request) (interface{}, error) {
+		runner, err := findRunner(runners, r.PathValue("id"))
+		if err != nil {
+			return nil, err
+		}
+		return changeSymbols(r, []*accountRunner{runner}, runner.accountID, false)
+	})
+
+	// 紧急平仓：不带confirm时返回确认码，在有效期内带上确认码再次请求才执行
+	srv.HandleJSON("POST", "/api/close-all", func(r *http.Request) (interface{}, error) {
+		var req struct {
+			Accounts []string `json:"accounts"` // 账号ID（为空表示全部账号）
+			Confirm  string   `json:"confirm"`  // 确认码
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, server.BadRequest("解析请求失败: %v", err)
+			}
+		}
+		if req.Confirm == "" {
+			c, err := panicSwitch.Request(req.Accounts, "api")
+			if err != nil {
+				return nil, server.BadRequest("%v", err)
+			}
+			return c, nil
+		}
+		record, err := panicSwitch.Confirm(req.Confirm, "api")
+		if err != nil {
+			return nil, server.BadRequest("%v", err)
+		}
+		return record, nil
+	})
+
+	srv.HandleJSON("GET", "/api/accounts/{id}/metrics", func(r *http.Request) (interface{}, error) {
+		runner, err := findRunner(runners, r.PathValue("id"))
+		if err != nil {
+			return nil, err
+		}
+		trades, err := tradeJournal.Load(runner.accountID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 未指定初始资金时按 当前钱包余额 - 日志净盈亏 推算（期间有出入金时不准确）
+		var initial float64
+		if v := r.URL.Query().Get("initial_balance"); v != "" {
+			if initial, err = strconv.ParseFloat(v, 64); err != nil || initial <= 0 {
+				return nil, server.BadRequest("initial_balance无效: %s", v)
+			}
+		} else if runner.shadow != nil {
+			initial = runner.shadow.InitialBalance()
+		} else if !runner.account.IsLive() {
+			return nil, server.BadRequest("观察账号不查询余额，需要指定initial_balance")
+		} else {
+			if runner.account.GetMarketType() != "usdt_m" {
+				return nil, server.BadRequest("非U本位合约账号需要指定initial_balance")
+			}
+			balance, err := runner.market.GetBalance("USDT")
+			if err != nil {
+				return nil, fmt.Errorf("查询余额失败: %w", err)
+			}
+			initial = balance.Total - journal.Summarize(trades).NetPnL
+		}
+
+		return journal.CalculateMetricsIn(trades, initial, time.Time{}, time.Time{}, loc), nil
+	})
+
+	srv.HandleJSON("POST", "/api/accounts/{id}/decisions", func(r *http.Request) (interface{}, error) {
+		runner, err := findRunner(runners, r.PathValue("id"))
+		if err != nil {
+			return nil, err
+		}
+		if runner.shadow == nil {
+			return nil, server.BadRequest("账号[%s]不是影子账号，不接受API提交的决策", runner.accountID)
+		}
+
+		var decision executor.Decision
+		if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+			return nil, server.BadRequest("解析决策失败: %v", err)
+		}
+		if decision.Symbol == "" || decision.Action == "" {
+			return nil, server.BadRequest("symbol和action不能为空")
+		}
+		if decision.Timestamp == 0 {
+			decision.Timestamp = time.Now().UnixMilli()
+		}
+		return runner.shadow.Execute(&decision)
+	})
+
+	srv.HandleJSON("GET", "/api/ai/calibration", func(r *http.Request) (interface{}, error) {
+		if aiAudit == nil {
+			return nil, server.BadRequest("未启用AI，没有审计记录")
+		}
+		bins := calibrationCfg.Bins
+		if v := r.URL.Query().Get("bins"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > 100 {
+				return nil, server.BadRequest("bins无效（1-100）: %s", v)
+			}
+			bins = n
+		}
+		return ai.BuildCalibrationReport(aiAudit, tradeJournal, runnerIDs(runners), bins), nil
+	})
+
+	srv.HandleJSON("GET", "/api/ai/cohorts", func(r *http.Request) (interface{}, error) {
+		if aiAudit == nil {
+			return nil, server.BadRequest("未启用AI，没有审计记录")
+		}
+		return ai.BuildCohortReport(aiAudit, tradeJournal, runnerIDs(runners)), nil
+	})
+
+	srv.HandleJSON("GET", "/api/marketdata/stats", func(r *http.Request) (interface{}, error) {
+		return map[string]interface{}{
+			"services": marketPool.Stats(),
+			"coalesce": binance.GetCoalesceStats(),
+		}, nil
+	})
+
+	srv.HandleJSON("GET", "/api/indicators/telemetry", func(r *http.Request) (interface{}, error) {
+		snapshot := indicators.Telemetry()
+		if r.URL.Query().Get("reset") == "true" {
+			indicators.ResetTelemetry()
+		}
+		return snapshot, nil
+	})
+
+	srv.HandleJSON("GET", "/api/indicators/schema", func(r *http.Request) (interface{}, error) {
+		return map[string]interface{}{
+			"current": schemas.Current(),
+			"history": schemas.History(),
+		}, nil
+	})
+
+	srv.HandleJSON("GET", "/api/indicators/history", func(r *http.Request) (interface{}, error) {
+		if aiAudit == nil {
+			return nil, server.BadRequest("未启用AI，没有审计记录")
+		}
+		q, accountIDs, err := snapshotQuery(r, runners)
+		if err != nil {
+			return nil, err
+		}
+		return ai.QuerySnapshots(aiAudit, accountIDs, q), nil
+	})
+
+	srv.HandleJSON("GET", "/api/indicators/series", func(r *http.Request) (interface{}, error) {
+		if aiAudit == nil {
+			return nil, server.BadRequest("未启用AI，没有审计记录")
+		}
+		q, accountIDs, err := snapshotQuery(r, runners)
+		if err != nil {
+			return nil, err
+		}
+		return ai.QuerySeries(aiAudit, accountIDs, q), nil
+	})
+
+	srv.HandleJSON("GET", "/api/streams/markprice", func(r *http.Request) (interface{}, error) {
+		symbol := strings.ToUpper(r.URL.Query().Get("symbol"))
+		result := make(map[string]interface{}, len(markPrices))
+		for marketType, stream := range markPrices {
+			prices := stream.Snapshot()
+			entry := map[string]interface{}{
+				"status":      stream.Status(),
+				"subscribers": stream.Subscribers(),
+			}
+			if symbol != "" {
+				prices = nil
+				if update, ok := stream.Get(symbol); ok {
+					prices = append(prices, update)
+				}
+				entry["candles"] = stream.Candles(symbol, 0)
+			}
+			entry["prices"] = prices
+			result[marketType] = entry
+		}
+		return result, nil
+	})
+
+	srv.HandleJSON("GET", "/api/streams/depth", func(r *http.Request) (interface{}, error) {
+		symbol := strings.ToUpper(r.URL.Query().Get("symbol"))
+		limit := 20
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, server.BadRequest("limit必须是正整数")
+			}
+			limit = n
+		}
+		result := make(map[string]interface{}, len(depthBooks))
+		for marketType, books := range depthBooks {
+			entry := map[string]interface{}{"books": books.Status(), "connections": books.Connections(), "subscribers": books.Subscribers()}
+			if symbol != "" {
+				book, _ := books.OrderBook(symbol, limit)
+				entry["order_book"] = book
+			}
+			result[marketType] = entry
+		}
+		return result, nil
+	})
+
+	srv.HandleJSON("GET", "/api/streams/liquidations", func(r *http.Request) (interface{}, error) {
+		if liquidations == nil {
+			return nil, server.BadRequest("未启用强平推送")
+		}
+		result := map[string]interface{}{
+			"status":      liquidations.Status(),
+			"cascades":    liquidations.Cascades(),
+			"subscribers": liquidations.Subscribers(),
+		}
+		if symbol := strings.ToUpper(r.URL.Query().Get("symbol")); symbol != "" {
+			result["stats"] = liquidations.Stats(symbol)
+			result["totals"] = liquidations.Totals(symbol)
+		}
+		return result, nil
+	})
+
+	srv.HandleJSON("GET", "/api/streams/indicators", func(r *http.Request) (interface{}, error) {
+		symbol := strings.ToUpper(r.URL.Query().Get("symbol"))
+		result := make(map[string]interface{}, len(liveIndicators))
+		for marketType, tracker := range liveIndicators {
+			snapshots := []indicators.LiveSnapshot{}
+			for _, snapshot := range tracker.Snapshot() {
+				if symbol == "" || snapshot.Symbol == symbol {
+					snapshots = append(snapshots, snapshot)
+				}
+			}
+			result[marketType] = snapshots
+		}
+		return result, nil
+	})
+
+	srv.HandleJSON("GET", "/api/alerts", func(r *http.Request) (interface{}, error) {
+		if notifier == nil {
+			return nil, server.BadRequest("未启用行情告警")
+		}
+		limit := 50
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, server.BadRequest("limit必须是正整数")
+			}
+			limit = n
+		}
+		query := r.URL.Query()
+		return map[string]interface{}{
+			"stats":  notifier.Stats(),
+			"events": notifier.Recent(limit, query.Get("kind"), strings.ToUpper(query.Get("symbol"))),
+		}, nil
+	})
+
+	srv.HandleJSON("GET", "/api/screener/volume", func(r *http.Request) (interface{}, error) {
+		if volumeScreener == nil {
+			return nil, server.BadRequest("未启用成交量异动筛选")
+		}
+		report := volumeScreener.Report()
+		if report == nil {
+			return nil, server.BadRequest("成交量异动筛选还没有完成第一次扫描")
+		}
+		return report, nil
+	})
+
+	srv.HandleJSON("GET", "/api/screener/pumpdump", func(r *http.Request) (interface{}, error) {
+		if pumpDump == nil {
+			return nil, server.BadRequest("未启用拉盘/砸盘筛选")
+		}
+		reports := pumpDump.Reports()
+		if id := r.URL.Query().Get("account_id"); id != "" {
+			if _, err := findRunner(runners, id); err != nil {
+				return nil, err
+			}
+			return reports[id], nil
+		}
+		return reports, nil
+	})
+
+	srv.HandleJSON("GET", "/api/exchange/status", func(r *http.Request) (interface{}, error) {
+		if exchangeStatus == nil {
+			return nil, server.BadRequest("未启用交易所状态检测")
+		}
+		return exchangeStatus.Status(), nil
+	})
+}
+
+// runnerIDs 所有账号ID
+func runnerIDs(runners []*accountRunner) []string {
+	ids := make([]string, 0, len(runners))
+	for _, runner := range runners {
+		ids = append(ids, runner.accountID)
+	}
+	return ids
+}
+
+// binanceClient 按账号的市场类型创建币安客户端（OKX账号返回nil）
+func binanceClient(cfg *config.Config, account *config.Account) *binance.Client {
+	var client *binance.Client
+	switch {
+	case account.GetExchange() == exchange.NameOKX:
+		return nil
+	case account.GetMarketType() == binance.MarketTypeCoinM:
+		client = binance.NewCoinMClient(account.APIKey, account.APISecret, cfg.Binance.DeliveryURL, cfg.GetProxyURL())
+	case account.GetMarketType() == binance.MarketTypeSpot:
+		client = binance.NewSpotClient(account.APIKey, account.APISecret, cfg.Binance.SpotURL, cfg.GetProxyURL())
+	default:
+		client = binance.NewClient(account.APIKey, account.APISecret, cfg.Binance.FuturesURL, cfg.GetProxyURL())
+	}
+	client.SetRecvWindow(cfg.Binance.RecvWindow)
+	return client
+}
+
+// checkAPIKeys 检查币安实盘账号的API密钥权限（影子账号、观察账号、OKX账号不检查），全部通过时返回true
+func checkAPIKeys(cfg *config.Config) bool {
+	var failed []string
+	for _, account := range cfg.GetEnabledAccounts() {
+		if account.GetExchange() != exchange.NameBinance || !account.IsLive() {
+			continue
+		}
+		report := checkAPIKey(cfg, &account, account.APIKey, account.APISecret)
+		if report.Failed() {
+			failed = append(failed, report.String())
+		} else {
+			utils.Info(report.String())
+		}
+	}
+	if len(failed) > 0 {
+		utils.Error("API密钥检查未通过:\n" + strings.Join(failed, "\n"))
+		return false
+	}
+	return true
+}
+
+// resolveSecrets 把账号的 api_key、api_secret、passphrase 中的密钥引用替换为密钥值（每次重新读取密钥文件和Vault）
+func resolveSecrets(cfg *config.Config, accounts []config.Account) error {
+	return secrets.NewResolver(cfg.GetSecretsConfig()).ResolveAccounts(accounts)
+}
+
+// checkAPIKey 按账号的用途检查一组API密钥（通过现货域名的权限接口）
+func checkAPIKey(cfg *config.Config, account *config.Account, apiKey, apiSecret string) *binance.KeyReport {
+	spot := binance.NewSpotClient(apiKey, apiSecret, cfg.Binance.SpotURL, cfg.GetProxyURL())
+	spot.SetRecvWindow(cfg.Binance.RecvWindow)
+	return binance.CheckKey(spot, account.ID, binance.KeyRequirement{
+		MarketType:        account.GetMarketType(),
+		Transfer:          account.Treasury.Enabled,
+		RequireIPRestrict: cfg.KeyCheck.RequireIPRestrict,
+		AllowWithdrawals:  cfg.KeyCheck.AllowWithdrawals,
+	})
+}
+
+// accountKeys 币安实盘账号的密钥组，轮换前检查新密钥（未配置spot_url时不检查）；影子账号、观察账号、OKX账号返回nil
+func accountKeys(cfg *config.Config, account config.Account, client *binance.Client) *binance.KeyRing {
+	if client == nil || !account.IsLive() {
+		return nil
+	}
+	keys := binance.NewKeyRing(client)
+	if cfg.Binance.SpotURL != "" {
+		keys.SetVerifier(func(apiKey, apiSecret string) error {
+			if report := checkAPIKey(cfg, &account, apiKey, apiSecret); report.Failed() {
+				return errors.New(report.String())
+			}
+			return nil
+		})
+	}
+	return keys
+}
+
+// reloadAPIKeys 重新读取账号配置文件（相对于 configs/config.yml），轮换API密钥有变化的账号
+func reloadAPIKeys(cfg *config.Config, runners []*accountRunner) {
+	accounts, err := config.LoadAccounts(filepath.Join("configs", cfg.AccountsConfig))
+	if err == nil {
+		err = resolveSecrets(cfg, accounts)
+	}
+	if err != nil {
+		utils.Error("重新读取账号配置失败，API密钥保持不变", zap.Error(err))
+		return
+	}
+	for _, r := range runners {
+		if r.keys == nil {
+			continue
+		}
+		i := slices.IndexFunc(accounts, func(a config.Account) bool { return a.ID == r.accountID })
+		if i < 0 {
+			continue
+		}
+		// 失败时已记录错误，账号继续使用旧密钥
+		r.rotateKey(accounts[i].APIKey, accounts[i].APISecret, "reload")
+	}
+}
+
+// accountTreasury 创建账号的合约资金调拨，划转成功后发送告警（创建失败时返回nil）
+func accountTreasury(cfg *config.Config, r *accountRunner) *treasury.Treasury {
+	spot := binance.NewSpotClient(r.account.APIKey, r.account.APISecret, cfg.Binance.SpotURL, cfg.GetProxyURL())
+	spot.SetRecvWindow(cfg.Binance.RecvWindow)
+	if r.keys != nil {
+		r.keys.Add(spot)
+	}
+	t, err := treasury.New(r.accountID, r.account.GetMarketType(), r.client, spot, r.account.GetTreasuryConfig())
+	if err != nil {
+		utils.Error("创建合约资金调拨失败", zap.String("account_id", r.accountID), zap.Error(err))
+		return nil
+	}
+	t.OnTransfer(func(tr *treasury.Transfer) {
+		// 划转改变的是合约账户的资金而不是盈亏，同步调整熔断峰值，避免转出被计为回撤
+		if r.executor != nil {
+			amount := tr.Amount
+			if tr.Direction == treasury.DirectionOut {
+				amount = -amount
+			}
+			r.executor.AdjustForTransfer(amount)
+		}
+
+		message := fmt.Sprintf("账号 %s 合约可用余额 %.2f 低于下限，从现货转入 %v %s", r.accountID, tr.Available, tr.Amount, tr.Asset)
+		if tr.Direction == treasury.DirectionOut {
+			message = fmt.Sprintf("账号 %s 合约可用余额 %.2f 高于上限，转出 %v %s 到现货", r.accountID, tr.Available, tr.Amount, tr.Asset)
+		}
+		r.alerts.Notify(alert.Event{
+			Time:    tr.Time,
+			Kind:    "treasury_transfer",
+			Level:   alert.LevelInfo,
+			Message: message,
+			Values:  map[string]float64{"amount": tr.Amount, "available": tr.Available},
+		})
+	})
+	return t
+}
+
+// emergencySwitch 注册有执行器的账号（含影子账号），紧急平仓前先停用账号（保存到文件，重启后仍不开仓），完成后发送告警
+func emergencySwitch(runners []*accountRunner, dir string, notifier *alert.Notifier) *emergency.Switch {
+	sw := emergency.New(dir)
+	for _, r := range runners {
+		if r.executor == nil && r.shadow == nil {
+			continue
+		}
+		sw.Register(emergency.Account{
+			ID: r.accountID,
+			CloseAll: func() ([]string, error) {
+				// 保存停用状态失败时仍然平仓，平仓比暂停开仓更紧急
+				if _, err := r.toggles.Disable(r.accountID, toggle.PositionsManage, "紧急平仓", "emergency"); err != nil {
+					utils.Error("紧急平仓停用账号失败", zap.String("account_id", r.accountID), zap.Error(err))
+				}
+				return r.closePositions()
+			},
+		})
+	}
+	sw.OnExecute(func(record *emergency.Record) {
+		notifier.Notify(alert.Event{
+			Time:    record.Time,
+			Kind:    "emergency_close_all",
+			Level:   alert.LevelCritical,
+			Message: fmt.Sprintf("紧急平仓（来源 %s）: %s", record.Source, emergencySummary(record)),
+		})
+	})
+	return sw
+}
+
+// emergencySummary 紧急平仓结果的文字说明（每个账号一段）
+func emergencySummary(record *emergency.Record) string {
+	parts := make([]string, 0, len(record.Results))
+	for _, res := range record.Results {
+		part := fmt.Sprintf("%s 平仓%d个", res.AccountID, len(res.Closed))
+		if len(res.Closed) > 0 {
+			part += "（" + strings.Join(res.Closed, ", ") + "）"
+		}
+		if res.Error != "" {
+			part += "，失败: " + res.Error
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "；")
+}
+
+// registerTelegramCommands 注册Telegram命令：/closeall 申请紧急平仓，/confirm 确认
+func registerTelegramCommands(bot *telegram.Bot, panicSwitch *emergency.Switch) {
+	bot.Handle("closeall", "[账号ID...] 紧急平仓：撤销全部挂单并市价平掉全部持仓（不指定账号表示全部账号，需要确认）",
+		func(chatID int64, args []string) string {
+			c, err := panicSwitch.Request(args, fmt.Sprintf("telegram:%d", chatID))
+			if err != nil {
+				return "紧急平仓申请失败: " + err.Error()
+			}
+			return fmt.Sprintf("将撤销全部挂单并市价平掉以下账号的全部持仓：%s\n%d秒内发送 /confirm %s 确认",
+				strings.Join(c.Accounts, ", "), int(emergency.ConfirmTTL.Seconds()), c.Code)
+		})
+	bot.Handle("confirm", "<确认码> 确认紧急平仓", func(chatID int64, args []string) string {
+		if len(args) != 1 {
+			return "用法: /confirm <确认码>"
+		}
+		record, err := panicSwitch.Confirm(args[0], fmt.Sprintf("telegram:%d", chatID))
+		if err != nil {
+			return "紧急平仓失败: " + err.Error()
+		}
+		return "紧急平仓完成: " + emergencySummary(record)
+	})
+}
+
+// closeAllCommand 命令行紧急平仓：撤销所选币安实盘账号的全部挂单并市价平掉全部持仓，返回退出码
+// 影子账号的模拟持仓由运行中的程序管理，需通过状态API或Telegram命令平仓
+func closeAllCommand(args []string) int {
+	fs := flag.NewFlagSet("close-all", flag.ContinueOnError)
+	configPath := fs.String("config", "configs/config.yml", "配置文件路径")
+	accountIDs := fs.String("accounts", "", "账号ID，逗号分隔（为空表示全部启用的币安实盘账号）")
+	yes := fs.Bool("yes", false, "不询问，直接执行")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Printf("加载配置失败: %v\n", err)
+		return 1
+	}
+	if err := resolveSecrets(cfg, cfg.Accounts); err != nil {
+		fmt.Printf("读取账号密钥失败: %v\n", err)
+		return 1
+	}
+	tradeJournal, err := journal.New(cfg.GetJournalConfig().Dir)
+	if err != nil {
+		fmt.Printf("创建交易日志失败: %v\n", err)
+		return 1
+	}
+
+	sw := emergency.New(cfg.GetJournalConfig().Dir)
+	for _, account := range cfg.GetEnabledAccounts() {
+		client := binanceClient(cfg, &account)
+		if client == nil || !account.IsLive() {
+			continue
+		}
+		symbols := cfg.SymbolPool.DefaultSymbols
+		if account.GetMarketType() == binance.MarketTypeCoinM {
+			symbols = make([]string, len(cfg.SymbolPool.DefaultSymbols))
+			for i, s := range cfg.SymbolPool.DefaultSymbols {
+				symbols[i] = binance.CoinMSymbol(s)
+			}
+		}
+		exec := executor.NewExecutor(account.ID, client)
+		exec.SetJournal(tradeJournal)
+		sw.Register(emergency.Account{
+			ID: account.ID,
+			CloseAll: func() ([]string, error) {
+				return exec.CloseAll(symbols)
+			},
+		})
+	}
+
+	var ids []string
+	if *accountIDs != "" {
+		ids = strings.Split(*accountIDs, ",")
+	}
+	c, err := sw.Request(ids, "cli")
+	if err != nil {
+		fmt.Printf("紧急平仓失败: %v\n", err)
+		return 1
+	}
+	fmt.Printf("将撤销全部挂单并市价平掉以下账号的全部持仓：%s\n", strings.Join(c.Accounts, ", "))
+	if !*yes {
+		fmt.Print("输入 yes 确认: ")
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(line) != "yes" {
+			fmt.Println("已取消")
+			return 1
+		}
+	}
+
+	record, err := sw.Confirm(c.Code, "cli")
+	if err != nil {
+		fmt.Printf("紧急平仓失败: %v\n", err)
+		return 1
+	}
+	for _, res := range record.Results {
+		fmt.Printf("%s: 平仓 %v", res.AccountID, res.Closed)
+		if res.Error != "" {
+			fmt.Printf("，失败: %s", res.Error)
+		}
+		fmt.Println()
+	}
+	if record.Failed() {
+		return 1
+	}
+	return 0
+}
+
+// changeSymbols 运行时向交易对池加入或移除交易对（请求体 symbols），scope 为 symbolpool.ScopeAll 或账号ID
+// 加入前逐个账号检查交易对，全部通过才生效；返回各账号调整后的交易对池
+func changeSymbols(r *http.Request, targets []*accountRunner, scope string, add bool) (interface{}, error) {
+	var req struct {
+		Symbols []string `json:"symbols"` // 交易对（如 BTCUSDT，币本位账号自动转换为币本位合约）
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, server.BadRequest("解析请求失败: %v", err)
+	}
+	var symbols []string
+	for _, symbol := range req.Symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol != "" && !slices.Contains(symbols, symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		return nil, server.BadRequest("symbols不能为空")
+	}
+	if len(targets) == 0 {
+		return nil, server.BadRequest("没有运行中的账号")
+	}
+
+	pool := targets[0].pool
+	if add {
+		for _, runner := range targets {
+			if err := runner.prepareSymbols(symbols); err != nil {
+				return nil, err
+			}
+		}
+		if err := pool.Add(scope, symbols); err != nil {
+			return nil, err
+		}
+	} else if err := pool.Remove(scope, symbols); err != nil {
+		return nil, err
+	}
+
+	pools := make(map[string][]string, len(targets))
+	for _, runner := range targets {
+		if add {
+			runner.watchSymbols(symbols)
+		}
+		pools[runner.accountID] = runner.poolSymbols()
+	}
+	return map[string]interface{}{"scope": scope, "symbols": symbols, "added": add, "pools": pools}, nil
+}
+
+// findRunner 按账号ID查找运行器
+func findRunner(runners []*accountRunner, id string) (*accountRunner, error) {
+	for _, runner := range runners {
+		if runner.accountID == id {
+			return runner, nil
+		}
+	}
+	return nil, server.NotFound("账号不存在: %s", id)
+}
+
+// snapshotQuery 解析指标快照查询参数：symbol（必需）、timeframe、from/to（RFC3339或毫秒时间戳）、limit（默认500）、account_id（默认全部账号）
+func snapshotQuery(r *http.Request, runners []*accountRunner) (ai.SnapshotQuery, []string, error) {
+	params := r.URL.Query()
+	q := ai.SnapshotQuery{
+		Symbol:    strings.ToUpper(params.Get("symbol")),
+		Timeframe: params.Get("timeframe"),
+		Limit:     500,
+	}
+	if q.Symbol == "" {
+		return q, nil, server.BadRequest("symbol不能为空")
+	}
+	var err error
+	if q.From, err = parseTimeParam(params.Get("from")); err != nil {
+		return q, nil, server.BadRequest("from无效: %v", err)
+	}
+	if q.To, err = parseTimeParam(params.Get("to")); err != nil {
+		return q, nil, server.BadRequest("to无效: %v", err)
+	}
+	if v := params.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return q, nil, server.BadRequest("limit必须是正整数")
+		}
+		q.Limit = n
+	}
+
+	accountIDs := runnerIDs(runners)
+	if id := params.Get("account_id"); id != "" {
+		if _, err := findRunner(runners, id); err != nil {
+			return q, nil, err
+		}
+		accountIDs = []string{id}
+	}
+	return q, accountIDs, nil
+}
+
+// parseTimeParam 解析时间参数：RFC3339（如 2026-01-02T15:04:05Z）或毫秒时间戳，为空时返回零值
+func parseTimeParam(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// portfolioAccounts 参与组合汇总的账号
+func portfolioAccounts(runners []*accountRunner) []portfolio.Account {
+	accounts := make([]portfolio.Account, 0, len(runners))
+	for _, runner := range runners {
+		// 影子账号没有真实持仓，观察账号不查询账户
+		if !runner.account.IsLive() {
+			continue
+		}
+		acc := portfolio.Account{ID: runner.accountID, Trading: runner.market}
+		// 币本位合约按各币种分别计保证金、现货没有保证金，都不参与保证金使用率汇总
+		if runner.account.GetMarketType() == "usdt_m" {
+			acc.MarginAsset = "USDT"
+		}
+		if runner.executor != nil {
+			exec := runner.executor
+			acc.Stops = func() map[string]float64 {
+				stops := make(map[string]float64)
+				for _, b := range exec.GetBrackets() {
+					stops[b.Symbol] = b.StopLoss
+				}
+				return stops
+			}
+		}
+		accounts = append(accounts, acc)
+	}
+	return accounts
+}
+
+// klineMarket 组合风险报告获取U本位交易对K线的行情接口（第一个U本位合约账号，没有时返回nil）
+func klineMarket(runners []*accountRunner) exchange.MarketData {
+	for _, runner := range runners {
+		if runner.account.GetMarketType() == "usdt_m" {
+			return runner.marketData
+		}
+	}
+	return nil
 }
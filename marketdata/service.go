@@ -0,0 +1,296 @@
+/*
+Package marketdata 共享行情数据服务（同一交易所、市场类型的所有账号共用，按周期缓存）
+
+主要功能：
+- NewPool(ttl time.Duration) *Pool                                                // 创建行情服务集合
+- (p *Pool) Get(key string, source exchange.MarketData) *Service                  // 获取key对应的共享行情服务（不存在时用source创建）
+- (p *Pool) Stats() map[string]Stats                                              // 各行情服务的缓存统计
+- NewService(source exchange.MarketData, ttl time.Duration) *Service              // 创建行情服务
+- (s *Service) Stats() Stats                                                      // 缓存命中统计
+- (s *Service) SetLiquidations(source exchange.LiquidationData)                   // 设置强平统计来源（实现 exchange.LiquidationData，未设置时返回 ErrUnsupported）
+- (s *Service) FetchedAt(symbol string, intervals []string) map[string]time.Time  // 交易对各项数据最近一次从交易所获取的时间
+
+Service 实现 exchange.MarketData，策略周期获取K线和指标计算获取持仓量、资金费率都通过它读取：
+同一份数据在有效期内只向交易所请求一次，其他账号、策略直接使用内存中的数据。
+1. K线：有效期到 ttl 或当前K线收盘（下一根K线开始）为止，取较早者，收盘后的第一次请求一定重新获取；缓存的数量不少于请求数量时返回最近 limit 根
+2. 持仓量、资金费率、资金费率历史：有效期 ttl
+同一数据的并发请求只发送一次（后来的调用等待第一个请求完成）。请求失败不缓存，下次调用重新请求。
+返回的切片是副本，调用方修改不影响缓存。
+每项数据记录最近一次从交易所获取成功的时间（缓存命中时数据的实际时间），供决策前检查输入数据是否过期。
+*/
+package marketdata
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"crypto-ai-trader/exchange"
+)
+
+// Stats 缓存统计
+type Stats struct {
+	Hits     int64 `json:"hits"`     // 命中缓存的调用数
+	Requests int64 `json:"requests"` // 向交易所发出的请求数（含失败）
+	Entries  int   `json:"entries"`  // 缓存的数据项数
+}
+
+// Service 共享行情数据服务
+type Service struct {
+	source exchange.MarketData
+	ttl    time.Duration
+
+	mu           sync.Mutex
+	entries      map[string]*entry
+	liquidations exchange.LiquidationData // 强平统计来源（推送数据已在内存中，不缓存）
+
+	hits     atomic.Int64
+	requests atomic.Int64
+}
+
+// entry 一项缓存数据（mu 保证同一数据同时只有一个请求）
+type entry struct {
+	mu      sync.Mutex
+	expires time.Time
+	limit   int          // 缓存的K线或资金费率历史的请求数量
+	fetched atomic.Int64 // 最近一次从交易所获取成功的时间（UnixNano，读取时不等待进行中的请求）
+
+	klines  []exchange.Kline
+	value   float64
+	history []float64
+}
+
+// NewService 创建行情服务（ttl为缓存有效期）
+func NewService(source exchange.MarketData, ttl time.Duration) *Service {
+	return &Service{source: source, ttl: ttl, entries: make(map[string]*entry)}
+}
+
+// Name 交易所名称
+func (s *Service) Name() string {
+	return s.source.Name()
+}
+
+// HasDerivativesData 是否有持仓量和资金费率
+func (s *Service) HasDerivativesData() bool {
+	return s.source.HasDerivativesData()
+}
+
+// GetKlines 获取K线（缓存有效且数量足够时返回最近 limit 根的副本）
+func (s *Service) GetKlines(symbol, interval string, limit int) ([]exchange.Kline, error) {
+	e := s.entry("klines|" + symbol + "|" + interval)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	if !now.Before(e.expires) || limit > e.limit {
+		s.requests.Add(1)
+		klines, err := s.source.GetKlines(symbol, interval, limit)
+		if err != nil {
+			return nil, err
+		}
+		e.klines, e.limit, e.expires = klines, limit, klinesExpiry(now, interval, s.ttl)
+		e.fetched.Store(time.Now().UnixNano())
+	} else {
+		s.hits.Add(1)
+	}
+
+	klines := e.klines
+	if limit > 0 && len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	return append([]exchange.Kline(nil), klines...), nil
+}
+
+// GetOpenInterest 获取持仓量
+func (s *Service) GetOpenInterest(symbol string) (float64, error) {
+	return s.value("oi|"+symbol, func() (float64, error) { return s.source.GetOpenInterest(symbol) })
+}
+
+// GetFundingRate 获取当前资金费率
+func (s *Service) GetFundingRate(symbol string) (float64, error) {
+	return s.value("funding|"+symbol, func() (float64, error) { return s.source.GetFundingRate(symbol) })
+}
+
+// GetFundingRateHistory 获取最近limit次已结算的资金费率（返回副本）
+func (s *Service) GetFundingRateHistory(symbol string, limit int) ([]float64, error) {
+	e := s.entry("funding_history|" + symbol)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	if !now.Before(e.expires) || limit > e.limit {
+		s.requests.Add(1)
+		history, err := s.source.GetFundingRateHistory(symbol, limit)
+		if err != nil {
+			return nil, err
+		}
+		e.history, e.limit, e.expires = history, limit, now.Add(s.ttl)
+		e.fetched.Store(time.Now().UnixNano())
+	} else {
+		s.hits.Add(1)
+	}
+
+	history := e.history
+	if limit > 0 && len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	return append([]float64(nil), history...), nil
+}
+
+// SetLiquidations 设置强平统计来源
+func (s *Service) SetLiquidations(source exchange.LiquidationData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.liquidations = source
+}
+
+// GetLiquidations 获取交易对的强平统计（未设置来源时返回 exchange.ErrUnsupported）
+func (s *Service) GetLiquidations(symbol string) ([]exchange.LiquidationWindow, bool, error) {
+	s.mu.Lock()
+	source := s.liquidations
+	s.mu.Unlock()
+
+	if source == nil {
+		return nil, false, exchange.ErrUnsupported
+	}
+	return source.GetLiquidations(symbol)
+}
+
+// FetchedAt 交易对各项数据最近一次从交易所获取的时间
+// 键为 klines:<周期>（intervals 中的周期）、oi、funding、funding_history，没有获取成功过的数据不返回
+func (s *Service) FetchedAt(symbol string, intervals []string) map[string]time.Time {
+	keys := map[string]string{
+		"oi":              "oi|" + symbol,
+		"funding":         "funding|" + symbol,
+		"funding_history": "funding_history|" + symbol,
+	}
+	for _, interval := range intervals {
+		keys["klines:"+interval] = "klines|" + symbol + "|" + interval
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fetched := make(map[string]time.Time, len(keys))
+	for input, key := range keys {
+		if e, ok := s.entries[key]; ok {
+			if at := e.fetched.Load(); at != 0 {
+				fetched[input] = time.Unix(0, at)
+			}
+		}
+	}
+	return fetched
+}
+
+// Stats 缓存命中统计
+func (s *Service) Stats() Stats {
+	s.mu.Lock()
+	entries := len(s.entries)
+	s.mu.Unlock()
+	return Stats{Hits: s.hits.Load(), Requests: s.requests.Load(), Entries: entries}
+}
+
+// value 读取单个数值（有效期内使用缓存）
+func (s *Service) value(key string, fetch func() (float64, error)) (float64, error) {
+	e := s.entry(key)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(e.expires) {
+		s.hits.Add(1)
+		return e.value, nil
+	}
+	s.requests.Add(1)
+	v, err := fetch()
+	if err != nil {
+		return 0, err
+	}
+	e.value, e.expires = v, now.Add(s.ttl)
+	e.fetched.Store(time.Now().UnixNano())
+	return v, nil
+}
+
+// entry 获取缓存项（不存在时创建）
+func (s *Service) entry(key string) *entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry{}
+		s.entries[key] = e
+	}
+	return e
+}
+
+// klinesExpiry K线缓存的过期时间：ttl 与当前K线收盘时间取较早者（无法解析周期时只按ttl）
+func klinesExpiry(now time.Time, interval string, ttl time.Duration) time.Time {
+	expires := now.Add(ttl)
+	if d := intervalDuration(interval); d > 0 {
+		if closeAt := now.Truncate(d).Add(d); closeAt.Before(expires) {
+			expires = closeAt
+		}
+	}
+	return expires
+}
+
+// intervalDuration 解析K线周期（1m、5m、1h、4h、1d等，周线、月线返回0）
+func intervalDuration(interval string) time.Duration {
+	if len(interval) < 2 {
+		return 0
+	}
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0
+	}
+	switch interval[len(interval)-1] {
+	case 'm':
+		return time.Duration(n) * time.Minute
+	case 'h':
+		return time.Duration(n) * time.Hour
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour
+	}
+	return 0
+}
+
+// Pool 行情服务集合（按交易所和市场类型共享）
+type Pool struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	services map[string]*Service
+}
+
+// NewPool 创建行情服务集合
+func NewPool(ttl time.Duration) *Pool {
+	return &Pool{ttl: ttl, services: make(map[string]*Service)}
+}
+
+// Get 获取key对应的共享行情服务（不存在时用source创建；同一key的账号应访问同一个交易所和市场类型）
+func (p *Pool) Get(key string, source exchange.MarketData) *Service {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.services[key]
+	if !ok {
+		s = NewService(source, p.ttl)
+		p.services[key] = s
+	}
+	return s
+}
+
+// Stats 各行情服务的缓存统计
+func (p *Pool) Stats() map[string]Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[string]Stats, len(p.services))
+	for key, s := range p.services {
+		stats[key] = s.Stats()
+	}
+	return stats
+}
@@ -0,0 +1,111 @@
+/*
+Package okx 账户相关API
+
+主要功能：
+- (c *Client) GetBalance(ccy string) (*BalanceDetail, error)   // 获取单个币种余额
+- (c *Client) GetPositions(instID string) ([]Position, error)   // 获取永续合约持仓（instID为空时返回全部）
+*/
+package okx
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 持仓方向（单向持仓模式为net，pos带正负号）
+const (
+	PosSideNet   = "net"
+	PosSideLong  = "long"
+	PosSideShort = "short"
+)
+
+// BalanceDetail 币种余额
+type BalanceDetail struct {
+	Ccy      string `json:"ccy"`      // 币种
+	Eq       string `json:"eq"`       // 币种权益（含未实现盈亏）
+	CashBal  string `json:"cashBal"`  // 现金余额
+	AvailBal string `json:"availBal"` // 可用余额
+	Upl      string `json:"upl"`      // 未实现盈亏
+}
+
+// Position 持仓信息（数量单位为张）
+type Position struct {
+	InstID  string `json:"instId"`  // 合约ID
+	PosSide string `json:"posSide"` // 持仓方向（net/long/short）
+	Pos     string `json:"pos"`     // 持仓数量（张，net模式下空仓为负）
+	AvgPx   string `json:"avgPx"`   // 开仓均价
+	MarkPx  string `json:"markPx"`  // 标记价格
+	Upl     string `json:"upl"`     // 未实现盈亏
+	Lever   string `json:"lever"`   // 杠杆倍数
+	MgnMode string `json:"mgnMode"` // 保证金模式（cross/isolated）
+	LiqPx   string `json:"liqPx"`   // 强平价格
+}
+
+// Contracts 带方向的持仓张数（多为正，空为负）
+func (p *Position) Contracts() float64 {
+	pos := parseFloat(p.Pos)
+	if p.PosSide == PosSideShort && pos > 0 {
+		return -pos
+	}
+	return pos
+}
+
+// GetBalance 获取单个币种余额
+func (c *Client) GetBalance(ccy string) (*BalanceDetail, error) {
+	utils.Debug("获取OKX账户余额", zap.String("ccy", ccy))
+
+	data, err := c.doRequest("GET", EndpointBalance, map[string]string{"ccy": ccy}, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("获取账户余额失败: %w", err)
+	}
+
+	var result []struct {
+		Details []BalanceDetail `json:"details"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("解析账户余额失败: %w", err)
+	}
+
+	for _, account := range result {
+		for _, detail := range account.Details {
+			if detail.Ccy == ccy {
+				return &detail, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("未找到%s余额", ccy)
+}
+
+// GetPositions 获取永续合约持仓（instID为空时返回全部，已过滤空仓）
+func (c *Client) GetPositions(instID string) ([]Position, error) {
+	params := map[string]string{"instType": InstTypeSwap}
+	if instID != "" {
+		params["instId"] = instID
+	}
+
+	data, err := c.doRequest("GET", EndpointPositions, params, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("获取持仓信息失败: %w", err)
+	}
+
+	var all []Position
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("解析持仓信息失败: %w", err)
+	}
+
+	positions := make([]Position, 0, len(all))
+	for _, pos := range all {
+		if pos.Contracts() != 0 {
+			positions = append(positions, pos)
+		}
+	}
+
+	utils.Info("获取OKX持仓信息成功", zap.Int("count", len(positions)))
+
+	return positions, nil
+}
@@ -0,0 +1,231 @@
+/*
+Package okx OKX API客户端（永续合约）
+
+主要功能：
+- NewClient(apiKey, apiSecret, passphrase, baseURL, proxyURL string) *Client  // 创建客户端
+- (c *Client) SetProxy(proxyURL string)                                         // 设置代理
+- (c *Client) SetSimulated(simulated bool)                                      // 设置模拟盘（x-simulated-trading）
+- (c *Client) doRequest(method, path string, params map[string]string, body interface{}, signed bool) (json.RawMessage, error)  // 执行HTTP请求
+
+OKX所有接口返回 {"code":"0","msg":"","data":[...]}，code非0表示业务错误，
+doRequest 统一检查code并返回data部分。
+*/
+package okx
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// Client OKX API客户端
+type Client struct {
+	apiKey     string
+	apiSecret  string
+	passphrase string
+	baseURL    string
+	simulated  bool // 模拟盘
+	httpClient *http.Client
+}
+
+// response OKX统一响应格式
+type response struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// NewClient 创建新的OKX客户端
+func NewClient(apiKey, apiSecret, passphrase, baseURL, proxyURL string) *Client {
+	client := &Client{
+		apiKey:     apiKey,
+		apiSecret:  apiSecret,
+		passphrase: passphrase,
+		baseURL:    baseURL,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+
+	// 设置代理
+	if proxyURL != "" {
+		client.SetProxy(proxyURL)
+	}
+
+	utils.Info("创建OKX客户端",
+		zap.String("base_url", baseURL),
+		zap.Bool("proxy_enabled", proxyURL != ""),
+	)
+
+	return client
+}
+
+// SetProxy 设置代理
+func (c *Client) SetProxy(proxyURL string) {
+	if proxyURL == "" {
+		return
+	}
+
+	proxy, err := url.Parse(proxyURL)
+	if err != nil {
+		utils.Error("解析代理URL失败", zap.String("proxy", proxyURL), zap.Error(err))
+		return
+	}
+
+	c.httpClient.Transport = &http.Transport{
+		Proxy: http.ProxyURL(proxy),
+	}
+
+	utils.Info("设置代理", zap.String("proxy", proxyURL))
+}
+
+// SetSimulated 设置模拟盘（请求头带 x-simulated-trading: 1）
+func (c *Client) SetSimulated(simulated bool) {
+	c.simulated = simulated
+}
+
+// doRequest 执行HTTP请求，返回data部分
+// params: 查询参数（GET）
+// body: 请求体（POST，序列化为JSON）
+func (c *Client) doRequest(method, path string, params map[string]string, body interface{}, signed bool) (json.RawMessage, error) {
+	requestPath := path
+	if len(params) > 0 {
+		requestPath += "?" + buildQueryString(params)
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("序列化请求体失败: %w", err)
+		}
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+requestPath, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.simulated {
+		req.Header.Set("x-simulated-trading", "1")
+	}
+
+	// 签名：Base64(HMAC-SHA256(timestamp + method + requestPath + body, secret))
+	if signed {
+		timestamp := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+		req.Header.Set("OK-ACCESS-KEY", c.apiKey)
+		req.Header.Set("OK-ACCESS-SIGN", c.sign(timestamp+method+requestPath+string(payload)))
+		req.Header.Set("OK-ACCESS-TIMESTAMP", timestamp)
+		req.Header.Set("OK-ACCESS-PASSPHRASE", c.passphrase)
+	}
+
+	return c.executeRequest(req, path, signed)
+}
+
+// executeRequest 执行HTTP请求并检查业务错误码
+func (c *Client) executeRequest(req *http.Request, path string, signed bool) (json.RawMessage, error) {
+	utils.Debug("发送OKX API请求",
+		zap.String("method", req.Method),
+		zap.String("path", path),
+		zap.Bool("signed", signed),
+	)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		utils.Error("OKX API请求失败",
+			zap.String("path", path),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		utils.Error("OKX API返回错误",
+			zap.String("path", path),
+			zap.Int("status_code", resp.StatusCode),
+			zap.String("response", string(body)),
+		)
+		return nil, fmt.Errorf("API错误 [%d]: %s", resp.StatusCode, string(body))
+	}
+
+	var result response
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Code != "0" {
+		utils.Error("OKX API返回业务错误",
+			zap.String("path", path),
+			zap.String("code", result.Code),
+			zap.String("msg", result.Msg),
+			zap.String("response", string(body)),
+		)
+		return nil, fmt.Errorf("API错误 [code=%s]: %s %s", result.Code, result.Msg, string(result.Data))
+	}
+
+	utils.Debug("OKX API请求成功",
+		zap.String("path", path),
+		zap.Int("response_size", len(body)),
+	)
+
+	return result.Data, nil
+}
+
+// sign 生成签名
+func (c *Client) sign(message string) string {
+	h := hmac.New(sha256.New, []byte(c.apiSecret))
+	h.Write([]byte(message))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// buildQueryString 构建查询字符串（按键排序，保证签名内容与实际请求一致）
+func buildQueryString(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, url.QueryEscape(params[k])))
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// GetServerTime 获取服务器时间（毫秒），也用于测试连接
+func (c *Client) GetServerTime() (int64, error) {
+	data, err := c.doRequest("GET", EndpointServerTime, nil, nil, false)
+	if err != nil {
+		return 0, fmt.Errorf("获取服务器时间失败: %w", err)
+	}
+
+	var result []struct {
+		Ts string `json:"ts"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil || len(result) == 0 {
+		return 0, fmt.Errorf("解析服务器时间失败: %s", string(data))
+	}
+
+	return parseInt(result[0].Ts), nil
+}
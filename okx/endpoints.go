@@ -0,0 +1,33 @@
+/*
+Package okx API端点常量
+
+主要功能：
+- EndpointServerTime                          // 基础端点（服务器时间）
+- EndpointCandles ... EndpointFundingHist     // 市场数据端点（K线、行情、合约信息、持仓量、资金费率）
+- EndpointBalance, EndpointPositions          // 账户端点（余额、持仓）
+- EndpointOrder, EndpointCancelOrder          // 交易端点（下单、查询、撤单）
+
+OKX v5 API端点定义
+*/
+package okx
+
+const (
+	// 基础端点
+	EndpointServerTime = "/api/v5/public/time" // 获取服务器时间
+
+	// 市场数据端点
+	EndpointCandles      = "/api/v5/market/candles"              // 获取K线数据
+	EndpointTicker       = "/api/v5/market/ticker"               // 获取最新行情
+	EndpointInstruments  = "/api/v5/public/instruments"          // 获取合约信息（面值、下单精度）
+	EndpointOpenInterest = "/api/v5/public/open-interest"        // 获取持仓量
+	EndpointFundingRate  = "/api/v5/public/funding-rate"         // 获取当前资金费率
+	EndpointFundingHist  = "/api/v5/public/funding-rate-history" // 获取资金费率历史
+
+	// 账户端点
+	EndpointBalance   = "/api/v5/account/balance"   // 获取账户余额
+	EndpointPositions = "/api/v5/account/positions" // 获取持仓信息
+
+	// 交易端点
+	EndpointOrder       = "/api/v5/trade/order"        // 下单/查询订单
+	EndpointCancelOrder = "/api/v5/trade/cancel-order" // 撤销订单
+)
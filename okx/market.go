@@ -0,0 +1,355 @@
+/*
+Package okx 市场数据相关API
+
+主要功能：
+- InstID(symbol string) string                                                // 币安格式交易对转OKX永续合约ID（BTCUSDT → BTC-USDT-SWAP）
+- Symbol(instID string) string                                                // OKX永续合约ID转币安格式交易对（BTC-USDT-SWAP → BTCUSDT）
+- Bar(interval string) string                                                 // 币安格式K线周期转OKX格式（1h → 1H，1d → 1Dutc）
+- (c *Client) GetCandles(instID, interval string, limit int) ([]Candle, error) // 获取K线数据（从旧到新）
+- (c *Client) GetInstrument(instID string) (*Instrument, error)               // 获取合约信息（面值、下单精度）
+- (i *Instrument) ContractsForQuantity(qty float64) float64                   // 标的数量换算为张数
+- (i *Instrument) FormatSize(contracts float64) string                        // 按lotSz向下取整并格式化张数
+- (i *Instrument) FormatPrice(price float64) string                           // 按tickSz取整并格式化价格
+- (c *Client) GetTicker(instID string) (*Ticker, error)                       // 获取最新行情（买一卖一）
+- (c *Client) GetOpenInterest(instID string) (*OpenInterest, error)           // 获取持仓量
+- (c *Client) GetFundingRate(instID string) (*FundingRate, error)             // 获取当前资金费率
+- (c *Client) GetFundingRateHistory(instID string, limit int) ([]FundingRate, error)  // 获取资金费率历史（从新到旧）
+*/
+package okx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 合约类型
+const (
+	InstTypeSwap = "SWAP" // 永续合约
+)
+
+// 支持的报价币种（币安格式交易对的后缀）
+var quoteAssets = []string{"USDT", "USDC", "USD"}
+
+// Candle K线数据（价格、成交量保留OKX返回的字符串）
+type Candle struct {
+	OpenTime    int64  // 开盘时间（毫秒）
+	CloseTime   int64  // 收盘时间（毫秒，按周期推算）
+	Open        string // 开盘价
+	High        string // 最高价
+	Low         string // 最低价
+	Close       string // 收盘价
+	Volume      string // 成交量（标的币种，volCcy）
+	QuoteVolume string // 成交额（报价币种，volCcyQuote）
+	Confirmed   bool   // K线是否已完结
+}
+
+// Instrument 合约信息
+type Instrument struct {
+	InstID    string `json:"instId"`    // 合约ID
+	CtVal     string `json:"ctVal"`     // 合约面值（一张合约对应的标的数量）
+	CtValCcy  string `json:"ctValCcy"`  // 面值币种
+	SettleCcy string `json:"settleCcy"` // 结算币种
+	LotSz     string `json:"lotSz"`     // 下单数量精度（张）
+	MinSz     string `json:"minSz"`     // 最小下单数量（张）
+	TickSz    string `json:"tickSz"`    // 价格精度
+	State     string `json:"state"`     // 状态（live为可交易）
+}
+
+// Ticker 最新行情
+type Ticker struct {
+	InstID string `json:"instId"` // 合约ID
+	Last   string `json:"last"`   // 最新成交价
+	BidPx  string `json:"bidPx"`  // 买一价
+	AskPx  string `json:"askPx"`  // 卖一价
+	Ts     string `json:"ts"`     // 时间戳（毫秒）
+}
+
+// OpenInterest 持仓量
+type OpenInterest struct {
+	InstID string `json:"instId"` // 合约ID
+	Oi     string `json:"oi"`     // 持仓量（张）
+	OiCcy  string `json:"oiCcy"`  // 持仓量（标的币种）
+	Ts     string `json:"ts"`     // 时间戳（毫秒）
+}
+
+// FundingRate 资金费率
+type FundingRate struct {
+	InstID          string `json:"instId"`          // 合约ID
+	FundingRate     string `json:"fundingRate"`     // 资金费率（历史接口为预估值，实际值见RealizedRate）
+	RealizedRate    string `json:"realizedRate"`    // 实际收取的资金费率（仅历史接口）
+	FundingTime     string `json:"fundingTime"`     // 资金费时间（毫秒）
+	NextFundingTime string `json:"nextFundingTime"` // 下次资金费时间（仅当前费率接口）
+}
+
+// CtValFloat 合约面值（解析失败时返回0）
+func (i *Instrument) CtValFloat() float64 {
+	return parseFloat(i.CtVal)
+}
+
+// ContractsForQuantity 标的数量换算为张数
+func (i *Instrument) ContractsForQuantity(qty float64) float64 {
+	ctVal := i.CtValFloat()
+	if ctVal <= 0 {
+		return qty
+	}
+	return qty / ctVal
+}
+
+// FormatSize 按lotSz向下取整并格式化张数
+func (i *Instrument) FormatSize(contracts float64) string {
+	return formatStep(contracts, parseFloat(i.LotSz), math.Floor)
+}
+
+// FormatPrice 按tickSz四舍五入并格式化价格
+func (i *Instrument) FormatPrice(price float64) string {
+	return formatStep(price, parseFloat(i.TickSz), math.Round)
+}
+
+// RateFloat 资金费率（历史数据优先使用实际收取的费率）
+func (f *FundingRate) RateFloat() float64 {
+	if f.RealizedRate != "" {
+		return parseFloat(f.RealizedRate)
+	}
+	return parseFloat(f.FundingRate)
+}
+
+// InstID 币安格式交易对转OKX永续合约ID（BTCUSDT → BTC-USDT-SWAP）
+// 已是OKX格式时原样返回
+func InstID(symbol string) string {
+	if strings.Contains(symbol, "-") {
+		return symbol
+	}
+	for _, quote := range quoteAssets {
+		if base, ok := strings.CutSuffix(symbol, quote); ok && base != "" {
+			return base + "-" + quote + "-" + InstTypeSwap
+		}
+	}
+	return symbol
+}
+
+// Symbol OKX永续合约ID转币安格式交易对（BTC-USDT-SWAP → BTCUSDT）
+func Symbol(instID string) string {
+	parts := strings.Split(instID, "-")
+	if len(parts) < 2 {
+		return instID
+	}
+	return parts[0] + parts[1]
+}
+
+// Bar 币安格式K线周期转OKX格式
+// OKX小时及以上周期使用大写（1H、4H），日线默认按香港时间开盘，使用1Dutc与币安的UTC开盘对齐
+func Bar(interval string) string {
+	switch {
+	case strings.HasSuffix(interval, "h"):
+		return strings.TrimSuffix(interval, "h") + "H"
+	case strings.HasSuffix(interval, "d"):
+		return strings.TrimSuffix(interval, "d") + "Dutc"
+	case strings.HasSuffix(interval, "w"):
+		return strings.TrimSuffix(interval, "w") + "Wutc"
+	default:
+		return interval
+	}
+}
+
+// GetCandles 获取K线数据
+// instID: 合约ID，如 "BTC-USDT-SWAP"
+// interval: 币安格式K线周期，如 "5m", "1h", "1d"
+// limit: 获取数量，最大300
+// 返回的K线按时间从旧到新排列（与币安一致），最后一根可能未完结
+func (c *Client) GetCandles(instID, interval string, limit int) ([]Candle, error) {
+	utils.Debug("获取OKX K线数据",
+		zap.String("inst_id", instID),
+		zap.String("interval", interval),
+		zap.Int("limit", limit),
+	)
+
+	params := map[string]string{
+		"instId": instID,
+		"bar":    Bar(interval),
+	}
+	if limit > 0 {
+		params["limit"] = strconv.Itoa(min(limit, 300))
+	}
+
+	data, err := c.doRequest("GET", EndpointCandles, params, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取K线数据失败: %w", err)
+	}
+
+	// OKX返回二维字符串数组：[ts, o, h, l, c, vol, volCcy, volCcyQuote, confirm]，从新到旧
+	var raw [][]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("解析K线数据失败: %w", err)
+	}
+
+	period := intervalMillis(interval)
+	candles := make([]Candle, 0, len(raw))
+	for i := len(raw) - 1; i >= 0; i-- {
+		row := raw[i]
+		if len(row) < 9 {
+			continue
+		}
+		openTime := parseInt(row[0])
+		candles = append(candles, Candle{
+			OpenTime:    openTime,
+			CloseTime:   openTime + period - 1,
+			Open:        row[1],
+			High:        row[2],
+			Low:         row[3],
+			Close:       row[4],
+			Volume:      row[6],
+			QuoteVolume: row[7],
+			Confirmed:   row[8] == "1",
+		})
+	}
+
+	utils.Info("获取OKX K线数据成功",
+		zap.String("inst_id", instID),
+		zap.String("interval", interval),
+		zap.Int("count", len(candles)),
+	)
+
+	return candles, nil
+}
+
+// GetInstrument 获取永续合约信息
+func (c *Client) GetInstrument(instID string) (*Instrument, error) {
+	data, err := c.doRequest("GET", EndpointInstruments, map[string]string{
+		"instType": InstTypeSwap,
+		"instId":   instID,
+	}, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取合约信息失败: %w", err)
+	}
+
+	var instruments []Instrument
+	if err := json.Unmarshal(data, &instruments); err != nil {
+		return nil, fmt.Errorf("解析合约信息失败: %w", err)
+	}
+	if len(instruments) == 0 {
+		return nil, fmt.Errorf("未找到合约: %s", instID)
+	}
+
+	return &instruments[0], nil
+}
+
+// GetTicker 获取最新行情
+func (c *Client) GetTicker(instID string) (*Ticker, error) {
+	data, err := c.doRequest("GET", EndpointTicker, map[string]string{"instId": instID}, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取行情失败: %w", err)
+	}
+
+	var tickers []Ticker
+	if err := json.Unmarshal(data, &tickers); err != nil || len(tickers) == 0 {
+		return nil, fmt.Errorf("解析行情失败: %s", string(data))
+	}
+
+	return &tickers[0], nil
+}
+
+// GetOpenInterest 获取持仓量
+func (c *Client) GetOpenInterest(instID string) (*OpenInterest, error) {
+	data, err := c.doRequest("GET", EndpointOpenInterest, map[string]string{
+		"instType": InstTypeSwap,
+		"instId":   instID,
+	}, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取持仓量失败: %w", err)
+	}
+
+	var result []OpenInterest
+	if err := json.Unmarshal(data, &result); err != nil || len(result) == 0 {
+		return nil, fmt.Errorf("解析持仓量失败: %s", string(data))
+	}
+
+	return &result[0], nil
+}
+
+// GetFundingRate 获取当前资金费率
+func (c *Client) GetFundingRate(instID string) (*FundingRate, error) {
+	data, err := c.doRequest("GET", EndpointFundingRate, map[string]string{"instId": instID}, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取资金费率失败: %w", err)
+	}
+
+	var result []FundingRate
+	if err := json.Unmarshal(data, &result); err != nil || len(result) == 0 {
+		return nil, fmt.Errorf("解析资金费率失败: %s", string(data))
+	}
+
+	return &result[0], nil
+}
+
+// GetFundingRateHistory 获取资金费率历史（从新到旧）
+func (c *Client) GetFundingRateHistory(instID string, limit int) ([]FundingRate, error) {
+	params := map[string]string{"instId": instID}
+	if limit > 0 {
+		params["limit"] = strconv.Itoa(min(limit, 100))
+	}
+
+	data, err := c.doRequest("GET", EndpointFundingHist, params, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取资金费率历史失败: %w", err)
+	}
+
+	var result []FundingRate
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("解析资金费率历史失败: %w", err)
+	}
+
+	return result, nil
+}
+
+// intervalMillis 币安格式K线周期的毫秒数（如5m → 300000），无法识别时返回0
+func intervalMillis(interval string) int64 {
+	if len(interval) < 2 {
+		return 0
+	}
+	n := parseInt(interval[:len(interval)-1])
+	switch interval[len(interval)-1] {
+	case 'm':
+		return n * int64(time.Minute/time.Millisecond)
+	case 'h':
+		return n * int64(time.Hour/time.Millisecond)
+	case 'd':
+		return n * 24 * int64(time.Hour/time.Millisecond)
+	case 'w':
+		return n * 7 * 24 * int64(time.Hour/time.Millisecond)
+	default:
+		return 0
+	}
+}
+
+// formatStep 按步长取整并格式化（round为取整方式）
+func formatStep(value, step float64, round func(float64) float64) string {
+	if step <= 0 {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	steps := round(value/step + 1e-9)
+	decimals := 0
+	if str := strconv.FormatFloat(step, 'f', -1, 64); strings.Contains(str, ".") {
+		decimals = len(str) - strings.IndexByte(str, '.') - 1
+	}
+	return strconv.FormatFloat(steps*step, 'f', decimals, 64)
+}
+
+// parseFloat 解析数字字符串（空字符串或解析失败返回0）
+func parseFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+// parseInt 解析整数字符串（空字符串或解析失败返回0）
+func parseInt(s string) int64 {
+	v, _ := strconv.ParseInt(s, 10, 64)
+	return v
+}
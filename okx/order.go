@@ -0,0 +1,194 @@
+/*
+Package okx 订单相关API
+
+主要功能：
+- (c *Client) PlaceOrder(req *OrderRequest) (*Order, error)              // 下单（返回下单后查询到的订单）
+- (c *Client) GetOrder(instID, ordID string) (*Order, error)              // 按订单ID查询订单
+- (c *Client) GetOrderByClientID(instID, clOrdID string) (*Order, error)  // 按客户端订单ID查询订单
+- (c *Client) CancelOrder(instID, ordID string) error                     // 撤销订单
+*/
+package okx
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 订单方向
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
+// 订单类型
+const (
+	OrdTypeMarket   = "market"    // 市价单
+	OrdTypeLimit    = "limit"     // 限价单
+	OrdTypePostOnly = "post_only" // 只做Maker
+	OrdTypeIOC      = "ioc"       // 立即成交，剩余撤销
+)
+
+// 交易模式（保证金模式）
+const (
+	TdModeCross    = "cross"    // 全仓
+	TdModeIsolated = "isolated" // 逐仓
+)
+
+// 订单状态
+const (
+	StateLive            = "live"             // 等待成交
+	StatePartiallyFilled = "partially_filled" // 部分成交
+	StateFilled          = "filled"           // 完全成交
+	StateCanceled        = "canceled"         // 已撤销
+	StateMMPCanceled     = "mmp_canceled"     // 做市商保护撤销
+)
+
+// OrderRequest 下单请求（数量单位为张）
+type OrderRequest struct {
+	InstID     string `json:"instId"`               // 合约ID
+	TdMode     string `json:"tdMode"`               // 交易模式（cross/isolated）
+	Side       string `json:"side"`                 // buy 或 sell
+	OrdType    string `json:"ordType"`              // 订单类型
+	Sz         string `json:"sz"`                   // 数量（张，已按lotSz格式化）
+	Px         string `json:"px,omitempty"`         // 限价单价格
+	ClOrdID    string `json:"clOrdId,omitempty"`    // 客户端订单ID（字母数字，最长32位）
+	ReduceOnly bool   `json:"reduceOnly,omitempty"` // 只减仓
+
+	AttachAlgoOrds []AttachAlgoOrd `json:"attachAlgoOrds,omitempty"` // 附带的止盈止损（成交后生效）
+}
+
+// AttachAlgoOrd 下单时附带的止盈止损（委托价为-1表示触发后市价平仓）
+type AttachAlgoOrd struct {
+	TpTriggerPx string `json:"tpTriggerPx,omitempty"` // 止盈触发价
+	TpOrdPx     string `json:"tpOrdPx,omitempty"`     // 止盈委托价
+	SlTriggerPx string `json:"slTriggerPx,omitempty"` // 止损触发价
+	SlOrdPx     string `json:"slOrdPx,omitempty"`     // 止损委托价
+}
+
+// Order 订单信息
+type Order struct {
+	InstID    string `json:"instId"`    // 合约ID
+	OrdID     string `json:"ordId"`     // 订单ID
+	ClOrdID   string `json:"clOrdId"`   // 客户端订单ID
+	Side      string `json:"side"`      // buy 或 sell
+	OrdType   string `json:"ordType"`   // 订单类型
+	State     string `json:"state"`     // 订单状态
+	Px        string `json:"px"`        // 委托价格
+	Sz        string `json:"sz"`        // 委托数量（张）
+	AccFillSz string `json:"accFillSz"` // 累计成交数量（张）
+	AvgPx     string `json:"avgPx"`     // 成交均价
+	UTime     string `json:"uTime"`     // 更新时间（毫秒）
+}
+
+// IsFinal 订单是否已结束（完全成交或已撤销）
+func (o *Order) IsFinal() bool {
+	return o.State == StateFilled || o.State == StateCanceled || o.State == StateMMPCanceled
+}
+
+// placeResult 下单/撤单结果（逐笔返回sCode）
+type placeResult struct {
+	OrdID   string `json:"ordId"`
+	ClOrdID string `json:"clOrdId"`
+	SCode   string `json:"sCode"`
+	SMsg    string `json:"sMsg"`
+}
+
+// PlaceOrder 下单
+// OKX下单接口只返回订单ID，下单成功后再查询一次订单详情
+func (c *Client) PlaceOrder(req *OrderRequest) (*Order, error) {
+	utils.Info("OKX下单",
+		zap.String("inst_id", req.InstID),
+		zap.String("side", req.Side),
+		zap.String("ord_type", req.OrdType),
+		zap.String("sz", req.Sz),
+		zap.String("px", req.Px),
+		zap.Bool("reduce_only", req.ReduceOnly),
+		zap.String("cl_ord_id", req.ClOrdID),
+	)
+
+	data, err := c.doRequest("POST", EndpointOrder, nil, req, true)
+	if err != nil {
+		return nil, fmt.Errorf("下单失败: %w", err)
+	}
+
+	result, err := parsePlaceResult(data)
+	if err != nil {
+		return nil, fmt.Errorf("下单失败: %w", err)
+	}
+
+	order, err := c.GetOrder(req.InstID, result.OrdID)
+	if err != nil {
+		// 下单已成功，查询失败时返回仅含订单ID的订单
+		utils.Warn("下单成功但查询订单失败", zap.String("ord_id", result.OrdID), zap.Error(err))
+		return &Order{InstID: req.InstID, OrdID: result.OrdID, ClOrdID: result.ClOrdID, State: StateLive, Sz: req.Sz}, nil
+	}
+
+	return order, nil
+}
+
+// GetOrder 按订单ID查询订单
+func (c *Client) GetOrder(instID, ordID string) (*Order, error) {
+	return c.queryOrder(map[string]string{"instId": instID, "ordId": ordID})
+}
+
+// GetOrderByClientID 按客户端订单ID查询订单
+func (c *Client) GetOrderByClientID(instID, clOrdID string) (*Order, error) {
+	return c.queryOrder(map[string]string{"instId": instID, "clOrdId": clOrdID})
+}
+
+// queryOrder 查询订单
+func (c *Client) queryOrder(params map[string]string) (*Order, error) {
+	data, err := c.doRequest("GET", EndpointOrder, params, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单失败: %w", err)
+	}
+
+	var orders []Order
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return nil, fmt.Errorf("解析订单失败: %w", err)
+	}
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("订单不存在")
+	}
+
+	return &orders[0], nil
+}
+
+// CancelOrder 撤销订单
+func (c *Client) CancelOrder(instID, ordID string) error {
+	utils.Info("OKX撤单", zap.String("inst_id", instID), zap.String("ord_id", ordID))
+
+	data, err := c.doRequest("POST", EndpointCancelOrder, nil, map[string]string{
+		"instId": instID,
+		"ordId":  ordID,
+	}, true)
+	if err != nil {
+		return fmt.Errorf("撤单失败: %w", err)
+	}
+
+	if _, err := parsePlaceResult(data); err != nil {
+		return fmt.Errorf("撤单失败: %w", err)
+	}
+
+	return nil
+}
+
+// parsePlaceResult 解析下单/撤单结果（sCode非0表示该笔失败）
+func parsePlaceResult(data json.RawMessage) (*placeResult, error) {
+	var results []placeResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("解析结果失败: %w", err)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("结果为空")
+	}
+	if results[0].SCode != "0" {
+		return nil, fmt.Errorf("API错误 [sCode=%s]: %s", results[0].SCode, results[0].SMsg)
+	}
+
+	return &results[0], nil
+}
@@ -0,0 +1,172 @@
+/*
+Package portfolio 跨账号组合视图
+
+主要功能：
+- Aggregate(accounts []Account) *Exposure  // 汇总所有账号的持仓，按交易对计算多空及净敞口
+
+多个账号经常交易同一个交易对池，单看每个账号的持仓无法判断整体风险。
+汇总时币本位合约（如 BTCUSD_PERP）归并到对应的U本位交易对（BTCUSDT），敞口统一按美元名义价值计算。
+现货账号没有合约持仓接口，不参与汇总（记录在Errors中）。
+*/
+package portfolio
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/exchange"
+)
+
+// 持仓方向
+const (
+	SideLong  = "long"
+	SideShort = "short"
+)
+
+// Account 参与汇总的账号
+type Account struct {
+	ID          string                    // 账号ID
+	Trading     exchange.Trading          // 交易接口（用于查询持仓和余额）
+	MarginAsset string                    // 保证金资产（如USDT，为空时风险报告不统计该账号的保证金使用率）
+	Stops       func() map[string]float64 // 账号内交易对 -> 止损价（为nil表示没有止损信息，风险报告使用）
+}
+
+// AccountPosition 单个账号在某交易对上的持仓
+type AccountPosition struct {
+	AccountID     string  `json:"account_id"`     // 账号ID
+	Symbol        string  `json:"symbol"`         // 账号内的交易对（币本位为 BTCUSD_PERP 等）
+	Side          string  `json:"side"`           // long 或 short
+	Quantity      float64 `json:"quantity"`       // 持仓数量（带方向，币本位为张数）
+	EntryPrice    float64 `json:"entry_price"`    // 开仓均价
+	MarkPrice     float64 `json:"mark_price"`     // 标记价格
+	Notional      float64 `json:"notional"`       // 名义价值（美元）
+	UnrealizedPnL float64 `json:"unrealized_pnl"` // 未实现盈亏
+	Leverage      int     `json:"leverage"`       // 杠杆倍数
+}
+
+// SymbolExposure 单个交易对的跨账号敞口
+type SymbolExposure struct {
+	Symbol    string            `json:"symbol"`    // 交易对（U本位格式）
+	Long      float64           `json:"long"`      // 多头名义价值合计
+	Short     float64           `json:"short"`     // 空头名义价值合计
+	Net       float64           `json:"net"`       // 净敞口（多 - 空）
+	Gross     float64           `json:"gross"`     // 总敞口（多 + 空）
+	Positions []AccountPosition `json:"positions"` // 各账号持仓
+}
+
+// Exposure 跨账号汇总敞口
+type Exposure struct {
+	Time     time.Time                  `json:"time"`             // 汇总时间
+	Accounts int                        `json:"accounts"`         // 成功汇总的账号数
+	Long     float64                    `json:"long"`             // 多头名义价值合计
+	Short    float64                    `json:"short"`            // 空头名义价值合计
+	Net      float64                    `json:"net"`              // 净敞口
+	Gross    float64                    `json:"gross"`            // 总敞口
+	Symbols  []SymbolExposure           `json:"symbols"`          // 按总敞口从大到小排列
+	Errors   map[string]string          `json:"errors,omitempty"` // 查询失败的账号 -> 错误
+	BySymbol map[string]*SymbolExposure `json:"-"`                // 交易对 -> 敞口（便于查找）
+}
+
+// Aggregate 汇总所有账号的持仓（并发查询，单个账号失败不影响其他账号）
+func Aggregate(accounts []Account) *Exposure {
+	type result struct {
+		id        string
+		positions []exchange.Position
+		err       error
+	}
+
+	results := make([]result, len(accounts))
+	var wg sync.WaitGroup
+	for i, acc := range accounts {
+		wg.Add(1)
+		go func(i int, acc Account) {
+			defer wg.Done()
+			positions, err := acc.Trading.GetPositions()
+			results[i] = result{id: acc.ID, positions: positions, err: err}
+		}(i, acc)
+	}
+	wg.Wait()
+
+	exposure := &Exposure{
+		Time:     time.Now(),
+		BySymbol: make(map[string]*SymbolExposure),
+	}
+	for _, r := range results {
+		if r.err != nil {
+			if exposure.Errors == nil {
+				exposure.Errors = make(map[string]string)
+			}
+			exposure.Errors[r.id] = r.err.Error()
+			continue
+		}
+		exposure.Accounts++
+
+		for _, pos := range r.positions {
+			if pos.Quantity == 0 {
+				continue
+			}
+			exposure.add(r.id, pos)
+		}
+	}
+
+	for _, se := range exposure.BySymbol {
+		sort.Slice(se.Positions, func(i, j int) bool { return se.Positions[i].AccountID < se.Positions[j].AccountID })
+		exposure.Symbols = append(exposure.Symbols, *se)
+	}
+	sort.Slice(exposure.Symbols, func(i, j int) bool {
+		if exposure.Symbols[i].Gross != exposure.Symbols[j].Gross {
+			return exposure.Symbols[i].Gross > exposure.Symbols[j].Gross
+		}
+		return exposure.Symbols[i].Symbol < exposure.Symbols[j].Symbol
+	})
+
+	return exposure
+}
+
+// add 累加一个账号持仓
+func (e *Exposure) add(accountID string, pos exchange.Position) {
+	symbol := NormalizeSymbol(pos.Symbol)
+	se := e.BySymbol[symbol]
+	if se == nil {
+		se = &SymbolExposure{Symbol: symbol}
+		e.BySymbol[symbol] = se
+	}
+
+	notional := math.Abs(pos.Notional)
+	side := SideLong
+	if pos.Quantity > 0 {
+		se.Long += notional
+		e.Long += notional
+	} else {
+		side = SideShort
+		se.Short += notional
+		e.Short += notional
+	}
+	se.Net = se.Long - se.Short
+	se.Gross = se.Long + se.Short
+	e.Net = e.Long - e.Short
+	e.Gross = e.Long + e.Short
+
+	se.Positions = append(se.Positions, AccountPosition{
+		AccountID:     accountID,
+		Symbol:        pos.Symbol,
+		Side:          side,
+		Quantity:      pos.Quantity,
+		EntryPrice:    pos.EntryPrice,
+		MarkPrice:     pos.MarkPrice,
+		Notional:      notional,
+		UnrealizedPnL: pos.UnrealizedPnL,
+		Leverage:      pos.Leverage,
+	})
+}
+
+// NormalizeSymbol 币本位永续合约归并到U本位交易对（BTCUSD_PERP → BTCUSDT），其他原样返回
+func NormalizeSymbol(symbol string) string {
+	if base, ok := strings.CutSuffix(symbol, "USD_PERP"); ok {
+		return base + "USDT"
+	}
+	return symbol
+}
@@ -0,0 +1,475 @@
+/*
+Package portfolio 组合风险报告
+
+主要功能：
+- BuildRiskReport(accounts []Account, market exchange.MarketData, cfg config.RiskReportConfig) *RiskReport  // 生成跨账号组合风险报告
+- SaveRiskReport(report *RiskReport, dir string) (string, error)                                          // 报告保存为JSON文件
+- RunRiskReports(ctx context.Context, accounts []Account, market exchange.MarketData, cfg config.RiskReportConfig)  // 定时生成并保存报告，直到ctx取消
+
+报告包含四部分：
+1. 敞口：Aggregate 的跨账号汇总
+2. 相关性调整风险：按净敞口、波动率和两两相关系数计算组合风险价值（95%），与单独风险价值之和对比体现对冲和分散效果
+3. 保证金使用率：各账号 (权益 - 可用) / 权益，权益 = 钱包余额 + 未实现盈亏
+4. 止损全部触发：按当前标记价格计算每个持仓打到止损价的亏损并合计，没有止损的持仓单独列出名义价值
+*/
+package portfolio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// varZScore 95%单尾置信度对应的标准正态分位数
+const varZScore = 1.645
+
+// RiskReport 组合风险报告
+type RiskReport struct {
+	Time             time.Time         `json:"time"`                // 生成时间
+	Exposure         *Exposure         `json:"exposure"`            // 跨账号敞口
+	Correlation      *CorrelationRisk  `json:"correlation"`         // 相关性调整后的风险
+	Margin           MarginSummary     `json:"margin"`              // 保证金使用率
+	StopLoss         StopLossRisk      `json:"stop_loss"`           // 止损全部触发时的亏损
+	WorstCaseLossPct float64           `json:"worst_case_loss_pct"` // 止损全部触发的亏损占总权益的百分比
+	Errors           map[string]string `json:"errors,omitempty"`    // 查询余额失败的账号 -> 错误
+}
+
+// CorrelationRisk 相关性调整后的风险（只统计净敞口不为0的交易对）
+type CorrelationRisk struct {
+	Timeframe            string              `json:"timeframe"`             // K线周期
+	HorizonBars          int                 `json:"horizon_bars"`          // 持有期（K线数量）
+	Symbols              []string            `json:"symbols"`               // 参与计算的交易对
+	Volatility           map[string]float64  `json:"volatility"`            // 每根K线收益率标准差（%）
+	Correlations         []SymbolCorrelation `json:"correlations"`          // 两两相关系数（按绝对值从大到小）
+	StandaloneVaR        float64             `json:"standalone_var"`        // 各交易对单独风险价值之和（美元，相当于假设完全正相关）
+	PortfolioVaR         float64             `json:"portfolio_var"`         // 按相关性调整后的组合风险价值（美元）
+	DiversificationRatio float64             `json:"diversification_ratio"` // 组合风险价值 / 单独风险价值之和（越小分散效果越好）
+	Missing              map[string]string   `json:"missing,omitempty"`     // 未计入的交易对 -> 原因
+}
+
+// SymbolCorrelation 两个交易对的收益率相关系数
+type SymbolCorrelation struct {
+	SymbolA     string  `json:"symbol_a"`
+	SymbolB     string  `json:"symbol_b"`
+	Correlation float64 `json:"correlation"`
+}
+
+// MarginSummary 保证金使用率汇总（只汇总保证金资产为USDT的账号）
+type MarginSummary struct {
+	Equity      float64       `json:"equity"`      // 总权益（USDT）
+	Used        float64       `json:"used"`        // 已占用保证金（USDT）
+	Utilization float64       `json:"utilization"` // 使用率（%）
+	Accounts    []MarginUsage `json:"accounts"`    // 各账号明细
+}
+
+// MarginUsage 单个账号的保证金使用情况
+type MarginUsage struct {
+	AccountID   string  `json:"account_id"`
+	Asset       string  `json:"asset"`       // 保证金资产
+	Equity      float64 `json:"equity"`      // 权益（钱包余额 + 未实现盈亏）
+	Available   float64 `json:"available"`   // 可用余额
+	Used        float64 `json:"used"`        // 已占用（权益 - 可用）
+	Utilization float64 `json:"utilization"` // 使用率（%）
+}
+
+// StopLossRisk 所有止损同时触发时的亏损
+type StopLossRisk struct {
+	WorstCaseLoss       float64        `json:"worst_case_loss"`      // 有止损的持仓打到止损价的亏损合计（美元，从当前标记价格算起）
+	UnprotectedNotional float64        `json:"unprotected_notional"` // 没有止损的持仓名义价值合计（美元，亏损无上限）
+	Positions           []StopLossLine `json:"positions"`            // 各持仓明细（按亏损从大到小）
+}
+
+// StopLossLine 单个持仓的止损亏损
+type StopLossLine struct {
+	AccountID string  `json:"account_id"`
+	Symbol    string  `json:"symbol"`     // 账号内的交易对
+	Side      string  `json:"side"`       // long 或 short
+	Notional  float64 `json:"notional"`   // 名义价值（美元）
+	MarkPrice float64 `json:"mark_price"` // 标记价格
+	StopLoss  float64 `json:"stop_loss"`  // 止损价（0表示没有止损）
+	Loss      float64 `json:"loss"`       // 打到止损价的亏损（美元）
+}
+
+// BuildRiskReport 生成跨账号组合风险报告
+// market: 获取U本位交易对K线的行情接口（为nil时跳过相关性风险）
+func BuildRiskReport(accounts []Account, market exchange.MarketData, cfg config.RiskReportConfig) *RiskReport {
+	report := &RiskReport{
+		Time:     time.Now(),
+		Exposure: Aggregate(accounts),
+	}
+
+	report.Margin, report.Errors = marginSummary(accounts)
+	report.StopLoss = stopLossRisk(accounts, report.Exposure)
+	if market != nil {
+		report.Correlation = correlationRisk(report.Exposure, market, cfg)
+	}
+	if report.Margin.Equity > 0 {
+		report.WorstCaseLossPct = round2(report.StopLoss.WorstCaseLoss / report.Margin.Equity * 100)
+	}
+
+	return report
+}
+
+// marginSummary 并发查询各账号保证金余额并汇总
+func marginSummary(accounts []Account) (MarginSummary, map[string]string) {
+	type result struct {
+		usage MarginUsage
+		err   error
+	}
+
+	results := make([]*result, len(accounts))
+	var wg sync.WaitGroup
+	for i, acc := range accounts {
+		if acc.MarginAsset == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, acc Account) {
+			defer wg.Done()
+			balance, err := acc.Trading.GetBalance(acc.MarginAsset)
+			if err != nil {
+				results[i] = &result{usage: MarginUsage{AccountID: acc.ID}, err: err}
+				return
+			}
+
+			equity := balance.Total + balance.UnrealizedPnL
+			usage := MarginUsage{
+				AccountID: acc.ID,
+				Asset:     balance.Asset,
+				Equity:    equity,
+				Available: balance.Available,
+				Used:      math.Max(equity-balance.Available, 0),
+			}
+			if equity > 0 {
+				usage.Utilization = round2(usage.Used / equity * 100)
+			}
+			results[i] = &result{usage: usage}
+		}(i, acc)
+	}
+	wg.Wait()
+
+	var summary MarginSummary
+	var errs map[string]string
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		if r.err != nil {
+			if errs == nil {
+				errs = make(map[string]string)
+			}
+			errs[r.usage.AccountID] = r.err.Error()
+			continue
+		}
+		summary.Accounts = append(summary.Accounts, r.usage)
+		if r.usage.Asset == "USDT" {
+			summary.Equity += r.usage.Equity
+			summary.Used += r.usage.Used
+		}
+	}
+	if summary.Equity > 0 {
+		summary.Utilization = round2(summary.Used / summary.Equity * 100)
+	}
+
+	return summary, errs
+}
+
+// stopLossRisk 按当前标记价格计算所有止损同时触发时的亏损
+func stopLossRisk(accounts []Account, exposure *Exposure) StopLossRisk {
+	stops := make(map[string]map[string]float64, len(accounts))
+	for _, acc := range accounts {
+		if acc.Stops != nil {
+			stops[acc.ID] = acc.Stops()
+		}
+	}
+
+	var risk StopLossRisk
+	for _, se := range exposure.Symbols {
+		for _, pos := range se.Positions {
+			line := StopLossLine{
+				AccountID: pos.AccountID,
+				Symbol:    pos.Symbol,
+				Side:      pos.Side,
+				Notional:  pos.Notional,
+				MarkPrice: pos.MarkPrice,
+				StopLoss:  stops[pos.AccountID][pos.Symbol],
+			}
+			if line.MarkPrice <= 0 {
+				line.MarkPrice = pos.EntryPrice
+			}
+
+			if line.StopLoss <= 0 || line.MarkPrice <= 0 {
+				// 没有止损：亏损无上限，只统计名义价值
+				line.StopLoss = 0
+				risk.UnprotectedNotional += line.Notional
+			} else {
+				// 止损价已越过标记价格（即将触发或已锁定利润）时按0计
+				move := (line.MarkPrice - line.StopLoss) / line.MarkPrice
+				if pos.Side == SideShort {
+					move = -move
+				}
+				line.Loss = round2(line.Notional * math.Max(move, 0))
+				risk.WorstCaseLoss += line.Loss
+			}
+			risk.Positions = append(risk.Positions, line)
+		}
+	}
+
+	sort.Slice(risk.Positions, func(i, j int) bool {
+		a, b := risk.Positions[i], risk.Positions[j]
+		// 没有止损的排在最前面
+		if (a.StopLoss == 0) != (b.StopLoss == 0) {
+			return a.StopLoss == 0
+		}
+		if a.Loss != b.Loss {
+			return a.Loss > b.Loss
+		}
+		return a.Notional > b.Notional
+	})
+	risk.WorstCaseLoss = round2(risk.WorstCaseLoss)
+	risk.UnprotectedNotional = round2(risk.UnprotectedNotional)
+
+	return risk
+}
+
+// returnSeries 单个交易对的对数收益率（按K线开盘时间索引）
+type returnSeries struct {
+	returns map[int64]float64
+	std     float64
+}
+
+// correlationRisk 按净敞口、波动率和相关系数计算组合风险价值
+func correlationRisk(exposure *Exposure, market exchange.MarketData, cfg config.RiskReportConfig) *CorrelationRisk {
+	risk := &CorrelationRisk{
+		Timeframe:   cfg.Timeframe,
+		HorizonBars: cfg.HorizonBars,
+		Volatility:  make(map[string]float64),
+	}
+
+	// 并发获取净敞口不为0的交易对K线
+	var symbols []string
+	for _, se := range exposure.Symbols {
+		if se.Net != 0 {
+			symbols = append(symbols, se.Symbol)
+		}
+	}
+	series := make([]*returnSeries, len(symbols))
+	errs := make([]error, len(symbols))
+	var wg sync.WaitGroup
+	for i, symbol := range symbols {
+		wg.Add(1)
+		go func(i int, symbol string) {
+			defer wg.Done()
+			klines, err := market.GetKlines(symbol, cfg.Timeframe, cfg.Lookback+1)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			series[i], errs[i] = logReturns(klines)
+		}(i, symbol)
+	}
+	wg.Wait()
+
+	var weights []float64
+	var used []*returnSeries
+	for i, symbol := range symbols {
+		if errs[i] != nil {
+			if risk.Missing == nil {
+				risk.Missing = make(map[string]string)
+			}
+			risk.Missing[symbol] = errs[i].Error()
+			continue
+		}
+		risk.Symbols = append(risk.Symbols, symbol)
+		risk.Volatility[symbol] = round2(series[i].std * 100)
+		weights = append(weights, exposure.BySymbol[symbol].Net)
+		used = append(used, series[i])
+	}
+
+	// 组合方差 = Σ wi·wj·ρij·σi·σj（wi为带方向的净敞口）
+	variance := 0.0
+	standalone := 0.0
+	for i := range used {
+		standalone += math.Abs(weights[i]) * used[i].std
+		variance += weights[i] * weights[i] * used[i].std * used[i].std
+		for j := i + 1; j < len(used); j++ {
+			rho := correlation(used[i], used[j])
+			risk.Correlations = append(risk.Correlations, SymbolCorrelation{
+				SymbolA:     risk.Symbols[i],
+				SymbolB:     risk.Symbols[j],
+				Correlation: math.Round(rho*1000) / 1000,
+			})
+			variance += 2 * weights[i] * weights[j] * rho * used[i].std * used[j].std
+		}
+	}
+	sort.Slice(risk.Correlations, func(i, j int) bool {
+		return math.Abs(risk.Correlations[i].Correlation) > math.Abs(risk.Correlations[j].Correlation)
+	})
+
+	// 两两对齐计算的相关矩阵不保证半正定，方差可能略小于0
+	horizon := varZScore * math.Sqrt(float64(cfg.HorizonBars))
+	risk.StandaloneVaR = round2(standalone * horizon)
+	risk.PortfolioVaR = round2(math.Sqrt(math.Max(variance, 0)) * horizon)
+	if risk.StandaloneVaR > 0 {
+		risk.DiversificationRatio = math.Round(risk.PortfolioVaR/risk.StandaloneVaR*1000) / 1000
+	}
+
+	return risk
+}
+
+// logReturns 计算K线收盘价的对数收益率及其标准差
+func logReturns(klines []exchange.Kline) (*returnSeries, error) {
+	if len(klines) < 21 {
+		return nil, fmt.Errorf("K线数量不足: %d", len(klines))
+	}
+
+	s := &returnSeries{returns: make(map[int64]float64, len(klines)-1)}
+	values := make([]float64, 0, len(klines)-1)
+	prev := 0.0
+	for i, k := range klines {
+		price := parseClose(k)
+		if price <= 0 {
+			return nil, fmt.Errorf("K线收盘价无效: %s", k.Close)
+		}
+		if i > 0 {
+			r := math.Log(price / prev)
+			s.returns[k.OpenTime] = r
+			values = append(values, r)
+		}
+		prev = price
+	}
+
+	_, s.std = meanStd(values)
+	return s, nil
+}
+
+// correlation 两个交易对在共同K线上的收益率相关系数（共同K线少于20根时返回1，按完全相关保守处理）
+func correlation(a, b *returnSeries) float64 {
+	var xs, ys []float64
+	for t, x := range a.returns {
+		if y, ok := b.returns[t]; ok {
+			xs = append(xs, x)
+			ys = append(ys, y)
+		}
+	}
+	if len(xs) < 20 {
+		return 1
+	}
+
+	mx, sx := meanStd(xs)
+	my, sy := meanStd(ys)
+	if sx == 0 || sy == 0 {
+		return 0
+	}
+	cov := 0.0
+	for i := range xs {
+		cov += (xs[i] - mx) * (ys[i] - my)
+	}
+	return cov / float64(len(xs)) / (sx * sy)
+}
+
+// meanStd 均值和总体标准差
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// parseClose 解析K线收盘价
+func parseClose(k exchange.Kline) float64 {
+	price, _ := strconv.ParseFloat(k.Close, 64)
+	return price
+}
+
+// round2 保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// SaveRiskReport 报告保存为JSON文件（risk_YYYYMMDD_HHMMSS.json），返回文件路径
+func SaveRiskReport(report *RiskReport, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建报告目录失败: %w", err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("序列化风险报告失败: %w", err)
+	}
+
+	path := filepath.Join(dir, "risk_"+report.Time.Format("20060102_150405")+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("写入风险报告失败: %w", err)
+	}
+	return path, nil
+}
+
+// RunRiskReports 定时生成并保存组合风险报告，直到ctx取消
+func RunRiskReports(ctx context.Context, accounts []Account, market exchange.MarketData, cfg config.RiskReportConfig) {
+	ticker := time.NewTicker(time.Duration(cfg.IntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			report := BuildRiskReport(accounts, market, cfg)
+			path, err := SaveRiskReport(report, cfg.Dir)
+			if err != nil {
+				utils.Error("保存组合风险报告失败", zap.Error(err))
+			}
+			logRiskReport(report, path)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// logRiskReport 输出报告摘要
+func logRiskReport(r *RiskReport, path string) {
+	fields := []zap.Field{
+		zap.String("file", path),
+		zap.Float64("gross", r.Exposure.Gross),
+		zap.Float64("net", r.Exposure.Net),
+		zap.Float64("equity", r.Margin.Equity),
+		zap.Float64("margin_utilization", r.Margin.Utilization),
+		zap.Float64("worst_case_loss", r.StopLoss.WorstCaseLoss),
+		zap.Float64("worst_case_loss_pct", r.WorstCaseLossPct),
+		zap.Float64("unprotected_notional", r.StopLoss.UnprotectedNotional),
+	}
+	if r.Correlation != nil {
+		fields = append(fields,
+			zap.Float64("portfolio_var", r.Correlation.PortfolioVaR),
+			zap.Float64("standalone_var", r.Correlation.StandaloneVaR),
+		)
+	}
+
+	if r.StopLoss.UnprotectedNotional > 0 || len(r.Errors) > 0 || len(r.Exposure.Errors) > 0 {
+		utils.Warn("组合风险报告（存在无止损持仓或查询失败的账号）", fields...)
+		return
+	}
+	utils.Info("组合风险报告", fields...)
+}
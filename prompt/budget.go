@@ -0,0 +1,223 @@
+/*
+Package prompt 提示词token预算与数据压缩
+
+主要功能：
+- EstimateTokens(text string) int                                                               // 估算文本的token数量
+- (s *Store) RenderBudget(name string, data *Data, budget config.PromptBudgetConfig) (string, *Reduction, error)  // 渲染提示词，超出预算时逐步压缩指标数据
+
+估算规则：ASCII字符按4个一个token，其他字符（中文等）按每个字符一个token，与常见分词器的结果大致相当（偏保守）。
+
+超出 max_tokens 时按以下顺序压缩指标数据的副本（原数据不变），每一步之后重新渲染，满足预算即停止：
+1. 去掉可选字段（optional_fields 中列出的JSON字段，如预留的扩展指标、OI历史）
+2. 所有浮点数保留 significant_digits 位有效数字
+3. 按 drop_timeframes 的顺序逐个去掉时间周期（JSON字段名，如 5m）
+某一步导致模板渲染失败（如模板直接引用了被去掉的字段）时撤销该步，继续下一步。
+全部压缩后仍超出预算时照常返回提示词，由调用方记录警告。
+*/
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"crypto-ai-trader/config"
+)
+
+// Reduction 提示词压缩记录
+type Reduction struct {
+	OriginalTokens int      `json:"original_tokens"` // 压缩前的估算token数
+	Tokens         int      `json:"tokens"`          // 压缩后的估算token数
+	Removed        []string `json:"removed"`         // 执行的压缩步骤（去掉的字段、时间周期，数值取整）
+	OverBudget     bool     `json:"over_budget"`     // 全部压缩后仍超出预算
+}
+
+// EstimateTokens 估算文本的token数量
+func EstimateTokens(text string) int {
+	ascii, other := 0, 0
+	for i := 0; i < len(text); {
+		if text[i] < utf8.RuneSelf {
+			ascii++
+			i++
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		other++
+		i += size
+	}
+	return (ascii+3)/4 + other
+}
+
+// RenderBudget 渲染提示词，超出预算时逐步压缩指标数据
+// 未超出预算（或未设置预算）时返回的 Reduction 为nil
+func (s *Store) RenderBudget(name string, data *Data, budget config.PromptBudgetConfig) (string, *Reduction, error) {
+	text, err := s.Render(name, data)
+	if err != nil {
+		return "", nil, err
+	}
+	tokens := EstimateTokens(text)
+	if budget.MaxTokens <= 0 || tokens <= budget.MaxTokens {
+		return text, nil, nil
+	}
+
+	reduction := &Reduction{OriginalTokens: tokens, Tokens: tokens}
+	indicators, ok := copyIndicators(data.Indicators)
+	if !ok {
+		reduction.OverBudget = true
+		return text, reduction, nil
+	}
+
+	type step struct {
+		label string
+		apply func(v reflect.Value) bool // 返回是否有改动
+	}
+	var steps []step
+	if len(budget.OptionalFields) > 0 {
+		steps = append(steps, step{
+			label: "可选字段 " + strings.Join(budget.OptionalFields, ","),
+			apply: func(v reflect.Value) bool { return clearFields(v, toSet(budget.OptionalFields)) },
+		})
+	}
+	if budget.SignificantDigits > 0 {
+		steps = append(steps, step{
+			label: fmt.Sprintf("数值保留%d位有效数字", budget.SignificantDigits),
+			apply: func(v reflect.Value) bool { return roundFloats(v, budget.SignificantDigits) },
+		})
+	}
+	for _, tf := range budget.DropTimeframes {
+		steps = append(steps, step{
+			label: "时间周期 " + tf,
+			apply: func(v reflect.Value) bool { return clearFields(v, toSet([]string{tf})) },
+		})
+	}
+
+	reduced := *data
+	for _, st := range steps {
+		candidate, _ := copyIndicators(indicators)
+		if !st.apply(reflect.ValueOf(candidate)) {
+			continue
+		}
+		reduced.Indicators = candidate
+		candidateText, err := s.Render(name, &reduced)
+		if err != nil {
+			// 模板引用了被去掉的字段，撤销这一步
+			continue
+		}
+
+		indicators, text = candidate, candidateText
+		reduction.Removed = append(reduction.Removed, st.label)
+		reduction.Tokens = EstimateTokens(text)
+		if reduction.Tokens <= budget.MaxTokens {
+			return text, reduction, nil
+		}
+	}
+
+	reduction.OverBudget = true
+	return text, reduction, nil
+}
+
+// copyIndicators 通过JSON深拷贝指标数据（只支持结构体指针）
+func copyIndicators(v interface{}) (interface{}, bool) {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, false
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	copied := reflect.New(t.Elem())
+	if err := json.Unmarshal(raw, copied.Interface()); err != nil {
+		return nil, false
+	}
+	return copied.Interface(), true
+}
+
+// clearFields 递归清空JSON字段名在集合中的字段（置为零值）
+func clearFields(v reflect.Value, names map[string]bool) bool {
+	changed := false
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			changed = clearFields(v.Elem(), names)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			if names[jsonName(t.Field(i))] {
+				if !field.IsZero() {
+					field.Set(reflect.Zero(field.Type()))
+					changed = true
+				}
+				continue
+			}
+			changed = clearFields(field, names) || changed
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			changed = clearFields(v.Index(i), names) || changed
+		}
+	}
+	return changed
+}
+
+// roundFloats 递归把浮点数保留指定位数的有效数字
+func roundFloats(v reflect.Value, digits int) bool {
+	changed := false
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			changed = roundFloats(v.Elem(), digits)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				changed = roundFloats(v.Field(i), digits) || changed
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			changed = roundFloats(v.Index(i), digits) || changed
+		}
+	case reflect.Float32, reflect.Float64:
+		if rounded := roundSignificant(v.Float(), digits); rounded != v.Float() && v.CanSet() {
+			v.SetFloat(rounded)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// roundSignificant 保留指定位数的有效数字
+func roundSignificant(x float64, digits int) float64 {
+	if x == 0 || math.IsNaN(x) || math.IsInf(x, 0) {
+		return x
+	}
+	scale := math.Pow(10, float64(digits)-math.Ceil(math.Log10(math.Abs(x))))
+	return math.Round(x*scale) / scale
+}
+
+// jsonName 结构体字段的JSON名称
+func jsonName(f reflect.StructField) string {
+	tag := f.Tag.Get("json")
+	if tag == "" {
+		return f.Name
+	}
+	return strings.Split(tag, ",")[0]
+}
+
+// toSet 字符串列表转为集合
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
@@ -0,0 +1,142 @@
+/*
+Package prompt 指标数据的紧凑格式（减少提示词token）
+
+主要功能：
+- Compact(v interface{}) (string, error)  // 紧凑格式的指标数据（字段缩写说明 + 单行JSON）
+- (d *Data) Payload() (string, error)      // 模板中的指标数据：账号配置 payload_format: compact 时为紧凑格式，delta 时只列出变化明显的字段，否则为格式化JSON
+
+紧凑格式在完整JSON的基础上：
+1. 常用字段换成短名称（如 close_price → c、timeframes → tf），第一行给出本次用到的缩写说明
+2. 非整数的浮点数保留 compactDigits 位有效数字
+3. 省略为nil、0、false、空字符串的字段，以及省略后为空的对象和数组
+4. 不缩进、不换行
+适用于 ShortTermIndicators、LongTermIndicators 等所有可以JSON序列化的指标数据。
+*/
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// compactDigits 紧凑格式的浮点数有效数字位数
+const compactDigits = 5
+
+// compactKeys 紧凑格式的字段缩写（JSON字段名 → 短名称），未列出的字段保持原名
+// 只缩写每个时间周期都会出现的字段，只出现一次的字段缩写后节省的token抵不上缩写说明的长度
+var compactKeys = map[string]string{
+	"timeframes":      "tf",
+	"open_price":      "o",
+	"high_price":      "h",
+	"low_price":       "l",
+	"close_price":     "c",
+	"live_price":      "lp",
+	"ema9":            "e9",
+	"ema21":           "e21",
+	"ema55":           "e55",
+	"ema_state":       "es",
+	"ema21_slope_pct": "e21s",
+	"ema55_dist_atr":  "e55d",
+	"cross_bars":      "xb",
+	"atr_pct":         "atr%",
+	"bb_width_pct":    "bbw%",
+	"bb_squeeze":      "sq",
+	"volume":          "v",
+	"quote_volume":    "qv",
+	"taker_buy_pct":   "tb%",
+	"volume_ratio":    "vr",
+	"stoch_rsi":       "srsi",
+}
+
+// Compact 紧凑格式的指标数据（字段缩写说明 + 单行JSON）
+func Compact(v interface{}) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("序列化指标数据失败: %w", err)
+	}
+	var tree interface{}
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return "", fmt.Errorf("解析指标数据失败: %w", err)
+	}
+
+	used := make(map[string]string)
+	tree, _ = compactValue(tree, used)
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return "", fmt.Errorf("序列化紧凑格式失败: %w", err)
+	}
+	if len(used) == 0 {
+		return string(data), nil
+	}
+
+	legend := make([]string, 0, len(used))
+	for short, name := range used {
+		legend = append(legend, short+"="+name)
+	}
+	sort.Strings(legend)
+	return "字段缩写：" + strings.Join(legend, " ") + "（省略的字段为0或空）\n" + string(data), nil
+}
+
+// compactValue 缩写字段名、降低精度并去掉空值，返回是否保留该值
+func compactValue(v interface{}, used map[string]string) (interface{}, bool) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(x))
+		for key, item := range x {
+			item, ok := compactValue(item, used)
+			if !ok {
+				continue
+			}
+			if short, ok := compactKeys[key]; ok {
+				used[short] = key
+				key = short
+			}
+			out[key] = item
+		}
+		return out, len(out) > 0
+	case []interface{}:
+		out := make([]interface{}, 0, len(x))
+		for _, item := range x {
+			// 数组元素保留0值，保证位置不变
+			if item, ok := compactValue(item, used); ok {
+				out = append(out, item)
+			} else if _, isNum := item.(float64); isNum {
+				out = append(out, 0)
+			}
+		}
+		return out, len(out) > 0
+	case float64:
+		if x == math.Trunc(x) {
+			// 整数（时间戳、数量等）保持原值
+			return x, x != 0
+		}
+		// 按十进制有效数字取整，避免 roundSignificant 的二进制误差使JSON变长
+		rounded, _ := strconv.ParseFloat(strconv.FormatFloat(x, 'g', compactDigits, 64), 64)
+		return rounded, true
+	case string:
+		return x, x != ""
+	case bool:
+		return x, x
+	default:
+		return nil, false
+	}
+}
+
+// Payload 模板中的指标数据：账号配置 payload_format: compact 时为紧凑格式，delta 时只列出变化明显的字段（完整输出时为格式化JSON），否则为格式化JSON
+func (d *Data) Payload() (string, error) {
+	if d.Delta != nil && !d.Delta.Full {
+		return "只列出与上次发送相比变化明显的字段（路径: 上次值 → 当前值，其余字段没有明显变化）：\n" + d.Delta.String(), nil
+	}
+	if d.Compact {
+		return Compact(d.Indicators)
+	}
+	data, err := json.MarshalIndent(d.Indicators, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,210 @@
+/*
+Package prompt 指标数据的变化输出（只输出与上次输出相比变化明显的字段）
+
+主要功能：
+- NewDeltaTracker(cfg config.DeltaConfig) *DeltaTracker                                    // 创建变化跟踪器（按交易对保存上次输出的数值）
+- (t *DeltaTracker) Update(symbol string, data interface{}, now time.Time) (*Delta, error) // 与上次输出比较，返回变化明显的字段
+- (d *Delta) String() string                                                               // 变化的文字描述（提示词和日志）
+
+指标数据按JSON路径展开为字段（如 timeframes.15m.rsi、market_data.oi_history.0）。比较的基准是每个字段上次输出的值，
+而不是上一周期的值：变化明显的字段输出后更新基准，其余字段保持基准不变，缓慢的累积变化超过阈值时同样会输出。
+数值字段按 thresholds 中的绝对阈值（按路径或字段名）比较，未配置的按相对变化 default_pct% 比较；
+字符串、布尔值有变化即输出，字段出现或消失（如 ema_state.cross_bars 由空变为有值）也输出。
+每 full_every 次（含第一次）为完整输出，基准更新为当前全部字段。
+*/
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/config"
+)
+
+// FieldChange 一个字段的变化
+type FieldChange struct {
+	Path string      `json:"path"` // JSON路径（如 timeframes.15m.rsi）
+	Old  interface{} `json:"old"`  // 上次输出的值（新出现的字段为nil）
+	New  interface{} `json:"new"`  // 当前值（消失的字段为nil）
+}
+
+// Delta 一次比较的结果
+type Delta struct {
+	Full    bool          `json:"full"`              // 完整输出（第一次或每 full_every 次），此时 Changes 为空
+	Since   time.Time     `json:"since"`             // 上次输出的时间（完整输出时为零值）
+	Changes []FieldChange `json:"changes,omitempty"` // 变化明显的字段（按路径排序）
+}
+
+// DeltaTracker 指标变化跟踪器（并发安全）
+type DeltaTracker struct {
+	cfg    config.DeltaConfig
+	mu     sync.Mutex
+	states map[string]*deltaState // 交易对 → 上次输出的状态
+}
+
+// deltaState 一个交易对上次输出的状态
+type deltaState struct {
+	baseline map[string]interface{} // 各字段上次输出的值
+	last     time.Time              // 上次输出的时间
+	count    int                    // 距上次完整输出的次数
+}
+
+// NewDeltaTracker 创建变化跟踪器（cfg 应为 config.GetDeltaConfig 的结果）
+func NewDeltaTracker(cfg config.DeltaConfig) *DeltaTracker {
+	return &DeltaTracker{cfg: cfg, states: make(map[string]*deltaState)}
+}
+
+// Update 与该交易对上次输出比较，返回变化明显的字段并更新基准
+func (t *DeltaTracker) Update(symbol string, data interface{}, now time.Time) (*Delta, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("序列化指标数据失败: %w", err)
+	}
+	var tree interface{}
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return nil, fmt.Errorf("解析指标数据失败: %w", err)
+	}
+	current := make(map[string]interface{})
+	flatten(tree, "", current)
+	for path := range current {
+		if t.ignored(path) {
+			delete(current, path)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state := t.states[symbol]
+	if state == nil || state.count+1 >= t.cfg.FullEvery {
+		t.states[symbol] = &deltaState{baseline: current, last: now}
+		return &Delta{Full: true}, nil
+	}
+
+	delta := &Delta{Since: state.last}
+	for path, value := range current {
+		if old, ok := state.baseline[path]; !ok || t.material(path, old, value) {
+			delta.Changes = append(delta.Changes, FieldChange{Path: path, Old: old, New: value})
+			state.baseline[path] = value
+		}
+	}
+	for path, old := range state.baseline {
+		if _, ok := current[path]; !ok {
+			delta.Changes = append(delta.Changes, FieldChange{Path: path, Old: old})
+			delete(state.baseline, path)
+		}
+	}
+	sort.Slice(delta.Changes, func(i, j int) bool { return delta.Changes[i].Path < delta.Changes[j].Path })
+	state.last = now
+	state.count++
+	return delta, nil
+}
+
+// material 字段相对基准的变化是否明显
+func (t *DeltaTracker) material(path string, old, value interface{}) bool {
+	a, okA := old.(float64)
+	b, okB := value.(float64)
+	if !okA || !okB {
+		return old != value
+	}
+	if a == b {
+		return false
+	}
+	if threshold, ok := lookupField(t.cfg.Thresholds, path); ok {
+		return math.Abs(b-a) >= threshold
+	}
+	if a == 0 {
+		return true
+	}
+	return math.Abs(b-a)/math.Abs(a)*100 >= t.cfg.DefaultPct
+}
+
+// ignored 字段是否不参与比较
+func (t *DeltaTracker) ignored(path string) bool {
+	for _, name := range t.cfg.Ignore {
+		if matchField(path, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// String 变化的文字描述，每行一个字段：路径: 上次值 → 当前值
+func (d *Delta) String() string {
+	if d.Full {
+		return "完整数据"
+	}
+	if len(d.Changes) == 0 {
+		return fmt.Sprintf("与上次（%s）相比没有明显变化", d.Since.Format("15:04:05"))
+	}
+	lines := make([]string, 0, len(d.Changes))
+	for _, c := range d.Changes {
+		lines = append(lines, fmt.Sprintf("%s: %s → %s", c.Path, formatValue(c.Old), formatValue(c.New)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// flatten 把JSON值展开为 路径 → 标量值（数组元素的路径为下标）
+func flatten(v interface{}, prefix string, out map[string]interface{}) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for key, item := range x {
+			flatten(item, join(key), out)
+		}
+	case []interface{}:
+		for i, item := range x {
+			flatten(item, join(strconv.Itoa(i)), out)
+		}
+	case nil:
+		// 空字段视为不存在
+	default:
+		out[prefix] = x
+	}
+}
+
+// lookupField 按路径查找配置：包含"."的名称按路径前缀匹配，否则匹配任一级字段名；多个匹配时取最长的名称
+func lookupField(fields map[string]float64, path string) (float64, bool) {
+	best, value := "", 0.0
+	for name, v := range fields {
+		if matchField(path, name) && len(name) > len(best) {
+			best, value = name, v
+		}
+	}
+	return value, best != ""
+}
+
+// matchField 路径是否匹配字段名（如 rsi）或路径（如 timeframes.15m、timeframes.15m.rsi）
+func matchField(path, name string) bool {
+	if strings.Contains(name, ".") {
+		return path == name || strings.HasPrefix(path, name+".")
+	}
+	for _, part := range strings.Split(path, ".") {
+		if part == name {
+			return true
+		}
+	}
+	return false
+}
+
+// formatValue 格式化字段值（nil 为"无"）
+func formatValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "无"
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return fmt.Sprint(x)
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Package prompt 指标数据指纹（判断数据是否与上次分析相同）
+
+主要功能：
+- PayloadHash(template string, indicators interface{}, digits int, ignore []string) string  // 按有效数字取整后的指标数据指纹（不支持的类型返回空字符串）
+
+两个周期之间K线可能只有末位小数的变化，对AI的判断没有影响。计算指纹前在指标数据的副本上去掉
+ignore 中的字段（如每个周期都会变化的 timestamp），所有浮点数保留 digits 位有效数字，
+再与模板名称一起做SHA-256。指纹相同表示“实质上相同”的提示词。
+*/
+package prompt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+)
+
+// PayloadHash 按有效数字取整后的指标数据指纹（不支持的类型返回空字符串）
+func PayloadHash(template string, indicators interface{}, digits int, ignore []string) string {
+	copied, ok := copyIndicators(indicators)
+	if !ok {
+		return ""
+	}
+	v := reflect.ValueOf(copied)
+	if len(ignore) > 0 {
+		clearFields(v, toSet(ignore))
+	}
+	if digits > 0 {
+		roundFloats(v, digits)
+	}
+
+	raw, err := json.Marshal(copied)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(append([]byte(template+"\n"), raw...))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,307 @@
+/*
+Package prompt AI提示词模板（configs/prompts/*.tmpl，支持热加载）
+
+主要功能：
+- NewStore(dir string) (*Store, error)                           // 加载目录下所有提示词模板
+- (s *Store) Reload() (bool, error)                               // 模板文件有变化时重新加载（解析失败时保留旧模板）
+- (s *Store) Watch(ctx context.Context, interval time.Duration)   // 定时检查模板文件变化并重新加载
+- (s *Store) Lookup(names ...string) string                       // 按顺序返回第一个存在的模板名称
+- (s *Store) Render(name string, data interface{}) (string, error) // 渲染提示词（Data、RankingData 或 DecisionData）
+- (s *Store) Names() []string                                     // 获取所有模板名称
+- (s *Store) Version(name string) string                          // 模板版本（模板及公共片段内容的哈希）
+
+模板使用 Go text/template 语法，模板名称为文件名去掉 .tmpl 后缀。所有文件解析在同一个模板集合中，
+可以用 {{define}} / {{template}} 共享片段。单个交易对的模板数据为 Data，指标结构在 .Indicators 中（{{.Payload}} 按账号配置输出完整、紧凑或变化格式），多周期趋势摘要在 .Summary 中；
+账号在该交易对有持仓时 .Position 为持仓状态（方向、入场价、浮动盈亏、持仓时长、止损止盈），
+可以用公共片段 {{template "position" .}} 输出；排名模板的数据为 RankingData，每个交易对的关键指标在 .Rows 中；两阶段分析的决策模板数据为 DecisionData，
+第一阶段的分析结论在 .Analysis 中，账户状态在 .Account 中。
+可用函数：json（格式化为JSON）、round（保留小数位数）。
+模板版本为模板文件与公共片段文件（含 {{define}} 的文件）内容的哈希，修改公共片段时引用它的模板版本都会变化，
+决策按版本打标签，修改提示词后自动形成新的对照组。
+*/
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// templateExt 模板文件后缀
+const templateExt = ".tmpl"
+
+// Data 模板数据
+type Data struct {
+	AccountID    string      // 账号ID
+	Strategy     string      // 策略注册名称
+	StrategyName string      // 策略名称（中文）
+	Symbol       string      // 交易对
+	Time         time.Time   // 生成提示词的时间
+	Indicators   interface{} // 策略输出的指标数据（如 *indicators.ShortTermIndicators）
+	Summary      string      // 多周期趋势摘要（由指标数据按固定规则生成，见 indicators.Describe）
+	Compact      bool        // 用紧凑格式输出指标数据（账号配置 payload_format: compact，见 Payload）
+	Delta        *Delta      // 与上次发送相比的指标变化（账号配置 payload_format: delta，见 Payload；为nil或完整输出时输出全部指标）
+
+	Position *PositionState // 账号在该交易对的持仓（没有时为nil），AI据此管理已有仓位而不是重复开仓
+}
+
+// RankingData 多交易对排名模板数据
+type RankingData struct {
+	AccountID    string                   // 账号ID
+	Strategy     string                   // 策略注册名称
+	StrategyName string                   // 策略名称（中文）
+	Time         time.Time                // 生成提示词的时间
+	TopN         int                      // 需要挑选的候选数量
+	Rows         []*indicators.KeyMetrics // 每个交易对的关键指标
+}
+
+// DecisionData 两阶段分析的决策模板数据
+type DecisionData struct {
+	AccountID    string        // 账号ID
+	Strategy     string        // 策略注册名称
+	StrategyName string        // 策略名称（中文）
+	Symbol       string        // 交易对
+	Time         time.Time     // 生成提示词的时间
+	Analysis     string        // 分析阶段AI输出的市场分析
+	Account      *AccountState // 账户状态
+}
+
+// AccountState 账户状态（决策阶段参考）
+type AccountState struct {
+	Shadow    bool             `json:"shadow"`             // 是否为影子账号
+	Balance   float64          `json:"balance"`            // 账户余额（USDT，影子账号为虚拟权益，无法获取时为0）
+	Position  *PositionState   `json:"position,omitempty"` // 当前交易对的持仓（没有时为nil）
+	Positions []*PositionState `json:"positions"`          // 所有持仓
+}
+
+// PositionState 持仓状态
+type PositionState struct {
+	Symbol     string    `json:"symbol"`      // 交易对
+	Side       string    `json:"side"`        // 方向（BUY做多 / SELL做空）
+	Quantity   float64   `json:"quantity"`    // 持仓数量
+	EntryPrice float64   `json:"entry_price"` // 入场均价
+	StopLoss   float64   `json:"stop_loss"`   // 止损价
+	TakeProfit float64   `json:"take_profit"` // 止盈价
+	Adds       int       `json:"adds"`        // 已加仓次数
+	OpenedAt   time.Time `json:"opened_at"`   // 开仓时间
+	MarkPrice  float64   `json:"mark_price"`  // 最新价（主分析周期收盘价）
+	PnL        float64   `json:"pnl"`         // 按最新价估算的浮动盈亏（USDT，币本位账号为0）
+	PnLPct     float64   `json:"pnl_pct"`     // 按持仓方向的价格涨跌幅（%）
+	Holding    string    `json:"holding"`     // 已持仓时长（如 2小时15分钟）
+	Overdue    bool      `json:"overdue"`     // 是否已超过策略的最长持仓时间（出场评估）
+	MaxHolding string    `json:"max_holding"` // 最长持仓时间（未启用时为空）
+}
+
+// Store 提示词模板集合
+type Store struct {
+	dir string
+
+	mu        sync.RWMutex
+	templates *template.Template
+	versions  map[string]string // 模板名称 → 版本
+	signature string            // 模板文件的名称、大小、修改时间（用于判断是否需要重新加载）
+	failed    string            // 最近一次解析失败的签名（文件未再变化时不重复解析和报错）
+}
+
+// NewStore 加载目录下所有提示词模板
+func NewStore(dir string) (*Store, error) {
+	s := &Store{dir: dir}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload 模板文件有变化时重新加载
+// 返回是否重新加载；解析失败时保留旧模板并返回错误（同一份内容只报错一次）
+func (s *Store) Reload() (bool, error) {
+	signature, err := s.scan()
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	unchanged := (s.templates != nil && signature == s.signature) || signature == s.failed
+	s.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	templates, err := template.New("").Funcs(funcs).ParseGlob(filepath.Join(s.dir, "*"+templateExt))
+	var versions map[string]string
+	if err == nil {
+		versions, err = s.readVersions()
+	}
+	s.mu.Lock()
+	if err != nil {
+		s.failed = signature
+		s.mu.Unlock()
+		return false, fmt.Errorf("解析提示词模板失败: %w", err)
+	}
+	s.templates = templates
+	s.versions = versions
+	s.signature = signature
+	s.failed = ""
+	s.mu.Unlock()
+
+	utils.Info("加载提示词模板",
+		zap.String("dir", s.dir),
+		zap.Strings("templates", s.Names()),
+	)
+	return true, nil
+}
+
+// Watch 定时检查模板文件变化并重新加载（修改模板无需重启或重新编译）
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.Reload(); err != nil {
+				utils.Error("重新加载提示词模板失败，继续使用旧模板", zap.String("dir", s.dir), zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Lookup 按顺序返回第一个存在的模板名称（都不存在时返回空字符串）
+func (s *Store) Lookup(names ...string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, name := range names {
+		if name != "" && s.templates.Lookup(name+templateExt) != nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// Render 渲染提示词（单个交易对用 *Data，排名用 *RankingData，两阶段决策用 *DecisionData）
+func (s *Store) Render(name string, data interface{}) (string, error) {
+	s.mu.RLock()
+	templates := s.templates
+	s.mu.RUnlock()
+
+	t := templates.Lookup(name + templateExt)
+	if t == nil {
+		return "", fmt.Errorf("提示词模板不存在: %s", name)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("渲染提示词模板[%s]失败: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// Names 获取所有模板名称
+func (s *Store) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var names []string
+	for _, t := range s.templates.Templates() {
+		if strings.HasSuffix(t.Name(), templateExt) {
+			names = append(names, strings.TrimSuffix(t.Name(), templateExt))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Version 模板版本（模板不存在时返回空字符串）
+func (s *Store) Version(name string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.versions[name]
+}
+
+// readVersions 读取模板文件内容，计算每个模板的版本（模板内容 + 所有公共片段内容的哈希）
+func (s *Store) readVersions() (map[string]string, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*"+templateExt))
+	if err != nil {
+		return nil, fmt.Errorf("扫描提示词模板失败: %w", err)
+	}
+	sort.Strings(files)
+
+	contents := make(map[string][]byte, len(files))
+	var shared []byte
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("读取提示词模板失败: %w", err)
+		}
+		name := strings.TrimSuffix(filepath.Base(file), templateExt)
+		contents[name] = content
+		if bytes.Contains(content, []byte("{{define")) || bytes.Contains(content, []byte("{{- define")) {
+			shared = append(shared, content...)
+		}
+	}
+
+	versions := make(map[string]string, len(contents))
+	for name, content := range contents {
+		h := sha256.New()
+		h.Write(content)
+		h.Write(shared)
+		versions[name] = hex.EncodeToString(h.Sum(nil)[:6])
+	}
+	return versions, nil
+}
+
+// scan 扫描模板文件，生成由名称、大小、修改时间组成的签名
+func (s *Store) scan() (string, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*"+templateExt))
+	if err != nil {
+		return "", fmt.Errorf("扫描提示词模板失败: %w", err)
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("提示词模板目录为空: %s", s.dir)
+	}
+	sort.Strings(files)
+
+	var sb strings.Builder
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", fmt.Errorf("读取提示词模板信息失败: %w", err)
+		}
+		fmt.Fprintf(&sb, "%s|%d|%d\n", filepath.Base(file), info.Size(), info.ModTime().UnixNano())
+	}
+	return sb.String(), nil
+}
+
+// funcs 模板可用函数
+var funcs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	},
+	"round": func(v float64, digits int) float64 {
+		p := math.Pow(10, float64(digits))
+		return math.Round(v*p) / p
+	},
+}
@@ -0,0 +1,221 @@
+/*
+Package scanner 资金费率扫描（寻找资金费收割机会）
+
+主要功能：
+- ScanFunding(client *binance.Client, symbols []string, cfg FundingConfig) (*FundingReport, error)  // 扫描资金费率并排名
+- (r *FundingReport) Candidates() []FundingCandidate                                                // 满足阈值且方向持续的候选
+
+排名方式：
+ 1. 按当前资金费率绝对值排序（费率越极端，收取的资金费越多）
+ 2. 按资金费率与基差的背离排序：资金费率 ≈ 溢价（基差）+ 利息，
+    两者背离说明当前费率没有被基差支撑，费率或基差可能回归
+
+资金费率为正时做空永续收取资金费，为负时做多。候选要求最近几次资金费率与当前同号，
+避免追逐一次性的极端费率。
+*/
+package scanner
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 收取资金费的方向
+const (
+	DirectionLong  = "long"  // 资金费率为负，做多收取
+	DirectionShort = "short" // 资金费率为正，做空收取
+)
+
+// 默认资金费间隔（小时），无法从历史推断时使用
+const defaultFundingIntervalHours = 8
+
+// FundingConfig 扫描配置
+type FundingConfig struct {
+	MinAbsFundingPct float64 // 候选的最低资金费率绝对值（%/期，默认0.05）
+	TopN             int     // 每个排名保留前N个（默认10）
+	HistoryLimit     int     // 每个入围交易对查询的资金费率历史条数（默认3）
+}
+
+// FundingCandidate 单个交易对的资金费率数据
+type FundingCandidate struct {
+	Symbol          string  `json:"symbol"`            // 交易对
+	FundingRatePct  float64 `json:"funding_rate_pct"`  // 当前资金费率（%）
+	AvgFundingPct   float64 `json:"avg_funding_pct"`   // 最近N次已结算费率平均（%）
+	HistoryCount    int     `json:"history_count"`     // 获取到的已结算费率条数
+	SameSignCount   int     `json:"same_sign_count"`   // 最近N次中与当前费率同号的次数
+	BasisPct        float64 `json:"basis_pct"`         // 基差（%，标记价格相对指数价格）
+	DivergencePct   float64 `json:"divergence_pct"`    // 资金费率与基差之差（%）
+	IntervalHours   float64 `json:"interval_hours"`    // 资金费间隔（小时）
+	AnnualizedPct   float64 `json:"annualized_pct"`    // 按当前费率年化（%）
+	Direction       string  `json:"direction"`         // 收取资金费的方向（long / short）
+	NextFundingTime int64   `json:"next_funding_time"` // 下次资金费时间（毫秒）
+}
+
+// FundingReport 扫描报告
+type FundingReport struct {
+	Timestamp    int64              `json:"timestamp"`     // 扫描时间（毫秒）
+	Scanned      int                `json:"scanned"`       // 扫描的交易对数量
+	ByFunding    []FundingCandidate `json:"by_funding"`    // 按资金费率绝对值排名
+	ByDivergence []FundingCandidate `json:"by_divergence"` // 按资金费率与基差背离排名
+
+	minAbsFundingPct float64
+}
+
+// withDefaults 补全默认值
+func (c FundingConfig) withDefaults() FundingConfig {
+	if c.MinAbsFundingPct == 0 {
+		c.MinAbsFundingPct = 0.05
+	}
+	if c.TopN == 0 {
+		c.TopN = 10
+	}
+	if c.HistoryLimit == 0 {
+		c.HistoryLimit = 3
+	}
+	return c
+}
+
+// ScanFunding 扫描资金费率并排名
+// symbols: 只扫描这些交易对，为空时扫描全部USDT永续合约
+// 溢价指数一次请求获取全部交易对，只有入围排名的交易对才查询资金费率历史
+func ScanFunding(client *binance.Client, symbols []string, cfg FundingConfig) (*FundingReport, error) {
+	cfg = cfg.withDefaults()
+
+	premiums, err := client.GetAllPremiumIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(symbols))
+	for _, s := range symbols {
+		wanted[s] = true
+	}
+
+	all := make([]FundingCandidate, 0, len(premiums))
+	for i := range premiums {
+		p := &premiums[i]
+		if len(wanted) > 0 && !wanted[p.Symbol] {
+			continue
+		}
+		if len(wanted) == 0 && !strings.HasSuffix(p.Symbol, "USDT") {
+			continue
+		}
+		if p.IndexPrice == "" || p.LastFundingRate == "" {
+			continue
+		}
+
+		rate := p.FundingRateFloat() * 100
+		basis := p.Basis() * 100
+		all = append(all, FundingCandidate{
+			Symbol:          p.Symbol,
+			FundingRatePct:  rate,
+			BasisPct:        basis,
+			DivergencePct:   rate - basis,
+			Direction:       fundingDirection(rate),
+			NextFundingTime: p.NextFundingTime,
+		})
+	}
+
+	report := &FundingReport{
+		Timestamp:        time.Now().UnixMilli(),
+		Scanned:          len(all),
+		ByFunding:        topBy(all, cfg.TopN, func(c FundingCandidate) float64 { return math.Abs(c.FundingRatePct) }),
+		ByDivergence:     topBy(all, cfg.TopN, func(c FundingCandidate) float64 { return math.Abs(c.DivergencePct) }),
+		minAbsFundingPct: cfg.MinAbsFundingPct,
+	}
+
+	// 入围的交易对补充资金费率历史（两个排名可能重复，每个交易对只查一次）
+	history := make(map[string]FundingCandidate)
+	for _, list := range [][]FundingCandidate{report.ByFunding, report.ByDivergence} {
+		for i := range list {
+			c := &list[i]
+			if enriched, ok := history[c.Symbol]; ok {
+				*c = enriched
+				continue
+			}
+			enrichWithHistory(client, c, cfg.HistoryLimit)
+			history[c.Symbol] = *c
+		}
+	}
+
+	utils.Info("资金费率扫描完成",
+		zap.Int("scanned", report.Scanned),
+		zap.Int("candidates", len(report.Candidates())),
+	)
+
+	return report, nil
+}
+
+// Candidates 满足阈值且方向持续的候选（按资金费率绝对值排序）
+// 要求当前费率绝对值不低于阈值，且最近N次已结算费率多数与当前同号
+func (r *FundingReport) Candidates() []FundingCandidate {
+	var candidates []FundingCandidate
+	for _, c := range r.ByFunding {
+		if math.Abs(c.FundingRatePct) < r.minAbsFundingPct {
+			continue
+		}
+		if c.HistoryCount == 0 || c.SameSignCount*2 <= c.HistoryCount {
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+// enrichWithHistory 补充最近N次资金费率、资金费间隔和年化收益
+func enrichWithHistory(client *binance.Client, c *FundingCandidate, limit int) {
+	c.IntervalHours = defaultFundingIntervalHours
+
+	rates, err := client.GetFundingRateHistory(c.Symbol, limit)
+	if err != nil {
+		utils.Warn("获取资金费率历史失败", zap.String("symbol", c.Symbol), zap.Error(err))
+	} else if len(rates) > 0 {
+		sum := 0.0
+		for i := range rates {
+			rate := rates[i].RateFloat() * 100
+			sum += rate
+			if rate != 0 && (rate > 0) == (c.FundingRatePct > 0) {
+				c.SameSignCount++
+			}
+		}
+		c.HistoryCount = len(rates)
+		c.AvgFundingPct = sum / float64(len(rates))
+
+		// 币安按时间升序返回，用最近两次结算时间推断资金费间隔（部分交易对为4小时或1小时）
+		if n := len(rates); n >= 2 {
+			if gap := rates[n-1].FundingTime - rates[n-2].FundingTime; gap > 0 {
+				c.IntervalHours = math.Round(float64(gap) / float64(time.Hour/time.Millisecond))
+			}
+		}
+	}
+
+	c.AnnualizedPct = c.FundingRatePct * (24 / c.IntervalHours) * 365
+}
+
+// fundingDirection 收取资金费的方向
+func fundingDirection(ratePct float64) string {
+	if ratePct < 0 {
+		return DirectionLong
+	}
+	return DirectionShort
+}
+
+// topBy 按score从大到小排序，取前n个（返回副本，不修改原切片）
+func topBy(all []FundingCandidate, n int, score func(FundingCandidate) float64) []FundingCandidate {
+	sorted := make([]FundingCandidate, len(all))
+	copy(sorted, all)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return score(sorted[i]) > score(sorted[j])
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
@@ -0,0 +1,156 @@
+/*
+Package scanner 拉盘/砸盘筛选（交易对池中短时间大幅涨跌且持仓量扩张的交易对）
+
+主要功能：
+- NewPumpDumpScreener(cfg PumpDumpConfig) *PumpDumpScreener                           // 创建拉盘/砸盘筛选
+- (s *PumpDumpScreener) Screen(accountID string, inputs []PumpDumpInput) *PumpDumpReport  // 筛选一个策略周期的交易对并保存报告
+- (s *PumpDumpScreener) Reports() map[string]*PumpDumpReport                         // 各账号最近一次的报告
+
+每个策略周期用已经计算好的指标筛选，不额外请求交易所：主分析周期当前K线涨跌幅绝对值不低于 MinReturnPct，
+且持仓量变化率（优先15分钟）不低于 MinOIChangePct 的交易对视为可能的拉盘（上涨）或砸盘（下跌）。
+价格大幅变化同时持仓量扩张，说明是新开仓推动而不是平仓，更可能是有组织的拉砸。
+按 涨跌幅绝对值 × 持仓量变化率 排序，保留前 TopN 个。
+nil 的 *PumpDumpScreener 可以安全调用，Screen 返回nil。
+*/
+package scanner
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 拉盘/砸盘方向
+const (
+	PumpDumpPump = "pump" // 上涨+持仓量扩张
+	PumpDumpDump = "dump" // 下跌+持仓量扩张
+)
+
+// PumpDumpConfig 拉盘/砸盘筛选配置
+type PumpDumpConfig struct {
+	MinReturnPct   float64 // 主分析周期当前K线涨跌幅绝对值下限（%，默认3）
+	MinOIChangePct float64 // 持仓量变化率下限（%，默认2）
+	TopN           int     // 报告保留前N个（默认10）
+}
+
+// PumpDumpInput 一个交易对本周期的数据
+type PumpDumpInput struct {
+	Symbol      string
+	Timeframe   string  // 主分析周期
+	Price       float64 // 收盘价
+	ReturnPct   float64 // 当前K线涨跌幅（%）
+	OIChangePct float64 // 持仓量变化率（%）
+	FundingRate float64 // 当前资金费率（%）
+}
+
+// PumpDumpEvent 一个可能的拉盘/砸盘
+type PumpDumpEvent struct {
+	Symbol      string  `json:"symbol"`
+	Kind        string  `json:"kind"`          // pump / dump
+	Timeframe   string  `json:"timeframe"`     // 主分析周期
+	Price       float64 `json:"price"`         // 收盘价
+	ReturnPct   float64 `json:"return_pct"`    // 当前K线涨跌幅（%）
+	OIChangePct float64 `json:"oi_change_pct"` // 持仓量变化率（%）
+	FundingRate float64 `json:"funding_rate"`  // 当前资金费率（%）
+	Score       float64 `json:"score"`         // 涨跌幅绝对值 × 持仓量变化率
+}
+
+// PumpDumpReport 一个策略周期的筛选报告
+type PumpDumpReport struct {
+	AccountID string          `json:"account_id"`
+	Timestamp int64           `json:"timestamp"` // 筛选时间（毫秒）
+	Scanned   int             `json:"scanned"`   // 有持仓量变化率的交易对数量
+	Events    []PumpDumpEvent `json:"events"`    // 按得分从高到低
+}
+
+// withDefaults 补全默认值
+func (c PumpDumpConfig) withDefaults() PumpDumpConfig {
+	if c.MinReturnPct == 0 {
+		c.MinReturnPct = 3
+	}
+	if c.MinOIChangePct == 0 {
+		c.MinOIChangePct = 2
+	}
+	if c.TopN == 0 {
+		c.TopN = 10
+	}
+	return c
+}
+
+// PumpDumpScreener 拉盘/砸盘筛选
+type PumpDumpScreener struct {
+	cfg PumpDumpConfig
+
+	mu      sync.RWMutex
+	reports map[string]*PumpDumpReport // accountID -> 最近一次的报告
+}
+
+// NewPumpDumpScreener 创建拉盘/砸盘筛选（多个账号共用，报告按账号保存）
+func NewPumpDumpScreener(cfg PumpDumpConfig) *PumpDumpScreener {
+	return &PumpDumpScreener{
+		cfg:     cfg.withDefaults(),
+		reports: make(map[string]*PumpDumpReport),
+	}
+}
+
+// Screen 筛选一个策略周期的交易对，保存为账号最近一次的报告并返回
+func (s *PumpDumpScreener) Screen(accountID string, inputs []PumpDumpInput) *PumpDumpReport {
+	if s == nil {
+		return nil
+	}
+	report := &PumpDumpReport{
+		AccountID: accountID,
+		Timestamp: time.Now().UnixMilli(),
+		Scanned:   len(inputs),
+		Events:    []PumpDumpEvent{},
+	}
+	for _, in := range inputs {
+		if math.Abs(in.ReturnPct) < s.cfg.MinReturnPct || in.OIChangePct < s.cfg.MinOIChangePct {
+			continue
+		}
+		kind := PumpDumpPump
+		if in.ReturnPct < 0 {
+			kind = PumpDumpDump
+		}
+		report.Events = append(report.Events, PumpDumpEvent{
+			Symbol:      in.Symbol,
+			Kind:        kind,
+			Timeframe:   in.Timeframe,
+			Price:       in.Price,
+			ReturnPct:   round2(in.ReturnPct),
+			OIChangePct: round2(in.OIChangePct),
+			FundingRate: in.FundingRate,
+			Score:       round2(math.Abs(in.ReturnPct) * in.OIChangePct),
+		})
+	}
+	sort.Slice(report.Events, func(i, j int) bool { return report.Events[i].Score > report.Events[j].Score })
+	if len(report.Events) > s.cfg.TopN {
+		report.Events = report.Events[:s.cfg.TopN]
+	}
+
+	s.mu.Lock()
+	s.reports[accountID] = report
+	s.mu.Unlock()
+	return report
+}
+
+// Reports 各账号最近一次的报告
+func (s *PumpDumpScreener) Reports() map[string]*PumpDumpReport {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reports := make(map[string]*PumpDumpReport, len(s.reports))
+	for id, report := range s.reports {
+		reports[id] = report
+	}
+	return reports
+}
+
+// round2 保留2位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,247 @@
+/*
+Package scanner 成交量异动筛选（在交易对池之外寻找临时候选）
+
+主要功能：
+- ScanVolume(client *binance.Client, cfg VolumeConfig) (*VolumeReport, error)  // 扫描一次，返回成交量异动的交易对
+- NewVolumeScreener(client *binance.Client, cfg VolumeConfig) *VolumeScreener  // 创建定时扫描的成交量异动筛选
+- (s *VolumeScreener) Run(ctx context.Context, interval time.Duration)         // 每interval扫描一次，直到ctx取消
+- (s *VolumeScreener) Candidates(limit int) []VolumeSpike                      // 最近一次扫描的候选（按倍数从高到低）
+- (s *VolumeScreener) Report() *VolumeReport                                   // 最近一次扫描的报告
+- (s *VolumeScreener) Lookback() int                                           // 计算平均成交量的K线数
+
+扫描范围：24小时成交额不低于 MinQuoteVolume 的USDT永续合约，按成交额取前 Universe 个（一次请求获取全部24小时行情），
+排除 Exclude 中的交易对。每个交易对获取 Lookback+1 根K线，当前未收盘K线的成交量超过前 Lookback 根平均成交量的
+Multiple 倍时视为异动。当前K线尚未走完，成交量只会继续增加，因此异动在K线走完前就能发现。
+候选只在其K线周期内有效：最近一次扫描超过两个扫描间隔（扫描失败或停止）时不返回候选。
+nil 的 *VolumeScreener 可以安全调用，Candidates 始终为空。
+*/
+package scanner
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// VolumeConfig 成交量异动筛选配置
+type VolumeConfig struct {
+	Interval       string   // K线周期（默认5m）
+	Lookback       int      // 计算平均成交量的已收盘K线数（默认20）
+	Multiple       float64  // 当前K线成交量超过平均的倍数（默认3）
+	Universe       int      // 按24小时成交额取前N个交易对扫描（默认50）
+	MinQuoteVolume float64  // 24小时成交额下限（USDT，默认20000000）
+	Exclude        []string // 排除的交易对
+}
+
+// VolumeSpike 单个交易对的成交量异动
+type VolumeSpike struct {
+	Symbol         string  `json:"symbol"`           // 交易对
+	Interval       string  `json:"interval"`         // K线周期
+	OpenTime       int64   `json:"open_time"`        // 当前K线开盘时间（毫秒）
+	Volume         float64 `json:"volume"`           // 当前K线成交量
+	AvgVolume      float64 `json:"avg_volume"`       // 前N根K线平均成交量
+	Ratio          float64 `json:"ratio"`            // 当前成交量 / 平均成交量
+	ChangePct      float64 `json:"change_pct"`       // 当前K线涨跌幅（%）
+	QuoteVolume24h float64 `json:"quote_volume_24h"` // 24小时成交额（USDT）
+}
+
+// VolumeReport 扫描报告
+type VolumeReport struct {
+	Timestamp int64         `json:"timestamp"` // 扫描时间（毫秒）
+	Scanned   int           `json:"scanned"`   // 扫描的交易对数量
+	Spikes    []VolumeSpike `json:"spikes"`    // 成交量异动的交易对（按倍数从高到低）
+}
+
+// withDefaults 补全默认值
+func (c VolumeConfig) withDefaults() VolumeConfig {
+	if c.Interval == "" {
+		c.Interval = "5m"
+	}
+	if c.Lookback == 0 {
+		c.Lookback = 20
+	}
+	if c.Multiple == 0 {
+		c.Multiple = 3
+	}
+	if c.Universe == 0 {
+		c.Universe = 50
+	}
+	if c.MinQuoteVolume == 0 {
+		c.MinQuoteVolume = 20000000
+	}
+	return c
+}
+
+// ScanVolume 扫描一次，返回成交量异动的交易对
+// 单个交易对K线获取失败时跳过（记录日志），24小时行情获取失败时返回错误
+func ScanVolume(client *binance.Client, cfg VolumeConfig) (*VolumeReport, error) {
+	cfg = cfg.withDefaults()
+
+	tickers, err := client.GetAllTickers24h()
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[string]bool, len(cfg.Exclude))
+	for _, s := range cfg.Exclude {
+		excluded[s] = true
+	}
+	universe := make([]binance.Ticker24h, 0, len(tickers))
+	for _, t := range tickers {
+		if !strings.HasSuffix(t.Symbol, "USDT") || excluded[t.Symbol] || t.QuoteVolumeFloat() < cfg.MinQuoteVolume {
+			continue
+		}
+		universe = append(universe, t)
+	}
+	sort.Slice(universe, func(i, j int) bool { return universe[i].QuoteVolumeFloat() > universe[j].QuoteVolumeFloat() })
+	if len(universe) > cfg.Universe {
+		universe = universe[:cfg.Universe]
+	}
+
+	report := &VolumeReport{Timestamp: time.Now().UnixMilli(), Spikes: []VolumeSpike{}}
+	for i := range universe {
+		t := &universe[i]
+		klines, err := client.GetKlines(t.Symbol, cfg.Interval, cfg.Lookback+1)
+		if err != nil {
+			utils.Warn("成交量异动筛选获取K线失败", zap.String("symbol", t.Symbol), zap.Error(err))
+			continue
+		}
+		report.Scanned++
+		spike, ok := volumeSpike(klines, cfg.Lookback)
+		if !ok || spike.Ratio < cfg.Multiple {
+			continue
+		}
+		spike.Symbol = t.Symbol
+		spike.Interval = cfg.Interval
+		spike.QuoteVolume24h = t.QuoteVolumeFloat()
+		report.Spikes = append(report.Spikes, spike)
+	}
+	sort.Slice(report.Spikes, func(i, j int) bool { return report.Spikes[i].Ratio > report.Spikes[j].Ratio })
+
+	utils.Info("成交量异动筛选完成",
+		zap.Int("scanned", report.Scanned),
+		zap.Int("spikes", len(report.Spikes)),
+	)
+	return report, nil
+}
+
+// volumeSpike 计算最后一根（当前）K线成交量与前lookback根平均成交量之比（K线不足或平均成交量为0时返回false）
+func volumeSpike(klines []binance.Kline, lookback int) (VolumeSpike, bool) {
+	if len(klines) < lookback+1 || lookback <= 0 {
+		return VolumeSpike{}, false
+	}
+	current := klines[len(klines)-1]
+	sum := 0.0
+	for _, k := range klines[len(klines)-1-lookback : len(klines)-1] {
+		v, _ := strconv.ParseFloat(k.Volume, 64)
+		sum += v
+	}
+	avg := sum / float64(lookback)
+	if avg <= 0 {
+		return VolumeSpike{}, false
+	}
+
+	volume, _ := strconv.ParseFloat(current.Volume, 64)
+	open, _ := strconv.ParseFloat(current.Open, 64)
+	price, _ := strconv.ParseFloat(current.Close, 64)
+	spike := VolumeSpike{
+		OpenTime:  current.OpenTime,
+		Volume:    volume,
+		AvgVolume: avg,
+		Ratio:     math.Round(volume/avg*100) / 100,
+	}
+	if open > 0 {
+		spike.ChangePct = math.Round((price-open)/open*100*100) / 100
+	}
+	return spike, true
+}
+
+// VolumeScreener 定时扫描的成交量异动筛选
+type VolumeScreener struct {
+	client *binance.Client
+	cfg    VolumeConfig
+
+	mu       sync.RWMutex
+	report   *VolumeReport
+	maxAge   time.Duration // 最近一次扫描超过该时间时不返回候选
+	lastScan time.Time
+}
+
+// NewVolumeScreener 创建成交量异动筛选（client只使用公开行情接口）
+func NewVolumeScreener(client *binance.Client, cfg VolumeConfig) *VolumeScreener {
+	return &VolumeScreener{
+		client: client,
+		cfg:    cfg.withDefaults(),
+	}
+}
+
+// Run 每interval扫描一次，直到ctx取消（扫描失败时保留上一次结果，超过两个间隔后不再返回候选）
+func (s *VolumeScreener) Run(ctx context.Context, interval time.Duration) {
+	s.mu.Lock()
+	s.maxAge = 2 * interval
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		report, err := ScanVolume(s.client, s.cfg)
+		if err != nil {
+			utils.Warn("成交量异动筛选失败", zap.Error(err))
+		} else {
+			s.mu.Lock()
+			s.report = report
+			s.lastScan = time.Now()
+			s.mu.Unlock()
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Candidates 最近一次扫描的候选（按倍数从高到低，最多limit个，limit<=0时返回全部；扫描结果过旧时为空）
+func (s *VolumeScreener) Candidates(limit int) []VolumeSpike {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.report == nil || (s.maxAge > 0 && time.Since(s.lastScan) > s.maxAge) {
+		return nil
+	}
+	spikes := s.report.Spikes
+	if limit > 0 && len(spikes) > limit {
+		spikes = spikes[:limit]
+	}
+	return append([]VolumeSpike(nil), spikes...)
+}
+
+// Report 最近一次扫描的报告（还没有扫描成功时为nil）
+func (s *VolumeScreener) Report() *VolumeReport {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.report
+}
+
+// Lookback 计算平均成交量的已收盘K线数
+func (s *VolumeScreener) Lookback() int {
+	if s == nil {
+		return 0
+	}
+	return s.cfg.Lookback
+}
@@ -0,0 +1,199 @@
+/*
+Package scheduler 按时间表执行的定时任务
+
+主要功能：
+- Daily(hour, minute int, loc *time.Location) Schedule                          // 每天固定时刻
+- Weekly(weekday time.Weekday, hour, minute int, loc *time.Location) Schedule   // 每周固定时刻
+- At(t time.Time) Schedule                                                       // 只执行一次
+- Offset(s Schedule, d time.Duration) Schedule                                   // 按时间表提前（d为负）或推后执行
+- (w Window) Active(t time.Time, lead time.Duration) bool                        // t 是否在时段内（含开始前的提前量）
+- New() *Scheduler                                                               // 创建调度器
+- (s *Scheduler) Add(name string, schedule Schedule, job func(at time.Time))     // 添加任务
+- (s *Scheduler) Len() int                                                       // 任务数量
+- (s *Scheduler) Run(ctx context.Context, tick time.Duration)                    // 按间隔检查并执行到期的任务，直到ctx取消
+- (s *Scheduler) Jobs() []JobStatus                                              // 任务及下一次执行时间
+
+任务在 Run 所在的goroutine中依次执行，执行时间较长的任务会推迟后面的任务。
+程序未运行期间错过的执行不补做：启动时和每次执行后都从当前时间起算下一次执行时间。
+*/
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// Schedule 时间表：返回 after 之后（不含）的下一次执行时间，零值表示不再执行
+type Schedule func(after time.Time) time.Time
+
+// Daily 每天固定时刻
+func Daily(hour, minute int, loc *time.Location) Schedule {
+	return func(after time.Time) time.Time {
+		t := after.In(loc)
+		next := time.Date(t.Year(), t.Month(), t.Day(), hour, minute, 0, 0, loc)
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	}
+}
+
+// Weekly 每周固定时刻
+func Weekly(weekday time.Weekday, hour, minute int, loc *time.Location) Schedule {
+	daily := Daily(hour, minute, loc)
+	return func(after time.Time) time.Time {
+		next := daily(after)
+		for next.Weekday() != weekday {
+			next = daily(next)
+		}
+		return next
+	}
+}
+
+// At 只在指定时间执行一次
+func At(at time.Time) Schedule {
+	return func(after time.Time) time.Time {
+		if at.After(after) {
+			return at
+		}
+		return time.Time{}
+	}
+}
+
+// Offset 按时间表提前（d为负）或推后执行
+func Offset(s Schedule, d time.Duration) Schedule {
+	return func(after time.Time) time.Time {
+		next := s(after.Add(-d))
+		if next.IsZero() {
+			return next
+		}
+		return next.Add(d)
+	}
+}
+
+// Window 按时间表开始、持续一段时间的时段（如周末、节假日等低流动性时段）
+type Window struct {
+	Name     string        // 名称
+	Start    Schedule      // 开始时间
+	Duration time.Duration // 持续时间
+}
+
+// Active t 是否在时段内，lead 为开始前的提前量（提前量内也视为在时段内）
+func (w Window) Active(t time.Time, lead time.Duration) bool {
+	start := w.Start(t.Add(-w.Duration))
+	return !start.IsZero() && !start.After(t.Add(lead))
+}
+
+// JobStatus 任务状态
+type JobStatus struct {
+	Name    string    `json:"name"`     // 任务名称
+	NextRun time.Time `json:"next_run"` // 下一次执行时间（零值表示不再执行）
+	LastRun time.Time `json:"last_run"` // 最近一次执行时间
+}
+
+// job 定时任务
+type job struct {
+	name     string
+	schedule Schedule
+	run      func(at time.Time)
+	next     time.Time
+	last     time.Time
+}
+
+// Scheduler 定时任务调度器
+type Scheduler struct {
+	mu   sync.Mutex
+	jobs []*job
+}
+
+// New 创建调度器
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add 添加任务（下一次执行时间从当前时间起算）
+func (s *Scheduler) Add(name string, schedule Schedule, run func(at time.Time)) {
+	j := &job{name: name, schedule: schedule, run: run, next: schedule(time.Now())}
+	s.mu.Lock()
+	s.jobs = append(s.jobs, j)
+	s.mu.Unlock()
+
+	utils.Info("添加定时任务", zap.String("name", name), zap.Time("next_run", j.next))
+}
+
+// Len 任务数量
+func (s *Scheduler) Len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.jobs)
+}
+
+// Run 按间隔检查并执行到期的任务，直到ctx取消
+func (s *Scheduler) Run(ctx context.Context, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			s.runDue(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runDue 执行到期的任务
+func (s *Scheduler) runDue(now time.Time) {
+	s.mu.Lock()
+	var due []*job
+	var times []time.Time
+	for _, j := range s.jobs {
+		if !j.next.IsZero() && !j.next.After(now) {
+			due, times = append(due, j), append(times, j.next)
+		}
+	}
+	s.mu.Unlock()
+
+	for i, j := range due {
+		at := times[i]
+		utils.Info("执行定时任务", zap.String("name", j.name), zap.Time("scheduled_at", at))
+		j.run(at)
+
+		s.mu.Lock()
+		j.last, j.next = now, j.schedule(time.Now())
+		s.mu.Unlock()
+	}
+}
+
+// Jobs 任务及下一次执行时间（按下一次执行时间排序，不再执行的排在最后）
+func (s *Scheduler) Jobs() []JobStatus {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	jobs := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, JobStatus{Name: j.name, NextRun: j.next, LastRun: j.last})
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(jobs, func(i, k int) bool {
+		a, b := jobs[i].NextRun, jobs[k].NextRun
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero()
+		}
+		return a.Before(b)
+	})
+	return jobs
+}
@@ -0,0 +1,450 @@
+/*
+Package schemas 指标数据的 Protobuf 编解码（定义见 indicators.proto）
+
+主要功能：
+- MarshalProto(data interface{}) ([]byte, error)                     // 编码为Protobuf（*ShortTermIndicators、*LongTermIndicators、*MarketData）
+- DecodeProto(kind string, raw []byte) (interface{}, error)          // 按类型标识（short_term、long_term）解码为指标结构
+- DecodeMarketDataProto(raw []byte) (*indicators.MarketData, error)  // 解码市场数据
+
+Protobuf 与JSON并存：JSON用于提示词、审计记录和状态API，Protobuf 用于与非Go程序交换快照和紧凑存储（体积约为JSON的一半）。
+解码结果与编码前的结构一致（JSON序列化结果相同）。数据的 schema_version 比当前版本新时解码返回错误，
+旧版本数据不在 Protobuf 中迁移（Protobuf 格式从版本2开始提供）。
+*/
+package schemas
+
+import (
+	"fmt"
+
+	"crypto-ai-trader/indicators"
+)
+
+// MarshalProto 编码为Protobuf（支持 *ShortTermIndicators、*LongTermIndicators、*MarketData）
+func MarshalProto(data interface{}) ([]byte, error) {
+	e := &protoEncoder{}
+	switch d := data.(type) {
+	case *indicators.ShortTermIndicators:
+		e.Int(1, int64(d.SchemaVersion))
+		e.String(2, d.Symbol)
+		e.Int(3, d.Timestamp)
+		e.Message(4, d.MarketData != nil, func(sub *protoEncoder) { encodeMarketData(sub, d.MarketData) })
+		e.Message(5, d.Timeframes != nil, func(sub *protoEncoder) {
+			encodeTimeframes(sub, d.Timeframes.H1, d.Timeframes.M15, d.Timeframes.M5)
+		})
+		e.Message(6, d.Levels != nil, func(sub *protoEncoder) { encodeLevels(sub, d.Levels) })
+	case *indicators.LongTermIndicators:
+		e.Int(1, int64(d.SchemaVersion))
+		e.String(2, d.Symbol)
+		e.Int(3, d.Timestamp)
+		e.Message(4, d.MarketData != nil, func(sub *protoEncoder) { encodeMarketData(sub, d.MarketData) })
+		e.Message(5, d.Timeframes != nil, func(sub *protoEncoder) {
+			encodeTimeframes(sub, d.Timeframes.H4, d.Timeframes.H1, d.Timeframes.M15)
+		})
+		e.Message(6, d.Levels != nil, func(sub *protoEncoder) { encodeLevels(sub, d.Levels) })
+	case *indicators.MarketData:
+		encodeMarketData(e, d)
+	default:
+		return nil, fmt.Errorf("不支持Protobuf编码的指标数据类型: %T", data)
+	}
+	return e.buf, nil
+}
+
+// DecodeProto 按类型标识（indicators.KindShortTerm、indicators.KindLongTerm）解码为指标结构（返回指针）
+func DecodeProto(kind string, raw []byte) (interface{}, error) {
+	var (
+		version    int
+		symbol     string
+		timestamp  int64
+		market     *indicators.MarketData
+		timeframes [3]*indicators.TimeframeData
+		present    bool
+		levels     *indicators.SuggestedLevels
+	)
+	if kind != indicators.KindShortTerm && kind != indicators.KindLongTerm {
+		return nil, fmt.Errorf("不支持Protobuf解码的指标数据类型: %q", kind)
+	}
+	err := decodeProto(raw, func(f protoField) error {
+		var err error
+		switch f.Num {
+		case 1:
+			version = int(f.Int())
+		case 2:
+			symbol = f.String()
+		case 3:
+			timestamp = f.Int()
+		case 4:
+			market, err = DecodeMarketDataProto(f.Bytes)
+		case 5:
+			present = true
+			timeframes, err = decodeTimeframes(f.Bytes)
+		case 6:
+			levels, err = decodeLevels(f.Bytes)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("解析Protobuf指标数据失败: %w", err)
+	}
+	if version > Current() {
+		return nil, fmt.Errorf("指标数据版本 %d 比当前版本 %d 新，请升级程序", version, Current())
+	}
+
+	if kind == indicators.KindShortTerm {
+		data := &indicators.ShortTermIndicators{SchemaVersion: version, Symbol: symbol, Timestamp: timestamp, MarketData: market, Levels: levels}
+		if present {
+			data.Timeframes = &indicators.ShortTermTimeframes{H1: timeframes[0], M15: timeframes[1], M5: timeframes[2]}
+		}
+		return data, nil
+	}
+	data := &indicators.LongTermIndicators{SchemaVersion: version, Symbol: symbol, Timestamp: timestamp, MarketData: market, Levels: levels}
+	if present {
+		data.Timeframes = &indicators.LongTermTimeframes{H4: timeframes[0], H1: timeframes[1], M15: timeframes[2]}
+	}
+	return data, nil
+}
+
+// encodeTimeframes 按从大到小的顺序写入三个时间周期（字段1-3）
+func encodeTimeframes(e *protoEncoder, timeframes ...*indicators.TimeframeData) {
+	for i, tf := range timeframes {
+		e.Message(i+1, tf != nil, func(sub *protoEncoder) { encodeTimeframe(sub, tf) })
+	}
+}
+
+// decodeTimeframes 读取三个时间周期（从大到小）
+func decodeTimeframes(data []byte) ([3]*indicators.TimeframeData, error) {
+	var timeframes [3]*indicators.TimeframeData
+	err := decodeProto(data, func(f protoField) error {
+		if f.Num < 1 || f.Num > len(timeframes) {
+			return nil
+		}
+		tf, err := decodeTimeframe(f.Bytes)
+		timeframes[f.Num-1] = tf
+		return err
+	})
+	return timeframes, err
+}
+
+// encodeTimeframe 写入单个时间周期的指标数据
+func encodeTimeframe(e *protoEncoder, tf *indicators.TimeframeData) {
+	e.Double(1, tf.ClosePrice)
+	e.Double(2, tf.HighPrice)
+	e.Double(3, tf.LowPrice)
+	e.Double(4, tf.OpenPrice)
+	e.OptionalDouble(5, tf.LivePrice)
+
+	e.Double(6, tf.EMA9)
+	e.Double(7, tf.EMA21)
+	e.Double(8, tf.EMA55)
+	if s := tf.EMAState; s != nil {
+		e.Message(9, true, func(sub *protoEncoder) {
+			sub.String(1, s.Order)
+			sub.Double(2, s.EMA21Slope)
+			sub.OptionalInt(3, s.CrossBars)
+			sub.String(4, s.Cross)
+			sub.Double(5, s.EMA55DistATR)
+		})
+	}
+
+	if m := tf.MACD; m != nil {
+		e.Message(10, true, func(sub *protoEncoder) {
+			sub.Double(1, m.DIF)
+			sub.Double(2, m.DEA)
+			sub.Double(3, m.Histogram)
+		})
+	}
+	e.Double(11, tf.RSI)
+
+	if bb := tf.BB; bb != nil {
+		e.Message(12, true, func(sub *protoEncoder) {
+			sub.Double(1, bb.Upper)
+			sub.Double(2, bb.Middle)
+			sub.Double(3, bb.Lower)
+		})
+	}
+	e.Double(13, tf.ATR)
+	e.Double(14, tf.ATRPct)
+	e.Double(15, tf.BBWidthPct)
+	e.Bool(16, tf.BBSqueeze)
+
+	e.Double(17, tf.Volume)
+	e.Double(18, tf.QuoteVolume)
+	e.OptionalDouble(19, tf.TakerBuyPct)
+	e.OptionalDouble(20, tf.VolumeRatio)
+
+	e.OptionalDouble(21, tf.ADX)
+	e.OptionalDouble(22, tf.VWAP)
+	if s := tf.StochRSI; s != nil {
+		e.Message(23, true, func(sub *protoEncoder) {
+			sub.Double(1, s.K)
+			sub.Double(2, s.D)
+		})
+	}
+	if ich := tf.Ichimoku; ich != nil {
+		e.Message(24, true, func(sub *protoEncoder) {
+			sub.Double(1, ich.TenkanSen)
+			sub.Double(2, ich.KijunSen)
+			sub.Double(3, ich.SenkouSpanA)
+			sub.Double(4, ich.SenkouSpanB)
+			sub.Double(5, ich.ChikouSpan)
+		})
+	}
+	e.OptionalDouble(25, tf.CVD)
+}
+
+// decodeTimeframe 读取单个时间周期的指标数据
+func decodeTimeframe(data []byte) (*indicators.TimeframeData, error) {
+	tf := &indicators.TimeframeData{}
+	err := decodeProto(data, func(f protoField) error {
+		switch f.Num {
+		case 1:
+			tf.ClosePrice = f.Double()
+		case 2:
+			tf.HighPrice = f.Double()
+		case 3:
+			tf.LowPrice = f.Double()
+		case 4:
+			tf.OpenPrice = f.Double()
+		case 5:
+			tf.LivePrice = float64Ptr(f.Double())
+		case 6:
+			tf.EMA9 = f.Double()
+		case 7:
+			tf.EMA21 = f.Double()
+		case 8:
+			tf.EMA55 = f.Double()
+		case 9:
+			tf.EMAState = &indicators.EMAStateData{}
+			return decodeProto(f.Bytes, func(f protoField) error {
+				switch f.Num {
+				case 1:
+					tf.EMAState.Order = f.String()
+				case 2:
+					tf.EMAState.EMA21Slope = f.Double()
+				case 3:
+					bars := int(int32(f.Int()))
+					tf.EMAState.CrossBars = &bars
+				case 4:
+					tf.EMAState.Cross = f.String()
+				case 5:
+					tf.EMAState.EMA55DistATR = f.Double()
+				}
+				return nil
+			})
+		case 10:
+			tf.MACD = &indicators.MACDData{}
+			return decodeDoubles(f.Bytes, &tf.MACD.DIF, &tf.MACD.DEA, &tf.MACD.Histogram)
+		case 11:
+			tf.RSI = f.Double()
+		case 12:
+			tf.BB = &indicators.BBData{}
+			return decodeDoubles(f.Bytes, &tf.BB.Upper, &tf.BB.Middle, &tf.BB.Lower)
+		case 13:
+			tf.ATR = f.Double()
+		case 14:
+			tf.ATRPct = f.Double()
+		case 15:
+			tf.BBWidthPct = f.Double()
+		case 16:
+			tf.BBSqueeze = f.Bool()
+		case 17:
+			tf.Volume = f.Double()
+		case 18:
+			tf.QuoteVolume = f.Double()
+		case 19:
+			tf.TakerBuyPct = float64Ptr(f.Double())
+		case 20:
+			tf.VolumeRatio = float64Ptr(f.Double())
+		case 21:
+			tf.ADX = float64Ptr(f.Double())
+		case 22:
+			tf.VWAP = float64Ptr(f.Double())
+		case 23:
+			tf.StochRSI = &indicators.StochRSIData{}
+			return decodeDoubles(f.Bytes, &tf.StochRSI.K, &tf.StochRSI.D)
+		case 24:
+			ich := &indicators.IchimokuData{}
+			tf.Ichimoku = ich
+			return decodeDoubles(f.Bytes, &ich.TenkanSen, &ich.KijunSen, &ich.SenkouSpanA, &ich.SenkouSpanB, &ich.ChikouSpan)
+		case 25:
+			tf.CVD = float64Ptr(f.Double())
+		}
+		return nil
+	})
+	return tf, err
+}
+
+// encodeMarketData 写入市场数据
+func encodeMarketData(e *protoEncoder, m *indicators.MarketData) {
+	e.Double(1, m.OICurrent)
+	e.Doubles(2, m.OIHistory)
+	e.OptionalDouble(3, m.OIChange5m)
+	e.OptionalDouble(4, m.OIChange15m)
+	e.OptionalDouble(5, m.OIChange25m)
+	e.OptionalDouble(6, m.OIChange45m)
+	e.OptionalDouble(7, m.OIChange75m)
+	e.String(8, m.OIPriceState)
+
+	e.Double(9, m.FundingRate)
+	e.Double(10, m.FundingAvg3)
+	e.String(11, m.FundingFlip)
+
+	e.Double(12, m.ATRPct1h)
+	e.OptionalDouble(13, m.ATRPctPercentile)
+	e.String(14, m.VolatilityRegime)
+
+	for _, w := range m.Liquidations {
+		e.Message(15, true, func(sub *protoEncoder) {
+			sub.Int(1, int64(w.WindowMinutes))
+			sub.Double(2, w.LongUSDT)
+			sub.Double(3, w.ShortUSDT)
+			sub.Int(4, int64(w.Count))
+		})
+	}
+	e.Bool(16, m.LiquidationCascade)
+}
+
+// DecodeMarketDataProto 解码市场数据
+func DecodeMarketDataProto(raw []byte) (*indicators.MarketData, error) {
+	m := &indicators.MarketData{}
+	err := decodeProto(raw, func(f protoField) error {
+		switch f.Num {
+		case 1:
+			m.OICurrent = f.Double()
+		case 2:
+			vs, err := f.Doubles()
+			if err != nil {
+				return err
+			}
+			m.OIHistory = append(m.OIHistory, vs...)
+		case 3:
+			m.OIChange5m = float64Ptr(f.Double())
+		case 4:
+			m.OIChange15m = float64Ptr(f.Double())
+		case 5:
+			m.OIChange25m = float64Ptr(f.Double())
+		case 6:
+			m.OIChange45m = float64Ptr(f.Double())
+		case 7:
+			m.OIChange75m = float64Ptr(f.Double())
+		case 8:
+			m.OIPriceState = f.String()
+		case 9:
+			m.FundingRate = f.Double()
+		case 10:
+			m.FundingAvg3 = f.Double()
+		case 11:
+			m.FundingFlip = f.String()
+		case 12:
+			m.ATRPct1h = f.Double()
+		case 13:
+			m.ATRPctPercentile = float64Ptr(f.Double())
+		case 14:
+			m.VolatilityRegime = f.String()
+		case 15:
+			var w indicators.LiquidationWindow
+			err := decodeProto(f.Bytes, func(f protoField) error {
+				switch f.Num {
+				case 1:
+					w.WindowMinutes = int(int32(f.Int()))
+				case 2:
+					w.LongUSDT = f.Double()
+				case 3:
+					w.ShortUSDT = f.Double()
+				case 4:
+					w.Count = int(int32(f.Int()))
+				}
+				return nil
+			})
+			m.Liquidations = append(m.Liquidations, w)
+			return err
+		case 16:
+			m.LiquidationCascade = f.Bool()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("解析Protobuf市场数据失败: %w", err)
+	}
+	return m, nil
+}
+
+// encodeLevels 写入建议止损止盈价位
+func encodeLevels(e *protoEncoder, l *indicators.SuggestedLevels) {
+	e.String(1, l.Timeframe)
+	e.Double(2, l.ATR)
+	e.Double(3, l.ATRPct)
+	e.Message(4, true, func(sub *protoEncoder) {
+		sub.Double(1, l.Params.StopATR)
+		sub.Double(2, l.Params.TargetATR)
+	})
+	for i, side := range []*indicators.TradeLevels{l.Long, l.Short} {
+		e.Message(5+i, side != nil, func(sub *protoEncoder) {
+			sub.String(1, side.Direction)
+			sub.Double(2, side.Entry)
+			sub.Double(3, side.StopLoss)
+			sub.Double(4, side.TakeProfit)
+			sub.Double(5, side.RiskPerUnit)
+			sub.Double(6, side.RMultiple)
+			sub.Double(7, side.Quantity)
+		})
+	}
+}
+
+// decodeLevels 读取建议止损止盈价位
+func decodeLevels(data []byte) (*indicators.SuggestedLevels, error) {
+	l := &indicators.SuggestedLevels{}
+	err := decodeProto(data, func(f protoField) error {
+		switch f.Num {
+		case 1:
+			l.Timeframe = f.String()
+		case 2:
+			l.ATR = f.Double()
+		case 3:
+			l.ATRPct = f.Double()
+		case 4:
+			return decodeDoubles(f.Bytes, &l.Params.StopATR, &l.Params.TargetATR)
+		case 5, 6:
+			side := &indicators.TradeLevels{}
+			if f.Num == 5 {
+				l.Long = side
+			} else {
+				l.Short = side
+			}
+			return decodeProto(f.Bytes, func(f protoField) error {
+				switch f.Num {
+				case 1:
+					side.Direction = f.String()
+				case 2:
+					side.Entry = f.Double()
+				case 3:
+					side.StopLoss = f.Double()
+				case 4:
+					side.TakeProfit = f.Double()
+				case 5:
+					side.RiskPerUnit = f.Double()
+				case 6:
+					side.RMultiple = f.Double()
+				case 7:
+					side.Quantity = f.Double()
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return l, err
+}
+
+// decodeDoubles 读取只有 double 字段的消息，字段编号从1开始依次对应 targets
+func decodeDoubles(data []byte, targets ...*float64) error {
+	return decodeProto(data, func(f protoField) error {
+		if f.Num >= 1 && f.Num <= len(targets) {
+			*targets[f.Num-1] = f.Double()
+		}
+		return nil
+	})
+}
+
+// float64Ptr 返回值的指针
+func float64Ptr(v float64) *float64 {
+	return &v
+}
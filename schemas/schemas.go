@@ -0,0 +1,116 @@
+/*
+Package schemas 指标数据结构版本与迁移
+
+主要功能：
+- Current() int                                         // 当前的数据结构版本（indicators.SchemaVersion）
+- Version(raw []byte) (int, error)                      // 指标数据JSON的结构版本（没有 schema_version 字段为版本1）
+- Migrate(raw []byte) ([]byte, error)                   // 把旧版本的指标数据JSON逐版本迁移到当前版本
+- Decode(kind string, raw []byte) (interface{}, error)  // 迁移到当前版本后按类型标识还原为指标结构
+- History() []Change                                    // 各版本的字段变更记录
+
+指标数据的JSON会发给AI、写入审计记录（回放时还原）并通过状态API提供给外部程序，字段变化时：
+1. indicators.SchemaVersion 加1（新数据的 schema_version 随之变化，外部程序据此判断）
+2. 在 history 中登记该版本新增、修改、删除的字段
+3. 在 migrations 中加入从上一版本迁移的函数，迁移需要读取的旧字段在该版本的结构中冻结（如 v1.go 的 TimeframeV1）
+4. 同步修改 indicators.proto 和 proto.go（Protobuf 格式，新字段使用新的字段编号）
+保存的旧版本数据读取时逐版本迁移；比当前版本新的数据（新版本程序写入、旧版本程序读取）返回错误，不会静默丢弃不认识的字段。
+*/
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"crypto-ai-trader/indicators"
+)
+
+// Change 一个数据结构版本的字段变更
+type Change struct {
+	Version int      `json:"version"`           // 版本号
+	Added   []string `json:"added,omitempty"`   // 新增字段（JSON字段名，单周期字段在 timeframes.* 下）
+	Changed []string `json:"changed,omitempty"` // 含义或格式有变化的字段
+	Removed []string `json:"removed,omitempty"` // 删除的字段
+	Note    string   `json:"note,omitempty"`    // 说明
+}
+
+// history 各版本的字段变更记录（按版本号从小到大）
+var history = []Change{
+	{
+		Version: 1,
+		Note:    "没有 schema_version 字段的数据；atr_pct、bb_width_pct 等字段可能缺失",
+	},
+	{
+		Version: 2,
+		Added:   []string{"schema_version"},
+		Changed: []string{"timeframes.*.atr_pct", "timeframes.*.bb_width_pct"},
+		Note:    "atr_pct、bb_width_pct 保证存在，迁移时按 atr/close_price 和布林带上下轨计算缺失的值",
+	},
+}
+
+// migrations 从版本 N 迁移到 N+1 的函数（修改JSON对象）
+var migrations = map[int]func(doc map[string]json.RawMessage) error{
+	1: migrateV1,
+}
+
+// Current 当前的数据结构版本
+func Current() int {
+	return indicators.SchemaVersion
+}
+
+// History 各版本的字段变更记录（按版本号从小到大）
+func History() []Change {
+	return append([]Change(nil), history...)
+}
+
+// Version 指标数据JSON的结构版本（没有 schema_version 字段为版本1）
+func Version(raw []byte) (int, error) {
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return 0, fmt.Errorf("解析指标数据版本失败: %w", err)
+	}
+	if header.SchemaVersion == 0 {
+		return 1, nil
+	}
+	return header.SchemaVersion, nil
+}
+
+// Migrate 把旧版本的指标数据JSON逐版本迁移到当前版本（已是当前版本时原样返回）
+func Migrate(raw []byte) ([]byte, error) {
+	version, err := Version(raw)
+	if err != nil {
+		return nil, err
+	}
+	if version == Current() {
+		return raw, nil
+	}
+	if version > Current() {
+		return nil, fmt.Errorf("指标数据版本 %d 比当前版本 %d 新，请升级程序", version, Current())
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("解析指标数据失败: %w", err)
+	}
+	for ; version < Current(); version++ {
+		migrate, ok := migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("缺少从版本 %d 迁移的函数", version)
+		}
+		if err := migrate(doc); err != nil {
+			return nil, fmt.Errorf("指标数据从版本 %d 迁移失败: %w", version, err)
+		}
+		doc["schema_version"] = json.RawMessage(fmt.Sprint(version + 1))
+	}
+	return json.Marshal(doc)
+}
+
+// Decode 迁移到当前版本后按类型标识还原为指标结构（返回指针）
+func Decode(kind string, raw []byte) (interface{}, error) {
+	migrated, err := Migrate(raw)
+	if err != nil {
+		return nil, err
+	}
+	return indicators.Decode(kind, migrated)
+}
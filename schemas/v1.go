@@ -0,0 +1,75 @@
+/*
+Package schemas 版本1的指标数据结构与迁移到版本2
+
+主要功能：
+- TimeframeV1  // 版本1单周期指标中迁移需要的字段（冻结，不随 indicators.TimeframeData 变化）
+- BBV1         // 版本1的布林带
+
+版本1的数据没有 schema_version 字段，早期的数据没有 atr_pct、bb_width_pct。
+迁移按保存的 atr、close_price 和布林带计算缺失的值；保存的价格已经取整，计算结果与当时直接计算的值可能有微小差别。
+*/
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// TimeframeV1 版本1单周期指标中迁移需要的字段
+type TimeframeV1 struct {
+	ClosePrice float64 `json:"close_price"`  // 收盘价
+	ATR        float64 `json:"atr"`          // ATR(14)
+	BB         *BBV1   `json:"bb"`           // 布林带(20, 2)
+	ATRPct     float64 `json:"atr_pct"`      // ATR占收盘价的百分比（可能缺失）
+	BBWidthPct float64 `json:"bb_width_pct"` // 布林带宽度占中轨的百分比（可能缺失）
+}
+
+// BBV1 版本1的布林带
+type BBV1 struct {
+	Upper  float64 `json:"upper"`
+	Middle float64 `json:"middle"`
+	Lower  float64 `json:"lower"`
+}
+
+// migrateV1 版本1迁移到版本2：补齐各周期缺失的 atr_pct、bb_width_pct
+func migrateV1(doc map[string]json.RawMessage) error {
+	raw, ok := doc["timeframes"]
+	if !ok || string(raw) == "null" {
+		return nil
+	}
+	var timeframes map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &timeframes); err != nil {
+		return fmt.Errorf("解析时间周期失败: %w", err)
+	}
+
+	for interval, fields := range timeframes {
+		if fields == nil {
+			continue
+		}
+		encoded, _ := json.Marshal(fields)
+		var tf TimeframeV1
+		if err := json.Unmarshal(encoded, &tf); err != nil {
+			return fmt.Errorf("解析 %s 周期失败: %w", interval, err)
+		}
+		if _, ok := fields["atr_pct"]; !ok && tf.ClosePrice > 0 {
+			fields["atr_pct"] = number(math.Round(tf.ATR/tf.ClosePrice*100*10000) / 10000)
+		}
+		if _, ok := fields["bb_width_pct"]; !ok && tf.BB != nil && tf.BB.Middle > 0 {
+			fields["bb_width_pct"] = number(math.Round((tf.BB.Upper-tf.BB.Lower)/tf.BB.Middle*100*100) / 100)
+		}
+	}
+
+	encoded, err := json.Marshal(timeframes)
+	if err != nil {
+		return fmt.Errorf("序列化时间周期失败: %w", err)
+	}
+	doc["timeframes"] = encoded
+	return nil
+}
+
+// number 数值的JSON
+func number(v float64) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
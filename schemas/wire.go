@@ -0,0 +1,195 @@
+/*
+Package schemas Protobuf 编码格式的读写（proto3，只实现指标数据用到的类型）
+
+主要功能：
+- protoEncoder                                                     // 按字段编号写入 varint、double、string、嵌套消息、packed repeated double
+- decodeProto(data []byte, handle func(f protoField) error) error  // 逐个读取字段，交给handle处理
+
+编码规则与 protobuf 官方实现一致：标量为默认值（0、false、空字符串）时不写入，optional 字段（指针）有值时总是写入，
+double 为 fixed64（小端），int32/int64 为 varint（负数按10字节补码），repeated double 使用 packed 编码。
+读取时跳过不认识的字段编号，repeated double 同时接受 packed 和非 packed 两种编码。
+*/
+package schemas
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// protobuf 字段的编码类型
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// protoEncoder Protobuf 消息写入器
+type protoEncoder struct {
+	buf []byte
+}
+
+// tag 写入字段编号和编码类型
+func (e *protoEncoder) tag(field, wire int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field)<<3|uint64(wire))
+}
+
+// Int 写入 int32/int64 字段（为0时不写入）
+func (e *protoEncoder) Int(field int, v int64) {
+	if v != 0 {
+		e.tag(field, wireVarint)
+		e.buf = binary.AppendUvarint(e.buf, uint64(v))
+	}
+}
+
+// OptionalInt 写入 optional int32 字段（为nil时不写入）
+func (e *protoEncoder) OptionalInt(field int, v *int) {
+	if v != nil {
+		e.tag(field, wireVarint)
+		e.buf = binary.AppendUvarint(e.buf, uint64(int64(*v)))
+	}
+}
+
+// Bool 写入 bool 字段（为false时不写入）
+func (e *protoEncoder) Bool(field int, v bool) {
+	if v {
+		e.tag(field, wireVarint)
+		e.buf = append(e.buf, 1)
+	}
+}
+
+// Double 写入 double 字段（为0时不写入）
+func (e *protoEncoder) Double(field int, v float64) {
+	if v != 0 {
+		e.tag(field, wireFixed64)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+	}
+}
+
+// OptionalDouble 写入 optional double 字段（为nil时不写入）
+func (e *protoEncoder) OptionalDouble(field int, v *float64) {
+	if v != nil {
+		e.tag(field, wireFixed64)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(*v))
+	}
+}
+
+// String 写入 string 字段（为空时不写入）
+func (e *protoEncoder) String(field int, v string) {
+	if v != "" {
+		e.tag(field, wireBytes)
+		e.buf = binary.AppendUvarint(e.buf, uint64(len(v)))
+		e.buf = append(e.buf, v...)
+	}
+}
+
+// Doubles 写入 packed repeated double 字段（为空时不写入）
+func (e *protoEncoder) Doubles(field int, vs []float64) {
+	if len(vs) == 0 {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(vs)*8))
+	for _, v := range vs {
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+	}
+}
+
+// Message 写入嵌套消息字段（present为false时不写入，对应nil）
+func (e *protoEncoder) Message(field int, present bool, encode func(sub *protoEncoder)) {
+	if !present {
+		return
+	}
+	sub := &protoEncoder{}
+	encode(sub)
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(sub.buf)))
+	e.buf = append(e.buf, sub.buf...)
+}
+
+// protoField 读取到的一个字段
+type protoField struct {
+	Num   int    // 字段编号
+	Wire  int    // 编码类型
+	Value uint64 // varint、fixed64、fixed32 的值
+	Bytes []byte // 长度前缀类型的内容（字符串、嵌套消息、packed 数组）
+}
+
+// Int int32/int64 字段的值
+func (f protoField) Int() int64 {
+	return int64(f.Value)
+}
+
+// Bool bool 字段的值
+func (f protoField) Bool() bool {
+	return f.Value != 0
+}
+
+// Double double 字段的值
+func (f protoField) Double() float64 {
+	return math.Float64frombits(f.Value)
+}
+
+// String string 字段的值
+func (f protoField) String() string {
+	return string(f.Bytes)
+}
+
+// Doubles repeated double 字段的值（packed 编码为全部元素，非 packed 编码为单个元素）
+func (f protoField) Doubles() ([]float64, error) {
+	if f.Wire == wireFixed64 {
+		return []float64{f.Double()}, nil
+	}
+	if len(f.Bytes)%8 != 0 {
+		return nil, fmt.Errorf("字段 %d 的packed double长度无效: %d", f.Num, len(f.Bytes))
+	}
+	vs := make([]float64, len(f.Bytes)/8)
+	for i := range vs {
+		vs[i] = math.Float64frombits(binary.LittleEndian.Uint64(f.Bytes[i*8:]))
+	}
+	return vs, nil
+}
+
+// decodeProto 逐个读取消息中的字段，交给handle处理
+func decodeProto(data []byte, handle func(f protoField) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("读取字段编号失败")
+		}
+		data = data[n:]
+		f := protoField{Num: int(key >> 3), Wire: int(key & 7)}
+
+		switch f.Wire {
+		case wireVarint:
+			if f.Value, n = binary.Uvarint(data); n <= 0 {
+				return fmt.Errorf("读取字段 %d 的varint失败", f.Num)
+			}
+			data = data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return fmt.Errorf("字段 %d 的fixed64数据不完整", f.Num)
+			}
+			f.Value, data = binary.LittleEndian.Uint64(data), data[8:]
+		case wireFixed32:
+			if len(data) < 4 {
+				return fmt.Errorf("字段 %d 的fixed32数据不完整", f.Num)
+			}
+			f.Value, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		case wireBytes:
+			size, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < size {
+				return fmt.Errorf("字段 %d 的长度无效", f.Num)
+			}
+			f.Bytes, data = data[n:n+int(size)], data[n+int(size):]
+		default:
+			return fmt.Errorf("字段 %d 的编码类型不支持: %d", f.Num, f.Wire)
+		}
+
+		if err := handle(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+/*
+Package secrets 密钥来源（账号配置只写密钥名称，启动时从环境变量、密钥文件或 HashiCorp Vault 读取）
+
+主要功能：
+- NewResolver(cfg config.SecretsConfig) *Resolver                 // 创建密钥解析器（注册 env、file、vault 三种来源）
+- (r *Resolver) Register(scheme string, p Provider)               // 注册或替换密钥来源
+- (r *Resolver) Resolve(value string) (string, error)             // 解析一个配置值（不是密钥引用时原样返回）
+- (r *Resolver) ResolveAccounts(accounts []config.Account) error  // 解析账号的 api_key、api_secret、passphrase
+- NewEnv() Provider                                               // 环境变量
+- NewFile(path string) Provider                                   // 密钥文件（YAML，名称: 值）
+- NewVault(cfg config.VaultConfig) Provider                       // HashiCorp Vault（KV v2）
+
+引用格式（前缀之外的部分为密钥名称）：
+- env:BINANCE_API_KEY_1           环境变量
+- file:account_1_api_key          密钥文件中的键
+- vault:trading/account_1#api_key Vault KV v2 路径和字段
+
+不带以上前缀的值视为密钥本身，原有直接写密钥的配置不受影响。
+错误信息只包含密钥名称，不包含密钥值。
+*/
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"crypto-ai-trader/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Provider 密钥来源
+type Provider interface {
+	// Get 按名称读取密钥（不存在或为空时返回错误）
+	Get(name string) (string, error)
+}
+
+// Resolver 密钥解析器（按引用前缀选择来源）
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver 创建密钥解析器（注册 env、file、vault 三种来源）
+func NewResolver(cfg config.SecretsConfig) *Resolver {
+	r := &Resolver{providers: make(map[string]Provider)}
+	r.Register("env", NewEnv())
+	r.Register("file", NewFile(cfg.File))
+	r.Register("vault", NewVault(cfg.Vault))
+	return r
+}
+
+// Register 注册或替换密钥来源
+func (r *Resolver) Register(scheme string, p Provider) {
+	r.providers[scheme] = p
+}
+
+// Resolve 解析一个配置值（不是密钥引用时原样返回）
+func (r *Resolver) Resolve(value string) (string, error) {
+	scheme, name, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, nil
+	}
+	p, ok := r.providers[scheme]
+	if !ok {
+		return value, nil
+	}
+	if name == "" {
+		return "", fmt.Errorf("密钥引用缺少名称: %s:", scheme)
+	}
+	secret, err := p.Get(name)
+	if err != nil {
+		return "", fmt.Errorf("读取密钥 %s:%s 失败: %w", scheme, name, err)
+	}
+	return secret, nil
+}
+
+// ResolveAccounts 解析账号的 api_key、api_secret、passphrase（原地替换为密钥值）
+func (r *Resolver) ResolveAccounts(accounts []config.Account) error {
+	for i := range accounts {
+		acc := &accounts[i]
+		for _, field := range []struct {
+			name  string
+			value *string
+		}{
+			{"api_key", &acc.APIKey},
+			{"api_secret", &acc.APISecret},
+			{"passphrase", &acc.Passphrase},
+		} {
+			secret, err := r.Resolve(*field.value)
+			if err != nil {
+				return fmt.Errorf("账号[%s]的%s: %w", acc.ID, field.name, err)
+			}
+			*field.value = secret
+		}
+	}
+	return nil
+}
+
+// env 环境变量
+type env struct{}
+
+// NewEnv 环境变量
+func NewEnv() Provider {
+	return env{}
+}
+
+// Get 读取环境变量
+func (env) Get(name string) (string, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return "", fmt.Errorf("环境变量未设置或为空")
+	}
+	return value, nil
+}
+
+// file 密钥文件（第一次使用时读取，之后使用缓存）
+type file struct {
+	path string
+
+	once    sync.Once
+	secrets map[string]string
+	err     error
+}
+
+// NewFile 密钥文件（YAML，名称: 值）
+func NewFile(path string) Provider {
+	return &file{path: path}
+}
+
+// Get 读取密钥文件中的键
+func (f *file) Get(name string) (string, error) {
+	if f.path == "" {
+		return "", fmt.Errorf("未配置密钥文件（secrets.file）")
+	}
+	f.once.Do(f.load)
+	if f.err != nil {
+		return "", f.err
+	}
+	value := f.secrets[name]
+	if value == "" {
+		return "", fmt.Errorf("密钥文件 %s 中没有该键", f.path)
+	}
+	return value, nil
+}
+
+// load 读取密钥文件
+func (f *file) load() {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		f.err = fmt.Errorf("读取密钥文件失败: %w", err)
+		return
+	}
+	if err := yaml.Unmarshal(data, &f.secrets); err != nil {
+		// yaml的错误信息可能包含文件内容，只保留文件路径
+		f.err = fmt.Errorf("解析密钥文件 %s 失败", f.path)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Package secrets HashiCorp Vault 密钥来源（KV v2）
+
+主要功能：
+- NewVault(cfg config.VaultConfig) Provider    // 创建Vault密钥来源
+- (v *vault) Get(name string) (string, error)  // 读取 路径#字段（如 trading/account_1#api_key）
+
+请求 GET <address>/v1/<mount>/data/<路径>，请求头 X-Vault-Token（及可选的 X-Vault-Namespace），
+取返回的 data.data.<字段>。同一路径只请求一次（一个账号的 api_key 和 api_secret 通常在同一路径下）。
+*/
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/config"
+)
+
+// vault HashiCorp Vault（KV v2）
+type vault struct {
+	cfg        config.VaultConfig
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]map[string]interface{} // 路径 -> 字段
+}
+
+// vaultResponse KV v2 读取返回
+type vaultResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// NewVault 创建Vault密钥来源
+func NewVault(cfg config.VaultConfig) Provider {
+	return &vault{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: time.Duration(cfg.TimeoutSec) * time.Second},
+		cache:      make(map[string]map[string]interface{}),
+	}
+}
+
+// Get 读取 路径#字段
+func (v *vault) Get(name string) (string, error) {
+	path, field, ok := strings.Cut(name, "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("Vault密钥名称格式应为 路径#字段")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	data, ok := v.cache[path]
+	if !ok {
+		var err error
+		if data, err = v.read(path); err != nil {
+			return "", err
+		}
+		v.cache[path] = data
+	}
+	value, _ := data[field].(string)
+	if value == "" {
+		return "", fmt.Errorf("Vault路径 %s 中没有字段 %s", path, field)
+	}
+	return value, nil
+}
+
+// read 读取一个路径下的全部字段
+func (v *vault) read(path string) (map[string]interface{}, error) {
+	if v.cfg.Address == "" || v.cfg.Token == "" {
+		return nil, fmt.Errorf("未配置Vault地址或令牌（secrets.vault 或环境变量 VAULT_ADDR、VAULT_TOKEN）")
+	}
+
+	url := strings.TrimRight(v.cfg.Address, "/") + "/v1/" + strings.Trim(v.cfg.Mount, "/") + "/data/" + strings.Trim(path, "/")
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.cfg.Token)
+	if v.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.cfg.Namespace)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求Vault失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var r vaultResponse
+	decodeErr := json.NewDecoder(resp.Body).Decode(&r)
+	if resp.StatusCode != http.StatusOK {
+		// 错误返回不一定是JSON（如路径不存在、代理返回的页面），只保留状态码和Vault给出的错误
+		msg := strings.Join(r.Errors, "; ")
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		return nil, fmt.Errorf("Vault返回错误（状态码 %d）: %s", resp.StatusCode, msg)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("解析Vault返回失败: %w", decodeErr)
+	}
+	return r.Data.Data, nil
+}
@@ -0,0 +1,141 @@
+/*
+Package server HTTP服务器（状态API，HTTP + JSON）
+
+主要功能：
+- New(cfg config.APIConfig) *Server                                   // 创建状态API服务
+- (s *Server) HandleJSON(method, path string, handler JSONHandler)      // 注册返回JSON的接口
+- (s *Server) Run(ctx context.Context) error                            // 启动服务，ctx取消时优雅关闭
+
+所有接口返回JSON：成功时为处理函数的返回值，失败时为 {"error": "..."}。
+配置了token时，请求需带上 Authorization: Bearer <token> 请求头（按固定时间比较）。
+未配置token时只注册GET接口，修改状态的接口（紧急平仓、停用账号、密钥轮换、交易对增删、熔断恢复等）不注册，请求返回404。
+*/
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// JSONHandler 返回JSON数据的处理函数
+type JSONHandler func(r *http.Request) (interface{}, error)
+
+// Error 带HTTP状态码的错误（处理函数返回其他错误时按500处理）
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// BadRequest 请求参数错误
+func BadRequest(format string, args ...interface{}) error {
+	return &Error{Status: http.StatusBadRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+// NotFound 资源不存在
+func NotFound(format string, args ...interface{}) error {
+	return &Error{Status: http.StatusNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// Server 状态API服务
+type Server struct {
+	listen  string
+	token   string
+	mux     *http.ServeMux
+	skipped []string // 未配置token而未注册的修改状态接口
+}
+
+// New 创建状态API服务
+func New(cfg config.APIConfig) *Server {
+	return &Server{
+		listen: cfg.Listen,
+		token:  cfg.Token,
+		mux:    http.NewServeMux(),
+	}
+}
+
+// HandleJSON 注册返回JSON的接口
+// method: HTTP方法（GET、POST），非GET接口视为修改状态的接口，未配置token时不注册
+// path: 路径（如 /api/status，支持Go 1.22路由模式中的 {name} 路径参数）
+func (s *Server) HandleJSON(method, path string, handler JSONHandler) {
+	if method != http.MethodGet && s.token == "" {
+		s.skipped = append(s.skipped, method+" "+path)
+		return
+	}
+
+	s.mux.HandleFunc(method+" "+path, func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "未授权"})
+			return
+		}
+
+		data, err := handler(r)
+		if err != nil {
+			status := http.StatusInternalServerError
+			var apiErr *Error
+			if errors.As(err, &apiErr) {
+				status = apiErr.Status
+			}
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, data)
+	})
+}
+
+// Run 启动服务，ctx取消时优雅关闭
+func (s *Server) Run(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.listen,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	if len(s.skipped) > 0 {
+		utils.Warn("未配置api.token，修改状态的接口未注册", zap.Strings("routes", s.skipped))
+	}
+	utils.Info("状态API已启动", zap.String("listen", s.listen))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("状态API服务异常退出: %w", err)
+	}
+	return nil
+}
+
+// authorized 校验Bearer token（未配置token时不校验，此时只有GET接口）
+func (s *Server) authorized(r *http.Request) bool {
+	if s.token == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+s.token)) == 1
+}
+
+// writeJSON 写入JSON响应
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		utils.Warn("写入API响应失败", zap.Error(err))
+	}
+}
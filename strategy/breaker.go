@@ -0,0 +1,173 @@
+/*
+Package strategy 交易对错误熔断
+
+主要功能：
+- NewSymbolBreaker(accountID string, maxFailures int, backoff, maxBackoff time.Duration) *SymbolBreaker  // 创建账号的交易对错误熔断
+- (b *SymbolBreaker) Allow(symbols []string) []string                                                    // 去掉熔断中的交易对（跳过时间已过的交易对放行探测）
+- (b *SymbolBreaker) Record(symbols []string, data *CycleData) []BreakerEvent                            // 按本周期结果更新连续失败次数，返回熔断、恢复事件
+- (b *SymbolBreaker) Status() []SymbolBreakerState                                                       // 有连续失败记录的交易对
+
+交易对连续 maxFailures 个周期获取K线、计算指标或获取持仓量失败时熔断，跳过 backoff 时间，
+到期后放行一次探测：探测成功恢复正常，探测失败跳过时间翻倍（不超过 maxBackoff）。
+一个交易对出错（下架、接口异常）不再占用每个周期的时间和日志。未启用时为nil，所有方法可以安全调用。
+*/
+package strategy
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// SymbolBreakerState 交易对的熔断状态
+type SymbolBreakerState struct {
+	Symbol    string    `json:"symbol"`
+	Failures  int       `json:"failures"`             // 连续失败的周期数
+	LastError string    `json:"last_error"`           // 最近一次失败原因
+	Open      bool      `json:"open"`                 // 是否熔断中
+	OpenUntil time.Time `json:"open_until,omitempty"` // 熔断到期时间（之后放行探测）
+	Backoff   float64   `json:"backoff_sec"`          // 本次跳过时间（秒）
+}
+
+// BreakerEvent 熔断或恢复事件
+type BreakerEvent struct {
+	Symbol   string
+	Open     bool          // true 为熔断（含探测失败后重新熔断），false 为恢复
+	Probe    bool          // 是否为探测的结果
+	Failures int           // 连续失败的周期数
+	Reason   string        // 最近一次失败原因
+	Backoff  time.Duration // 跳过时间（恢复时为0）
+}
+
+// SymbolBreaker 交易对错误熔断
+type SymbolBreaker struct {
+	accountID   string
+	maxFailures int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+
+	mu     sync.Mutex
+	states map[string]*SymbolBreakerState
+}
+
+// NewSymbolBreaker 创建账号的交易对错误熔断
+func NewSymbolBreaker(accountID string, maxFailures int, backoff, maxBackoff time.Duration) *SymbolBreaker {
+	return &SymbolBreaker{
+		accountID:   accountID,
+		maxFailures: maxFailures,
+		backoff:     backoff,
+		maxBackoff:  maxBackoff,
+		states:      make(map[string]*SymbolBreakerState),
+	}
+}
+
+// Allow 去掉熔断中的交易对（返回新的切片）；跳过时间已过的交易对放行探测，直到记录结果
+func (b *SymbolBreaker) Allow(symbols []string) []string {
+	if b == nil {
+		return symbols
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	allowed := make([]string, 0, len(symbols))
+	var skipped []string
+	for _, symbol := range symbols {
+		st, ok := b.states[symbol]
+		if ok && st.Open && now.Before(st.OpenUntil) {
+			skipped = append(skipped, symbol)
+			continue
+		}
+		allowed = append(allowed, symbol)
+	}
+	if len(skipped) > 0 {
+		utils.Info("交易对熔断中，本周期跳过", zap.String("account_id", b.accountID), zap.Strings("symbols", skipped))
+	}
+	return allowed
+}
+
+// Record 按本周期结果更新连续失败次数：symbols 为本周期请求的交易对，data.Failures 中没有的交易对视为成功
+func (b *SymbolBreaker) Record(symbols []string, data *CycleData) []BreakerEvent {
+	if b == nil || data == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	var events []BreakerEvent
+	for _, symbol := range symbols {
+		st, ok := b.states[symbol]
+		reason, failed := data.Failures[symbol]
+		if !failed {
+			if ok && st.Open {
+				events = append(events, BreakerEvent{Symbol: symbol, Probe: true, Failures: st.Failures, Reason: st.LastError})
+				utils.Info("交易对探测成功，熔断恢复",
+					zap.String("account_id", b.accountID),
+					zap.String("symbol", symbol),
+					zap.Int("failures", st.Failures),
+				)
+			}
+			delete(b.states, symbol)
+			continue
+		}
+
+		if !ok {
+			st = &SymbolBreakerState{Symbol: symbol}
+			b.states[symbol] = st
+		}
+		st.Failures++
+		st.LastError = reason
+
+		var backoff time.Duration
+		probe := st.Open
+		switch {
+		case st.Open:
+			backoff = min(2*time.Duration(st.Backoff*float64(time.Second)), b.maxBackoff)
+		case st.Failures >= b.maxFailures:
+			backoff = b.backoff
+		default:
+			continue
+		}
+		st.Open = true
+		st.OpenUntil = now.Add(backoff)
+		st.Backoff = backoff.Seconds()
+		events = append(events, BreakerEvent{
+			Symbol:   symbol,
+			Open:     true,
+			Probe:    probe,
+			Failures: st.Failures,
+			Reason:   reason,
+			Backoff:  backoff,
+		})
+		utils.Warn("交易对连续失败，熔断跳过",
+			zap.String("account_id", b.accountID),
+			zap.String("symbol", symbol),
+			zap.Int("failures", st.Failures),
+			zap.Bool("probe", probe),
+			zap.String("reason", reason),
+			zap.Duration("backoff", backoff),
+		)
+	}
+	return events
+}
+
+// Status 有连续失败记录的交易对（按交易对排序，返回副本）
+func (b *SymbolBreaker) Status() []SymbolBreakerState {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	states := make([]SymbolBreakerState, 0, len(b.states))
+	for _, st := range b.states {
+		states = append(states, *st)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Symbol < states[j].Symbol })
+	return states
+}
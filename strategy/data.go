@@ -0,0 +1,192 @@
+/*
+Package strategy 策略周期输入数据
+
+主要功能：
+- FetchCycleData(market exchange.MarketData, accountID string, symbols []string, timeframes []string, limit int, oiCacheManager *utils.OICacheManager) *CycleData  // 获取一个周期的K线数据
+- (d *CycleData) GetOICache(symbol string) *indicators.OICache                                                                                                     // 获取指标计算用的OI缓存
+- (d *CycleData) UpdateOI(symbol string, oi float64)                                                                                                               // 更新OI缓存
+- (d *CycleData) DropForming(now time.Time)                                                                                                                        // 去掉各周期未收盘的K线（只用已收盘K线计算指标）
+- (d *CycleData) StampLive(signals []Signal)                                                                                                                       // 把未收盘K线的最新价记入信号的各周期指标
+- (d *CycleData) Fail(symbol, reason string)                                                                                                                       // 记录交易对本周期的失败原因（交易对错误熔断统计）
+- StampInputs(market exchange.MarketData, timeframes []string, signals []Signal)                                                                                   // 记录信号输入数据的获取时间
+*/
+package strategy
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// CycleData 单个周期的输入数据
+type CycleData struct {
+	AccountID      string                                // 账号ID
+	Symbols        []string                              // 本周期成功获取数据的交易对
+	Klines         map[string]map[string][]binance.Kline // symbol -> interval -> K线
+	Market         exchange.MarketData                   // 交易所行情接口（用于获取OI和资金费率）
+	OICacheManager *utils.OICacheManager                 // OI缓存管理器
+	Failures       map[string]string                     // 本周期失败的交易对 -> 失败原因（获取K线、计算指标、获取持仓量）
+	Forming        map[string]map[string]binance.Kline   // 去掉的未收盘K线 symbol -> interval -> K线（见 DropForming）
+}
+
+// FetchCycleData 获取一个周期的K线数据
+// 同一交易对的各周期K线并发获取；任意周期获取失败的交易对会被跳过（与原有逻辑一致）
+func FetchCycleData(market exchange.MarketData, accountID string, symbols []string, timeframes []string, limit int, oiCacheManager *utils.OICacheManager) *CycleData {
+	data := &CycleData{
+		AccountID:      accountID,
+		Symbols:        make([]string, 0, len(symbols)),
+		Klines:         make(map[string]map[string][]binance.Kline, len(symbols)),
+		Market:         market,
+		OICacheManager: oiCacheManager,
+	}
+
+	for _, symbol := range symbols {
+		klinesByInterval, err := fetchSymbolKlines(market, symbol, timeframes, limit)
+		if err != nil {
+			data.Fail(symbol, err.Error())
+			continue
+		}
+
+		data.Symbols = append(data.Symbols, symbol)
+		data.Klines[symbol] = klinesByInterval
+	}
+
+	return data
+}
+
+// fetchSymbolKlines 并发获取一个交易对所有周期的K线（耗时取决于最慢的一个请求）
+// 任意周期获取失败时返回最先失败的请求的错误
+func fetchSymbolKlines(market exchange.MarketData, symbol string, timeframes []string, limit int) (map[string][]binance.Kline, error) {
+	results := make([][]binance.Kline, len(timeframes))
+	var g errgroup.Group
+	for i, interval := range timeframes {
+		g.Go(func() error {
+			klines, err := market.GetKlines(symbol, interval, limit)
+			if err != nil {
+				utils.Error("获取K线失败",
+					zap.String("symbol", symbol),
+					zap.String("interval", interval),
+					zap.Error(err),
+				)
+				return fmt.Errorf("获取%s K线失败: %w", interval, err)
+			}
+			results[i] = klines
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	klinesByInterval := make(map[string][]binance.Kline, len(timeframes))
+	for i, interval := range timeframes {
+		klinesByInterval[interval] = results[i]
+	}
+	return klinesByInterval, nil
+}
+
+// DropForming 去掉各周期未收盘的K线（收盘时间不早于now），保存到 Forming，在策略计算指标前调用
+// 未收盘K线的收盘价、成交量随时间变化，去掉后同一根K线收盘前各周期的指标保持不变
+func (d *CycleData) DropForming(now time.Time) {
+	nowMs := now.UnixMilli()
+	for symbol, klinesByInterval := range d.Klines {
+		for interval, klines := range klinesByInterval {
+			n := len(klines)
+			if n == 0 || klines[n-1].CloseTime < nowMs {
+				continue
+			}
+			if d.Forming == nil {
+				d.Forming = make(map[string]map[string]binance.Kline)
+			}
+			if d.Forming[symbol] == nil {
+				d.Forming[symbol] = make(map[string]binance.Kline)
+			}
+			d.Forming[symbol][interval] = klines[n-1]
+			klinesByInterval[interval] = klines[:n-1]
+		}
+	}
+}
+
+// StampLive 把未收盘K线的最新价记入信号的各周期指标（live_price），策略计算完成后调用
+// 只用已收盘K线计算时 close_price 为最近一根已收盘K线的收盘价，live_price 为当前价格；未调用 DropForming 时不设置
+func (d *CycleData) StampLive(signals []Signal) {
+	for i := range signals {
+		forming, ok := d.Forming[signals[i].Symbol]
+		if !ok {
+			continue
+		}
+		for interval, tf := range indicators.Timeframes(signals[i].Data) {
+			if k, ok := forming[interval]; ok {
+				if price, err := strconv.ParseFloat(k.Close, 64); err == nil {
+					tf.LivePrice = &price
+				}
+			}
+		}
+	}
+}
+
+// GetOICache 获取指标计算用的OI缓存（没有缓存时返回空缓存）
+func (d *CycleData) GetOICache(symbol string) *indicators.OICache {
+	if d.OICacheManager == nil {
+		return &indicators.OICache{Symbol: symbol}
+	}
+
+	oiCache := d.OICacheManager.Get(symbol)
+	if oiCache == nil {
+		return &indicators.OICache{
+			Symbol:     symbol,
+			History:    []float64{},
+			Timestamps: []int64{},
+		}
+	}
+
+	// 转换为indicators.OICache类型
+	return &indicators.OICache{
+		Symbol:     oiCache.Symbol,
+		History:    oiCache.History,
+		Timestamps: oiCache.Timestamps,
+	}
+}
+
+// UpdateOI 更新OI缓存
+func (d *CycleData) UpdateOI(symbol string, oi float64) {
+	if d.OICacheManager == nil {
+		return
+	}
+	d.OICacheManager.Update(symbol, oi, time.Now().Unix())
+}
+
+// Fail 记录交易对本周期的失败原因（同一周期只保留第一个原因）
+func (d *CycleData) Fail(symbol, reason string) {
+	if d.Failures == nil {
+		d.Failures = make(map[string]string)
+	}
+	if _, ok := d.Failures[symbol]; !ok {
+		d.Failures[symbol] = reason
+	}
+}
+
+// inputTimer 记录数据获取时间的行情接口（共享行情数据服务）
+type inputTimer interface {
+	FetchedAt(symbol string, intervals []string) map[string]time.Time
+}
+
+// StampInputs 记录信号输入数据（各周期K线、持仓量、资金费率）的获取时间，策略计算完成后立即调用
+// 缓存命中时为缓存数据的获取时间；行情接口不记录获取时间时不设置（不检查过期）
+func StampInputs(market exchange.MarketData, timeframes []string, signals []Signal) {
+	timer, ok := market.(inputTimer)
+	if !ok {
+		return
+	}
+	for i := range signals {
+		signals[i].FetchedAt = timer.FetchedAt(signals[i].Symbol, timeframes)
+	}
+}
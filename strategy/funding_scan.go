@@ -0,0 +1,163 @@
+/*
+Package strategy 资金费率扫描策略
+
+主要功能：
+- NewFundingScanStrategy() Strategy  // 创建资金费率扫描策略
+
+每个周期按资金费率绝对值、资金费率与基差背离对交易对排名，
+满足阈值且方向持续的交易对作为资金费收割候选信号输出（信号数据为 *scanner.FundingCandidate）。
+只需要溢价指数和资金费率历史，不获取K线；目前只支持币安合约账号。
+
+strategy_params：
+- min_abs_funding_pct: 候选的最低资金费率绝对值（%/期，默认0.05）
+- top_n: 每个排名保留前N个（默认10）
+- history_limit: 资金费率历史条数（默认3）
+- interval_minutes: 运行周期（分钟，默认15）
+- scan_all: 是否扫描全部USDT永续合约（默认false，只扫描交易对池）
+*/
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/scanner"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("funding_scan", NewFundingScanStrategy)
+}
+
+// binanceMarket 可以取得底层币安客户端的行情接口（exchange.Binance）
+type binanceMarket interface {
+	Client() *binance.Client
+}
+
+// FundingScanStrategy 资金费率扫描策略
+type FundingScanStrategy struct {
+	interval time.Duration
+	scanAll  bool
+	cfg      scanner.FundingConfig
+}
+
+// NewFundingScanStrategy 创建资金费率扫描策略
+func NewFundingScanStrategy() Strategy {
+	return &FundingScanStrategy{
+		interval: 15 * time.Minute,
+	}
+}
+
+// Name 策略名称
+func (s *FundingScanStrategy) Name() string {
+	return "funding_scan"
+}
+
+// Init 初始化策略
+func (s *FundingScanStrategy) Init(params map[string]interface{}) error {
+	var err error
+	if s.cfg.MinAbsFundingPct, err = floatParam(params, "min_abs_funding_pct"); err != nil {
+		return err
+	}
+	if s.cfg.TopN, err = intParam(params, "top_n"); err != nil {
+		return err
+	}
+	if s.cfg.HistoryLimit, err = intParam(params, "history_limit"); err != nil {
+		return err
+	}
+	minutes, err := intParam(params, "interval_minutes")
+	if err != nil {
+		return err
+	}
+	if minutes > 0 {
+		s.interval = time.Duration(minutes) * time.Minute
+	}
+	if v, ok := params["scan_all"]; ok {
+		if s.scanAll, ok = v.(bool); !ok {
+			return fmt.Errorf("参数scan_all必须是布尔值: %v", v)
+		}
+	}
+	return nil
+}
+
+// Timeframes 不需要K线
+func (s *FundingScanStrategy) Timeframes() []string {
+	return nil
+}
+
+// Interval 运行周期
+func (s *FundingScanStrategy) Interval() time.Duration {
+	return s.interval
+}
+
+// HoldingTime 预期持仓时间（至少跨过一次资金费结算）
+func (s *FundingScanStrategy) HoldingTime() (time.Duration, time.Duration) {
+	return 8 * time.Hour, 7 * 24 * time.Hour
+}
+
+// OnCycle 扫描资金费率，输出候选信号
+func (s *FundingScanStrategy) OnCycle(ctx context.Context, data *CycleData) []Signal {
+	market, ok := data.Market.(binanceMarket)
+	if !ok || !data.Market.HasDerivativesData() {
+		utils.Warn("资金费率扫描只支持币安合约账号", zap.String("account_id", data.AccountID))
+		return nil
+	}
+
+	symbols := data.Symbols
+	if s.scanAll {
+		symbols = nil
+	}
+
+	report, err := scanner.ScanFunding(market.Client(), symbols, s.cfg)
+	if err != nil {
+		utils.Error("资金费率扫描失败", zap.String("account_id", data.AccountID), zap.Error(err))
+		return nil
+	}
+
+	candidates := report.Candidates()
+	signals := make([]Signal, 0, len(candidates))
+	for i := range candidates {
+		signals = append(signals, Signal{
+			AccountID: data.AccountID,
+			Strategy:  s.Name(),
+			Symbol:    candidates[i].Symbol,
+			Timestamp: report.Timestamp,
+			Data:      &candidates[i],
+		})
+	}
+
+	return signals
+}
+
+// floatParam 读取数字参数（未配置时返回0）
+func floatParam(params map[string]interface{}, key string) (float64, error) {
+	v, ok := params[key]
+	if !ok {
+		return 0, nil
+	}
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	default:
+		return 0, fmt.Errorf("参数%s必须是数字: %v", key, v)
+	}
+}
+
+// intParam 读取整数参数（未配置时返回0）
+func intParam(params map[string]interface{}, key string) (int, error) {
+	v, ok := params[key]
+	if !ok {
+		return 0, nil
+	}
+	n, ok := v.(int)
+	if !ok {
+		return 0, fmt.Errorf("参数%s必须是整数: %v", key, v)
+	}
+	return n, nil
+}
@@ -0,0 +1,358 @@
+/*
+Package strategy 配对交易（统计套利）策略
+
+主要功能：
+- NewPairTradeStrategy() Strategy  // 创建配对交易策略
+
+跟踪配置的交易对组合（如 ETHUSDT/BTCUSDT）的对数价差和Z分数，输出市场中性的多空两腿信号
+（信号数据为 *PairSignal）：
+- 开仓：|z| ≥ entry_z 且收益率相关系数 ≥ min_correlation。z为负做多价差（多A空B），z为正做空价差（空A多B）
+- 止盈：价差回归，|z| ≤ exit_z
+- 止损：价差继续偏离，|z| ≥ stop_z
+- 时间止损：持有超过 max_holding_bars 根K线
+- 关系破裂：持有期间相关系数低于 min_correlation
+
+两腿按名义价值 1 : β 配比（β为对冲比例），两腿作为一个整体开平，任一条件触发时同时平掉两腿。
+持仓状态只保存在策略实例内存中，重启后从空仓开始。
+
+strategy_params：
+- pairs: 交易对组合列表，格式 "A/B"（如 ["ETHUSDT/BTCUSDT"]）
+- timeframe: K线周期（默认1h）
+- lookback: 回看K线数量（默认100）
+- entry_z / exit_z / stop_z: 开仓、止盈、止损Z分数（默认2.0 / 0.5 / 3.5）
+- min_correlation: 最低收益率相关系数（默认0.7）
+- max_holding_bars: 最长持有K线数（默认48，0表示不限）
+*/
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("pair_trade", NewPairTradeStrategy)
+}
+
+// 配对交易动作
+const (
+	PairActionOpen  = "open"  // 开仓（同时开两腿）
+	PairActionClose = "close" // 止盈平仓
+	PairActionStop  = "stop"  // 止损平仓（价差继续偏离、超时或关系破裂）
+)
+
+// 价差方向
+const (
+	PairLongSpread  = "long_spread"  // 做多价差：多A空B
+	PairShortSpread = "short_spread" // 做空价差：空A多B
+)
+
+// PairLeg 配对交易的一条腿
+type PairLeg struct {
+	Symbol string  `json:"symbol"` // 交易对
+	Side   string  `json:"side"`   // BUY 或 SELL（平仓信号为平仓方向）
+	Weight float64 `json:"weight"` // 名义价值占组合的比例（两腿合计为1）
+}
+
+// PairSignal 配对交易信号
+type PairSignal struct {
+	Pair      string                 `json:"pair"`      // 组合名称（A/B）
+	Action    string                 `json:"action"`    // open / close / stop
+	Direction string                 `json:"direction"` // long_spread / short_spread
+	Legs      []PairLeg              `json:"legs"`      // 两腿
+	Spread    *indicators.SpreadData `json:"spread"`    // 价差数据
+	EntryZ    float64                `json:"entry_z"`   // 开仓时的Z分数
+	HeldBars  int                    `json:"held_bars"` // 已持有K线数（平仓信号）
+	Reason    string                 `json:"reason"`    // 触发原因
+}
+
+// pairPosition 组合的持仓状态
+type pairPosition struct {
+	direction  string
+	entryZ     float64
+	hedgeRatio float64
+	openedAt   int64 // 开仓时最新K线的开盘时间
+}
+
+// pairConfig 单个组合
+type pairConfig struct {
+	name    string
+	symbolA string
+	symbolB string
+}
+
+// PairTradeStrategy 配对交易策略
+type PairTradeStrategy struct {
+	pairs          []pairConfig
+	timeframe      string
+	lookback       int
+	entryZ         float64
+	exitZ          float64
+	stopZ          float64
+	minCorrelation float64
+	maxHoldingBars int
+
+	positions map[string]*pairPosition // 组合名称 -> 持仓状态
+	mu        sync.Mutex
+}
+
+// NewPairTradeStrategy 创建配对交易策略
+func NewPairTradeStrategy() Strategy {
+	return &PairTradeStrategy{
+		timeframe:      "1h",
+		lookback:       100,
+		entryZ:         2.0,
+		exitZ:          0.5,
+		stopZ:          3.5,
+		minCorrelation: 0.7,
+		maxHoldingBars: 48,
+		positions:      make(map[string]*pairPosition),
+	}
+}
+
+// Name 策略名称
+func (s *PairTradeStrategy) Name() string {
+	return "pair_trade"
+}
+
+// Init 初始化策略
+func (s *PairTradeStrategy) Init(params map[string]interface{}) error {
+	raw, ok := params["pairs"].([]interface{})
+	if !ok || len(raw) == 0 {
+		return fmt.Errorf("参数pairs不能为空（格式如 [\"ETHUSDT/BTCUSDT\"]）")
+	}
+	for _, item := range raw {
+		name, _ := item.(string)
+		legs := strings.Split(name, "/")
+		if len(legs) != 2 || legs[0] == "" || legs[1] == "" || legs[0] == legs[1] {
+			return fmt.Errorf("交易对组合格式无效: %v（格式为 A/B）", item)
+		}
+		s.pairs = append(s.pairs, pairConfig{name: name, symbolA: legs[0], symbolB: legs[1]})
+	}
+
+	if tf, ok := params["timeframe"].(string); ok && tf != "" {
+		s.timeframe = tf
+	}
+	for key, target := range map[string]*float64{
+		"entry_z":         &s.entryZ,
+		"exit_z":          &s.exitZ,
+		"stop_z":          &s.stopZ,
+		"min_correlation": &s.minCorrelation,
+	} {
+		v, err := floatParam(params, key)
+		if err != nil {
+			return err
+		}
+		if _, set := params[key]; set {
+			*target = v
+		}
+	}
+	for key, target := range map[string]*int{
+		"lookback":         &s.lookback,
+		"max_holding_bars": &s.maxHoldingBars,
+	} {
+		v, err := intParam(params, key)
+		if err != nil {
+			return err
+		}
+		if _, set := params[key]; set {
+			*target = v
+		}
+	}
+
+	if !(s.exitZ >= 0 && s.exitZ < s.entryZ && s.entryZ < s.stopZ) {
+		return fmt.Errorf("Z分数阈值必须满足 0 ≤ exit_z < entry_z < stop_z: %v / %v / %v", s.exitZ, s.entryZ, s.stopZ)
+	}
+	if s.lookback < 20 {
+		return fmt.Errorf("lookback至少为20: %d", s.lookback)
+	}
+	return nil
+}
+
+// Timeframes 组合的两腿不一定在交易对池中，K线在OnCycle中按组合获取
+func (s *PairTradeStrategy) Timeframes() []string {
+	return nil
+}
+
+// Interval 运行周期（与K线周期一致）
+func (s *PairTradeStrategy) Interval() time.Duration {
+	if d := timeframeDuration(s.timeframe); d > 0 {
+		return d
+	}
+	return time.Hour
+}
+
+// HoldingTime 预期持仓时间
+func (s *PairTradeStrategy) HoldingTime() (time.Duration, time.Duration) {
+	return s.Interval(), s.Interval() * time.Duration(max(s.maxHoldingBars, 1))
+}
+
+// OnCycle 计算每个组合的价差，输出开平仓信号
+func (s *PairTradeStrategy) OnCycle(ctx context.Context, data *CycleData) []Signal {
+	var signals []Signal
+	for _, pair := range s.pairs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		klinesA, err := data.Market.GetKlines(pair.symbolA, s.timeframe, s.lookback+1)
+		if err != nil {
+			utils.Error("获取K线失败", zap.String("symbol", pair.symbolA), zap.Error(err))
+			continue
+		}
+		klinesB, err := data.Market.GetKlines(pair.symbolB, s.timeframe, s.lookback+1)
+		if err != nil {
+			utils.Error("获取K线失败", zap.String("symbol", pair.symbolB), zap.Error(err))
+			continue
+		}
+
+		spread := indicators.CalculateSpread(pair.symbolA, pair.symbolB, klinesA, klinesB, s.lookback)
+		if spread == nil {
+			utils.Warn("K线不足，跳过配对", zap.String("pair", pair.name))
+			continue
+		}
+
+		utils.Debug("配对价差",
+			zap.String("pair", pair.name),
+			zap.Float64("z_score", spread.ZScore),
+			zap.Float64("hedge_ratio", spread.HedgeRatio),
+			zap.Float64("correlation", spread.Correlation),
+		)
+
+		if sig := s.evaluate(pair, spread, klinesA[len(klinesA)-1].OpenTime); sig != nil {
+			signals = append(signals, Signal{
+				AccountID: data.AccountID,
+				Strategy:  s.Name(),
+				Symbol:    pair.name,
+				Timestamp: time.Now().UnixMilli(),
+				Data:      sig,
+			})
+		}
+	}
+
+	return signals
+}
+
+// evaluate 根据Z分数和持仓状态生成信号（无动作时返回nil）
+func (s *PairTradeStrategy) evaluate(pair pairConfig, spread *indicators.SpreadData, barTime int64) *PairSignal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pos := s.positions[pair.name]
+	z := spread.ZScore
+
+	// 空仓：检查开仓条件
+	if pos == nil {
+		if math.Abs(z) < s.entryZ || math.Abs(z) >= s.stopZ {
+			return nil
+		}
+		if spread.Correlation < s.minCorrelation || spread.HedgeRatio <= 0 {
+			utils.Info("价差偏离但相关性不足，不开仓",
+				zap.String("pair", pair.name),
+				zap.Float64("z_score", z),
+				zap.Float64("correlation", spread.Correlation),
+			)
+			return nil
+		}
+
+		direction := PairLongSpread
+		if z > 0 {
+			direction = PairShortSpread
+		}
+		s.positions[pair.name] = &pairPosition{
+			direction:  direction,
+			entryZ:     z,
+			hedgeRatio: spread.HedgeRatio,
+			openedAt:   barTime,
+		}
+		return &PairSignal{
+			Pair:      pair.name,
+			Action:    PairActionOpen,
+			Direction: direction,
+			Legs:      pairLegs(pair, direction, spread.HedgeRatio, false),
+			Spread:    spread,
+			EntryZ:    z,
+			Reason:    fmt.Sprintf("|z|=%.2f ≥ %.2f", math.Abs(z), s.entryZ),
+		}
+	}
+
+	// 持仓中：检查平仓条件（方向相反的偏离视为已回归）
+	heldBars := 0
+	if d := timeframeDuration(s.timeframe); d > 0 {
+		heldBars = int((barTime - pos.openedAt) / d.Milliseconds())
+	}
+	adverse := z
+	if pos.direction == PairLongSpread {
+		adverse = -z
+	}
+
+	action, reason := "", ""
+	switch {
+	case adverse >= s.stopZ:
+		action, reason = PairActionStop, fmt.Sprintf("价差继续偏离 |z|=%.2f ≥ %.2f", math.Abs(z), s.stopZ)
+	case adverse <= s.exitZ:
+		action, reason = PairActionClose, fmt.Sprintf("价差回归 z=%.2f", z)
+	case s.maxHoldingBars > 0 && heldBars >= s.maxHoldingBars:
+		action, reason = PairActionStop, fmt.Sprintf("持有%d根K线未回归", heldBars)
+	case spread.Correlation < s.minCorrelation:
+		action, reason = PairActionStop, fmt.Sprintf("相关系数降至%.2f", spread.Correlation)
+	default:
+		return nil
+	}
+
+	delete(s.positions, pair.name)
+	return &PairSignal{
+		Pair:      pair.name,
+		Action:    action,
+		Direction: pos.direction,
+		Legs:      pairLegs(pair, pos.direction, pos.hedgeRatio, true),
+		Spread:    spread,
+		EntryZ:    pos.entryZ,
+		HeldBars:  heldBars,
+		Reason:    reason,
+	}
+}
+
+// pairLegs 两腿的方向和名义价值占比（A : B = 1 : β）
+// closing为true时返回平仓方向
+func pairLegs(pair pairConfig, direction string, hedgeRatio float64, closing bool) []PairLeg {
+	sideA, sideB := "BUY", "SELL"
+	if (direction == PairShortSpread) != closing {
+		sideA, sideB = sideB, sideA
+	}
+	weightA := 1 / (1 + hedgeRatio)
+	return []PairLeg{
+		{Symbol: pair.symbolA, Side: sideA, Weight: math.Round(weightA*10000) / 10000},
+		{Symbol: pair.symbolB, Side: sideB, Weight: math.Round((1-weightA)*10000) / 10000},
+	}
+}
+
+// timeframeDuration K线周期的时长（如1h → 1小时），无法识别时返回0
+func timeframeDuration(tf string) time.Duration {
+	if len(tf) < 2 {
+		return 0
+	}
+	var n int
+	if _, err := fmt.Sscanf(tf[:len(tf)-1], "%d", &n); err != nil {
+		return 0
+	}
+	switch tf[len(tf)-1] {
+	case 'm':
+		return time.Duration(n) * time.Minute
+	case 'h':
+		return time.Duration(n) * time.Hour
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Package strategy 多周期指标策略（短线、中长线、剥头皮、波段）
+
+主要功能：
+- NewProfileStrategy(name string, profile *indicators.Profile, interval, minHold, maxHold time.Duration) Factory  // 创建按指标配置计算的策略
+
+各策略只有指标配置（周期、参数、ATR倍数，见 indicators.Profile）、运行周期和持仓时间不同：
+短线：      持仓30-90分钟，每5分钟运行一次，1h（方向过滤） → 15m（主分析） → 5m（入场）
+中长线：    持仓2-4小时，每15分钟运行一次，4h（大趋势） → 1h（主分析） → 15m（入场）
+剥头皮：    持仓5-20分钟，每1分钟运行一次，15m（方向过滤） → 5m（主分析） → 1m（入场）
+波段：      持仓1-5天，每1小时运行一次，1d（宏观趋势） → 4h（主分析） → 1h（入场）
+*/
+package strategy
+
+import (
+	"context"
+	"time"
+
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("short_term", NewProfileStrategy("short_term", indicators.ShortTermProfile, 5*time.Minute, 30*time.Minute, 90*time.Minute))
+	Register("long_term", NewProfileStrategy("long_term", indicators.LongTermProfile, 15*time.Minute, 2*time.Hour, 4*time.Hour))
+	Register("scalp", NewProfileStrategy("scalp", indicators.ScalpProfile, 1*time.Minute, 5*time.Minute, 20*time.Minute))
+	Register("swing", NewProfileStrategy("swing", indicators.SwingProfile, 1*time.Hour, 24*time.Hour, 5*24*time.Hour))
+}
+
+// ProfileStrategy 按指标配置计算的多周期策略
+type ProfileStrategy struct {
+	name     string
+	profile  *indicators.Profile
+	interval time.Duration
+	minHold  time.Duration
+	maxHold  time.Duration
+}
+
+// NewProfileStrategy 创建按指标配置计算的策略
+// name: 策略注册名称（提示词模板按 <策略>_<提示词类型> 查找）
+// interval: 运行周期
+// minHold, maxHold: 预期持仓时间范围
+func NewProfileStrategy(name string, profile *indicators.Profile, interval, minHold, maxHold time.Duration) Factory {
+	return func() Strategy {
+		return &ProfileStrategy{
+			name:     name,
+			profile:  profile,
+			interval: interval,
+			minHold:  minHold,
+			maxHold:  maxHold,
+		}
+	}
+}
+
+// Name 策略名称
+func (s *ProfileStrategy) Name() string {
+	return s.name
+}
+
+// Init 初始化策略
+func (s *ProfileStrategy) Init(params map[string]interface{}) error {
+	return nil
+}
+
+// Timeframes 需要的K线周期
+func (s *ProfileStrategy) Timeframes() []string {
+	return append([]string(nil), s.profile.Intervals[:]...)
+}
+
+// Interval 运行周期
+func (s *ProfileStrategy) Interval() time.Duration {
+	return s.interval
+}
+
+// HoldingTime 预期持仓时间
+func (s *ProfileStrategy) HoldingTime() (time.Duration, time.Duration) {
+	return s.minHold, s.maxHold
+}
+
+// OnCycle 计算每个交易对的指标
+func (s *ProfileStrategy) OnCycle(ctx context.Context, data *CycleData) []Signal {
+	label := s.profile.Label
+	utils.Info("处理"+label+"策略", zap.String("account_id", data.AccountID), zap.Int("symbols", len(data.Symbols)))
+
+	signals := make([]Signal, 0, len(data.Symbols))
+	for _, symbol := range data.Symbols {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// 计算指标（包含市场数据）
+		result := s.profile.CalculateWithMarket(symbol, data.Klines[symbol], data.Market, data.GetOICache(symbol))
+		if result == nil {
+			utils.Error("计算"+label+"指标失败", zap.String("symbol", symbol))
+			data.Fail(symbol, "计算"+label+"指标失败")
+			continue
+		}
+
+		// 更新OI缓存（有持仓量的市场获取持仓量、资金费率失败时记为失败，指标照常输出）
+		_, _, marketData := indicators.PrimaryTimeframe(result)
+		if marketData != nil {
+			data.UpdateOI(symbol, marketData.OICurrent)
+		} else if data.Market != nil && data.Market.HasDerivativesData() {
+			data.Fail(symbol, "获取持仓量或资金费率失败")
+		}
+
+		signals = append(signals, Signal{
+			AccountID: data.AccountID,
+			Strategy:  s.Name(),
+			Symbol:    symbol,
+			Timestamp: indicators.Timestamp(result),
+			Data:      result,
+		})
+	}
+
+	return signals
+}
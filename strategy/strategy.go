@@ -0,0 +1,99 @@
+/*
+Package strategy 可插拔策略接口与注册表
+
+主要功能：
+- Register(name string, factory Factory)        // 注册策略（按名称）
+- New(name string) (Strategy, error)            // 根据名称创建策略实例
+- Exists(name string) bool                      // 判断策略是否已注册
+- Names() []string                              // 获取所有已注册的策略名称
+
+新增策略只需实现 Strategy 接口并在 init() 中调用 Register，
+然后在 accounts.yml 的 strategy 字段中填写注册名称即可，无需修改 main.go。
+*/
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Strategy 策略接口
+type Strategy interface {
+	// Name 策略注册名称（如 short_term）
+	Name() string
+	// Init 使用账号配置中的 strategy_params 初始化策略
+	Init(params map[string]interface{}) error
+	// Timeframes 策略需要的K线周期（从大到小，如 1h、15m、5m）
+	Timeframes() []string
+	// Interval 策略的运行周期（每隔多久执行一次 OnCycle）
+	Interval() time.Duration
+	// HoldingTime 预期持仓时间范围（最短、最长）
+	HoldingTime() (min, max time.Duration)
+	// OnCycle 每个周期执行一次，根据输入数据生成信号
+	OnCycle(ctx context.Context, data *CycleData) []Signal
+}
+
+// Signal 策略输出的信号（目前为指标数据，后续交给AI分析）
+type Signal struct {
+	AccountID string               `json:"account_id"`           // 账号ID
+	Strategy  string               `json:"strategy"`             // 策略名称
+	Symbol    string               `json:"symbol"`               // 交易对
+	Timestamp int64                `json:"timestamp"`            // 生成时间
+	Data      interface{}          `json:"data"`                 // 指标数据（如 *indicators.ShortTermIndicators）
+	FetchedAt map[string]time.Time `json:"fetched_at,omitempty"` // 输入数据的获取时间（见 StampInputs，决策前检查是否过期）
+}
+
+// Factory 策略工厂函数
+type Factory func() Strategy
+
+var (
+	registry   = make(map[string]Factory)
+	registryMu sync.RWMutex
+)
+
+// Register 注册策略（重复注册会覆盖）
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[name] = factory
+}
+
+// New 根据名称创建策略实例
+func New(name string) (Strategy, error) {
+	registryMu.RLock()
+	factory, exists := registry[name]
+	registryMu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("未注册的策略: %s (可用策略: %v)", name, Names())
+	}
+
+	return factory(), nil
+}
+
+// Exists 判断策略是否已注册
+func Exists(name string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	_, exists := registry[name]
+	return exists
+}
+
+// Names 获取所有已注册的策略名称（按字母排序）
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,192 @@
+/*
+Package symbolpool 运行时调整交易对池（不修改配置、不重启程序）
+
+主要功能：
+- New(dir string) *Overrides                                                                   // 创建交易对池调整并加载保存的状态（<dir>/symbol_overrides.json）
+- (o *Overrides) Add(scope string, symbols []string) error                                     // 加入交易对
+- (o *Overrides) Remove(scope string, symbols []string) error                                  // 移除交易对
+- (o *Overrides) Apply(accountID string, base []string, convert func(string) string) []string  // 按调整计算账号的交易对池
+- (o *Overrides) All() map[string]*Changes                                                     // 全部调整
+
+调整分为全部账号（ScopeAll）和单个账号（账号ID）两级，先应用全部账号的调整，再应用账号的调整，
+同一级中后一次操作覆盖前一次（移除已加入的交易对、重新加入已移除的交易对）。
+交易对在调用方校验后才加入；状态每次变化后写入文件，重启后保持。
+*/
+package symbolpool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// ScopeAll 对全部账号生效的调整
+const ScopeAll = "*"
+
+// Changes 一级调整（同时作为持久化格式）
+type Changes struct {
+	Added   []string `json:"added,omitempty"`   // 加入的交易对
+	Removed []string `json:"removed,omitempty"` // 移除的交易对
+}
+
+// Overrides 交易对池调整
+type Overrides struct {
+	path   string
+	mu     sync.Mutex
+	scopes map[string]*Changes // ScopeAll 或账号ID -> 调整
+}
+
+// New 创建交易对池调整并加载保存的状态（<dir>/symbol_overrides.json，读取失败时不调整）
+func New(dir string) *Overrides {
+	o := &Overrides{
+		path:   filepath.Join(dir, "symbol_overrides.json"),
+		scopes: make(map[string]*Changes),
+	}
+
+	data, err := os.ReadFile(o.path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			utils.Warn("读取交易对池调整失败", zap.String("path", o.path), zap.Error(err))
+		}
+		return o
+	}
+	if err := json.Unmarshal(data, &o.scopes); err != nil {
+		utils.Warn("解析交易对池调整失败", zap.String("path", o.path), zap.Error(err))
+		o.scopes = make(map[string]*Changes)
+		return o
+	}
+	for scope, c := range o.scopes {
+		utils.Info("交易对池调整",
+			zap.String("scope", scope),
+			zap.Strings("added", c.Added),
+			zap.Strings("removed", c.Removed),
+		)
+	}
+	return o
+}
+
+// Add 加入交易对（同时取消对这些交易对的移除）
+func (o *Overrides) Add(scope string, symbols []string) error {
+	return o.update(scope, symbols, true)
+}
+
+// Remove 移除交易对（同时取消对这些交易对的加入）
+func (o *Overrides) Remove(scope string, symbols []string) error {
+	return o.update(scope, symbols, false)
+}
+
+// update 修改一级调整并保存（保存失败时恢复内存中的状态）
+func (o *Overrides) update(scope string, symbols []string, add bool) error {
+	if scope == "" {
+		return fmt.Errorf("调整范围不能为空")
+	}
+	if len(symbols) == 0 {
+		return fmt.Errorf("交易对不能为空")
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	old, ok := o.scopes[scope]
+	c := &Changes{}
+	if ok {
+		c.Added, c.Removed = slices.Clone(old.Added), slices.Clone(old.Removed)
+	}
+	for _, symbol := range symbols {
+		if add {
+			c.Removed = slices.DeleteFunc(c.Removed, func(s string) bool { return s == symbol })
+			if !slices.Contains(c.Added, symbol) {
+				c.Added = append(c.Added, symbol)
+			}
+		} else {
+			c.Added = slices.DeleteFunc(c.Added, func(s string) bool { return s == symbol })
+			if !slices.Contains(c.Removed, symbol) {
+				c.Removed = append(c.Removed, symbol)
+			}
+		}
+	}
+
+	if len(c.Added) == 0 && len(c.Removed) == 0 {
+		delete(o.scopes, scope)
+	} else {
+		o.scopes[scope] = c
+	}
+	if err := o.saveLocked(); err != nil {
+		if ok {
+			o.scopes[scope] = old
+		} else {
+			delete(o.scopes, scope)
+		}
+		return err
+	}
+	utils.Info("交易对池已调整",
+		zap.String("scope", scope),
+		zap.Bool("add", add),
+		zap.Strings("symbols", symbols),
+	)
+	return nil
+}
+
+// Apply 按调整计算账号的交易对池（返回新的切片，不修改base）
+// convert 将调整中的交易对转换为账号的交易对格式（如币本位合约），为nil时不转换
+func (o *Overrides) Apply(accountID string, base []string, convert func(string) string) []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	symbols := slices.Clone(base)
+	for _, scope := range []string{ScopeAll, accountID} {
+		c, ok := o.scopes[scope]
+		if !ok {
+			continue
+		}
+		for _, symbol := range c.Removed {
+			if convert != nil {
+				symbol = convert(symbol)
+			}
+			symbols = slices.DeleteFunc(symbols, func(s string) bool { return s == symbol })
+		}
+		for _, symbol := range c.Added {
+			if convert != nil {
+				symbol = convert(symbol)
+			}
+			if !slices.Contains(symbols, symbol) {
+				symbols = append(symbols, symbol)
+			}
+		}
+	}
+	return symbols
+}
+
+// All 全部调整（返回副本）
+func (o *Overrides) All() map[string]*Changes {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	all := make(map[string]*Changes, len(o.scopes))
+	for scope, c := range o.scopes {
+		all[scope] = &Changes{Added: slices.Clone(c.Added), Removed: slices.Clone(c.Removed)}
+	}
+	return all
+}
+
+// saveLocked 保存状态（调用方已持有锁）
+func (o *Overrides) saveLocked() error {
+	data, err := json.MarshalIndent(o.scopes, "", "  ")
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(o.path), 0755); err == nil {
+			err = os.WriteFile(o.path, data, 0644)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("保存交易对池调整失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+/*
+Package telegram Telegram机器人命令（长轮询 getUpdates，回复 sendMessage）
+
+主要功能：
+- NewBot(cfg config.TelegramConfig, proxyURL string) *Bot              // 创建机器人
+- (b *Bot) Handle(name, help string, handler Handler)                  // 注册命令（如 closeall 对应 /closeall）
+- (b *Bot) Run(ctx context.Context)                                    // 接收并处理命令，直到ctx取消
+- (b *Bot) Send(ctx context.Context, chatID int64, text string) error  // 发送消息
+
+只处理 chat_ids 中的聊天发来的命令，其他消息忽略并记录警告。
+/help 列出已注册的命令。命令依次在 Run 所在的goroutine中处理，处理函数返回的文字作为回复。
+*/
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// retryDelay 接收消息失败后的重试间隔
+const retryDelay = 5 * time.Second
+
+// Handler 命令处理函数，返回回复的文字（为空时不回复）
+type Handler func(chatID int64, args []string) string
+
+// command 已注册的命令
+type command struct {
+	help    string
+	handler Handler
+}
+
+// Bot Telegram机器人
+type Bot struct {
+	baseURL    string
+	chats      []int64
+	poll       time.Duration
+	httpClient *http.Client
+	commands   map[string]command
+	offset     int64
+}
+
+// update getUpdates 返回的一条更新
+type update struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+// apiResponse 接口返回
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	Description string          `json:"description"`
+}
+
+// NewBot 创建机器人
+func NewBot(cfg config.TelegramConfig, proxyURL string) *Bot {
+	poll := time.Duration(cfg.PollSec) * time.Second
+	b := &Bot{
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/") + "/bot" + cfg.BotToken,
+		chats:      cfg.ChatIDs,
+		poll:       poll,
+		httpClient: &http.Client{Timeout: poll + 10*time.Second},
+		commands:   make(map[string]command),
+	}
+	if proxyURL != "" {
+		if proxy, err := url.Parse(proxyURL); err != nil {
+			utils.Error("解析代理URL失败", zap.String("proxy", proxyURL), zap.Error(err))
+		} else {
+			b.httpClient.Transport = &http.Transport{Proxy: http.ProxyURL(proxy)}
+		}
+	}
+	return b
+}
+
+// Handle 注册命令（name不带斜杠）
+func (b *Bot) Handle(name, help string, handler Handler) {
+	b.commands[name] = command{help: help, handler: handler}
+}
+
+// Run 接收并处理命令，直到ctx取消
+func (b *Bot) Run(ctx context.Context) {
+	utils.Info("Telegram机器人启动", zap.Int("chats", len(b.chats)), zap.Int("commands", len(b.commands)))
+	for ctx.Err() == nil {
+		updates, err := b.getUpdates(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			utils.Warn("接收Telegram消息失败", zap.Error(err))
+			select {
+			case <-time.After(retryDelay):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		for _, u := range updates {
+			b.offset = u.UpdateID + 1
+			if u.Message != nil {
+				b.dispatch(ctx, u.Message.Chat.ID, u.Message.Text)
+			}
+		}
+	}
+}
+
+// dispatch 处理一条消息
+func (b *Bot) dispatch(ctx context.Context, chatID int64, text string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return
+	}
+	if !slices.Contains(b.chats, chatID) {
+		utils.Warn("忽略未授权聊天的Telegram命令", zap.Int64("chat_id", chatID), zap.String("command", fields[0]))
+		return
+	}
+
+	// 群组中的命令可能带机器人用户名（/closeall@MyBot）
+	name, _, _ := strings.Cut(strings.TrimPrefix(fields[0], "/"), "@")
+	name = strings.ToLower(name)
+	utils.Info("收到Telegram命令", zap.Int64("chat_id", chatID), zap.String("command", name), zap.Strings("args", fields[1:]))
+
+	var reply string
+	if cmd, ok := b.commands[name]; ok {
+		reply = cmd.handler(chatID, fields[1:])
+	} else {
+		reply = b.help()
+	}
+	if reply == "" {
+		return
+	}
+	if err := b.Send(ctx, chatID, reply); err != nil {
+		utils.Warn("发送Telegram回复失败", zap.Int64("chat_id", chatID), zap.Error(err))
+	}
+}
+
+// help 已注册命令的说明
+func (b *Bot) help() string {
+	names := make([]string, 0, len(b.commands))
+	for name := range b.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := []string{"可用命令："}
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("/%s %s", name, b.commands[name].help))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Send 发送消息
+func (b *Bot) Send(ctx context.Context, chatID int64, text string) error {
+	body, err := json.Marshal(map[string]interface{}{"chat_id": chatID, "text": text})
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+	_, err = b.call(ctx, http.MethodPost, "sendMessage", bytes.NewReader(body))
+	return err
+}
+
+// getUpdates 长轮询获取新消息
+func (b *Bot) getUpdates(ctx context.Context) ([]update, error) {
+	params := url.Values{}
+	params.Set("timeout", strconv.Itoa(int(b.poll.Seconds())))
+	params.Set("offset", strconv.FormatInt(b.offset, 10))
+	params.Set("allowed_updates", `["message"]`)
+
+	result, err := b.call(ctx, http.MethodGet, "getUpdates?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var updates []update
+	if err := json.Unmarshal(result, &updates); err != nil {
+		return nil, fmt.Errorf("解析消息失败: %w", err)
+	}
+	return updates, nil
+}
+
+// call 调用接口，返回 result 字段
+func (b *Bot) call(ctx context.Context, method, path string, body io.Reader) (json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+"/"+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", redact(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", redact(err))
+	}
+	defer resp.Body.Close()
+
+	var r apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("解析返回失败（状态码 %d）: %w", resp.StatusCode, err)
+	}
+	if !r.OK {
+		return nil, fmt.Errorf("接口返回错误（状态码 %d）: %s", resp.StatusCode, r.Description)
+	}
+	return r.Result, nil
+}
+
+// redact 去掉错误中的URL（URL含机器人令牌，不能写入日志）
+func redact(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+/*
+AI决策缓存测试程序
+
+测试内容：
+- 指标数据只有末位小数和时间戳变化时指纹相同，价格明显变化或换用其他模板时指纹不同
+- 指纹相同且未过期时复用上次决策（返回副本，修改不影响缓存）
+- 指纹不同、超过最长缓存时间、其他交易对时不命中
+- 未启用缓存（nil）时总是不命中
+
+运行方式：
+
+	go run test/ai/test_cache.go
+*/
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/ai"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/prompt"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== AI决策缓存测试开始 ===")
+
+	payload := func(price, rsi float64, ts int64) *indicators.ShortTermIndicators {
+		return &indicators.ShortTermIndicators{
+			Symbol:     "ETHUSDT",
+			Timestamp:  ts,
+			Timeframes: &indicators.ShortTermTimeframes{M15: &indicators.TimeframeData{ClosePrice: price, RSI: rsi}},
+		}
+	}
+	ignore := []string{"timestamp"}
+	base := prompt.PayloadHash("minimal", payload(2345.61, 55.231, 1000), 4, ignore)
+	fmt.Printf("基准指纹: %s\n", base)
+	for _, c := range []struct {
+		name     string
+		template string
+		data     *indicators.ShortTermIndicators
+		same     bool
+	}{
+		{"末位小数和时间戳变化", "minimal", payload(2345.64, 55.228, 2000), true},
+		{"价格变化", "minimal", payload(2351.2, 55.231, 1000), false},
+		{"换用其他模板", "detailed", payload(2345.61, 55.231, 1000), false},
+	} {
+		hash := prompt.PayloadHash(c.template, c.data, 4, ignore)
+		fmt.Printf("%-12s 指纹相同=%v (期望 %v)\n", c.name, hash == base, c.same)
+	}
+	fmt.Printf("不支持的类型: %q (期望为空)\n\n", prompt.PayloadHash("minimal", 42, 4, ignore))
+
+	now := time.Now()
+	cache := ai.NewCache(15 * time.Minute)
+	cache.Put("ETHUSDT", base, &executor.Decision{Symbol: "ETHUSDT", Action: "open_long", StopLoss: 2300, Reason: "突破"}, now)
+
+	d, decidedAt := cache.Get("ETHUSDT", base, now.Add(5*time.Minute))
+	fmt.Printf("5分钟后相同指纹: 命中=%v 动作=%s 决策时间一致=%v\n", d != nil, d.Action, decidedAt.Equal(now))
+	d.Action = "close"
+	d, _ = cache.Get("ETHUSDT", base, now.Add(5*time.Minute))
+	fmt.Printf("修改返回的决策后再次读取: %s (期望 open_long)\n", d.Action)
+
+	for _, c := range []struct {
+		name   string
+		symbol string
+		hash   string
+		at     time.Time
+	}{
+		{"指纹不同", "ETHUSDT", "other", now.Add(time.Minute)},
+		{"超过15分钟", "ETHUSDT", base, now.Add(16 * time.Minute)},
+		{"其他交易对", "BTCUSDT", base, now.Add(time.Minute)},
+		{"指纹为空", "ETHUSDT", "", now.Add(time.Minute)},
+	} {
+		d, _ := cache.Get(c.symbol, c.hash, c.at)
+		fmt.Printf("%-10s 命中=%v (期望 false)\n", c.name, d != nil)
+	}
+
+	var disabled *ai.Cache
+	disabled.Put("ETHUSDT", base, &executor.Decision{Action: "hold"}, now)
+	d, _ = disabled.Get("ETHUSDT", base, now)
+	fmt.Printf("未启用缓存: 命中=%v (期望 false)\n", d != nil)
+
+	utils.Info("=== AI决策缓存测试完成 ===")
+}
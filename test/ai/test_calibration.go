@@ -0,0 +1,152 @@
+/*
+置信度校准测试程序
+
+测试内容：
+- 在临时目录写入模拟的审计记录（两个模型、不同置信度的开仓决策）和对应的交易日志
+- 决策按哈希与交易对应，分两次平仓的决策净盈亏合计
+- 观望决策、置信度为0、尚未平仓的决策和非AI交易不参与统计
+- 复用缓存的决策归入原决策的模型
+- 输出各模型的校准曲线、Brier分数和期望校准误差，并保存报告文件
+
+运行方式：
+
+	go run test/ai/test_calibration.go
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"crypto-ai-trader/ai"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/journal"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 置信度校准测试开始 ===")
+
+	dir, err := os.MkdirTemp("", "ai-calibration")
+	if err != nil {
+		utils.Fatal("创建临时目录失败", zap.Error(err))
+	}
+	defer os.RemoveAll(dir)
+	audit, err := ai.NewAudit(filepath.Join(dir, "audit"))
+	if err != nil {
+		utils.Fatal("创建审计记录失败", zap.Error(err))
+	}
+	tradeJournal, err := journal.New(filepath.Join(dir, "journal"))
+	if err != nil {
+		utils.Fatal("创建交易日志失败", zap.Error(err))
+	}
+
+	// 模型A：高置信度4笔赢3笔，低置信度2笔赢1笔；模型B：高置信度2笔全亏
+	start := time.Now().Add(-48 * time.Hour)
+	cases := []struct {
+		model      string
+		action     string
+		confidence float64
+		pnls       []float64 // 交易日志中的净盈亏（为空表示尚未平仓）
+	}{
+		{"model-a", executor.ActionOpenLong, 0.9, []float64{12}},
+		{"model-a", executor.ActionOpenShort, 0.85, []float64{5, 3}}, // 分两次平仓
+		{"model-a", executor.ActionOpenLong, 0.92, []float64{-8}},
+		{"model-a", executor.ActionOpenLong, 0.88, []float64{6}},
+		{"model-a", executor.ActionOpenLong, 0.55, []float64{-4}},
+		{"model-a", executor.ActionOpenShort, 0.5, []float64{2}},
+		{"model-b", executor.ActionOpenLong, 0.95, []float64{-10}},
+		{"model-b", executor.ActionOpenShort, 0.9, []float64{-3}},
+		{"model-a", executor.ActionHold, 0.8, nil},
+		{"model-a", executor.ActionOpenLong, 0, []float64{7}}, // 未给出置信度
+		{"model-b", executor.ActionOpenLong, 0.7, nil},        // 尚未平仓
+	}
+	var first *ai.AuditRecord
+	for i, c := range cases {
+		rec := record(start.Add(time.Duration(i)*time.Hour), c.model, c.action, c.confidence)
+		write(audit, tradeJournal, rec, c.pnls)
+		if first == nil {
+			first = rec
+		}
+	}
+
+	// 复用缓存的决策（没有阶段记录，归入原决策的模型 model-a）
+	cached := record(start.Add(20*time.Hour), "", executor.ActionOpenShort, 0.8)
+	cached.Mode, cached.Stages, cached.CachedFrom = ai.ModeCached, nil, &first.Time
+	write(audit, tradeJournal, cached, []float64{9})
+
+	// 非AI决策产生的交易
+	tradeJournal.Record(&journal.Trade{AccountID: "account_1", Symbol: "BTCUSDT", GrossPnL: 100, DecisionID: "manual", ExitTime: time.Now()})
+
+	report := ai.BuildCalibrationReport(audit, tradeJournal, []string{"account_1", "account_2"}, 5)
+	for _, g := range report.Groups {
+		fmt.Printf("%s %s: 决策 %d，平均置信度 %.3f，胜率 %.3f，Brier %.3f，ECE %.3f\n",
+			g.AccountID, g.Model, g.Trades, g.MeanConfidence, g.WinRate, g.Brier, g.ECE)
+		for _, b := range g.Curve {
+			fmt.Printf("  [%.1f, %.1f) 决策 %d，平均置信度 %.3f，胜率 %.3f，平均净盈亏 %.2f\n",
+				b.Low, b.High, b.Trades, b.MeanConfidence, b.WinRate, b.AvgNetPnL)
+		}
+	}
+	fmt.Println("期望: model-a 7笔（含缓存复用的1笔），model-b 2笔胜率0")
+
+	path, err := ai.SaveCalibrationReport(report, filepath.Join(dir, "reports"))
+	if err != nil {
+		utils.Fatal("保存校准报告失败", zap.Error(err))
+	}
+	fmt.Printf("报告文件: %s\n", filepath.Base(path))
+
+	utils.Info("=== 置信度校准测试完成 ===")
+}
+
+// record 生成一条单次调用的审计记录
+func record(t time.Time, model, action string, confidence float64) *ai.AuditRecord {
+	decision := &executor.Decision{
+		AccountID:  "account_1",
+		Symbol:     "ETHUSDT",
+		Action:     action,
+		Quantity:   0.1,
+		StopLoss:   2000,
+		Confidence: confidence,
+		Timestamp:  t.UnixMilli(),
+	}
+	return &ai.AuditRecord{
+		AccountID: "account_1",
+		Strategy:  "short_term",
+		Symbol:    "ETHUSDT",
+		Time:      t,
+		Mode:      ai.ModeSingle,
+		Stages:    []*ai.Stage{{Name: ai.StageSingle, Model: model}},
+		Decision:  decision,
+		Result:    "executed",
+	}
+}
+
+// write 写入审计记录和决策对应的交易
+func write(audit *ai.Audit, tradeJournal *journal.Journal, rec *ai.AuditRecord, pnls []float64) {
+	if err := audit.Record(rec); err != nil {
+		utils.Fatal("写入审计记录失败", zap.Error(err))
+	}
+	for _, pnl := range pnls {
+		trade := &journal.Trade{
+			AccountID:  rec.AccountID,
+			Symbol:     rec.Symbol,
+			GrossPnL:   pnl,
+			DecisionID: rec.Decision.Hash(),
+			EntryTime:  rec.Time,
+			ExitTime:   rec.Time.Add(30 * time.Minute),
+		}
+		if err := tradeJournal.Record(trade); err != nil {
+			utils.Fatal("写入交易日志失败", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,213 @@
+/*
+决策标签与对照组测试程序
+
+测试内容：
+- 模板版本：修改模板后只有该模板的版本变化，修改公共片段（含 {{define}}）后引用它的模板版本都变化
+- 配置哈希：相同配置哈希相同，参数变化后哈希变化
+- 在临时目录写入模拟的审计记录（两个模板版本、两个模型）和对应的交易日志
+- 按标签分组统计决策数、开仓数、被否决数、胜率和净盈亏，复用缓存的决策沿用原标签，没有标签的记录单独一组
+
+运行方式：
+
+	go run test/ai/test_cohort.go
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"crypto-ai-trader/ai"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/journal"
+	"crypto-ai-trader/prompt"
+	"crypto-ai-trader/utils"
+	"crypto-ai-trader/veto"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 决策标签与对照组测试开始 ===")
+
+	dir, err := os.MkdirTemp("", "ai-cohort")
+	if err != nil {
+		utils.Fatal("创建临时目录失败", zap.Error(err))
+	}
+	defer os.RemoveAll(dir)
+
+	// 1. 模板版本
+	fmt.Println("\n--- 模板版本 ---")
+	promptDir := filepath.Join(dir, "prompts")
+	writeTemplate(promptDir, "common", `{{define "output_format"}}输出JSON{{end}}`)
+	writeTemplate(promptDir, "minimal", `{{.Symbol}} 简要分析 {{template "output_format" .}}`)
+	writeTemplate(promptDir, "detailed", `{{.Symbol}} 详细分析 {{template "output_format" .}}`)
+	store, err := prompt.NewStore(promptDir)
+	if err != nil {
+		utils.Fatal("加载提示词模板失败", zap.Error(err))
+	}
+	v1 := versions(store)
+	fmt.Printf("初始版本: %v\n", v1)
+
+	writeTemplate(promptDir, "minimal", `{{.Symbol}} 简要分析（只看趋势） {{template "output_format" .}}`)
+	reload(store)
+	v2 := versions(store)
+	fmt.Printf("修改 minimal 后: %v（期望 minimal 变化，detailed 不变: %v）\n",
+		v2, v2["minimal"] != v1["minimal"] && v2["detailed"] == v1["detailed"])
+
+	writeTemplate(promptDir, "common", `{{define "output_format"}}只输出一个JSON对象{{end}}`)
+	reload(store)
+	v3 := versions(store)
+	fmt.Printf("修改公共片段后: %v（期望两个模板都变化: %v）\n",
+		v3, v3["minimal"] != v2["minimal"] && v3["detailed"] != v2["detailed"])
+	fmt.Printf("不存在的模板版本: %q\n", store.Version("missing"))
+
+	// 2. 配置哈希
+	fmt.Println("\n--- 配置哈希 ---")
+	params := map[string]interface{}{"strategy": "short_term", "temperature": 0.3}
+	h1, h2 := ai.ConfigHash(params), ai.ConfigHash(map[string]interface{}{"temperature": 0.3, "strategy": "short_term"})
+	params["temperature"] = 0.7
+	h3 := ai.ConfigHash(params)
+	fmt.Printf("相同配置: %s %s（期望相同: %v），temperature变化后: %s（期望不同: %v）\n", h1, h2, h1 == h2, h3, h1 != h3)
+
+	// 3. 对照组统计
+	fmt.Println("\n--- 对照组统计 ---")
+	audit, err := ai.NewAudit(filepath.Join(dir, "audit"))
+	if err != nil {
+		utils.Fatal("创建审计记录失败", zap.Error(err))
+	}
+	tradeJournal, err := journal.New(filepath.Join(dir, "journal"))
+	if err != nil {
+		utils.Fatal("创建交易日志失败", zap.Error(err))
+	}
+
+	tagsV1 := &executor.DecisionTags{Template: "minimal", TemplateVersion: v1["minimal"], Model: "model-a", ConfigHash: h1}
+	tagsV2 := &executor.DecisionTags{Template: "minimal", TemplateVersion: v2["minimal"], Model: "model-a", ConfigHash: h1}
+	tagsB := &executor.DecisionTags{Template: "minimal", TemplateVersion: v2["minimal"], Model: "model-b", ConfigHash: h1}
+
+	start := time.Now().Add(-48 * time.Hour)
+	cases := []struct {
+		tags   *executor.DecisionTags
+		action string
+		pnls   []float64 // 交易日志中的净盈亏（为空表示没有交易）
+		vetoed bool
+	}{
+		{tagsV1, executor.ActionOpenLong, []float64{-5}, false},
+		{tagsV1, executor.ActionOpenShort, []float64{-3}, false},
+		{tagsV1, executor.ActionHold, nil, false},
+		{tagsV2, executor.ActionOpenLong, []float64{8}, false},
+		{tagsV2, executor.ActionOpenLong, []float64{4, 2}, false}, // 分两次平仓
+		{tagsV2, executor.ActionOpenShort, nil, true},             // 被风控否决
+		{tagsV2, executor.ActionClose, nil, false},
+		{tagsB, executor.ActionOpenShort, []float64{6}, false},
+		{nil, executor.ActionOpenLong, []float64{1}, false}, // 打标签之前的历史记录
+	}
+	var first *ai.AuditRecord
+	for i, c := range cases {
+		rec := record(start.Add(time.Duration(i)*time.Hour), c.tags, c.action)
+		if c.vetoed {
+			rec.Veto = &veto.Result{Vetoed: true, Verdicts: []*veto.Verdict{{Rule: "adx", Action: veto.ActionVeto, Reason: "ADX过低"}}}
+			rec.Result = "vetoed"
+		}
+		write(audit, tradeJournal, rec, c.pnls)
+		if c.tags == tagsV2 && first == nil {
+			first = rec
+		}
+	}
+
+	// 复用缓存的决策沿用原决策的标签（归入 tagsV2）
+	cached := record(start.Add(20*time.Hour), tagsV2, executor.ActionOpenLong)
+	cached.Mode, cached.Stages, cached.CachedFrom = ai.ModeCached, nil, &first.Time
+	write(audit, tradeJournal, cached, []float64{-1})
+
+	report := ai.BuildCohortReport(audit, tradeJournal, []string{"account_1", "account_2"})
+	for _, c := range report.Cohorts {
+		fmt.Printf("%s [%q]: 决策 %d %v，开仓 %d，否决 %d，平仓交易 %d，胜率 %.2f，净盈亏 %.2f（平均 %.2f）\n",
+			c.AccountID, c.Cohort, c.Decisions, c.Actions, c.Opens, c.Vetoed, c.Trades, c.WinRate, c.NetPnL, c.AvgNetPnL)
+	}
+	fmt.Println("期望: 4个对照组；v1 胜率0 净亏-8，v2（含缓存复用）交易3笔 净盈亏13，model-b 1笔，空标签组1笔")
+
+	utils.Info("=== 决策标签与对照组测试完成 ===")
+}
+
+// writeTemplate 写入模板文件
+func writeTemplate(dir, name, content string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		utils.Fatal("创建模板目录失败", zap.Error(err))
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".tmpl"), []byte(content), 0644); err != nil {
+		utils.Fatal("写入模板失败", zap.Error(err))
+	}
+}
+
+// reload 重新加载模板
+func reload(store *prompt.Store) {
+	if _, err := store.Reload(); err != nil {
+		utils.Fatal("重新加载模板失败", zap.Error(err))
+	}
+}
+
+// versions 所有模板的版本
+func versions(store *prompt.Store) map[string]string {
+	result := make(map[string]string)
+	for _, name := range store.Names() {
+		result[name] = store.Version(name)
+	}
+	return result
+}
+
+// record 生成一条单次调用的审计记录
+func record(t time.Time, tags *executor.DecisionTags, action string) *ai.AuditRecord {
+	decision := &executor.Decision{
+		AccountID:  "account_1",
+		Symbol:     "BTCUSDT",
+		Action:     action,
+		Quantity:   0.01,
+		StopLoss:   60000,
+		Confidence: 0.7,
+		Timestamp:  t.UnixMilli(),
+		Tags:       tags,
+	}
+	model := ""
+	if tags != nil {
+		model = tags.Model
+	}
+	return &ai.AuditRecord{
+		AccountID: "account_1",
+		Strategy:  "short_term",
+		Symbol:    "BTCUSDT",
+		Time:      t,
+		Mode:      ai.ModeSingle,
+		Stages:    []*ai.Stage{{Name: ai.StageSingle, Template: "minimal", Model: model}},
+		Decision:  decision,
+		Result:    "executed",
+	}
+}
+
+// write 写入审计记录和决策对应的交易
+func write(audit *ai.Audit, tradeJournal *journal.Journal, rec *ai.AuditRecord, pnls []float64) {
+	if err := audit.Record(rec); err != nil {
+		utils.Fatal("写入审计记录失败", zap.Error(err))
+	}
+	for _, pnl := range pnls {
+		trade := &journal.Trade{
+			AccountID:  rec.AccountID,
+			Symbol:     rec.Symbol,
+			GrossPnL:   pnl,
+			DecisionID: rec.Decision.Hash(),
+			EntryTime:  rec.Time,
+			ExitTime:   rec.Time.Add(30 * time.Minute),
+		}
+		if err := tradeJournal.Record(trade); err != nil {
+			utils.Fatal("写入交易日志失败", zap.Error(err))
+		}
+	}
+}
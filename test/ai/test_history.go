@@ -0,0 +1,151 @@
+/*
+指标快照历史查询测试程序
+
+测试内容：
+- 在临时目录写入两个账号的模拟审计记录（短线、长线指标数据，含旧版本数据、没有指标数据和其他交易对的记录）
+- 按交易对查询完整指标快照（按时间从旧到新，旧版本迁移到当前版本），按时间范围和 limit 过滤
+- 指定时间周期只返回该周期指标和市场数据，没有该周期的快照跳过
+- 持仓量、资金费率序列；读取失败的账号记入 errors
+- 状态API的 /api/indicators/history、/api/indicators/series 参数解析见 main.go 的 snapshotQuery
+
+运行方式：
+
+	go run test/ai/test_history.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/ai"
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// makeKlines 生成count根上涨的K线：第i根收盘价为 base×(1+0.002×i)，最高、最低价为收盘价±0.4%
+func makeKlines(base float64, count int) []exchange.Kline {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', 12, 64) }
+	klines := make([]exchange.Kline, count)
+	for i := range klines {
+		c := base * (1 + 0.002*float64(i))
+		klines[i] = exchange.Kline{
+			OpenTime:  int64(i) * 60000,
+			Open:      format(c * 0.999),
+			High:      format(c * 1.004),
+			Low:       format(c * 0.996),
+			Close:     format(c),
+			Volume:    "100",
+			CloseTime: int64(i+1)*60000 - 1,
+		}
+	}
+	return klines
+}
+
+// record 生成一条带指标数据的审计记录
+func record(accountID, strategy, symbol string, at time.Time, data interface{}) *ai.AuditRecord {
+	raw, _ := json.Marshal(data)
+	return &ai.AuditRecord{
+		AccountID:     accountID,
+		Strategy:      strategy,
+		Symbol:        symbol,
+		Time:          at,
+		Mode:          ai.ModeSingle,
+		IndicatorKind: indicators.Kind(data),
+		Indicators:    raw,
+	}
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 指标快照历史查询测试开始 ===")
+
+	dir, err := os.MkdirTemp("", "ai-history")
+	if err != nil {
+		utils.Fatal("创建临时目录失败", zap.Error(err))
+	}
+	defer os.RemoveAll(dir)
+	audit, err := ai.NewAudit(dir)
+	if err != nil {
+		utils.Fatal("创建审计记录失败", zap.Error(err))
+	}
+
+	// 1. 模拟审计记录：account_1 短线每5分钟一次，account_2 长线每15分钟一次
+	start := time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		klines := makeKlines(60000+float64(i)*100, 100)
+		short := indicators.CalculateShortTermIndicators("BTCUSDT", klines, klines, klines)
+		short.MarketData = &indicators.MarketData{OICurrent: 120 + float64(i), FundingRate: 0.01 * float64(i)}
+		audit.Record(record("account_1", "short_term", "BTCUSDT", start.Add(time.Duration(i)*5*time.Minute), short))
+	}
+	eth := makeKlines(3000, 100)
+	audit.Record(record("account_1", "short_term", "ETHUSDT", start, indicators.CalculateShortTermIndicators("ETHUSDT", eth, eth, eth)))
+	audit.Record(&ai.AuditRecord{AccountID: "account_1", Symbol: "BTCUSDT", Time: start.Add(time.Minute), Error: "没有指标数据"})
+	for i := 0; i < 2; i++ {
+		klines := makeKlines(60050, 100)
+		long := indicators.CalculateLongTermIndicators("BTCUSDT", klines, klines, klines)
+		rec := record("account_2", "long_term", "BTCUSDT", start.Add(time.Duration(i)*15*time.Minute+time.Minute), long)
+		if i == 0 {
+			// 旧版本数据：没有 schema_version 和 atr_pct
+			var doc map[string]interface{}
+			json.Unmarshal(rec.Indicators, &doc)
+			delete(doc, "schema_version")
+			delete(doc["timeframes"].(map[string]interface{})["1h"].(map[string]interface{}), "atr_pct")
+			rec.Indicators, _ = json.Marshal(doc)
+		}
+		audit.Record(rec)
+	}
+
+	// 2. 完整指标快照
+	accounts := []string{"account_1", "account_2"}
+	all := ai.QuerySnapshots(audit, accounts, ai.SnapshotQuery{Symbol: "BTCUSDT"})
+	fmt.Printf("BTCUSDT全部快照: %d 跳过 %d（期望8 0）\n", len(all.Snapshots), all.Skipped)
+	sorted := true
+	for i := 1; i < len(all.Snapshots); i++ {
+		sorted = sorted && !all.Snapshots[i].Time.Before(all.Snapshots[i-1].Time)
+	}
+	fmt.Printf("按时间从旧到新: %v 第二个快照: %s %s（期望true account_2 long_term）\n", sorted, all.Snapshots[1].AccountID, all.Snapshots[1].Kind)
+	migrated := all.Snapshots[1].Indicators.(*indicators.LongTermIndicators)
+	fmt.Printf("旧版本迁移: schema_version=%d 1h atr_pct=%v（期望%d 非0）\n", migrated.SchemaVersion, migrated.Timeframes.H1.ATRPct, indicators.SchemaVersion)
+
+	// 3. 时间范围和 limit
+	ranged := ai.QuerySnapshots(audit, accounts, ai.SnapshotQuery{Symbol: "BTCUSDT", From: start.Add(10 * time.Minute), To: start.Add(20 * time.Minute)})
+	fmt.Printf("08:10-08:20: %d（期望3：account_1的08:10、08:15，account_2的08:16）\n", len(ranged.Snapshots))
+	latest := ai.QuerySnapshots(audit, []string{"account_1"}, ai.SnapshotQuery{Symbol: "BTCUSDT", Limit: 2})
+	fmt.Printf("limit=2: %s %s（期望08:20 08:25）\n", latest.Snapshots[0].Time.Format("15:04"), latest.Snapshots[1].Time.Format("15:04"))
+
+	// 4. 指定时间周期
+	m5 := ai.QuerySnapshots(audit, accounts, ai.SnapshotQuery{Symbol: "BTCUSDT", Timeframe: "5m"})
+	first := m5.Snapshots[0]
+	fmt.Printf("5m: 快照 %d 跳过 %d（期望6 2，长线没有5m）\n", len(m5.Snapshots), m5.Skipped)
+	fmt.Printf("5m快照: 完整数据为空=%v 收盘价=%v 持仓量=%v（期望true 有值 120）\n", first.Indicators == nil, first.Timeframe.ClosePrice, first.MarketData.OICurrent)
+
+	// 5. 持仓量、资金费率序列
+	series := ai.QuerySeries(audit, accounts, ai.SnapshotQuery{Symbol: "BTCUSDT"})
+	fmt.Printf("序列点数: %d（期望6，长线记录没有市场数据）\n", len(series.Points))
+	last := series.Points[len(series.Points)-1]
+	fmt.Printf("最后一点: 时间=%s 持仓量=%v 资金费率=%v 价格>0=%v（期望08:25 125 0.05 true）\n",
+		last.Time.Format("15:04"), last.OI, last.FundingRate, last.Price > 0)
+
+	// 6. 读取失败的账号
+	os.WriteFile(filepath.Join(dir, "account_3.jsonl"), []byte("{broken\n"), 0644)
+	broken := ai.QuerySeries(audit, []string{"account_1", "account_3"}, ai.SnapshotQuery{Symbol: "BTCUSDT"})
+	fmt.Printf("损坏的审计记录: 点数=%d errors=%v（期望6 account_3解析失败）\n", len(broken.Points), broken.Errors)
+	empty := ai.QuerySnapshots(audit, accounts, ai.SnapshotQuery{Symbol: "SOLUSDT"})
+	out, _ := json.Marshal(empty)
+	fmt.Printf("没有快照: %s（期望snapshots为空数组）\n", out)
+
+	utils.Info("=== 指标快照历史查询测试结束 ===")
+}
@@ -0,0 +1,109 @@
+/*
+多交易对排名测试程序
+
+测试内容：
+- 构造5个交易对的短线指标，提取关键指标并渲染 ranking 模板
+- 启动本地模拟的 /chat/completions 接口（返回代码块包裹的JSON），用AI客户端发送排名提示词
+- 解析排名回复：过滤不在排名表中的交易对、去重、截断到 top_n
+
+运行方式：
+
+	go run test/ai/test_ranking.go
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"crypto-ai-trader/ai"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/prompt"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 多交易对排名测试开始 ===")
+
+	store, err := prompt.NewStore("configs/prompts")
+	if err != nil {
+		utils.Fatal("加载提示词模板失败", zap.Error(err))
+	}
+
+	// 构造关键指标
+	var rows []*indicators.KeyMetrics
+	var candidates []string
+	for i, symbol := range []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "DOGEUSDT", "XRPUSDT"} {
+		price := 100.0 * float64(i+1)
+		oi := float64(i) - 1.5
+		data := &indicators.ShortTermIndicators{
+			Symbol:     symbol,
+			MarketData: &indicators.MarketData{FundingRate: 0.01 * float64(i), OIChange15m: &oi},
+			Timeframes: &indicators.ShortTermTimeframes{M15: &indicators.TimeframeData{
+				OpenPrice: price * 0.99, ClosePrice: price,
+				EMA9: price * 1.01, EMA21: price, EMA55: price * 0.99,
+				RSI: 50 + float64(i)*4, ATR: price * 0.01,
+				MACD: &indicators.MACDData{Histogram: 0.5 - 0.2*float64(i)},
+			}},
+		}
+		m := indicators.Summarize(data)
+		rows = append(rows, m)
+		candidates = append(candidates, m.Symbol)
+	}
+
+	text, err := store.Render("ranking", &prompt.RankingData{
+		AccountID:    "account_1",
+		Strategy:     "short_term",
+		StrategyName: "短线",
+		Time:         time.Now(),
+		TopN:         2,
+		Rows:         rows,
+	})
+	if err != nil {
+		utils.Fatal("渲染排名提示词失败", zap.Error(err))
+	}
+	fmt.Printf("===== 排名提示词（估算 %d tokens）=====\n%s\n", prompt.EstimateTokens(text), text)
+
+	// 模拟AI接口：回复中包含排名表外的交易对和重复项
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reply := "```json\n{\"symbols\": [\"solusdt\", \"FOOUSDT\", \"SOLUSDT\", \"BTCUSDT\", \"ETHUSDT\"]}\n```"
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":   "mock-model",
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": reply}}},
+			"usage":   map[string]int{"prompt_tokens": 321, "completion_tokens": 18},
+		})
+	}))
+	defer server.Close()
+
+	client := ai.NewClient(config.AIConfig{BaseURL: server.URL, APIKey: "test", Model: "mock-model", TimeoutSec: 5}, "")
+	resp, err := client.Complete(context.Background(), &ai.Request{Prompt: text})
+	if err != nil {
+		utils.Fatal("AI请求失败", zap.Error(err))
+	}
+	fmt.Printf("AI回复: %s (提示词 %d tokens，回复 %d tokens)\n", resp.Text, resp.PromptTokens, resp.CompletionTokens)
+
+	picked, err := ai.ParseRanking(resp.Text, candidates, 2)
+	if err != nil {
+		utils.Fatal("解析排名失败", zap.Error(err))
+	}
+	fmt.Printf("挑选结果: %v (期望 [SOLUSDT BTCUSDT])\n", picked)
+
+	if _, err := ai.ParseRanking(`{"symbols": ["FOOUSDT"]}`, candidates, 2); err != nil {
+		fmt.Printf("无有效交易对: %v\n", err)
+	}
+
+	utils.Info("=== 多交易对排名测试完成 ===")
+}
@@ -0,0 +1,222 @@
+/*
+决策回放程序
+
+测试内容：
+  - 不带参数：在临时目录写入模拟的审计记录（单次调用、两阶段、缓存复用），用本地模拟的AI接口
+    分别按原提示词和新模板（用保存的指标数据重新渲染）回放，输出动作变化统计和逐条差异
+  - 带参数：读取账号的真实审计记录，用指定的模型和模板回放，报告保存到 data/reports/replay_<账号ID>_<时间>.json
+
+运行方式：
+
+	go run test/ai/test_replay.go
+	go run test/ai/test_replay.go <账号ID> [模型，默认使用ai.model] [模板，默认原提示词] [条数，默认50]
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/ai"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/prompt"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 决策回放开始 ===")
+	if len(os.Args) > 1 {
+		replayAccount(os.Args[1:])
+	} else {
+		replayMock()
+	}
+	utils.Info("=== 决策回放完成 ===")
+}
+
+// replayAccount 回放账号的真实审计记录
+func replayAccount(args []string) {
+	cfg, err := config.Load("configs/config.yml")
+	if err != nil {
+		utils.Fatal("加载配置失败", zap.Error(err))
+	}
+	aiCfg := cfg.GetAIConfig()
+	if len(args) > 1 && args[1] != "" {
+		aiCfg.Model = args[1]
+	}
+	opts := ai.ReplayOptions{Limit: 50}
+	if len(args) > 2 {
+		opts.Template = args[2]
+	}
+	if len(args) > 3 {
+		if opts.Limit, err = strconv.Atoi(args[3]); err != nil {
+			utils.Fatal("条数无效", zap.String("limit", args[3]))
+		}
+	}
+	if opts.Template != "" {
+		if opts.Store, err = prompt.NewStore(cfg.GetPromptsConfig().Dir); err != nil {
+			utils.Fatal("加载提示词模板失败", zap.Error(err))
+		}
+		if opts.Store.Lookup(opts.Template) == "" {
+			utils.Fatal("提示词模板不存在", zap.String("template", opts.Template), zap.Strings("available", opts.Store.Names()))
+		}
+	}
+
+	audit, err := ai.NewAudit(aiCfg.AuditDir)
+	if err != nil {
+		utils.Fatal("打开审计记录失败", zap.Error(err))
+	}
+	records, err := audit.Load(args[0])
+	if err != nil {
+		utils.Fatal("读取审计记录失败", zap.Error(err))
+	}
+	utils.Info("开始回放", zap.String("account_id", args[0]), zap.String("model", aiCfg.Model),
+		zap.String("template", opts.Template), zap.Int("records", len(records)), zap.Int("limit", opts.Limit))
+
+	report := ai.NewClient(aiCfg, cfg.GetProxyURL()).Replay(context.Background(), records, opts)
+	printReport(report)
+
+	dir := "data/reports"
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		utils.Fatal("创建报告目录失败", zap.Error(err))
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		utils.Fatal("序列化回放报告失败", zap.Error(err))
+	}
+	path := filepath.Join(dir, "replay_"+args[0]+"_"+time.Now().Format("20060102_150405")+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		utils.Fatal("保存回放报告失败", zap.Error(err))
+	}
+	fmt.Printf("\n回放报告: %s\n", path)
+}
+
+// replayMock 用模拟的审计记录和AI接口回放
+func replayMock() {
+	// 模拟的新模型：提示词中RSI超过70时观望，否则开多
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		reply := `{"action": "open_long", "stop_loss": 97, "take_profit": 106, "confidence": 0.6, "reason": "趋势延续"}`
+		if strings.Contains(req.Messages[len(req.Messages)-1].Content, "RSI 75") ||
+			strings.Contains(req.Messages[len(req.Messages)-1].Content, `"rsi": 75`) {
+			reply = `{"action": "hold", "confidence": 0.5, "reason": "超买"}`
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":   "new-model",
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	}))
+	defer server.Close()
+
+	store, err := prompt.NewStore("configs/prompts")
+	if err != nil {
+		utils.Fatal("加载提示词模板失败", zap.Error(err))
+	}
+	dir, err := os.MkdirTemp("", "ai-replay")
+	if err != nil {
+		utils.Fatal("创建临时目录失败", zap.Error(err))
+	}
+	defer os.RemoveAll(dir)
+	audit, err := ai.NewAudit(dir)
+	if err != nil {
+		utils.Fatal("创建审计记录失败", zap.Error(err))
+	}
+
+	start := time.Now().Add(-3 * time.Hour)
+	data := func(rsi float64) *indicators.ShortTermIndicators {
+		return &indicators.ShortTermIndicators{
+			Symbol:     "ETHUSDT",
+			Timeframes: &indicators.ShortTermTimeframes{M15: &indicators.TimeframeData{ClosePrice: 100, RSI: rsi}},
+		}
+	}
+	records := []*ai.AuditRecord{
+		single(start, "RSI 58，均线多头", data(58), &executor.Decision{Symbol: "ETHUSDT", Action: executor.ActionOpenLong, StopLoss: 98, TakeProfit: 105, Confidence: 0.7}),
+		single(start.Add(time.Hour), "RSI 75，接近阻力", data(75), &executor.Decision{Symbol: "ETHUSDT", Action: executor.ActionOpenLong, StopLoss: 98, Confidence: 0.8}),
+		single(start.Add(90*time.Minute), "RSI 60，无法判断", data(60), nil),
+		{
+			AccountID: "account_1", Strategy: "short_term", Symbol: "ETHUSDT", Time: start.Add(2 * time.Hour), Mode: ai.ModeTwoStage,
+			Stages: []*ai.Stage{
+				{Name: ai.StageAnalysis, Template: "analysis", Prompt: "分析提示词", Reply: "RSI 75，超买"},
+				{Name: ai.StageDecision, Template: "decision", Prompt: "分析结论：RSI 75，超买", Reply: `{"action": "open_short", "stop_loss": 103}`},
+			},
+			Decision: &executor.Decision{Symbol: "ETHUSDT", Action: executor.ActionOpenShort, StopLoss: 103},
+		},
+		{AccountID: "account_1", Strategy: "short_term", Symbol: "ETHUSDT", Time: start.Add(150 * time.Minute), Mode: ai.ModeCached,
+			Decision: &executor.Decision{Symbol: "ETHUSDT", Action: executor.ActionOpenShort, StopLoss: 103}},
+	}
+	for _, rec := range records {
+		if err := audit.Record(rec); err != nil {
+			utils.Fatal("写入审计记录失败", zap.Error(err))
+		}
+	}
+	loaded, err := audit.Load("account_1")
+	if err != nil {
+		utils.Fatal("读取审计记录失败", zap.Error(err))
+	}
+
+	client := ai.NewClient(config.AIConfig{BaseURL: server.URL, APIKey: "test", Model: "new-model", TimeoutSec: 5}, "")
+	fmt.Println("===== 按原提示词回放 =====")
+	printReport(client.Replay(context.Background(), loaded, ai.ReplayOptions{}))
+	fmt.Println("\n===== 用新模板 detailed 回放（两阶段记录没有保存指标数据，跳过） =====")
+	printReport(client.Replay(context.Background(), loaded, ai.ReplayOptions{Template: "detailed", Store: store}))
+}
+
+// single 生成一条单次调用的审计记录
+func single(t time.Time, text string, data *indicators.ShortTermIndicators, decision *executor.Decision) *ai.AuditRecord {
+	raw, _ := json.Marshal(data)
+	return &ai.AuditRecord{
+		AccountID:     "account_1",
+		Strategy:      "short_term",
+		Symbol:        "ETHUSDT",
+		Time:          t,
+		Mode:          ai.ModeSingle,
+		Stages:        []*ai.Stage{{Name: ai.StageSingle, Template: "minimal", Prompt: text, Model: "old-model"}},
+		Decision:      decision,
+		IndicatorKind: indicators.Kind(data),
+		Indicators:    raw,
+	}
+}
+
+// printReport 输出回放结果
+func printReport(report *ai.ReplayReport) {
+	fmt.Printf("模型 %s：回放 %d 条，跳过 %d，失败 %d，动作相同 %d，改变 %d\n",
+		report.Model, report.Records, report.Skipped, report.Failed, report.SameAction, report.Changed)
+	transitions := make([]string, 0, len(report.Transitions))
+	for transition := range report.Transitions {
+		transitions = append(transitions, transition)
+	}
+	sort.Strings(transitions)
+	for _, transition := range transitions {
+		fmt.Printf("  %-25s %d\n", transition, report.Transitions[transition])
+	}
+	for _, r := range report.Results {
+		if r.Error != "" {
+			fmt.Printf("  %s %s 失败: %s\n", r.Time.Format("15:04"), r.Symbol, r.Error)
+			continue
+		}
+		fmt.Printf("  %s %s 改变=%v %v\n", r.Time.Format("15:04"), r.Symbol, r.Changed, r.Diff)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+AI流式回复测试程序
+
+测试内容：
+- 正常的流式回复：拼接内容分片，读取最后一个数据块中的token用量
+- 工具调用分片（参数分多个数据块到达）拼接后执行工具，再继续请求得到回复
+- 决策JSON输出完后接口停顿：超过 idle_timeout_sec 中断，使用部分内容（partial）
+- JSON输出到一半接口停顿：返回 ErrStreamInterrupted
+- 接口只发送心跳不结束：超过 timeout_sec 截止时间中断
+
+运行方式：
+
+	go run test/ai/test_stream.go
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"crypto-ai-trader/ai"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+)
+
+// chunk 构造一个SSE数据块
+func chunk(w http.ResponseWriter, v interface{}) {
+	data, _ := json.Marshal(v)
+	fmt.Fprintf(w, "data: %s\n\n", data)
+	w.(http.Flusher).Flush()
+}
+
+// content 内容分片
+func content(text string) map[string]interface{} {
+	return map[string]interface{}{"model": "mock-model", "choices": []interface{}{map[string]interface{}{"delta": map[string]string{"content": text}}}}
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== AI流式回复测试开始 ===")
+
+	decision := []string{`{"action": "open_long", `, `"stop_loss": 98, "confidence": 0.6, `, `"reason": "突破"}`}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		last := req.Messages[len(req.Messages)-1]
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		switch {
+		case last.Role == "tool":
+			fmt.Printf("  收到工具结果: %s\n", last.Content)
+			chunk(w, content(`{"action": "hold", "confidence": 0.5, "reason": "日线震荡"}`))
+		case last.Content == "normal":
+			for _, part := range decision {
+				chunk(w, content(part))
+				time.Sleep(100 * time.Millisecond)
+			}
+			chunk(w, map[string]interface{}{"choices": []interface{}{}, "usage": map[string]int{"prompt_tokens": 120, "completion_tokens": 25}})
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		case last.Content == "tools":
+			for _, args := range []string{`{"symbol": `, `"ETHUSDT", "interval": "1d"}`} {
+				delta := map[string]interface{}{"tool_calls": []interface{}{map[string]interface{}{
+					"index": 0, "id": "call_1", "function": map[string]string{"name": "get_klines", "arguments": args}}}}
+				chunk(w, map[string]interface{}{"choices": []interface{}{map[string]interface{}{"delta": delta}}})
+			}
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		case last.Content == "stall_after_json":
+			for _, part := range decision {
+				chunk(w, content(part))
+			}
+			chunk(w, content("\n\n理由说明："))
+			<-r.Context().Done()
+		case last.Content == "stall_mid_json":
+			chunk(w, content(decision[0]))
+			<-r.Context().Done()
+		case last.Content == "heartbeat":
+			for r.Context().Err() == nil {
+				fmt.Fprint(w, ": ping\n\n")
+				w.(http.Flusher).Flush()
+				time.Sleep(300 * time.Millisecond)
+			}
+		}
+	}))
+	defer server.Close()
+
+	client := ai.NewClient(config.AIConfig{BaseURL: server.URL, APIKey: "test", Model: "mock-model", TimeoutSec: 3, Stream: true, IdleTimeoutSec: 1}, "")
+	tools := []*ai.Tool{{
+		Name: "get_klines",
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			return fmt.Sprintf(`{"args": %s, "klines": []}`, args), nil
+		},
+	}}
+
+	for _, c := range []struct {
+		name   string
+		prompt string
+		tools  []*ai.Tool
+	}{
+		{"正常流式回复", "normal", nil},
+		{"工具调用分片", "tools", tools},
+		{"JSON输出完后停顿", "stall_after_json", nil},
+		{"JSON输出到一半停顿", "stall_mid_json", nil},
+		{"只有心跳不结束", "heartbeat", nil},
+	} {
+		start := time.Now()
+		resp, err := client.Complete(context.Background(), &ai.Request{Prompt: c.prompt, Tools: c.tools, MaxToolCalls: 3})
+		elapsed := time.Since(start).Round(100 * time.Millisecond)
+		if err != nil {
+			fmt.Printf("%s: 耗时 %s，错误: %v (中断=%v)\n\n", c.name, elapsed, err, errors.Is(err, ai.ErrStreamInterrupted))
+			continue
+		}
+		fmt.Printf("%s: 耗时 %s，回复 %s，partial=%v，token %d/%d，工具调用 %d 次\n\n",
+			c.name, elapsed, resp.Text, resp.Partial, resp.PromptTokens, resp.CompletionTokens, len(resp.ToolCalls))
+	}
+
+	utils.Info("=== AI流式回复测试完成 ===")
+}
@@ -0,0 +1,129 @@
+/*
+AI工具调用测试程序
+
+测试内容：
+- 本地模拟币安行情接口（日线K线、订单簿深度、资金费率历史），用 MarketTools 创建行情工具
+- 本地模拟的 /chat/completions 接口：第一轮请求日线K线和资金费率历史，第二轮请求不存在的工具，第三轮给出决策
+- 检查工具结果以 tool 消息加入对话、未知工具返回错误、token用量累加
+- 工具调用次数上限为1时，第二轮请求不再带工具，AI直接回复
+
+运行方式：
+
+	go run test/ai/test_tools.go
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"crypto-ai-trader/ai"
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// toolCall 构造回复中的工具调用
+func toolCall(id, name, args string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "type": "function", "function": map[string]string{"name": name, "arguments": args}}
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== AI工具调用测试开始 ===")
+
+	// 模拟币安行情接口
+	market := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case binance.EndpointKlines:
+			fmt.Fprint(w, `[[1700000000000,"100","110","95","108","1000",1700086399999,"105000",500,"600","63000","0"],
+				[1700086400000,"108","112","104","111","800",1700172799999,"88000",400,"450","49500","0"]]`)
+		case binance.EndpointDepth:
+			fmt.Fprint(w, `{"lastUpdateId": 1, "bids": [["110.9", "5"], ["110.8", "8"]], "asks": [["111.1", "4"], ["111.2", "9"]]}`)
+		case binance.EndpointFundingRate:
+			fmt.Fprint(w, `[{"symbol": "ETHUSDT", "fundingRate": "0.0001", "fundingTime": 1700000000000},
+				{"symbol": "ETHUSDT", "fundingRate": "0.0003", "fundingTime": 1700028800000}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer market.Close()
+	tools := ai.MarketTools(binance.NewClient("", "", market.URL, ""))
+
+	// 模拟AI接口：按对话中已有的工具结果数量决定下一步
+	aiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+			Tools []interface{} `json:"tools"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		toolResults := 0
+		for _, m := range req.Messages {
+			if m.Role == "tool" {
+				toolResults++
+			}
+		}
+		fmt.Printf("AI收到请求: %d 条消息，%d 个工具结果，提供工具 %d 个\n", len(req.Messages), toolResults, len(req.Tools))
+
+		message := map[string]interface{}{"role": "assistant"}
+		switch {
+		case len(req.Tools) > 0 && toolResults == 0:
+			message["tool_calls"] = []interface{}{
+				toolCall("call_1", "get_klines", `{"symbol": "ethusdt", "interval": "1d", "limit": 2}`),
+				toolCall("call_2", "get_funding_history", `{"symbol": "ETHUSDT"}`),
+			}
+		case len(req.Tools) > 0 && toolResults == 2:
+			message["tool_calls"] = []interface{}{toolCall("call_3", "get_news", `{}`)}
+		default:
+			message["content"] = `{"action": "open_long", "stop_loss": 104, "confidence": 0.6, "reason": "日线上涨，资金费率温和"}`
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":   "mock-model",
+			"choices": []map[string]interface{}{{"message": message}},
+			"usage":   map[string]int{"prompt_tokens": 100, "completion_tokens": 10},
+		})
+	}))
+	defer aiServer.Close()
+	client := ai.NewClient(config.AIConfig{BaseURL: aiServer.URL, APIKey: "test", Model: "mock-model", TimeoutSec: 5}, "")
+
+	resp, err := client.Complete(context.Background(), &ai.Request{Prompt: "分析 ETHUSDT", Tools: tools, MaxToolCalls: 5})
+	if err != nil {
+		utils.Fatal("AI请求失败", zap.Error(err))
+	}
+	fmt.Printf("\n最终回复: %s\n", resp.Text)
+	fmt.Printf("token: 提示词 %d，回复 %d (期望 300 / 30)\n", resp.PromptTokens, resp.CompletionTokens)
+	for _, call := range resp.ToolCalls {
+		fmt.Printf("  工具 %-20s 参数 %-55s 结果 %s 错误 %s\n", call.Name, call.Arguments, call.Result, call.Error)
+	}
+
+	// 订单簿工具直接调用
+	for _, tool := range tools {
+		if tool.Name == "get_order_book" {
+			result, err := tool.Handler(context.Background(), json.RawMessage(`{"symbol": "ETHUSDT", "limit": 7}`))
+			fmt.Printf("\n订单簿: %s %v\n", result, err)
+		}
+	}
+
+	// 工具调用次数上限
+	fmt.Println("\n工具调用次数上限为1：")
+	resp, err = client.Complete(context.Background(), &ai.Request{Prompt: "分析 ETHUSDT", Tools: tools, MaxToolCalls: 1})
+	if err != nil {
+		utils.Fatal("AI请求失败", zap.Error(err))
+	}
+	fmt.Printf("最终回复: %s，工具调用 %d 次\n", resp.Text, len(resp.ToolCalls))
+
+	utils.Info("=== AI工具调用测试完成 ===")
+}
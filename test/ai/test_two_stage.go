@@ -0,0 +1,158 @@
+/*
+两阶段分析测试程序
+
+测试内容：
+- 渲染 analysis 模板，发送到本地模拟的 /chat/completions 接口得到市场分析
+- 用分析结论和账户状态（含当前交易对持仓）渲染 decision 模板，得到决策回复
+- 解析决策：动作大小写、缺少交易对、交易对不一致、开仓缺少止损、置信度越界
+- 两个阶段和决策写入审计记录后重新读取
+
+运行方式：
+
+	go run test/ai/test_two_stage.go
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/ai"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/prompt"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 两阶段分析测试开始 ===")
+
+	store, err := prompt.NewStore("configs/prompts")
+	if err != nil {
+		utils.Fatal("加载提示词模板失败", zap.Error(err))
+	}
+
+	// 模拟AI接口：分析阶段返回文字分析，决策阶段返回JSON决策
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		reply := "1. 15m与1h均线多头排列\n2. RSI 58，MACD柱转正\n3. 支撑 99，阻力 104\n4. 最新收盘价 100"
+		if strings.Contains(req.Messages[len(req.Messages)-1].Content, "账户状态") {
+			reply = `决策如下：{"action": "OPEN_LONG", "stop_loss": 98, "take_profit": 105, "confidence": 0.7, "analyzed_price": 100, "reason": "趋势一致"}`
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":   "mock-model",
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": reply}}},
+			"usage":   map[string]int{"prompt_tokens": 200, "completion_tokens": 40},
+		})
+	}))
+	defer server.Close()
+	client := ai.NewClient(config.AIConfig{BaseURL: server.URL, APIKey: "test", Model: "mock-model", TimeoutSec: 5}, "")
+
+	// 阶段一：市场分析
+	now := time.Now()
+	data := &prompt.Data{
+		AccountID:    "account_1",
+		Strategy:     "short_term",
+		StrategyName: "短线",
+		Symbol:       "ETHUSDT",
+		Time:         now,
+		Indicators: &indicators.ShortTermIndicators{
+			Symbol:     "ETHUSDT",
+			Timeframes: &indicators.ShortTermTimeframes{M15: &indicators.TimeframeData{ClosePrice: 100, RSI: 58}},
+		},
+	}
+	text, err := store.Render("analysis", data)
+	if err != nil {
+		utils.Fatal("渲染分析提示词失败", zap.Error(err))
+	}
+	rec := &ai.AuditRecord{AccountID: "account_1", Strategy: "short_term", Symbol: "ETHUSDT", Time: now, Mode: ai.ModeTwoStage}
+	analysis, err := client.RunStage(context.Background(), ai.StageAnalysis, "analysis", &ai.Request{Prompt: text})
+	rec.Stages = append(rec.Stages, analysis)
+	if err != nil {
+		utils.Fatal("分析阶段失败", zap.Error(err))
+	}
+	fmt.Printf("===== 分析阶段回复 =====\n%s\n\n", analysis.Reply)
+
+	// 阶段二：结合账户状态决策
+	position := &prompt.PositionState{Symbol: "ETHUSDT", Side: "BUY", Quantity: 0.5, EntryPrice: 97, StopLoss: 95, TakeProfit: 104, OpenedAt: now.Add(-time.Hour)}
+	text, err = store.Render("decision", &prompt.DecisionData{
+		AccountID:    "account_1",
+		Strategy:     "short_term",
+		StrategyName: "短线",
+		Symbol:       "ETHUSDT",
+		Time:         now,
+		Analysis:     analysis.Reply,
+		Account:      &prompt.AccountState{Shadow: true, Balance: 10234.567, Position: position, Positions: []*prompt.PositionState{position}},
+	})
+	if err != nil {
+		utils.Fatal("渲染决策提示词失败", zap.Error(err))
+	}
+	fmt.Printf("===== 决策提示词 =====\n%s\n\n", text)
+
+	decisionStage, err := client.RunStage(context.Background(), ai.StageDecision, "decision", &ai.Request{Prompt: text})
+	rec.Stages = append(rec.Stages, decisionStage)
+	if err != nil {
+		utils.Fatal("决策阶段失败", zap.Error(err))
+	}
+	decision, err := ai.ParseDecision(decisionStage.Reply, "ETHUSDT")
+	if err != nil {
+		utils.Fatal("解析决策失败", zap.Error(err))
+	}
+	rec.Decision, rec.Result = decision, "opened"
+	fmt.Printf("解析决策: %s %s 止损 %v 置信度 %v (期望 ETHUSDT open_long 98 0.7)\n\n",
+		decision.Symbol, decision.Action, decision.StopLoss, decision.Confidence)
+
+	// 无效回复
+	for _, reply := range []string{
+		`{"symbol": "BTCUSDT", "action": "hold"}`,
+		`{"action": "open_short", "confidence": 0.6}`,
+		`{"action": "hold", "confidence": 80}`,
+		`{"action": "buy"}`,
+		`无法判断`,
+	} {
+		_, err := ai.ParseDecision(reply, "ETHUSDT")
+		fmt.Printf("%-45s → %v\n", reply, err)
+	}
+
+	// 审计记录
+	dir, err := os.MkdirTemp("", "ai-audit")
+	if err != nil {
+		utils.Fatal("创建临时目录失败", zap.Error(err))
+	}
+	defer os.RemoveAll(dir)
+	audit, err := ai.NewAudit(dir)
+	if err != nil {
+		utils.Fatal("创建审计记录失败", zap.Error(err))
+	}
+	if err := audit.Record(rec); err != nil {
+		utils.Fatal("写入审计记录失败", zap.Error(err))
+	}
+	records, err := audit.Load("account_1")
+	if err != nil {
+		utils.Fatal("读取审计记录失败", zap.Error(err))
+	}
+	fmt.Printf("\n审计记录: %d 条，模式 %s，阶段 %d 个（%s → %s），决策 %s，结果 %s\n",
+		len(records), records[0].Mode, len(records[0].Stages), records[0].Stages[0].Name, records[0].Stages[1].Name,
+		records[0].Decision.Action, records[0].Result)
+
+	utils.Info("=== 两阶段分析测试完成 ===")
+}
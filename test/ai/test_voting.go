@@ -0,0 +1,100 @@
+/*
+多次采样投票测试程序
+
+测试内容：
+- 本地模拟的 /chat/completions 接口按请求顺序返回不同的决策，检查采样请求使用了投票温度
+- 并发采样3次，2票开多、1票开空时采用开多，开空的采样标记为异议
+- 票数相同、同意票不足、回复无法解析时不采用任何决策
+
+运行方式：
+
+	go run test/ai/test_voting.go
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"crypto-ai-trader/ai"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 多次采样投票测试开始 ===")
+
+	// 模拟AI接口：按请求顺序依次返回 开多、开空、开多
+	replies := []string{
+		`{"action": "open_long", "stop_loss": 98, "confidence": 0.7, "reason": "突破"}`,
+		`{"action": "open_short", "stop_loss": 103, "confidence": 0.55, "reason": "超买"}`,
+		`{"action": "open_long", "stop_loss": 97.5, "confidence": 0.65, "reason": "趋势延续"}`,
+	}
+	var mu sync.Mutex
+	var temperatures []float64
+	next := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Temperature float64 `json:"temperature"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		temperatures = append(temperatures, req.Temperature)
+		reply := replies[next%len(replies)]
+		next++
+		mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":   "mock-model",
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	}))
+	defer server.Close()
+
+	client := ai.NewClient(config.AIConfig{BaseURL: server.URL, APIKey: "test", Model: "mock-model", TimeoutSec: 5, Temperature: 0.1}, "")
+	stages := client.SampleStage(context.Background(), ai.StageSingle, "minimal", &ai.Request{Prompt: "测试提示词"}, 3, 0.7)
+	decision, vote := ai.Vote(stages, "ETHUSDT", 2)
+	fmt.Printf("采样温度: %v (期望都为0.7)\n", temperatures)
+	fmt.Printf("票数: %v，胜出: %q，同意 %d/%d\n", vote.Counts, vote.Action, vote.Agree, vote.Samples)
+	if decision != nil {
+		fmt.Printf("采用决策: %s 止损 %v (%s)\n", decision.Action, decision.StopLoss, decision.Reason)
+	}
+	for _, stage := range stages {
+		fmt.Printf("  采样%d: %-10s 异议=%v\n", stage.Sample, stage.Action, stage.Dissent)
+	}
+
+	// 不采用决策的情况
+	cases := []struct {
+		name     string
+		replies  []string
+		required int
+	}{
+		{"票数相同", []string{`{"action": "open_long", "stop_loss": 1}`, `{"action": "hold"}`}, 1},
+		{"同意票不足", []string{`{"action": "open_long", "stop_loss": 1}`, `{"action": "open_long", "stop_loss": 1}`, `{"action": "hold"}`, `{"action": "close"}`}, 3},
+		{"无法解析", []string{`无法判断`, `{"action": "buy"}`, `{"action": "hold"}`}, 2},
+	}
+	for _, c := range cases {
+		stages := make([]*ai.Stage, len(c.replies))
+		for i, reply := range c.replies {
+			stages[i] = &ai.Stage{Sample: i + 1, Reply: reply}
+		}
+		decision, vote := ai.Vote(stages, "ETHUSDT", c.required)
+		fmt.Printf("%s: 票数 %v，有效 %d，达成=%v，决策=%v\n", c.name, vote.Counts, vote.Valid, vote.Agreed(), decision != nil)
+		for _, stage := range stages {
+			if stage.Error != "" {
+				fmt.Printf("  采样%d 解析失败: %s\n", stage.Sample, stage.Error)
+			}
+		}
+	}
+
+	utils.Info("=== 多次采样投票测试完成 ===")
+}
@@ -0,0 +1,129 @@
+/*
+行情告警测试程序
+
+测试内容：
+- 价格异动：K线涨跌幅度超过 atr_multiple 倍ATR 时告警，未超过时不告警
+- 持仓量异动：与窗口之前相比变化超过 oi_jump_pct 时告警，启动后不足一个窗口时不告警
+- 告警冷却：同一交易对同一类告警在冷却期内只发出一次，不同交易对互不影响
+- webhook推送：本地模拟webhook接收推送内容（不访问外部服务），查询最近的告警和统计
+
+运行方式：
+
+	go run test/alert/test_anomaly.go
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/alert"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 行情告警测试开始 ===")
+
+	detector := alert.NewAnomalyDetector(config.AnomalyConfig{
+		Enabled:         true,
+		ATRMultiple:     3,
+		OIJumpPct:       5,
+		OIWindowMinutes: 5,
+	})
+	start := time.Now()
+
+	// 1. 价格异动
+	calm := &indicators.TimeframeData{OpenPrice: 65000, ClosePrice: 65200, ATR: 150}
+	spike := &indicators.TimeframeData{OpenPrice: 65000, ClosePrice: 64400, ATR: 150}
+	events := detector.Check("BTCUSDT", "15m", calm, nil, start)
+	fmt.Printf("价格波动1.3倍ATR: 告警 %d 条（期望0）\n", len(events))
+	events = detector.Check("BTCUSDT", "15m", spike, nil, start)
+	fmt.Printf("价格下跌4倍ATR: 告警 %d 条（期望1）\n", len(events))
+	for _, e := range events {
+		fmt.Printf("  %s: %s\n", e.Kind, alert.Text(e))
+	}
+
+	// 2. 持仓量异动（每分钟检查一次）
+	oi := []float64{1000, 1005, 1010, 1008, 1012, 1015, 1030, 1080}
+	for i, v := range oi {
+		events = detector.Check("ETHUSDT", "15m", nil, &indicators.MarketData{OICurrent: v}, start.Add(time.Duration(i)*time.Minute))
+		for _, e := range events {
+			fmt.Printf("第%d分钟: %s\n", i, alert.Text(e))
+		}
+	}
+	fmt.Println("  期望只有第7分钟告警（与第2分钟相比增加约6.9%）")
+
+	// 3. 冷却与webhook推送
+	var mu sync.Mutex
+	var received []string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Text  string      `json:"text"`
+			Event alert.Event `json:"event"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, payload.Text)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	notifier := alert.NewNotifier(config.AlertsConfig{
+		Enabled:         true,
+		WebhookURL:      webhook.URL,
+		CooldownMinutes: 30,
+		History:         10,
+	}, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		notifier.Run(ctx)
+		close(done)
+	}()
+
+	event := alert.Event{Time: start, Kind: alert.KindPriceMove, Symbol: "BTCUSDT", Message: "测试告警"}
+	first := notifier.Notify(event)
+	event.Time = start.Add(10 * time.Minute)
+	second := notifier.Notify(event)
+	event.Symbol = "SOLUSDT"
+	other := notifier.Notify(event)
+	event.Symbol = "BTCUSDT"
+	event.Time = start.Add(31 * time.Minute)
+	after := notifier.Notify(event)
+	fmt.Printf("冷却: 首次=%v 10分钟后=%v 其他交易对=%v 31分钟后=%v（期望true false true true）\n", first, second, other, after)
+
+	time.Sleep(300 * time.Millisecond)
+	cancel()
+	<-done
+	mu.Lock()
+	fmt.Printf("webhook收到 %d 条（期望3）\n", len(received))
+	for _, text := range received {
+		fmt.Printf("  %s\n", text)
+	}
+	mu.Unlock()
+
+	recent := notifier.Recent(0, "", "BTCUSDT")
+	stats := notifier.Stats()
+	fmt.Printf("最近BTCUSDT告警 %d 条（期望2），统计 %+v（期望sent=3 suppressed=1 delivered=3）\n", len(recent), stats)
+
+	var none *alert.Notifier
+	fmt.Printf("nil告警: 发出=%v 最近 %d 条（期望false，0）\n", none.Notify(event), len(none.Recent(0, "", "")))
+
+	utils.Info("=== 行情告警测试完成 ===")
+}
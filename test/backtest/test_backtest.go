@@ -0,0 +1,175 @@
+/*
+回测模块测试程序
+
+测试内容：
+- 获取BTCUSDT最近1000根1小时K线和资金费率历史
+- 使用简单均线交叉决策运行回测
+- 对比价格盈亏与扣除手续费、资金费后的净盈亏
+- 输出夏普、索提诺、卡玛、盈利因子等绩效指标
+- 对交易序列做蒙特卡洛重采样，输出收益率和最大回撤分布
+- 固定随机种子加随机滑点运行两次，确认结果完全一致
+- 按 maker_first 入场（1秒延迟、排队、部分成交）重新回测，对比Maker占比、补齐次数和净盈亏
+- 导出亏损交易的图表数据到 data/backtest/losing_trades.json
+
+运行方式：
+  go run test/backtest/test_backtest.go
+*/
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/backtest"
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 回测模块测试开始 ===")
+
+	// 加载配置
+	cfg, err := config.Load("configs/config.yml")
+	if err != nil {
+		utils.Fatal("加载配置失败", zap.Error(err))
+	}
+
+	// 行情数据不需要API密钥
+	client := binance.NewClient("", "", cfg.Binance.FuturesURL, cfg.GetProxyURL())
+
+	symbol := "BTCUSDT"
+	klines, err := client.GetKlines(symbol, "1h", 1000)
+	if err != nil {
+		utils.Fatal("获取K线失败", zap.Error(err))
+	}
+	funding, err := client.GetFundingRateHistory(symbol, 1000)
+	if err != nil {
+		utils.Fatal("获取资金费率失败", zap.Error(err))
+	}
+
+	btCfg := backtest.DefaultConfig()
+	btCfg.SlippageJitterPct = 0.05
+	btCfg.Seed = 42
+	result, err := backtest.Run(btCfg, symbol, klines, funding, smaCross(10, 30, 0.01))
+	if err != nil {
+		utils.Fatal("回测失败", zap.Error(err))
+	}
+
+	// 相同种子再跑一次，结果必须完全一致
+	rerun, err := backtest.Run(btCfg, symbol, klines, funding, smaCross(10, 30, 0.01))
+	if err != nil {
+		utils.Fatal("回测失败", zap.Error(err))
+	}
+	if rerun.FinalBalance != result.FinalBalance || rerun.ConfigHash != result.ConfigHash {
+		utils.Fatal("相同种子的回测结果不一致",
+			zap.Float64("first", result.FinalBalance),
+			zap.Float64("second", rerun.FinalBalance),
+		)
+	}
+
+	s := result.Summary
+	fmt.Println("【回测结果】")
+	fmt.Printf("  随机种子:   %d, 配置哈希 %s\n", result.Seed, result.ConfigHash)
+	fmt.Printf("  交易笔数:   %d (盈利 %d, 胜率 %.1f%%)\n", s.Trades, s.Wins, s.WinRate*100)
+	fmt.Printf("  价格盈亏:   %.2f USDT\n", s.GrossPnL)
+	fmt.Printf("  手续费:     %.2f USDT\n", s.Commission)
+	fmt.Printf("  资金费:     %.2f USDT\n", s.Funding)
+	fmt.Printf("  净盈亏:     %.2f USDT\n", s.NetPnL)
+	fmt.Printf("  费用占比:   %.1f%%\n", s.FeeDrag*100)
+	fmt.Printf("  最终资金:   %.2f USDT\n", result.FinalBalance)
+
+	m := result.Metrics
+	fmt.Println("\n【绩效指标】")
+	fmt.Printf("  收益率:     %.2f%% (年化 %.2f%%), 最大回撤 %.2f%%\n", m.TotalReturnPct, m.AnnualReturnPct, m.MaxDrawdownPct)
+	fmt.Printf("  夏普/索提诺/卡玛: %.2f / %.2f / %.2f\n", m.Sharpe, m.Sortino, m.Calmar)
+	fmt.Printf("  盈利因子:   %.2f, 期望值 %.2f USDT/笔\n", m.ProfitFactor, m.Expectancy)
+	fmt.Printf("  平均持仓:   %.1f小时, 持仓时间占比 %.1f%%\n", m.AvgHoldingHours, m.ExposurePct)
+
+	mc, err := backtest.MonteCarlo(result, backtest.DefaultMonteCarloConfig())
+	if err != nil {
+		utils.Warn("蒙特卡洛分析跳过", zap.Error(err))
+	} else {
+		fmt.Printf("\n【蒙特卡洛（%d次，跳过10%%，滑点0.05%%）】\n", mc.Iterations)
+		fmt.Printf("  原始路径:   收益 %.2f%%, 最大回撤 %.2f%%\n", mc.OriginalReturn, mc.OriginalMaxDrawdown)
+		fmt.Printf("  收益率:     P5 %.2f%% / 中位数 %.2f%% / P95 %.2f%%\n", mc.Return.P5, mc.Return.Median, mc.Return.P95)
+		fmt.Printf("  最大回撤:   中位数 %.2f%% / P95 %.2f%% / 最大 %.2f%%\n", mc.MaxDrawdown.Median, mc.MaxDrawdown.P95, mc.MaxDrawdown.Max)
+		fmt.Printf("  亏损概率:   %.1f%%, 回撤超过原始路径: %.1f%%\n", mc.ProbLoss*100, mc.ProbWorseDrawdown*100)
+	}
+
+	// maker_first 入场：每次挂单等1根K线，最多改价2次，之后市价补齐
+	makerCfg := btCfg
+	makerCfg.Fill = backtest.FillConfigFromExecution(config.ExecutionConfig{
+		EntryType:       config.EntryTypeMakerFirst,
+		LimitTimeoutSec: 3600,
+		MaxReprices:     2,
+		Fallback:        config.FallbackMarket,
+	}, time.Hour)
+	makerCfg.Fill.LatencyMs = 1000
+	makerResult, err := backtest.Run(makerCfg, symbol, klines, funding, smaCross(10, 30, 0.01))
+	if err != nil {
+		utils.Fatal("maker_first回测失败", zap.Error(err))
+	}
+	f := makerResult.Fills
+	fmt.Println("\n【maker_first 入场对比】")
+	fmt.Printf("  订单:       %d, 部分成交 %d, 改价 %d 次, 市价补齐 %d 次\n", f.Orders, f.PartialOrders, f.Reprices, f.Fallbacks)
+	fmt.Printf("  成交率:     %.1f%%, Maker占比 %.1f%%\n", f.FillRate*100, f.MakerRatio*100)
+	fmt.Printf("  手续费:     %.2f USDT (市价入场 %.2f USDT)\n", makerResult.Summary.Commission, s.Commission)
+	fmt.Printf("  净盈亏:     %.2f USDT (市价入场 %.2f USDT)\n", makerResult.Summary.NetPnL, s.NetPnL)
+
+	charts := backtest.TradeCharts(result, klines, backtest.ChartOptions{LosingOnly: true})
+	if err := backtest.ExportTradeCharts("data/backtest/losing_trades.json", charts); err != nil {
+		utils.Warn("导出交易图表失败", zap.Error(err))
+	} else {
+		fmt.Printf("\n已导出 %d 笔亏损交易的图表数据: data/backtest/losing_trades.json\n", len(charts))
+	}
+
+	utils.Info("=== 回测模块测试完成 ===")
+}
+
+// smaCross 简单均线交叉决策：快线上穿慢线开多，下穿开空，止损止盈按入场价的百分比
+func smaCross(fast, slow int, qty float64) backtest.DecideFunc {
+	return func(symbol string, history []binance.Kline) *executor.Decision {
+		if len(history) < slow+1 {
+			return nil
+		}
+		closes := make([]float64, len(history))
+		for i, k := range history {
+			closes[i], _ = strconv.ParseFloat(k.Close, 64)
+		}
+
+		prevFast, prevSlow := sma(closes[:len(closes)-1], fast), sma(closes[:len(closes)-1], slow)
+		curFast, curSlow := sma(closes, fast), sma(closes, slow)
+		price := closes[len(closes)-1]
+		last := history[len(history)-1]
+
+		switch {
+		case prevFast <= prevSlow && curFast > curSlow:
+			return &executor.Decision{Symbol: symbol, Action: executor.ActionOpenLong, Quantity: qty,
+				StopLoss: price * 0.98, TakeProfit: price * 1.04, Timestamp: last.CloseTime}
+		case prevFast >= prevSlow && curFast < curSlow:
+			return &executor.Decision{Symbol: symbol, Action: executor.ActionOpenShort, Quantity: qty,
+				StopLoss: price * 1.02, TakeProfit: price * 0.96, Timestamp: last.CloseTime}
+		}
+		return nil
+	}
+}
+
+// sma 最后period个值的简单均值
+func sma(values []float64, period int) float64 {
+	var sum float64
+	for _, v := range values[len(values)-period:] {
+		sum += v
+	}
+	return sum / float64(period)
+}
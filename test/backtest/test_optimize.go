@@ -0,0 +1,75 @@
+/*
+参数网格搜索测试程序
+
+测试内容：
+- 加载参数优化配置（默认 configs/optimize.example.yml）
+- 获取配置中交易对的K线和资金费率历史
+- 按样本内/样本外切分，并行回测全部参数组合
+- 输出按样本内得分排序的结果表和过拟合警告
+
+运行方式：
+  go run test/backtest/test_optimize.go [参数优化配置文件]
+*/
+package main
+
+import (
+	"os"
+
+	"crypto-ai-trader/backtest"
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	optimizePath := "configs/optimize.example.yml"
+	if len(os.Args) > 1 {
+		optimizePath = os.Args[1]
+	}
+
+	cfg, err := config.Load("configs/config.yml")
+	if err != nil {
+		utils.Fatal("加载配置失败", zap.Error(err))
+	}
+	optCfg, err := config.LoadOptimize(optimizePath)
+	if err != nil {
+		utils.Fatal("加载参数优化配置失败", zap.Error(err))
+	}
+
+	// 行情数据不需要API密钥
+	client := binance.NewClient("", "", cfg.Binance.FuturesURL, cfg.GetProxyURL())
+
+	klines, err := client.GetKlines(optCfg.Symbol, optCfg.Interval, optCfg.Limit)
+	if err != nil {
+		utils.Fatal("获取K线失败", zap.Error(err))
+	}
+	funding, err := client.GetFundingRateHistory(optCfg.Symbol, 1000)
+	if err != nil {
+		utils.Fatal("获取资金费率失败", zap.Error(err))
+	}
+
+	combinations := len(backtest.Grid(optCfg.Params))
+	utils.Info("开始参数网格搜索",
+		zap.String("strategy", optCfg.Strategy),
+		zap.String("symbol", optCfg.Symbol),
+		zap.Int("klines", len(klines)),
+		zap.Int("combinations", combinations),
+	)
+
+	result, err := backtest.Optimize(*optCfg, klines, funding)
+	if err != nil {
+		utils.Fatal("参数优化失败", zap.Error(err))
+	}
+
+	backtest.WriteTable(os.Stdout, result, optCfg.Top)
+
+	utils.Info("=== 参数网格搜索完成 ===")
+}
@@ -0,0 +1,111 @@
+/*
+公开行情请求合并测试程序
+
+测试内容：
+- 本地模拟币安接口（每个请求延迟200ms，统计收到的请求数，不访问交易所）
+- 5个客户端（模拟5个账号）同时请求相同交易对的K线和持仓量，每种数据只发送一次请求
+- 参数不同的请求（不同交易对）分别发送
+- 前一批请求完成后再次请求会重新发送（只合并进行中的请求，不缓存）
+
+运行方式：
+
+	go run test/binance/test_coalesce.go
+*/
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 公开行情请求合并测试开始 ===")
+
+	var received atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		time.Sleep(200 * time.Millisecond)
+		switch r.URL.Path {
+		case binance.EndpointKlines:
+			fmt.Fprint(w, `[[1700000000000,"100","101","99","100.5","10",1700000059999,"1005",5,"4","402"]]`)
+		case binance.EndpointOpenInterest:
+			fmt.Fprintf(w, `{"symbol":"%s","openInterest":"1234.5","time":1700000000000}`, r.URL.Query().Get("symbol"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	clients := make([]*binance.Client, 5)
+	for i := range clients {
+		clients[i] = binance.NewClient(fmt.Sprintf("key_%d", i), "secret", srv.URL, "")
+	}
+
+	// 1. 所有账号同时请求相同数据
+	before := binance.GetCoalesceStats()
+	fetchAll(clients, "BTCUSDT")
+	after := binance.GetCoalesceStats()
+	fmt.Printf("相同交易对: 调用 %d 次，服务端收到 %d 个请求，合并 %d 次（期望收到2个：K线、持仓量各1个）\n",
+		len(clients)*2, received.Load(), after.Shared-before.Shared)
+
+	// 2. 不同交易对分别请求
+	received.Store(0)
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		wg.Add(1)
+		go func(client *binance.Client, symbol string) {
+			defer wg.Done()
+			if _, err := client.GetOpenInterest(symbol); err != nil {
+				fmt.Printf("请求失败: %v\n", err)
+			}
+		}(client, fmt.Sprintf("SYM%dUSDT", i))
+	}
+	wg.Wait()
+	fmt.Printf("不同交易对: 服务端收到 %d 个请求（期望5个）\n", received.Load())
+
+	// 3. 请求完成后再次请求
+	received.Store(0)
+	fetchAll(clients[:1], "BTCUSDT")
+	fmt.Printf("再次请求: 服务端收到 %d 个请求（期望2个，不缓存已完成的响应）\n", received.Load())
+
+	stats := binance.GetCoalesceStats()
+	fmt.Printf("累计: 发出 %d 个请求，合并 %d 次\n", stats.Requests, stats.Shared)
+
+	utils.Info("=== 公开行情请求合并测试完成 ===")
+}
+
+// fetchAll 所有客户端同时获取交易对的K线和持仓量
+func fetchAll(clients []*binance.Client, symbol string) {
+	var wg sync.WaitGroup
+	for _, client := range clients {
+		wg.Add(2)
+		go func(client *binance.Client) {
+			defer wg.Done()
+			klines, err := client.GetKlines(symbol, "5m", 100)
+			if err != nil || len(klines) != 1 {
+				fmt.Printf("获取K线失败: %v\n", err)
+			}
+		}(client)
+		go func(client *binance.Client) {
+			defer wg.Done()
+			oi, err := client.GetOpenInterest(symbol)
+			if err != nil || oi.Symbol != symbol {
+				fmt.Printf("获取持仓量失败: %v\n", err)
+			}
+		}(client)
+	}
+	wg.Wait()
+}
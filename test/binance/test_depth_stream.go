@@ -0,0 +1,295 @@
+/*
+本地订单簿测试程序
+
+测试内容：
+- 本地模拟币安深度快照接口和组合推送（/stream?streams=，不访问交易所），每100ms推送一次增量深度
+- 快照之前的推送被丢弃，之后按更新ID衔接应用，OrderBook/BestBidAsk 返回最新盘口
+- 单连接上限为2时，第3个交易对自动新建连接；连接已建立时新增交易对通过SUBSCRIBE订阅
+- 推送不连续时只重新获取该交易对的快照，不断开连接
+- 取消不需要的交易对（UNSUBSCRIBE），连接上的交易对全部取消时关闭连接
+- 服务端断开连接后按退避时间重连，重连地址包含该连接的全部交易对，重新同步
+
+运行方式：
+
+	go run test/binance/test_depth_stream.go
+*/
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+)
+
+// mockServer 模拟的币安深度服务
+type mockServer struct {
+	mu      sync.Mutex
+	ids     map[string]int64             // 交易对 -> 最新更新ID
+	conns   map[net.Conn]map[string]bool // 连接 -> 订阅的推送
+	gap     map[string]bool              // 下一条推送制造不连续
+	methods []string                     // 收到的订阅请求
+
+	connections atomic.Int64
+	snapshots   atomic.Int64
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 本地订单簿测试开始 ===")
+
+	mock := &mockServer{ids: map[string]int64{"BTCUSDT": 100, "ETHUSDT": 100, "SOLUSDT": 100}, conns: make(map[net.Conn]map[string]bool), gap: make(map[string]bool)}
+	mux := http.NewServeMux()
+	mux.HandleFunc(binance.EndpointDepth, mock.serveSnapshot)
+	mux.HandleFunc("/stream", mock.serveStream)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mock.push(ctx)
+
+	client := binance.NewClient("", "", srv.URL, "")
+	books := binance.NewDepthStream(client, "ws://"+strings.TrimPrefix(srv.URL, "http://"), "", 2)
+	books.SetSymbols("account_1", []string{"BTCUSDT"})
+	go books.Run(ctx)
+
+	_, ok := books.OrderBook("BTCUSDT", 5)
+	fmt.Printf("同步前: ok=%v（期望false）\n", ok)
+
+	// 1. 同步完成并应用增量
+	time.Sleep(600 * time.Millisecond)
+	book, ok := books.OrderBook("BTCUSDT", 5)
+	bid, ask, _ := books.BestBidAsk("BTCUSDT")
+	fmt.Printf("同步后: ok=%v 买一=%.1f 卖一=%.1f（期望true，买一100.1（推送新增），卖一100.3（100.2被推送删除））\n", ok, bid, ask)
+	if ok {
+		fmt.Printf("  买单: %v\n  卖单: %v\n", book.Bids, book.Asks)
+		est := book.EstimateFill(binance.SideBuy, 3)
+		fmt.Printf("  买入3个的预估成交均价 %.4f，滑点 %.1fbps\n", est.AvgPrice, est.SlippageBps)
+	}
+
+	// 2. 新增交易对：ETH装入第一个连接（SUBSCRIBE），SOL超过单连接上限新建连接
+	books.SetSymbols("account_2", []string{"ETHUSDT", "SOLUSDT"})
+	time.Sleep(600 * time.Millisecond)
+	printStatus(books)
+	fmt.Printf("  连接数 %d，服务端收到连接 %d 次，订阅请求 %v（期望2，2，[SUBSCRIBE ethusdt@depth@100ms]）\n",
+		books.Connections(), mock.connections.Load(), mock.requests())
+
+	// 3. BTC推送不连续：只重新同步BTC，不断开连接
+	mock.setGap("BTCUSDT")
+	time.Sleep(300 * time.Millisecond)
+	_, ok = books.OrderBook("BTCUSDT", 5)
+	_, ethOK := books.OrderBook("ETHUSDT", 5)
+	fmt.Printf("推送不连续: BTC ok=%v ETH ok=%v（期望false，true）\n", ok, ethOK)
+	time.Sleep(1500 * time.Millisecond)
+	_, ok = books.OrderBook("BTCUSDT", 5)
+	fmt.Printf("重新同步后: BTC ok=%v 服务端收到连接 %d 次（期望true，仍为2次）\n", ok, mock.connections.Load())
+
+	// 4. 取消ETH、SOL：ETH取消订阅，SOL的连接关闭
+	books.SetSymbols("account_2", nil)
+	time.Sleep(300 * time.Millisecond)
+	fmt.Printf("取消订阅后: 连接数 %d 交易对数 %d 订阅请求 %v（期望1，1，增加UNSUBSCRIBE ethusdt@depth@100ms）\n",
+		books.Connections(), len(books.Status()), mock.requests())
+
+	// 5. 服务端断开连接后重连
+	mock.dropAll()
+	time.Sleep(200 * time.Millisecond)
+	_, ok = books.OrderBook("BTCUSDT", 5)
+	fmt.Printf("断开后: BTC ok=%v（期望false）\n", ok)
+	time.Sleep(2 * time.Second)
+	_, ok = books.OrderBook("BTCUSDT", 5)
+	fmt.Printf("重连后: BTC ok=%v 服务端收到连接 %d 次，快照请求 %d 次（期望true，3次，5次）\n",
+		ok, mock.connections.Load(), mock.snapshots.Load())
+
+	// 6. 全部停止订阅
+	books.SetSymbols("account_1", nil)
+	_, ok = books.OrderBook("BTCUSDT", 5)
+	fmt.Printf("停止订阅后: ok=%v 交易对数 %d 连接数 %d（期望false，0，0）\n", ok, len(books.Status()), books.Connections())
+
+	utils.Info("=== 本地订单簿测试完成 ===")
+}
+
+// printStatus 打印各交易对的同步状态和所在连接
+func printStatus(books *binance.DepthStream) {
+	for _, status := range books.Status() {
+		fmt.Printf("  %s synced=%v shard=%d resyncs=%d\n", status.Symbol, status.Synced, status.Shard, status.Resyncs)
+	}
+}
+
+// serveSnapshot 订单簿快照：lastUpdateId落在下一条推送的更新ID范围内
+func (m *mockServer) serveSnapshot(w http.ResponseWriter, r *http.Request) {
+	m.snapshots.Add(1)
+	m.mu.Lock()
+	id := m.ids[r.URL.Query().Get("symbol")] + 5
+	m.mu.Unlock()
+	fmt.Fprintf(w, `{"lastUpdateId":%d,"bids":[["100.0","2"],["99.9","5"],["99.8","10"]],"asks":[["100.2","1"],["100.3","4"],["100.4","8"]]}`, id)
+}
+
+// serveStream 组合推送连接：地址中的推送立即生效，之后处理客户端的订阅请求
+func (m *mockServer) serveStream(w http.ResponseWriter, r *http.Request) {
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	m.connections.Add(1)
+
+	sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(sum[:]))
+	rw.Flush()
+
+	streams := make(map[string]bool)
+	for _, stream := range strings.Split(r.URL.Query().Get("streams"), "/") {
+		streams[stream] = true
+	}
+	m.mu.Lock()
+	m.conns[conn] = streams
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.conns, conn)
+		m.mu.Unlock()
+	}()
+
+	for {
+		opcode, payload, err := readClientFrame(rw.Reader)
+		if err != nil {
+			return
+		}
+		if opcode != 0x1 {
+			continue
+		}
+		var req struct {
+			Method string   `json:"method"`
+			Params []string `json:"params"`
+			ID     int64    `json:"id"`
+		}
+		if json.Unmarshal(payload, &req) != nil {
+			continue
+		}
+		m.mu.Lock()
+		for _, stream := range req.Params {
+			streams[stream] = req.Method == "SUBSCRIBE"
+			m.methods = append(m.methods, req.Method+" "+stream)
+		}
+		writeFrame(conn, []byte(fmt.Sprintf(`{"result":null,"id":%d}`, req.ID)))
+		m.mu.Unlock()
+	}
+}
+
+// push 每100ms为每个交易对生成一条增量深度（更新ID每次增加10），推送给订阅的连接
+func (m *mockServer) push(ctx context.Context) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.Lock()
+		for _, symbol := range []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"} {
+			prev := m.ids[symbol]
+			id := prev + 10
+			m.ids[symbol] = id
+			pu := prev
+			if m.gap[symbol] {
+				pu = prev - 1
+				delete(m.gap, symbol)
+			}
+			stream := strings.ToLower(symbol) + "@depth@100ms"
+			message := fmt.Sprintf(`{"stream":"%s","data":{"e":"depthUpdate","s":"%s","U":%d,"u":%d,"pu":%d,"b":[["100.1","3"]],"a":[["100.2","0"]]}}`,
+				stream, symbol, prev+1, id, pu)
+			for conn, streams := range m.conns {
+				if streams[stream] {
+					writeFrame(conn, []byte(message))
+				}
+			}
+		}
+		m.mu.Unlock()
+	}
+}
+
+// setGap 下一条推送的pu与上一条u不一致
+func (m *mockServer) setGap(symbol string) {
+	m.mu.Lock()
+	m.gap[symbol] = true
+	m.mu.Unlock()
+}
+
+// dropAll 断开所有连接
+func (m *mockServer) dropAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for conn := range m.conns {
+		conn.Close()
+	}
+}
+
+// requests 收到的订阅请求
+func (m *mockServer) requests() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.methods...)
+}
+
+// readClientFrame 读取一个客户端帧（客户端帧带掩码）
+func readClientFrame(reader *bufio.Reader) (byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return 0, nil, err
+	}
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(reader, ext); err != nil {
+			return 0, nil, err
+		}
+		length = int(binary.BigEndian.Uint16(ext))
+	}
+	mask := make([]byte, 4)
+	if _, err := io.ReadFull(reader, mask); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return header[0] & 0x0F, payload, nil
+}
+
+// writeFrame 发送一个文本帧（服务端帧不加掩码）
+func writeFrame(conn net.Conn, payload []byte) {
+	frame := []byte{0x81}
+	if n := len(payload); n < 126 {
+		frame = append(frame, byte(n))
+	} else {
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	}
+	conn.Write(append(frame, payload...))
+}
@@ -0,0 +1,92 @@
+/*
+币安接口错误类型测试程序
+
+测试内容：
+- 本地模拟币安接口返回错误（不访问交易所）
+- 接口错误为 *APIError：HTTP状态码、币安错误码、说明，错误信息格式不变
+- errors.Is 判断常见错误：余额不足、时间戳超出recvWindow、条件单会立即触发、频率超限、订单不存在
+- 包装后的错误同样可以判断；按客户端订单ID查询不存在的订单返回 (nil, nil)
+
+运行方式：
+
+	go run test/binance/test_errors.go
+*/
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+)
+
+// 模拟错误：交易对 -> HTTP状态码、响应体
+var mockErrors = map[string]struct {
+	status int
+	body   string
+}{
+	"MARGIN": {400, `{"code":-2019,"msg":"Margin is insufficient."}`},
+	"TIME":   {400, `{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`},
+	"STOP":   {400, `{"code":-2021,"msg":"Order would immediately trigger."}`},
+	"LIMIT":  {429, `{"code":-1003,"msg":"Too many requests."}`},
+	"BANNED": {418, `{"code":-1003,"msg":"Way too many requests; IP banned."}`},
+	"GONE":   {400, `{"code":-2013,"msg":"Order does not exist."}`},
+	"HTML":   {502, `<html>Bad Gateway</html>`},
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 币安接口错误类型测试开始 ===")
+
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := mockErrors[r.URL.Query().Get("symbol")]
+		w.WriteHeader(e.status)
+		w.Write([]byte(e.body))
+	}))
+	defer mock.Close()
+
+	client := binance.NewClient("key", "secret", mock.URL, "")
+
+	// 1. 错误类型和常见错误
+	sentinels := map[string]error{
+		"余额不足":  binance.ErrInsufficientBalance,
+		"时间戳":   binance.ErrInvalidTimestamp,
+		"立即触发":  binance.ErrWouldTrigger,
+		"频率超限":  binance.ErrRateLimited,
+		"订单不存在": binance.ErrOrderNotFound,
+	}
+	for _, symbol := range []string{"MARGIN", "TIME", "STOP", "LIMIT", "BANNED", "HTML"} {
+		_, err := client.GetOrder(symbol, 1) // GetOrder 包装了错误
+		var apiErr *binance.APIError
+		if !errors.As(err, &apiErr) {
+			fmt.Printf("%s: 不是 *APIError: %v\n", symbol, err)
+			continue
+		}
+		var matched []string
+		for name, sentinel := range sentinels {
+			if errors.Is(err, sentinel) {
+				matched = append(matched, name)
+			}
+		}
+		fmt.Printf("%s: HTTP %d，错误码 %d（ErrorCode %d），匹配 %v\n", symbol, apiErr.StatusCode, apiErr.Code, binance.ErrorCode(err), matched)
+	}
+	fmt.Println("  期望 MARGIN余额不足、TIME时间戳、STOP立即触发、LIMIT和BANNED频率超限、HTML错误码0且不匹配")
+
+	// 2. 错误信息格式
+	_, err := client.GetOrder("HTML", 1)
+	fmt.Printf("错误信息: %v\n", err)
+
+	// 3. 订单不存在
+	order, err := client.GetOrderByClientID("GONE", "x")
+	fmt.Printf("按客户端订单ID查询不存在的订单: %v %v（期望<nil> <nil>）\n", order, err)
+
+	utils.Info("=== 币安接口错误类型测试完成 ===")
+}
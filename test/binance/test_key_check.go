@@ -0,0 +1,81 @@
+/*
+API密钥权限检查测试程序
+
+测试内容：
+- 本地模拟币安API密钥权限接口（不访问交易所），按API Key返回不同的权限
+- GetAPIRestrictions：只能通过现货客户端查询
+- CheckKey：合约账号缺少合约交易权限、开启提现权限、未开启IP限制、资金调拨缺少万向划转权限时报告不通过
+- 密钥无效或IP不在白名单（-2015）时报告带上交易所的说明，签名错误（-1022）提示检查API Secret
+
+运行方式：
+
+	go run test/binance/test_key_check.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== API密钥权限检查测试开始 ===")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sapi/v1/account/apiRestrictions", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("X-MBX-APIKEY") {
+		case "good":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"ipRestrict": true, "enableReading": true, "enableFutures": true, "permitsUniversalTransfer": true,
+			})
+		case "risky":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"ipRestrict": false, "enableReading": true, "enableSpotAndMarginTrading": true, "enableWithdrawals": true,
+			})
+		case "bad-secret":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-1022,"msg":"Signature for this request is not valid."}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":-2015,"msg":"Invalid API-key, IP, or permissions for action, request ip: 203.0.113.7"}`))
+		}
+	})
+	mock := httptest.NewServer(mux)
+	defer mock.Close()
+
+	// 1. 只支持现货客户端
+	_, err := binance.NewClient("good", "secret", mock.URL, "").GetAPIRestrictions()
+	fmt.Printf("合约客户端查询: %v（期望不支持）\n", err)
+	r, err := binance.NewSpotClient("good", "secret", mock.URL, "").GetAPIRestrictions()
+	fmt.Printf("现货客户端查询: IP限制 %v 合约 %v 错误 %v（期望true true <nil>）\n", r.IPRestrict, r.EnableFutures, err)
+
+	// 2. 各种密钥的检查报告
+	strict := binance.KeyRequirement{MarketType: binance.MarketTypeUSDTM, Transfer: true, RequireIPRestrict: true}
+	for _, c := range []struct {
+		key    string
+		req    binance.KeyRequirement
+		expect string
+	}{
+		{"good", strict, "通过"},
+		{"risky", strict, "4项：合约交易、提现、IP限制、万向划转"},
+		{"risky", binance.KeyRequirement{MarketType: binance.MarketTypeSpot, AllowWithdrawals: true}, "通过（现货账号，允许提现，不要求IP限制）"},
+		{"bad-secret", strict, "签名无效"},
+		{"unknown", strict, "IP不在白名单，含 request ip"},
+	} {
+		report := binance.CheckKey(binance.NewSpotClient(c.key, "secret", mock.URL, ""), c.key, c.req)
+		fmt.Printf("%s（期望%s）\n", report.String(), c.expect)
+	}
+
+	utils.Info("=== API密钥权限检查测试完成 ===")
+}
@@ -0,0 +1,117 @@
+/*
+API密钥轮换测试程序
+
+测试内容：
+- 本地模拟币安余额接口（不访问交易所），按请求头的API Key对应的Secret校验签名
+- 并发请求过程中轮换密钥：每个请求的API Key和签名都属于同一组密钥（没有签名错误）
+- KeyRing：密钥未变化时不替换，检查函数返回错误时全部客户端保留旧密钥，通过后全部客户端一起替换
+
+运行方式：
+
+	go run test/binance/test_key_rotation.go
+*/
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== API密钥轮换测试开始 ===")
+
+	secrets := map[string]string{"OLDKEY-0001-AAAA": "old-secret", "NEWKEY-0002-BBBB": "new-secret"}
+	var mu sync.Mutex
+	used := make(map[string]int)
+	var badSignatures atomic.Int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fapi/v2/balance", func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-MBX-APIKEY")
+		query, signature, _ := strings.Cut(r.URL.RawQuery, "&signature=")
+		h := hmac.New(sha256.New, []byte(secrets[key]))
+		h.Write([]byte(query))
+		if hex.EncodeToString(h.Sum(nil)) != signature {
+			badSignatures.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-1022,"msg":"Signature for this request is not valid."}`))
+			return
+		}
+		mu.Lock()
+		used[key]++
+		mu.Unlock()
+		json.NewEncoder(w).Encode([]map[string]string{{"asset": "USDT", "balance": "100", "availableBalance": "100"}})
+	})
+	mock := httptest.NewServer(mux)
+	defer mock.Close()
+
+	futures := binance.NewClient("OLDKEY-0001-AAAA", "old-secret", mock.URL, "")
+	spot := binance.NewSpotClient("OLDKEY-0001-AAAA", "old-secret", mock.URL, "")
+
+	keys := binance.NewKeyRing(futures)
+	keys.Add(spot)
+
+	// 1. 密钥未变化
+	changed, err := keys.Rotate("OLDKEY-0001-AAAA", "old-secret")
+	fmt.Printf("密钥未变化: %v %v（期望false <nil>）\n", changed, err)
+	_, err = keys.Rotate("", "x")
+	fmt.Printf("空密钥: %v（期望不能为空）\n", err)
+
+	// 2. 检查未通过时保留旧密钥
+	keys.SetVerifier(func(apiKey, apiSecret string) error {
+		if apiKey != "NEWKEY-0002-BBBB" {
+			return errors.New("未开启合约交易权限")
+		}
+		return nil
+	})
+	changed, err = keys.Rotate("BADKEY-0003-CCCC", "bad-secret")
+	fmt.Printf("检查未通过: %v %v，仍使用旧密钥 %v（期望false 新密钥检查未通过 true）\n", changed, err, futures.HasCredentials("OLDKEY-0001-AAAA", "old-secret"))
+
+	// 3. 并发请求过程中轮换
+	var wg sync.WaitGroup
+	var failed atomic.Int64
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				if _, err := futures.GetAssetBalance("USDT"); err != nil {
+					failed.Add(1)
+				}
+			}
+		}()
+	}
+	changed, err = keys.Rotate("NEWKEY-0002-BBBB", "new-secret")
+	wg.Wait()
+	fmt.Printf("轮换: %v %v，使用新密钥 %v（期望true <nil> true）\n", changed, err, futures.HasCredentials("NEWKEY-0002-BBBB", "new-secret"))
+	fmt.Printf("并发请求: 失败 %d 签名错误 %d（期望0 0）\n", failed.Load(), badSignatures.Load())
+	fmt.Printf("现货客户端: 使用新密钥 %v（期望true）\n", spot.HasCredentials("NEWKEY-0002-BBBB", "new-secret"))
+
+	// 4. 轮换后的请求全部使用新密钥
+	mu.Lock()
+	before := used["NEWKEY-0002-BBBB"]
+	mu.Unlock()
+	futures.GetAssetBalance("USDT")
+	mu.Lock()
+	fmt.Printf("轮换后请求: 新密钥增加 %d 次，新旧密钥合计 %d 次（期望1 201）\n", used["NEWKEY-0002-BBBB"]-before, used["OLDKEY-0001-AAAA"]+used["NEWKEY-0002-BBBB"])
+	mu.Unlock()
+
+	utils.Info("=== API密钥轮换测试完成 ===")
+}
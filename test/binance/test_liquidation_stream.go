@@ -0,0 +1,134 @@
+/*
+强平推送测试程序
+
+测试内容：
+- 本地模拟币安全市场强平订单推送（不访问交易所）
+- 强平卖单计为多头被强平、强平买单计为空头被强平，Stats 按窗口统计名义价值
+- 单个交易对窗口内强平达到阈值时 Cascade 返回true和原因，其他交易对不受影响
+- 全市场强平达到阈值时所有交易对 Cascade 返回true
+- nil 推送可以安全调用
+
+运行方式：
+
+	go run test/binance/test_liquidation_stream.go
+*/
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 强平推送测试开始 ===")
+
+	srv := httptest.NewServer(http.HandlerFunc(serveForceOrder))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := binance.NewLiquidationStream("ws://"+strings.TrimPrefix(srv.URL, "http://"), "",
+		[]time.Duration{5 * time.Minute, time.Hour},
+		binance.CascadeRule{Window: 5 * time.Minute, SymbolNotional: 1000000, MarketNotional: 1500000})
+	go stream.Run(ctx)
+
+	// 1. 窗口统计
+	time.Sleep(500 * time.Millisecond)
+	stats := stream.Stats("BTCUSDT")
+	for _, w := range stats.Windows {
+		fmt.Printf("BTCUSDT %d分钟: 多头 %.0f 空头 %.0f 共%d笔（期望多头1300000，空头65000，3笔）\n",
+			w.Minutes, w.LongNotional, w.ShortNotional, w.Count)
+	}
+
+	// 2. 交易对连环强平
+	cascade, reason := stream.Cascade("BTCUSDT")
+	fmt.Printf("BTCUSDT 连环强平: %v %s（期望true）\n", cascade, reason)
+	cascade, _ = stream.Cascade("ETHUSDT")
+	fmt.Printf("ETHUSDT 连环强平: %v（期望false）\n", cascade)
+
+	// 3. 全市场连环强平
+	time.Sleep(time.Second)
+	cascade, reason = stream.Cascade("ETHUSDT")
+	fmt.Printf("推送ETH强平后 ETHUSDT: %v %s（期望true，全市场）\n", cascade, reason)
+	for _, c := range stream.Cascades() {
+		fmt.Printf("  进行中: symbol=%q notional=%.0f\n", c.Symbol, c.Notional)
+	}
+	status := stream.Status()
+	fmt.Printf("状态: connected=%v events=%d（期望true，4）\n", status.Connected, status.Events)
+
+	// 4. nil 推送可以安全调用
+	var none *binance.LiquidationStream
+	cascade, _ = none.Cascade("BTCUSDT")
+	fmt.Printf("nil推送: cascade=%v windows=%d（期望false，0）\n", cascade, len(none.Stats("BTCUSDT").Windows))
+
+	utils.Info("=== 强平推送测试完成 ===")
+}
+
+// serveForceOrder 模拟全市场强平推送：先推送BTC的3笔，1秒后推送ETH的1笔
+func serveForceOrder(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/ws/!forceOrder@arr" {
+		http.NotFound(w, r)
+		return
+	}
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(sum[:]))
+	rw.Flush()
+
+	writeFrame(conn, forceOrder("BTCUSDT", "SELL", "65000", "10"))
+	writeFrame(conn, forceOrder("BTCUSDT", "SELL", "65000", "10"))
+	writeFrame(conn, forceOrder("BTCUSDT", "BUY", "65000", "1"))
+	time.Sleep(time.Second)
+	writeFrame(conn, forceOrder("ETHUSDT", "SELL", "3200", "100"))
+
+	// 保持连接直到客户端关闭
+	buf := make([]byte, 512)
+	for {
+		if _, err := rw.Read(buf); err != nil {
+			return
+		}
+	}
+}
+
+// forceOrder 构造一条强平订单推送
+func forceOrder(symbol, side, price, qty string) []byte {
+	now := time.Now().UnixMilli()
+	return []byte(fmt.Sprintf(`{"e":"forceOrder","E":%d,"o":{"s":"%s","S":"%s","o":"LIMIT","f":"IOC","q":"%s","p":"%s","ap":"%s","X":"FILLED","l":"%s","z":"%s","T":%d}}`,
+		now, symbol, side, qty, price, price, qty, qty, now))
+}
+
+// writeFrame 发送一个文本帧（服务端帧不加掩码）
+func writeFrame(conn net.Conn, payload []byte) {
+	frame := []byte{0x81}
+	if n := len(payload); n < 126 {
+		frame = append(frame, byte(n))
+	} else {
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	}
+	conn.Write(append(frame, payload...))
+}
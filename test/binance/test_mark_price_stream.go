@@ -0,0 +1,182 @@
+/*
+标记价格推送测试程序
+
+测试内容：
+- 本地模拟币安推送服务（WebSocket握手、每200ms推送一次全市场标记价格，不访问交易所）和溢价指数接口
+- 连接后先用REST补齐全部交易对（只在REST中出现的交易对也有数据）
+- 收到推送后 Get/Price/FundingRate 返回最新数据
+- 服务端发送ping，客户端回复pong
+- 服务端断开连接后停止推送，数据超过有效期后 Get 返回false（调用方改用REST接口）
+- 重连按指数退避：第一次约1秒后（服务端拒绝握手），第二次约2秒后成功并恢复数据
+- 收到的标记价格生成1分钟K线，Candles/Closes 返回副本
+
+运行方式：
+
+	go run test/binance/test_mark_price_stream.go
+*/
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+)
+
+var (
+	connections atomic.Int64
+	pongs       atomic.Int64
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 标记价格推送测试开始 ===")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(binance.EndpointPremiumIndex, servePremiumIndex)
+	mux.HandleFunc("/ws/", serveMarkPrice)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := binance.NewClient("", "", srv.URL, "")
+	stream := binance.NewMarkPriceStream(client, "ws://"+strings.TrimPrefix(srv.URL, "http://"), "", time.Second)
+	go stream.Run(ctx)
+
+	// 1. 补齐数据并收到推送
+	time.Sleep(700 * time.Millisecond)
+	price, ok := stream.Price("SOLUSDT")
+	fmt.Printf("REST补齐: SOLUSDT ok=%v 标记价格=%.2f（期望true，150.00）\n", ok, price)
+	update, ok := stream.Get("BTCUSDT")
+	fmt.Printf("连接后: ok=%v 标记价格=%.2f 指数价格=%.2f 资金费率=%.6f（期望ok=true，价格约65000，资金费率0.000100）\n",
+		ok, update.MarkPrice, update.IndexPrice, update.FundingRate)
+	rate, ok := stream.FundingRate("ETHUSDT")
+	fmt.Printf("ETHUSDT 资金费率: %.6f ok=%v\n", rate, ok)
+	_, ok = stream.Price("UNKNOWN")
+	fmt.Printf("未推送的交易对: ok=%v（期望false）\n", ok)
+
+	// 2. 服务端在推送5次后断开，数据超过1秒有效期后失效（此时第一次重连被拒绝）
+	time.Sleep(2300 * time.Millisecond)
+	_, ok = stream.Price("BTCUSDT")
+	status := stream.Status()
+	fmt.Printf("断开后: ok=%v connected=%v reconnects=%d 收到pong %d 次（期望ok=false，connected=false，至少1次pong）\n",
+		ok, status.Connected, status.Reconnects, pongs.Load())
+
+	// 3. 退避后重连成功
+	time.Sleep(3500 * time.Millisecond)
+	price, ok = stream.Price("BTCUSDT")
+	status = stream.Status()
+	fmt.Printf("重连后: ok=%v 标记价格=%.2f 服务端收到连接 %d 次 reconnects=%d backfills=%d（期望ok=true，3次，2，2）\n",
+		ok, price, connections.Load(), status.Reconnects, status.Backfills)
+	fmt.Printf("快照: %d 个交易对，状态 symbols=%d（期望3）\n", len(stream.Snapshot()), status.Symbols)
+	for _, c := range stream.Candles("BTCUSDT", 0) {
+		fmt.Printf("1分钟K线 %s: 开 %.2f 高 %.2f 低 %.2f 收 %.2f 推送 %d 次\n",
+			c.Time.Format("15:04"), c.Value.Open, c.Value.High, c.Value.Low, c.Value.Close, c.Value.Updates)
+	}
+	fmt.Printf("  收盘价: %v（期望1~2根，最后一根收盘价为最新标记价格）\n", stream.Closes("BTCUSDT", 2))
+
+	// 4. nil 推送可以安全调用
+	var none *binance.MarkPriceStream
+	_, ok = none.Price("BTCUSDT")
+	fmt.Printf("nil推送: ok=%v（期望false）\n", ok)
+
+	utils.Info("=== 标记价格推送测试完成 ===")
+}
+
+// servePremiumIndex 全部交易对的溢价指数（比推送多一个SOLUSDT）
+func servePremiumIndex(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UnixMilli()
+	fmt.Fprintf(w, `[{"symbol":"BTCUSDT","markPrice":"64000.00","indexPrice":"63990.00","lastFundingRate":"0.00010000","nextFundingTime":%d,"time":%d},`+
+		`{"symbol":"SOLUSDT","markPrice":"150.00","indexPrice":"149.90","lastFundingRate":"0.00002000","nextFundingTime":%d,"time":%d}]`,
+		now+3600000, now, now+3600000, now)
+}
+
+// serveMarkPrice 模拟币安全市场标记价格推送：第一次连接推送5次后断开，第二次连接拒绝握手，之后持续推送
+func serveMarkPrice(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/ws/!markPrice@arr@1s" {
+		http.NotFound(w, r)
+		return
+	}
+	n := connections.Add(1)
+	if n == 2 {
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(sum[:]))
+	rw.Flush()
+
+	go readPongs(rw.Reader)
+
+	for i := 0; n > 1 || i < 5; i++ {
+		now := time.Now().UnixMilli()
+		message := fmt.Sprintf(`[{"e":"markPriceUpdate","E":%d,"s":"BTCUSDT","p":"%.2f","i":"64990.00","P":"65000.00","r":"0.00010000","T":%d},`+
+			`{"e":"markPriceUpdate","E":%d,"s":"ETHUSDT","p":"3200.50","i":"3200.00","P":"3200.00","r":"-0.00005000","T":%d}]`,
+			now, 65000+float64(i), now+3600000, now, now+3600000)
+		if writeFrame(conn, 0x1, []byte(message)) != nil {
+			return
+		}
+		if i == 1 {
+			writeFrame(conn, 0x9, []byte("ping"))
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// readPongs 统计客户端回复的pong帧（客户端帧带掩码）
+func readPongs(reader *bufio.Reader) {
+	for {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return
+		}
+		length := int(header[1] & 0x7F)
+		payload := make([]byte, 4+length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return
+		}
+		if header[0]&0x0F == 0xA {
+			pongs.Add(1)
+		}
+	}
+}
+
+// writeFrame 发送一帧（服务端帧不加掩码）
+func writeFrame(conn net.Conn, opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, byte(n))
+	default:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	}
+	_, err := conn.Write(append(frame, payload...))
+	return err
+}
@@ -0,0 +1,64 @@
+/*
+持仓数值计算测试程序
+
+测试内容：
+- PositionRisk 数值解析：数量、开仓均价、标记价格、强平价格、杠杆、未实现盈亏
+- PnLPct：按持仓方向的涨跌幅（多头上涨、空头下跌为正）
+- ROE：涨跌幅 × 杠杆，杠杆缺失时按1倍
+- LiquidationDistancePct：没有强平价格时返回false
+- NotionalUSDT：优先使用接口返回的名义价值，缺失时按 数量 × 标记价格
+- Position 与 PositionRisk 结果一致
+
+运行方式：
+
+	go run test/binance/test_position_metrics.go
+*/
+package main
+
+import (
+	"fmt"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 持仓数值计算测试开始 ===")
+
+	long := binance.PositionRisk{
+		Symbol: "BTCUSDT", PositionAmt: "0.5", EntryPrice: "60000", MarkPrice: "61200",
+		UnRealizedProfit: "600", LiquidationPrice: "51000", Leverage: "10", Notional: "30600",
+	}
+	short := binance.PositionRisk{
+		Symbol: "ETHUSDT", PositionAmt: "-2", EntryPrice: "3000", MarkPrice: "3060",
+		UnRealizedProfit: "-120", LiquidationPrice: "0", Leverage: "",
+	}
+
+	// 1. 多头：涨2%，10倍杠杆
+	dist, ok := long.LiquidationDistancePct()
+	fmt.Printf("多头: 数量 %.2f 均价 %.0f 标记 %.0f 强平 %.0f 杠杆 %d 盈亏 %.0f\n",
+		long.Quantity(), long.EntryPriceFloat(), long.MarkPriceFloat(), long.LiquidationPriceFloat(), long.LeverageInt(), long.UnrealizedPnL())
+	fmt.Printf("  涨跌幅 %.2f%% ROE %.2f%% 强平距离 %.2f%%(%v) 名义价值 %.0f（期望2.00 20.00 16.67(true) 30600）\n",
+		long.PnLPct(), long.ROE(), dist, ok, long.NotionalUSDT())
+
+	// 2. 空头：涨2%（亏损），杠杆缺失，没有强平价格，没有名义价值字段
+	dist, ok = short.LiquidationDistancePct()
+	fmt.Printf("空头: 涨跌幅 %.2f%% ROE %.2f%% 强平距离 %.2f(%v) 名义价值 %.0f（期望-2.00 -2.00 0.00(false) 6120）\n",
+		short.PnLPct(), short.ROE(), dist, ok, short.NotionalUSDT())
+
+	// 3. 没有持仓
+	empty := binance.PositionRisk{Symbol: "SOLUSDT", PositionAmt: "0", EntryPrice: "0", MarkPrice: "150", Leverage: "5"}
+	fmt.Printf("无持仓: 涨跌幅 %.2f ROE %.2f 名义价值 %.0f（期望0.00 0.00 0）\n", empty.PnLPct(), empty.ROE(), empty.NotionalUSDT())
+
+	// 4. Position 结果一致
+	pos := binance.Position(long)
+	fmt.Printf("Position: ROE %.2f%% 名义价值 %.0f（期望与多头相同）\n", pos.ROE(), pos.NotionalUSDT())
+
+	utils.Info("=== 持仓数值计算测试完成 ===")
+}
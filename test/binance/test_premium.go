@@ -0,0 +1,87 @@
+/*
+标记价格溢价测试程序
+
+测试内容：
+- 本地模拟币安溢价指数接口（不访问交易所）
+- PremiumPct：没有标记价格推送时通过REST接口计算 (标记价格 - 指数价格) / 指数价格 × 100
+- 指数价格缺失时返回错误
+- Executor.SetPremiumGuard：溢价（正负）超过上限时拒绝开仓，不发送下单请求
+
+运行方式：
+
+	go run test/binance/test_premium.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/utils"
+)
+
+// 模拟溢价指数：交易对 -> 标记价格、指数价格
+var mockPremiums = map[string][2]string{
+	"BTCUSDT":  {"60030", "60000"}, // +0.05%
+	"PEPEUSDT": {"0.0101", "0.01"}, // +1%
+	"WIFUSDT":  {"1.98", "2"},      // -1%
+	"NEWUSDT":  {"5", ""},          // 指数价格缺失
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 标记价格溢价测试开始 ===")
+
+	var orders atomic.Int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fapi/v1/premiumIndex", func(w http.ResponseWriter, r *http.Request) {
+		p := mockPremiums[r.URL.Query().Get("symbol")]
+		json.NewEncoder(w).Encode(map[string]string{
+			"symbol":          r.URL.Query().Get("symbol"),
+			"markPrice":       p[0],
+			"indexPrice":      p[1],
+			"lastFundingRate": "0.0001",
+		})
+	})
+	mux.HandleFunc("/fapi/v1/order", func(w http.ResponseWriter, r *http.Request) {
+		orders.Add(1)
+		http.Error(w, `{"code":-1000,"msg":"mock"}`, http.StatusBadRequest)
+	})
+	mock := httptest.NewServer(mux)
+	defer mock.Close()
+
+	client := binance.NewClient("key", "secret", mock.URL, "")
+
+	// 1. 通过REST接口计算溢价
+	for _, symbol := range []string{"BTCUSDT", "PEPEUSDT", "WIFUSDT"} {
+		premium, err := binance.PremiumPct(nil, client, symbol)
+		fmt.Printf("%s 溢价: %+.3f%% 错误: %v\n", symbol, premium, err)
+	}
+	fmt.Println("  期望 +0.050%、+1.000%、-1.000%，没有错误")
+	_, err := binance.PremiumPct(nil, client, "NEWUSDT")
+	fmt.Printf("指数价格缺失: %v（期望返回错误）\n", err)
+
+	// 2. 溢价超过上限时拒绝开仓
+	exec := executor.NewExecutor("acc-1", client)
+	exec.SetPremiumGuard(0.5)
+	for _, d := range []*executor.Decision{
+		{Symbol: "PEPEUSDT", Action: executor.ActionOpenLong, StopLoss: 0.0095},
+		{Symbol: "WIFUSDT", Action: executor.ActionOpenShort, StopLoss: 2.1},
+	} {
+		_, err := exec.PlaceBracket(d)
+		fmt.Printf("%s 开仓: %v\n", d.Symbol, err)
+	}
+	fmt.Printf("  期望两次都因溢价超过上限被拒绝，下单请求 %d 次（期望0）\n", orders.Load())
+
+	utils.Info("=== 标记价格溢价测试完成 ===")
+}
@@ -0,0 +1,159 @@
+/*
+推送订阅队列测试程序
+
+测试内容：
+- 本地模拟币安推送（不访问交易所），短时间内推送大量标记价格和强平订单
+- 标记价格订阅（conflate）：消费者处理慢时同一交易对只保留最新一条，队列中最多每个交易对一条，最后收到的是最新价格
+- 强平订阅（drop_oldest）：队列满时丢弃最旧的强平，队列长度不超过容量，最后收到的是最新的强平
+- 订阅的ctx取消后通道关闭、订阅队列移除
+
+运行方式：
+
+	go run test/binance/test_stream_queue.go
+*/
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+)
+
+const bursts = 200 // 每种推送的条数
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 推送订阅队列测试开始 ===")
+
+	srv := httptest.NewServer(http.HandlerFunc(serve))
+	defer srv.Close()
+	baseURL := "ws://" + strings.TrimPrefix(srv.URL, "http://")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	marks := binance.NewMarkPriceStream(nil, baseURL, "", time.Minute)
+	liquidations := binance.NewLiquidationStream(baseURL, "", []time.Duration{time.Hour}, binance.CascadeRule{})
+
+	// 先订阅再连接，推送一开始就进入队列
+	subCtx, unsubscribe := context.WithCancel(ctx)
+	markUpdates := marks.Subscribe(subCtx, "slow_marks", 10)
+	liqEvents := liquidations.Subscribe(subCtx, "slow_liquidations", 10)
+	go marks.Run(ctx)
+	go liquidations.Run(ctx)
+
+	// 1. 推送完成后消费者才开始读取（模拟处理慢）
+	time.Sleep(time.Second)
+	for _, stats := range append(marks.Subscribers(), liquidations.Subscribers()...) {
+		fmt.Printf("%s(%s): 容量 %d 待消费 %d 丢弃 %d 合并 %d\n",
+			stats.Name, stats.Policy, stats.Capacity, stats.Pending, stats.Dropped, stats.Conflated)
+	}
+	// 投递协程手上还有一条等待消费者接收的数据，不计入待消费
+	fmt.Printf("  期望 slow_marks 待消费不超过3（每个交易对一条）；slow_liquidations 待消费不超过10，丢弃+待消费+1=%d\n", bursts)
+
+	// 2. 读取剩余的数据（第一条是投递协程手上的数据，之后是队列中保留的数据）
+	last := make(map[string]float64)
+	received := 0
+	for drained := false; !drained; {
+		select {
+		case update := <-markUpdates:
+			last[update.Symbol] = update.MarkPrice
+			received++
+		case <-time.After(100 * time.Millisecond):
+			drained = true
+		}
+	}
+	fmt.Printf("标记价格: 收到%d条 %v（期望不超过4条，每个交易对都是最后一次推送的价格 %d）\n", received, last, 1000+bursts-1)
+
+	var quantities []float64
+	for drained := false; !drained; {
+		select {
+		case event := <-liqEvents:
+			quantities = append(quantities, event.Quantity)
+		case <-time.After(100 * time.Millisecond):
+			drained = true
+		}
+	}
+	fmt.Printf("强平: 收到数量 %v（期望最多11条，最后10条为最新的 %d..%d）\n", quantities, bursts-9, bursts)
+
+	// 3. 取消订阅
+	unsubscribe()
+	time.Sleep(100 * time.Millisecond)
+	_, open := <-markUpdates
+	fmt.Printf("取消订阅后: 通道打开=%v 订阅数 %d（期望false，0）\n", open, len(marks.Subscribers())+len(liquidations.Subscribers()))
+
+	// 4. nil 推送的订阅立即关闭
+	var none *binance.MarkPriceStream
+	_, open = <-none.Subscribe(ctx, "none", 10)
+	fmt.Printf("nil推送: 通道打开=%v（期望false）\n", open)
+
+	utils.Info("=== 推送订阅队列测试完成 ===")
+}
+
+// serve 标记价格推送：连续推送bursts次3个交易对；强平推送：连续推送bursts条（数量1..bursts）
+func serve(w http.ResponseWriter, r *http.Request) {
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(sum[:]))
+	rw.Flush()
+
+	now := time.Now().UnixMilli()
+	for i := 0; i < bursts; i++ {
+		var message string
+		switch r.URL.Path {
+		case "/ws/!markPrice@arr@1s":
+			var events []string
+			for _, symbol := range []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"} {
+				events = append(events, fmt.Sprintf(`{"e":"markPriceUpdate","E":%d,"s":"%s","p":"%d","i":"0","r":"0","T":0}`, now, symbol, 1000+i))
+			}
+			message = "[" + strings.Join(events, ",") + "]"
+		case "/ws/!forceOrder@arr":
+			message = fmt.Sprintf(`{"e":"forceOrder","E":%d,"o":{"s":"BTCUSDT","S":"SELL","q":"%d","p":"65000","ap":"65000","X":"FILLED","z":"%d","T":%d}}`,
+				now, i+1, i+1, now)
+		default:
+			return
+		}
+		writeFrame(conn, []byte(message))
+	}
+
+	// 保持连接直到客户端关闭
+	buf := make([]byte, 512)
+	for {
+		if _, err := rw.Read(buf); err != nil {
+			return
+		}
+	}
+}
+
+// writeFrame 发送一个文本帧（服务端帧不加掩码）
+func writeFrame(conn net.Conn, payload []byte) {
+	frame := []byte{0x81}
+	if n := len(payload); n < 126 {
+		frame = append(frame, byte(n))
+	} else {
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	}
+	conn.Write(append(frame, payload...))
+}
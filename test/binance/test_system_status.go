@@ -0,0 +1,112 @@
+/*
+交易所系统状态测试程序
+
+测试内容：
+- 本地模拟币安系统状态和交易规则接口（不访问交易所）
+- GetSystemStatus：只能通过现货客户端查询
+- StatusMonitor：进入、结束系统维护；只检查Watch的交易对，状态不是TRADING的视为暂停交易
+- 请求失败时保留上一次的状态并记录错误
+- nil 的 StatusMonitor 始终视为正常
+
+运行方式：
+
+	go run test/binance/test_system_status.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 交易所系统状态测试开始 ===")
+
+	var maintenance, failing atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sapi/v1/system/status", func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if maintenance.Load() {
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": 1, "msg": "system_maintenance"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": 0, "msg": "normal"})
+	})
+	mux.HandleFunc("/fapi/v1/exchangeInfo", func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"symbols": []map[string]string{
+				{"symbol": "BTCUSDT", "status": "TRADING"},
+				{"symbol": "LUNAUSDT", "status": "SETTLING"},
+				{"symbol": "XYZUSDT", "status": "BREAK"}, // 不在交易对池中
+			},
+		})
+	})
+	mock := httptest.NewServer(mux)
+	defer mock.Close()
+
+	spot := binance.NewSpotClient("", "", mock.URL, "")
+	futures := binance.NewClient("", "", mock.URL, "")
+
+	// 1. 系统状态接口
+	status, err := spot.GetSystemStatus()
+	fmt.Printf("现货客户端: 正常 %v 错误 %v（期望true <nil>）\n", status.Normal(), err)
+	_, err = futures.GetSystemStatus()
+	fmt.Printf("合约客户端: %v（期望不支持）\n", err)
+
+	// 2. 正常状态与暂停交易的交易对
+	monitor := binance.NewStatusMonitor(spot)
+	monitor.Watch(binance.MarketTypeUSDTM, futures, []string{"BTCUSDT", "LUNAUSDT"})
+	monitor.Check()
+	down, _ := monitor.Maintenance()
+	_, btcHalted := monitor.Halted(binance.MarketTypeUSDTM, "BTCUSDT")
+	lunaState, lunaHalted := monitor.Halted(binance.MarketTypeUSDTM, "LUNAUSDT")
+	_, xyzHalted := monitor.Halted(binance.MarketTypeUSDTM, "XYZUSDT")
+	fmt.Printf("正常: 维护 %v，BTCUSDT暂停 %v，LUNAUSDT暂停 %v(%s)，XYZUSDT暂停 %v（期望false false true(SETTLING) false）\n",
+		down, btcHalted, lunaHalted, lunaState, xyzHalted)
+
+	// 3. 进入维护
+	maintenance.Store(true)
+	monitor.Check()
+	down, msg := monitor.Maintenance()
+	fmt.Printf("维护: %v %s（期望true system_maintenance）\n", down, msg)
+
+	// 4. 接口不可用时保留上一次的状态
+	failing.Store(true)
+	maintenance.Store(false)
+	monitor.Check()
+	down, _ = monitor.Maintenance()
+	_, lunaHalted = monitor.Halted(binance.MarketTypeUSDTM, "LUNAUSDT")
+	fmt.Printf("接口不可用: 维护 %v，LUNAUSDT暂停 %v，有错误 %v（期望true true true）\n", down, lunaHalted, monitor.Status().LastError != "")
+
+	// 5. 维护结束
+	failing.Store(false)
+	monitor.Check()
+	down, _ = monitor.Maintenance()
+	fmt.Printf("恢复: 维护 %v，错误 %q（期望false \"\"）\n", down, monitor.Status().LastError)
+
+	var none *binance.StatusMonitor
+	down, _ = none.Maintenance()
+	_, halted := none.Halted(binance.MarketTypeUSDTM, "BTCUSDT")
+	fmt.Printf("nil监控: 维护 %v，暂停 %v（期望false false）\n", down, halted)
+
+	utils.Info("=== 交易所系统状态测试完成 ===")
+}
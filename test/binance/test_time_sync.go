@@ -0,0 +1,91 @@
+/*
+请求时间戳同步测试程序
+
+测试内容：
+- 本地模拟币安接口（不访问交易所），模拟服务器时间比本地快10秒
+- 时间戳超出recvWindow返回 -1021 时，自动同步服务器时间并重试一次，请求成功
+- 同步后的请求直接使用校准后的时间戳，不再重试
+- SetRecvWindow：签名请求带上recvWindow参数，超过上限时按60000
+- 同步服务器时间失败时返回原来的 -1021 错误
+
+运行方式：
+
+	go run test/binance/test_time_sync.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/utils"
+)
+
+const skew = 10 * time.Second // 服务器时间 - 本地时间
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 请求时间戳同步测试开始 ===")
+
+	var orderCalls, timeCalls atomic.Int64
+	var timeDown atomic.Bool
+	var lastRecvWindow atomic.Value
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fapi/v1/time", func(w http.ResponseWriter, r *http.Request) {
+		timeCalls.Add(1)
+		if timeDown.Load() {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]int64{"serverTime": time.Now().Add(skew).UnixMilli()})
+	})
+	mux.HandleFunc("/fapi/v1/order", func(w http.ResponseWriter, r *http.Request) {
+		orderCalls.Add(1)
+		lastRecvWindow.Store(r.URL.Query().Get("recvWindow"))
+		ts, _ := strconv.ParseInt(r.URL.Query().Get("timestamp"), 10, 64)
+		if time.Now().Add(skew).UnixMilli()-ts > 5000 {
+			http.Error(w, `{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"orderId": 1, "symbol": "BTCUSDT", "status": "FILLED"})
+	})
+	mock := httptest.NewServer(mux)
+	defer mock.Close()
+
+	// 1. 时间戳超出recvWindow时同步后重试
+	client := binance.NewClient("key", "secret", mock.URL, "")
+	order, err := client.GetOrder("BTCUSDT", 1)
+	fmt.Printf("首次请求: 成功 %v，错误 %v，下单接口请求 %d 次，同步 %d 次（期望true <nil> 2 1）\n", order != nil, err, orderCalls.Load(), timeCalls.Load())
+	fmt.Printf("时间偏移: %v（期望约10s）\n", client.TimeOffset().Round(time.Second))
+
+	// 2. 校准后的请求不再重试
+	orderCalls.Store(0)
+	timeCalls.Store(0)
+	_, err = client.GetOrder("BTCUSDT", 1)
+	fmt.Printf("再次请求: 错误 %v，下单接口请求 %d 次，同步 %d 次（期望<nil> 1 0）\n", err, orderCalls.Load(), timeCalls.Load())
+
+	// 3. recvWindow参数
+	client.SetRecvWindow(90000)
+	client.GetOrder("BTCUSDT", 1)
+	fmt.Printf("recvWindow: %q（期望\"60000\"）\n", lastRecvWindow.Load())
+
+	// 4. 同步失败时返回原来的错误
+	timeDown.Store(true)
+	stale := binance.NewClient("key", "secret", mock.URL, "")
+	_, err = stale.GetOrder("BTCUSDT", 1)
+	fmt.Printf("同步失败: 时间戳错误 %v（期望true）\n", errors.Is(err, binance.ErrInvalidTimestamp))
+
+	utils.Info("=== 请求时间戳同步测试完成 ===")
+}
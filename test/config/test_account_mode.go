@@ -0,0 +1,75 @@
+/*
+账号运行模式测试程序
+
+测试内容：
+- 未配置 mode 时为 live，启用 shadow 时为 paper
+- mode: paper 等同于 shadow.enabled: true（加载后影子模式已启用，不需要API密钥）
+- mode: observe 不需要API密钥，不支持最大回撤熔断和资金调拨
+- 无效的 mode、shadow.enabled 与 live/observe 同时配置时加载失败
+
+运行方式：
+
+	go run test/config/test_account_mode.go
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 账号运行模式测试开始 ===")
+
+	dir, err := os.MkdirTemp("", "accounts")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// load 写入一个账号的配置并加载
+	load := func(extra string) ([]config.Account, error) {
+		path := filepath.Join(dir, "accounts.yml")
+		data := "accounts:\n  - id: a\n    name: a\n    strategy: short_term\n    prompt_type: minimal\n    enabled: true\n" + extra
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			return nil, err
+		}
+		return config.LoadAccounts(path)
+	}
+
+	for _, c := range []struct {
+		name   string
+		extra  string
+		expect string
+	}{
+		{"默认", "    api_key: k\n    api_secret: s\n", "live true"},
+		{"shadow.enabled", "    shadow:\n      enabled: true\n", "paper false 影子模式 true"},
+		{"mode: paper", "    mode: paper\n", "paper false 影子模式 true"},
+		{"mode: observe", "    mode: observe\n", "observe false"},
+		{"observe + 熔断", "    mode: observe\n    circuit_breaker:\n      max_drawdown_pct: 10\n", "不支持最大回撤熔断"},
+		{"observe + 资金调拨", "    mode: observe\n    treasury:\n      enabled: true\n      floor: 100\n", "资金调拨只支持币安合约实盘账号"},
+		{"live 无密钥", "    mode: live\n", "API Key不能为空"},
+		{"live + shadow", "    mode: live\n    shadow:\n      enabled: true\n", "运行模式只能是paper"},
+		{"无效模式", "    mode: dry_run\n", "运行模式无效"},
+	} {
+		accounts, err := load(c.extra)
+		if err != nil {
+			fmt.Printf("%s: %v（期望%s）\n", c.name, err, c.expect)
+			continue
+		}
+		acc := accounts[0]
+		fmt.Printf("%s: %s %v 影子模式 %v（期望%s）\n", c.name, acc.GetMode(), acc.IsLive(), acc.Shadow.Enabled, c.expect)
+	}
+
+	utils.Info("=== 账号运行模式测试完成 ===")
+}
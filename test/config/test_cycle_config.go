@@ -0,0 +1,59 @@
+/*
+账号运行周期配置测试程序
+
+测试内容：
+- cycle.interval_sec、cycle.manage_interval_sec 的校验（负数、短于10秒、管理周期不短于分析周期）
+- 加载 configs/accounts.example.yml 后 account_4 的运行周期
+
+运行方式：
+
+	go run test/config/test_cycle_config.go
+*/
+package main
+
+import (
+	"fmt"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 账号运行周期配置测试开始 ===")
+
+	// 1. 校验
+	for _, c := range []struct {
+		cfg    config.CycleConfig
+		expect string
+	}{
+		{config.CycleConfig{}, "<nil>（使用策略默认周期）"},
+		{config.CycleConfig{IntervalSec: 1800, ManageIntervalSec: 300}, "<nil>"},
+		{config.CycleConfig{ManageIntervalSec: 60}, "<nil>（分析周期未配置，启动时与策略周期比较）"},
+		{config.CycleConfig{IntervalSec: -1}, "不能为负数"},
+		{config.CycleConfig{IntervalSec: 5}, "分析周期不能短于10秒"},
+		{config.CycleConfig{ManageIntervalSec: 5}, "持仓管理周期不能短于10秒"},
+		{config.CycleConfig{IntervalSec: 300, ManageIntervalSec: 300}, "应短于分析周期"},
+	} {
+		fmt.Printf("%+v: %v（期望%s）\n", c.cfg, c.cfg.Validate(), c.expect)
+	}
+
+	// 2. 示例配置
+	accounts, err := config.LoadAccounts("configs/accounts.example.yml")
+	if err != nil {
+		fmt.Printf("加载示例配置失败: %v\n", err)
+		return
+	}
+	for _, acc := range accounts {
+		if acc.ID == "account_4" {
+			fmt.Printf("account_4: 分析周期 %d秒 持仓管理周期 %d秒（期望1800 300）\n", acc.Cycle.IntervalSec, acc.Cycle.ManageIntervalSec)
+		}
+	}
+
+	utils.Info("=== 账号运行周期配置测试完成 ===")
+}
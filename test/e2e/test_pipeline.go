@@ -0,0 +1,326 @@
+/*
+全流程测试程序（模拟币安接口 + 模拟AI接口，不需要真实API密钥）
+
+测试内容：
+- 交易对池：默认交易对 + 模拟外部API（过滤低评分、排除列表）
+- 周期1：获取K线、持仓量、资金费率 → 短线策略计算指标 → 渲染提示词 → AI决策（BTCUSDT开多、ETHUSDT观望） → 市价入场并挂出止损止盈单
+- 周期2：BTCUSDT上涨后AI决策平仓 → 撤销止损止盈单 → 交易日志的出场价、手续费和净盈亏与模拟交易所的成交结算一致
+- 周期2：ETHUSDT开空，入场下单第一次返回503（结果未知）→ 按客户端订单ID幂等重试，只成交一次
+- ETHUSDT上涨越过止损价 → 模拟交易所触发止损单 → 检查括号订单时撤销止盈单、记录止损交易
+- 审计记录中的指标快照、签名校验、没有未实现的接口调用
+
+运行方式：
+
+	go run test/e2e/test_pipeline.go
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/ai"
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/fakebinance"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/journal"
+	"crypto-ai-trader/prompt"
+	"crypto-ai-trader/strategy"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const accountID = "e2e"
+
+// fakeAI 模拟AI接口：按提示词中的交易对返回预设的回复（没有预设时观望）
+type fakeAI struct {
+	mu       sync.Mutex
+	replies  map[string]string
+	requests int
+}
+
+// set 设置交易对的回复
+func (f *fakeAI) set(symbol, reply string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.replies[symbol] = reply
+}
+
+// ServeHTTP 处理 /chat/completions 请求
+func (f *fakeAI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Messages []struct {
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	content := req.Messages[len(req.Messages)-1].Content
+
+	f.mu.Lock()
+	f.requests++
+	reply := `{"action": "hold", "confidence": 0.5, "reason": "没有明确方向"}`
+	for symbol, r := range f.replies {
+		if strings.Contains(content, symbol) {
+			reply = r
+		}
+	}
+	f.mu.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"model":   "mock-model",
+		"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		"usage":   map[string]int{"prompt_tokens": 800, "completion_tokens": 60},
+	})
+}
+
+// pipeline 一个账号的完整周期：K线 → 策略指标 → 提示词 → AI决策 → 执行 → 审计记录（与 main.go 的 runCycle 相同的顺序）
+type pipeline struct {
+	market   exchange.MarketData
+	strategy strategy.Strategy
+	prompts  *prompt.Store
+	ai       *ai.Client
+	exec     *executor.Executor
+	audit    *ai.Audit
+	oiCache  *utils.OICacheManager
+	prompt   map[string]string // 交易对 → 最近一次的提示词
+}
+
+// runCycle 执行一个周期，返回各交易对的结果（决策动作或错误）
+func (p *pipeline) runCycle(ctx context.Context, symbols []string) map[string]string {
+	results := make(map[string]string)
+	data := strategy.FetchCycleData(p.market, accountID, symbols, p.strategy.Timeframes(), 100, p.oiCache)
+	data.DropForming(time.Now())
+	for symbol, reason := range data.Failures {
+		results[symbol] = "数据失败: " + reason
+	}
+
+	for _, sig := range p.strategy.OnCycle(ctx, data) {
+		now := time.Now()
+		text, err := p.prompts.Render("minimal", &prompt.Data{
+			AccountID:  accountID,
+			Strategy:   sig.Strategy,
+			Symbol:     sig.Symbol,
+			Time:       now,
+			Indicators: sig.Data,
+			Summary:    indicators.Describe(sig.Data),
+		})
+		if err != nil {
+			results[sig.Symbol] = "提示词失败: " + err.Error()
+			continue
+		}
+		p.prompt[sig.Symbol] = text
+
+		rec := &ai.AuditRecord{AccountID: accountID, Strategy: sig.Strategy, Symbol: sig.Symbol, Time: now, Mode: ai.ModeSingle}
+		if raw, err := json.Marshal(sig.Data); err == nil {
+			rec.IndicatorKind, rec.Indicators = indicators.Kind(sig.Data), raw
+		}
+		stage, err := p.ai.RunStage(ctx, ai.StageSingle, "minimal", &ai.Request{Prompt: text})
+		rec.Stages = append(rec.Stages, stage)
+		if err == nil {
+			rec.Decision, err = ai.ParseDecision(stage.Reply, sig.Symbol)
+		}
+		if err == nil {
+			rec.Decision.AccountID, rec.Decision.Timestamp = accountID, now.UnixMilli()
+			err = p.exec.Execute(rec.Decision)
+		}
+		if err != nil {
+			rec.Error, results[sig.Symbol] = err.Error(), "失败: "+err.Error()
+		} else {
+			rec.Result, results[sig.Symbol] = "executed", rec.Decision.Action
+		}
+		p.audit.Record(rec)
+	}
+	return results
+}
+
+// orderTypes 订单的 类型:状态 列表
+func orderTypes(orders []binance.Order) []string {
+	list := make([]string, 0, len(orders))
+	for _, o := range orders {
+		list = append(list, o.Type+":"+o.Status)
+	}
+	return list
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 全流程测试开始 ===")
+	ctx := context.Background()
+
+	dir, err := os.MkdirTemp("", "e2e")
+	if err != nil {
+		utils.Fatal("创建临时目录失败", zap.Error(err))
+	}
+	defer os.RemoveAll(dir)
+
+	// 1. 模拟币安接口：两个交易对，5m/15m/1h K线
+	fake := fakebinance.New("test-key", "test-secret")
+	defer fake.Close()
+	now := time.Now()
+	for _, sym := range []fakebinance.Symbol{
+		{Symbol: "BTCUSDT", TickSize: 0.1, StepSize: 0.001, OpenInterest: 80000, FundingRate: 0.0001},
+		{Symbol: "ETHUSDT", TickSize: 0.01, StepSize: 0.001, OpenInterest: 1500000, FundingRate: -0.00005},
+	} {
+		fake.AddSymbol(sym)
+		base := map[string]float64{"BTCUSDT": 60000, "ETHUSDT": 3000}[sym.Symbol]
+		for interval, d := range map[string]time.Duration{"5m": 5 * time.Minute, "15m": 15 * time.Minute, "1h": time.Hour} {
+			fake.SetKlines(sym.Symbol, interval, fakebinance.MakeKlines(base, 150, d, now))
+		}
+	}
+
+	// 2. 交易对池：默认 BTCUSDT，外部API评分≥50的交易对，排除 XRPUSDT
+	poolServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"data":{"top_coins":[{"pair":"ETHUSDT","score":75},{"pair":"XRPUSDT","score":70}],"bottom_coins":[{"pair":"DOGEUSDT","score":30}]}}`))
+	}))
+	defer poolServer.Close()
+	symbols, _ := utils.GetSymbolPool([]string{"BTCUSDT"}, []string{"XRPUSDT"}, poolServer.URL, true, 50)
+	sort.Strings(symbols)
+	fmt.Printf("交易对池: %v（期望[BTCUSDT ETHUSDT]）\n", symbols)
+
+	// 3. 组装流程：客户端、执行器、交易日志、AI、审计记录
+	aiServer := &fakeAI{replies: make(map[string]string)}
+	aiHTTP := httptest.NewServer(aiServer)
+	defer aiHTTP.Close()
+
+	client := binance.NewClient("test-key", "test-secret", fake.URL, "")
+	exec := executor.NewExecutor(accountID, client)
+	if err := exec.LoadSymbolRules(); err != nil {
+		utils.Fatal("加载交易规则失败", zap.Error(err))
+	}
+	trades, err := journal.New(dir + "/journal")
+	if err != nil {
+		utils.Fatal("创建交易日志失败", zap.Error(err))
+	}
+	exec.SetJournal(trades)
+	audit, err := ai.NewAudit(dir + "/audit")
+	if err != nil {
+		utils.Fatal("创建审计记录失败", zap.Error(err))
+	}
+	strat, err := strategy.New("short_term")
+	if err != nil {
+		utils.Fatal("创建策略失败", zap.Error(err))
+	}
+	store, err := prompt.NewStore("configs/prompts")
+	if err != nil {
+		utils.Fatal("加载提示词模板失败", zap.Error(err))
+	}
+	p := &pipeline{
+		market:   exchange.NewBinance(client),
+		strategy: strat,
+		prompts:  store,
+		ai:       ai.NewClient(config.AIConfig{BaseURL: aiHTTP.URL, APIKey: "test", Model: "mock-model", TimeoutSec: 5}, ""),
+		exec:     exec,
+		audit:    audit,
+		oiCache:  utils.NewOICacheManager(100),
+		prompt:   make(map[string]string),
+	}
+
+	// 4. 周期1：BTCUSDT开多，ETHUSDT观望
+	btcPrice := lastClose(fake, "BTCUSDT")
+	aiServer.set("BTCUSDT", fmt.Sprintf(`{"action": "open_long", "quantity": 0.05, "stop_loss": %.1f, "take_profit": %.1f, "confidence": 0.8, "reason": "趋势向上"}`,
+		btcPrice*0.98, btcPrice*1.04))
+	results := p.runCycle(ctx, symbols)
+	fmt.Printf("\n===== 周期1 =====\n结果: BTCUSDT=%s ETHUSDT=%s（期望open_long hold）\n", results["BTCUSDT"], results["ETHUSDT"])
+	fmt.Printf("AI请求数: %d 提示词包含指标: %v（期望2 true）\n", aiServer.requests, strings.Contains(p.prompt["BTCUSDT"], "rsi"))
+	amt, entry := fake.Position("BTCUSDT")
+	fmt.Printf("BTCUSDT持仓: %v 开仓均价=%v（期望0.05 %v）\n", amt, entry, btcPrice)
+	fmt.Printf("BTCUSDT订单: %v（期望[MARKET:FILLED STOP_MARKET:NEW TAKE_PROFIT_MARKET:NEW]）\n", orderTypes(fake.Orders("BTCUSDT")))
+	if b := exec.GetBracket("BTCUSDT"); b != nil {
+		fmt.Printf("括号订单: 数量=%v 入场价=%v 止损单=%d 止盈单=%d（期望0.05 %v 非0 非0）\n", b.Quantity, b.EntryPrice, b.StopLossOrderID, b.TakeProfitOrderID, entry)
+	} else {
+		fmt.Println("括号订单: 无（期望有）")
+	}
+	fmt.Printf("ETHUSDT订单数: %d（期望0）\n", len(fake.Orders("ETHUSDT")))
+
+	// 5. 周期2：BTCUSDT上涨1%后平仓；ETHUSDT开空，入场下单第一次返回503
+	fake.SetPrice("BTCUSDT", math.Round(btcPrice*1.01*10)/10)
+	exitPrice := lastClose(fake, "BTCUSDT")
+	ethPrice := lastClose(fake, "ETHUSDT")
+	aiServer.set("BTCUSDT", `{"action": "close", "confidence": 0.7, "reason": "到达阻力位"}`)
+	aiServer.set("ETHUSDT", fmt.Sprintf(`{"action": "open_short", "quantity": 1, "stop_loss": %.2f, "take_profit": %.2f, "confidence": 0.75, "reason": "资金费率转负"}`,
+		ethPrice*1.02, ethPrice*0.95))
+	fake.FailNext("POST", binance.EndpointOrder, http.StatusServiceUnavailable, -1001, "Internal error; unable to process your request.")
+	results = p.runCycle(ctx, symbols)
+	fmt.Printf("\n===== 周期2 =====\n结果: BTCUSDT=%s ETHUSDT=%s（期望close open_short）\n", results["BTCUSDT"], results["ETHUSDT"])
+
+	amt, _ = fake.Position("BTCUSDT")
+	fmt.Printf("BTCUSDT持仓: %v 括号订单: %v（期望0 <nil>）\n", amt, exec.GetBracket("BTCUSDT"))
+	fmt.Printf("BTCUSDT订单: %v（期望[MARKET:FILLED STOP_MARKET:CANCELED TAKE_PROFIT_MARKET:CANCELED MARKET:FILLED]）\n", orderTypes(fake.Orders("BTCUSDT")))
+	recorded, _ := trades.Load(accountID)
+	if len(recorded) == 1 {
+		t := recorded[0]
+		fmt.Printf("交易日志: 出场价=%.1f 原因=%s（期望%.1f position_closed）\n", t.ExitPrice, t.CloseReason, exitPrice)
+		fees := 0.05 * (entry + exitPrice) * 0.0004
+		fmt.Printf("手续费=%.4f 价格盈亏=%.4f 净盈亏=%.4f（期望%.4f %.4f %.4f）\n", t.Commission, t.GrossPnL, t.NetPnL, fees, 0.05*(exitPrice-entry), 0.05*(exitPrice-entry)-fees)
+		var settled float64
+		for _, fill := range fake.Trades("BTCUSDT") {
+			settled += parseFloat(fill.RealizedPnl) - parseFloat(fill.Commission)
+		}
+		fmt.Printf("模拟交易所BTCUSDT成交: 已实现盈亏-手续费=%.4f（期望与净盈亏一致）\n", settled)
+	} else {
+		fmt.Printf("交易日志: %d 笔（期望1）\n", len(recorded))
+	}
+
+	amt, ethEntry := fake.Position("ETHUSDT")
+	fmt.Printf("ETHUSDT持仓: %v 开仓均价=%v（期望-1 %v，503后幂等重试只成交一次）\n", amt, ethEntry, ethPrice)
+	fmt.Printf("ETHUSDT订单: %v（期望[MARKET:FILLED STOP_MARKET:NEW TAKE_PROFIT_MARKET:NEW]）\n", orderTypes(fake.Orders("ETHUSDT")))
+
+	// 6. ETHUSDT上涨3%越过止损价，模拟交易所触发止损单，检查括号订单
+	fake.SetPrice("ETHUSDT", math.Round(ethPrice*1.03*100)/100)
+	exec.CheckBrackets()
+	fmt.Printf("\n===== 止损触发 =====\n")
+	amt, _ = fake.Position("ETHUSDT")
+	fmt.Printf("ETHUSDT持仓: %v 括号订单: %v（期望0 <nil>）\n", amt, exec.GetBracket("ETHUSDT"))
+	fmt.Printf("ETHUSDT订单: %v（期望[MARKET:FILLED STOP_MARKET:FILLED TAKE_PROFIT_MARKET:CANCELED]）\n", orderTypes(fake.Orders("ETHUSDT")))
+	recorded, _ = trades.Load(accountID)
+	if len(recorded) == 2 {
+		t := recorded[1]
+		fmt.Printf("交易日志: %s %s 出场价=%.2f 净盈亏<0=%v（期望ETHUSDT stop_loss %.2f true）\n", t.Symbol, t.CloseReason, t.ExitPrice, t.NetPnL < 0, lastClose(fake, "ETHUSDT"))
+	} else {
+		fmt.Printf("交易日志: %d 笔（期望2）\n", len(recorded))
+	}
+
+	// 7. 审计记录、签名、接口覆盖
+	history := ai.QuerySnapshots(audit, []string{accountID}, ai.SnapshotQuery{Symbol: "BTCUSDT"})
+	fmt.Printf("\n===== 其他 =====\nBTCUSDT指标快照: %d（期望2）\n", len(history.Snapshots))
+	_, err = binance.NewClient("test-key", "wrong-secret", fake.URL, "").GetBalance()
+	fmt.Printf("错误的密钥: %v（期望true）\n", err != nil && strings.Contains(err.Error(), "-2015"))
+	fmt.Printf("下单请求: %d 未实现的接口: %v（期望8：两次入场含一次503、四个止损止盈单、一次平仓；[]）\n", fake.Requests("POST", binance.EndpointOrder), fake.Unhandled())
+
+	utils.Info("=== 全流程测试结束 ===")
+}
+
+// lastClose 模拟交易所最新K线的收盘价
+func lastClose(fake *fakebinance.Server, symbol string) float64 {
+	client := binance.NewClient("test-key", "test-secret", fake.URL, "")
+	ticker, err := client.GetBookTicker(symbol)
+	if err != nil {
+		utils.Fatal("获取最新价失败", zap.Error(err))
+	}
+	return math.Round((ticker.BidPriceFloat()+ticker.AskPriceFloat())/2*100) / 100
+}
+
+// parseFloat 解析数值字符串
+func parseFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
@@ -0,0 +1,88 @@
+/*
+紧急平仓测试程序
+
+测试内容：
+- 申请紧急平仓返回6位确认码，不指定账号时选择全部账号
+- 不存在的账号、错误的确认码返回错误
+- 确认后依次执行各账号的全部平仓，部分账号失败时记录错误，确认码只能使用一次
+- 执行记录追加写入 emergency.jsonl，执行后回调收到同一条记录
+
+运行方式：
+
+	go run test/emergency/test_emergency.go
+*/
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"crypto-ai-trader/emergency"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 紧急平仓测试开始 ===")
+
+	dir, err := os.MkdirTemp("", "emergency")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sw := emergency.New(dir)
+	calls := 0
+	sw.Register(emergency.Account{ID: "acc-1", CloseAll: func() ([]string, error) {
+		calls++
+		return []string{"BTCUSDT", "ETHUSDT"}, nil
+	}})
+	sw.Register(emergency.Account{ID: "acc-2", CloseAll: func() ([]string, error) {
+		calls++
+		return []string{"SOLUSDT"}, errors.New("DOGEUSDT: 平仓失败")
+	}})
+	var notified *emergency.Record
+	sw.OnExecute(func(record *emergency.Record) { notified = record })
+
+	// 1. 申请
+	c, err := sw.Request(nil, "test")
+	fmt.Printf("申请: 确认码长度 %d 账号 %v 错误 %v（期望6 [acc-1 acc-2] <nil>）\n", len(c.Code), c.Accounts, err)
+	_, err = sw.Request([]string{"acc-3"}, "test")
+	fmt.Printf("不存在的账号: %v（期望账号不存在）\n", err)
+
+	// 2. 错误的确认码
+	_, err = sw.Confirm("000000x", "test")
+	fmt.Printf("错误的确认码: %v，调用次数 %d（期望确认码无效，0）\n", err, calls)
+
+	// 3. 确认执行
+	record, err := sw.Confirm(c.Code, "test")
+	if err != nil {
+		utils.Fatal("确认失败")
+	}
+	for _, res := range record.Results {
+		fmt.Printf("  %s: 平仓 %v 错误 %q\n", res.AccountID, res.Closed, res.Error)
+	}
+	fmt.Printf("调用次数 %d，有失败 %v，回调 %v（期望2 true true）\n", calls, record.Failed(), notified == record)
+	_, err = sw.Confirm(c.Code, "test")
+	fmt.Printf("重复确认: %v（期望确认码无效或已使用）\n", err)
+
+	// 4. 只选一个账号直接执行
+	record, _ = sw.Execute([]string{"acc-1"}, "cli")
+	fmt.Printf("直接执行: 账号 %v 有失败 %v（期望[acc-1] false）\n", record.Accounts, record.Failed())
+
+	// 5. 执行记录
+	data, err := os.ReadFile(filepath.Join(dir, "emergency.jsonl"))
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	fmt.Printf("执行记录: %d 条，错误 %v（期望2 <nil>）\n", len(lines), err)
+	fmt.Println(" ", lines[len(lines)-1])
+
+	utils.Info("=== 紧急平仓测试完成 ===")
+}
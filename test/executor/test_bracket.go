@@ -0,0 +1,130 @@
+/*
+括号订单测试程序（模拟币安接口，不需要真实API密钥）
+
+测试内容：
+- 正常入场：市价成交后挂出只减仓的止损止盈单
+- 止损单被交易所拒绝：立即市价紧急平仓，不留下无保护的持仓，不登记括号订单
+- 入场下单后交易所返回 -1007 超时（订单实际已成交）：按客户端订单ID查到已成交的订单，不重复下单
+- 止盈单被拒绝：保留止损单，括号订单照常建立
+
+运行方式：
+
+	go run test/executor/test_bracket.go
+*/
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/fakebinance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const accountID = "bracket_test"
+
+// decision 开多决策（止损-2%，止盈+4%），时间不同的决策生成不同的客户端订单ID
+func decision(symbol string, price, quantity float64) *executor.Decision {
+	return &executor.Decision{
+		AccountID:  accountID,
+		Symbol:     symbol,
+		Action:     executor.ActionOpenLong,
+		Quantity:   quantity,
+		StopLoss:   price * 0.98,
+		TakeProfit: price * 1.04,
+		Timestamp:  time.Now().UnixNano(),
+	}
+}
+
+// orderTypes 订单的 类型:状态 列表
+func orderTypes(orders []binance.Order) []string {
+	list := make([]string, 0, len(orders))
+	for _, o := range orders {
+		list = append(list, o.Type+":"+o.Status)
+	}
+	return list
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 括号订单测试开始 ===")
+
+	fake := fakebinance.New("test-key", "test-secret")
+	defer fake.Close()
+	for _, symbol := range []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "BNBUSDT"} {
+		fake.AddSymbol(fakebinance.Symbol{Symbol: symbol, Price: 1000, TickSize: 0.1, StepSize: 0.001})
+	}
+
+	client := binance.NewClient("test-key", "test-secret", fake.URL, "")
+	exec := executor.NewExecutor(accountID, client)
+	if err := exec.LoadSymbolRules(); err != nil {
+		utils.Fatal("加载交易规则失败", zap.Error(err))
+	}
+
+	// 1. 正常入场
+	fmt.Println("===== 正常入场 =====")
+	err := exec.Execute(decision("BTCUSDT", 1000, 0.5))
+	amt, _ := fake.Position("BTCUSDT")
+	fmt.Printf("错误: %v 持仓: %v（期望<nil> 0.5）\n", err, amt)
+	fmt.Printf("订单: %v（期望[MARKET:FILLED STOP_MARKET:NEW TAKE_PROFIT_MARKET:NEW]）\n", orderTypes(fake.Orders("BTCUSDT")))
+	if b := exec.GetBracket("BTCUSDT"); b != nil {
+		fmt.Printf("括号订单: 数量=%v 止损价=%v 止盈价=%v（期望0.5 980 1040）\n", b.Quantity, b.StopLoss, b.TakeProfit)
+	} else {
+		fmt.Println("括号订单: 无（期望有）")
+	}
+
+	// 2. 止损单被拒绝：紧急平仓
+	fmt.Println("\n===== 止损单被拒绝 =====")
+	fake.RejectNextOrder(binance.OrderTypeStopMarket, -2021, "Order would immediately trigger.")
+	err = exec.Execute(decision("ETHUSDT", 1000, 0.5))
+	amt, _ = fake.Position("ETHUSDT")
+	fmt.Printf("错误: %v（期望止损价已被越过）\n", err)
+	fmt.Printf("持仓: %v 括号订单: %v（期望0 <nil>）\n", amt, exec.GetBracket("ETHUSDT"))
+	fmt.Printf("订单: %v（期望[MARKET:FILLED MARKET:FILLED]，入场后紧急平仓）\n", orderTypes(fake.Orders("ETHUSDT")))
+	orders := fake.Orders("ETHUSDT")
+	if len(orders) == 2 {
+		fmt.Printf("平仓单: 方向=%s 只减仓=%v（期望SELL true）\n", orders[1].Side, orders[1].ReduceOnly)
+	}
+
+	// 3. 入场超时后幂等重试：订单实际已成交，重试时按客户端订单ID查到，不重复下单
+	fmt.Println("\n===== 入场超时后重试 =====")
+	before := fake.Requests(http.MethodPost, binance.EndpointOrder)
+	fake.TimeoutNext(http.MethodPost, binance.EndpointOrder)
+	err = exec.Execute(decision("SOLUSDT", 1000, 0.5))
+	amt, _ = fake.Position("SOLUSDT")
+	fmt.Printf("错误: %v 持仓: %v（期望<nil> 0.5，只成交一次）\n", err, amt)
+	fmt.Printf("订单: %v（期望[MARKET:FILLED STOP_MARKET:NEW TAKE_PROFIT_MARKET:NEW]）\n", orderTypes(fake.Orders("SOLUSDT")))
+	fmt.Printf("下单请求: %d（期望3：超时的入场单、止损单、止盈单）\n", fake.Requests(http.MethodPost, binance.EndpointOrder)-before)
+	if b := exec.GetBracket("SOLUSDT"); b != nil {
+		orders := fake.Orders("SOLUSDT")
+		fmt.Printf("括号订单入场单ID与交易所一致: %v（期望true）\n", b.EntryOrderID == orders[0].OrderID)
+	} else {
+		fmt.Println("括号订单: 无（期望有）")
+	}
+
+	// 4. 止盈单被拒绝：保留止损单
+	fmt.Println("\n===== 止盈单被拒绝 =====")
+	fake.RejectNextOrder(binance.OrderTypeTakeProfitMarket, -4014, "Price not increased by tick size.")
+	err = exec.Execute(decision("BNBUSDT", 1000, 0.5))
+	amt, _ = fake.Position("BNBUSDT")
+	fmt.Printf("错误: %v 持仓: %v（期望<nil> 0.5）\n", err, amt)
+	fmt.Printf("订单: %v（期望[MARKET:FILLED STOP_MARKET:NEW]）\n", orderTypes(fake.Orders("BNBUSDT")))
+	if b := exec.GetBracket("BNBUSDT"); b != nil {
+		fmt.Printf("括号订单: 止损单=%v 止盈单=%d（期望非0 0）\n", b.StopLossOrderID != 0, b.TakeProfitOrderID)
+	} else {
+		fmt.Println("括号订单: 无（期望有）")
+	}
+
+	fmt.Printf("\n未实现的接口: %v（期望[]）\n", fake.Unhandled())
+	utils.Info("=== 括号订单测试结束 ===")
+}
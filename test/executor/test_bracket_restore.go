@@ -0,0 +1,132 @@
+/*
+括号订单重启恢复测试程序（模拟币安接口，不需要真实API密钥）
+
+测试内容：
+- 建立括号订单后保存到 <目录>/<账号ID>.brackets.json
+- 重新创建执行器（模拟重启）后加载括号订单：数量、止损单ID与重启前相同
+- 停机期间手动减仓：重启后第一次监控检查按新的持仓数量重新挂出止损止盈单
+- 停机期间止损触发：重启后第一次监控检查按止损结束括号订单，保存的文件中不再有该括号订单
+
+运行方式：
+
+	go run test/executor/test_bracket_restore.go
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/fakebinance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	accountID = "restore_test"
+	symbol    = "BTCUSDT"
+)
+
+// restart 模拟重启：创建新的执行器并加载保存的括号订单
+func restart(client *binance.Client, dir string) *executor.Executor {
+	exec := executor.NewExecutor(accountID, client)
+	exec.LoadBrackets(dir)
+	if err := exec.LoadSymbolRules(); err != nil {
+		utils.Fatal("加载交易规则失败", zap.Error(err))
+	}
+	return exec
+}
+
+// exitLegs 未结束的止损止盈单（类型 → 数量）
+func exitLegs(fake *fakebinance.Server) map[string]string {
+	legs := make(map[string]string)
+	for _, o := range fake.Orders(symbol) {
+		if !o.IsFinal() {
+			legs[o.Type] = o.OrigQty
+		}
+	}
+	return legs
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 括号订单重启恢复测试开始 ===")
+
+	dir, err := os.MkdirTemp("", "brackets")
+	if err != nil {
+		utils.Fatal("创建临时目录失败", zap.Error(err))
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, accountID+".brackets.json")
+
+	fake := fakebinance.New("test-key", "test-secret")
+	defer fake.Close()
+	fake.AddSymbol(fakebinance.Symbol{Symbol: symbol, Price: 1000, TickSize: 0.1, StepSize: 0.001})
+	client := binance.NewClient("test-key", "test-secret", fake.URL, "")
+
+	// 1. 建立括号订单
+	exec := restart(client, dir)
+	err = exec.Execute(&executor.Decision{
+		AccountID:  accountID,
+		Symbol:     symbol,
+		Action:     executor.ActionOpenLong,
+		Quantity:   0.5,
+		StopLoss:   980,
+		TakeProfit: 1040,
+		Timestamp:  time.Now().UnixMilli(),
+	})
+	before := exec.GetBracket(symbol)
+	if before == nil {
+		utils.Fatal("括号订单未建立", zap.Error(err))
+	}
+	_, statErr := os.Stat(path)
+	fmt.Printf("入场: %v 已保存: %v（期望<nil> true）\n", err, statErr == nil)
+
+	// 2. 重启后加载
+	fmt.Println("\n===== 重启 =====")
+	exec = restart(client, dir)
+	b := exec.GetBracket(symbol)
+	if b == nil {
+		utils.Fatal("重启后没有括号订单")
+	}
+	fmt.Printf("加载: 数量=%v 止损单ID相同=%v 持仓逻辑=%v（期望0.5 true true）\n",
+		b.Quantity, b.StopLossOrderID == before.StopLossOrderID, exec.GetThesis(symbol) != nil)
+
+	// 3. 停机期间手动减仓0.2
+	fmt.Println("\n===== 停机期间减仓 =====")
+	_, err = client.PlaceOrder(&binance.OrderRequest{Symbol: symbol, Side: binance.SideSell, Type: binance.OrderTypeMarket, Quantity: "0.2", ReduceOnly: true})
+	if err != nil {
+		utils.Fatal("手动减仓失败", zap.Error(err))
+	}
+	exec = restart(client, dir)
+	exec.CheckBrackets()
+	b = exec.GetBracket(symbol)
+	fmt.Printf("监控检查后: 止损止盈单=%v 括号订单数量=%v（期望map[STOP_MARKET:0.3 TAKE_PROFIT_MARKET:0.3] 0.3）\n", exitLegs(fake), b.Quantity)
+
+	// 4. 停机期间止损触发
+	fmt.Println("\n===== 停机期间止损 =====")
+	fake.SetPrice(symbol, 975)
+	exec = restart(client, dir)
+	fmt.Printf("加载: 括号订单存在=%v（期望true）\n", exec.GetBracket(symbol) != nil)
+	exec.CheckBrackets()
+	amt, _ := fake.Position(symbol)
+	data, _ := os.ReadFile(path)
+	fmt.Printf("监控检查后: 括号订单=%v 持仓=%v 挂单=%v 文件=%s（期望<nil> 0 map[] []）\n",
+		exec.GetBracket(symbol), amt, exitLegs(fake), strings.TrimSpace(string(data)))
+
+	exec = restart(client, dir)
+	fmt.Printf("再次重启: 括号订单数=%d（期望0）\n", len(exec.GetBrackets()))
+
+	utils.Info("=== 括号订单重启恢复测试结束 ===")
+}
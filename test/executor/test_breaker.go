@@ -0,0 +1,178 @@
+/*
+最大回撤熔断测试程序（模拟币安接口，不需要真实API密钥）
+
+测试内容：
+- 最大回撤5%：首次采样记录权益峰值10000
+- 持仓浮亏未超过上限时照常交易；余额下降后权益回撤超过5%：进入只平仓模式，开仓决策被拒绝，平仓决策照常执行
+- 熔断状态保存到文件：重新创建的执行器加载后仍处于只平仓模式
+- 手动恢复（Rearm）：以当前权益作为新的峰值，恢复开仓
+- 资金划转：转出后按划转金额下调峰值，不计为回撤、不触发熔断；转入后峰值同步上调，调整后的峰值保存到文件
+- 现货账号：权益 = USDT余额 + 其他资产按最优买价折算，没有USDT交易对的资产不计入；币价下跌导致回撤超过上限时熔断
+- 币本位合约账号：保证金按币种计算，设置熔断时忽略
+
+运行方式：
+
+	go run test/executor/test_breaker.go
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/fakebinance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const accountID = "breaker_test"
+
+// decision 交易对的决策（开仓决策止损-2%，止盈+4%）
+func decision(symbol, action string, price, quantity float64) *executor.Decision {
+	return &executor.Decision{
+		AccountID:  accountID,
+		Symbol:     symbol,
+		Action:     action,
+		Quantity:   quantity,
+		StopLoss:   price * 0.98,
+		TakeProfit: price * 1.04,
+		Timestamp:  time.Now().UnixNano(),
+	}
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 最大回撤熔断测试开始 ===")
+
+	dir, err := os.MkdirTemp("", "breaker")
+	if err != nil {
+		utils.Fatal("创建临时目录失败", zap.Error(err))
+	}
+	defer os.RemoveAll(dir)
+
+	fake := fakebinance.New("test-key", "test-secret")
+	defer fake.Close()
+	fake.AddSymbol(fakebinance.Symbol{Symbol: "BTCUSDT", Price: 1000, TickSize: 0.1, StepSize: 0.001})
+	fake.AddSymbol(fakebinance.Symbol{Symbol: "ETHUSDT", Price: 1000, TickSize: 0.1, StepSize: 0.001})
+
+	client := binance.NewClient("test-key", "test-secret", fake.URL, "")
+	breakerCfg := config.CircuitBreakerConfig{MaxDrawdownPct: 5}
+	exec := executor.NewExecutor(accountID, client)
+	exec.SetCircuitBreaker(breakerCfg, dir)
+	if err := exec.LoadSymbolRules(); err != nil {
+		utils.Fatal("加载交易规则失败", zap.Error(err))
+	}
+
+	// 1. 首次采样
+	exec.CheckDrawdown()
+	status := exec.BreakerStatus()
+	fmt.Printf("峰值: %v 回撤: %v%% 只平仓: %v（期望10000 0 false）\n", status.PeakEquity, status.DrawdownPct, status.CloseOnly)
+
+	// 2. 开多20（名义价值20000，止损980），价格跌至990时浮亏200、手续费8，回撤2.08%
+	err = exec.Execute(decision("BTCUSDT", executor.ActionOpenLong, 1000, 20))
+	fmt.Printf("\n开仓: %v（期望<nil>）\n", err)
+	fake.SetPrice("BTCUSDT", 990)
+	exec.CheckDrawdown()
+	fmt.Printf("价格990: 回撤=%v%% 只平仓=%v（期望2.08 false）\n", exec.BreakerStatus().DrawdownPct, exec.CloseOnly())
+
+	// 余额降至9400（如其他持仓亏损），权益9200，回撤8%
+	fake.SetBalance(9400)
+	exec.CheckDrawdown()
+	status = exec.BreakerStatus()
+	fmt.Printf("余额降至9400: 回撤=%v%% 只平仓=%v 熔断时间已记录=%v（期望8 true true）\n", status.DrawdownPct, status.CloseOnly, status.TrippedAt != nil)
+
+	// 3. 只平仓模式：拒绝开仓，平仓照常
+	fmt.Println("\n===== 只平仓模式 =====")
+	err = exec.Execute(decision("ETHUSDT", executor.ActionOpenLong, 1000, 1))
+	amt, _ := fake.Position("ETHUSDT")
+	fmt.Printf("开仓ETHUSDT: %v 持仓=%v（期望只平仓模式的错误 0）\n", err, amt)
+
+	// 4. 重新创建执行器：从文件加载熔断状态
+	reloaded := executor.NewExecutor(accountID, client)
+	reloaded.SetCircuitBreaker(breakerCfg, dir)
+	fmt.Printf("重新加载: 只平仓=%v 峰值=%v（期望true 10000）\n", reloaded.CloseOnly(), reloaded.BreakerStatus().PeakEquity)
+
+	err = exec.Execute(decision("BTCUSDT", executor.ActionClose, 990, 0))
+	amt, _ = fake.Position("BTCUSDT")
+	fmt.Printf("平仓BTCUSDT: %v 持仓=%v 括号订单=%v（期望<nil> 0 <nil>）\n", err, amt, exec.GetBracket("BTCUSDT"))
+
+	// 5. 手动恢复：当前权益为新峰值
+	fmt.Println("\n===== 手动恢复 =====")
+	status, err = exec.Rearm()
+	fmt.Printf("恢复: %v 只平仓=%v 峰值=%.2f 回撤=%v%%（期望<nil> false %.2f 0）\n", err, status.CloseOnly, status.PeakEquity, status.DrawdownPct, fake.Balance())
+	err = exec.Execute(decision("ETHUSDT", executor.ActionOpenLong, 1000, 1))
+	amt, _ = fake.Position("ETHUSDT")
+	fmt.Printf("开仓ETHUSDT: %v 持仓=%v（期望<nil> 1）\n", err, amt)
+
+	reloaded = executor.NewExecutor(accountID, client)
+	reloaded.SetCircuitBreaker(breakerCfg, dir)
+	fmt.Printf("恢复后重新加载: 只平仓=%v（期望false）\n", reloaded.CloseOnly())
+
+	// 6. 资金划转：转出2000不触发熔断，转入1000不被当作盈利
+	fmt.Println("\n===== 资金划转 =====")
+	transfer := executor.NewExecutor("breaker_transfer_test", client)
+	transfer.SetCircuitBreaker(breakerCfg, dir)
+	transfer.CheckDrawdown()
+	peak := transfer.BreakerStatus().PeakEquity
+
+	fake.SetBalance(fake.Balance() - 2000)
+	transfer.AdjustForTransfer(-2000)
+	transfer.CheckDrawdown()
+	status = transfer.BreakerStatus()
+	fmt.Printf("转出2000: 峰值下调=%.2f 回撤=%v%% 只平仓=%v（期望2000.00 0 false）\n", peak-status.PeakEquity, status.DrawdownPct, status.CloseOnly)
+
+	fake.SetBalance(fake.Balance() + 1000)
+	transfer.AdjustForTransfer(1000)
+	transfer.CheckDrawdown()
+	status = transfer.BreakerStatus()
+	fmt.Printf("转入1000: 峰值下调=%.2f 回撤=%v%% 只平仓=%v（期望1000.00 0 false）\n", peak-status.PeakEquity, status.DrawdownPct, status.CloseOnly)
+
+	reloaded = executor.NewExecutor("breaker_transfer_test", client)
+	reloaded.SetCircuitBreaker(breakerCfg, dir)
+	fmt.Printf("重新加载: 峰值下调=%.2f（期望1000.00）\n", peak-reloaded.BreakerStatus().PeakEquity)
+
+	// 7. 现货账号：持有1 BTC和9000 USDT，另持有没有USDT交易对的资产
+	fmt.Println("\n===== 现货权益 =====")
+	spot := fakebinance.NewSpot("test-key", "test-secret")
+	defer spot.Close()
+	spot.AddSymbol(fakebinance.Symbol{Symbol: "BTCUSDT", Price: 1000.1, TickSize: 0.1, StepSize: 0.001})
+	spot.SetSpotBalance("USDT", 9000)
+	spot.SetSpotBalance("BTC", 1)
+	spot.SetSpotBalance("XYZ", 5)
+
+	spotExec := executor.NewExecutor("breaker_spot_test", binance.NewSpotClient("test-key", "test-secret", spot.URL, ""))
+	spotExec.SetCircuitBreaker(breakerCfg, dir)
+	spotExec.CheckDrawdown()
+	status = spotExec.BreakerStatus()
+	fmt.Printf("峰值: %v 只平仓: %v（期望10000 false，BTC按买一价1000折算，XYZ不计入）\n", status.PeakEquity, status.CloseOnly)
+
+	spot.SetPrice("BTCUSDT", 900.1)
+	spotExec.CheckDrawdown()
+	status = spotExec.BreakerStatus()
+	fmt.Printf("BTC跌至900: 权益=%v 回撤=%v%% 只平仓=%v（期望9900 1 false）\n", status.Equity, status.DrawdownPct, status.CloseOnly)
+
+	spot.SetPrice("BTCUSDT", 400.1)
+	spotExec.CheckDrawdown()
+	status = spotExec.BreakerStatus()
+	fmt.Printf("BTC跌至400: 权益=%v 回撤=%v%% 只平仓=%v（期望9400 6 true）\n", status.Equity, status.DrawdownPct, status.CloseOnly)
+
+	// 8. 币本位合约账号：不支持熔断
+	fmt.Println("\n===== 币本位合约 =====")
+	coinM := executor.NewExecutor("breaker_coinm_test", binance.NewCoinMClient("test-key", "test-secret", fake.URL, ""))
+	coinM.SetCircuitBreaker(breakerCfg, dir)
+	coinM.CheckDrawdown()
+	status = coinM.BreakerStatus()
+	fmt.Printf("上限: %v 峰值: %v 只平仓: %v（期望0 0 false，熔断被忽略）\n", status.MaxDrawdownPct, status.PeakEquity, status.CloseOnly)
+
+	utils.Info("=== 最大回撤熔断测试结束 ===")
+}
@@ -0,0 +1,122 @@
+/*
+全部平仓测试程序（模拟币安接口，不需要真实API密钥）
+
+测试内容：
+- 合约：有持仓和未成交的加仓限价单时，先撤单再平仓，平仓后没有挂单、没有持仓
+- 现货：手动挂出的限价卖单冻结了全部余额，先撤单释放余额再市价卖出（先卖出会因余额不足被拒绝）
+
+运行方式：
+
+	go run test/executor/test_close_all.go
+*/
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/fakebinance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const symbol = "BTCUSDT"
+
+// manualLimit 在交易所手动挂出限价单（不经过执行器）
+func manualLimit(client *binance.Client, side, quantity, price string) {
+	_, err := client.PlaceOrder(&binance.OrderRequest{
+		Symbol:      symbol,
+		Side:        side,
+		Type:        binance.OrderTypeLimit,
+		Quantity:    quantity,
+		Price:       price,
+		TimeInForce: binance.TimeInForceGTC,
+	})
+	if err != nil {
+		utils.Fatal("手动下单失败", zap.Error(err))
+	}
+}
+
+// openOrders 未结束的订单数
+func openOrders(fake *fakebinance.Server) int {
+	n := 0
+	for _, o := range fake.Orders(symbol) {
+		if !o.IsFinal() {
+			n++
+		}
+	}
+	return n
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 全部平仓测试开始 ===")
+
+	// 1. 合约：持仓 + 止损止盈单 + 未成交的加仓单
+	fmt.Println("===== 合约 =====")
+	fake := fakebinance.New("test-key", "test-secret")
+	defer fake.Close()
+	fake.AddSymbol(fakebinance.Symbol{Symbol: symbol, Price: 1000, TickSize: 0.1, StepSize: 0.001})
+
+	client := binance.NewClient("test-key", "test-secret", fake.URL, "")
+	exec := executor.NewExecutor("close_all_test", client)
+	if err := exec.LoadSymbolRules(); err != nil {
+		utils.Fatal("加载交易规则失败", zap.Error(err))
+	}
+	err := exec.Execute(&executor.Decision{
+		AccountID:  "close_all_test",
+		Symbol:     symbol,
+		Action:     executor.ActionOpenLong,
+		Quantity:   0.5,
+		StopLoss:   980,
+		TakeProfit: 1040,
+		Timestamp:  time.Now().UnixMilli(),
+	})
+	manualLimit(client, binance.SideBuy, "0.3", "995")
+	fmt.Printf("入场: %v 挂单数: %d（期望<nil> 3）\n", err, openOrders(fake))
+
+	closed, err := exec.CloseAll(nil)
+	amt, _ := fake.Position(symbol)
+	fmt.Printf("全部平仓: %v %v 持仓: %v 挂单数: %d 括号订单: %v（期望[BTCUSDT] <nil> 0 0 <nil>）\n",
+		closed, err, amt, openOrders(fake), exec.GetBracket(symbol))
+
+	// 平仓后价格跌破加仓单价格也不会重新开仓
+	fake.SetPrice(symbol, 990)
+	amt, _ = fake.Position(symbol)
+	fmt.Printf("价格跌至990后持仓: %v（期望0）\n", amt)
+
+	// 2. 现货：限价卖单冻结了全部余额
+	fmt.Println("\n===== 现货 =====")
+	spot := fakebinance.NewSpot("test-key", "test-secret")
+	defer spot.Close()
+	spot.AddSymbol(fakebinance.Symbol{Symbol: symbol, Price: 1000, TickSize: 0.1, StepSize: 0.001})
+	spot.SetSpotBalance("BTC", 0.4)
+
+	spotClient := binance.NewSpotClient("test-key", "test-secret", spot.URL, "")
+	spotExec := executor.NewExecutor("close_all_spot_test", spotClient)
+	if err := spotExec.LoadSymbolRules(); err != nil {
+		utils.Fatal("加载交易规则失败", zap.Error(err))
+	}
+	manualLimit(spotClient, binance.SideSell, "0.4", "1100")
+	free, locked := spot.SpotBalance("BTC")
+	fmt.Printf("限价卖单挂出后BTC: 可用=%.3f 冻结=%.3f（期望0.000 0.400）\n", free, locked)
+
+	closed, err = spotExec.CloseAll([]string{symbol})
+	free, locked = spot.SpotBalance("BTC")
+	fmt.Printf("全部平仓: %v %v 挂单数: %d BTC: 可用=%.3f 冻结=%.3f（期望[BTCUSDT] <nil> 0 0.000 0.000）\n",
+		closed, err, openOrders(spot), free, locked)
+
+	if unhandled := spot.Unhandled(); len(unhandled) > 0 {
+		fmt.Printf("未实现的请求: %v（期望无）\n", unhandled)
+	}
+
+	utils.Info("=== 全部平仓测试结束 ===")
+}
@@ -0,0 +1,141 @@
+/*
+止损止盈单数量同步测试程序（模拟币安接口，不需要真实API密钥）
+
+测试内容：
+- 开多0.5后在交易所手动平掉0.2：检查括号订单时按0.3重新挂出止损止盈单，撤销旧单
+- 再加回0.2（恢复为最初的0.5）：重新挂出的止损单使用新的客户端订单ID，不会复用最初已撤销的0.5止损单
+- 再手动加仓0.2：按0.7重新挂出，加仓部分也受止损保护
+- 同步后价格跌破止损价：只减仓的止损单平掉全部0.7，检查括号订单时记录止损
+
+运行方式：
+
+	go run test/executor/test_exit_sync.go
+*/
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/fakebinance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const symbol = "BTCUSDT"
+
+// manualOrder 在交易所手动下市价单（不经过执行器）
+func manualOrder(client *binance.Client, side, quantity string, reduceOnly bool) {
+	_, err := client.PlaceOrder(&binance.OrderRequest{Symbol: symbol, Side: side, Type: binance.OrderTypeMarket, Quantity: quantity, ReduceOnly: reduceOnly})
+	if err != nil {
+		utils.Fatal("手动下单失败", zap.Error(err))
+	}
+}
+
+// openLegs 未结束的止损止盈单（类型 → 数量）
+func openLegs(fake *fakebinance.Server) map[string]string {
+	legs := make(map[string]string)
+	for _, o := range fake.Orders(symbol) {
+		if !o.IsFinal() {
+			legs[o.Type] = o.OrigQty
+		}
+	}
+	return legs
+}
+
+// stopOrder 交易所中括号订单当前引用的止损单
+func stopOrder(fake *fakebinance.Server, b *executor.Bracket) *binance.Order {
+	for _, o := range fake.Orders(symbol) {
+		if o.OrderID == b.StopLossOrderID {
+			return &o
+		}
+	}
+	return nil
+}
+
+// canceled 已撤销的订单数
+func canceled(fake *fakebinance.Server) int {
+	n := 0
+	for _, o := range fake.Orders(symbol) {
+		if o.Status == binance.OrderStatusCanceled {
+			n++
+		}
+	}
+	return n
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 止损止盈单数量同步测试开始 ===")
+
+	fake := fakebinance.New("test-key", "test-secret")
+	defer fake.Close()
+	fake.AddSymbol(fakebinance.Symbol{Symbol: symbol, Price: 1000, TickSize: 0.1, StepSize: 0.001})
+
+	client := binance.NewClient("test-key", "test-secret", fake.URL, "")
+	exec := executor.NewExecutor("exit_sync_test", client)
+	if err := exec.LoadSymbolRules(); err != nil {
+		utils.Fatal("加载交易规则失败", zap.Error(err))
+	}
+
+	err := exec.Execute(&executor.Decision{
+		AccountID:  "exit_sync_test",
+		Symbol:     symbol,
+		Action:     executor.ActionOpenLong,
+		Quantity:   0.5,
+		StopLoss:   980,
+		TakeProfit: 1040,
+		Timestamp:  time.Now().UnixMilli(),
+	})
+	fmt.Printf("入场: %v 挂单: %v（期望<nil> map[STOP_MARKET:0.5 TAKE_PROFIT_MARKET:0.5]）\n", err, openLegs(fake))
+	b := exec.GetBracket(symbol)
+	if b == nil {
+		utils.Fatal("括号订单未建立")
+	}
+	firstStop := stopOrder(fake, b)
+
+	// 1. 手动部分平仓
+	fmt.Println("\n===== 部分平仓 =====")
+	manualOrder(client, binance.SideSell, "0.2", true)
+	exec.CheckBrackets()
+	fmt.Printf("挂单: %v 已撤销: %d（期望map[STOP_MARKET:0.3 TAKE_PROFIT_MARKET:0.3] 2）\n", openLegs(fake), canceled(fake))
+	fmt.Printf("括号订单数量: %v（期望0.3）\n", b.Quantity)
+
+	// 2. 加回最初的数量：不能复用最初已撤销的止损单
+	fmt.Println("\n===== 恢复为最初数量 =====")
+	manualOrder(client, binance.SideBuy, "0.2", false)
+	exec.CheckBrackets()
+	fmt.Printf("挂单: %v 已撤销: %d（期望map[STOP_MARKET:0.5 TAKE_PROFIT_MARKET:0.5] 4）\n", openLegs(fake), canceled(fake))
+	if stop := stopOrder(fake, b); stop != nil {
+		fmt.Printf("括号订单数量: %v 止损单状态: %s 数量: %s（期望0.5 NEW 0.5）\n", b.Quantity, stop.Status, stop.OrigQty)
+		fmt.Printf("与最初的止损单不同: 订单ID=%v 客户端订单ID=%v（期望true true）\n", stop.OrderID != firstStop.OrderID, stop.ClientOrderID != firstStop.ClientOrderID)
+	} else {
+		fmt.Println("止损单: 交易所中不存在（期望存在）")
+	}
+
+	// 3. 手动加仓
+	fmt.Println("\n===== 加仓 =====")
+	manualOrder(client, binance.SideBuy, "0.2", false)
+	exec.CheckBrackets()
+	fmt.Printf("挂单: %v 已撤销: %d（期望map[STOP_MARKET:0.7 TAKE_PROFIT_MARKET:0.7] 6）\n", openLegs(fake), canceled(fake))
+	if stop := stopOrder(fake, b); stop != nil {
+		fmt.Printf("括号订单数量: %v 止损单状态: %s 数量: %s（期望0.7 NEW 0.7）\n", b.Quantity, stop.Status, stop.OrigQty)
+	}
+
+	// 4. 跌破止损价
+	fmt.Println("\n===== 止损触发 =====")
+	fake.SetPrice(symbol, 975)
+	exec.CheckBrackets()
+	amt, _ := fake.Position(symbol)
+	fmt.Printf("持仓: %v 括号订单: %v 挂单: %v（期望0 <nil> map[]）\n", amt, exec.GetBracket(symbol), openLegs(fake))
+
+	utils.Info("=== 止损止盈单数量同步测试结束 ===")
+}
@@ -0,0 +1,137 @@
+/*
+部分成交后止损止盈单同步测试程序（模拟币安接口，不需要真实API密钥）
+
+测试内容：
+- 开多0.5后手动挂出只减仓的限价卖单0.4：部分成交0.1时按0.4重新挂出止损止盈单，再成交0.2时按0.2重新挂出
+- 手动挂出限价买单0.3（加仓）：部分成交0.1时按0.3重新挂出，已成交的加仓部分也受止损保护
+- 每次同步后只有一组止损止盈单生效，旧单全部撤销；数量未变化时不重新挂单
+
+运行方式：
+
+	go run test/executor/test_partial_fill.go
+*/
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/fakebinance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const symbol = "BTCUSDT"
+
+// manualLimit 在交易所手动挂出限价单（不经过执行器）
+func manualLimit(client *binance.Client, side, quantity, price string, reduceOnly bool) *binance.Order {
+	order, err := client.PlaceOrder(&binance.OrderRequest{
+		Symbol:      symbol,
+		Side:        side,
+		Type:        binance.OrderTypeLimit,
+		Quantity:    quantity,
+		Price:       price,
+		TimeInForce: binance.TimeInForceGTC,
+		ReduceOnly:  reduceOnly,
+	})
+	if err != nil {
+		utils.Fatal("手动下单失败", zap.Error(err))
+	}
+	return order
+}
+
+// exitLegs 未结束的止损止盈单（类型 → 数量，同类型有多笔时用逗号连接）
+func exitLegs(fake *fakebinance.Server) map[string]string {
+	legs := make(map[string]string)
+	for _, o := range fake.Orders(symbol) {
+		if o.IsFinal() || (o.Type != binance.OrderTypeStopMarket && o.Type != binance.OrderTypeTakeProfitMarket) {
+			continue
+		}
+		if legs[o.Type] != "" {
+			legs[o.Type] += ","
+		}
+		legs[o.Type] += o.OrigQty
+	}
+	return legs
+}
+
+// orderStatus 交易所中订单的状态和已成交数量
+func orderStatus(fake *fakebinance.Server, orderID int64) string {
+	for _, o := range fake.Orders(symbol) {
+		if o.OrderID == orderID {
+			return o.Status + " " + o.ExecutedQty
+		}
+	}
+	return "不存在"
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 部分成交后止损止盈单同步测试开始 ===")
+
+	fake := fakebinance.New("test-key", "test-secret")
+	defer fake.Close()
+	fake.AddSymbol(fakebinance.Symbol{Symbol: symbol, Price: 1000, TickSize: 0.1, StepSize: 0.001})
+
+	client := binance.NewClient("test-key", "test-secret", fake.URL, "")
+	exec := executor.NewExecutor("partial_fill_test", client)
+	if err := exec.LoadSymbolRules(); err != nil {
+		utils.Fatal("加载交易规则失败", zap.Error(err))
+	}
+
+	err := exec.Execute(&executor.Decision{
+		AccountID:  "partial_fill_test",
+		Symbol:     symbol,
+		Action:     executor.ActionOpenLong,
+		Quantity:   0.5,
+		StopLoss:   980,
+		TakeProfit: 1040,
+		Timestamp:  time.Now().UnixMilli(),
+	})
+	fmt.Printf("入场: %v 止损止盈单: %v（期望<nil> map[STOP_MARKET:0.5 TAKE_PROFIT_MARKET:0.5]）\n", err, exitLegs(fake))
+	b := exec.GetBracket(symbol)
+	if b == nil {
+		utils.Fatal("括号订单未建立")
+	}
+
+	// 1. 只减仓的限价卖单部分成交
+	fmt.Println("\n===== 减仓单部分成交 =====")
+	sell := manualLimit(client, binance.SideSell, "0.4", "1010", true)
+	exec.CheckBrackets()
+	fmt.Printf("挂单未成交: 止损止盈单: %v（期望map[STOP_MARKET:0.5 TAKE_PROFIT_MARKET:0.5]，数量未变化不重新挂单）\n", exitLegs(fake))
+
+	fake.PartialFill(sell.OrderID, 0.1)
+	exec.CheckBrackets()
+	amt, _ := fake.Position(symbol)
+	fmt.Printf("卖单: %s 持仓: %v（期望PARTIALLY_FILLED 0.1 0.4）\n", orderStatus(fake, sell.OrderID), amt)
+	fmt.Printf("止损止盈单: %v 括号订单数量: %v（期望map[STOP_MARKET:0.4 TAKE_PROFIT_MARKET:0.4] 0.4）\n", exitLegs(fake), b.Quantity)
+
+	fake.PartialFill(sell.OrderID, 0.2)
+	exec.CheckBrackets()
+	amt, _ = fake.Position(symbol)
+	fmt.Printf("卖单: %s 持仓: %v（期望PARTIALLY_FILLED 0.3 0.2）\n", orderStatus(fake, sell.OrderID), amt)
+	fmt.Printf("止损止盈单: %v 括号订单数量: %v（期望map[STOP_MARKET:0.2 TAKE_PROFIT_MARKET:0.2] 0.2）\n", exitLegs(fake), b.Quantity)
+	if _, err := client.CancelOrder(symbol, sell.OrderID); err != nil {
+		utils.Fatal("撤销卖单失败", zap.Error(err))
+	}
+
+	// 2. 加仓的限价买单部分成交
+	fmt.Println("\n===== 加仓单部分成交 =====")
+	buy := manualLimit(client, binance.SideBuy, "0.3", "995", false)
+	fake.PartialFill(buy.OrderID, 0.1)
+	exec.CheckBrackets()
+	amt, entry := fake.Position(symbol)
+	fmt.Printf("买单: %s 持仓: %v（期望PARTIALLY_FILLED 0.1 0.3）\n", orderStatus(fake, buy.OrderID), amt)
+	fmt.Printf("止损止盈单: %v 括号订单数量: %v（期望map[STOP_MARKET:0.3 TAKE_PROFIT_MARKET:0.3] 0.3）\n", exitLegs(fake), b.Quantity)
+	fmt.Printf("括号订单开仓均价: %.2f 交易所开仓均价: %.2f（期望两者相同，按加仓后的均价更新）\n", b.EntryPrice, entry)
+
+	utils.Info("=== 部分成交后止损止盈单同步测试结束 ===")
+}
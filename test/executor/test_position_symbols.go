@@ -0,0 +1,102 @@
+/*
+按交易所持仓列出交易对测试程序（模拟币安接口，不需要真实API密钥）
+
+测试内容：
+- 合约：括号订单的持仓、手动开的仓（不在传入的交易对中）都列出，没有持仓的交易对不列出
+- 合约：持仓被平掉但括号订单还未被监控结束时不列出
+- 现货：传入的交易对按资产余额判断，余额低于最小下单数量（粉尘）时不列出
+
+运行方式：
+
+	go run test/executor/test_position_symbols.go
+*/
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/fakebinance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// marketOrder 在交易所手动市价下单（不经过执行器）
+func marketOrder(client *binance.Client, symbol, side, quantity string, reduceOnly bool) {
+	_, err := client.PlaceOrder(&binance.OrderRequest{Symbol: symbol, Side: side, Type: binance.OrderTypeMarket, Quantity: quantity, ReduceOnly: reduceOnly})
+	if err != nil {
+		utils.Fatal("手动下单失败", zap.Error(err))
+	}
+}
+
+// positionSymbols 有持仓的交易对（排序后输出）
+func positionSymbols(exec *executor.Executor, symbols []string) string {
+	open, err := exec.PositionSymbols(symbols)
+	if err != nil {
+		return "错误: " + err.Error()
+	}
+	slices.Sort(open)
+	return fmt.Sprint(open)
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 按交易所持仓列出交易对测试开始 ===")
+
+	// 1. 合约
+	fmt.Println("===== 合约 =====")
+	fake := fakebinance.New("test-key", "test-secret")
+	defer fake.Close()
+	for _, symbol := range []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"} {
+		fake.AddSymbol(fakebinance.Symbol{Symbol: symbol, Price: 1000, TickSize: 0.1, StepSize: 0.001})
+	}
+	client := binance.NewClient("test-key", "test-secret", fake.URL, "")
+	exec := executor.NewExecutor("position_symbols_test", client)
+	if err := exec.LoadSymbolRules(); err != nil {
+		utils.Fatal("加载交易规则失败", zap.Error(err))
+	}
+
+	pool := []string{"BTCUSDT", "ETHUSDT"}
+	fmt.Printf("没有持仓: %s（期望[]）\n", positionSymbols(exec, pool))
+
+	err := exec.Execute(&executor.Decision{
+		AccountID:  "position_symbols_test",
+		Symbol:     "BTCUSDT",
+		Action:     executor.ActionOpenLong,
+		Quantity:   0.5,
+		StopLoss:   980,
+		TakeProfit: 1040,
+		Timestamp:  time.Now().UnixMilli(),
+	})
+	marketOrder(client, "SOLUSDT", binance.SideSell, "1", false)
+	fmt.Printf("括号订单BTCUSDT + 手动开空SOLUSDT: %v %s（期望<nil> [BTCUSDT SOLUSDT]）\n", err, positionSymbols(exec, pool))
+
+	marketOrder(client, "BTCUSDT", binance.SideSell, "0.5", true)
+	fmt.Printf("手动平掉BTCUSDT（括号订单仍在）: 括号订单=%v %s（期望true [SOLUSDT]）\n", exec.GetBracket("BTCUSDT") != nil, positionSymbols(exec, pool))
+
+	// 2. 现货
+	fmt.Println("\n===== 现货 =====")
+	spot := fakebinance.NewSpot("test-key", "test-secret")
+	defer spot.Close()
+	spot.AddSymbol(fakebinance.Symbol{Symbol: "BTCUSDT", Price: 1000, TickSize: 0.1, StepSize: 0.001})
+	spot.AddSymbol(fakebinance.Symbol{Symbol: "ETHUSDT", Price: 1000, TickSize: 0.1, StepSize: 0.001})
+	spot.SetSpotBalance("BTC", 0.3)
+	spot.SetSpotBalance("ETH", 0.0004)
+
+	spotExec := executor.NewExecutor("position_symbols_spot_test", binance.NewSpotClient("test-key", "test-secret", spot.URL, ""))
+	if err := spotExec.LoadSymbolRules(); err != nil {
+		utils.Fatal("加载交易规则失败", zap.Error(err))
+	}
+	fmt.Printf("BTC 0.3 + ETH粉尘: %s（期望[BTCUSDT]）\n", positionSymbols(spotExec, pool))
+
+	utils.Info("=== 按交易所持仓列出交易对测试结束 ===")
+}
@@ -0,0 +1,108 @@
+/*
+盈利加仓风控测试程序（模拟币安接口，不需要真实API密钥）
+
+测试内容：
+- 开多0.4后价格上涨2%，满足加仓规则（min_move_pct 1%）
+- 标记价格偏离指数价格约1%（上限0.5%）：加仓被拒绝，不下单，持仓和括号订单不变
+- 溢价恢复后再次加仓：按 size_ratio 加仓0.2，止损止盈单同步为0.6
+
+运行方式：
+
+	go run test/executor/test_pyramid.go
+*/
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/fakebinance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	accountID = "pyramid_test"
+	symbol    = "BTCUSDT"
+)
+
+// decision 开多决策（时间不同的决策生成不同的客户端订单ID）
+func decision(quantity, stopLoss float64) *executor.Decision {
+	return &executor.Decision{
+		AccountID:  accountID,
+		Symbol:     symbol,
+		Action:     executor.ActionOpenLong,
+		Quantity:   quantity,
+		StopLoss:   stopLoss,
+		TakeProfit: 1100,
+		Timestamp:  time.Now().UnixNano(),
+	}
+}
+
+// openLegs 未结束的止损止盈单（类型 → 数量）
+func openLegs(fake *fakebinance.Server) map[string]string {
+	legs := make(map[string]string)
+	for _, o := range fake.Orders(symbol) {
+		if !o.IsFinal() {
+			legs[o.Type] = o.OrigQty
+		}
+	}
+	return legs
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 盈利加仓风控测试开始 ===")
+
+	fake := fakebinance.New("test-key", "test-secret")
+	defer fake.Close()
+	fake.AddSymbol(fakebinance.Symbol{Symbol: symbol, Price: 1000, TickSize: 0.1, StepSize: 0.001})
+
+	client := binance.NewClient("test-key", "test-secret", fake.URL, "")
+	exec := executor.NewExecutor(accountID, client)
+	exec.SetPyramiding(config.PyramidingConfig{Enabled: true, MaxAdds: 2, MinMovePct: 1, SizeRatio: 0.5})
+	exec.SetPremiumGuard(0.5)
+	if err := exec.LoadSymbolRules(); err != nil {
+		utils.Fatal("加载交易规则失败", zap.Error(err))
+	}
+
+	// 1. 开仓
+	err := exec.Execute(decision(0.4, 980))
+	fmt.Printf("开仓: %v 挂单: %v（期望<nil> map[STOP_MARKET:0.4 TAKE_PROFIT_MARKET:0.4]）\n", err, openLegs(fake))
+
+	// 2. 价格上涨2%，但标记价格偏离指数价格约1%
+	fmt.Println("\n===== 溢价超过上限 =====")
+	fake.SetPrice(symbol, 1020)
+	fake.SetIndexPrice(symbol, 1010)
+	before := fake.Requests(http.MethodPost, binance.EndpointOrder)
+	err = exec.Execute(decision(0.4, 1000))
+	amt, _ := fake.Position(symbol)
+	fmt.Printf("加仓: %v（期望标记价格偏离指数价格的错误）\n", err)
+	fmt.Printf("持仓: %v 下单请求: %d 挂单: %v（期望0.4 0 map[STOP_MARKET:0.4 TAKE_PROFIT_MARKET:0.4]）\n", amt, fake.Requests(http.MethodPost, binance.EndpointOrder)-before, openLegs(fake))
+	if b := exec.GetBracket(symbol); b != nil {
+		fmt.Printf("括号订单: 数量=%v 加仓次数=%d（期望0.4 0）\n", b.Quantity, b.Adds)
+	}
+
+	// 3. 溢价恢复后加仓
+	fmt.Println("\n===== 溢价恢复 =====")
+	fake.SetIndexPrice(symbol, 0)
+	err = exec.Execute(decision(0.4, 1000))
+	amt, _ = fake.Position(symbol)
+	fmt.Printf("加仓: %v 持仓: %v 挂单: %v（期望<nil> 0.6 map[STOP_MARKET:0.6 TAKE_PROFIT_MARKET:0.6]）\n", err, amt, openLegs(fake))
+	if b := exec.GetBracket(symbol); b != nil {
+		fmt.Printf("括号订单: 数量=%v 加仓次数=%d 止损价=%v（期望0.6 1 1000）\n", b.Quantity, b.Adds, b.StopLoss)
+	}
+
+	fmt.Printf("\n未实现的接口: %v（期望[]）\n", fake.Unhandled())
+	utils.Info("=== 盈利加仓风控测试结束 ===")
+}
@@ -0,0 +1,96 @@
+/*
+影子模式测试程序
+
+测试内容：
+- 使用公开行情接口创建影子执行器（不需要API密钥，不下真实订单）
+- 提交BTCUSDT开多决策（止损-1%，止盈+1%），检查模拟持仓
+- 检查一次止损止盈后提交平仓决策，输出虚拟账户状态
+- 决策日志、模拟持仓和交易日志写入 data/shadow_test/
+
+运行方式：
+  go run test/executor/test_shadow.go
+*/
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/journal"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 影子模式测试开始 ===")
+
+	cfg, err := config.Load("configs/config.yml")
+	if err != nil {
+		utils.Fatal("加载配置失败", zap.Error(err))
+	}
+
+	// 行情数据不需要API密钥
+	client := binance.NewClient("", "", cfg.Binance.FuturesURL, cfg.GetProxyURL())
+	market := exchange.NewBinance(client)
+
+	dir := "data/shadow_test"
+	tradeJournal, err := journal.New(dir)
+	if err != nil {
+		utils.Fatal("创建交易日志失败", zap.Error(err))
+	}
+	shadow := executor.NewShadowExecutor("shadow_test", market, tradeJournal, dir, 10000)
+
+	symbol := "BTCUSDT"
+	klines, err := client.GetKlines(symbol, "1m", 1)
+	if err != nil || len(klines) == 0 {
+		utils.Fatal("获取K线失败", zap.Error(err))
+	}
+	price, _ := strconv.ParseFloat(klines[0].Close, 64)
+
+	open, err := shadow.Execute(&executor.Decision{
+		Symbol:     symbol,
+		Action:     executor.ActionOpenLong,
+		Quantity:   0.01,
+		StopLoss:   price * 0.99,
+		TakeProfit: price * 1.01,
+		Reason:     "影子模式测试",
+		Timestamp:  time.Now().UnixMilli(),
+	})
+	if err != nil {
+		utils.Fatal("提交开仓决策失败", zap.Error(err))
+	}
+	fmt.Printf("开仓决策: %s %s, 价格 %.2f\n", open.Result, open.Note, open.Price)
+
+	shadow.CheckPositions()
+	for _, b := range shadow.GetBrackets() {
+		fmt.Printf("模拟持仓: %s %s 数量 %.4f 入场 %.2f 止损 %.2f 止盈 %.2f\n",
+			b.Symbol, b.Side, b.Quantity, b.EntryPrice, b.StopLoss, b.TakeProfit)
+	}
+
+	closed, err := shadow.Execute(&executor.Decision{Symbol: symbol, Action: executor.ActionClose, Timestamp: time.Now().UnixMilli()})
+	if err != nil {
+		utils.Fatal("提交平仓决策失败", zap.Error(err))
+	}
+	fmt.Printf("平仓决策: %s %s, 价格 %.2f\n", closed.Result, closed.Note, closed.Price)
+
+	status, err := shadow.Status()
+	if err != nil {
+		utils.Fatal("获取虚拟账户状态失败", zap.Error(err))
+	}
+	fmt.Printf("虚拟账户: 已实现 %.4f USDT, 权益 %.2f USDT, 交易 %d 笔, 持仓 %d 个\n",
+		status.RealizedPnL, status.Equity, status.Trades, len(status.Positions))
+
+	utils.Info("=== 影子模式测试完成 ===")
+}
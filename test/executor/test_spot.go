@@ -0,0 +1,159 @@
+/*
+现货括号订单测试程序（模拟币安现货接口，不需要真实API密钥）
+
+测试内容：
+- 买入手续费以标的资产扣除：买入0.5实际到账0.4998，止损单按余额向下取整为0.499
+- 手动加仓后同步止损单：旧止损单冻结了余额，先撤旧单再挂新单（先挂新单会因余额不足被拒绝）
+- 同步时新止损单被拒绝：按原数量恢复止损单，持仓不会无保护
+- 平仓：先撤销止损单释放冻结的余额再市价卖出，卖出数量按余额截断；卖出失败时恢复止损单
+- 止盈：最优买价达到止盈价时撤销止损单，按余额市价卖出
+
+运行方式：
+
+	go run test/executor/test_spot.go
+*/
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/fakebinance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const symbol = "BTCUSDT"
+
+// openLong 开多（入场价1000）
+func openLong(exec *executor.Executor, quantity float64) error {
+	return exec.Execute(&executor.Decision{
+		AccountID:  "spot_test",
+		Symbol:     symbol,
+		Action:     executor.ActionOpenLong,
+		Quantity:   quantity,
+		StopLoss:   980,
+		TakeProfit: 1040,
+		Timestamp:  time.Now().UnixMilli(),
+	})
+}
+
+// manualBuy 在交易所手动市价买入（不经过执行器）
+func manualBuy(client *binance.Client, quantity string) {
+	_, err := client.PlaceOrder(&binance.OrderRequest{Symbol: symbol, Side: binance.SideBuy, Type: binance.OrderTypeMarket, Quantity: quantity})
+	if err != nil {
+		utils.Fatal("手动下单失败", zap.Error(err))
+	}
+}
+
+// openLegs 未结束的订单（类型 → 数量）
+func openLegs(fake *fakebinance.Server) map[string]string {
+	legs := make(map[string]string)
+	for _, o := range fake.Orders(symbol) {
+		if !o.IsFinal() {
+			legs[o.Type] = o.OrigQty
+		}
+	}
+	return legs
+}
+
+// lastSell 最后一笔市价卖单
+func lastSell(fake *fakebinance.Server) *binance.Order {
+	orders := fake.Orders(symbol)
+	for i := len(orders) - 1; i >= 0; i-- {
+		if orders[i].Side == binance.SideSell && orders[i].Type == binance.OrderTypeMarket {
+			return &orders[i]
+		}
+	}
+	return nil
+}
+
+// printBalance 输出标的资产的可用和冻结余额
+func printBalance(fake *fakebinance.Server, expect string) {
+	free, locked := fake.SpotBalance("BTC")
+	fmt.Printf("BTC余额: 可用=%.4f 冻结=%.4f（期望%s）\n", free, locked, expect)
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 现货括号订单测试开始 ===")
+
+	fake := fakebinance.NewSpot("test-key", "test-secret")
+	defer fake.Close()
+	fake.AddSymbol(fakebinance.Symbol{Symbol: symbol, Price: 1000, TickSize: 0.1, StepSize: 0.001})
+
+	client := binance.NewSpotClient("test-key", "test-secret", fake.URL, "")
+	exec := executor.NewExecutor("spot_test", client)
+	if err := exec.LoadSymbolRules(); err != nil {
+		utils.Fatal("加载交易规则失败", zap.Error(err))
+	}
+
+	// 1. 入场：手续费以BTC扣除，止损单按到账余额挂出
+	fmt.Println("===== 入场 =====")
+	err := openLong(exec, 0.5)
+	fmt.Printf("入场: %v 挂单: %v（期望<nil> map[STOP_LOSS:0.499]）\n", err, openLegs(fake))
+	b := exec.GetBracket(symbol)
+	if b == nil {
+		utils.Fatal("括号订单未建立")
+	}
+	fmt.Printf("括号订单数量: %v（期望0.499）\n", b.Quantity)
+	printBalance(fake, "可用=0.0008 冻结=0.4990")
+
+	// 2. 手动加仓：撤销旧止损单后按新余额挂出
+	fmt.Println("\n===== 加仓后同步止损单 =====")
+	manualBuy(client, "0.2")
+	exec.CheckBrackets()
+	fmt.Printf("挂单: %v 括号订单数量: %v（期望map[STOP_LOSS:0.699] 0.699）\n", openLegs(fake), b.Quantity)
+	printBalance(fake, "可用=0.0007 冻结=0.6990")
+
+	// 3. 新止损单被拒绝：恢复原数量的止损单
+	fmt.Println("\n===== 同步失败时恢复止损单 =====")
+	manualBuy(client, "0.1")
+	fake.RejectNextOrder("STOP_LOSS", -2010, "Account has insufficient balance for requested action.")
+	exec.CheckBrackets()
+	fmt.Printf("挂单: %v 括号订单数量: %v（期望map[STOP_LOSS:0.699] 0.699）\n", openLegs(fake), b.Quantity)
+	exec.CheckBrackets()
+	fmt.Printf("再次检查后挂单: %v 括号订单数量: %v（期望map[STOP_LOSS:0.799] 0.799）\n", openLegs(fake), b.Quantity)
+
+	// 4. 平仓失败：恢复止损单
+	fmt.Println("\n===== 平仓失败时恢复止损单 =====")
+	fake.RejectNextOrder(binance.OrderTypeMarket, -1013, "Filter failure: NOTIONAL")
+	err = exec.ClosePosition(symbol)
+	fmt.Printf("平仓失败: %v 挂单: %v 括号订单: %v（期望true map[STOP_LOSS:0.799] true）\n", err != nil, openLegs(fake), exec.GetBracket(symbol) != nil)
+
+	// 5. 平仓：先撤销止损单再按余额卖出
+	fmt.Println("\n===== 平仓 =====")
+	err = exec.ClosePosition(symbol)
+	sell := lastSell(fake)
+	fmt.Printf("平仓: %v 挂单: %v 括号订单: %v（期望<nil> map[] <nil>）\n", err, openLegs(fake), exec.GetBracket(symbol))
+	if sell != nil {
+		fmt.Printf("卖出: 状态=%s 数量=%s（期望FILLED 0.799）\n", sell.Status, sell.OrigQty)
+	}
+	printBalance(fake, "可用=0.0007 冻结=0.0000")
+
+	// 6. 止盈：撤销止损单后按余额卖出
+	fmt.Println("\n===== 止盈 =====")
+	err = openLong(exec, 0.3)
+	fmt.Printf("入场: %v 挂单: %v（期望<nil> map[STOP_LOSS:0.3]）\n", err, openLegs(fake))
+	fake.SetPrice(symbol, 1045)
+	exec.CheckBrackets()
+	sell = lastSell(fake)
+	fmt.Printf("挂单: %v 括号订单: %v（期望map[] <nil>）\n", openLegs(fake), exec.GetBracket(symbol))
+	if sell != nil {
+		fmt.Printf("卖出: 状态=%s 数量=%s（期望FILLED 0.3）\n", sell.Status, sell.OrigQty)
+	}
+
+	if unhandled := fake.Unhandled(); len(unhandled) > 0 {
+		fmt.Printf("未实现的请求: %v（期望无）\n", unhandled)
+	}
+
+	utils.Info("=== 现货括号订单测试结束 ===")
+}
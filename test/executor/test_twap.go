@@ -0,0 +1,113 @@
+/*
+TWAP拆单入场测试程序（模拟币安接口，不需要真实API密钥）
+
+测试内容：
+- 拆成3笔入场：第一笔成交后立即挂出止损止盈单，之后每笔成交后按累计数量重新挂出并撤销旧单
+- 第二笔入场被拒绝：停止拆单，保留第一笔成交（已受止损保护），括号订单按已成交数量建立
+- 第二笔成交后止损单同步失败：撤销已挂出的止损止盈单，市价平掉全部已成交数量，不再继续拆单
+
+运行方式：
+
+	go run test/executor/test_twap.go
+*/
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/fakebinance"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+const accountID = "twap_test"
+
+// decision 开多0.9的决策（名义价值900，超过拆单阈值500）
+func decision(symbol string) *executor.Decision {
+	return &executor.Decision{
+		AccountID:  accountID,
+		Symbol:     symbol,
+		Action:     executor.ActionOpenLong,
+		Quantity:   0.9,
+		StopLoss:   980,
+		TakeProfit: 1040,
+		Timestamp:  time.Now().UnixNano(),
+	}
+}
+
+// orderTypes 订单的 类型:状态:数量 列表
+func orderTypes(orders []binance.Order) []string {
+	list := make([]string, 0, len(orders))
+	for _, o := range orders {
+		list = append(list, o.Type+":"+o.Status+":"+o.OrigQty)
+	}
+	return list
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== TWAP拆单入场测试开始 ===")
+
+	fake := fakebinance.New("test-key", "test-secret")
+	defer fake.Close()
+	for _, symbol := range []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"} {
+		fake.AddSymbol(fakebinance.Symbol{Symbol: symbol, Price: 1000, TickSize: 0.1, StepSize: 0.001})
+	}
+
+	client := binance.NewClient("test-key", "test-secret", fake.URL, "")
+	exec := executor.NewExecutor(accountID, client)
+	exec.SetExecutionConfig(config.ExecutionConfig{
+		EntryType: config.EntryTypeMarket,
+		TWAP:      config.TWAPConfig{ThresholdUSDT: 500, Slices: 3},
+	})
+	if err := exec.LoadSymbolRules(); err != nil {
+		utils.Fatal("加载交易规则失败", zap.Error(err))
+	}
+
+	// 1. 正常拆单：每笔成交后止损止盈单跟随累计数量
+	fmt.Println("===== 拆成3笔入场 =====")
+	err := exec.Execute(decision("BTCUSDT"))
+	amt, _ := fake.Position("BTCUSDT")
+	fmt.Printf("错误: %v 持仓: %v（期望<nil> 0.9）\n", err, amt)
+	fmt.Printf("订单: %v\n（期望第一笔MARKET之后紧跟STOP_MARKET和TAKE_PROFIT_MARKET，数量依次为0.3、0.6、0.9，只有最后一组止损止盈单为NEW）\n", orderTypes(fake.Orders("BTCUSDT")))
+	if b := exec.GetBracket("BTCUSDT"); b != nil {
+		fmt.Printf("括号订单: 数量=%v 入场单ID与第一笔一致=%v（期望0.9 true）\n", b.Quantity, b.EntryOrderID == fake.Orders("BTCUSDT")[0].OrderID)
+	} else {
+		fmt.Println("括号订单: 无（期望有）")
+	}
+
+	// 2. 第二笔入场被拒绝：保留第一笔
+	fmt.Println("\n===== 第二笔入场被拒绝 =====")
+	fake.RejectOrderAfter(binance.OrderTypeMarket, 1, -2019, "Margin is insufficient.")
+	err = exec.Execute(decision("ETHUSDT"))
+	amt, _ = fake.Position("ETHUSDT")
+	fmt.Printf("错误: %v 持仓: %v（期望<nil> 0.3）\n", err, amt)
+	fmt.Printf("订单: %v（期望[MARKET:FILLED:0.3 STOP_MARKET:NEW:0.3 TAKE_PROFIT_MARKET:NEW:0.3]）\n", orderTypes(fake.Orders("ETHUSDT")))
+	if b := exec.GetBracket("ETHUSDT"); b != nil {
+		fmt.Printf("括号订单: 数量=%v（期望0.3）\n", b.Quantity)
+	} else {
+		fmt.Println("括号订单: 无（期望有）")
+	}
+
+	// 3. 第二笔成交后止损单同步失败：平掉全部已成交数量
+	fmt.Println("\n===== 第二笔成交后止损单同步失败 =====")
+	fake.RejectOrderAfter(binance.OrderTypeStopMarket, 1, -4131, "The counterparty's best price does not meet the PERCENT_PRICE filter limit.")
+	err = exec.Execute(decision("SOLUSDT"))
+	amt, _ = fake.Position("SOLUSDT")
+	fmt.Printf("错误: %v（期望拆单成交后同步止损止盈单失败，已平仓）\n", err)
+	fmt.Printf("持仓: %v 括号订单: %v（期望0 <nil>）\n", amt, exec.GetBracket("SOLUSDT"))
+	fmt.Printf("订单: %v\n（期望[MARKET:FILLED:0.3 STOP_MARKET:CANCELED:0.3 TAKE_PROFIT_MARKET:CANCELED:0.3 MARKET:FILLED:0.3 MARKET:FILLED:0.6]，不再下第三笔）\n", orderTypes(fake.Orders("SOLUSDT")))
+
+	fmt.Printf("\n未实现的接口: %v（期望[]）\n", fake.Unhandled())
+	utils.Info("=== TWAP拆单入场测试结束 ===")
+}
@@ -0,0 +1,114 @@
+/*
+多周期趋势摘要测试程序
+
+测试内容：
+- 1h 单边上涨、15m 上涨后急跌回到EMA21到EMA21附近、5m 连续下跌（RSI超卖）：摘要按 1h → 15m → 5m 的顺序描述
+- 资金费率偏高、持仓量与价格关系、波动率状态附加在最后；资金费率在阈值内为中性
+- 相同数据两次生成的摘要相同；不支持的类型返回空字符串
+- 内置 minimal 模板在指标JSON之后输出摘要，摘要为空时不输出
+
+运行方式：
+
+	go run test/indicators/test_describe.go
+*/
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/prompt"
+	"crypto-ai-trader/utils"
+)
+
+// makeKlines 按每根K线的涨跌幅（%）生成K线，最高、最低价为收盘价±0.2%
+func makeKlines(changes []float64) []exchange.Kline {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', 12, 64) }
+	klines := make([]exchange.Kline, len(changes))
+	price := 100.0
+	for i, change := range changes {
+		open := price
+		price *= 1 + change/100
+		klines[i] = exchange.Kline{
+			OpenTime:  int64(i) * 60000,
+			Open:      format(open),
+			High:      format(price * 1.002),
+			Low:       format(price * 0.998),
+			Close:     format(price),
+			Volume:    "100",
+			CloseTime: int64(i+1)*60000 - 1,
+		}
+	}
+	return klines
+}
+
+// series 生成count根K线的涨跌幅：前count-tail根为first，最后tail根为second
+func series(count, tail int, first, second float64) []float64 {
+	changes := make([]float64, count)
+	for i := range changes {
+		changes[i] = first
+		if i >= count-tail {
+			changes[i] = second
+		}
+	}
+	return changes
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 多周期趋势摘要测试开始 ===")
+
+	data := indicators.CalculateShortTermIndicators("BTCUSDT",
+		makeKlines(series(100, 0, 0.3, 0)),     // 1h 单边上涨
+		makeKlines(series(100, 3, 0.3, -1.0)),  // 15m 上涨后急跌回到EMA21
+		makeKlines(series(100, 20, 0.1, -0.5)), // 5m 连续下跌
+	)
+	data.MarketData = &indicators.MarketData{
+		FundingRate:      0.05,
+		OIPriceState:     indicators.OIPriceShortBuildup,
+		VolatilityRegime: indicators.VolatilityElevated,
+	}
+
+	summary := indicators.Describe(data)
+	fmt.Printf("摘要: %s\n", summary)
+	parts := strings.Split(summary, "；")
+	fmt.Printf("周期顺序: %s | %s | %s（期望1h 15m 5m开头）\n",
+		strings.Fields(parts[0])[0], strings.Fields(parts[1])[0], strings.Fields(parts[2])[0])
+	fmt.Printf("1h上涨趋势: %v（期望true）\n", strings.HasPrefix(parts[0], "1h 上涨趋势，在EMA55上方"))
+	fmt.Printf("15m回调至EMA21: %v（期望true）\n", strings.Contains(parts[1], "回调至EMA21附近"))
+	fmt.Printf("5m下跌超卖: %v（期望true）\n", strings.Contains(parts[2], "下跌趋势") && strings.Contains(parts[2], "超卖"))
+	fmt.Printf("市场: %v（期望[资金费率0.0500%%（偏高，多头拥挤） 持仓量随价格下跌增加 波动率偏高]）\n", parts[3:])
+
+	data.MarketData.FundingRate = 0.01
+	fmt.Printf("资金费率中性: %v（期望true）\n", strings.Contains(indicators.Describe(data), "资金费率中性"))
+	fmt.Printf("两次生成相同: %v（期望true）\n", indicators.Describe(data) == indicators.Describe(data))
+	fmt.Printf("不支持的类型: %q（期望\"\"）\n", indicators.Describe("BTCUSDT"))
+
+	// 模板输出
+	store, err := prompt.NewStore("configs/prompts")
+	if err != nil {
+		panic(err)
+	}
+	render := func(summary string) string {
+		text, err := store.Render("minimal", &prompt.Data{Symbol: "BTCUSDT", Time: time.Now(), Indicators: data, Summary: summary})
+		if err != nil {
+			panic(err)
+		}
+		return text
+	}
+	text := render(indicators.Describe(data))
+	fmt.Printf("模板输出摘要: %v 在JSON之后: %v（期望true true）\n",
+		strings.Contains(text, "趋势摘要"), strings.Index(text, "趋势摘要") > strings.Index(text, `"timeframes"`))
+	fmt.Printf("摘要为空时不输出: %v（期望true）\n", !strings.Contains(render(""), "趋势摘要"))
+
+	utils.Info("=== 多周期趋势摘要测试结束 ===")
+}
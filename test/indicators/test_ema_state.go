@@ -0,0 +1,100 @@
+/*
+EMA状态指标测试程序
+
+测试内容：
+- 单边上涨：多头排列，EMA21斜率为正，回看范围内没有交叉，收盘价在EMA55上方
+- 先跌后涨：最近一次交叉为金叉，交叉K线根数在反转之后；先涨后跌为死叉
+- 低价币（价格0.00002量级）：ema9/ema21 取整后为0，排列和交叉仍按未取整的均线判断
+- K线不足55根时为nil；指标数据的 ema_state 与直接计算一致
+
+运行方式：
+
+	go run test/indicators/test_ema_state.go
+*/
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+)
+
+// makeKlines 按收盘价序列生成K线（最高、最低价为收盘价±0.5%）
+func makeKlines(closes []float64) []exchange.Kline {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', 10, 64) }
+	klines := make([]exchange.Kline, len(closes))
+	for i, c := range closes {
+		klines[i] = exchange.Kline{
+			OpenTime:  int64(i) * 60000,
+			Open:      format(c),
+			High:      format(c * 1.005),
+			Low:       format(c * 0.995),
+			Close:     format(c),
+			Volume:    "100",
+			CloseTime: int64(i+1)*60000 - 1,
+		}
+	}
+	return klines
+}
+
+// path 生成收盘价：从base开始，前turn根每根变化first，之后每根变化second（百分比）
+func path(base float64, count, turn int, first, second float64) []float64 {
+	closes := make([]float64, count)
+	price := base
+	for i := range closes {
+		if i < turn {
+			price *= 1 + first/100
+		} else {
+			price *= 1 + second/100
+		}
+		closes[i] = price
+	}
+	return closes
+}
+
+// crossBars 格式化交叉K线根数（没有交叉时为nil）
+func crossBars(s *indicators.EMAStateData) string {
+	if s.CrossBars == nil {
+		return "nil"
+	}
+	return strconv.Itoa(*s.CrossBars)
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== EMA状态指标测试开始 ===")
+
+	// 1. 单边上涨
+	up := indicators.CalculateEMAState(makeKlines(path(100, 100, 100, 0.5, 0)), 14)
+	fmt.Printf("单边上涨: order=%s 斜率为正=%v cross_bars=%s 在EMA55上方=%v（期望up true nil true）\n",
+		up.Order, up.EMA21Slope > 0, crossBars(up), up.EMA55DistATR > 0)
+
+	// 2. 金叉与死叉
+	golden := indicators.CalculateEMAState(makeKlines(path(100, 100, 80, -0.5, 1)), 14)
+	fmt.Printf("先跌后涨: cross=%s cross_bars=%s 在反转之后=%v（期望golden 小于20 true）\n",
+		golden.Cross, crossBars(golden), golden.CrossBars != nil && *golden.CrossBars < 20)
+	dead := indicators.CalculateEMAState(makeKlines(path(100, 100, 80, 0.5, -1)), 14)
+	fmt.Printf("先涨后跌: cross=%s 斜率为负=%v 在EMA55下方=%v（期望dead true true）\n",
+		dead.Cross, dead.EMA21Slope < 0, dead.EMA55DistATR < 0)
+
+	// 3. 低价币
+	tiny := path(0.00002, 100, 100, 0.5, 0)
+	data := indicators.CalculateShortTermIndicators("PEPEUSDT", makeKlines(tiny), makeKlines(tiny), makeKlines(tiny))
+	tf := data.Timeframes.M15
+	fmt.Printf("低价币: ema9=%.2f ema21=%.2f order=%s（期望0.00 0.00 up）\n", tf.EMA9, tf.EMA21, tf.EMAState.Order)
+	fmt.Printf("低价币与高价币一致: 斜率=%v 距离=%v（期望true true）\n",
+		tf.EMAState.EMA21Slope == up.EMA21Slope, tf.EMAState.EMA55DistATR == up.EMA55DistATR)
+
+	// 4. K线不足
+	fmt.Printf("K线不足: %v（期望true）\n", indicators.CalculateEMAState(makeKlines(path(100, 54, 54, 0.5, 0)), 14) == nil)
+
+	utils.Info("=== EMA状态指标测试结束 ===")
+}
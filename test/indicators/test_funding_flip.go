@@ -0,0 +1,67 @@
+/*
+资金费率翻转测试程序
+
+测试内容：
+- FundingFlip：当前费率与最近一次已结算费率异号（穿越0）、与最近3次平均异号时的翻转方向
+- 当前费率为0、与历史同号时不算翻转
+- StateTracker：只在翻转状态改变时告警，第一次记录和状态持续期间不告警
+
+运行方式：
+
+	go run test/indicators/test_funding_flip.go
+*/
+package main
+
+import (
+	"fmt"
+
+	"crypto-ai-trader/alert"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 资金费率翻转测试开始 ===")
+
+	// 1. 翻转方向（当前、最近一次结算、最近3次平均，单位%）
+	cases := []struct {
+		name                string
+		current, last, avg3 float64
+		want                string
+	}{
+		{"穿越0由负转正", 0.005, -0.003, -0.004, indicators.FundingFlipToPositive},
+		{"穿越0由正转负", -0.002, 0.01, 0.01, indicators.FundingFlipToNegative},
+		{"与3次平均异号", 0.003, 0.001, -0.002, indicators.FundingFlipToPositive},
+		{"持续为正", 0.01, 0.01, 0.008, ""},
+		{"持续为负", -0.02, -0.01, -0.015, ""},
+		{"回到0", 0, -0.01, -0.01, ""},
+	}
+	failed := 0
+	for _, c := range cases {
+		got := indicators.FundingFlip(c.current, c.last, c.avg3)
+		if got != c.want {
+			failed++
+		}
+		fmt.Printf("%s: %q（期望%q）\n", c.name, got, c.want)
+	}
+	fmt.Printf("翻转方向: 不一致 %d 个（期望0）\n", failed)
+
+	// 2. 状态变化（每个周期的翻转状态）
+	tracker := alert.NewStateTracker()
+	cycles := []string{indicators.FundingFlipToNegative, "", "", indicators.FundingFlipToPositive, indicators.FundingFlipToPositive, ""}
+	var alerts []int
+	for i, state := range cycles {
+		if _, changed := tracker.Update("funding_flip/BTCUSDT", state); changed && state != "" {
+			alerts = append(alerts, i)
+		}
+	}
+	fmt.Printf("告警的周期: %v（期望[3]：启动时已翻转的第0个周期不告警，第4个周期状态未变）\n", alerts)
+
+	utils.Info("=== 资金费率翻转测试完成 ===")
+}
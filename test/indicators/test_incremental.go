@@ -0,0 +1,127 @@
+/*
+增量指标测试程序
+
+测试内容：
+- 用模拟K线（不访问交易所）逐根加入增量EMA/RSI/ATR，每一根都与 ta-lib 全量计算（CalculateEMA/CalculateRSI/CalculateATR）对比
+- 预热不足时 Ready 为false，重复或更早的K线被忽略
+- 增量更新与全量计算的耗时对比
+- 秒级增量指标：本地模拟标记价格推送生成1分钟K线（不访问交易所，不预热），nil 的 LiveTracker 可以安全调用
+
+运行方式：
+
+	go run test/indicators/test_incremental.go
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 增量指标测试开始 ===")
+
+	series := klines(65000, 300)
+
+	// 1. 逐根对比（前100根全量计算，之后每根对比）
+	live := indicators.NewLiveIndicators([]int{9, 21}, 14, 14)
+	live.Seed(series[:100])
+	mismatches := 0
+	for i := 100; i < len(series); i++ {
+		live.Seed(series[i : i+1])
+		window := series[:i+1]
+		snapshot := live.Snapshot()
+		if snapshot.EMA[9] != indicators.CalculateEMA(window, 9) || snapshot.EMA[21] != indicators.CalculateEMA(window, 21) ||
+			snapshot.RSI != indicators.CalculateRSI(window, 14) || snapshot.ATR != indicators.CalculateATR(window, 14) ||
+			snapshot.ATRPercent != indicators.CalculateATRPercent(window, 14) {
+			mismatches++
+			if mismatches <= 3 {
+				fmt.Printf("  第%d根不一致: 增量 %+v，全量 EMA9=%.2f RSI=%.2f ATR=%.2f\n", i, snapshot,
+					indicators.CalculateEMA(window, 9), indicators.CalculateRSI(window, 14), indicators.CalculateATR(window, 14))
+			}
+		}
+	}
+	snapshot := live.Snapshot()
+	fmt.Printf("逐根对比 %d 根: 不一致 %d 根（期望0）\n", len(series)-100, mismatches)
+	fmt.Printf("  最新: EMA9=%.2f EMA21=%.2f RSI=%.2f ATR=%.2f ATR%%=%.4f ready=%v\n",
+		snapshot.EMA[9], snapshot.EMA[21], snapshot.RSI, snapshot.ATR, snapshot.ATRPercent, snapshot.Ready)
+
+	// 2. 预热不足、重复K线
+	short := indicators.NewLiveIndicators([]int{9}, 14, 14)
+	short.Seed(series[:10])
+	fmt.Printf("10根K线: ready=%v EMA9=%.2f RSI=%.2f（期望false，EMA9已有值，RSI为0）\n",
+		short.Snapshot().Ready, short.Snapshot().EMA[9], short.Snapshot().RSI)
+	last := series[9]
+	high, _ := strconv.ParseFloat(last.High, 64)
+	added := short.Add(time.UnixMilli(last.OpenTime), high, high, high)
+	fmt.Printf("重复K线: 加入=%v 根数=%d（期望false，10）\n", added, short.Snapshot().Candles)
+
+	// 3. 耗时对比
+	start := time.Now()
+	bench := indicators.NewLiveIndicators([]int{9, 21}, 14, 14)
+	bench.Seed(series)
+	incremental := time.Since(start)
+	start = time.Now()
+	for i := 100; i < len(series); i++ {
+		window := series[i-99 : i+1]
+		indicators.CalculateEMA(window, 9)
+		indicators.CalculateEMA(window, 21)
+		indicators.CalculateRSI(window, 14)
+		indicators.CalculateATR(window, 14)
+	}
+	fmt.Printf("耗时: 增量 %v（%d根），每根用100根全量计算 %v（%d根）\n", incremental, len(series), time.Since(start), len(series)-100)
+
+	// 4. 秒级增量指标（推送生成的K线不足时未预热完成）
+	marks := binance.NewMarkPriceStream(nil, "ws://127.0.0.1:1", "", time.Minute)
+	tracker := indicators.NewLiveTracker(marks, nil, []int{9}, 14, 14)
+	tracker.Track([]string{"BTCUSDT"})
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	tracker.Run(ctx, 100*time.Millisecond)
+	cancel()
+	_, ok := tracker.Get("BTCUSDT")
+	all := tracker.Snapshot()
+	fmt.Printf("秒级指标: ok=%v 交易对 %d 个 K线 %d 根（期望false，1，0）\n", ok, len(all), all[0].Candles)
+
+	var none *indicators.LiveTracker
+	none.Track([]string{"BTCUSDT"})
+	_, ok = none.Get("BTCUSDT")
+	fmt.Printf("nil跟踪: ok=%v（期望false）\n", ok)
+
+	utils.Info("=== 增量指标测试完成 ===")
+}
+
+// klines 生成n根模拟1分钟K线（正弦波动叠加缓慢上涨）
+func klines(base float64, n int) []binance.Kline {
+	result := make([]binance.Kline, n)
+	start := time.Now().Add(-time.Duration(n) * time.Minute).Truncate(time.Minute).UnixMilli()
+	for i := range result {
+		price := base * (1 + 0.01*math.Sin(float64(i)/6) + 0.003*math.Cos(float64(i)/2.3) + 0.0002*float64(i))
+		result[i] = binance.Kline{
+			OpenTime:  start + int64(i)*60_000,
+			Open:      format(price * 0.9995),
+			High:      format(price * (1.001 + 0.001*math.Abs(math.Sin(float64(i))))),
+			Low:       format(price * (0.999 - 0.001*math.Abs(math.Cos(float64(i))))),
+			Close:     format(price),
+			CloseTime: start + int64(i+1)*60_000 - 1,
+		}
+	}
+	return result
+}
+
+// format 价格转为字符串（与交易所接口相同）
+func format(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -19,6 +19,7 @@ import (
 
 	"crypto-ai-trader/binance"
 	"crypto-ai-trader/config"
+	"crypto-ai-trader/exchange"
 	"crypto-ai-trader/indicators"
 	"crypto-ai-trader/utils"
 
@@ -116,7 +117,7 @@ func main() {
 	}
 	
 	fmt.Println("正在计算短线指标（含市场数据）...")
-	shortTermWithMarket := indicators.CalculateShortTermIndicatorsWithMarket(symbol, klines1h_short, klines15m_short, klines5m, client, oiCache)
+	shortTermWithMarket := indicators.CalculateShortTermIndicatorsWithMarket(symbol, klines1h_short, klines15m_short, klines5m, exchange.NewBinance(client), oiCache)
 	if shortTermWithMarket == nil {
 		utils.Fatal("短线指标（含市场数据）计算失败")
 	}
@@ -0,0 +1,71 @@
+/*
+持仓量与价格关系测试程序
+
+测试内容：
+- ClassifyOIAndPrice：价格、持仓量变化方向的四种组合及无变化
+- AnalyzeOIAndPrice：说明文字与分类一致
+- ApplyOIPriceState：优先使用15分钟持仓量变化率，没有时使用5分钟，都没有时不设置
+
+运行方式：
+
+	go run test/indicators/test_oi_price.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 持仓量与价格关系测试开始 ===")
+
+	// 1. 分类
+	cases := []struct {
+		price, oi float64
+		want      string
+	}{
+		{1.2, 3.5, indicators.OIPriceLongBuildup},
+		{0.8, -2.1, indicators.OIPriceShortCovering},
+		{-1.5, 4.0, indicators.OIPriceShortBuildup},
+		{-0.6, -1.2, indicators.OIPriceLongLiquidation},
+		{0, 2.0, indicators.OIPriceNeutral},
+	}
+	failed := 0
+	for _, c := range cases {
+		got := indicators.ClassifyOIAndPrice(c.price, c.oi)
+		if got != c.want {
+			failed++
+		}
+		fmt.Printf("价格%+.1f%% OI%+.1f%%: %s（期望%s）%s\n", c.price, c.oi, got, c.want, indicators.AnalyzeOIAndPrice(c.price, c.oi))
+	}
+	fmt.Printf("分类: 不一致 %d 个（期望0）\n", failed)
+
+	// 2. 写入市场数据
+	tf := &indicators.TimeframeData{OpenPrice: 100, ClosePrice: 101}
+	oi5, oi15 := -0.5, 2.0
+
+	market := &indicators.MarketData{OIChange5m: &oi5, OIChange15m: &oi15}
+	indicators.ApplyOIPriceState(market, tf)
+	fmt.Printf("15分钟OI+2%%、5分钟OI-0.5%%: %s（期望long_buildup）\n", market.OIPriceState)
+
+	market = &indicators.MarketData{OIChange5m: &oi5}
+	indicators.ApplyOIPriceState(market, tf)
+	fmt.Printf("只有5分钟OI-0.5%%: %s（期望short_covering）\n", market.OIPriceState)
+
+	market = &indicators.MarketData{}
+	indicators.ApplyOIPriceState(market, tf)
+	data, _ := json.Marshal(market)
+	fmt.Printf("没有OI变化率: %q，JSON中没有oi_price_state: %s\n", market.OIPriceState, data)
+
+	utils.Info("=== 持仓量与价格关系测试完成 ===")
+}
@@ -0,0 +1,80 @@
+/*
+低价币价格精度测试程序
+
+测试内容：
+- 高价币（BTCUSDT 6万量级）：价格、EMA 仍保留2位小数，与原来一致；ATR（几百）保留6位有效数字
+- 低价币（PEPEUSDT 0.00001量级）：收盘价、EMA、布林带、ATR、VWAP、MACD 保留6位有效数字，不再被舍入为0
+- 中间价位（100量级）：保留6位有效数字（3位小数）
+- 排名表的关键指标：低价币的 atr_pct 不为0，趋势按未舍入为0的均线判断
+
+运行方式：
+
+	go run test/indicators/test_price_format.go
+*/
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+)
+
+// makeKlines 生成count根上涨的K线：第i根收盘价为 base×(1+0.002×i)，最高、最低价为收盘价±0.3%
+func makeKlines(base float64, count int) []exchange.Kline {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', 12, 64) }
+	klines := make([]exchange.Kline, count)
+	for i := range klines {
+		c := base * (1 + 0.002*float64(i))
+		klines[i] = exchange.Kline{
+			OpenTime:  int64(i) * 60000,
+			Open:      format(c * 0.999),
+			High:      format(c * 1.003),
+			Low:       format(c * 0.997),
+			Close:     format(c),
+			Volume:    "1000000",
+			CloseTime: int64(i+1)*60000 - 1,
+		}
+	}
+	return klines
+}
+
+// timeframe 用同一组K线计算短线指标，返回15m周期
+func timeframe(symbol string, base float64) (*indicators.ShortTermIndicators, *indicators.TimeframeData) {
+	klines := makeKlines(base, 100)
+	data := indicators.CalculateShortTermIndicators(symbol, klines, klines, klines)
+	return data, data.Timeframes.M15
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 低价币价格精度测试开始 ===")
+
+	// 1. 高价币
+	_, btc := timeframe("BTCUSDT", 60000.123)
+	fmt.Printf("BTCUSDT: close=%v ema21=%v atr=%v（期望71880.15 70680.14 421.929）\n", btc.ClosePrice, btc.EMA21, btc.ATR)
+
+	// 2. 低价币
+	pepeData, pepe := timeframe("PEPEUSDT", 0.0000123456)
+	fmt.Printf("PEPEUSDT: close=%.10f（期望0.0000147900）\n", pepe.ClosePrice)
+	fmt.Printf("PEPEUSDT: ema9=%.11f ema21=%.11f ema55=%.11f（期望0.00001469130 0.00001454310 0.00001412340）\n", pepe.EMA9, pepe.EMA21, pepe.EMA55)
+	fmt.Printf("PEPEUSDT: bb.upper=%v bb.lower=%v atr=%v vwap=%v（期望均不为0）\n", pepe.BB.Upper, pepe.BB.Lower, pepe.ATR, *pepe.VWAP)
+	fmt.Printf("PEPEUSDT: macd.dif不为0=%v（期望true）\n", pepe.MACD.DIF != 0)
+
+	// 3. 中间价位
+	_, mid := timeframe("SOLUSDT", 123.456789)
+	fmt.Printf("SOLUSDT: open=%v（期望147.753）\n", mid.OpenPrice)
+
+	// 4. 关键指标
+	m := indicators.Summarize(pepeData)
+	fmt.Printf("PEPEUSDT关键指标: price=%.10f trend=%s atr_pct=%v（期望0.0000147900 up 0.59）\n", m.Price, m.Trend, m.ATRPct)
+
+	utils.Info("=== 低价币价格精度测试结束 ===")
+}
@@ -0,0 +1,106 @@
+/*
+指标计算耗时统计测试程序
+
+测试内容：
+- 用模拟K线（不访问交易所）对多个交易对计算短线、波段策略指标
+- 输出各指标函数的调用次数和平均、最大耗时，以及按策略指标类型、交易对的整体耗时
+- 周期耗时合计与预算对比（预算很小时超出并计数，预算足够时不超出）
+- 清空统计后重新开始计数
+
+运行方式：
+
+	go run test/indicators/test_telemetry.go
+*/
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 指标计算耗时统计测试开始 ===")
+
+	symbols := []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}
+	for i, symbol := range symbols {
+		base := 100 * math.Pow(10, float64(i))
+		for round := 0; round < 3; round++ {
+			indicators.CalculateShortTermIndicators(symbol, klines(base, 200), klines(base, 200), klines(base, 200))
+		}
+		indicators.SwingProfile.Calculate(symbol, map[string][]binance.Kline{"1d": klines(base, 200), "4h": klines(base, 200), "1h": klines(base, 200)})
+	}
+
+	snapshot := indicators.Telemetry()
+	fmt.Println("\n--- 指标函数耗时 ---")
+	names := make([]string, 0, len(snapshot.Functions))
+	for name := range snapshot.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := snapshot.Functions[name]
+		fmt.Printf("%-10s 调用 %4d 次，平均 %.3fms，最大 %.3fms\n", name, s.Count, s.AvgMs, s.MaxMs)
+	}
+
+	fmt.Println("\n--- 策略指标类型 / 交易对耗时 ---")
+	for kind, k := range snapshot.Kinds {
+		fmt.Printf("%s: 计算 %d 次，平均 %.3fms，最大 %.3fms\n", kind, k.Total.Count, k.Total.AvgMs, k.Total.MaxMs)
+		for _, symbol := range symbols {
+			s := k.Symbols[symbol]
+			fmt.Printf("  %s: %d 次，平均 %.3fms，最近 %.3fms\n", symbol, s.Count, s.AvgMs, s.LastMs)
+		}
+	}
+	fmt.Println("期望: short_term 每个交易对3次，swing 每个交易对1次")
+
+	fmt.Println("\n--- 周期预算 ---")
+	latency, over := indicators.CheckCycleBudget(indicators.KindShortTerm, symbols, time.Microsecond)
+	fmt.Printf("预算1µs: 耗时合计 %v，超出 %v（期望超出）\n", latency, over)
+	latency, over = indicators.CheckCycleBudget(indicators.KindShortTerm, symbols, time.Minute)
+	fmt.Printf("预算1分钟: 耗时合计 %v，超出 %v（期望不超出）\n", latency, over)
+	fmt.Printf("超出次数: %d（期望1），未统计的类型耗时: %v\n",
+		indicators.Telemetry().Kinds[indicators.KindShortTerm].OverBudget,
+		indicators.CycleLatency(indicators.KindScalp, symbols))
+
+	indicators.ResetTelemetry()
+	snapshot = indicators.Telemetry()
+	fmt.Printf("\n清空后: 函数 %d 个，类型 %d 个（期望都为0）\n", len(snapshot.Functions), len(snapshot.Kinds))
+
+	utils.Info("=== 指标计算耗时统计测试完成 ===")
+}
+
+// klines 生成模拟K线（正弦波动 + 缓慢上涨）
+func klines(base float64, n int) []binance.Kline {
+	result := make([]binance.Kline, n)
+	start := time.Now().Add(-time.Duration(n) * time.Hour).UnixMilli()
+	for i := range result {
+		price := base * (1 + 0.02*math.Sin(float64(i)/8) + 0.0005*float64(i))
+		result[i] = binance.Kline{
+			OpenTime:  start + int64(i)*3600_000,
+			Open:      format(price * 0.999),
+			High:      format(price * 1.004),
+			Low:       format(price * 0.996),
+			Close:     format(price),
+			Volume:    format(1000 + 100*math.Cos(float64(i)/5)),
+			CloseTime: start + int64(i+1)*3600_000 - 1,
+		}
+	}
+	return result
+}
+
+// format 价格转为字符串（与交易所接口相同）
+func format(v float64) string {
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
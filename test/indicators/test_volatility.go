@@ -0,0 +1,117 @@
+/*
+波动率分位测试程序
+
+测试内容：
+- PercentileRank：不大于v的比例，最小值、最大值、中间值
+- VolatilityRegime：quiet / normal / elevated / extreme 的分界
+- ApplyVolatilityRegime：30天平静后最近放大的1小时K线处于高百分位；同一小时内第二次调用使用缓存；未收盘的K线不参与计算
+- 样本不足7天时不设置，JSON中没有相关字段
+
+运行方式：
+
+	go run test/indicators/test_volatility.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+)
+
+// mockMarket 返回固定K线的行情接口
+type mockMarket struct {
+	name     string
+	klines   []exchange.Kline
+	requests int
+}
+
+func (m *mockMarket) Name() string             { return m.name }
+func (m *mockMarket) HasDerivativesData() bool { return true }
+func (m *mockMarket) GetKlines(symbol, interval string, limit int) ([]exchange.Kline, error) {
+	m.requests++
+	if len(m.klines) > limit {
+		return m.klines[len(m.klines)-limit:], nil
+	}
+	return m.klines, nil
+}
+func (m *mockMarket) GetOpenInterest(symbol string) (float64, error) { return 0, nil }
+func (m *mockMarket) GetFundingRate(symbol string) (float64, error)  { return 0, nil }
+func (m *mockMarket) GetFundingRateHistory(symbol string, limit int) ([]float64, error) {
+	return nil, nil
+}
+
+// hourlyKlines 生成count根已收盘的1小时K线（最后spike根振幅放大），再加一根未收盘的K线（振幅极大）
+func hourlyKlines(count, spike int) []exchange.Kline {
+	start := time.Now().Truncate(time.Hour).Add(-time.Duration(count) * time.Hour)
+	var klines []exchange.Kline
+	for i := 0; i <= count; i++ {
+		rangePct := 0.5 + float64(i%7)*0.05 // 平常振幅0.5%-0.8%
+		if i >= count-spike {
+			rangePct = 2.5
+		}
+		if i == count {
+			rangePct = 20 // 未收盘
+		}
+		price := 100.0
+		open := start.Add(time.Duration(i) * time.Hour)
+		klines = append(klines, exchange.Kline{
+			OpenTime:  open.UnixMilli(),
+			Open:      strconv.FormatFloat(price, 'f', 4, 64),
+			High:      strconv.FormatFloat(price*(1+rangePct/200), 'f', 4, 64),
+			Low:       strconv.FormatFloat(price*(1-rangePct/200), 'f', 4, 64),
+			Close:     strconv.FormatFloat(price, 'f', 4, 64),
+			CloseTime: open.Add(time.Hour).UnixMilli() - 1,
+		})
+	}
+	return klines
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 波动率分位测试开始 ===")
+
+	// 1. 百分位
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	fmt.Printf("百分位: 最小 %.0f，最大 %.0f，中间 %.0f，低于全部 %.0f（期望10，100，50，0）\n",
+		indicators.PercentileRank(values, 1), indicators.PercentileRank(values, 10),
+		indicators.PercentileRank(values, 5), indicators.PercentileRank(values, 0.5))
+
+	// 2. 波动率状态
+	for _, p := range []float64{5, 50, 85, 97} {
+		fmt.Printf("百分位 %.0f: %s\n", p, indicators.VolatilityRegime(p))
+	}
+	fmt.Println("  期望 quiet、normal、elevated、extreme")
+
+	// 3. 30天平静后最近几小时放大
+	market := &mockMarket{name: "mock", klines: hourlyKlines(800, 5)}
+	data := &indicators.MarketData{}
+	indicators.ApplyVolatilityRegime(data, market, "SMALLUSDT")
+	if data.ATRPctPercentile != nil {
+		fmt.Printf("波动放大: ATR%% %.4f，百分位 %.1f，状态 %s（期望ATR%%约1，远小于未收盘K线的20；百分位100，extreme）\n",
+			data.ATRPct1h, *data.ATRPctPercentile, data.VolatilityRegime)
+	} else {
+		fmt.Println("波动放大: 没有设置百分位（不符合期望）")
+	}
+	indicators.ApplyVolatilityRegime(&indicators.MarketData{}, market, "SMALLUSDT")
+	fmt.Printf("同一小时内调用两次: 请求交易所 %d 次（期望1）\n", market.requests)
+
+	// 4. 样本不足
+	young := &mockMarket{name: "mock", klines: hourlyKlines(100, 0)}
+	data = &indicators.MarketData{}
+	indicators.ApplyVolatilityRegime(data, young, "NEWUSDT")
+	raw, _ := json.Marshal(data)
+	fmt.Printf("上线约4天: 状态 %q，JSON %s（期望没有atr_pct字段）\n", data.VolatilityRegime, raw)
+
+	utils.Info("=== 波动率分位测试完成 ===")
+}
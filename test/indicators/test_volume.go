@@ -0,0 +1,96 @@
+/*
+成交量结构指标测试程序
+
+测试内容：
+- GetQuoteVolume：成交额取自K线的 QuoteAssetVolume
+- CalculateTakerBuyPercent：主动买入量占成交量的百分比；成交量为0或没有主动买入量（OKX）时返回false
+- CalculateVolumeRatio：最新K线成交量与之前20根平均成交量的比值；K线不足时返回0
+- 各周期指标数据输出 quote_volume、taker_buy_pct、volume_ratio，没有主动买入量时JSON中没有 taker_buy_pct
+
+运行方式：
+
+	go run test/indicators/test_volume.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+)
+
+// makeKlines 生成count根K线：成交量100、主动买入50，最后一根成交量300、主动买入240
+// taker 为false时不设置主动买入量（与OKX的K线一致）
+func makeKlines(count int, taker bool) []exchange.Kline {
+	klines := make([]exchange.Kline, count)
+	for i := range klines {
+		price := 100 + float64(i%5)
+		volume, buy := 100.0, 50.0
+		if i == count-1 {
+			volume, buy = 300, 240
+		}
+		klines[i] = exchange.Kline{
+			OpenTime:         int64(i) * 60000,
+			Open:             strconv.FormatFloat(price, 'f', 2, 64),
+			High:             strconv.FormatFloat(price+1, 'f', 2, 64),
+			Low:              strconv.FormatFloat(price-1, 'f', 2, 64),
+			Close:            strconv.FormatFloat(price+0.5, 'f', 2, 64),
+			Volume:           strconv.FormatFloat(volume, 'f', 2, 64),
+			QuoteAssetVolume: strconv.FormatFloat(volume*price, 'f', 2, 64),
+			CloseTime:        int64(i+1)*60000 - 1,
+		}
+		if taker {
+			klines[i].TakerBuyBaseAssetVolume = strconv.FormatFloat(buy, 'f', 2, 64)
+		}
+	}
+	return klines
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 成交量结构指标测试开始 ===")
+
+	klines := makeKlines(60, true)
+	last := klines[len(klines)-1]
+
+	// 1. 单根K线
+	pct, ok := indicators.CalculateTakerBuyPercent(last)
+	fmt.Printf("成交额: %.2f（期望31200.00）\n", indicators.GetQuoteVolume(last))
+	fmt.Printf("主动买入占比: %.2f ok=%v（期望80.00 true）\n", pct, ok)
+	_, ok = indicators.CalculateTakerBuyPercent(makeKlines(1, false)[0])
+	fmt.Printf("没有主动买入量: ok=%v（期望false）\n", ok)
+	zero := last
+	zero.Volume = "0"
+	_, ok = indicators.CalculateTakerBuyPercent(zero)
+	fmt.Printf("成交量为0: ok=%v（期望false）\n", ok)
+
+	// 2. 相对成交量
+	fmt.Printf("相对成交量: %.2f（期望3.00）\n", indicators.CalculateVolumeRatio(klines, 20))
+	fmt.Printf("K线不足: %.2f（期望0.00）\n", indicators.CalculateVolumeRatio(klines[:20], 20))
+
+	// 3. 指标数据
+	data := indicators.CalculateShortTermIndicators("BTCUSDT", klines, klines, klines)
+	tf := data.Timeframes.M15
+	fmt.Printf("15m: quote_volume=%.2f taker_buy_pct=%.2f volume_ratio=%.2f（期望31200.00 80.00 3.00）\n",
+		tf.QuoteVolume, *tf.TakerBuyPct, *tf.VolumeRatio)
+
+	okxKlines := makeKlines(60, false)
+	okxData := indicators.CalculateShortTermIndicators("BTCUSDT", okxKlines, okxKlines, okxKlines)
+	raw, _ := json.Marshal(okxData.Timeframes.M15)
+	var fields map[string]interface{}
+	_ = json.Unmarshal(raw, &fields)
+	_, hasTaker := fields["taker_buy_pct"]
+	_, hasRatio := fields["volume_ratio"]
+	fmt.Printf("没有主动买入量的JSON: taker_buy_pct=%v volume_ratio=%v（期望false true）\n", hasTaker, hasRatio)
+
+	utils.Info("=== 成交量结构指标测试结束 ===")
+}
@@ -0,0 +1,75 @@
+/*
+归一化波动率指标测试程序
+
+测试内容：
+- 形状相同、价格相差7个数量级的两组K线（60000与0.002）：ATR绝对值不可比（低价币舍入为0），atr_pct、bb_width_pct 一致
+- CalculateBBWidthSeries：前period-1根没有值，K线不足时返回nil
+- 波动后转为窄幅整理：bb_squeeze 为true；一直大幅波动：bb_squeeze 为false
+
+运行方式：
+
+	go run test/indicators/test_width.go
+*/
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+)
+
+// makeKlines 生成count根K线：基准价base，前count-quiet根收盘价在±2%到±4%间交替，最后quiet根在±0.1%间交替
+func makeKlines(base float64, count, quiet int) []exchange.Kline {
+	klines := make([]exchange.Kline, count)
+	for i := range klines {
+		swing := 0.02 + 0.01*float64(i/10%3) // 幅度每10根K线变化一次
+		if i >= count-quiet {
+			swing = 0.001
+		}
+		price := base * (1 + swing*float64(i%2*2-1))
+		format := func(v float64) string { return strconv.FormatFloat(v, 'g', 10, 64) }
+		klines[i] = exchange.Kline{
+			OpenTime:  int64(i) * 60000,
+			Open:      format(base),
+			High:      format(price * (1 + swing/2)),
+			Low:       format(price * (1 - swing/2)),
+			Close:     format(price),
+			Volume:    "100",
+			CloseTime: int64(i+1)*60000 - 1,
+		}
+	}
+	return klines
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 归一化波动率指标测试开始 ===")
+
+	// 1. 不同价格量级
+	btc := indicators.CalculateShortTermIndicators("BTCUSDT", makeKlines(60000, 100, 0), makeKlines(60000, 100, 0), makeKlines(60000, 100, 0)).Timeframes.M15
+	pepe := indicators.CalculateShortTermIndicators("PEPEUSDT", makeKlines(0.002, 100, 0), makeKlines(0.002, 100, 0), makeKlines(0.002, 100, 0)).Timeframes.M15
+	fmt.Printf("ATR: BTC=%.2f PEPE=%.2f（期望PEPE为0）\n", btc.ATR, pepe.ATR)
+	fmt.Printf("atr_pct: BTC=%.4f PEPE=%.4f 相同=%v（期望true）\n", btc.ATRPct, pepe.ATRPct, btc.ATRPct == pepe.ATRPct)
+	fmt.Printf("bb_width_pct: BTC=%.2f PEPE=%.2f 相同=%v（期望true）\n", btc.BBWidthPct, pepe.BBWidthPct, btc.BBWidthPct == pepe.BBWidthPct)
+
+	// 2. 宽度序列
+	klines := makeKlines(100, 100, 0)
+	fmt.Printf("宽度序列长度: %d（期望81）\n", len(indicators.CalculateBBWidthSeries(klines, 20, 2)))
+	fmt.Printf("K线不足: %v（期望true）\n", indicators.CalculateBBWidthSeries(klines[:19], 20, 2) == nil)
+
+	// 3. 收口
+	quiet := makeKlines(100, 100, 25)
+	squeezed := indicators.CalculateShortTermIndicators("BTCUSDT", quiet, quiet, quiet).Timeframes.M15
+	fmt.Printf("窄幅整理: bb_squeeze=%v bb_width_pct=%.2f（期望true，宽度远小于%.2f）\n", squeezed.BBSqueeze, squeezed.BBWidthPct, btc.BBWidthPct)
+	fmt.Printf("一直大幅波动: bb_squeeze=%v（期望false）\n", btc.BBSqueeze)
+
+	utils.Info("=== 归一化波动率指标测试结束 ===")
+}
@@ -0,0 +1,135 @@
+/*
+输入数据获取时间测试程序
+
+测试内容：
+- 模拟行情接口（不访问交易所），FetchedAt 返回各周期K线、持仓量、资金费率、资金费率历史的获取时间
+- 缓存命中时获取时间不变，过期重新获取后更新；请求失败时保留上一次成功的获取时间
+- 没有获取过的周期不返回
+- StampInputs 把获取时间记入信号；不记录获取时间的行情接口不设置
+
+运行方式：
+
+	go run test/marketdata/test_freshness.go
+*/
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/marketdata"
+	"crypto-ai-trader/strategy"
+	"crypto-ai-trader/utils"
+)
+
+// mockMarket 模拟行情接口
+type mockMarket struct {
+	fail atomic.Bool // 为true时全部请求失败
+}
+
+func (m *mockMarket) Name() string             { return "mock" }
+func (m *mockMarket) HasDerivativesData() bool { return true }
+
+func (m *mockMarket) GetKlines(symbol, interval string, limit int) ([]exchange.Kline, error) {
+	if m.fail.Load() {
+		return nil, fmt.Errorf("模拟请求失败")
+	}
+	return make([]exchange.Kline, limit), nil
+}
+
+func (m *mockMarket) GetOpenInterest(symbol string) (float64, error) {
+	if m.fail.Load() {
+		return 0, fmt.Errorf("模拟请求失败")
+	}
+	return 12345, nil
+}
+
+func (m *mockMarket) GetFundingRate(symbol string) (float64, error) {
+	if m.fail.Load() {
+		return 0, fmt.Errorf("模拟请求失败")
+	}
+	return 0.0001, nil
+}
+
+func (m *mockMarket) GetFundingRateHistory(symbol string, limit int) ([]float64, error) {
+	if m.fail.Load() {
+		return nil, fmt.Errorf("模拟请求失败")
+	}
+	return make([]float64, limit), nil
+}
+
+// fetchAll 获取一个交易对的全部输入数据
+func fetchAll(s *marketdata.Service, symbol string) {
+	s.GetKlines(symbol, "1h", 10)
+	s.GetKlines(symbol, "15m", 10)
+	s.GetOpenInterest(symbol)
+	s.GetFundingRate(symbol)
+	s.GetFundingRateHistory(symbol, 3)
+}
+
+// keys 排序后的输入名称
+func keys(m map[string]time.Time) []string {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 输入数据获取时间测试开始 ===")
+
+	market := &mockMarket{}
+	service := marketdata.NewService(market, 100*time.Millisecond)
+	intervals := []string{"1h", "15m"}
+
+	// 1. 获取后返回全部输入
+	fmt.Printf("获取前: %v（期望[]）\n", keys(service.FetchedAt("BTCUSDT", intervals)))
+	fetchAll(service, "BTCUSDT")
+	first := service.FetchedAt("BTCUSDT", intervals)
+	fmt.Printf("获取后: %v（期望[funding funding_history klines:15m klines:1h oi]）\n", keys(first))
+	fmt.Printf("未获取的周期: %v（期望[funding funding_history oi]）\n", keys(service.FetchedAt("BTCUSDT", []string{"4h"})))
+
+	// 2. 缓存命中时获取时间不变
+	time.Sleep(30 * time.Millisecond)
+	fetchAll(service, "BTCUSDT")
+	hit := service.FetchedAt("BTCUSDT", intervals)
+	fmt.Printf("缓存命中 oi 时间不变=%v（期望true）\n", hit["oi"].Equal(first["oi"]))
+
+	// 3. 过期后重新获取
+	time.Sleep(120 * time.Millisecond)
+	fetchAll(service, "BTCUSDT")
+	refreshed := service.FetchedAt("BTCUSDT", intervals)
+	fmt.Printf("过期后重新获取 oi 时间更新=%v（期望true）\n", refreshed["oi"].After(first["oi"]))
+
+	// 4. 请求失败保留上一次的获取时间
+	market.fail.Store(true)
+	time.Sleep(120 * time.Millisecond)
+	fetchAll(service, "BTCUSDT")
+	failed := service.FetchedAt("BTCUSDT", intervals)
+	fmt.Printf("请求失败 oi 时间不变=%v 距今>=120ms=%v（期望true true）\n",
+		failed["oi"].Equal(refreshed["oi"]), time.Since(failed["oi"]) >= 120*time.Millisecond)
+	market.fail.Store(false)
+
+	// 5. StampInputs
+	signals := []strategy.Signal{{Symbol: "BTCUSDT"}, {Symbol: "ETHUSDT"}}
+	strategy.StampInputs(service, intervals, signals)
+	fmt.Printf("BTCUSDT 信号输入: %v（期望[funding funding_history klines:15m klines:1h oi]）\n", keys(signals[0].FetchedAt))
+	fmt.Printf("ETHUSDT 信号输入: %v（期望[]）\n", keys(signals[1].FetchedAt))
+
+	direct := []strategy.Signal{{Symbol: "BTCUSDT"}}
+	strategy.StampInputs(market, intervals, direct)
+	fmt.Printf("不记录获取时间的行情接口: FetchedAt==nil %v（期望true）\n", direct[0].FetchedAt == nil)
+
+	utils.Info("=== 输入数据获取时间测试结束 ===")
+}
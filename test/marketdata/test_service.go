@@ -0,0 +1,138 @@
+/*
+共享行情数据服务测试程序
+
+测试内容：
+- 模拟行情接口（统计请求次数，不访问交易所）
+- 3个账号同时和先后获取同一交易对池的周期数据，每份K线只请求一次
+- 持仓量、资金费率在有效期内只请求一次，过期后重新请求
+- 请求更多K线时重新获取，之后较少数量的请求返回缓存的最近K线
+- 返回的K线是副本，修改不影响缓存；请求失败不缓存
+
+运行方式：
+
+	go run test/marketdata/test_service.go
+*/
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/marketdata"
+	"crypto-ai-trader/strategy"
+	"crypto-ai-trader/utils"
+)
+
+// mockMarket 模拟行情接口
+type mockMarket struct {
+	klines  atomic.Int64
+	oi      atomic.Int64
+	funding atomic.Int64
+	fail    atomic.Bool // 为true时K线请求失败
+}
+
+func (m *mockMarket) Name() string             { return "mock" }
+func (m *mockMarket) HasDerivativesData() bool { return true }
+
+func (m *mockMarket) GetKlines(symbol, interval string, limit int) ([]exchange.Kline, error) {
+	m.klines.Add(1)
+	time.Sleep(50 * time.Millisecond)
+	if m.fail.Load() {
+		return nil, fmt.Errorf("模拟请求失败")
+	}
+	klines := make([]exchange.Kline, limit)
+	for i := range klines {
+		klines[i].OpenTime = int64(i)
+		klines[i].Close = "100"
+	}
+	return klines, nil
+}
+
+func (m *mockMarket) GetOpenInterest(symbol string) (float64, error) {
+	m.oi.Add(1)
+	return 12345, nil
+}
+
+func (m *mockMarket) GetFundingRate(symbol string) (float64, error) {
+	m.funding.Add(1)
+	return 0.0001, nil
+}
+
+func (m *mockMarket) GetFundingRateHistory(symbol string, limit int) ([]float64, error) {
+	m.funding.Add(1)
+	return make([]float64, limit), nil
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 共享行情数据服务测试开始 ===")
+
+	source := &mockMarket{}
+	pool := marketdata.NewPool(2 * time.Second)
+	symbols := []string{"BTCUSDT", "ETHUSDT"}
+	timeframes := []string{"1d", "4h", "1h"} // 测试期间不会跨越K线收盘
+
+	// 1. 3个账号（同一交易所、市场类型）同时获取周期数据
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(accountID string) {
+			defer wg.Done()
+			service := pool.Get("binance/usdt_m", source)
+			data := strategy.FetchCycleData(service, accountID, symbols, timeframes, 100, nil)
+			if len(data.Symbols) != len(symbols) {
+				fmt.Printf("%s 获取周期数据失败\n", accountID)
+			}
+		}(fmt.Sprintf("account_%d", i+1))
+	}
+	wg.Wait()
+	fmt.Printf("3个账号同时获取: K线请求 %d 次（期望 %d 次）\n", source.klines.Load(), len(symbols)*len(timeframes))
+
+	// 2. 之后的账号直接使用缓存
+	service := pool.Get("binance/usdt_m", source)
+	strategy.FetchCycleData(service, "account_4", symbols, timeframes, 100, nil)
+	fmt.Printf("第4个账号获取: K线请求 %d 次（期望不变）\n", source.klines.Load())
+
+	// 3. 持仓量、资金费率
+	for i := 0; i < 3; i++ {
+		service.GetOpenInterest("BTCUSDT")
+		service.GetFundingRate("BTCUSDT")
+	}
+	fmt.Printf("持仓量请求 %d 次，资金费率请求 %d 次（期望各1次）\n", source.oi.Load(), source.funding.Load())
+	time.Sleep(2100 * time.Millisecond)
+	service.GetOpenInterest("BTCUSDT")
+	fmt.Printf("有效期过后: 持仓量请求 %d 次（期望2次）\n", source.oi.Load())
+
+	// 4. K线数量
+	before := source.klines.Load()
+	more, _ := service.GetKlines("SOLUSDT", "1d", 200)
+	less, _ := service.GetKlines("SOLUSDT", "1d", 50)
+	fmt.Printf("先请求200根再请求50根: K线请求 %d 次（期望1次），返回 %d / %d 根，50根的第一根为第 %d 根（期望150）\n",
+		source.klines.Load()-before, len(more), len(less), less[0].OpenTime)
+	service.GetKlines("SOLUSDT", "1d", 300)
+	fmt.Printf("再请求300根: K线请求 %d 次（期望2次）\n", source.klines.Load()-before)
+
+	// 5. 副本与失败
+	less[0].Close = "modified"
+	again, _ := service.GetKlines("SOLUSDT", "1d", 50)
+	fmt.Printf("修改返回的K线后再次获取: Close=%s（期望100）\n", again[0].Close)
+	source.fail.Store(true)
+	_, err := service.GetKlines("XRPUSDT", "1d", 100)
+	source.fail.Store(false)
+	_, err2 := service.GetKlines("XRPUSDT", "1d", 100)
+	fmt.Printf("请求失败: %v；恢复后再次请求: err=%v（期望成功，失败不缓存）\n", err, err2)
+
+	for key, s := range pool.Stats() {
+		fmt.Printf("%s: 命中 %d 次，请求 %d 次，缓存 %d 项\n", key, s.Hits, s.Requests, s.Entries)
+	}
+
+	utils.Info("=== 共享行情数据服务测试完成 ===")
+}
@@ -0,0 +1,92 @@
+/*
+OKX行情接口测试程序
+
+测试内容：
+- 创建OKX客户端并测试连接（获取服务器时间）
+- 通过exchange.MarketData获取K线、持仓量、资金费率（公共接口，无需API Key）
+- 使用OKX行情计算短线指标（含市场数据）
+
+运行方式：
+  go run test/okx/test_okx.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/okx"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "debug"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	// 加载配置（只使用代理和OKX地址）
+	cfg, err := config.Load("configs/config.yml")
+	if err != nil {
+		utils.Fatal("加载配置失败", zap.Error(err))
+	}
+	baseURL := cfg.OKX.BaseURL
+	if baseURL == "" {
+		baseURL = "https://www.okx.com"
+	}
+
+	fmt.Println("=== 测试1: 连接OKX ===")
+	client := okx.NewClient("", "", "", baseURL, cfg.GetProxyURL())
+	serverTime, err := client.GetServerTime()
+	if err != nil {
+		utils.Fatal("连接OKX失败", zap.Error(err))
+	}
+	fmt.Printf("服务器时间: %d\n", serverTime)
+
+	var market exchange.MarketData = exchange.NewOKX(client)
+	symbol := "BTCUSDT"
+	fmt.Printf("\n交易对: %s → %s\n", symbol, okx.InstID(symbol))
+
+	fmt.Println("\n=== 测试2: K线 ===")
+	klines := make(map[string][]exchange.Kline)
+	for _, interval := range []string{"1h", "15m", "5m"} {
+		k, err := market.GetKlines(symbol, interval, 100)
+		if err != nil {
+			utils.Fatal("获取K线失败", zap.String("interval", interval), zap.Error(err))
+		}
+		last := k[len(k)-1]
+		fmt.Printf("%s: %d根，最新收盘价 %s，开盘时间 %d\n", interval, len(k), last.Close, last.OpenTime)
+		klines[interval] = k
+	}
+
+	fmt.Println("\n=== 测试3: 持仓量和资金费率 ===")
+	oi, err := market.GetOpenInterest(symbol)
+	if err != nil {
+		utils.Fatal("获取持仓量失败", zap.Error(err))
+	}
+	rate, err := market.GetFundingRate(symbol)
+	if err != nil {
+		utils.Fatal("获取资金费率失败", zap.Error(err))
+	}
+	history, err := market.GetFundingRateHistory(symbol, 3)
+	if err != nil {
+		utils.Fatal("获取资金费率历史失败", zap.Error(err))
+	}
+	fmt.Printf("持仓量: %.2f BTC\n当前资金费率: %.6f%%\n最近3次: %v\n", oi, rate*100, history)
+
+	fmt.Println("\n=== 测试4: 短线指标（含市场数据）===")
+	result := indicators.CalculateShortTermIndicatorsWithMarket(symbol, klines["1h"], klines["15m"], klines["5m"], market, nil)
+	if result == nil {
+		utils.Fatal("计算指标失败")
+	}
+	data, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(data))
+
+	fmt.Println("\n=== 测试完成 ===")
+}
@@ -0,0 +1,114 @@
+/*
+紧凑格式指标数据测试程序
+
+测试内容：
+- 同一份短线指标数据用 minimal 模板分别以完整格式和紧凑格式渲染，比较估算token数
+- 紧凑格式：第一行为字段缩写说明，JSON为单行，使用短字段名，非整数保留5位有效数字（时间戳不变），省略nil/0/false字段
+- 长线指标数据同样可以压缩；账号 payload_format 配置校验
+
+运行方式：
+
+	go run test/prompt/test_compact.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/prompt"
+	"crypto-ai-trader/utils"
+)
+
+// makeKlines 生成count根上涨的K线：第i根收盘价为 base×(1+0.003×i)，最高、最低价为收盘价±0.4%
+func makeKlines(base float64, count int) []exchange.Kline {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', 12, 64) }
+	klines := make([]exchange.Kline, count)
+	for i := range klines {
+		c := base * (1 + 0.003*float64(i))
+		klines[i] = exchange.Kline{
+			OpenTime:  int64(i) * 60000,
+			Open:      format(c * 0.999),
+			High:      format(c * 1.004),
+			Low:       format(c * 0.996),
+			Close:     format(c),
+			Volume:    "1234.5678",
+			CloseTime: int64(i+1)*60000 - 1,
+		}
+	}
+	return klines
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 紧凑格式指标数据测试开始 ===")
+
+	klines := makeKlines(60000.123, 100)
+	data := indicators.CalculateShortTermIndicators("BTCUSDT", klines, klines, klines)
+
+	store, err := prompt.NewStore("configs/prompts")
+	if err != nil {
+		panic(err)
+	}
+	render := func(compact bool) string {
+		text, err := store.Render("minimal", &prompt.Data{Symbol: "BTCUSDT", Time: time.Now(), Indicators: data, Compact: compact})
+		if err != nil {
+			panic(err)
+		}
+		return text
+	}
+
+	// 1. token数
+	full, compact := prompt.EstimateTokens(render(false)), prompt.EstimateTokens(render(true))
+	fmt.Printf("估算token: 完整=%d 紧凑=%d 减少=%.0f%%（期望约40%%）\n", full, compact, float64(full-compact)/float64(full)*100)
+	fmt.Printf("完整格式与json函数一致: %v（期望true）\n", strings.Contains(render(false), `"close_price": `))
+
+	// 2. 紧凑格式内容
+	text, err := prompt.Compact(data)
+	if err != nil {
+		panic(err)
+	}
+	lines := strings.Split(text, "\n")
+	fmt.Printf("行数: %d 第一行为缩写说明: %v（期望2 true）\n", len(lines), strings.HasPrefix(lines[0], "字段缩写："))
+	fmt.Printf("缩写说明包含: c=close_price %v tf=timeframes %v（期望true true）\n",
+		strings.Contains(lines[0], "c=close_price"), strings.Contains(lines[0], "tf=timeframes"))
+
+	var tree map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &tree); err != nil {
+		panic(err)
+	}
+	m15 := tree["tf"].(map[string]interface{})["15m"].(map[string]interface{})
+	fmt.Printf("15m收盘价: %v（期望%v）\n", m15["c"], data.Timeframes.M15.ClosePrice)
+	fmt.Printf("15m ATR: %v（期望5位有效数字，原值%v）\n", m15["atr"], data.Timeframes.M15.ATR)
+	_, hasFull := m15["close_price"]
+	fmt.Printf("不再有原字段名: %v 时间戳保持整数: %v（期望true true）\n", !hasFull, tree["timestamp"] == float64(data.Timestamp))
+	_, hasMarket := tree["market_data"]
+	_, hasTaker := m15["tb%"]
+	fmt.Printf("省略nil的market_data: %v 省略nil的taker_buy_pct: %v（期望true true）\n", !hasMarket, !hasTaker)
+
+	// 3. 长线指标
+	long := indicators.CalculateLongTermIndicators("BTCUSDT", klines, klines, klines)
+	longText, err := prompt.Compact(long)
+	fullLong, _ := json.MarshalIndent(long, "", "  ")
+	fmt.Printf("长线: 压缩成功=%v 字符数 %d → %d（期望true，明显减少）\n", err == nil, len(fullLong), len(longText))
+
+	// 4. 配置校验
+	account := config.Account{ID: "a", Name: "a", Strategy: "short_term", PromptType: "minimal", Mode: config.AccountModeObserve}
+	account.PayloadFormat = "compact"
+	fmt.Printf("compact: 校验通过=%v 紧凑=%v（期望true true）\n", account.Validate() == nil, account.IsCompactPayload())
+	account.PayloadFormat = "tiny"
+	fmt.Printf("无效格式: %v（期望指标数据格式无效）\n", account.Validate())
+
+	utils.Info("=== 紧凑格式指标数据测试结束 ===")
+}
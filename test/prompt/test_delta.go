@@ -0,0 +1,158 @@
+/*
+指标变化输出测试程序
+
+测试内容：
+- 第一次为完整输出；价格小幅变化时只列出变化明显的字段，minimal 提示词的估算token明显减少
+- 数值字段按绝对阈值（字段名或路径，路径优先）或默认相对变化比较；timestamp 默认不比较
+- 基准为字段上次输出的值：多次小幅变化累积超过阈值时输出
+- 字段出现、消失时输出；每 full_every 次为完整输出；不同交易对分别比较
+- delta 配置默认值、账号 payload_format: delta 校验
+
+运行方式：
+
+	go run test/prompt/test_delta.go
+*/
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/prompt"
+	"crypto-ai-trader/utils"
+)
+
+// makeKlines 生成count根震荡上涨的K线：第i根收盘价为 base×(1+0.002×i)×(1+0.01×sin(i/3))，最高、最低价为收盘价±0.4%
+func makeKlines(base float64, count int) []exchange.Kline {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', 12, 64) }
+	klines := make([]exchange.Kline, count)
+	for i := range klines {
+		c := base * (1 + 0.002*float64(i)) * (1 + 0.01*math.Sin(float64(i)/3))
+		klines[i] = exchange.Kline{
+			OpenTime:  int64(i) * 60000,
+			Open:      format(c * 0.999),
+			High:      format(c * 1.004),
+			Low:       format(c * 0.996),
+			Close:     format(c),
+			Volume:    "1234.5678",
+			CloseTime: int64(i+1)*60000 - 1,
+		}
+	}
+	return klines
+}
+
+// shortTerm 计算短线指标，最后一根K线的收盘价变化move（如0.002为上涨0.2%）
+func shortTerm(move float64) *indicators.ShortTermIndicators {
+	klines := makeKlines(60000, 100)
+	last := &klines[len(klines)-1]
+	c, _ := strconv.ParseFloat(last.Close, 64)
+	last.Close = strconv.FormatFloat(c*(1+move), 'g', 12, 64)
+	last.High = strconv.FormatFloat(c*(1+move)*1.004, 'g', 12, 64)
+	return indicators.CalculateShortTermIndicators("BTCUSDT", klines, klines, klines)
+}
+
+// changed 变化中是否有该路径
+func changed(d *prompt.Delta, path string) bool {
+	for _, c := range d.Changes {
+		if c.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 指标变化输出测试开始 ===")
+
+	cfg := (&config.Config{}).GetDeltaConfig("short_term")
+	fmt.Printf("默认配置: default_pct=%v full_every=%d ignore=%v（期望0.5 12 [timestamp]）\n", cfg.DefaultPct, cfg.FullEvery, cfg.Ignore)
+	cfg.Thresholds = map[string]float64{"rsi": 5, "timeframes.15m.rsi": 0.01}
+	tracker := prompt.NewDeltaTracker(cfg)
+	now := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	// 1. 第一次完整输出
+	first := shortTerm(0)
+	delta, _ := tracker.Update("BTCUSDT", first, now)
+	fmt.Printf("第一次: 完整=%v（期望true）\n", delta.Full)
+
+	// 2. 最新K线上涨0.2%
+	second := shortTerm(0.002)
+	second.Timestamp = first.Timestamp + 300
+	delta, _ = tracker.Update("BTCUSDT", second, now.Add(5*time.Minute))
+	fmt.Printf("上涨0.2%%: 完整=%v 变化字段=%d（期望false，只有ATR、MACD等变化超过0.5%%的字段）\n", delta.Full, len(delta.Changes))
+	fmt.Printf("timestamp不比较: %v 15m收盘价未达0.5%%: %v（期望true true）\n",
+		!changed(delta, "timestamp"), !changed(delta, "timeframes.15m.close_price"))
+	fmt.Printf("RSI %.2f → %.2f: 15m按路径阈值0.01输出=%v 1h按字段阈值5输出=%v（期望true false）\n",
+		first.Timeframes.M15.RSI, second.Timeframes.M15.RSI, changed(delta, "timeframes.15m.rsi"), changed(delta, "timeframes.1h.rsi"))
+
+	store, err := prompt.NewStore("configs/prompts")
+	if err != nil {
+		panic(err)
+	}
+	render := func(d *prompt.Delta) string {
+		text, err := store.Render("minimal", &prompt.Data{Symbol: "BTCUSDT", Time: now, Indicators: second, Delta: d})
+		if err != nil {
+			panic(err)
+		}
+		return text
+	}
+	full, diff := prompt.EstimateTokens(render(nil)), prompt.EstimateTokens(render(delta))
+	fmt.Printf("估算token: 完整=%d 变化=%d（期望变化明显更少）\n", full, diff)
+	fmt.Printf("提示词包含变化说明: %v（期望true）\n", strings.Contains(render(delta), "只列出与上次发送相比变化明显的字段"))
+
+	// 3. 累积变化：相对上次输出的值累计上涨0.6%
+	third := shortTerm(0.006)
+	delta, _ = tracker.Update("BTCUSDT", third, now.Add(10*time.Minute))
+	fmt.Printf("累计上涨0.6%%: 15m收盘价输出=%v（期望true）\n", changed(delta, "timeframes.15m.close_price"))
+	delta, _ = tracker.Update("BTCUSDT", third, now.Add(15*time.Minute))
+	fmt.Printf("数据不变: 变化字段=%d 说明=%s（期望0 与上次（08:10:00）相比没有明显变化）\n", len(delta.Changes), delta.String())
+
+	// 4. 字段出现和消失
+	withMarket := shortTerm(0.006)
+	withMarket.MarketData = &indicators.MarketData{FundingRate: 0.01}
+	delta, _ = tracker.Update("BTCUSDT", withMarket, now.Add(20*time.Minute))
+	fmt.Printf("新出现: %s（期望market_data.funding_rate: 无 → 0.01）\n", firstLine(delta, "market_data.funding_rate"))
+	delta, _ = tracker.Update("BTCUSDT", third, now.Add(25*time.Minute))
+	fmt.Printf("消失: %s（期望market_data.funding_rate: 0.01 → 无）\n", firstLine(delta, "market_data.funding_rate"))
+
+	// 5. 其他交易对独立比较；每 full_every 次完整输出
+	delta, _ = tracker.Update("ETHUSDT", third, now)
+	fmt.Printf("ETHUSDT第一次: 完整=%v（期望true）\n", delta.Full)
+	cfg.FullEvery = 3
+	every := prompt.NewDeltaTracker(cfg)
+	var fulls []bool
+	for i := 0; i < 7; i++ {
+		d, _ := every.Update("BTCUSDT", third, now)
+		fulls = append(fulls, d.Full)
+	}
+	fmt.Printf("full_every=3: %v（期望[true false false true false false true]）\n", fulls)
+
+	// 6. 账号配置校验
+	account := config.Account{ID: "a", Name: "a", Strategy: "short_term", PromptType: "minimal", Mode: config.AccountModeObserve}
+	account.PayloadFormat = "delta"
+	fmt.Printf("delta: 校验通过=%v 变化格式=%v（期望true true）\n", account.Validate() == nil, account.IsDeltaPayload())
+
+	utils.Info("=== 指标变化输出测试结束 ===")
+}
+
+// firstLine 变化说明中该路径的一行
+func firstLine(d *prompt.Delta, path string) string {
+	for _, line := range strings.Split(d.String(), "\n") {
+		if strings.HasPrefix(line, path+":") {
+			return line
+		}
+	}
+	return "（没有该字段）"
+}
@@ -0,0 +1,86 @@
+/*
+提示词持仓上下文测试程序
+
+测试内容：
+- 加载 configs/prompts 下的模板
+- 没有持仓时 minimal、analysis 模板不输出持仓信息
+- 有持仓时输出方向、入场价、最新价、浮动盈亏、持仓时长、止损止盈，并提示AI管理已有仓位
+- 持仓超过最长持仓时间时提示这是一次出场评估
+
+运行方式：
+
+	go run test/prompt/test_position_context.go
+*/
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"crypto-ai-trader/prompt"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 提示词持仓上下文测试开始 ===")
+
+	store, err := prompt.NewStore("configs/prompts")
+	if err != nil {
+		utils.Fatal("加载提示词模板失败", zap.Error(err))
+	}
+
+	data := &prompt.Data{
+		AccountID:    "acc-1",
+		Strategy:     "short_term",
+		StrategyName: "短线",
+		Symbol:       "BTCUSDT",
+		Time:         time.Now(),
+		Indicators:   map[string]interface{}{"symbol": "BTCUSDT"},
+	}
+
+	// 1. 没有持仓
+	for _, name := range []string{"minimal", "analysis"} {
+		text, err := store.Render(name, data)
+		fmt.Printf("%s 无持仓: 包含持仓信息 %v，错误 %v（期望false <nil>）\n", name, strings.Contains(text, "当前持仓"), err)
+	}
+
+	// 2. 有持仓
+	data.Position = &prompt.PositionState{
+		Symbol:     "BTCUSDT",
+		Side:       "SELL",
+		Quantity:   0.05,
+		EntryPrice: 62000,
+		StopLoss:   63000,
+		TakeProfit: 60000,
+		OpenedAt:   time.Now().Add(-135 * time.Minute),
+		MarkPrice:  61380,
+		PnL:        31,
+		PnLPct:     1,
+		Holding:    "2小时15分钟",
+	}
+	text, err := store.Render("minimal", data)
+	if err != nil {
+		utils.Fatal("渲染提示词失败", zap.Error(err))
+	}
+	fmt.Println("minimal 有持仓:")
+	fmt.Println(text)
+	fmt.Println("（期望在输出格式之前包含：做空 0.05，入场价 62000，最新价 61380，浮动盈亏 31 USDT（1%），已持仓 2小时15分钟）")
+
+	// 3. 超过最长持仓时间
+	fmt.Printf("未超时: 包含出场评估 %v（期望false）\n", strings.Contains(text, "出场评估"))
+	data.Position.Overdue, data.Position.MaxHolding = true, "1小时30分钟"
+	text, err = store.Render("minimal", data)
+	fmt.Printf("已超时: 包含出场评估 %v，包含最长持仓时间 %v，错误 %v（期望true true <nil>）\n",
+		strings.Contains(text, "出场评估"), strings.Contains(text, "1小时30分钟"), err)
+
+	utils.Info("=== 提示词持仓上下文测试完成 ===")
+}
@@ -0,0 +1,147 @@
+/*
+提示词模板测试程序
+
+测试内容：
+- 加载 configs/prompts 下的模板，按账号配置选择模板
+- 用构造的短线指标数据渲染 short_term_detailed 和 minimal 模板
+- 复制模板到临时目录，修改后检查 Reload 能重新加载，语法错误时保留旧模板
+- 设置较小的token预算，检查压缩步骤（可选字段、有效数字、时间周期）和模板引用被去掉字段时的撤销
+
+运行方式：
+
+	go run test/prompt/test_prompt.go
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/prompt"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 提示词模板测试开始 ===")
+
+	store, err := prompt.NewStore("configs/prompts")
+	if err != nil {
+		utils.Fatal("加载提示词模板失败", zap.Error(err))
+	}
+	fmt.Printf("模板: %v\n", store.Names())
+
+	// 按账号配置选择模板
+	for _, acc := range []config.Account{
+		{ID: "a1", Strategy: "short_term", PromptType: "detailed"},
+		{ID: "a2", Strategy: "long_term", PromptType: "minimal"},
+		{ID: "a3", Strategy: "long_term", PromptType: "detailed", PromptTemplate: "minimal"},
+	} {
+		fmt.Printf("账号 %s 候选 %v → %s\n", acc.ID, acc.GetPromptTemplates(), store.Lookup(acc.GetPromptTemplates()...))
+	}
+
+	tf := func(close float64) *indicators.TimeframeData {
+		return &indicators.TimeframeData{
+			ClosePrice: close, EMA9: close * 1.001, EMA21: close * 0.998, RSI: 55.3,
+			MACD: &indicators.MACDData{Histogram: 12.5}, ATR: close * 0.004,
+		}
+	}
+	data := &prompt.Data{
+		AccountID:    "a1",
+		Strategy:     "short_term",
+		StrategyName: "短线",
+		Symbol:       "BTCUSDT",
+		Time:         time.Now(),
+		Indicators: &indicators.ShortTermIndicators{
+			Symbol:     "BTCUSDT",
+			Timestamp:  time.Now().Unix(),
+			MarketData: &indicators.MarketData{FundingRate: 0.01, FundingAvg3: 0.008},
+			Timeframes: &indicators.ShortTermTimeframes{H1: tf(65000), M15: tf(65100), M5: tf(65120)},
+			Levels: &indicators.SuggestedLevels{
+				Timeframe: "15m",
+				ATR:       260,
+				Params:    indicators.ShortTermLevelParams,
+				Long:      indicators.CalculateLevels(65120, indicators.DirectionLong, 260, indicators.ShortTermLevelParams, 0),
+				Short:     indicators.CalculateLevels(65120, indicators.DirectionShort, 260, indicators.ShortTermLevelParams, 0),
+			},
+		},
+	}
+	for _, name := range []string{"short_term_detailed", "minimal"} {
+		text, err := store.Render(name, data)
+		if err != nil {
+			utils.Fatal("渲染提示词失败", zap.String("template", name), zap.Error(err))
+		}
+		fmt.Printf("\n===== %s =====\n%s\n", name, text)
+	}
+
+	// token预算：minimal 输出完整JSON可以压缩，short_term_detailed 引用了全部时间周期，去掉时间周期会被撤销
+	adx := 23.456789
+	data.Indicators.(*indicators.ShortTermIndicators).Timeframes.M5.ADX = &adx
+	budget := config.PromptBudgetConfig{
+		MaxTokens:         300,
+		OptionalFields:    []string{"adx", "oi_history"},
+		SignificantDigits: 5,
+		DropTimeframes:    []string{"5m", "1h"},
+	}
+	for _, name := range []string{"minimal", "short_term_detailed"} {
+		text, reduction, err := store.RenderBudget(name, data, budget)
+		if err != nil {
+			utils.Fatal("按预算渲染提示词失败", zap.String("template", name), zap.Error(err))
+		}
+		fmt.Printf("\n%s 预算 %d: 估算 %d tokens\n", name, budget.MaxTokens, prompt.EstimateTokens(text))
+		if reduction != nil {
+			fmt.Printf("  压缩前 %d → 压缩后 %d，步骤 %v，仍超出 %v\n",
+				reduction.OriginalTokens, reduction.Tokens, reduction.Removed, reduction.OverBudget)
+		}
+	}
+	if data.Indicators.(*indicators.ShortTermIndicators).Timeframes.M5.ADX == nil {
+		utils.Fatal("压缩修改了原始指标数据")
+	}
+
+	// 热加载：修改模板后重新加载，语法错误时保留旧模板
+	dir, err := os.MkdirTemp("", "prompts")
+	if err != nil {
+		utils.Fatal("创建临时目录失败", zap.Error(err))
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "custom.tmpl")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			utils.Fatal("写入模板失败", zap.Error(err))
+		}
+		// 保证修改时间变化
+		future := time.Now().Add(time.Second)
+		os.Chtimes(path, future, future)
+	}
+
+	write("版本1 {{.Symbol}}")
+	temp, err := prompt.NewStore(dir)
+	if err != nil {
+		utils.Fatal("加载临时模板失败", zap.Error(err))
+	}
+	text, _ := temp.Render("custom", data)
+	fmt.Printf("\n修改前: %s\n", text)
+
+	write("版本2 {{.Symbol}} {{.StrategyName}}")
+	reloaded, err := temp.Reload()
+	text, _ = temp.Render("custom", data)
+	fmt.Printf("修改后: %s (重新加载 %v, 错误 %v)\n", text, reloaded, err)
+
+	write("版本3 {{.Symbol")
+	reloaded, err = temp.Reload()
+	text, _ = temp.Render("custom", data)
+	fmt.Printf("语法错误: %s (重新加载 %v, 错误 %v)\n", text, reloaded, err)
+
+	utils.Info("=== 提示词模板测试完成 ===")
+}
@@ -0,0 +1,54 @@
+/*
+拉盘/砸盘筛选测试程序
+
+测试内容：
+- 涨跌幅和持仓量变化率同时超过阈值才入选，上涨为pump、下跌为dump
+- 按 涨跌幅绝对值 × 持仓量变化率 排序，只保留前N个
+- 报告按账号保存，nil 的 PumpDumpScreener 可以安全调用
+
+运行方式：
+
+	go run test/scanner/test_pumpdump.go
+*/
+package main
+
+import (
+	"fmt"
+
+	"crypto-ai-trader/scanner"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 拉盘/砸盘筛选测试开始 ===")
+
+	screener := scanner.NewPumpDumpScreener(scanner.PumpDumpConfig{MinReturnPct: 3, MinOIChangePct: 2, TopN: 2})
+	inputs := []scanner.PumpDumpInput{
+		{Symbol: "BTCUSDT", Timeframe: "15m", ReturnPct: 0.8, OIChangePct: 5},    // 涨幅不足
+		{Symbol: "PEPEUSDT", Timeframe: "15m", ReturnPct: 6.5, OIChangePct: 8},   // pump，得分52
+		{Symbol: "WIFUSDT", Timeframe: "15m", ReturnPct: -4.2, OIChangePct: 6},   // dump，得分25.2
+		{Symbol: "SOLUSDT", Timeframe: "15m", ReturnPct: 5.0, OIChangePct: -3},   // 持仓量减少（平仓推动）
+		{Symbol: "DOGEUSDT", Timeframe: "15m", ReturnPct: 3.1, OIChangePct: 2.5}, // 入选但得分最低，被TopN截掉
+	}
+	report := screener.Screen("acc-1", inputs)
+	fmt.Printf("筛选 %d 个，入选 %d 个（期望5，2）\n", report.Scanned, len(report.Events))
+	for _, e := range report.Events {
+		fmt.Printf("  %s %s: 涨跌 %.2f%% 持仓量 %+.2f%% 得分 %.2f\n", e.Symbol, e.Kind, e.ReturnPct, e.OIChangePct, e.Score)
+	}
+	fmt.Println("  期望 PEPEUSDT pump 52、WIFUSDT dump 25.2")
+
+	screener.Screen("acc-2", inputs[:1])
+	reports := screener.Reports()
+	fmt.Printf("报告: 账号 %d 个，acc-2 入选 %d 个（期望2，0）\n", len(reports), len(reports["acc-2"].Events))
+
+	var none *scanner.PumpDumpScreener
+	fmt.Printf("nil筛选: 报告 %v（期望<nil>）\n", none.Screen("acc-1", inputs))
+
+	utils.Info("=== 拉盘/砸盘筛选测试完成 ===")
+}
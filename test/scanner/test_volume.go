@@ -0,0 +1,132 @@
+/*
+成交量异动筛选测试程序
+
+测试内容：
+- 本地模拟币安行情接口（不访问交易所）：24小时行情和K线
+- 扫描范围：只扫描USDT交易对，排除成交额不足和被排除的交易对，按成交额取前N个
+- 当前K线成交量超过前N根平均的倍数时视为异动，按倍数从高到低排序
+- 定时扫描：Candidates 按数量截取，nil 的 VolumeScreener 可以安全调用
+
+运行方式：
+
+	go run test/scanner/test_volume.go
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/scanner"
+	"crypto-ai-trader/utils"
+)
+
+// 模拟行情：交易对 -> 24小时成交额、当前K线成交量（前面的K线成交量都是100）
+var mockSymbols = map[string][2]float64{
+	"BTCUSDT":  {9e9, 150},   // 1.5倍，不是异动
+	"PEPEUSDT": {3e8, 800},   // 8倍
+	"WIFUSDT":  {1e8, 450},   // 4.5倍
+	"DOGEUSDT": {5e8, 900},   // 9倍，但被排除
+	"TINYUSDT": {1e6, 2000},  // 成交额不足
+	"ETHBTC":   {9e9, 10000}, // 不是USDT交易对
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 成交量异动筛选测试开始 ===")
+
+	var klineRequests atomic.Int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fapi/v1/ticker/24hr", func(w http.ResponseWriter, r *http.Request) {
+		var tickers []map[string]string
+		for symbol, v := range mockSymbols {
+			tickers = append(tickers, map[string]string{
+				"symbol":      symbol,
+				"quoteVolume": strconv.FormatFloat(v[0], 'f', 0, 64),
+			})
+		}
+		json.NewEncoder(w).Encode(tickers)
+	})
+	mux.HandleFunc("/fapi/v1/klines", func(w http.ResponseWriter, r *http.Request) {
+		klineRequests.Add(1)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		current := mockSymbols[r.URL.Query().Get("symbol")][1]
+		start := time.Now().Truncate(5 * time.Minute).Add(-time.Duration(limit-1) * 5 * time.Minute)
+		rows := make([][]interface{}, limit)
+		for i := range rows {
+			volume := 100.0
+			if i == limit-1 {
+				volume = current
+			}
+			open := start.Add(time.Duration(i) * 5 * time.Minute).UnixMilli()
+			rows[i] = []interface{}{open, "1.00", "1.05", "0.98", "1.02", strconv.FormatFloat(volume, 'f', 0, 64),
+				open + 5*60*1000 - 1, "0", 10, "0", "0", "0"}
+		}
+		json.NewEncoder(w).Encode(rows)
+	})
+	mock := httptest.NewServer(mux)
+	defer mock.Close()
+
+	client := binance.NewClient("", "", mock.URL, "")
+	cfg := scanner.VolumeConfig{
+		Interval:       "5m",
+		Lookback:       20,
+		Multiple:       3,
+		Universe:       4,
+		MinQuoteVolume: 5e7,
+		Exclude:        []string{"DOGEUSDT"},
+	}
+
+	// 1. 扫描一次
+	report, err := scanner.ScanVolume(client, cfg)
+	if err != nil {
+		fmt.Printf("扫描失败: %v\n", err)
+		return
+	}
+	fmt.Printf("扫描 %d 个交易对（期望3: BTC、PEPE、WIF），K线请求 %d 次\n", report.Scanned, klineRequests.Load())
+	for _, s := range report.Spikes {
+		fmt.Printf("  %s: 成交量 %.0f / 平均 %.0f = %.2f倍 涨跌 %.2f%%\n", s.Symbol, s.Volume, s.AvgVolume, s.Ratio, s.ChangePct)
+	}
+	fmt.Println("  期望 PEPEUSDT 8倍、WIFUSDT 4.5倍")
+
+	// 2. 按成交额只取前2个
+	cfg.Universe = 2
+	report, _ = scanner.ScanVolume(client, cfg)
+	fmt.Printf("前2个: 扫描 %d 个，异动 %d 个（期望2，1）\n", report.Scanned, len(report.Spikes))
+
+	// 3. 定时扫描
+	cfg.Universe = 10
+	screener := scanner.NewVolumeScreener(client, cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	go screener.Run(ctx, time.Minute)
+	time.Sleep(300 * time.Millisecond)
+	cancel()
+	top := screener.Candidates(1)
+	fmt.Printf("定时扫描: 全部候选 %d 个，前1个 %v（期望2，PEPEUSDT）\n", len(screener.Candidates(0)), symbols(top))
+
+	var none *scanner.VolumeScreener
+	fmt.Printf("nil筛选: 候选 %d 个 报告 %v（期望0，<nil>）\n", len(none.Candidates(0)), none.Report())
+
+	utils.Info("=== 成交量异动筛选测试完成 ===")
+}
+
+// symbols 候选的交易对
+func symbols(spikes []scanner.VolumeSpike) []string {
+	result := make([]string, len(spikes))
+	for i, s := range spikes {
+		result[i] = s.Symbol
+	}
+	return result
+}
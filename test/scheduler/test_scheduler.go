@@ -0,0 +1,124 @@
+/*
+定时任务调度测试程序
+
+测试内容：
+- Daily、Weekly：下一次执行时间（当天时刻已过时顺延）
+- At：只执行一次，时间过后不再执行
+- Offset：按时间表提前执行
+- Window.Active：时段内、提前量内、时段结束后
+- Scheduler：到期任务执行一次，Jobs 返回下一次执行时间
+- 低流动性时段配置验证
+- 每日定时平仓配置验证，平仓后不开仓的时长（跨天）
+
+运行方式：
+
+	go run test/scheduler/test_scheduler.go
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/scheduler"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 定时任务调度测试开始 ===")
+
+	// 2026-10-16 是周五
+	now := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+	layout := "01-02 Mon 15:04"
+
+	// 1. Daily、Weekly
+	daily := scheduler.Daily(20, 0, time.UTC)
+	fmt.Printf("Daily 20:00: %s（期望10-17 Sat 20:00）\n", daily(now).Format(layout))
+	weekly := scheduler.Weekly(time.Friday, 20, 0, time.UTC)
+	fmt.Printf("Weekly 周五20:00: %s（期望10-23 Fri 20:00）\n", weekly(now).Format(layout))
+	fmt.Printf("Weekly 周五22:00: %s（期望10-16 Fri 22:00）\n", scheduler.Weekly(time.Friday, 22, 0, time.UTC)(now).Format(layout))
+
+	// 2. At
+	at := scheduler.At(now.Add(time.Hour))
+	fmt.Printf("At: %s，之后 %v（期望10-16 Fri 22:00，true）\n", at(now).Format(layout), at(now.Add(2*time.Hour)).IsZero())
+
+	// 3. Offset：提前30分钟
+	early := scheduler.Offset(weekly, -30*time.Minute)
+	fmt.Printf("Offset -30m: %s（期望10-23 Fri 19:30）\n", early(now).Format(layout))
+	fmt.Printf("Offset 19:40 之后: %s（期望10-23 Fri 19:30）\n", early(time.Date(2026, 10, 16, 19, 40, 0, 0, time.UTC)).Format(layout))
+
+	// 4. Window.Active：周五20:00开始，持续52小时，提前30分钟
+	weekend := scheduler.Window{Name: "weekend", Start: weekly, Duration: 52 * time.Hour}
+	lead := 30 * time.Minute
+	for _, c := range []struct {
+		t    time.Time
+		want bool
+	}{
+		{time.Date(2026, 10, 16, 19, 0, 0, 0, time.UTC), false}, // 提前量之前
+		{time.Date(2026, 10, 16, 19, 45, 0, 0, time.UTC), true}, // 提前量内
+		{time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), true},  // 周日
+		{time.Date(2026, 10, 19, 0, 30, 0, 0, time.UTC), false}, // 周一00:00结束
+		{time.Date(2026, 10, 21, 12, 0, 0, 0, time.UTC), false}, // 周三
+	} {
+		fmt.Printf("Active %s: %v（期望%v）\n", c.t.Format(layout), weekend.Active(c.t, lead), c.want)
+	}
+
+	// 5. Scheduler：到期任务执行一次
+	jobs := scheduler.New()
+	runs := make(chan time.Time, 4)
+	jobs.Add("once", scheduler.At(time.Now().Add(200*time.Millisecond)), func(at time.Time) { runs <- at })
+	jobs.Add("daily", scheduler.Daily(0, 0, time.UTC), func(time.Time) {})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	jobs.Run(ctx, 100*time.Millisecond)
+	cancel()
+	fmt.Printf("执行次数: %d（期望1）\n", len(runs))
+	for _, j := range jobs.Jobs() {
+		fmt.Printf("  %s: 下一次 %v 最近 %v（期望daily有下一次，once下一次为零值、有最近执行时间）\n",
+			j.Name, !j.NextRun.IsZero(), !j.LastRun.IsZero())
+	}
+
+	// 6. 配置验证
+	for _, c := range []struct {
+		name string
+		cfg  config.FlattenConfig
+		ok   bool
+	}{
+		{"每周", config.FlattenConfig{Enabled: true, Windows: []config.FlattenWindow{{Weekday: "fri", Start: "20:00", Hours: 52}}}, true},
+		{"指定日期", config.FlattenConfig{Enabled: true, Windows: []config.FlattenWindow{{Date: "2026-12-24", Hours: 48}}}, true},
+		{"没有时段", config.FlattenConfig{Enabled: true}, false},
+		{"weekday和date都配置", config.FlattenConfig{Windows: []config.FlattenWindow{{Weekday: "fri", Date: "2026-12-24", Hours: 1}}}, false},
+		{"星期无效", config.FlattenConfig{Windows: []config.FlattenWindow{{Weekday: "funday", Hours: 1}}}, false},
+		{"时间无效", config.FlattenConfig{Windows: []config.FlattenWindow{{Weekday: "fri", Start: "25:00", Hours: 1}}}, false},
+		{"减仓比例无效", config.FlattenConfig{Action: config.FlattenReduce, ReducePct: 150}, false},
+	} {
+		err := c.cfg.Validate()
+		fmt.Printf("验证 %s: %v（期望%v） %v\n", c.name, err == nil, c.ok, err)
+	}
+
+	// 7. 每日定时平仓
+	for _, c := range []struct {
+		name string
+		cfg  config.FlatTimeConfig
+		ok   bool
+		want time.Duration
+	}{
+		{"跨天恢复", config.FlatTimeConfig{Enabled: true, Time: "21:00", Resume: "00:30"}, true, 3*time.Hour + 30*time.Minute},
+		{"当天恢复", config.FlatTimeConfig{Enabled: true, Time: "08:00", Resume: "09:15"}, true, 75 * time.Minute},
+		{"不恢复", config.FlatTimeConfig{Enabled: true, Time: "21:00"}, true, 0},
+		{"没有时间", config.FlatTimeConfig{Enabled: true}, false, 0},
+		{"恢复时间相同", config.FlatTimeConfig{Enabled: true, Time: "21:00", Resume: "21:00"}, false, 0},
+	} {
+		err := c.cfg.Validate()
+		fmt.Printf("定时平仓 %s: %v 不开仓 %v（期望%v %v） %v\n", c.name, err == nil, c.cfg.BlockDuration(), c.ok, c.want, err)
+	}
+
+	utils.Info("=== 定时任务调度测试完成 ===")
+}
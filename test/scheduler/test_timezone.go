@@ -0,0 +1,72 @@
+/*
+时区测试程序
+
+测试内容：
+- config.GetLocation：未配置时为UTC，配置 Asia/Shanghai 时按北京时间；无效时区无法加载（配置验证失败）
+- Daily、Weekly 按时区计算下一次执行时间（北京时间08:00 = UTC 00:00）
+- 低流动性时段的指定日期按时区的零点开始
+- 绩效指标的日收益率按时区的自然日切分：UTC跨天、北京时间同一天的两笔交易
+
+运行方式：
+
+	go run test/scheduler/test_timezone.go
+*/
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/journal"
+	"crypto-ai-trader/scheduler"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 时区测试开始 ===")
+
+	// 1. 配置
+	cfg := &config.Config{}
+	fmt.Printf("未配置: %s（期望UTC）\n", cfg.GetLocation())
+	cfg.Timezone = "Asia/Shanghai"
+	loc := cfg.GetLocation()
+	fmt.Printf("Asia/Shanghai: %s（期望Asia/Shanghai）\n", loc)
+	_, err := time.LoadLocation("Asia/Nowhere")
+	fmt.Printf("无效时区: %v（期望unknown time zone Asia/Nowhere）\n", err)
+
+	// 2. 定时任务（2026-10-16 21:00 UTC 为北京时间周六05:00）
+	now := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+	layout := "01-02 Mon 15:04 MST"
+	next := scheduler.Daily(8, 0, loc)(now)
+	fmt.Printf("每天08:00: %s / %s（期望10-17 Sat 08:00 CST / 10-17 Sat 00:00 UTC）\n", next.Format(layout), next.UTC().Format(layout))
+	next = scheduler.Daily(8, 0, time.UTC)(now)
+	fmt.Printf("每天08:00（UTC）: %s（期望10-17 Sat 08:00 UTC）\n", next.Format(layout))
+	next = scheduler.Weekly(time.Saturday, 4, 0, loc)(now)
+	fmt.Printf("每周六04:00: %s（期望10-24 Sat 04:00 CST，本周六04:00已过）\n", next.Format(layout))
+
+	// 3. 指定日期
+	date, _ := time.ParseInLocation(time.DateOnly, "2026-12-24", loc)
+	fmt.Printf("指定日期 2026-12-24 零点: %s（期望12-23 Wed 16:00 UTC）\n", date.UTC().Format(layout))
+
+	// 4. 日收益率：两笔交易出场时间为 UTC 3月1日23:00 和 3月2日01:00，北京时间都是3月2日
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	trades := []journal.Trade{
+		{Symbol: "BTCUSDT", EntryTime: start, ExitTime: time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC), NetPnL: 100},
+		{Symbol: "ETHUSDT", EntryTime: start, ExitTime: time.Date(2026, 3, 2, 1, 0, 0, 0, time.UTC), NetPnL: -50},
+	}
+	end := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	utc := journal.CalculateMetrics(trades, 10000, start, end)
+	local := journal.CalculateMetricsIn(trades, 10000, start, end, loc)
+	fmt.Printf("夏普（UTC）: %v（期望5.1712：日收益 +1%%、-0.495%%、0）\n", utc.Sharpe)
+	fmt.Printf("夏普（北京时间）: %v（期望13.5093：日收益 0、+0.5%%、0）\n", local.Sharpe)
+	fmt.Printf("总收益率: %v %v（期望0.5 0.5，与时区无关）\n", utc.TotalReturnPct, local.TotalReturnPct)
+
+	utils.Info("=== 时区测试完成 ===")
+}
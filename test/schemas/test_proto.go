@@ -0,0 +1,145 @@
+/*
+指标数据Protobuf编解码测试程序
+
+测试内容：
+- 短线、长线指标数据（含市场数据、强平统计、建议价位）编码后解码，JSON序列化结果与原数据完全一致
+- optional 字段值为0时保留（指针不为nil），为nil时仍为nil；缺失的时间周期解码后仍为nil
+- Protobuf 体积与JSON比较
+- 跳过不认识的字段（新版本增加的字段）；数据截断、比当前版本新、不支持的类型返回错误
+
+运行方式：
+
+	go run test/schemas/test_proto.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/schemas"
+	"crypto-ai-trader/utils"
+)
+
+// makeKlines 生成count根上涨的K线（最高、最低价为收盘价±0.5%，带主动买入量）
+func makeKlines(count int) []exchange.Kline {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', 12, 64) }
+	klines := make([]exchange.Kline, count)
+	for i := range klines {
+		c := 60000 * (1 + 0.002*float64(i))
+		klines[i] = exchange.Kline{
+			OpenTime:                 int64(i) * 60000,
+			Open:                     format(c * 0.999),
+			High:                     format(c * 1.005),
+			Low:                      format(c * 0.995),
+			Close:                    format(c),
+			Volume:                   "100",
+			QuoteAssetVolume:         format(c * 100),
+			TakerBuyBaseAssetVolume:  "55",
+			TakerBuyQuoteAssetVolume: format(c * 55),
+			CloseTime:                int64(i+1)*60000 - 1,
+		}
+	}
+	return klines
+}
+
+// roundTrip 编码后按类型解码，返回Protobuf字节数、JSON字节数和两次JSON是否相同
+func roundTrip(kind string, data interface{}) (int, int, bool) {
+	raw, err := schemas.MarshalProto(data)
+	if err != nil {
+		panic(err)
+	}
+	decoded, err := schemas.DecodeProto(kind, raw)
+	if err != nil {
+		panic(err)
+	}
+	before, _ := json.Marshal(data)
+	after, _ := json.Marshal(decoded)
+	return len(raw), len(before), string(before) == string(after)
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 指标数据Protobuf编解码测试开始 ===")
+
+	klines := makeKlines(100)
+	zero, change, percentile := 0.0, -1.25, 87.5
+	market := &indicators.MarketData{
+		OICurrent:        123.45,
+		OIHistory:        []float64{123.45, 120, 0, 118.2},
+		OIChange5m:       &zero,
+		OIChange15m:      &change,
+		OIPriceState:     indicators.OIPriceShortBuildup,
+		FundingRate:      -0.0123,
+		FundingAvg3:      0.01,
+		FundingFlip:      "to_negative",
+		ATRPct1h:         0.85,
+		ATRPctPercentile: &percentile,
+		VolatilityRegime: indicators.VolatilityElevated,
+		Liquidations: []indicators.LiquidationWindow{
+			{WindowMinutes: 5, LongUSDT: 150000, ShortUSDT: 0, Count: 3},
+			{WindowMinutes: 60, LongUSDT: 2500000, ShortUSDT: 800000, Count: 41},
+		},
+		LiquidationCascade: true,
+	}
+
+	// 1. 短线
+	short := indicators.CalculateShortTermIndicators("BTCUSDT", klines, klines, klines)
+	short.MarketData = market
+	protoSize, jsonSize, same := roundTrip(indicators.KindShortTerm, short)
+	fmt.Printf("短线: 往返后JSON相同=%v Protobuf=%d字节 JSON=%d字节（期望true，Protobuf明显更小）\n", same, protoSize, jsonSize)
+
+	raw, _ := schemas.MarshalProto(short)
+	decoded, _ := schemas.DecodeProto(indicators.KindShortTerm, raw)
+	md := decoded.(*indicators.ShortTermIndicators).MarketData
+	fmt.Printf("optional为0: oi_change_5m不为nil=%v 值=%v oi_change_25m为nil=%v（期望true 0 true）\n", md.OIChange5m != nil, *md.OIChange5m, md.OIChange25m == nil)
+	fmt.Printf("oi_history: %v（期望[123.45 120 0 118.2]）\n", md.OIHistory)
+
+	// 2. 长线（缺少一个时间周期）
+	long := indicators.CalculateLongTermIndicators("ETHUSDT", klines, klines, klines)
+	long.Timeframes.M15 = nil
+	_, _, same = roundTrip(indicators.KindLongTerm, long)
+	decoded, _ = schemas.DecodeProto(indicators.KindLongTerm, mustMarshal(long))
+	fmt.Printf("长线: 往返后JSON相同=%v 15m为nil=%v（期望true true）\n", same, decoded.(*indicators.LongTermIndicators).Timeframes.M15 == nil)
+
+	// 3. 单独的市场数据
+	marketRaw := mustMarshal(market)
+	marketBack, err := schemas.DecodeMarketDataProto(marketRaw)
+	before, _ := json.Marshal(market)
+	after, _ := json.Marshal(marketBack)
+	fmt.Printf("市场数据: 解码成功=%v JSON相同=%v（期望true true）\n", err == nil, string(before) == string(after))
+
+	// 4. 不认识的字段（字段99，varint 1）
+	withUnknown := append(append([]byte{}, raw...), 0x98, 0x06, 0x01)
+	_, err = schemas.DecodeProto(indicators.KindShortTerm, withUnknown)
+	fmt.Printf("跳过不认识的字段: %v（期望true）\n", err == nil)
+
+	// 5. 错误
+	_, err = schemas.DecodeProto(indicators.KindShortTerm, raw[:len(raw)-3])
+	fmt.Printf("数据截断: %v（期望解析失败）\n", err)
+	_, err = schemas.DecodeProto(indicators.KindShortTerm, []byte{0x08, 99})
+	fmt.Printf("未来版本: %v（期望比当前版本新的错误）\n", err)
+	_, err = schemas.DecodeProto(indicators.KindScalp, raw)
+	fmt.Printf("不支持的类型: %v（期望不支持Protobuf解码）\n", err)
+	_, err = schemas.MarshalProto("BTCUSDT")
+	fmt.Printf("不支持的编码类型: %v（期望不支持Protobuf编码）\n", err)
+
+	utils.Info("=== 指标数据Protobuf编解码测试结束 ===")
+}
+
+// mustMarshal 编码为Protobuf（失败时panic）
+func mustMarshal(data interface{}) []byte {
+	raw, err := schemas.MarshalProto(data)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
@@ -0,0 +1,95 @@
+/*
+指标数据结构版本测试程序
+
+测试内容：
+- 新计算的指标数据 schema_version 为当前版本，Migrate 原样返回
+- 版本1的数据（没有 schema_version，没有 atr_pct、bb_width_pct）：迁移后为当前版本，按 atr/close_price 和布林带补齐缺失字段，已有字段不变
+- Decode 迁移后还原为指标结构；比当前版本新的数据返回错误
+- History 按版本号从小到大，最后一个为当前版本
+
+运行方式：
+
+	go run test/schemas/test_schemas.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/schemas"
+	"crypto-ai-trader/utils"
+)
+
+// makeKlines 生成count根上涨的K线（最高、最低价为收盘价±0.5%）
+func makeKlines(count int) []exchange.Kline {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', 12, 64) }
+	klines := make([]exchange.Kline, count)
+	for i := range klines {
+		c := 100 * (1 + 0.002*float64(i))
+		klines[i] = exchange.Kline{
+			OpenTime:  int64(i) * 60000,
+			Open:      format(c),
+			High:      format(c * 1.005),
+			Low:       format(c * 0.995),
+			Close:     format(c),
+			Volume:    "100",
+			CloseTime: int64(i+1)*60000 - 1,
+		}
+	}
+	return klines
+}
+
+// v1Payload 版本1的短线指标数据：15m 没有 atr_pct、bb_width_pct，5m 已有 atr_pct
+const v1Payload = `{
+  "symbol": "BTCUSDT",
+  "timestamp": 1700000000,
+  "timeframes": {
+    "1h": null,
+    "15m": {"close_price": 50000, "atr": 250, "rsi": 55, "bb": {"upper": 51000, "middle": 50000, "lower": 49000}},
+    "5m": {"close_price": 50000, "atr": 100, "atr_pct": 0.3, "bb": {"upper": 50500, "middle": 50000, "lower": 49500}}
+  }
+}`
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 指标数据结构版本测试开始 ===")
+
+	// 1. 当前版本
+	klines := makeKlines(100)
+	raw, _ := json.Marshal(indicators.CalculateShortTermIndicators("BTCUSDT", klines, klines, klines))
+	version, _ := schemas.Version(raw)
+	migrated, err := schemas.Migrate(raw)
+	fmt.Printf("新数据版本: %d 当前版本: %d 原样返回: %v（期望2 2 true）\n", version, schemas.Current(), err == nil && string(migrated) == string(raw))
+
+	// 2. 版本1迁移
+	version, _ = schemas.Version([]byte(v1Payload))
+	fmt.Printf("旧数据版本: %d（期望1）\n", version)
+	data, err := schemas.Decode(indicators.KindShortTerm, []byte(v1Payload))
+	if err != nil {
+		panic(err)
+	}
+	st := data.(*indicators.ShortTermIndicators)
+	fmt.Printf("迁移后版本: %d（期望2）\n", st.SchemaVersion)
+	fmt.Printf("15m: atr_pct=%v bb_width_pct=%v rsi=%v（期望0.5 4 55）\n", st.Timeframes.M15.ATRPct, st.Timeframes.M15.BBWidthPct, st.Timeframes.M15.RSI)
+	fmt.Printf("5m: atr_pct=%v bb_width_pct=%v（期望0.3（保留原值） 2）\n", st.Timeframes.M5.ATRPct, st.Timeframes.M5.BBWidthPct)
+	fmt.Printf("1h: %v（期望nil）\n", st.Timeframes.H1)
+
+	// 3. 未来版本
+	_, err = schemas.Decode(indicators.KindShortTerm, []byte(`{"schema_version": 99, "symbol": "BTCUSDT"}`))
+	fmt.Printf("未来版本: %v（期望比当前版本新的错误）\n", err)
+
+	// 4. 变更记录
+	history := schemas.History()
+	fmt.Printf("变更记录: %d个 最后版本=%d（期望2 2）\n", len(history), history[len(history)-1].Version)
+
+	utils.Info("=== 指标数据结构版本测试结束 ===")
+}
@@ -0,0 +1,119 @@
+/*
+密钥来源测试程序
+
+测试内容：
+- env：读取环境变量，未设置时报错
+- file：读取临时密钥文件中的键，没有该键时报错
+- vault：本地模拟 Vault KV v2 接口（不访问真实Vault），校验令牌和命名空间，同一路径只请求一次
+- 不带前缀的值原样返回（原有直接写密钥的配置不受影响）
+- ResolveAccounts：原地替换账号的密钥，错误信息只包含账号、字段和密钥名称，不包含密钥值
+
+运行方式：
+
+	go run test/secrets/test_secrets.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/secrets"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 密钥来源测试开始 ===")
+
+	// 模拟Vault
+	var requests atomic.Int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/secret/data/trading/account_2", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("X-Vault-Token") != "test-token" || r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"data": map[string]string{"api_key": "vault-key", "api_secret": "vault-secret"}},
+		})
+	})
+	mock := httptest.NewServer(mux)
+	defer mock.Close()
+
+	// 临时密钥文件
+	dir, err := os.MkdirTemp("", "secrets")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "secrets.yml")
+	os.WriteFile(path, []byte("account_1_api_secret: file-secret\n"), 0600)
+
+	os.Setenv("TEST_BINANCE_API_KEY_1", "env-key")
+	cfg := config.SecretsConfig{
+		File:  path,
+		Vault: config.VaultConfig{Address: mock.URL, Token: "test-token", Namespace: "team", Mount: "secret", TimeoutSec: 5},
+	}
+	r := secrets.NewResolver(cfg)
+
+	// 1. 单个值
+	for _, c := range []struct {
+		value  string
+		expect string
+	}{
+		{"env:TEST_BINANCE_API_KEY_1", "env-key"},
+		{"env:TEST_NOT_SET", "环境变量未设置或为空"},
+		{"file:account_1_api_secret", "file-secret"},
+		{"file:missing", "密钥文件中没有该键"},
+		{"vault:trading/account_2#api_key", "vault-key"},
+		{"vault:trading/account_2", "格式应为 路径#字段"},
+		{"vault:trading/other#api_key", "状态码 404"},
+		{"plain-api-key", "plain-api-key（原样返回）"},
+		{"env:", "缺少名称"},
+	} {
+		value, err := r.Resolve(c.value)
+		fmt.Printf("%s -> %q %v（期望%s）\n", c.value, value, err, c.expect)
+	}
+
+	// 2. 账号配置
+	accounts := []config.Account{
+		{ID: "account_1", APIKey: "env:TEST_BINANCE_API_KEY_1", APISecret: "file:account_1_api_secret"},
+		{ID: "account_2", APIKey: "vault:trading/account_2#api_key", APISecret: "vault:trading/account_2#api_secret"},
+	}
+	err = r.ResolveAccounts(accounts)
+	fmt.Printf("解析账号: %v（期望<nil>）\n", err)
+	fmt.Printf("account_1: %s %s（期望env-key file-secret）\n", accounts[0].APIKey, accounts[0].APISecret)
+	fmt.Printf("account_2: %s %s（期望vault-key vault-secret）\n", accounts[1].APIKey, accounts[1].APISecret)
+
+	// 3. Vault同一路径只请求一次（第1步请求过一次，api_key和api_secret使用缓存）
+	fmt.Printf("account_2 路径请求次数: %d（期望1）\n", requests.Load())
+
+	// 4. 令牌错误
+	bad := cfg
+	bad.Vault.Token = "wrong-token"
+	_, err = secrets.NewResolver(bad).Resolve("vault:trading/account_2#api_key")
+	fmt.Printf("令牌错误: %v（期望状态码 403 permission denied）\n", err)
+
+	// 5. 错误信息不包含密钥值
+	accounts = []config.Account{{ID: "account_3", APIKey: "env:TEST_BINANCE_API_KEY_1", APISecret: "file:missing"}}
+	err = r.ResolveAccounts(accounts)
+	fmt.Printf("解析失败: %v（期望账号[account_3]的api_secret）\n", err)
+	fmt.Printf("错误信息包含密钥值: %v（期望false）\n", strings.Contains(err.Error(), "env-key") || strings.Contains(err.Error(), "file-secret"))
+
+	utils.Info("=== 密钥来源测试完成 ===")
+}
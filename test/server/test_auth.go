@@ -0,0 +1,91 @@
+/*
+状态API鉴权测试程序（本地端口，不需要API密钥）
+
+测试内容：
+- 未配置token：GET接口可访问，POST接口（修改状态）未注册，返回404
+- 配置了token：不带或带错误的Authorization返回401，正确的token可以访问GET和POST接口
+
+运行方式：
+
+	go run test/server/test_auth.go
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/server"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// start 在listen上启动注册了一个GET和一个POST接口的服务
+func start(ctx context.Context, listen, token string) {
+	srv := server.New(config.APIConfig{Enabled: true, Listen: listen, Token: token})
+	srv.HandleJSON("GET", "/api/status", func(r *http.Request) (interface{}, error) {
+		return map[string]string{"status": "ok"}, nil
+	})
+	srv.HandleJSON("POST", "/api/close-all", func(r *http.Request) (interface{}, error) {
+		return map[string]string{"result": "closed"}, nil
+	})
+	go func() {
+		if err := srv.Run(ctx); err != nil {
+			utils.Error("状态API启动失败", zap.Error(err))
+		}
+	}()
+	time.Sleep(200 * time.Millisecond)
+}
+
+// status 发送请求，返回HTTP状态码
+func status(method, url, auth string) int {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		utils.Fatal("创建请求失败", zap.Error(err))
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		utils.Fatal("请求失败", zap.Error(err))
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 状态API鉴权测试开始 ===")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 1. 未配置token
+	fmt.Println("===== 未配置token =====")
+	start(ctx, "127.0.0.1:18081", "")
+	base := "http://127.0.0.1:18081"
+	fmt.Printf("GET /api/status: %d（期望200）\n", status("GET", base+"/api/status", ""))
+	fmt.Printf("POST /api/close-all: %d（期望404，未注册）\n", status("POST", base+"/api/close-all", ""))
+
+	// 2. 配置了token
+	fmt.Println("\n===== 配置了token =====")
+	start(ctx, "127.0.0.1:18082", "secret-token")
+	base = "http://127.0.0.1:18082"
+	fmt.Printf("POST 不带token: %d（期望401）\n", status("POST", base+"/api/close-all", ""))
+	fmt.Printf("POST 错误token: %d（期望401）\n", status("POST", base+"/api/close-all", "Bearer secret-tokeX"))
+	fmt.Printf("POST token前缀: %d（期望401）\n", status("POST", base+"/api/close-all", "Bearer secret"))
+	fmt.Printf("GET 正确token: %d（期望200）\n", status("GET", base+"/api/status", "Bearer secret-token"))
+	fmt.Printf("POST 正确token: %d（期望200）\n", status("POST", base+"/api/close-all", "Bearer secret-token"))
+
+	utils.Info("=== 状态API鉴权测试结束 ===")
+}
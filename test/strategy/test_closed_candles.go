@@ -0,0 +1,142 @@
+/*
+已收盘K线计算测试程序
+
+测试内容：
+- 模拟行情接口（最后一根K线未收盘，收盘价随每次请求变化，不访问交易所）
+- DropForming 去掉各周期未收盘的K线并保存到 Forming，已收盘的K线不受影响
+- 只用已收盘K线计算时，未收盘K线的价格变化不改变指标；close_price 为最近已收盘K线的收盘价，live_price 为最新价
+- 包含未收盘K线时（不调用 DropForming）close_price 随最新价变化，不设置 live_price
+
+运行方式：
+
+	go run test/strategy/test_closed_candles.go
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/strategy"
+	"crypto-ai-trader/utils"
+)
+
+// intervals 各周期的时长
+var intervals = map[string]time.Duration{
+	"1h":  time.Hour,
+	"15m": 15 * time.Minute,
+	"5m":  5 * time.Minute,
+}
+
+// mockMarket 模拟行情接口：已收盘K线的收盘价为 100+序号，最后一根未收盘K线的收盘价为 live
+type mockMarket struct {
+	live float64
+}
+
+func (m *mockMarket) Name() string             { return "mock" }
+func (m *mockMarket) HasDerivativesData() bool { return false }
+
+func (m *mockMarket) GetKlines(symbol, interval string, limit int) ([]exchange.Kline, error) {
+	period := intervals[interval]
+	current := time.Now().Truncate(period)
+	klines := make([]exchange.Kline, limit)
+	for i := range klines {
+		open := current.Add(-time.Duration(limit-1-i) * period)
+		price := 100 + float64(i)
+		if i == limit-1 {
+			price = m.live
+		}
+		p := strconv.FormatFloat(price, 'f', 2, 64)
+		klines[i] = exchange.Kline{
+			OpenTime:  open.UnixMilli(),
+			Open:      p,
+			High:      p,
+			Low:       p,
+			Close:     p,
+			Volume:    "10",
+			CloseTime: open.Add(period).UnixMilli() - 1,
+		}
+	}
+	return klines, nil
+}
+
+func (m *mockMarket) GetOpenInterest(symbol string) (float64, error) {
+	return 0, exchange.ErrUnsupported
+}
+
+func (m *mockMarket) GetFundingRate(symbol string) (float64, error) {
+	return 0, exchange.ErrUnsupported
+}
+
+func (m *mockMarket) GetFundingRateHistory(symbol string, limit int) ([]float64, error) {
+	return nil, exchange.ErrUnsupported
+}
+
+// primary 计算一个周期，返回主分析周期（15m）的指标
+func primary(strat strategy.Strategy, market *mockMarket, closedOnly bool) *indicators.TimeframeData {
+	data := strategy.FetchCycleData(market, "account_1", []string{"BTCUSDT"}, strat.Timeframes(), 100, nil)
+	if closedOnly {
+		data.DropForming(time.Now())
+		fmt.Printf("  去掉未收盘K线: 15m剩余%d根 Forming周期数=%d（期望99 3）\n", len(data.Klines["BTCUSDT"]["15m"]), len(data.Forming["BTCUSDT"]))
+	}
+	signals := strat.OnCycle(context.Background(), data)
+	data.StampLive(signals)
+	if len(signals) == 0 {
+		return nil
+	}
+	_, tf, _ := indicators.PrimaryTimeframe(signals[0].Data)
+	return tf
+}
+
+// livePrice 格式化 live_price（未设置时为nil）
+func livePrice(tf *indicators.TimeframeData) string {
+	if tf.LivePrice == nil {
+		return "nil"
+	}
+	return strconv.FormatFloat(*tf.LivePrice, 'f', 2, 64)
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 已收盘K线计算测试开始 ===")
+
+	strat, err := strategy.New("short_term")
+	if err != nil {
+		panic(err)
+	}
+	if err := strat.Init(nil); err != nil {
+		panic(err)
+	}
+	market := &mockMarket{live: 150}
+
+	// 1. 只用已收盘K线
+	fmt.Println("【只用已收盘K线】")
+	a := primary(strat, market, true)
+	market.live = 80
+	b := primary(strat, market, true)
+	fmt.Printf("  close_price: %.2f → %.2f（期望198.00 → 198.00）\n", a.ClosePrice, b.ClosePrice)
+	fmt.Printf("  live_price: %s → %s（期望150.00 → 80.00）\n", livePrice(a), livePrice(b))
+	fmt.Printf("  EMA9不变: %v RSI不变: %v（期望true true）\n", a.EMA9 == b.EMA9, a.RSI == b.RSI)
+	fmt.Printf("  当前价格: %.2f（期望80.00）\n", b.CurrentPrice())
+
+	// 2. 包含未收盘K线
+	fmt.Println("【包含未收盘K线】")
+	market.live = 150
+	c := primary(strat, market, false)
+	market.live = 80
+	d := primary(strat, market, false)
+	fmt.Printf("  close_price: %.2f → %.2f（期望150.00 → 80.00）\n", c.ClosePrice, d.ClosePrice)
+	fmt.Printf("  live_price: %s（期望nil）\n", livePrice(d))
+	fmt.Printf("  EMA9变化: %v 当前价格: %.2f（期望true 80.00）\n", c.EMA9 != d.EMA9, d.CurrentPrice())
+
+	utils.Info("=== 已收盘K线计算测试结束 ===")
+}
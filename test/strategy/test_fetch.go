@@ -0,0 +1,91 @@
+/*
+周期数据并发获取测试程序
+
+测试内容：
+- 模拟行情接口（每个K线请求延迟不同，不访问交易所）
+- 同一交易对的三个周期并发获取，每个交易对的耗时约等于最慢的一个请求
+- 任意周期获取失败的交易对被跳过，其他交易对不受影响
+
+运行方式：
+
+	go run test/strategy/test_fetch.go
+*/
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/strategy"
+	"crypto-ai-trader/utils"
+)
+
+// delays 各周期K线请求的模拟延迟
+var delays = map[string]time.Duration{
+	"1h":  100 * time.Millisecond,
+	"15m": 200 * time.Millisecond,
+	"5m":  300 * time.Millisecond,
+}
+
+// mockMarket 模拟行情接口
+type mockMarket struct {
+	requests atomic.Int64
+	failing  string // K线请求失败的交易对
+}
+
+func (m *mockMarket) Name() string             { return "mock" }
+func (m *mockMarket) HasDerivativesData() bool { return false }
+
+func (m *mockMarket) GetKlines(symbol, interval string, limit int) ([]exchange.Kline, error) {
+	m.requests.Add(1)
+	time.Sleep(delays[interval])
+	if symbol == m.failing && interval == "15m" {
+		return nil, fmt.Errorf("模拟请求失败: %s %s", symbol, interval)
+	}
+	return make([]exchange.Kline, limit), nil
+}
+
+func (m *mockMarket) GetOpenInterest(symbol string) (float64, error) {
+	return 0, exchange.ErrUnsupported
+}
+
+func (m *mockMarket) GetFundingRate(symbol string) (float64, error) {
+	return 0, exchange.ErrUnsupported
+}
+
+func (m *mockMarket) GetFundingRateHistory(symbol string, limit int) ([]float64, error) {
+	return nil, exchange.ErrUnsupported
+}
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 周期数据并发获取测试开始 ===")
+
+	timeframes := []string{"1h", "15m", "5m"}
+	market := &mockMarket{failing: "ETHUSDT"}
+	symbols := []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}
+
+	start := time.Now()
+	data := strategy.FetchCycleData(market, "account_1", symbols, timeframes, 100, nil)
+	elapsed := time.Since(start)
+
+	fmt.Printf("请求数: %d，耗时: %v（顺序获取约 %v，并发获取约 %v）\n",
+		market.requests.Load(), elapsed.Round(10*time.Millisecond),
+		time.Duration(len(symbols))*(delays["1h"]+delays["15m"]+delays["5m"]),
+		time.Duration(len(symbols))*delays["5m"])
+	fmt.Printf("成功的交易对: %v（期望 [BTCUSDT SOLUSDT]，ETHUSDT 的15m请求失败被跳过）\n", data.Symbols)
+	for _, symbol := range data.Symbols {
+		for _, interval := range timeframes {
+			fmt.Printf("  %s %s: %d 根\n", symbol, interval, len(data.Klines[symbol][interval]))
+		}
+	}
+
+	utils.Info("=== 周期数据并发获取测试完成 ===")
+}
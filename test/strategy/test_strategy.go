@@ -0,0 +1,85 @@
+/*
+策略模块测试程序
+
+测试内容：
+- 列出所有已注册的策略
+- 按名称创建策略实例
+- 对BTCUSDT执行一次完整的策略周期
+- 输出信号JSON
+
+运行方式：
+  go run test/strategy/test_strategy.go
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/exchange"
+	"crypto-ai-trader/strategy"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 策略模块测试开始 ===")
+
+	// 加载配置
+	cfg, err := config.Load("configs/config.yml")
+	if err != nil {
+		utils.Fatal("加载配置失败", zap.Error(err))
+	}
+
+	// 创建客户端
+	acc := cfg.GetEnabledAccounts()[0]
+	client := binance.NewClient(
+		acc.APIKey,
+		acc.APISecret,
+		cfg.Binance.FuturesURL,
+		cfg.GetProxyURL(),
+	)
+
+	fmt.Println("【已注册的策略】")
+	for _, name := range strategy.Names() {
+		fmt.Printf("  - %s\n", name)
+	}
+	fmt.Println()
+
+	oiCacheManager := utils.NewOICacheManager(5)
+	symbols := []string{"BTCUSDT"}
+
+	for _, name := range strategy.Names() {
+		strat, err := strategy.New(name)
+		if err != nil {
+			utils.Fatal("创建策略失败", zap.Error(err))
+		}
+		if err := strat.Init(nil); err != nil {
+			utils.Fatal("初始化策略失败", zap.Error(err))
+		}
+
+		fmt.Printf("【策略 %s】周期: %v 运行间隔: %v\n", strat.Name(), strat.Timeframes(), strat.Interval())
+
+		data := strategy.FetchCycleData(exchange.NewBinance(client), "test", symbols, strat.Timeframes(), 100, oiCacheManager)
+		signals := strat.OnCycle(context.Background(), data)
+		fmt.Printf("  ✓ 生成信号: %d个\n", len(signals))
+
+		for _, sig := range signals {
+			sigJSON, _ := json.MarshalIndent(sig, "", "  ")
+			fmt.Println(string(sigJSON))
+		}
+		fmt.Println()
+	}
+
+	utils.Info("=== 测试完成 ===")
+}
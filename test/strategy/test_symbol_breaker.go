@@ -0,0 +1,87 @@
+/*
+交易对错误熔断测试程序
+
+测试内容：
+- 连续失败 max_failures 个周期后熔断，熔断期间 Allow 跳过该交易对，成功一次清零连续失败次数
+- 跳过时间到期后放行探测：探测失败跳过时间翻倍（不超过上限），探测成功恢复并返回恢复事件
+- CycleData.Fail 同一周期只保留第一个失败原因
+- 未启用（nil）时不过滤、不记录
+
+运行方式：
+
+	go run test/strategy/test_symbol_breaker.go
+*/
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/strategy"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 交易对错误熔断测试开始 ===")
+
+	symbols := []string{"BTCUSDT", "BADUSDT"}
+	b := strategy.NewSymbolBreaker("account_1", 3, 100*time.Millisecond, 300*time.Millisecond)
+
+	// cycle 模拟一个周期：BADUSDT 按 fail 失败，返回本周期请求的交易对和事件
+	cycle := func(fail bool) ([]string, []strategy.BreakerEvent) {
+		allowed := b.Allow(symbols)
+		data := &strategy.CycleData{}
+		if fail {
+			data.Fail("BADUSDT", "获取1h K线失败: 无效的交易对")
+			data.Fail("BADUSDT", "计算短线指标失败")
+		}
+		return allowed, b.Record(allowed, data)
+	}
+
+	// 1. 连续失败与清零
+	cycle(true)
+	cycle(true)
+	cycle(false)
+	fmt.Printf("失败2次后成功: 记录数=%d（期望0）\n", len(b.Status()))
+
+	cycle(true)
+	cycle(true)
+	_, events := cycle(true)
+	fmt.Printf("连续失败3次: 事件数=%d open=%v probe=%v backoff=%v reason=%s（期望1 true false 100ms 获取1h K线失败: 无效的交易对）\n",
+		len(events), events[0].Open, events[0].Probe, events[0].Backoff, events[0].Reason)
+
+	// 2. 熔断期间跳过
+	allowed, _ := cycle(true)
+	fmt.Printf("熔断中请求的交易对: %v（期望[BTCUSDT]）\n", allowed)
+	st := b.Status()[0]
+	fmt.Printf("状态: symbol=%s failures=%d open=%v backoff_sec=%.1f（期望BADUSDT 3 true 0.1）\n", st.Symbol, st.Failures, st.Open, st.Backoff)
+
+	// 3. 探测失败，跳过时间翻倍
+	time.Sleep(120 * time.Millisecond)
+	allowed, events = cycle(true)
+	fmt.Printf("到期后探测: 请求=%v probe=%v backoff=%v（期望[BTCUSDT BADUSDT] true 200ms）\n", allowed, events[0].Probe, events[0].Backoff)
+
+	time.Sleep(220 * time.Millisecond)
+	_, events = cycle(true)
+	fmt.Printf("再次探测失败: backoff=%v（期望300ms，不超过上限）\n", events[0].Backoff)
+
+	// 4. 探测成功恢复
+	time.Sleep(320 * time.Millisecond)
+	allowed, events = cycle(false)
+	fmt.Printf("探测成功: 请求=%v 事件数=%d open=%v failures=%d（期望[BTCUSDT BADUSDT] 1 false 5）\n",
+		allowed, len(events), events[0].Open, events[0].Failures)
+	fmt.Printf("恢复后记录数=%d（期望0）\n", len(b.Status()))
+
+	// 5. 未启用
+	var disabled *strategy.SymbolBreaker
+	fmt.Printf("未启用: Allow=%v Record=%v Status=%v（期望[BTCUSDT BADUSDT] [] []）\n",
+		disabled.Allow(symbols), disabled.Record(symbols, &strategy.CycleData{}), disabled.Status())
+
+	utils.Info("=== 交易对错误熔断测试结束 ===")
+}
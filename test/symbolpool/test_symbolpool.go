@@ -0,0 +1,89 @@
+/*
+交易对池调整测试程序
+
+测试内容：
+- Add/Remove：全部账号（*）和单个账号两级调整，先应用全部账号的调整再应用账号的调整
+- 同一级中移除已加入的交易对、重新加入已移除的交易对
+- Apply：convert 转换调整中的交易对（币本位合约），不修改传入的交易对池
+- 调整写入临时目录的 symbol_overrides.json，重新加载后保持；空的范围和交易对报错
+
+运行方式：
+
+	go run test/symbolpool/test_symbolpool.go
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/symbolpool"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 交易对池调整测试开始 ===")
+
+	dir, err := os.MkdirTemp("", "symbolpool")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := []string{"BTCUSDT", "ETHUSDT", "DOGEUSDT"}
+	o := symbolpool.New(dir)
+	fmt.Printf("无调整: %v（期望[BTCUSDT ETHUSDT DOGEUSDT]）\n", o.Apply("account_1", base, nil))
+
+	// 1. 全部账号加入、移除
+	if err := o.Add(symbolpool.ScopeAll, []string{"SUIUSDT"}); err != nil {
+		panic(err)
+	}
+	if err := o.Remove(symbolpool.ScopeAll, []string{"DOGEUSDT"}); err != nil {
+		panic(err)
+	}
+	fmt.Printf("全部账号调整后 account_1: %v（期望[BTCUSDT ETHUSDT SUIUSDT]）\n", o.Apply("account_1", base, nil))
+
+	// 2. 账号级调整覆盖全部账号的调整
+	if err := o.Add("account_2", []string{"DOGEUSDT"}); err != nil {
+		panic(err)
+	}
+	if err := o.Remove("account_2", []string{"SUIUSDT"}); err != nil {
+		panic(err)
+	}
+	fmt.Printf("account_2: %v（期望[BTCUSDT ETHUSDT DOGEUSDT]）\n", o.Apply("account_2", base, nil))
+	fmt.Printf("account_1 不受影响: %v（期望[BTCUSDT ETHUSDT SUIUSDT]）\n", o.Apply("account_1", base, nil))
+
+	// 3. 同一级中后一次操作覆盖前一次
+	if err := o.Add(symbolpool.ScopeAll, []string{"DOGEUSDT"}); err != nil {
+		panic(err)
+	}
+	c := o.All()[symbolpool.ScopeAll]
+	fmt.Printf("重新加入 DOGEUSDT: added=%v removed=%v（期望[SUIUSDT DOGEUSDT] []）\n", c.Added, c.Removed)
+	if err := o.Remove(symbolpool.ScopeAll, []string{"DOGEUSDT"}); err != nil {
+		panic(err)
+	}
+
+	// 4. 币本位转换
+	coinM := []string{"BTCUSD_PERP", "ETHUSD_PERP", "DOGEUSD_PERP"}
+	fmt.Printf("币本位 account_3: %v（期望[BTCUSD_PERP ETHUSD_PERP SUIUSD_PERP]）\n", o.Apply("account_3", coinM, binance.CoinMSymbol))
+	fmt.Printf("传入的交易对池未修改: %v（期望[BTCUSDT ETHUSDT DOGEUSDT]）\n", base)
+
+	// 5. 参数错误
+	fmt.Printf("空范围: err=%v（期望报错）\n", o.Add("", []string{"BTCUSDT"}))
+	fmt.Printf("空交易对: err=%v（期望报错）\n", o.Remove("account_1", nil))
+
+	// 6. 重新加载
+	o2 := symbolpool.New(dir)
+	fmt.Printf("重新加载 account_1: %v（期望[BTCUSDT ETHUSDT SUIUSDT]）\n", o2.Apply("account_1", base, nil))
+	fmt.Printf("重新加载 account_2: %v（期望[BTCUSDT ETHUSDT DOGEUSDT]）\n", o2.Apply("account_2", base, nil))
+	fmt.Printf("调整范围数量=%d（期望2）\n", len(o2.All()))
+
+	utils.Info("=== 交易对池调整测试结束 ===")
+}
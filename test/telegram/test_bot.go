@@ -0,0 +1,106 @@
+/*
+Telegram机器人命令测试程序
+
+测试内容：
+- 本地模拟的 Telegram 接口：getUpdates 返回一批消息，sendMessage 记录回复
+- 未授权聊天的命令被忽略，不回复
+- 授权聊天的命令（含 /command@BotName 形式）调用对应的处理函数并回复
+- 未注册的命令回复命令列表，普通文字消息不处理
+- 下一次 getUpdates 的 offset 为最后一条消息的 update_id + 1
+
+运行方式：
+
+	go run test/telegram/test_bot.go
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/telegram"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== Telegram机器人命令测试开始 ===")
+
+	message := func(id, chat int64, text string) map[string]interface{} {
+		return map[string]interface{}{
+			"update_id": id,
+			"message":   map[string]interface{}{"text": text, "chat": map[string]int64{"id": chat}},
+		}
+	}
+	batch := []map[string]interface{}{
+		message(10, 999, "/closeall"),            // 未授权聊天
+		message(11, 42, "/closeall@MyBot acc-1"), // 带机器人用户名
+		message(12, 42, "/unknown"),
+		message(13, 42, "hello"),
+	}
+
+	var mu sync.Mutex
+	var replies []string
+	var offsets []string
+	served := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+			offsets = append(offsets, r.URL.Query().Get("offset"))
+			result := []map[string]interface{}{}
+			if !served {
+				result, served = batch, true
+			} else {
+				time.Sleep(20 * time.Millisecond)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": result})
+		case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+			var req struct {
+				ChatID int64  `json:"chat_id"`
+				Text   string `json:"text"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			replies = append(replies, fmt.Sprintf("%d: %s", req.ChatID, req.Text))
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": map[string]int{"message_id": 1}})
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "description": "Not Found"})
+		}
+	}))
+	defer server.Close()
+
+	bot := telegram.NewBot(config.TelegramConfig{BaseURL: server.URL, BotToken: "TOKEN", ChatIDs: []int64{42}, PollSec: 1}, "")
+	var args []string
+	bot.Handle("closeall", "[账号ID...] 紧急平仓", func(chatID int64, a []string) string {
+		args = a
+		return "收到 closeall"
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	bot.Run(ctx)
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	fmt.Printf("回复 %d 条（期望2：closeall 和命令列表）\n", len(replies))
+	for _, reply := range replies {
+		fmt.Printf("  %s\n", strings.ReplaceAll(reply, "\n", " | "))
+	}
+	fmt.Printf("closeall 参数: %v（期望[acc-1]）\n", args)
+	fmt.Printf("offset: 第一次 %s，第二次 %s（期望0 14）\n", offsets[0], offsets[1])
+
+	utils.Info("=== Telegram机器人命令测试完成 ===")
+}
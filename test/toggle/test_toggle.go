@@ -0,0 +1,88 @@
+/*
+账号开关测试程序
+
+测试内容：
+- Disable：positions 为空时默认为 manage，无效的处理方式报错
+- 重复停用更新处理方式和原因，保留原停用时间
+- 状态写入临时目录的 account_toggles.json，重新加载后保持停用
+- Enable：恢复后 Get 返回nil，未停用的账号返回false
+- All：返回全部停用的账号
+
+运行方式：
+
+	go run test/toggle/test_toggle.go
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"crypto-ai-trader/toggle"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 账号开关测试开始 ===")
+
+	dir, err := os.MkdirTemp("", "toggle")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t := toggle.New(dir)
+	fmt.Printf("初始 Get(account_1)=%v（期望<nil>）\n", t.Get("account_1"))
+
+	// 1. 默认处理方式
+	s, err := t.Disable("account_1", "", "提示词调整中", "api")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("停用 account_1: positions=%s reason=%s source=%s（期望manage 提示词调整中 api）\n",
+		s.Positions, s.Reason, s.Source)
+
+	// 2. 无效的处理方式
+	_, err = t.Disable("account_2", "hold", "", "api")
+	fmt.Printf("positions=hold: err=%v（期望报错）\n", err)
+	fmt.Printf("account_2 Get=%v（期望<nil>）\n", t.Get("account_2"))
+
+	// 3. 重复停用
+	s2, err := t.Disable("account_1", toggle.PositionsClose, "紧急", "api")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("重复停用: positions=%s reason=%s 停用时间不变=%v（期望close 紧急 true）\n",
+		s2.Positions, s2.Reason, s2.DisabledAt.Equal(s.DisabledAt))
+
+	if _, err := t.Disable("account_3", toggle.PositionsManage, "", "api"); err != nil {
+		panic(err)
+	}
+
+	// 4. 重新加载
+	t2 := toggle.New(dir)
+	r := t2.Get("account_1")
+	fmt.Printf("重新加载 account_1: positions=%s reason=%s（期望close 紧急）\n", r.Positions, r.Reason)
+	fmt.Printf("重新加载 All 数量=%d（期望2）\n", len(t2.All()))
+
+	// 5. 恢复
+	ok, err := t2.Enable("account_1", "api")
+	fmt.Printf("恢复 account_1: enabled=%v err=%v（期望true <nil>）\n", ok, err)
+	ok, err = t2.Enable("account_1", "api")
+	fmt.Printf("再次恢复 account_1: enabled=%v err=%v（期望false <nil>）\n", ok, err)
+	fmt.Printf("恢复后 Get(account_1)=%v（期望<nil>）\n", t2.Get("account_1"))
+
+	// 6. 恢复后重新加载
+	t3 := toggle.New(dir)
+	for id, st := range t3.All() {
+		fmt.Printf("最终停用账号: %s positions=%s（期望只有account_3 manage）\n", id, st.Positions)
+	}
+
+	utils.Info("=== 账号开关测试结束 ===")
+}
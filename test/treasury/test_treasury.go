@@ -0,0 +1,136 @@
+/*
+合约账户资金自动调拨测试程序
+
+测试内容：
+- Plan：低于下限转入到目标，高于上限转出到目标，转入不超过现货可用余额，单次划转上限截断
+- 本地模拟币安合约余额、现货余额和划转接口（不访问交易所）
+- Transfer：只能通过现货客户端调用，提交划转类型、资产和数量，返回划转ID
+- Check：按合约可用余额转入或转出，回调收到划转记录；余额在上下限之间或现货没有余额时不划转
+
+运行方式：
+
+	go run test/treasury/test_treasury.go
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/treasury"
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 合约账户资金自动调拨测试开始 ===")
+
+	account := config.Account{MarketType: "usdt_m", Treasury: config.TreasuryConfig{Enabled: true, Floor: 500, Ceiling: 3000, MaxTransfer: 1000}}
+	cfg := account.GetTreasuryConfig()
+	fmt.Printf("默认值: 资产 %s 目标 %v 间隔 %d（期望USDT 1750 5）\n", cfg.Asset, cfg.Target, cfg.IntervalMinutes)
+	fmt.Printf("验证: %v（期望<nil>）\n", cfg.Validate())
+	fmt.Printf("上限不大于下限: %v（期望错误）\n", config.TreasuryConfig{Enabled: true, Floor: 500, Ceiling: 400}.Validate())
+
+	// 1. 划转计划
+	for _, c := range []struct {
+		available, spot float64
+		expect          string
+	}{
+		{300, 5000, "in 1000（上限截断）"},
+		{1200, 5000, "（不划转）"},
+		{3500, 0, "out 1000（上限截断）"},
+		{400, 123.456, "in 123.456（现货余额）"},
+		{400, 0, "in 0（现货没有余额）"},
+	} {
+		direction, amount := treasury.Plan(cfg, c.available, c.spot)
+		fmt.Printf("可用 %v 现货 %v: %s %v（期望%s）\n", c.available, c.spot, direction, amount, c.expect)
+	}
+	cfg.MaxTransfer = 0
+	direction, amount := treasury.Plan(cfg, 3200.5, 0)
+	fmt.Printf("不限单次划转: %s %v（期望out 1450.5）\n", direction, amount)
+
+	// 2. 模拟接口
+	var mu sync.Mutex
+	available, spotFree := "200", "800"
+	var transfers []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fapi/v2/balance", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode([]map[string]string{{"asset": "USDT", "balance": "5000", "availableBalance": available}})
+	})
+	mux.HandleFunc("/api/v3/account", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"balances": []map[string]string{{"asset": "USDT", "free": spotFree, "locked": "100"}},
+		})
+	})
+	mux.HandleFunc("/sapi/v1/asset/transfer", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		r.ParseForm()
+		transfers = append(transfers, fmt.Sprintf("%s %s %s", r.Form.Get("type"), r.Form.Get("asset"), r.Form.Get("amount")))
+		json.NewEncoder(w).Encode(map[string]int64{"tranId": int64(100 + len(transfers))})
+	})
+	mock := httptest.NewServer(mux)
+	defer mock.Close()
+
+	spot := binance.NewSpotClient("", "", mock.URL, "")
+	futures := binance.NewClient("", "", mock.URL, "")
+
+	// 3. Transfer 只支持现货客户端
+	_, err := futures.Transfer(binance.TransferMainToUMFuture, "USDT", 10)
+	fmt.Printf("合约客户端划转: %v（期望不支持）\n", err)
+	tranID, err := spot.Transfer(binance.TransferMainToUMFuture, "USDT", 10)
+	fmt.Printf("现货客户端划转: ID %d 错误 %v（期望101 <nil>）\n", tranID, err)
+
+	// 4. Check
+	t, err := treasury.New("acc-1", binance.MarketTypeUSDTM, futures, spot, cfg)
+	if err != nil {
+		utils.Fatal("创建资金调拨失败")
+	}
+	var notified []*treasury.Transfer
+	t.OnTransfer(func(tr *treasury.Transfer) { notified = append(notified, tr) })
+
+	tr, err := t.Check()
+	fmt.Printf("低于下限: %s %v 错误 %v（期望in 800（现货可用余额，不含冻结） <nil>）\n", tr.Direction, tr.Amount, err)
+
+	mu.Lock()
+	available = "1000"
+	mu.Unlock()
+	tr, err = t.Check()
+	fmt.Printf("上下限之间: %v 错误 %v（期望<nil> <nil>）\n", tr, err)
+
+	mu.Lock()
+	available = "4000"
+	mu.Unlock()
+	tr, err = t.Check()
+	fmt.Printf("高于上限: %s %v 划转前可用 %v（期望out 2250 4000）\n", tr.Direction, tr.Amount, tr.Available)
+
+	mu.Lock()
+	available, spotFree = "100", "0"
+	mu.Unlock()
+	tr, err = t.Check()
+	fmt.Printf("现货没有余额: %v 错误 %v（期望<nil> <nil>）\n", tr, err)
+
+	mu.Lock()
+	fmt.Printf("划转请求: %v（期望[MAIN_UMFUTURE USDT 10 MAIN_UMFUTURE USDT 800 UMFUTURE_MAIN USDT 2250]）\n", transfers)
+	mu.Unlock()
+	fmt.Printf("回调次数: %d（期望2）\n", len(notified))
+
+	_, err = treasury.New("acc-2", binance.MarketTypeSpot, futures, spot, cfg)
+	fmt.Printf("现货账号: %v（期望不是合约市场类型）\n", err)
+
+	utils.Info("=== 合约账户资金自动调拨测试完成 ===")
+}
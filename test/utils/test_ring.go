@@ -0,0 +1,84 @@
+/*
+时间序列环形缓冲测试程序
+
+测试内容：
+- 未写满时按写入顺序返回，写满后覆盖最旧的点
+- Latest/Update/Since/Newest/Values 的返回值，返回的是副本
+- OICacheManager 基于环形缓冲：History 从新到旧、最多maxSize个，Get 返回快照
+
+运行方式：
+
+	go run test/utils/test_ring.go
+*/
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/utils"
+)
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== 环形缓冲测试开始 ===")
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ring := utils.NewRing[float64](3)
+
+	// 1. 未写满
+	ring.Push(base, 1)
+	ring.Push(base.Add(time.Minute), 2)
+	fmt.Printf("写入2个: Len=%d Cap=%d Values=%v（期望2，3，[1 2]）\n", ring.Len(), ring.Cap(), ring.Values())
+
+	// 2. 写满后覆盖最旧的点
+	for i := 3; i <= 5; i++ {
+		ring.Push(base.Add(time.Duration(i-1)*time.Minute), float64(i))
+	}
+	fmt.Printf("写入5个: Len=%d Values=%v（期望3，[3 4 5]）\n", ring.Len(), ring.Values())
+
+	// 3. 最新一个点和累加更新
+	latest, _ := ring.Latest()
+	ring.Update(latest.Value + 10)
+	latest, _ = ring.Latest()
+	fmt.Printf("Update后最新: %.0f %s（期望15，00:04）\n", latest.Value, latest.Time.Format("15:04"))
+
+	// 4. 时间范围和最新n个
+	var since []float64
+	for _, p := range ring.Since(base.Add(2 * time.Minute)) {
+		since = append(since, p.Value)
+	}
+	var newest []float64
+	for _, p := range ring.Newest(2) {
+		newest = append(newest, p.Value)
+	}
+	fmt.Printf("Since(00:02)=%v Newest(2)=%v（期望[4 15]，[15 4]）\n", since, newest)
+
+	// 5. 返回副本
+	values := ring.Values()
+	values[0] = 100
+	fmt.Printf("修改副本后: %v（期望[3 4 15]）\n", ring.Values())
+
+	empty := utils.NewRing[int](0)
+	_, ok := empty.Latest()
+	fmt.Printf("空缓冲: Cap=%d Latest ok=%v Update=%v（期望1，false，false）\n", empty.Cap(), ok, empty.Update(1))
+
+	// 6. OI缓存
+	manager := utils.NewOICacheManager(3)
+	now := time.Now().Unix()
+	for i := 0; i < 5; i++ {
+		manager.Update("BTCUSDT", 5300+float64(i)*10, now+int64(i))
+	}
+	cache := manager.Get("BTCUSDT")
+	fmt.Printf("OI缓存: History=%v 最新时间戳偏移=%d（期望[5340 5330 5320]，4）\n", cache.History, cache.Timestamps[0]-now)
+	manager.Update("BTCUSDT", 5350, now+5)
+	fmt.Printf("再次更新后旧快照: %v 新快照: %v（期望不变，[5350 5340 5330]）\n", cache.History, manager.Get("BTCUSDT").History)
+	fmt.Printf("过期: %v（期望false）统计: %v\n", manager.IsExpired("BTCUSDT", 60), manager.GetStats())
+
+	utils.Info("=== 环形缓冲测试完成 ===")
+}
@@ -0,0 +1,121 @@
+/*
+AI决策风控否决测试程序
+
+测试内容：
+- 从YAML解析风控否决配置（禁止开仓时间段为RFC3339时间）
+- ADX过低、资金费率不利、价差过大、禁止开仓时间段分别触发否决
+- 列在 downsize 中的规则只缩减仓位，多条相乘后写入决策的 size_factor
+- 查询盘口失败时价差规则同样触发
+- 平仓、观望决策和未启用（nil）时不检查
+
+运行方式：
+
+	go run test/veto/test_veto.go
+*/
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/indicators"
+	"crypto-ai-trader/utils"
+	"crypto-ai-trader/veto"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const vetoYAML = `
+enabled: true
+min_adx: 20
+funding: true
+max_spread_bps: 8
+blackouts:
+  - start: 2026-03-18T17:45:00Z
+    end: 2026-03-18T19:00:00Z
+    reason: FOMC
+downsize_factor: 0.5
+`
+
+func main() {
+	// 初始化日志
+	if err := utils.Init("logs/app.log", "info"); err != nil {
+		panic(err)
+	}
+	defer utils.Sync()
+
+	utils.Info("=== AI决策风控否决测试开始 ===")
+
+	var cfg config.VetoConfig
+	if err := yaml.Unmarshal([]byte(vetoYAML), &cfg); err != nil {
+		utils.Fatal("解析配置失败", zap.Error(err))
+	}
+	fmt.Printf("禁止开仓时间段: %s ~ %s (%s)\n\n", cfg.Blackouts[0].Start, cfg.Blackouts[0].End, cfg.Blackouts[0].Reason)
+
+	data := func(adx, funding float64) *indicators.ShortTermIndicators {
+		return &indicators.ShortTermIndicators{
+			Symbol:     "ETHUSDT",
+			MarketData: &indicators.MarketData{FundingRate: funding},
+			Timeframes: &indicators.ShortTermTimeframes{M15: &indicators.TimeframeData{ClosePrice: 2000, ADX: &adx}},
+		}
+	}
+	spreads := map[string]float64{"ETHUSDT": 2, "WIDEUSDT": 15}
+	spread := func(symbol string) (float64, error) {
+		if s, ok := spreads[symbol]; ok {
+			return s, nil
+		}
+		return 0, fmt.Errorf("交易对不存在: %s", symbol)
+	}
+	normal := time.Date(2026, 3, 18, 12, 0, 0, 0, time.UTC)
+
+	engine := veto.New(cfg, spread)
+	cases := []struct {
+		name   string
+		symbol string
+		action string
+		data   *indicators.ShortTermIndicators
+		now    time.Time
+	}{
+		{"全部通过", "ETHUSDT", executor.ActionOpenLong, data(30, 0.01), normal},
+		{"ADX过低", "ETHUSDT", executor.ActionOpenLong, data(12, 0.01), normal},
+		{"开多时资金费率偏高", "ETHUSDT", executor.ActionOpenLong, data(30, 0.08), normal},
+		{"开空时资金费率偏高", "ETHUSDT", executor.ActionOpenShort, data(30, 0.08), normal},
+		{"价差过大", "WIDEUSDT", executor.ActionOpenLong, data(30, 0.01), normal},
+		{"查询盘口失败", "FOOUSDT", executor.ActionOpenLong, data(30, 0.01), normal},
+		{"FOMC时间段内", "ETHUSDT", executor.ActionOpenShort, data(30, 0.01), time.Date(2026, 3, 18, 18, 0, 0, 0, time.UTC)},
+		{"FOMC结束时", "ETHUSDT", executor.ActionOpenShort, data(30, 0.01), time.Date(2026, 3, 18, 19, 0, 0, 0, time.UTC)},
+		{"平仓不检查", "ETHUSDT", executor.ActionClose, data(12, 0.2), normal},
+	}
+	for _, c := range cases {
+		result := engine.Check(&executor.Decision{Symbol: c.symbol, Action: c.action}, c.data, c.now)
+		printResult(c.name, result)
+	}
+
+	// ADX和资金费率只缩减仓位
+	cfg.Downsize = []string{config.VetoRuleADX, config.VetoRuleFunding}
+	decision := &executor.Decision{Symbol: "ETHUSDT", Action: executor.ActionOpenLong, Quantity: 1}
+	result := veto.New(cfg, spread).Check(decision, data(12, 0.08), normal)
+	printResult("缩减仓位", result)
+	result.Apply(decision)
+	fmt.Printf("  决策 size_factor: %v (期望 0.25)\n", decision.SizeFactor)
+
+	var disabled *veto.Engine
+	fmt.Printf("未启用: 结果=%v (期望 <nil>)\n", disabled.Check(decision, data(12, 0.2), normal))
+
+	utils.Info("=== AI决策风控否决测试完成 ===")
+}
+
+// printResult 输出检查结果
+func printResult(name string, result *veto.Result) {
+	if result == nil {
+		fmt.Printf("%-12s 通过\n", name)
+		return
+	}
+	fmt.Printf("%-12s 否决=%v 数量系数=%v\n", name, result.Vetoed, result.SizeFactor)
+	for _, v := range result.Verdicts {
+		fmt.Printf("  [%s/%s] %s\n", v.Rule, v.Action, v.Reason)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Package toggle 账号运行时停用/启用（不修改 accounts.yml、不重启程序）
+
+主要功能：
+- New(dir string) *Toggles                                                           // 创建账号开关并加载保存的状态（<dir>/account_toggles.json）
+- (t *Toggles) Get(accountID string) *State                                          // 账号的停用状态（未停用时为nil）
+- (t *Toggles) Disable(accountID, positions, reason, source string) (*State, error)  // 停用账号
+- (t *Toggles) Enable(accountID, source string) (bool, error)                        // 恢复账号，账号未停用时返回false
+- (t *Toggles) All() map[string]*State                                               // 全部停用的账号
+
+停用的账号不再开仓；已有持仓按 positions 处理：manage（默认）继续按策略周期只分析有持仓的交易对，
+由AI决策和止损止盈单平仓，close 立即市价平掉全部持仓并撤销挂单（由调用方执行）。
+状态每次变化后写入文件，重启后保持停用，只能通过接口恢复。
+*/
+package toggle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 停用后已有持仓的处理方式
+const (
+	PositionsManage = "manage"
+	PositionsClose  = "close"
+)
+
+// State 账号的停用状态（同时作为持久化格式）
+type State struct {
+	Positions  string    `json:"positions"`        // 已有持仓的处理方式：manage 或 close
+	Reason     string    `json:"reason,omitempty"` // 停用原因
+	Source     string    `json:"source"`           // 来源（如 api）
+	DisabledAt time.Time `json:"disabled_at"`      // 停用时间
+}
+
+// Toggles 账号开关
+type Toggles struct {
+	path     string
+	mu       sync.Mutex
+	disabled map[string]*State // 账号ID -> 停用状态
+}
+
+// New 创建账号开关并加载保存的状态（<dir>/account_toggles.json，读取失败时全部账号为启用）
+func New(dir string) *Toggles {
+	t := &Toggles{
+		path:     filepath.Join(dir, "account_toggles.json"),
+		disabled: make(map[string]*State),
+	}
+
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			utils.Warn("读取账号开关状态失败", zap.String("path", t.path), zap.Error(err))
+		}
+		return t
+	}
+	if err := json.Unmarshal(data, &t.disabled); err != nil {
+		utils.Warn("解析账号开关状态失败", zap.String("path", t.path), zap.Error(err))
+		t.disabled = make(map[string]*State)
+		return t
+	}
+	for id, s := range t.disabled {
+		utils.Warn("账号已停用，不开新仓（通过API恢复）",
+			zap.String("account_id", id),
+			zap.String("positions", s.Positions),
+			zap.String("reason", s.Reason),
+			zap.Time("disabled_at", s.DisabledAt),
+		)
+	}
+	return t
+}
+
+// Get 账号的停用状态（未停用时为nil，返回副本）
+func (t *Toggles) Get(accountID string) *State {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.disabled[accountID]
+	if !ok {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+// Disable 停用账号（positions 为空时为 manage）；已停用的账号更新处理方式和原因
+func (t *Toggles) Disable(accountID, positions, reason, source string) (*State, error) {
+	switch positions {
+	case "":
+		positions = PositionsManage
+	case PositionsManage, PositionsClose:
+	default:
+		return nil, fmt.Errorf("持仓处理方式无效: %s (必须是 manage 或 close)", positions)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := &State{Positions: positions, Reason: reason, Source: source, DisabledAt: time.Now()}
+	old, ok := t.disabled[accountID]
+	if ok {
+		s.DisabledAt = old.DisabledAt
+	}
+	t.disabled[accountID] = s
+	if err := t.saveLocked(); err != nil {
+		if ok {
+			t.disabled[accountID] = old
+		} else {
+			delete(t.disabled, accountID)
+		}
+		return nil, err
+	}
+	utils.Warn("账号已停用",
+		zap.String("account_id", accountID),
+		zap.String("positions", positions),
+		zap.String("reason", reason),
+		zap.String("source", source),
+	)
+	c := *s
+	return &c, nil
+}
+
+// Enable 恢复账号，账号未停用时返回false
+func (t *Toggles) Enable(accountID, source string) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	old, ok := t.disabled[accountID]
+	if !ok {
+		return false, nil
+	}
+	delete(t.disabled, accountID)
+	if err := t.saveLocked(); err != nil {
+		t.disabled[accountID] = old
+		return false, err
+	}
+	utils.Info("账号已恢复", zap.String("account_id", accountID), zap.String("source", source))
+	return true, nil
+}
+
+// All 全部停用的账号（返回副本）
+func (t *Toggles) All() map[string]*State {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	all := make(map[string]*State, len(t.disabled))
+	for id, s := range t.disabled {
+		c := *s
+		all[id] = &c
+	}
+	return all
+}
+
+// saveLocked 保存状态（调用方已持有锁，保存失败时由调用方恢复内存中的状态）
+func (t *Toggles) saveLocked() error {
+	data, err := json.MarshalIndent(t.disabled, "", "  ")
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(t.path), 0755); err == nil {
+			err = os.WriteFile(t.path, data, 0644)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("保存账号开关状态失败: %w", err)
+	}
+	return nil
+}
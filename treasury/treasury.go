@@ -0,0 +1,204 @@
+/*
+Package treasury 合约账户资金自动调拨（可用余额低于下限时从现货转入，高于上限时把利润转回现货）
+
+主要功能：
+- New(accountID, marketType string, futures, spot *binance.Client, cfg config.TreasuryConfig) (*Treasury, error)  // 创建资金调拨
+- (t *Treasury) OnTransfer(fn func(tr *Transfer))                                                                 // 设置划转成功后的回调（如发送告警）
+- (t *Treasury) Check() (*Transfer, error)                                                                        // 检查一次，需要时划转（不需要划转时返回nil）
+- (t *Treasury) Run(ctx context.Context)                                                                          // 立即检查一次，然后按间隔定时检查，直到ctx取消
+- Plan(cfg config.TreasuryConfig, available, spotFree float64) (string, float64)                                  // 计算划转方向和数量
+
+转入数量不超过现货账户的可用余额，转出数量不超过合约账户的可用余额；
+配置了单次划转上限时按上限截断，剩余部分在下一次检查时继续划转。
+*/
+package treasury
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"crypto-ai-trader/binance"
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/utils"
+
+	"go.uber.org/zap"
+)
+
+// 划转方向
+const (
+	DirectionIn  = "in"  // 现货 → 合约（补充保证金）
+	DirectionOut = "out" // 合约 → 现货（转出利润）
+)
+
+// precision 划转数量的小数位数（按交易所划转精度向下取整）
+const precision = 1e8
+
+// Transfer 一次划转
+type Transfer struct {
+	Time      time.Time `json:"time"`       // 划转时间
+	AccountID string    `json:"account_id"` // 账号ID
+	Direction string    `json:"direction"`  // in（转入合约）或 out（转出到现货）
+	Asset     string    `json:"asset"`      // 资产
+	Amount    float64   `json:"amount"`     // 数量
+	Available float64   `json:"available"`  // 划转前合约账户可用余额
+	TranID    int64     `json:"tran_id"`    // 交易所返回的划转ID
+}
+
+// Treasury 单个合约账号的资金调拨
+type Treasury struct {
+	accountID string
+	futures   *binance.Client // 合约客户端（查询可用余额）
+	spot      *binance.Client // 同一组API密钥的现货客户端（查询现货余额、划转）
+	cfg       config.TreasuryConfig
+	in, out   string // 转入、转出的划转类型
+	notify    func(tr *Transfer)
+}
+
+// New 创建资金调拨（cfg 需已填充默认值，见 Account.GetTreasuryConfig）
+func New(accountID, marketType string, futures, spot *binance.Client, cfg config.TreasuryConfig) (*Treasury, error) {
+	in, out, err := binance.FuturesTransferTypes(marketType)
+	if err != nil {
+		return nil, err
+	}
+	return &Treasury{
+		accountID: accountID,
+		futures:   futures,
+		spot:      spot,
+		cfg:       cfg,
+		in:        in,
+		out:       out,
+	}, nil
+}
+
+// OnTransfer 设置划转成功后的回调
+func (t *Treasury) OnTransfer(fn func(tr *Transfer)) {
+	t.notify = fn
+}
+
+// Plan 根据合约可用余额和现货可用余额计算划转方向和数量（不需要划转时数量为0）
+func Plan(cfg config.TreasuryConfig, available, spotFree float64) (string, float64) {
+	var direction string
+	var amount float64
+	switch {
+	case cfg.Floor > 0 && available < cfg.Floor:
+		direction, amount = DirectionIn, math.Min(cfg.Target-available, spotFree)
+	case cfg.Ceiling > 0 && available > cfg.Ceiling:
+		direction, amount = DirectionOut, available-cfg.Target
+	default:
+		return "", 0
+	}
+	if cfg.MaxTransfer > 0 {
+		amount = math.Min(amount, cfg.MaxTransfer)
+	}
+	amount = math.Floor(amount*precision) / precision
+	if amount <= 0 {
+		return direction, 0
+	}
+	return direction, amount
+}
+
+// Check 检查一次，需要时划转（不需要划转时返回nil）
+func (t *Treasury) Check() (*Transfer, error) {
+	balance, err := t.futures.GetAssetBalance(t.cfg.Asset)
+	if err != nil {
+		return nil, fmt.Errorf("查询合约可用余额失败: %w", err)
+	}
+	available, _ := strconv.ParseFloat(balance.AvailableBalance, 64)
+
+	// 只有需要转入时才查询现货余额
+	var spotFree float64
+	if t.cfg.Floor > 0 && available < t.cfg.Floor {
+		if spotFree, err = t.spotFree(); err != nil {
+			return nil, err
+		}
+	}
+
+	direction, amount := Plan(t.cfg, available, spotFree)
+	if direction == "" {
+		return nil, nil
+	}
+	if amount == 0 {
+		utils.Warn("合约可用余额低于下限，现货账户没有可转入的余额",
+			zap.String("account_id", t.accountID),
+			zap.String("asset", t.cfg.Asset),
+			zap.Float64("available", available),
+			zap.Float64("floor", t.cfg.Floor),
+		)
+		return nil, nil
+	}
+
+	transferType := t.in
+	if direction == DirectionOut {
+		transferType = t.out
+	}
+	tranID, err := t.spot.Transfer(transferType, t.cfg.Asset, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := &Transfer{
+		Time:      time.Now(),
+		AccountID: t.accountID,
+		Direction: direction,
+		Asset:     t.cfg.Asset,
+		Amount:    amount,
+		Available: available,
+		TranID:    tranID,
+	}
+	utils.Info("合约账户资金调拨完成",
+		zap.String("account_id", t.accountID),
+		zap.String("direction", direction),
+		zap.String("asset", t.cfg.Asset),
+		zap.Float64("amount", amount),
+		zap.Float64("available", available),
+		zap.Int64("tran_id", tranID),
+	)
+	if t.notify != nil {
+		t.notify(tr)
+	}
+	return tr, nil
+}
+
+// spotFree 现货账户的可用余额（不含冻结）
+func (t *Treasury) spotFree() (float64, error) {
+	balances, err := t.spot.GetSpotBalances()
+	if err != nil {
+		return 0, fmt.Errorf("查询现货余额失败: %w", err)
+	}
+	for _, b := range balances {
+		if b.Asset == t.cfg.Asset {
+			free, _ := strconv.ParseFloat(b.Free, 64)
+			return free, nil
+		}
+	}
+	return 0, nil
+}
+
+// Run 立即检查一次，然后按间隔定时检查，直到ctx取消
+func (t *Treasury) Run(ctx context.Context) {
+	interval := time.Duration(t.cfg.IntervalMinutes) * time.Minute
+	utils.Info("启动合约账户资金调拨",
+		zap.String("account_id", t.accountID),
+		zap.String("asset", t.cfg.Asset),
+		zap.Float64("floor", t.cfg.Floor),
+		zap.Float64("ceiling", t.cfg.Ceiling),
+		zap.Float64("target", t.cfg.Target),
+		zap.Duration("interval", interval),
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := t.Check(); err != nil {
+			utils.Error("合约账户资金调拨失败", zap.String("account_id", t.accountID), zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -6,6 +6,9 @@ Package utils OI缓存管理
 - (m *OICacheManager) Get(symbol string) *indicators.OICache             // 获取缓存
 - (m *OICacheManager) Update(symbol string, oi float64, timestamp int64) // 更新缓存
 - (m *OICacheManager) GetAll() map[string]*indicators.OICache            // 获取所有缓存
+
+每个交易对的历史保存在固定容量的环形缓冲（Ring）中，Get/GetAll 返回的 OICache 是快照副本，
+调用方可以在其他协程更新缓存的同时安全读取。
 */
 package utils
 
@@ -16,7 +19,7 @@ import (
 	"go.uber.org/zap"
 )
 
-// OICache OI缓存结构（避免循环依赖，在这里重新定义）
+// OICache OI缓存快照（避免循环依赖，在这里重新定义）
 type OICache struct {
 	Symbol     string    // 交易对
 	History    []float64 // 历史OI值（从新到旧，最多5个）
@@ -25,7 +28,7 @@ type OICache struct {
 
 // OICacheManager OI缓存管理器
 type OICacheManager struct {
-	caches map[string]*OICache
+	caches map[string]*Ring[float64] // symbol -> OI历史（百万美元）
 	mu     sync.RWMutex
 	maxSize int // 每个symbol最多保存的历史记录数
 }
@@ -39,21 +42,36 @@ func NewOICacheManager(maxSize int) *OICacheManager {
 	Info("创建OI缓存管理器", zap.Int("max_size", maxSize))
 	
 	return &OICacheManager{
-		caches:  make(map[string]*OICache),
+		caches:  make(map[string]*Ring[float64]),
 		maxSize: maxSize,
 	}
 }
 
-// Get 获取指定交易对的OI缓存
+// Get 获取指定交易对的OI缓存快照（从新到旧）
 func (m *OICacheManager) Get(symbol string) *OICache {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
-	cache, exists := m.caches[symbol]
+	ring, exists := m.caches[symbol]
 	if !exists {
 		return nil
 	}
 	
+	return snapshotOICache(symbol, ring)
+}
+
+// snapshotOICache 把环形缓冲转换为OI缓存快照（调用方持有锁）
+func snapshotOICache(symbol string, ring *Ring[float64]) *OICache {
+	points := ring.Newest(ring.Len())
+	cache := &OICache{
+		Symbol:     symbol,
+		History:    make([]float64, len(points)),
+		Timestamps: make([]int64, len(points)),
+	}
+	for i, p := range points {
+		cache.History[i] = p.Value
+		cache.Timestamps[i] = p.Time.Unix()
+	}
 	return cache
 }
 
@@ -62,31 +80,20 @@ func (m *OICacheManager) Update(symbol string, oi float64, timestamp int64) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
-	cache, exists := m.caches[symbol]
+	ring, exists := m.caches[symbol]
 	if !exists {
 		// 创建新缓存
-		cache = &OICache{
-			Symbol:     symbol,
-			History:    []float64{},
-			Timestamps: []int64{},
-		}
-		m.caches[symbol] = cache
+		ring = NewRing[float64](m.maxSize)
+		m.caches[symbol] = ring
 	}
 	
-	// 添加新值到开头
-	cache.History = append([]float64{oi}, cache.History...)
-	cache.Timestamps = append([]int64{timestamp}, cache.Timestamps...)
-	
-	// 保持最大数量
-	if len(cache.History) > m.maxSize {
-		cache.History = cache.History[:m.maxSize]
-		cache.Timestamps = cache.Timestamps[:m.maxSize]
-	}
+	// 写满后覆盖最旧的值
+	ring.Push(time.Unix(timestamp, 0), oi)
 	
 	Debug("更新OI缓存",
 		zap.String("symbol", symbol),
 		zap.Float64("oi", oi),
-		zap.Int("history_count", len(cache.History)),
+		zap.Int("history_count", ring.Len()),
 	)
 }
 
@@ -95,10 +102,10 @@ func (m *OICacheManager) GetAll() map[string]*OICache {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
-	// 返回副本，避免外部修改
+	// 返回快照，避免外部修改
 	result := make(map[string]*OICache, len(m.caches))
-	for symbol, cache := range m.caches {
-		result[symbol] = cache
+	for symbol, ring := range m.caches {
+		result[symbol] = snapshotOICache(symbol, ring)
 	}
 	
 	return result
@@ -118,7 +125,7 @@ func (m *OICacheManager) ClearAll() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
-	m.caches = make(map[string]*OICache)
+	m.caches = make(map[string]*Ring[float64])
 	Info("清空所有OI缓存")
 }
 
@@ -146,16 +153,20 @@ func (m *OICacheManager) GetSymbols() []string {
 // IsExpired 检查缓存是否过期
 // maxAge: 最大缓存时间（秒）
 func (m *OICacheManager) IsExpired(symbol string, maxAge int64) bool {
-	cache := m.Get(symbol)
-	if cache == nil || len(cache.Timestamps) == 0 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	ring, exists := m.caches[symbol]
+	if !exists {
+		return true
+	}
+	latest, ok := ring.Latest()
+	if !ok {
 		return true
 	}
 	
 	// 检查最新数据的时间戳
-	latestTimestamp := cache.Timestamps[0]
-	currentTimestamp := time.Now().Unix()
-	
-	return (currentTimestamp - latestTimestamp) > maxAge
+	return (time.Now().Unix() - latest.Time.Unix()) > maxAge
 }
 
 // CleanExpired 清理过期缓存
@@ -167,15 +178,15 @@ func (m *OICacheManager) CleanExpired(maxAge int64) int {
 	currentTimestamp := time.Now().Unix()
 	cleaned := 0
 	
-	for symbol, cache := range m.caches {
-		if len(cache.Timestamps) == 0 {
+	for symbol, ring := range m.caches {
+		latest, ok := ring.Latest()
+		if !ok {
 			delete(m.caches, symbol)
 			cleaned++
 			continue
 		}
 		
-		latestTimestamp := cache.Timestamps[0]
-		if (currentTimestamp - latestTimestamp) > maxAge {
+		if (currentTimestamp - latest.Time.Unix()) > maxAge {
 			delete(m.caches, symbol)
 			cleaned++
 		}
@@ -194,8 +205,8 @@ func (m *OICacheManager) GetStats() map[string]interface{} {
 	defer m.mu.RUnlock()
 	
 	totalRecords := 0
-	for _, cache := range m.caches {
-		totalRecords += len(cache.History)
+	for _, ring := range m.caches {
+		totalRecords += ring.Len()
 	}
 	
 	return map[string]interface{}{
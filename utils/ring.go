@@ -0,0 +1,123 @@
+/*
+Package utils 固定容量的时间序列环形缓冲
+
+主要功能：
+- NewRing[T any](capacity int) *Ring[T]                  // 创建环形缓冲（写满后覆盖最旧的点）
+- (r *Ring[T]) Push(t time.Time, value T)                // 追加一个点
+- (r *Ring[T]) Update(value T) bool                      // 替换最新一个点的值（用于按周期累加的数据）
+- (r *Ring[T]) Latest() (Point[T], bool)                 // 最新一个点
+- (r *Ring[T]) Snapshot() []Point[T]                     // 全部点的副本（从旧到新）
+- (r *Ring[T]) Since(t time.Time) []Point[T]             // 时间晚于t的点（从旧到新）
+- (r *Ring[T]) Values() []T                              // 全部值的副本（从旧到新，供指标计算）
+- (r *Ring[T]) Newest(n int) []Point[T]                  // 最新的n个点（从新到旧）
+
+按写入顺序保存，不按时间排序；容量固定，写入不分配内存，替代“插入到切片开头再截断”的写法。
+Ring 不加锁，调用方负责并发保护；所有访问方法返回副本，调用方可以在释放锁之后使用。
+*/
+package utils
+
+import "time"
+
+// Point 时间序列中的一个点
+type Point[T any] struct {
+	Time  time.Time `json:"time"`
+	Value T         `json:"value"`
+}
+
+// Ring 固定容量的时间序列环形缓冲
+type Ring[T any] struct {
+	points []Point[T]
+	next   int // 下一个写入位置
+	size   int // 已保存的点数
+}
+
+// NewRing 创建环形缓冲（capacity<=0时按1处理）
+func NewRing[T any](capacity int) *Ring[T] {
+	return &Ring[T]{points: make([]Point[T], max(capacity, 1))}
+}
+
+// Push 追加一个点，写满后覆盖最旧的点
+func (r *Ring[T]) Push(t time.Time, value T) {
+	r.points[r.next] = Point[T]{Time: t, Value: value}
+	r.next = (r.next + 1) % len(r.points)
+	r.size = min(r.size+1, len(r.points))
+}
+
+// Update 替换最新一个点的值（时间不变，缓冲为空时返回false）
+func (r *Ring[T]) Update(value T) bool {
+	if r.size == 0 {
+		return false
+	}
+	r.points[r.index(r.size-1)].Value = value
+	return true
+}
+
+// Len 已保存的点数
+func (r *Ring[T]) Len() int {
+	return r.size
+}
+
+// Cap 容量
+func (r *Ring[T]) Cap() int {
+	return len(r.points)
+}
+
+// Reset 清空
+func (r *Ring[T]) Reset() {
+	clear(r.points)
+	r.next, r.size = 0, 0
+}
+
+// index 第i个点（0为最旧）在底层数组中的位置
+func (r *Ring[T]) index(i int) int {
+	return (r.next - r.size + i + len(r.points)) % len(r.points)
+}
+
+// Latest 最新一个点（缓冲为空时返回false）
+func (r *Ring[T]) Latest() (Point[T], bool) {
+	if r.size == 0 {
+		return Point[T]{}, false
+	}
+	return r.points[r.index(r.size-1)], true
+}
+
+// Snapshot 全部点的副本（从旧到新）
+func (r *Ring[T]) Snapshot() []Point[T] {
+	points := make([]Point[T], r.size)
+	for i := range points {
+		points[i] = r.points[r.index(i)]
+	}
+	return points
+}
+
+// Since 时间晚于t的点（从旧到新，从最新的点往前找，遇到不晚于t的点停止）
+func (r *Ring[T]) Since(t time.Time) []Point[T] {
+	n := 0
+	for n < r.size && r.points[r.index(r.size-1-n)].Time.After(t) {
+		n++
+	}
+	points := make([]Point[T], n)
+	for i := range points {
+		points[i] = r.points[r.index(r.size-n+i)]
+	}
+	return points
+}
+
+// Values 全部值的副本（从旧到新）
+func (r *Ring[T]) Values() []T {
+	values := make([]T, r.size)
+	for i := range values {
+		values[i] = r.points[r.index(i)].Value
+	}
+	return values
+}
+
+// Newest 最新的n个点（从新到旧，不足n个时返回全部）
+func (r *Ring[T]) Newest(n int) []Point[T] {
+	n = min(max(n, 0), r.size)
+	points := make([]Point[T], n)
+	for i := range points {
+		points[i] = r.points[r.index(r.size-1-i)]
+	}
+	return points
+}
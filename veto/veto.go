@@ -0,0 +1,137 @@
+/*
+Package veto AI决策风控否决（确定性规则）
+
+主要功能：
+- New(cfg config.VetoConfig, spread SpreadFunc) *Engine                                      // 创建风控否决规则（未启用时为nil，检查总是通过）
+- (e *Engine) Check(decision *executor.Decision, data interface{}, now time.Time) *Result    // 检查开仓决策，返回触发的规则
+- (r *Result) Apply(decision *executor.Decision)                                             // 按检查结果缩减决策的开仓数量
+
+AI的判断不可控，开仓决策执行前用硬性约束再检查一遍，规则只依赖指标数据、盘口和配置：
+1. adx：主分析周期ADX低于 min_adx（没有趋势）
+2. funding：资金费率不利于开仓方向（indicators.ShouldTradeBasedOnFunding，多头或空头拥挤）
+3. spread：买卖价差超过 max_spread_bps（流动性差，滑点大）；查询盘口失败时同样触发
+4. blackout：当前时间处于禁止开仓的时间段（如重大数据或新闻发布前后）
+触发的规则默认否决决策；列在 downsize 中的规则只把开仓数量乘以 downsize_factor（多条相乘）。
+平仓和观望决策不检查。所有触发的规则和原因写入审计记录。
+*/
+package veto
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"crypto-ai-trader/config"
+	"crypto-ai-trader/executor"
+	"crypto-ai-trader/indicators"
+)
+
+// 规则触发后的处理
+const (
+	ActionVeto     = "veto"     // 否决决策
+	ActionDownsize = "downsize" // 缩减开仓数量
+)
+
+// SpreadFunc 查询交易对的买卖价差（基点）
+type SpreadFunc func(symbol string) (float64, error)
+
+// Verdict 一条触发的规则
+type Verdict struct {
+	Rule   string `json:"rule"`   // 规则：adx / funding / spread / blackout
+	Action string `json:"action"` // 处理：veto / downsize
+	Reason string `json:"reason"` // 触发原因
+}
+
+// Result 检查结果
+type Result struct {
+	Vetoed     bool       `json:"vetoed"`                // 是否否决
+	SizeFactor float64    `json:"size_factor,omitempty"` // 开仓数量系数（未缩减时为0）
+	Verdicts   []*Verdict `json:"verdicts"`              // 触发的规则
+}
+
+// Engine 风控否决规则
+type Engine struct {
+	cfg    config.VetoConfig
+	spread SpreadFunc // 为nil时不检查价差
+}
+
+// New 创建风控否决规则（未启用时返回nil；spread为nil时跳过价差规则）
+func New(cfg config.VetoConfig, spread SpreadFunc) *Engine {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Engine{cfg: cfg, spread: spread}
+}
+
+// Check 检查开仓决策，返回触发的规则（未启用、平仓、观望或没有触发任何规则时返回nil）
+// data: 策略输出的指标数据（ADX、资金费率取自主分析周期和市场数据）
+func (e *Engine) Check(decision *executor.Decision, data interface{}, now time.Time) *Result {
+	if e == nil {
+		return nil
+	}
+	var direction string
+	switch decision.Action {
+	case executor.ActionOpenLong:
+		direction = indicators.DirectionLong
+	case executor.ActionOpenShort:
+		direction = indicators.DirectionShort
+	default:
+		return nil
+	}
+
+	result := &Result{}
+	_, tf, market := indicators.PrimaryTimeframe(data)
+	if e.cfg.MinADX > 0 && tf != nil && tf.ADX != nil && *tf.ADX < e.cfg.MinADX {
+		e.trigger(result, config.VetoRuleADX, fmt.Sprintf("ADX %.1f 低于 %.1f，没有明确趋势", *tf.ADX, e.cfg.MinADX))
+	}
+	if e.cfg.Funding && market != nil {
+		if ok, reason := indicators.ShouldTradeBasedOnFunding(market.FundingRate, direction); !ok {
+			e.trigger(result, config.VetoRuleFunding, fmt.Sprintf("资金费率 %.4f%%：%s", market.FundingRate, reason))
+		}
+	}
+	if e.cfg.MaxSpreadBps > 0 && e.spread != nil {
+		if spread, err := e.spread(decision.Symbol); err != nil {
+			e.trigger(result, config.VetoRuleSpread, fmt.Sprintf("查询盘口失败，无法检查价差: %v", err))
+		} else if spread > e.cfg.MaxSpreadBps {
+			e.trigger(result, config.VetoRuleSpread, fmt.Sprintf("买卖价差 %.1f 基点超过 %.1f", spread, e.cfg.MaxSpreadBps))
+		}
+	}
+	for _, w := range e.cfg.Blackouts {
+		if !now.Before(w.Start) && now.Before(w.End) {
+			e.trigger(result, config.VetoRuleBlackout, fmt.Sprintf("禁止开仓时间段 %s ~ %s：%s",
+				w.Start.Format(time.RFC3339), w.End.Format(time.RFC3339), w.Reason))
+			break
+		}
+	}
+
+	if len(result.Verdicts) == 0 {
+		return nil
+	}
+	return result
+}
+
+// trigger 记录一条触发的规则
+func (e *Engine) trigger(result *Result, rule, reason string) {
+	verdict := &Verdict{Rule: rule, Action: ActionVeto, Reason: reason}
+	if slices.Contains(e.cfg.Downsize, rule) {
+		verdict.Action = ActionDownsize
+		if result.SizeFactor == 0 {
+			result.SizeFactor = 1
+		}
+		result.SizeFactor *= e.cfg.DownsizeFactor
+	} else {
+		result.Vetoed = true
+	}
+	result.Verdicts = append(result.Verdicts, verdict)
+}
+
+// Apply 按检查结果缩减决策的开仓数量（否决的决策由调用方跳过，不在此处理）
+func (r *Result) Apply(decision *executor.Decision) {
+	if r == nil || r.SizeFactor <= 0 {
+		return
+	}
+	if decision.SizeFactor == 0 {
+		decision.SizeFactor = 1
+	}
+	decision.SizeFactor *= r.SizeFactor
+}